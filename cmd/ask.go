@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bgdnvk/clanker/internal/agent"
 	"github.com/bgdnvk/clanker/internal/ai"
 	"github.com/bgdnvk/clanker/internal/aws"
 	"github.com/bgdnvk/clanker/internal/azure"
@@ -90,10 +92,12 @@ Examples:
   clanker ask "Show me lambda functions with high error rates"
   clanker ask "What's the current RDS instance status?"
   clanker ask "Show me GitHub Actions workflow status"
-  clanker ask "What pull requests are open?"`,
+  clanker ask "What pull requests are open?"
+  clanker ask --account-summary`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		apply, _ := cmd.Flags().GetBool("apply")
-		if apply {
+		accountSummary, _ := cmd.Flags().GetBool("account-summary")
+		if apply || accountSummary {
 			return nil
 		}
 		if len(args) < 1 {
@@ -163,7 +167,62 @@ Examples:
 		if cmd.Flags().Changed("agent-trace") {
 			viper.Set("agent.trace", agentTrace)
 		}
+		if explain, _ := cmd.Flags().GetBool("explain"); explain {
+			viper.Set("agent.explain", true)
+		}
 		routeOnly, _ := cmd.Flags().GetBool("route-only")
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			viper.Set("aws.dry_run", true)
+		}
+		if allowMutations, _ := cmd.Flags().GetBool("allow-mutations"); allowMutations {
+			viper.Set("aws.allow_mutations", true)
+		}
+		if regions, _ := cmd.Flags().GetStringSlice("regions"); len(regions) > 0 {
+			viper.Set("aws.regions", regions)
+		}
+		if resume, _ := cmd.Flags().GetBool("resume"); resume {
+			viper.Set("aws.discovery_resume", true)
+		}
+		if services, _ := cmd.Flags().GetStringSlice("services"); len(services) > 0 {
+			viper.Set("aws.discovery.include", services)
+		}
+		// Explicit flags beat AWS_PROFILE / AWS_REGION and config defaults.
+		if strings.TrimSpace(profile) != "" {
+			viper.Set("aws.profile_override", strings.TrimSpace(profile))
+		}
+		if region, _ := cmd.Flags().GetString("region"); strings.TrimSpace(region) != "" {
+			viper.Set("aws.region_override", strings.TrimSpace(region))
+		}
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+		if strings.TrimSpace(since) != "" || strings.TrimSpace(until) != "" {
+			if _, err := aws.ParseTimeRange(since, until, time.Now()); err != nil {
+				return err
+			}
+			viper.Set("agent.since", strings.TrimSpace(since))
+			viper.Set("agent.until", strings.TrimSpace(until))
+		}
+		for flag, key := range map[string]string{"agents": "agent.force_agents", "no-agents": "agent.suppress_agents"} {
+			names, _ := cmd.Flags().GetStringSlice(flag)
+			if len(names) == 0 {
+				continue
+			}
+			if err := agent.ValidateAgentTypes(names); err != nil {
+				return fmt.Errorf("--%s: %w", flag, err)
+			}
+			viper.Set(key, names)
+		}
+		if redact, _ := cmd.Flags().GetBool("redact"); redact {
+			viper.Set("agent.redact", true)
+		}
+		outputFormat, _ := cmd.Flags().GetString("output")
+		switch strings.ToLower(strings.TrimSpace(outputFormat)) {
+		case "", "text":
+		case "json", "markdown":
+			viper.Set("agent.output", strings.ToLower(strings.TrimSpace(outputFormat)))
+		default:
+			return fmt.Errorf("unsupported --output %q (available: text, json, markdown)", outputFormat)
+		}
 
 		if strings.TrimSpace(localModelInferenceURL) != "" {
 			viper.Set("ai.providers.openai.local_model_inference_url", strings.TrimSpace(localModelInferenceURL))
@@ -187,6 +246,10 @@ Examples:
 		}
 		dbRequestedExplicitly := cmd.Flags().Changed("db") || cmd.Flags().Changed("db-connection")
 
+		if accountSummary, _ := cmd.Flags().GetBool("account-summary"); accountSummary {
+			return printAccountSummary(context.Background(), profile, debug)
+		}
+
 		applyCommandAIOverrides(aiProfile, openaiKey, anthropicKey, geminiKey, deepseekKey, cohereKey, minimaxKey, openaiModel, anthropicModel, geminiModel, deepseekModel, cohereModel, minimaxModel, githubModel)
 
 		// Handle route-only mode: return routing decision as JSON without executing
@@ -479,6 +542,8 @@ Examples:
 				apiKey = resolveCohereKey(cohereKey)
 			case "minimax":
 				apiKey = resolveMiniMaxKey(minimaxKey)
+			case "openrouter":
+				apiKey = resolveOpenRouterKey("")
 			case "github-models":
 				apiKey = ""
 			default:
@@ -541,6 +606,8 @@ Examples:
 				apiKey = resolveCohereKey(cohereKey)
 			case "minimax":
 				apiKey = resolveMiniMaxKey(minimaxKey)
+			case "openrouter":
+				apiKey = resolveOpenRouterKey("")
 			case "github-models":
 				apiKey = ""
 			default:
@@ -1413,6 +1480,8 @@ Format as a professional compliance table suitable for government security docum
 				apiKey = resolveCohereKey(cohereKey)
 			case "minimax":
 				apiKey = resolveMiniMaxKey(minimaxKey)
+			case "openrouter":
+				apiKey = resolveOpenRouterKey("")
 			case "github-models":
 				apiKey = ""
 			default:
@@ -1458,6 +1527,8 @@ Format as a professional compliance table suitable for government security docum
 				apiKey = resolveCohereKey(cohereKey)
 			case "minimax":
 				apiKey = resolveMiniMaxKey(minimaxKey)
+			case "openrouter":
+				apiKey = resolveOpenRouterKey("")
 			case "github-models":
 				apiKey = ""
 			default:
@@ -1493,16 +1564,58 @@ Format as a professional compliance table suitable for government security docum
 			fmt.Printf("Calling AskWithTools with AWS profile: %s\n", awsProfileForTools)
 		}
 
+		followUp, _ := cmd.Flags().GetBool("follow-up")
+		if followUp {
+			aiClient.StartAgentSession()
+		}
+
 		response, err := aiClient.AskWithTools(ctx, question, awsContext, combinedCodeContext, awsProfileForTools, githubContext)
 		if err != nil {
 			return fmt.Errorf("failed to get AI response: %w", err)
 		}
 
 		fmt.Println(response)
+		if followUp {
+			runFollowUpSession(os.Stdin, func(q string) (string, error) {
+				return aiClient.AskWithTools(ctx, q, awsContext, combinedCodeContext, awsProfileForTools, githubContext)
+			})
+		}
 		return nil
 	},
 }
 
+// runFollowUpSession reads follow-up questions from in until EOF or "exit".
+// The AI client is in an agent session, so each answer builds on the data
+// the previous investigations gathered.
+func runFollowUpSession(in io.Reader, ask func(question string) (string, error)) {
+	fmt.Println()
+	fmt.Println("Ask a follow-up question, or type 'exit' to end the session.")
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Print("follow-up> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return
+		}
+
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" {
+			continue
+		}
+		lower := strings.ToLower(input)
+		if lower == "exit" || lower == "quit" || lower == "/quit" || lower == "/exit" {
+			return
+		}
+
+		response, err := ask(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		fmt.Println(response)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(askCmd)
 
@@ -1530,7 +1643,11 @@ func init() {
 	askCmd.Flags().String("policy-arn", "", "Scope IAM query to a specific policy ARN")
 	askCmd.Flags().Bool("discovery", false, "Run comprehensive infrastructure discovery (all services)")
 	askCmd.Flags().Bool("compliance", false, "Generate compliance report showing all services, ports, and protocols")
-	askCmd.Flags().String("profile", "", "AWS profile to use for infrastructure queries")
+	askCmd.Flags().Bool("account-summary", false, "Print a one-page AWS account overview: resource counts, cost, firing alarms, public exposure and recent changes")
+	askCmd.Flags().String("profile", "", "AWS profile to use for infrastructure queries (overrides AWS_PROFILE and config)")
+	askCmd.Flags().String("region", "", "AWS region to use for infrastructure queries (overrides AWS_REGION, AWS_DEFAULT_REGION and config)")
+	askCmd.Flags().String("since", "", "Start of the investigation window for logs and metrics: a lookback such as 2h or 3d, an RFC3339 time, or a date")
+	askCmd.Flags().String("until", "", "End of the investigation window (RFC3339 time, date, or lookback such as 1h); defaults to now")
 	askCmd.Flags().String("gcp-project", "", "GCP project ID to use for infrastructure queries")
 	askCmd.Flags().String("azure-subscription", "", "Azure subscription ID to use for infrastructure queries")
 	askCmd.Flags().String("workspace", "", "Terraform workspace to use for infrastructure queries")
@@ -1550,6 +1667,17 @@ func init() {
 	askCmd.Flags().String("minimax-model", "", "MiniMax model to use (overrides config)")
 	askCmd.Flags().String("github-model", "", "GitHub Models model to use (overrides config)")
 	askCmd.Flags().Bool("agent-trace", false, "Show detailed coordinator agent lifecycle logs (overrides config)")
+	askCmd.Flags().StringSlice("agents", nil, "Always spawn these agent types, e.g. cost,security, plus the agents they depend on, whatever the decision tree matches")
+	askCmd.Flags().StringSlice("no-agents", nil, "Never spawn these agent types, e.g. metrics, even when the decision tree matches them")
+	askCmd.Flags().Bool("explain", false, "Print the decision tree path, matched keywords and the agents spawned with their operations")
+	askCmd.Flags().StringSlice("regions", nil, "AWS regions to scan during service discovery, e.g. us-east-1,eu-west-1 (overrides aws.regions)")
+	askCmd.Flags().StringSlice("services", nil, "Only run these discovery service checks, e.g. ec2,lambda,rds or check_iot* (overrides aws.discovery.include; aws.discovery.exclude still applies)")
+	askCmd.Flags().Bool("resume", false, "Reuse service checks recorded in the discovery checkpoint instead of re-running them (entries older than aws.discovery_checkpoint_max_age are re-checked)")
+	askCmd.Flags().Bool("allow-mutations", false, "Allow confirmed AWS write operations (restart_ecs_service, update_lambda_env, set_asg_desired_capacity); every call is audit logged")
+	askCmd.Flags().Bool("dry-run", false, "Print the AWS CLI commands the agent would run instead of executing them")
+	askCmd.Flags().Bool("follow-up", false, "After answering, keep reading follow-up questions from stdin; agent investigations reuse the data already gathered instead of starting over")
+	askCmd.Flags().String("output", "text", "Output format for agent investigations: text, json (structured findings for scripts) or markdown (a shareable report)")
+	askCmd.Flags().Bool("redact", false, "Replace account IDs, private IPs and access key IDs in agent investigation output with stable placeholders (<ACCOUNT_1>, <IP_1>) so it is safe to share")
 	askCmd.Flags().Bool("maker", false, "Generate an AWS, GCP, Azure, Cloudflare, Digital Ocean, Hetzner, Oracle, Vercel, Railway, or Verda plan (JSON) for infrastructure changes")
 	askCmd.Flags().Bool("destroyer", false, "Allow destructive operations when using --maker (requires explicit confirmation in UI/workflow)")
 	askCmd.Flags().Bool("apply", false, "Apply an approved maker plan (reads from stdin unless --plan-file is provided)")
@@ -1735,6 +1863,24 @@ func resolveMiniMaxKey(flagValue string) string {
 	return ""
 }
 
+func resolveOpenRouterKey(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if key := viper.GetString("ai.providers.openrouter.api_key"); key != "" {
+		return key
+	}
+	if envName := viper.GetString("ai.providers.openrouter.api_key_env"); envName != "" {
+		if envVal := os.Getenv(envName); envVal != "" {
+			return envVal
+		}
+	}
+	if envVal := os.Getenv("OPENROUTER_API_KEY"); envVal != "" {
+		return envVal
+	}
+	return ""
+}
+
 func maybeRunTerraformCommand(ctx context.Context, question string, tfClient *tfclient.Client) (bool, error) {
 	q := strings.ToLower(strings.TrimSpace(question))
 	if q == "" {
@@ -2192,6 +2338,8 @@ func handleCloudflareQuery(ctx context.Context, question string, debug bool) err
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString("ai.api_key")
 	}
@@ -2338,6 +2486,8 @@ func handleDigitalOceanQuery(ctx context.Context, question string, debug bool) e
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString("ai.api_key")
 	}
@@ -2400,6 +2550,8 @@ func handleTencentQuery(ctx context.Context, question string, debug bool) error
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString("ai.api_key")
 	}
@@ -2518,6 +2670,8 @@ func handleHetznerQuery(ctx context.Context, question string, debug bool) error
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString("ai.api_key")
 	}
@@ -2577,6 +2731,8 @@ func handleOracleQuery(ctx context.Context, question string, debug bool) error {
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString("ai.api_key")
 	}
@@ -2657,6 +2813,8 @@ func handleVercelQuery(ctx context.Context, question string, debug bool) error {
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString(fmt.Sprintf("ai.providers.%s.api_key", provider))
 	}
@@ -2794,6 +2952,8 @@ func handleFlyioQuery(ctx context.Context, question string, debug bool) error {
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString(fmt.Sprintf("ai.providers.%s.api_key", provider))
 	}
@@ -2923,6 +3083,8 @@ func handleRailwayQuery(ctx context.Context, question string, debug bool) error
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString(fmt.Sprintf("ai.providers.%s.api_key", provider))
 	}
@@ -3071,6 +3233,8 @@ func handleVerdaQuery(ctx context.Context, question string, debug bool) error {
 		apiKey = resolveDeepSeekKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString(fmt.Sprintf("ai.providers.%s.api_key", provider))
 	}
@@ -3931,6 +4095,21 @@ func isClankerCloudQuestion(questionLower string) bool {
 	return false
 }
 
+// printAccountSummary handles `ask --account-summary`: a one-page overview of
+// the AWS account, without a question or an AI call.
+func printAccountSummary(ctx context.Context, profile string, debug bool) error {
+	awsClient, err := aws.NewClientWithProfileAndDebug(ctx, resolveAWSProfile(profile), debug)
+	if err != nil {
+		return fmt.Errorf("failed to create AWS client: %w", err)
+	}
+	summary, err := awsClient.BuildAccountSummary(ctx, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Print(summary)
+	return nil
+}
+
 // handleHermesQuery delegates a question to the Hermes agent and prints the response.
 // When an AWS profile is available, it gathers infrastructure context first so the
 // agent can answer questions about the user's environment.
@@ -243,6 +243,8 @@ func handleGeneralCfQuery(ctx context.Context, client *cloudflare.Client, questi
 		apiKey = resolveCohereKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	default:
 		apiKey = viper.GetString(fmt.Sprintf("ai.providers.%s.api_key", aiProfile))
 	}
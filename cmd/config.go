@@ -100,6 +100,11 @@ ai:
       model: MiniMax-M2.5
       api_key_env: MINIMAX_API_KEY
 
+    openrouter:
+      model: anthropic/claude-3.5-sonnet
+      api_key_env: OPENROUTER_API_KEY
+      # base_url: https://openrouter.ai/api/v1
+
 # Infrastructure Providers Configuration
 infra:
   default_environment: dev             # Default environment to use
@@ -8,16 +8,20 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/bgdnvk/clanker/internal/ai"
+	"github.com/bgdnvk/clanker/internal/aws"
 	"github.com/bgdnvk/clanker/internal/azure"
 	"github.com/bgdnvk/clanker/internal/cloudflare"
 	"github.com/bgdnvk/clanker/internal/deploy"
+	"github.com/bgdnvk/clanker/internal/logging"
 	"github.com/bgdnvk/clanker/internal/maker"
+	"github.com/bgdnvk/clanker/internal/notify"
 	"github.com/bgdnvk/clanker/internal/openclaw"
 	"github.com/bgdnvk/clanker/internal/resourcedb"
 	"github.com/spf13/cobra"
@@ -34,8 +38,10 @@ Examples:
   clanker deploy https://github.com/user/repo --apply
   clanker deploy https://github.com/user/repo --target ec2
   clanker deploy https://github.com/user/repo --target eks
+  clanker deploy https://github.com/user/monorepo --sub-path packages/api
   clanker deploy https://github.com/user/repo --provider cloudflare
-  clanker deploy https://github.com/user/repo --profile prod`,
+  clanker deploy https://github.com/user/repo --profile prod
+  clanker deploy https://github.com/user/repo --no-cache`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		repoURL := args[0]
@@ -64,12 +70,23 @@ Examples:
 		deployTarget, _ := cmd.Flags().GetString("target")
 		sreMode, _ := cmd.Flags().GetBool("sre")
 		instanceType, _ := cmd.Flags().GetString("instance-type")
+		// Only pass explicit flags down so a repo's .clanker.yaml can fill
+		// the rest before the defaults apply.
+		if !cmd.Flags().Changed("target") {
+			deployTarget = ""
+		}
+		if !cmd.Flags().Changed("instance-type") {
+			instanceType = ""
+		}
 		newVPC, _ := cmd.Flags().GetBool("new-vpc")
 		gcpProject, _ := cmd.Flags().GetString("gcp-project")
 		azureSubscription, _ := cmd.Flags().GetString("azure-subscription")
 		doAccessToken, _ := cmd.Flags().GetString("do-token")
 		hetznerToken, _ := cmd.Flags().GetString("hetzner-token")
 		enforceImageDeploy, _ := cmd.Flags().GetBool("enforce-image-deploy")
+		subPath, _ := cmd.Flags().GetString("sub-path")
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+		interactive, _ := cmd.Flags().GetBool("interactive")
 
 		if strings.TrimSpace(localModelInferenceURL) != "" {
 			viper.Set("ai.providers.openai.local_model_inference_url", strings.TrimSpace(localModelInferenceURL))
@@ -77,11 +94,20 @@ Examples:
 
 		// 1. Clone + analyze
 		fmt.Fprintf(os.Stderr, "[deploy] cloning %s ...\n", repoURL)
-		rp, err := deploy.CloneAndAnalyze(ctx, repoURL)
+		rp, err := deploy.CloneAndAnalyzeWithOptions(ctx, repoURL, deploy.CloneOptions{
+			NoCache:       noCache,
+			CacheDir:      viper.GetString("deploy.clone_cache_dir"),
+			MaxCacheAge:   time.Duration(viper.GetInt("deploy.clone_cache_max_age_hours")) * time.Hour,
+			MaxCacheBytes: int64(viper.GetInt("deploy.clone_cache_max_mb")) << 20,
+		})
 		if err != nil {
 			return fmt.Errorf("analysis failed: %w", err)
 		}
-		defer os.RemoveAll(rp.ClonePath)
+		if rp.FromCache {
+			fmt.Fprintf(os.Stderr, "[deploy] using cached clone %s\n", rp.ClonePath)
+		} else {
+			defer os.RemoveAll(rp.ClonePath)
+		}
 
 		fmt.Fprintf(os.Stderr, "[deploy] analysis: %s\n", rp.Summary)
 
@@ -112,6 +138,8 @@ Examples:
 			apiKey = resolveCohereKey(cohereKey)
 		case "minimax":
 			apiKey = resolveMiniMaxKey(minimaxKey)
+		case "openrouter":
+			apiKey = resolveOpenRouterKey("")
 		default:
 			apiKey = viper.GetString("ai.api_key")
 		}
@@ -120,10 +148,8 @@ Examples:
 
 		aiClient := ai.NewClient(provider, apiKey, debug, aiProfile)
 
-		// log helper
-		logf := func(format string, args ...any) {
-			fmt.Fprintf(os.Stderr, format+"\n", args...)
-		}
+		// Deploy progress is normal-level output: --log-level quiet hides it.
+		logf := logging.Infof
 		if sreMode {
 			logf("[deploy] --sre requested; planning a long-running Clanker SRE agent with heartbeat verification")
 		}
@@ -141,6 +167,21 @@ Examples:
 			InstanceType: instanceType,
 			NewVPC:       newVPC,
 			SREOnly:      sreMode,
+			SubPath:      subPath,
+
+			FileBudgetTokens: viper.GetInt("intelligence.file_budget_tokens"),
+			MaxExploreRounds: viper.GetInt("intelligence.max_explore_rounds"),
+			AnalysisAsk:      aiClient.WithModelRole(aws.ModelRoleAnalysis).AskPrompt,
+			Notifier:         notify.FromConfig(),
+
+			ArchitectCandidates: viper.GetInt("intelligence.architect_candidates"),
+		}
+		if interactive {
+			// Let the user make the cost vs. simplicity call between candidates.
+			if deployOpts.ArchitectCandidates < 2 {
+				deployOpts.ArchitectCandidates = 3
+			}
+			deployOpts.ChooseArchitecture = deploy.PromptForArchitecture
 		}
 		// Run-specific id so resource names get a fresh short-hash suffix each deploy.
 		deployOpts.DeployID = time.Now().UTC().Format(time.RFC3339Nano)
@@ -162,6 +203,12 @@ Examples:
 			deployOpts.DOToken = tok
 		}
 
+		// Pass Cloudflare credentials for infra scan if targeting Cloudflare
+		if strings.EqualFold(strings.TrimSpace(targetProvider), "cloudflare") {
+			deployOpts.CFToken = cloudflare.ResolveAPIToken()
+			deployOpts.CFAccountID = cloudflare.ResolveAccountID()
+		}
+
 		// Pass Hetzner token for infra scan if targeting Hetzner
 		if strings.EqualFold(strings.TrimSpace(targetProvider), "hetzner") {
 			tok := strings.TrimSpace(hetznerToken)
@@ -352,7 +399,7 @@ Examples:
 			requiredLaunchOps = []string{"ecs create-service", "ecs run-task"}
 		case "app-runner":
 			requiredLaunchOps = []string{"apprunner create-service"}
-		case "lambda":
+		case "lambda", "lambda-apigw":
 			requiredLaunchOps = []string{"lambda create-function"}
 		case "s3-cloudfront":
 			requiredLaunchOps = []string{"s3api create-bucket", "cloudfront create-distribution"}
@@ -682,7 +729,6 @@ Examples:
 				}(),
 			}
 
-			const maxRepairRounds = 3
 			currentValidation := triage.Hard
 			currentPlanJSON := string(planJSON)
 
@@ -732,6 +778,10 @@ Examples:
 					logf("[deploy] repair: focusing on %d structural issue(s), %d user-data issue(s) handled separately", len(structuralIssues), len(udIssues))
 				}
 			}
+			// Cap the loop and stop early when a repair brings back the
+			// exact issues of an earlier round instead of oscillating.
+			repairTracker := deploy.NewValidationTracker(viper.GetInt("intelligence.max_validation_attempts"), currentValidation)
+			maxRepairRounds := repairTracker.MaxAttempts()
 			for r := 1; r <= maxRepairRounds; r++ {
 				if currentValidation == nil || len(currentValidation.Issues) == 0 {
 					break // all issues resolved (e.g. by micro-repair)
@@ -784,12 +834,17 @@ Examples:
 					}
 					currentValidation = invariants
 					currentPlanJSON = string(repairedJSON)
-					if r == maxRepairRounds {
+					convErr := repairTracker.Record(invariants)
+					if convErr != nil {
+						logf("[deploy] warning: repair stopped: %v", convErr)
+					}
+					if r == maxRepairRounds || convErr != nil {
 						if applyMode {
 							logf("[deploy] warning: invariants still failing after final repair round (issues=%d); continuing so execution/self-heal can proceed", len(invariants.Issues))
 						} else {
 							logf("[deploy] warning: invariants still failing after final repair round; continuing in plan-only mode")
 						}
+						break
 					}
 					continue
 				}
@@ -833,8 +888,12 @@ Examples:
 						logf("[deploy]   issue: %s", strings.TrimSpace(issue))
 					}
 				}
+				convErr := repairTracker.Record(currentValidation)
+				if convErr != nil {
+					logf("[deploy] warning: repair stopped: %v", convErr)
+				}
 
-				if r == maxRepairRounds {
+				if r == maxRepairRounds || convErr != nil {
 					issueCount := 0
 					if currentValidation != nil {
 						issueCount = len(currentValidation.Issues)
@@ -844,6 +903,7 @@ Examples:
 					} else {
 						logf("[deploy] warning: plan is still LLM-invalid after repair (issues=%d), but deterministic checks passed; returning plan in plan-only mode", issueCount)
 					}
+					break
 				}
 			}
 		}
@@ -1131,6 +1191,9 @@ Examples:
 			logf("[deploy] warning: %d shell-style placeholder token(s) remain; continuing without hard fail so self-healing/runtime binding can resolve them", remaining)
 		}
 
+		// Tear down only what this plan creates.
+		intel.RollbackPlan = intel.RollbackPlan.ForPlan(plan)
+
 		planJSON, err = json.MarshalIndent(plan, "", "  ")
 		if err != nil {
 			return err
@@ -1245,6 +1308,11 @@ Examples:
 
 		// apply mode: execute the plan in phases
 		fmt.Fprintf(os.Stderr, "[deploy] applying plan (%d commands)...\n", len(plan.Commands))
+		if path, err := writeRollbackPlan(intel.RollbackPlan); err != nil {
+			fmt.Fprintf(os.Stderr, "[deploy] warning: rollback plan not saved: %v\n", err)
+		} else if path != "" {
+			fmt.Fprintf(os.Stderr, "[deploy] rollback plan saved; undo this deployment with: clanker ask --apply --destroyer --plan-file %s\n", path)
+		}
 
 		// Split plan: infrastructure first, then app deployment (after Docker build)
 		infraPlan, appPlan := splitPlanAtDockerBuild(plan)
@@ -1388,26 +1456,30 @@ Examples:
 			if httpsURL != "" {
 				baseURL = httpsURL
 			}
-			path := "/health"
-			if openclaw.Detect(strings.TrimSpace(baseQuestion), rp.RepoURL) {
-				path = "/"
+			healthTimeout := deploy.DefaultHealthCheckTimeout
+			if secs := viper.GetInt("deploy.health_timeout_seconds"); secs > 0 {
+				healthTimeout = time.Duration(secs) * time.Second
 			}
-			if intel.DeepAnalysis != nil && strings.TrimSpace(intel.DeepAnalysis.HealthEndpoint) != "" {
-				path = strings.TrimSpace(intel.DeepAnalysis.HealthEndpoint)
-				if !strings.HasPrefix(path, "/") {
-					path = "/" + path
-				}
+			healthCtx, cancelHealth := context.WithTimeout(ctx, healthTimeout)
+			// OpenClaw serves its control UI at "/" and has no separate health route.
+			var health *deploy.DeploymentHealth
+			if openclaw.Detect(strings.TrimSpace(baseQuestion), rp.RepoURL) {
+				health = deploy.VerifyDeploymentPath(healthCtx, baseURL, "/")
+			} else {
+				health = deploy.VerifyDeployment(healthCtx, baseURL, intel.DeepAnalysis)
 			}
-			endpoint := strings.TrimRight(baseURL, "/") + path
-			if err := maker.VerifyDeployment(ctx, endpoint, 6*time.Minute, os.Stdout); err != nil {
-				// Common fallback: app has no /health.
-				fallback := strings.TrimRight(baseURL, "/") + "/"
-				if err2 := maker.VerifyDeployment(ctx, fallback, 3*time.Minute, os.Stdout); err2 != nil {
-					fmt.Fprintf(os.Stderr, "[deploy] health check failed: %v\n", err)
-					fmt.Fprintf(os.Stderr, "[deploy] tip: check EC2 instance logs via SSM Session Manager\n")
-					return fmt.Errorf("deployment verification failed: %w", err2)
-				}
+			cancelHealth()
+			intel.Health = health
+			if !health.Healthy {
+				fmt.Fprintf(os.Stderr, "[deploy] health check failed for %s: %s\n", health.URL, health.Error)
+				fmt.Fprintf(os.Stderr, "[deploy] tip: check EC2 instance logs via SSM Session Manager\n")
+				reportPostDeployCheck(ctx, intel, targetProfile, region)
+				return fmt.Errorf("deployment verification failed: %s", health.Error)
 			}
+			fmt.Fprintf(os.Stderr, "[deploy] %s returned %d in %s (attempts: %d)\n", health.URL, health.StatusCode, health.Latency.Round(time.Millisecond), health.Attempts)
+		}
+		if strings.EqualFold(strings.TrimSpace(targetProvider), "aws") {
+			reportPostDeployCheck(ctx, intel, targetProfile, region)
 		}
 
 		// Print deployment summary with endpoint
@@ -1456,6 +1528,55 @@ Examples:
 }
 
 // resolveAWSProfile picks the aws profile from flag, config, or default
+// reportPostDeployCheck inspects the created resources with the
+// investigation operations and prints what they found. It only reports;
+// the HTTP health check decides whether the deploy failed.
+// writeRollbackPlan saves the rollback as a maker plan under
+// deploy.rollback_dir (default ~/.clanker/rollbacks) and returns its path, or
+// "" when there is nothing to roll back.
+func writeRollbackPlan(rollback *deploy.RollbackPlan) (string, error) {
+	if rollback == nil || len(rollback.Steps) == 0 {
+		return "", nil
+	}
+	dir := strings.TrimSpace(viper.GetString("deploy.rollback_dir"))
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".clanker", "rollbacks")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(rollback.MakerPlan(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, rollback.Prefix+".json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func reportPostDeployCheck(ctx context.Context, intel *deploy.IntelligenceResult, awsProfile, region string) {
+	fmt.Fprintf(os.Stderr, "[deploy] checking the deployed resources...\n")
+	report, err := deploy.PostDeployCheck(ctx, intel, &aws.AIProfile{AWSProfile: awsProfile, Region: region})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[deploy] warning: post-deploy check skipped: %v\n", err)
+		return
+	}
+	for _, finding := range report.Findings() {
+		fmt.Fprintf(os.Stderr, "[deploy]   - %s\n", finding)
+	}
+	if report.Healthy {
+		fmt.Fprintf(os.Stderr, "[deploy] %s resources look healthy (%d checks)\n", report.Prefix, len(report.Checks))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[deploy] warning: %s is deployed but not healthy; investigate with: clanker ask \"why is %s unhealthy\"\n", report.Prefix, report.Prefix)
+}
+
 func resolveAWSProfile(flag string) string {
 	if flag != "" {
 		return flag
@@ -2208,10 +2329,13 @@ func init() {
 	deployCmd.Flags().String("github-model", "", "GitHub Models model to use (overrides config)")
 	deployCmd.Flags().Bool("apply", false, "Apply the plan immediately after generation")
 	deployCmd.Flags().String("provider", "aws", "Cloud provider: aws, gcp, azure, cloudflare, digitalocean, or hetzner")
-	deployCmd.Flags().String("target", "fargate", "Deployment target: fargate (default), ec2, or eks")
+	deployCmd.Flags().String("target", "fargate", "Deployment target: fargate (default), ec2, or eks; overrides deploy.target in the repo's .clanker.yaml")
 	deployCmd.Flags().Bool("sre", false, "Deploy only a low-cost Clanker SRE observer agent")
-	deployCmd.Flags().String("instance-type", "t3.small", "EC2 instance type (only used with --target ec2)")
+	deployCmd.Flags().String("instance-type", "t3.small", "EC2 instance type (only used with --target ec2); overrides deploy.instance_type in the repo's .clanker.yaml")
 	deployCmd.Flags().Bool("new-vpc", false, "Create a new VPC instead of using default")
+	deployCmd.Flags().String("sub-path", "", "Monorepo workspace to deploy, relative to the repo root (e.g. packages/api)")
+	deployCmd.Flags().Bool("no-cache", false, "Clone the repo fresh instead of reusing the cached clone (deploy.clone_cache_dir)")
+	deployCmd.Flags().Bool("interactive", false, "Have the architect propose 2-3 candidate architectures and choose one before the plan is generated (intelligence.architect_candidates sets how many)")
 	deployCmd.Flags().Bool("enforce-image-deploy", false, "Force ECR image-based deploy path (avoid docker build-on-EC2 user-data)")
 	deployCmd.Flags().String("gcp-project", "", "GCP project ID (required for --provider gcp apply)")
 	deployCmd.Flags().String("azure-subscription", "", "Azure subscription ID (required for --provider azure apply)")
@@ -1667,6 +1667,8 @@ func newConfiguredAIClient(debug bool) *ai.Client {
 		apiKey = resolveCohereKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	case "github-models":
 		apiKey = ""
 	default:
@@ -2711,6 +2711,8 @@ func createAIClient(debug bool) (*ai.Client, error) {
 		apiKey = resolveCohereKey("")
 	case "minimax":
 		apiKey = resolveMiniMaxKey("")
+	case "openrouter":
+		apiKey = resolveOpenRouterKey("")
 	}
 
 	if debug {
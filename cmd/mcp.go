@@ -1300,6 +1300,8 @@ func mcpResolveProviderKey(provider string) string {
 		return resolveDeepSeekKey("")
 	case "minimax":
 		return resolveMiniMaxKey("")
+	case "openrouter":
+		return resolveOpenRouterKey("")
 	default:
 		return viper.GetString(fmt.Sprintf("ai.providers.%s.api_key", provider))
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/bgdnvk/clanker/internal/gcp"
 	"github.com/bgdnvk/clanker/internal/hetzner"
 	"github.com/bgdnvk/clanker/internal/linear"
+	"github.com/bgdnvk/clanker/internal/logging"
 	"github.com/bgdnvk/clanker/internal/notion"
 	"github.com/bgdnvk/clanker/internal/oracle"
 	"github.com/bgdnvk/clanker/internal/railway"
@@ -66,6 +67,7 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.clanker.yaml)")
 	rootCmd.PersistentFlags().Bool("debug", false, "enable debug output (shows progress + internal diagnostics)")
+	rootCmd.PersistentFlags().String("log-level", "", "diagnostic output: quiet, normal (default), verbose (each operation) or debug (commands and raw output; same as --debug)")
 	rootCmd.PersistentFlags().Bool("local-mode", true, "enable local mode with rate limiting to prevent system overload (default: true)")
 	rootCmd.PersistentFlags().Int("local-delay", 100, "delay in milliseconds between calls in local mode (default 100ms)")
 
@@ -79,6 +81,7 @@ func init() {
 		flag string
 	}{
 		{"debug", "debug"},
+		{"log_level", "log-level"},
 		{"local_mode", "local-mode"},
 		{"local_delay_ms", "local-delay"},
 		{"backend.api_key", "api-key"},
@@ -197,6 +200,10 @@ func initConfig() {
 
 	viper.AutomaticEnv()
 
+	if _, err := logging.ParseLevel(viper.GetString("log_level")); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v; using normal\n", err)
+	}
+
 	if err := viper.ReadInConfig(); err == nil {
 		if err := hardenUserConfigFile(viper.ConfigFileUsed()); err != nil && viper.GetBool("debug") {
 			fmt.Fprintf(os.Stderr, "warning: failed to secure config file permissions: %v\n", err)
@@ -452,6 +452,8 @@ func resolveAIKeyForProfile(profile string) string {
 		return resolveCohereKey("")
 	case "minimax":
 		return resolveMiniMaxKey("")
+	case "openrouter":
+		return resolveOpenRouterKey("")
 	default:
 		return viper.GetString(fmt.Sprintf("ai.providers.%s.api_key", profile))
 	}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/bgdnvk/clanker/internal/agent/coordinator"
 	dt "github.com/bgdnvk/clanker/internal/agent/decisiontree"
+	"github.com/bgdnvk/clanker/internal/agent/memory"
 	"github.com/bgdnvk/clanker/internal/agent/model"
 	"github.com/bgdnvk/clanker/internal/agent/semantic"
 	awsclient "github.com/bgdnvk/clanker/internal/aws"
+	"github.com/bgdnvk/clanker/internal/notify"
 	"github.com/spf13/viper"
 )
 
@@ -21,6 +25,7 @@ type (
 	MetricsData      = model.MetricsData
 	LogData          = model.LogData
 	ErrorPatterns    = model.ErrorPatterns
+	ErrorCategory    = model.ErrorCategory
 	QueryIntent      = model.QueryIntent
 	QueryContext     = model.QueryContext
 	HealthStatus     = model.HealthStatus
@@ -30,27 +35,139 @@ type (
 	AWSFunctionCall  = model.AWSFunctionCall
 	ChainOfThought   = model.ChainOfThought
 	AgentContext     = model.AgentContext
+	AgentEvent       = model.AgentEvent
+	AgentFailure     = model.AgentFailure
+	CoordinatorTrace = model.CoordinatorTrace
 	SemanticAnalyzer = semantic.Analyzer
 	DecisionTree     = dt.Tree
 	DecisionNode     = dt.Node
 	LLMOperation     = awsclient.LLMOperation
 )
 
+const (
+	defaultMaxSteps        = 3 // Kept small for faster decisions
+	defaultParallelTimeout = coordinator.DefaultParallelTimeout
+)
+
+// AgentOptions overrides investigation limits for a single InvestigateQuery call.
+// Zero values fall back to config (agent.max_steps, agent.parallel_timeout_seconds)
+// and then to the built-in defaults.
+//
+// ProgressChan, when set, receives an AgentEvent as each parallel agent starts,
+// completes or fails and as each reasoning step is recorded. Sends never block;
+// events are dropped if the consumer falls behind. The channel is closed when
+// the investigation returns.
+//
+// TimeRange bounds every log and metric operation. When zero it comes from
+// agent.since / agent.until (set by --since / --until), and otherwise from the
+// query's semantic time frame.
+//
+// Prior, when set, is a finished investigation to extend with a follow-up
+// question instead of starting over; see Session.
+//
+// ForceAgents spawns those agent types (and the agents providing data they
+// require) even when the decision tree does not match them; SuppressAgents
+// never spawns those. When nil they come from agent.force_agents and
+// agent.suppress_agents (set by --agents / --no-agents).
+type AgentOptions struct {
+	MaxSteps        int
+	ParallelTimeout time.Duration
+	ProgressChan    chan AgentEvent
+	TimeRange       awsclient.TimeRange
+	Prior           *AgentContext
+	ForceAgents     []string
+	SuppressAgents  []string
+}
+
+// ValidateAgentTypes returns an error naming the first unknown agent type.
+func ValidateAgentTypes(names []string) error {
+	return coordinator.ValidateAgentTypes(names)
+}
+
 // Agent represents the intelligent context-gathering agent
 type Agent struct {
 	client       *awsclient.Client
 	debug        bool
 	maxSteps     int
 	aiDecisionFn func(context.Context, string) (string, error)
+	memory       *memory.AgentMemory
+	memoryPath   string
+	notifier     notify.Notifier
+	// redactor is set when agent.redact is on; nil leaves output untouched.
+	redactor *redactor
 }
 
 // NewAgent creates a new intelligent agent for context gathering
 func NewAgent(client *awsclient.Client, debug bool) *Agent {
-	return &Agent{
+	a := &Agent{
 		client:   client,
 		debug:    debug,
-		maxSteps: 3, // Reduced to 3 steps for faster decisions
+		maxSteps: configuredMaxSteps(),
+		notifier: notify.FromConfig(),
+	}
+	if viper.GetBool("agent.redact") {
+		a.redactor = newRedactor()
+	}
+	a.memoryPath = configuredMemoryPath()
+	if a.memoryPath != "" {
+		a.memory = memory.Load(a.memoryPath)
+	}
+	return a
+}
+
+// configuredMemoryPath reads agent.memory_path, expanding a leading "~/".
+// An empty value disables persistent memory.
+func configuredMemoryPath() string {
+	path := strings.TrimSpace(viper.GetString("agent.memory_path"))
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// configuredMaxSteps reads agent.max_steps, defaulting to defaultMaxSteps.
+func configuredMaxSteps() int {
+	if steps := viper.GetInt("agent.max_steps"); steps > 0 {
+		return steps
+	}
+	return defaultMaxSteps
+}
+
+// configuredParallelTimeout reads agent.parallel_timeout_seconds, falling back to
+// the legacy agent.timeout key and then to defaultParallelTimeout.
+func configuredParallelTimeout() time.Duration {
+	if secs := viper.GetInt("agent.parallel_timeout_seconds"); secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if secs := viper.GetInt("agent.timeout"); secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultParallelTimeout
+}
+
+// resolve fills unset options from the agent and config defaults.
+func (o AgentOptions) resolve(a *Agent) AgentOptions {
+	if o.MaxSteps <= 0 {
+		o.MaxSteps = a.maxSteps
+		if o.MaxSteps <= 0 {
+			o.MaxSteps = configuredMaxSteps()
+		}
+	}
+	if o.ParallelTimeout <= 0 {
+		o.ParallelTimeout = configuredParallelTimeout()
+	}
+	if o.TimeRange.IsZero() {
+		o.TimeRange = awsclient.ConfiguredTimeRange()
+	}
+	if o.ForceAgents == nil {
+		o.ForceAgents = viper.GetStringSlice("agent.force_agents")
+	}
+	if o.SuppressAgents == nil {
+		o.SuppressAgents = viper.GetStringSlice("agent.suppress_agents")
 	}
+	return o
 }
 
 // SetAIDecisionFunction sets the AI decision making function
@@ -58,26 +175,82 @@ func (a *Agent) SetAIDecisionFunction(fn func(context.Context, string) (string,
 	a.aiDecisionFn = fn
 }
 
+// SetNotifier replaces the notifier configured by notifications.sink.
+func (a *Agent) SetNotifier(n notify.Notifier) {
+	a.notifier = n
+}
+
 // InvestigateQuery intelligently investigates a query using decision trees and parallel agents
 func (a *Agent) InvestigateQuery(ctx context.Context, query string) (*AgentContext, error) {
+	return a.InvestigateQueryWithOptions(ctx, query, AgentOptions{})
+}
+
+// InvestigateQueryWithOptions is InvestigateQuery with per-call overrides for
+// the step limit and parallel agent timeout.
+//
+// With opts.Prior set the query is treated as a follow-up: the semantic
+// analyzer compares it with the prior question, and when the window and
+// services are unchanged the prior's gathered data is reused and only agents
+// that have not run yet are spawned. Prior is extended in place and returned.
+//
+// A new investigation sends a completion notification, success or failure,
+// through the configured notifier; follow-ups are interactive and do not.
+func (a *Agent) InvestigateQueryWithOptions(ctx context.Context, query string, opts AgentOptions) (*AgentContext, error) {
+	startTime := time.Now()
+	agentCtx, err := a.investigate(ctx, query, opts)
+	if opts.Prior == nil {
+		notify.Send(ctx, a.notifier, investigationNotification(query, agentCtx, err, time.Since(startTime)))
+	}
+	return agentCtx, err
+}
+
+func (a *Agent) investigate(ctx context.Context, query string, opts AgentOptions) (*AgentContext, error) {
 	verbose := viper.GetBool("debug")
+	opts = opts.resolve(a)
+	startTime := time.Now()
 
 	// Perform semantic analysis on the query
 	semanticAnalyzer := semantic.NewAnalyzer()
 	queryIntent := semanticAnalyzer.AnalyzeQuery(query)
+	var followUp *semantic.FollowUp
+	if opts.Prior != nil {
+		f := semanticAnalyzer.CompareFollowUp(priorIntent(opts.Prior), query)
+		followUp = &f
+		queryIntent = f.Intent
+	}
 
-	agentCtx := &AgentContext{
-		OriginalQuery:  query,
-		CurrentStep:    0,
-		MaxSteps:       a.maxSteps,
-		GatheredData:   make(AWSData),
-		Decisions:      []AgentDecision{},
-		ChainOfThought: []ChainOfThought{},
-		ServiceData:    make(ServiceData),
-		Metrics:        make(MetricsData),
-		ServiceStatus:  make(map[string]string),
-		LastUpdateTime: time.Now(),
+	// Only --since/--until override an operation's own hours_back; the
+	// window implied by the question (or the previous turn) is a fallback.
+	timeRange := opts.TimeRange
+	ctx = awsclient.WithTimeRange(ctx, timeRange)
+	if timeRange.IsZero() && followUp != nil && !followUp.TimeFrameChanged {
+		timeRange = opts.Prior.TimeRange
 	}
+	if timeRange.IsZero() {
+		timeRange = awsclient.DefaultTimeRange(queryIntent.TimeFrame)
+	}
+	ctx = awsclient.WithDefaultTimeRange(ctx, timeRange)
+
+	var agentCtx *AgentContext
+	if followUp != nil {
+		agentCtx = continueContext(opts.Prior, query, opts, timeRange, followUp.TimeFrameChanged)
+	} else {
+		agentCtx = &AgentContext{
+			OriginalQuery:  query,
+			CurrentStep:    0,
+			MaxSteps:       opts.MaxSteps,
+			GatheredData:   make(AWSData),
+			Decisions:      []AgentDecision{},
+			ChainOfThought: []ChainOfThought{},
+			ServiceData:    make(ServiceData),
+			Metrics:        make(MetricsData),
+			ServiceStatus:  make(map[string]string),
+			LastUpdateTime: time.Now(),
+			Progress:       model.NewEventSink(opts.ProgressChan),
+			TimeRange:      timeRange,
+		}
+	}
+	defer agentCtx.Progress.Close()
 
 	// Add semantic analysis results to context
 	agentCtx.GatheredData["semantic_analysis"] = map[string]any{
@@ -86,6 +259,7 @@ func (a *Agent) InvestigateQuery(ctx context.Context, query string) (*AgentConte
 		"target_services": queryIntent.TargetServices,
 		"urgency":         queryIntent.Urgency,
 		"time_frame":      queryIntent.TimeFrame,
+		"time_range":      timeRange.String(),
 		"data_types":      queryIntent.DataTypes,
 	}
 
@@ -93,12 +267,25 @@ func (a *Agent) InvestigateQuery(ctx context.Context, query string) (*AgentConte
 	a.addThought(agentCtx, fmt.Sprintf("Starting investigation of query: '%s'", query), "analyze", "Query received, beginning analysis")
 	a.addThought(agentCtx, fmt.Sprintf("Semantic analysis: Intent=%s, Confidence=%.2f, Urgency=%s",
 		queryIntent.Primary, queryIntent.Confidence, queryIntent.Urgency), "analyze", "Performed semantic analysis")
+	if followUp != nil {
+		a.addThought(agentCtx, describeFollowUp(*followUp), "analyze", "Compared with the previous question")
+	}
+
+	if a.memory != nil {
+		if similar := a.memory.GetSimilarQueries(queryIntent, 3); len(similar) > 0 {
+			previous := make([]string, 0, len(similar))
+			for _, qc := range similar {
+				previous = append(previous, qc.Query)
+			}
+			a.addThought(agentCtx, fmt.Sprintf("Found %d similar previous queries: %s", len(similar), strings.Join(previous, "; ")), "analyze", "Recalled from agent memory")
+		}
+	}
 
 	if verbose {
 		fmt.Printf("🤖 Agent starting investigation of query: %s\n", query)
 		fmt.Printf("🧠 Semantic Analysis: Intent=%s (%.1f%% confidence), Urgency=%s, Services=%v\n",
 			queryIntent.Primary, queryIntent.Confidence*100, queryIntent.Urgency, queryIntent.TargetServices)
-		fmt.Printf("🎯 Maximum investigation steps: %d\n", a.maxSteps)
+		fmt.Printf("🎯 Maximum investigation steps: %d\n", opts.MaxSteps)
 	}
 
 	// Create agent coordinator with decision tree
@@ -106,9 +293,15 @@ func (a *Agent) InvestigateQuery(ctx context.Context, query string) (*AgentConte
 	if err != nil {
 		return agentCtx, fmt.Errorf("failed to create coordinator: %w", err)
 	}
+	coord.SetParallelTimeout(opts.ParallelTimeout)
+	coord.SetAgentOverrides(opts.ForceAgents, opts.SuppressAgents)
 
 	// Traverse decision tree to determine what agents to spawn
 	applicableNodes := coord.Analyze(query)
+	reusing := followUp != nil && followUp.Reusable()
+	if reusing {
+		applicableNodes = withoutGatheredAgents(applicableNodes, agentCtx.GatheredData)
+	}
 
 	if verbose {
 		fmt.Printf("🌳 Decision tree analysis: %d applicable nodes found\n", len(applicableNodes))
@@ -118,18 +311,18 @@ func (a *Agent) InvestigateQuery(ctx context.Context, query string) (*AgentConte
 	}
 
 	a.addThought(agentCtx, fmt.Sprintf("Decision tree identified %d applicable strategies", len(applicableNodes)), "analyze", "Determined parallel execution strategy")
+	if len(opts.ForceAgents) > 0 || len(opts.SuppressAgents) > 0 {
+		a.addThought(agentCtx, fmt.Sprintf("Agent overrides: forcing %v, suppressing %v", opts.ForceAgents, opts.SuppressAgents), "analyze", "Applied --agents / --no-agents")
+	}
+	spawn := len(applicableNodes) > 0 || len(opts.ForceAgents) > 0
 
 	// Spawn parallel agents based on decision tree
-	if len(applicableNodes) > 0 {
+	if spawn {
 		coord.SpawnAgents(ctx, applicableNodes)
 
 		// Wait for parallel agents to complete.
-		// Configurable via agent.timeout in config, defaults to 15s.
-		timeout := time.Duration(viper.GetInt("agent.timeout")) * time.Second
-		if timeout <= 0 {
-			timeout = 15 * time.Second
-		}
-		err := coord.WaitForCompletion(ctx, timeout)
+		// Configurable via agent.parallel_timeout_seconds or AgentOptions, defaults to 15s.
+		err := coord.WaitForCompletion(ctx, opts.ParallelTimeout)
 		if err != nil {
 			a.addThought(agentCtx, fmt.Sprintf("Some parallel agents failed or timed out: %v", err), "warning", "Proceeding with available data")
 			if verbose {
@@ -153,9 +346,14 @@ func (a *Agent) InvestigateQuery(ctx context.Context, query string) (*AgentConte
 				stats.Completed, stats.Failed)
 		}
 	}
+	agentCtx.Trace = coord.Trace()
+
+	if !spawn && reusing {
+		a.addThought(agentCtx, "Data gathered for the previous question covers this follow-up", "reuse", "Skipped data gathering")
+	}
 
 	// Fallback to traditional sequential approach if no parallel agents were spawned
-	if len(applicableNodes) == 0 {
+	if !spawn && !reusing {
 		a.addThought(agentCtx, "No specific parallel strategies identified, using sequential approach", "fallback", "Traditional investigation approach")
 
 		if verbose {
@@ -187,9 +385,123 @@ func (a *Agent) InvestigateQuery(ctx context.Context, query string) (*AgentConte
 	}
 	a.addThought(agentCtx, fmt.Sprintf("Investigation complete: %d data points gathered across %d steps", dataCount, agentCtx.CurrentStep), "summary", "Ready to analyze findings and provide response")
 
+	// Follow-ups reuse most of the prior data; scoring them again would
+	// record the same errors as a second check.
+	if followUp == nil {
+		a.assessServiceHealth(agentCtx, queryIntent, startTime)
+	}
+	a.rememberQuery(agentCtx, queryIntent, startTime)
+	agentCtx.Emit(AgentEvent{Type: model.EventInvestigationComplete, Message: fmt.Sprintf("%d data points gathered", dataCount)})
+
 	return agentCtx, nil
 }
 
+// investigationNotification summarizes a finished investigation in one line:
+// the query, the services investigated and how long it took.
+func investigationNotification(query string, agentCtx *AgentContext, err error, took time.Duration) notify.Notification {
+	took = took.Round(time.Second)
+	if err != nil {
+		return notify.Notification{Title: "Investigation failed", Summary: fmt.Sprintf("%q after %s: %v", query, took, err), Failed: true}
+	}
+	summary := fmt.Sprintf("%q in %s", query, took)
+	if agentCtx != nil {
+		if services := priorIntent(agentCtx).TargetServices; len(services) > 0 {
+			summary += "; services: " + strings.Join(services, ", ")
+		}
+		if agentCtx.Trace != nil && len(agentCtx.Trace.Agents) > 0 {
+			summary += fmt.Sprintf("; %d agents", len(agentCtx.Trace.Agents))
+		}
+	}
+	return notify.Notification{Title: "Investigation finished", Summary: summary}
+}
+
+// priorIntent is the semantic intent recorded on a finished investigation.
+func priorIntent(agentCtx *AgentContext) QueryIntent {
+	if semData, ok := agentCtx.GatheredData["semantic_analysis"].(map[string]any); ok {
+		if intent, ok := semData["intent"].(QueryIntent); ok {
+			return intent
+		}
+	}
+	return QueryIntent{}
+}
+
+// continueContext turns a finished investigation into the context for a
+// follow-up question. Gathered data, decisions and reasoning carry over
+// unless the window changed, in which case the data no longer applies; the
+// step budget, progress sink and trace start fresh.
+func continueContext(prior *AgentContext, query string, opts AgentOptions, timeRange awsclient.TimeRange, resetData bool) *AgentContext {
+	prior.PriorQueries = append(prior.PriorQueries, prior.OriginalQuery)
+	prior.OriginalQuery = query
+	prior.CurrentStep = 0
+	prior.MaxSteps = opts.MaxSteps
+	prior.Progress = model.NewEventSink(opts.ProgressChan)
+	prior.TimeRange = timeRange
+	prior.Trace = nil
+	prior.LastUpdateTime = time.Now()
+	if resetData || prior.GatheredData == nil {
+		prior.GatheredData = make(AWSData)
+		prior.ServiceData = make(ServiceData)
+		prior.Metrics = make(MetricsData)
+		prior.ServiceStatus = make(map[string]string)
+	}
+	return prior
+}
+
+// describeFollowUp is the chain-of-thought entry for a follow-up comparison.
+func describeFollowUp(f semantic.FollowUp) string {
+	switch {
+	case f.TimeFrameChanged:
+		return fmt.Sprintf("Follow-up asks about a different time frame (%s); gathering fresh data", f.Intent.TimeFrame)
+	case len(f.NewServices) > 0:
+		return fmt.Sprintf("Follow-up adds services %s; gathering data for them alongside what was already collected", strings.Join(f.NewServices, ", "))
+	case len(f.NewDataTypes) > 0:
+		return fmt.Sprintf("Follow-up needs %s on top of the previous data; reusing what was already gathered", strings.Join(f.NewDataTypes, ", "))
+	}
+	return "Follow-up is about the same services and window; reusing data gathered for the previous question"
+}
+
+// withoutGatheredAgents drops agent types whose results are already in
+// gathered, and nodes left with no agents to run.
+func withoutGatheredAgents(nodes []*DecisionNode, gathered AWSData) []*DecisionNode {
+	var remaining []*DecisionNode
+	for _, node := range nodes {
+		var agents []string
+		for _, name := range node.AgentTypes {
+			if _, ok := gathered[name]; !ok {
+				agents = append(agents, name)
+			}
+		}
+		if len(agents) == 0 {
+			continue
+		}
+		n := *node
+		n.AgentTypes = agents
+		remaining = append(remaining, &n)
+	}
+	return remaining
+}
+
+// rememberQuery records the finished investigation in persistent memory.
+// Gathered data is not stored; it can be large and is rarely JSON-friendly.
+func (a *Agent) rememberQuery(agentCtx *AgentContext, intent QueryIntent, started time.Time) {
+	if a.memory == nil {
+		return
+	}
+	a.memory.AddQueryContext(QueryContext{
+		Query:         agentCtx.OriginalQuery,
+		Timestamp:     started,
+		Intent:        intent,
+		ExecutionTime: time.Since(started),
+		Success:       len(agentCtx.GatheredData) > 1,
+	})
+	for _, service := range intent.TargetServices {
+		a.memory.LearnPattern(intent.Primary+":"+service, fmt.Sprintf("%s investigation of %s", intent.Primary, service), intent.DataTypes)
+	}
+	if err := a.memory.Save(a.memoryPath); err != nil && a.debug {
+		fmt.Printf("⚠️  Failed to save agent memory: %v\n", err)
+	}
+}
+
 // Helper functions
 
 // BuildDecisionPrompt creates a sophisticated prompt for AI-based decision making
@@ -274,7 +586,15 @@ func (a *Agent) BuildFinalContext(agentCtx *AgentContext) string {
 	var context strings.Builder
 
 	context.WriteString("=== INTELLIGENT AGENT INVESTIGATION RESULTS ===\n")
-	context.WriteString(fmt.Sprintf("Query: %s\n\n", agentCtx.OriginalQuery))
+	context.WriteString(fmt.Sprintf("Query: %s\n", agentCtx.OriginalQuery))
+	if len(agentCtx.PriorQueries) > 0 {
+		context.WriteString("Earlier questions in this session:\n")
+		for _, q := range agentCtx.PriorQueries {
+			context.WriteString(fmt.Sprintf("  - %s\n", q))
+		}
+	}
+	context.WriteString("\n")
+	context.WriteString(incompleteDataBanner(agentFailures(agentCtx)))
 
 	// Semantic analysis
 	if semanticData, exists := agentCtx.GatheredData["semantic_analysis"]; exists {
@@ -290,7 +610,7 @@ func (a *Agent) BuildFinalContext(agentCtx *AgentContext) string {
 	// Single pass over gathered data, categorized by type.
 	// Track which keys have been rendered to avoid duplication.
 	rendered := make(map[string]bool)
-	skipKeys := map[string]bool{"semantic_analysis": true, "_metadata": true}
+	skipKeys := map[string]bool{"semantic_analysis": true, "_metadata": true, "error_patterns": true, serviceHealthKey: true}
 
 	// Pass 1: Lambda error analysis (highlighted at top for visibility)
 	for key, data := range agentCtx.GatheredData {
@@ -324,6 +644,9 @@ func (a *Agent) BuildFinalContext(agentCtx *AgentContext) string {
 		}
 	}
 
+	// Logs are compressed to share the agent.log_context_tokens budget.
+	logBudget := logSourceBudget(countLogSources(agentCtx.GatheredData))
+
 	// Pass 2: Legacy log format (structured LogData slices)
 	for key, data := range agentCtx.GatheredData {
 		if skipKeys[key] || rendered[key] {
@@ -334,7 +657,7 @@ func (a *Agent) BuildFinalContext(agentCtx *AgentContext) string {
 				serviceName := strings.TrimSuffix(key, "_logs")
 				context.WriteString(fmt.Sprintf("=== %s SERVICE LOG ANALYSIS ===\n", strings.ToUpper(serviceName)))
 				for _, logGroupData := range logGroups {
-					writeLogGroupData(&context, logGroupData)
+					writeLogGroupData(&context, logGroupData, logBudget)
 				}
 				rendered[key] = true
 			}
@@ -349,14 +672,8 @@ func (a *Agent) BuildFinalContext(agentCtx *AgentContext) string {
 		if strings.HasSuffix(key, "_all_log_entries") {
 			serviceName := strings.TrimSuffix(key, "_all_log_entries")
 			if logs, ok := data.([]string); ok && len(logs) > 0 {
-				context.WriteString(fmt.Sprintf("=== %s RAW LOG ENTRIES ===\n", strings.ToUpper(serviceName)))
-				limit := 30
-				if len(logs) < limit {
-					limit = len(logs)
-				}
-				for _, log := range logs[:limit] {
-					context.WriteString(fmt.Sprintf("%s\n", log))
-				}
+				context.WriteString(fmt.Sprintf("=== %s LOG SUMMARY ===\n", strings.ToUpper(serviceName)))
+				context.WriteString(summarizeLogs(logs, logBudget))
 				context.WriteString("\n")
 			}
 			rendered[key] = true
@@ -403,6 +720,15 @@ func (a *Agent) BuildFinalContext(agentCtx *AgentContext) string {
 		context.WriteString("\n")
 	}
 
+	// Metric anomalies, as timestamps to correlate with log events
+	if notes := metricAnomalyNotes(agentCtx.GatheredData); len(notes) > 0 {
+		context.WriteString("=== METRIC ANOMALIES ===\n")
+		for _, note := range notes {
+			context.WriteString("- " + note + "\n")
+		}
+		context.WriteString("\n")
+	}
+
 	// Service status
 	if len(agentCtx.ServiceStatus) > 0 {
 		context.WriteString("=== SERVICE STATUS ===\n")
@@ -412,10 +738,22 @@ func (a *Agent) BuildFinalContext(agentCtx *AgentContext) string {
 		context.WriteString("\n")
 	}
 
+	// Service health, with what got worse since the last investigation
+	if reports, ok := agentCtx.GatheredData[serviceHealthKey].([]ServiceHealthReport); ok && len(reports) > 0 {
+		context.WriteString("=== SERVICE HEALTH ===\n")
+		context.WriteString(formatServiceHealth(reports))
+		context.WriteString("\n")
+	}
+
 	// Error analysis
 	if errorPatterns, exists := agentCtx.GatheredData["error_patterns"]; exists {
 		context.WriteString("=== ERROR ANALYSIS ===\n")
-		context.WriteString(fmt.Sprintf("%v\n\n", errorPatterns))
+		if patterns, ok := errorPatterns.(ErrorPatterns); ok {
+			context.WriteString(formatErrorPatterns(patterns))
+			context.WriteString("\n")
+		} else {
+			context.WriteString(fmt.Sprintf("%v\n\n", errorPatterns))
+		}
 	}
 
 	context.WriteString(fmt.Sprintf("Investigation completed in %d steps.\n", agentCtx.CurrentStep))
@@ -431,7 +769,43 @@ func (a *Agent) BuildFinalContext(agentCtx *AgentContext) string {
 		}
 	}
 
-	return context.String()
+	return a.redactor.redactSensitive(context.String())
+}
+
+// incompleteDataWarning heads the banner shown when agents failed.
+const incompleteDataWarning = "⚠️ INCOMPLETE DATA: some agents could not gather their data"
+
+// agentFailures returns the failed_agents list the coordinator recorded in
+// the aggregated _metadata.
+func agentFailures(agentCtx *AgentContext) []AgentFailure {
+	meta, ok := agentCtx.GatheredData["_metadata"].(AWSData)
+	if !ok {
+		return nil
+	}
+	failures, _ := meta["failed_agents"].([]AgentFailure)
+	return failures
+}
+
+// incompleteDataBanner tells the LLM which data is missing so it does not
+// report the absence of a problem (e.g. "no errors found") it never checked.
+func incompleteDataBanner(failures []AgentFailure) string {
+	if len(failures) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(incompleteDataWarning + ":\n")
+	for _, f := range failures {
+		b.WriteString(fmt.Sprintf("- %s agent: %s", f.Agent, f.Error))
+		if len(f.FailedOperations) > 0 {
+			b.WriteString(fmt.Sprintf(" (failed: %s)", strings.Join(f.FailedOperations, ", ")))
+		}
+		if len(f.SkippedOperations) > 0 {
+			b.WriteString(fmt.Sprintf(" (not run: %s)", strings.Join(f.SkippedOperations, ", ")))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Treat the data above as unavailable, not as healthy: do not conclude that there are no errors, alarms or issues in these areas. Tell the user which checks could not be completed.\n\n")
+	return b.String()
 }
 
 // writeDataValue writes any data value to the builder in a readable format.
@@ -443,8 +817,9 @@ func writeDataValue(b *strings.Builder, data any) {
 	}
 }
 
-// writeLogGroupData writes a structured log group entry.
-func writeLogGroupData(b *strings.Builder, lgd LogData) {
+// writeLogGroupData writes a structured log group entry, summarizing its
+// log lines to fit within budget tokens.
+func writeLogGroupData(b *strings.Builder, lgd LogData, budget int) {
 	if logGroup, exists := lgd["log_group"]; exists {
 		b.WriteString(fmt.Sprintf("Log Group: %s\n", logGroup))
 	}
@@ -460,23 +835,15 @@ func writeLogGroupData(b *strings.Builder, lgd LogData) {
 	if recentLogs, exists := lgd["recent_logs"]; exists {
 		if logs, ok := recentLogs.([]string); ok && len(logs) > 0 {
 			b.WriteString("\n--- Recent Log Entries ---\n")
-			limit := 20
-			if len(logs) < limit {
-				limit = len(logs)
-			}
-			for _, log := range logs[:limit] {
-				b.WriteString(fmt.Sprintf("%s\n", log))
-			}
+			b.WriteString(summarizeLogs(logs, budget/2))
 		}
 	}
 	if errorLogs, exists := lgd["error_logs"]; exists {
 		if logs, ok := errorLogs.([]string); ok && len(logs) > 0 {
+			// The error filter already picked these; keep each one verbatim
+			// even when it lacks the words summarizeLogs treats as errors.
 			b.WriteString("\n--- Error Log Entries ---\n")
-			limit := 10
-			if len(logs) < limit {
-				limit = len(logs)
-			}
-			for _, log := range logs[:limit] {
+			for _, log := range logs {
 				b.WriteString(fmt.Sprintf("ERROR: %s\n", log))
 			}
 		}
@@ -492,11 +859,9 @@ func writeLogGroupData(b *strings.Builder, lgd LogData) {
 	b.WriteString("\n")
 }
 
-// getErrorLogsFromGroup gets error logs from a specific log group
-func (a *Agent) getErrorLogsFromGroup(ctx context.Context, logGroup string) ([]string, error) {
-	// Get logs from the last hour with ERROR filter
-	endTime := time.Now()
-	startTime := endTime.Add(-1 * time.Hour)
+// getErrorLogsFromGroup gets ERROR logs between startTime and endTime from a
+// specific log group
+func (a *Agent) getErrorLogsFromGroup(ctx context.Context, logGroup string, startTime, endTime time.Time) ([]string, error) {
 
 	args := []string{
 		"logs", "filter-log-events",
@@ -508,7 +873,7 @@ func (a *Agent) getErrorLogsFromGroup(ctx context.Context, logGroup string) ([]s
 		"--output", "json",
 	}
 
-	output, err := a.client.ExecCLI(ctx, args)
+	output, err := a.execLogsCLI(ctx, args)
 	if err != nil {
 		return nil, err
 	}
@@ -545,7 +910,7 @@ func (a *Agent) getLogStreamsFromGroup(ctx context.Context, logGroup string) ([]
 		"--output", "json",
 	}
 
-	output, err := a.client.ExecCLI(ctx, args)
+	output, err := a.execLogsCLI(ctx, args)
 	if err != nil {
 		return nil, err
 	}
@@ -554,7 +919,7 @@ func (a *Agent) getLogStreamsFromGroup(ctx context.Context, logGroup string) ([]
 	var logData struct {
 		LogStreams []struct {
 			LogStreamName     string `json:"logStreamName"`
-			LastEventTime     int64  `json:"lastEventTime"`
+			LastEventTime     int64  `json:"lastEventTimestamp"`
 			LastIngestionTime int64  `json:"lastIngestionTime"`
 			StoredBytes       int64  `json:"storedBytes"`
 		} `json:"logStreams"`
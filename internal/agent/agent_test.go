@@ -3,6 +3,10 @@ package agent
 import (
 	"strings"
 	"testing"
+	"time"
+
+	awsclient "github.com/bgdnvk/clanker/internal/aws"
+	"github.com/spf13/viper"
 )
 
 func TestBuildFinalContext_NilContext(t *testing.T) {
@@ -145,3 +149,70 @@ func TestBuildFinalContext_ChainOfThought(t *testing.T) {
 		t.Error("expected thought content in output")
 	}
 }
+
+func TestAgentOptionsResolveTimeRange(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	if r := (AgentOptions{}).resolve(&Agent{}).TimeRange; !r.IsZero() {
+		t.Errorf("expected no configured window, got %+v", r)
+	}
+
+	viper.Set("agent.since", "2d")
+	if r := (AgentOptions{}).resolve(&Agent{}).TimeRange; r.Lookback != 48*time.Hour {
+		t.Errorf("expected agent.since to set a 2d lookback, got %+v", r)
+	}
+}
+
+func TestWithoutGatheredAgents(t *testing.T) {
+	nodes := []*DecisionNode{
+		{Name: "errors", AgentTypes: []string{"log", "metrics"}},
+		{Name: "latency", AgentTypes: []string{"metrics"}},
+	}
+	got := withoutGatheredAgents(nodes, AWSData{"metrics": AWSData{}})
+	if len(got) != 1 || got[0].Name != "errors" || len(got[0].AgentTypes) != 1 || got[0].AgentTypes[0] != "log" {
+		t.Fatalf("got %+v, want only the log agent of the errors node", got)
+	}
+	if len(nodes[0].AgentTypes) != 2 {
+		t.Error("the decision tree's nodes must not be modified")
+	}
+}
+
+func TestContinueContext(t *testing.T) {
+	prior := &AgentContext{
+		OriginalQuery: "why is the api slow",
+		CurrentStep:   3,
+		GatheredData:  AWSData{"metrics": AWSData{"p99": 1200}},
+		ServiceData:   ServiceData{},
+		Metrics:       MetricsData{},
+		ServiceStatus: map[string]string{},
+	}
+	window := awsclient.TimeRange{Lookback: time.Hour}
+
+	got := continueContext(prior, "show me the worst 5 requests", AgentOptions{MaxSteps: 2}, window, false)
+	if got.OriginalQuery != "show me the worst 5 requests" || got.CurrentStep != 0 || got.MaxSteps != 2 {
+		t.Errorf("got query=%q step=%d max=%d", got.OriginalQuery, got.CurrentStep, got.MaxSteps)
+	}
+	if len(got.PriorQueries) != 1 || got.PriorQueries[0] != "why is the api slow" {
+		t.Errorf("PriorQueries = %v", got.PriorQueries)
+	}
+	if _, ok := got.GatheredData["metrics"]; !ok {
+		t.Error("gathered data should carry over when the window is unchanged")
+	}
+
+	got = continueContext(got, "what about last week", AgentOptions{}, window, true)
+	if len(got.GatheredData) != 0 || len(got.PriorQueries) != 2 {
+		t.Errorf("a new window should drop gathered data: %v, %v", got.GatheredData, got.PriorQueries)
+	}
+}
+
+func TestBuildFinalContext_PriorQueries(t *testing.T) {
+	a := &Agent{}
+	ctx := &AgentContext{
+		OriginalQuery: "show me the worst 5 requests",
+		PriorQueries:  []string{"why is the api slow"},
+		GatheredData:  AWSData{},
+	}
+	out := a.BuildFinalContext(ctx)
+	if !strings.Contains(out, "Earlier questions in this session:\n  - why is the api slow") {
+		t.Errorf("prior questions missing from context:\n%s", out)
+	}
+}
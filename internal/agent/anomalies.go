@@ -0,0 +1,221 @@
+package agent
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// anomalyZScore is how many standard deviations from the series mean a
+	// value, or a change between neighbouring values, must be to count.
+	anomalyZScore = 2.5
+	// anomalyMinPoints is the shortest series worth scoring.
+	anomalyMinPoints = 5
+	// anomalyMaxPerSeries bounds the annotations for one metric; the
+	// strongest deviations are kept.
+	anomalyMaxPerSeries = 3
+)
+
+// Datapoint is one metric value at a point in time.
+type Datapoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Anomaly is a datapoint that deviated sharply from the rest of its series.
+type Anomaly struct {
+	Datapoint
+	// Kind is "spike" or "drop" for a value far from the mean, "jump" or
+	// "fall" for a sharp change from the previous value that held.
+	Kind string
+	// Baseline is the series mean, or the previous value for jump and fall.
+	Baseline float64
+	// Score is the deviation in standard deviations.
+	Score float64
+}
+
+// annotateAnomalies flags the datapoints that deviate sharply from the
+// series, oldest first. A value far from the mean (z-score) is a spike or
+// drop; a step the mean hides, such as latency doubling and staying there,
+// is caught by the rate of change between neighbouring points. A spike's
+// return to normal is not reported again as a fall.
+func annotateAnomalies(datapoints []Datapoint) []Anomaly {
+	if len(datapoints) < anomalyMinPoints {
+		return nil
+	}
+	points := append([]Datapoint(nil), datapoints...)
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+
+	values := make([]float64, len(points))
+	deltas := make([]float64, len(points)-1)
+	for i, p := range points {
+		values[i] = p.Value
+		if i > 0 {
+			deltas[i-1] = p.Value - points[i-1].Value
+		}
+	}
+	mean, sd := meanStdDev(values)
+	deltaMean, deltaSD := meanStdDev(deltas)
+
+	var anomalies []Anomaly
+	flagged := make([]bool, len(points))
+	for i, p := range points {
+		if sd > 0 {
+			if z := (p.Value - mean) / sd; math.Abs(z) >= anomalyZScore {
+				kind := "spike"
+				if z < 0 {
+					kind = "drop"
+				}
+				anomalies = append(anomalies, Anomaly{Datapoint: p, Kind: kind, Baseline: mean, Score: math.Abs(z)})
+				flagged[i] = true
+				continue
+			}
+		}
+		if i == 0 || deltaSD == 0 || flagged[i-1] {
+			continue
+		}
+		if z := (deltas[i-1] - deltaMean) / deltaSD; math.Abs(z) >= anomalyZScore {
+			kind := "jump"
+			if deltas[i-1] < 0 {
+				kind = "fall"
+			}
+			anomalies = append(anomalies, Anomaly{Datapoint: p, Kind: kind, Baseline: points[i-1].Value, Score: math.Abs(z)})
+			flagged[i] = true
+		}
+	}
+
+	if len(anomalies) > anomalyMaxPerSeries {
+		sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Score > anomalies[j].Score })
+		anomalies = anomalies[:anomalyMaxPerSeries]
+		sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Timestamp.Before(anomalies[j].Timestamp) })
+	}
+	return anomalies
+}
+
+func meanStdDev(values []float64) (mean, sd float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		sd += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sd / float64(len(values)))
+}
+
+// metricSeries is one get_metric_statistics result read back from the
+// operation's report.
+type metricSeries struct {
+	Label  string
+	Unit   string
+	Points []Datapoint
+}
+
+var (
+	metricHeaderPattern  = regexp.MustCompile(`^📈 (\S+) (\S+) \(`)
+	metricSummaryPattern = regexp.MustCompile(`^Summary: .*avg \S+ (.*?) ?over \d+ datapoints`)
+	metricPointPattern   = regexp.MustCompile(`^\s+(\d{4}-\d{2}-\d{2}T\S+)\s+(-?[\d.]+)$`)
+)
+
+// parseMetricSeries reads the series out of get_metric_statistics reports
+// in text, which may hold several (one per ECS cluster, for example).
+func parseMetricSeries(text string) []metricSeries {
+	var series []metricSeries
+	var current *metricSeries
+	for _, line := range strings.Split(text, "\n") {
+		if m := metricHeaderPattern.FindStringSubmatch(line); m != nil {
+			series = append(series, metricSeries{Label: m[2]})
+			current = &series[len(series)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if dims, ok := strings.CutPrefix(line, "Dimensions: "); ok {
+			current.Label += " (" + dims + ")"
+		} else if m := metricSummaryPattern.FindStringSubmatch(line); m != nil {
+			current.Unit = m[1]
+		} else if m := metricPointPattern.FindStringSubmatch(line); m != nil {
+			ts, err := time.Parse(time.RFC3339, m[1])
+			value, verr := strconv.ParseFloat(m[2], 64)
+			if err == nil && verr == nil {
+				current.Points = append(current.Points, Datapoint{Timestamp: ts, Value: value})
+			}
+		}
+	}
+	return series
+}
+
+// metricAnomalyNotes annotates every metric series found in the gathered
+// data, e.g. "CPUUtilization spiked to 95% at 14:32 UTC (avg 21.4%)", so the
+// answer can line the moment up with log events.
+func metricAnomalyNotes(data AWSData) []string {
+	var texts []string
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch v := data[key].(type) {
+		case string:
+			texts = append(texts, v)
+		case AWSData:
+			for _, sub := range v {
+				if s, ok := sub.(string); ok {
+					texts = append(texts, s)
+				}
+			}
+		}
+	}
+
+	var notes []string
+	for _, text := range texts {
+		if !strings.Contains(text, "📈") {
+			continue
+		}
+		for _, s := range parseMetricSeries(text) {
+			for _, a := range annotateAnomalies(s.Points) {
+				notes = append(notes, formatAnomaly(s, a))
+			}
+		}
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+func formatAnomaly(s metricSeries, a Anomaly) string {
+	at := a.Timestamp.UTC().Format("Jan 2 15:04 UTC")
+	value := formatMetricValue(a.Value, s.Unit)
+	baseline := formatMetricValue(a.Baseline, s.Unit)
+	switch a.Kind {
+	case "spike":
+		return fmt.Sprintf("%s spiked to %s at %s (avg %s)", s.Label, value, at, baseline)
+	case "drop":
+		return fmt.Sprintf("%s dropped to %s at %s (avg %s)", s.Label, value, at, baseline)
+	case "jump":
+		return fmt.Sprintf("%s jumped from %s to %s at %s", s.Label, baseline, value, at)
+	default:
+		return fmt.Sprintf("%s fell from %s to %s at %s", s.Label, baseline, value, at)
+	}
+}
+
+func formatMetricValue(v float64, unit string) string {
+	s := strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	switch unit {
+	case "", "None", "Count":
+		return s
+	case "Percent":
+		return s + "%"
+	default:
+		return s + " " + unit
+	}
+}
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func series(start time.Time, values ...float64) []Datapoint {
+	points := make([]Datapoint, len(values))
+	for i, v := range values {
+		points[i] = Datapoint{Timestamp: start.Add(time.Duration(i) * 5 * time.Minute), Value: v}
+	}
+	return points
+}
+
+func TestAnnotateAnomalies(t *testing.T) {
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		points []Datapoint
+		want   []string // kind@HH:MM
+	}{
+		{
+			name:   "single spike, not its recovery",
+			points: series(start, 20, 22, 19, 21, 20, 95, 21, 20, 22, 19),
+			want:   []string{"spike@14:25"},
+		},
+		{
+			name:   "step change the mean hides",
+			points: series(start, 100, 101, 99, 100, 100, 250, 251, 249, 250, 250),
+			want:   []string{"jump@14:25"},
+		},
+		{
+			name:   "flat series",
+			points: series(start, 5, 5, 5, 5, 5, 5),
+		},
+		{
+			name:   "too few points",
+			points: series(start, 1, 1, 90, 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, a := range annotateAnomalies(tt.points) {
+				got = append(got, a.Kind+"@"+a.Timestamp.Format("15:04"))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("annotateAnomalies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFinalContext_MetricAnomalies(t *testing.T) {
+	var report strings.Builder
+	report.WriteString("📈 AWS/ECS CPUUtilization (Average, 300s period, last 1h)\n")
+	report.WriteString("Dimensions: ClusterName=prod, ServiceName=api\n")
+	report.WriteString("Summary: min 19.00, max 95.00, avg 27.50 Percent over 10 datapoints\n")
+	for i, v := range []float64{20, 22, 19, 21, 20, 95, 21, 20, 22, 19} {
+		ts := time.Date(2026, 10, 16, 14, 7+5*i, 0, 0, time.UTC)
+		report.WriteString(fmt.Sprintf("  %s  %.2f\n", ts.Format(time.RFC3339), v))
+	}
+
+	a := &Agent{}
+	ctx := &AgentContext{
+		OriginalQuery: "why is the api slow",
+		GatheredData: AWSData{
+			"metrics": AWSData{"get_metric_statistics_0": report.String()},
+		},
+		ServiceData:   make(ServiceData),
+		Metrics:       make(MetricsData),
+		ServiceStatus: make(map[string]string),
+	}
+
+	result := a.BuildFinalContext(ctx)
+	want := "- CPUUtilization (ClusterName=prod, ServiceName=api) spiked to 95% at Oct 16 14:32 UTC (avg 27.9%)"
+	if !strings.Contains(result, "=== METRIC ANOMALIES ===") || !strings.Contains(result, want) {
+		t.Errorf("expected %q in:\n%s", want, result)
+	}
+}
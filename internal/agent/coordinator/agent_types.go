@@ -108,6 +108,14 @@ var (
 			WaitTimeout:    6 * time.Second,
 		},
 	}
+	AgentTypeCapacity = AgentType{
+		Name: "capacity",
+		Dependencies: Dependency{
+			ProvidedData:   []string{"quota_utilization"},
+			ExecutionOrder: 2,
+			WaitTimeout:    6 * time.Second,
+		},
+	}
 	AgentTypeLLM = AgentType{
 		Name: "llm",
 		Dependencies: Dependency{
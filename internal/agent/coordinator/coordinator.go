@@ -4,6 +4,7 @@ package coordinator
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -29,17 +30,41 @@ type ParallelAgent struct {
 	Results    model.AWSData
 	Error      error
 	Operations []awsclient.LLMOperation
+	// FailedOps are operations that returned an error; SkippedOps never ran
+	// because an earlier operation failed the agent.
+	FailedOps  []string
+	SkippedOps []string
 }
 
+// DefaultParallelTimeout is the overall budget the agent types' WaitTimeout
+// values were tuned against.
+const DefaultParallelTimeout = 15 * time.Second
+
 // Coordinator drives decision-tree-based parallel execution.
 type Coordinator struct {
 	DecisionTree *dt.Tree
 	MainContext  *model.AgentContext
 
-	client    *awsclient.Client
-	registry  *AgentRegistry
-	dataBus   *SharedDataBus
-	scheduler *DependencyScheduler
+	client          *awsclient.Client
+	registry        *AgentRegistry
+	dataBus         *SharedDataBus
+	opCache         *OperationCache
+	scheduler       *DependencyScheduler
+	parallelTimeout time.Duration
+
+	query   string
+	matched []*dt.Node
+	planned []plannedAgent
+
+	forceAgents    []string
+	suppressAgents []string
+}
+
+// plannedAgent pairs a scheduled config with the agent that ran it; agent is
+// nil when the config was skipped for unmet dependencies.
+type plannedAgent struct {
+	cfg   AgentConfig
+	agent *ParallelAgent
 }
 
 // New returns a ready-to-use coordinator.
@@ -52,18 +77,48 @@ func New(mainContext *model.AgentContext, client *awsclient.Client) (*Coordinato
 		return nil, fmt.Errorf("coordinator: AWS client must not be nil")
 	}
 	return &Coordinator{
-		DecisionTree: dt.New(),
-		MainContext:  mainContext,
-		client:       client,
-		registry:     NewAgentRegistry(),
-		dataBus:      NewSharedDataBus(),
-		scheduler:    NewDependencyScheduler(),
+		DecisionTree:    dt.New(),
+		MainContext:     mainContext,
+		client:          client,
+		registry:        NewAgentRegistry(),
+		dataBus:         NewSharedDataBus(),
+		opCache:         NewOperationCache(),
+		scheduler:       NewDependencyScheduler(),
+		parallelTimeout: DefaultParallelTimeout,
 	}, nil
 }
 
+// SetParallelTimeout sets the overall agent budget. Per-agent WaitTimeout
+// values are scaled proportionally so they keep the same relative weight.
+func (c *Coordinator) SetParallelTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultParallelTimeout
+	}
+	c.parallelTimeout = timeout
+}
+
+// SetAgentOverrides makes SpawnAgents run the force agent types whatever the
+// decision tree matched, along with the agents that provide the data they
+// require, and never run the suppress ones. Suppressing wins over forcing.
+func (c *Coordinator) SetAgentOverrides(force, suppress []string) {
+	c.forceAgents = append([]string(nil), force...)
+	c.suppressAgents = append([]string(nil), suppress...)
+}
+
+// ScaleWaitTimeout rescales a WaitTimeout tuned for DefaultParallelTimeout to
+// the given overall budget.
+func ScaleWaitTimeout(wait, total time.Duration) time.Duration {
+	if total <= 0 || total == DefaultParallelTimeout {
+		return wait
+	}
+	return time.Duration(float64(wait) * float64(total) / float64(DefaultParallelTimeout))
+}
+
 // Analyze traverses the decision tree for the provided query.
 func (c *Coordinator) Analyze(query string) []*dt.Node {
-	return c.DecisionTree.Traverse(query, c.MainContext)
+	c.query = query
+	c.matched = c.DecisionTree.Traverse(query, c.MainContext)
+	return c.matched
 }
 
 // SpawnAgents starts agents grouped by dependency order.
@@ -81,11 +136,13 @@ func (c *Coordinator) SpawnAgents(ctx context.Context, applicable []*dt.Node) {
 					Priority:   node.Priority,
 					Parameters: node.Parameters,
 					AgentType:  agt,
+					Node:       node,
 				}
 			}
 		}
 	}
 
+	c.applyAgentOverrides(agentConfigs)
 	if len(agentConfigs) == 0 {
 		return
 	}
@@ -103,9 +160,11 @@ func (c *Coordinator) SpawnAgents(ctx context.Context, applicable []*dt.Node) {
 				if verbose {
 					fmt.Printf("⏸️  Agent %s waiting for dependencies\n", cfg.AgentType.Name)
 				}
+				c.planned = append(c.planned, plannedAgent{cfg: cfg})
 				continue
 			}
 			agent := c.newParallelAgent(cfg)
+			c.planned = append(c.planned, plannedAgent{cfg: cfg, agent: agent})
 			c.registry.Register(agent)
 			wg.Add(1)
 			go c.runPlannedAgent(ctx, &wg, agent)
@@ -117,6 +176,80 @@ func (c *Coordinator) SpawnAgents(ctx context.Context, applicable []*dt.Node) {
 	}
 }
 
+// forcedNode stands in for the decision tree node of an agent type that was
+// forced, or pulled in as a dependency of one.
+func forcedNode(name, reason string) *dt.Node {
+	return &dt.Node{
+		ID:         "forced_" + name,
+		Name:       reason,
+		Condition:  "always",
+		Action:     "forced_agent",
+		Priority:   10,
+		AgentTypes: []string{name},
+	}
+}
+
+// applyAgentOverrides adds the forced agent types to configs, then the
+// providers of any data they require that no scheduled agent provides, and
+// finally removes the suppressed types.
+func (c *Coordinator) applyAgentOverrides(configs map[string]AgentConfig) {
+	add := func(name, reason string) {
+		if _, exists := configs[name]; exists {
+			return
+		}
+		agt, ok := c.lookupAgentType(name)
+		if !ok {
+			return
+		}
+		node := forcedNode(name, reason)
+		configs[name] = AgentConfig{Priority: node.Priority, Parameters: model.AWSData{}, AgentType: agt, Node: node}
+	}
+	for _, name := range c.forceAgents {
+		add(name, "Forced by --agents")
+	}
+	if len(c.forceAgents) > 0 {
+		// Walk the required data of everything scheduled until each key has a
+		// provider, so a forced agent is not skipped for missing inputs.
+		for changed := true; changed; {
+			changed = false
+			provided := make(map[string]bool)
+			for _, cfg := range configs {
+				for _, key := range cfg.AgentType.Dependencies.ProvidedData {
+					provided[key] = true
+				}
+			}
+			for _, cfg := range configs {
+				for _, key := range cfg.AgentType.Dependencies.RequiredData {
+					if provided[key] {
+						continue
+					}
+					if provider := providerOf(key); provider != "" {
+						add(provider, fmt.Sprintf("Provides %s for %s", key, cfg.AgentType.Name))
+						provided[key] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	for _, name := range c.suppressAgents {
+		delete(configs, name)
+	}
+}
+
+// providerOf returns the first agent type that provides key.
+func providerOf(key string) string {
+	for _, name := range agentTypeNames {
+		agt, _ := agentTypeByName(name)
+		for _, provided := range agt.Dependencies.ProvidedData {
+			if provided == key {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
 // WaitForCompletion blocks until all agents finish or timeout occurs.
 func (c *Coordinator) WaitForCompletion(ctx context.Context, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
@@ -162,16 +295,68 @@ func (c *Coordinator) AggregateResults() model.AWSData {
 		"failed_count":    stats.Failed,
 		"decision_path":   c.DecisionTree.CurrentPath,
 		"execution_time":  time.Now().Format(time.RFC3339),
+		"failed_agents":   c.failures(),
 	}
 
 	return aggregated
 }
 
+// failures lists every agent that failed, lost operations, or never ran
+// because its dependencies were not ready, ordered by agent type.
+func (c *Coordinator) failures() []model.AgentFailure {
+	var failures []model.AgentFailure
+	for _, agent := range c.registry.Agents() {
+		if agent.Status == "completed" && len(agent.FailedOps) == 0 {
+			continue
+		}
+		failure := model.AgentFailure{
+			Agent:             agent.Type.Name,
+			FailedOperations:  agent.FailedOps,
+			SkippedOperations: agent.SkippedOps,
+		}
+		switch {
+		case agent.Error != nil:
+			failure.Error = agent.Error.Error()
+		case agent.Status != "completed":
+			failure.Error = "did not finish before the parallel timeout"
+		default:
+			failure.Error = "some operations failed"
+		}
+		failures = append(failures, failure)
+	}
+	for _, p := range c.planned {
+		if p.agent == nil {
+			failures = append(failures, model.AgentFailure{
+				Agent: p.cfg.AgentType.Name,
+				Error: "not run: required data from other agents was unavailable",
+			})
+		}
+	}
+	sort.SliceStable(failures, func(i, j int) bool { return failures[i].Agent < failures[j].Agent })
+	return failures
+}
+
 // Stats exposes snapshot counters for callers needing execution metrics.
 func (c *Coordinator) Stats() AgentStats {
 	return c.registry.Stats()
 }
 
+// executeOperation runs an AWS operation through the coordinator's operation
+// cache, so agents asking for the same data share one CLI call. Mutations
+// always run.
+func (c *Coordinator) executeOperation(ctx context.Context, operation string, params map[string]any) (string, error) {
+	if awsclient.IsMutatingOperation(operation) {
+		return c.client.ExecuteOperation(ctx, operation, params)
+	}
+	result, shared, err := c.opCache.Do(ctx, operation, params, func() (string, error) {
+		return c.client.ExecuteOperation(ctx, operation, params)
+	})
+	if shared && verboseAgents() {
+		fmt.Printf("♻️  Reusing %s result from another agent\n", operation)
+	}
+	return result, err
+}
+
 func (c *Coordinator) runPlannedAgent(ctx context.Context, wg *sync.WaitGroup, agent *ParallelAgent) {
 	defer wg.Done()
 	verbose := verboseAgents()
@@ -189,15 +374,34 @@ func (c *Coordinator) runPlannedAgent(ctx context.Context, wg *sync.WaitGroup, a
 }
 
 func (c *Coordinator) runParallelAgent(ctx context.Context, agent *ParallelAgent) {
+	c.MainContext.Emit(model.AgentEvent{
+		Type:      model.EventAgentStarted,
+		AgentID:   agent.ID,
+		AgentType: agent.Type.Name,
+		Message:   fmt.Sprintf("%s agent started with %d operations", agent.Type.Name, len(agent.Operations)),
+	})
 	defer func() {
 		agent.EndTime = time.Now()
 		if agent.Error != nil {
 			agent.Status = "failed"
 			c.registry.MarkFailed()
+			c.MainContext.Emit(model.AgentEvent{
+				Type:      model.EventAgentFailed,
+				AgentID:   agent.ID,
+				AgentType: agent.Type.Name,
+				Message:   fmt.Sprintf("%s agent failed", agent.Type.Name),
+				Error:     agent.Error.Error(),
+			})
 			return
 		}
 		agent.Status = "completed"
 		c.registry.MarkCompleted()
+		c.MainContext.Emit(model.AgentEvent{
+			Type:      model.EventAgentCompleted,
+			AgentID:   agent.ID,
+			AgentType: agent.Type.Name,
+			Message:   fmt.Sprintf("%s agent completed in %s", agent.Type.Name, agent.EndTime.Sub(agent.StartTime).Round(time.Millisecond)),
+		})
 	}()
 
 	verbose := verboseAgents()
@@ -206,7 +410,7 @@ func (c *Coordinator) runParallelAgent(ctx context.Context, agent *ParallelAgent
 			agent.ID, agent.Type.Name, len(agent.Operations))
 	}
 
-	for _, op := range agent.Operations {
+	for i, op := range agent.Operations {
 		var (
 			result any
 			err    error
@@ -246,13 +450,14 @@ func (c *Coordinator) runParallelAgent(ctx context.Context, agent *ParallelAgent
 			}
 			result, err = c.investigateServiceLogsWithAI(ctx, op.Parameters, discovered)
 		default:
-			result, err = c.client.ExecuteOperation(ctx, op.Operation, op.Parameters)
+			result, err = c.executeOperation(ctx, op.Operation, op.Parameters)
 		}
 
 		if err != nil {
 			if verbose {
 				fmt.Printf("Agent %s operation %s failed: %v\n", agent.ID, op.Operation, err)
 			}
+			agent.FailedOps = append(agent.FailedOps, op.Operation)
 			// Service discovery and log investigation failures are non-fatal:
 			// the agent continues with whatever data it has gathered so far.
 			if op.Operation == "discover_services" || op.Operation == "investigate_service_logs" {
@@ -260,10 +465,21 @@ func (c *Coordinator) runParallelAgent(ctx context.Context, agent *ParallelAgent
 				continue
 			}
 			agent.Error = err
+			for _, rest := range agent.Operations[i+1:] {
+				agent.SkippedOps = append(agent.SkippedOps, rest.Operation)
+			}
 			return
 		}
 
+		// The same operation can run several times with different parameters
+		// (one get_metric_statistics per metric), so never overwrite a result.
 		key := fmt.Sprintf("%s_%s", agent.Type.Name, op.Operation)
+		for n := 2; ; n++ {
+			if _, exists := agent.Results[key]; !exists {
+				break
+			}
+			key = fmt.Sprintf("%s_%s_%d", agent.Type.Name, op.Operation, n)
+		}
 		agent.Results[key] = result
 		if verbose {
 			fmt.Printf("✅ Agent %s completed operation: %s\n", agent.ID, op.Operation)
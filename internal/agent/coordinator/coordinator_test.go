@@ -1,7 +1,12 @@
 package coordinator
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/bgdnvk/clanker/internal/agent/model"
 )
@@ -79,3 +84,145 @@ func TestCopyContextForAgent_CopiesFields(t *testing.T) {
 		t.Error("modifying copied decisions should not affect original")
 	}
 }
+
+func TestScaleWaitTimeout(t *testing.T) {
+	tests := []struct {
+		wait, total, want time.Duration
+	}{
+		{8 * time.Second, DefaultParallelTimeout, 8 * time.Second},
+		{8 * time.Second, 0, 8 * time.Second},
+		{8 * time.Second, 30 * time.Second, 16 * time.Second},
+		{6 * time.Second, 5 * time.Second, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := ScaleWaitTimeout(tt.wait, tt.total); got != tt.want {
+			t.Errorf("ScaleWaitTimeout(%v, %v) = %v, want %v", tt.wait, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestFailures_ReportsFailedAndPartialAgents(t *testing.T) {
+	c := &Coordinator{registry: NewAgentRegistry()}
+	c.registry.Register(&ParallelAgent{Type: AgentTypeMetrics, Status: "completed"})
+	c.registry.Register(&ParallelAgent{Type: AgentTypeLog, Status: "failed", Error: errors.New("AccessDenied"),
+		FailedOps: []string{"get_recent_logs"}, SkippedOps: []string{"analyze_lambda_errors"}})
+	c.registry.Register(&ParallelAgent{Type: AgentTypeInfrastructure, Status: "completed", FailedOps: []string{"discover_services"}})
+	c.planned = append(c.planned, plannedAgent{cfg: AgentConfig{AgentType: AgentTypeK8s}})
+
+	failures := c.failures()
+	if len(failures) != 3 {
+		t.Fatalf("expected 3 failures, got %+v", failures)
+	}
+	got := map[string]model.AgentFailure{}
+	for _, f := range failures {
+		got[f.Agent] = f
+	}
+	if f := got["log"]; f.Error != "AccessDenied" || len(f.SkippedOperations) != 1 {
+		t.Errorf("unexpected log failure: %+v", f)
+	}
+	if f := got["infrastructure"]; f.Error != "some operations failed" || f.FailedOperations[0] != "discover_services" {
+		t.Errorf("unexpected infrastructure failure: %+v", f)
+	}
+	if _, ok := got["k8s"]; !ok {
+		t.Error("expected the unscheduled k8s agent to be reported")
+	}
+	if _, ok := got["metrics"]; ok {
+		t.Error("a clean agent must not be reported")
+	}
+}
+
+func TestApplyAgentOverrides_ForcesWithDependenciesAndSuppresses(t *testing.T) {
+	c := &Coordinator{}
+	c.SetAgentOverrides([]string{"cost"}, []string{"k8s"})
+	configs := map[string]AgentConfig{
+		"k8s": {AgentType: AgentTypeK8s},
+	}
+	c.applyAgentOverrides(configs)
+
+	// cost requires metrics and resource_health (from infrastructure).
+	for _, name := range []string{"cost", "metrics", "infrastructure"} {
+		cfg, ok := configs[name]
+		if !ok {
+			t.Fatalf("expected %s to be scheduled, got %v", name, configs)
+		}
+		if cfg.Node == nil || cfg.Node.Action != "forced_agent" {
+			t.Errorf("%s should carry a forced node, got %+v", name, cfg.Node)
+		}
+	}
+	if _, ok := configs["k8s"]; ok {
+		t.Error("suppressed k8s agent is still scheduled")
+	}
+	if len(configs) != 3 {
+		t.Errorf("expected only cost and its providers, got %v", configs)
+	}
+
+	groups := NewDependencyScheduler().Plan(configs)
+	if last := groups[len(groups)-1]; last.Agents[0].AgentType.Name != "cost" {
+		t.Errorf("cost should run after its providers, got order %+v", groups)
+	}
+}
+
+func TestValidateAgentTypes(t *testing.T) {
+	if err := ValidateAgentTypes([]string{"cost", "capacity"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateAgentTypes([]string{"costs"}); err == nil {
+		t.Error("expected an error for an unknown agent type")
+	}
+}
+
+func TestOperationCache_SharesIdenticalCalls(t *testing.T) {
+	cache := NewOperationCache()
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fn := func() (string, error) {
+		calls.Add(1)
+		<-release
+		return "functions", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, _ = cache.Do(context.Background(), "list_lambda_functions", map[string]any{"region": "us-east-1", "limit": 5}, fn)
+		}(i)
+	}
+	// Let every caller reach the cache before the first call returns.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected one underlying call, got %d", calls.Load())
+	}
+	for _, r := range results {
+		if r != "functions" {
+			t.Errorf("expected shared result, got %q", r)
+		}
+	}
+	if cache.Hits() != 2 {
+		t.Errorf("expected 2 cache hits, got %d", cache.Hits())
+	}
+
+	if _, shared, _ := cache.Do(context.Background(), "list_lambda_functions", map[string]any{"limit": 5, "region": "us-east-1"}, fn); !shared {
+		t.Error("parameter order must not matter")
+	}
+	if _, shared, _ := cache.Do(context.Background(), "list_lambda_functions", map[string]any{"region": "eu-west-1"}, func() (string, error) { return "", nil }); shared {
+		t.Error("different parameters must not share a result")
+	}
+}
+
+func TestOperationCache_DoesNotKeepFailures(t *testing.T) {
+	cache := NewOperationCache()
+	fail := func() (string, error) { return "", errors.New("throttled") }
+	if _, _, err := cache.Do(context.Background(), "list_log_groups", nil, fail); err == nil {
+		t.Fatal("expected the first call to fail")
+	}
+	result, shared, err := cache.Do(context.Background(), "list_log_groups", nil, func() (string, error) { return "groups", nil })
+	if err != nil || shared || result != "groups" {
+		t.Errorf("expected a fresh call after a failure, got %q shared=%v err=%v", result, shared, err)
+	}
+}
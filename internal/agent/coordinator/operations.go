@@ -1,10 +1,13 @@
 package coordinator
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/bgdnvk/clanker/internal/agent/model"
+	"github.com/bgdnvk/clanker/internal/agent/semantic"
 	awsclient "github.com/bgdnvk/clanker/internal/aws"
+	"github.com/spf13/viper"
 )
 
 type operationGenerator func(*model.AgentContext, model.AWSData) []awsclient.LLMOperation
@@ -21,6 +24,7 @@ var operationCatalog = map[string]operationGenerator{
 	"datapipeline":   generateDataPipelineOperations,
 	"queue":          generateQueueOperations,
 	"availability":   generateAvailabilityOperations,
+	"capacity":       generateCapacityOperations,
 	"llm":            generateLLMOperations,
 }
 
@@ -55,8 +59,127 @@ func generateK8sOperations(_ *model.AgentContext, params model.AWSData) []awscli
 	}
 }
 
-func generateMetricsOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
-	return []awsclient.LLMOperation{{Operation: "list_cloudwatch_alarms", Reason: "Get CloudWatch alarms for performance issues", Parameters: map[string]any{}}}
+func generateMetricsOperations(ctx *model.AgentContext, params model.AWSData) []awsclient.LLMOperation {
+	alarms := awsclient.LLMOperation{Operation: "list_cloudwatch_alarms", Reason: "Get CloudWatch alarms for performance issues", Parameters: map[string]any{}}
+	query := ""
+	if ctx != nil {
+		query = strings.ToLower(ctx.OriginalQuery)
+	}
+	if focus, _ := params["focus"].(string); focus == "alarms" || isAlarmQuery(query) {
+		alarms = awsclient.LLMOperation{Operation: "analyze_active_alarms", Reason: "Find the alarms firing now, their history and current values", Parameters: map[string]any{}}
+	}
+	ops := []awsclient.LLMOperation{alarms}
+	if ctx == nil {
+		return ops
+	}
+
+	if isDatabasePerformanceQuery(query) {
+		ops = append(ops, rdsPerformanceOperation(query))
+	}
+	if strings.Contains(query, "lambda") || strings.Contains(query, "function") {
+		ops = append(ops,
+			metricStatisticsOperation("AWS/Lambda", "Duration", "p99", "Lambda p99 duration"),
+			metricStatisticsOperation("AWS/Lambda", "Errors", "Sum", "Lambda errors"),
+			metricStatisticsOperation("AWS/Lambda", "Throttles", "Sum", "Lambda throttles"),
+		)
+	}
+	if strings.Contains(query, "ecs") || strings.Contains(query, "container") || strings.Contains(query, "fargate") {
+		ops = append(ops,
+			metricStatisticsOperation("AWS/ECS", "CPUUtilization", "Average", "ECS CPU utilization"),
+			metricStatisticsOperation("AWS/ECS", "MemoryUtilization", "Average", "ECS memory utilization"),
+		)
+	}
+	return ops
+}
+
+func metricStatisticsOperation(namespace, metricName, stat, what string) awsclient.LLMOperation {
+	return awsclient.LLMOperation{
+		Operation: "get_metric_statistics",
+		Reason:    "Get recent " + what,
+		Parameters: map[string]any{
+			"namespace":   namespace,
+			"metric_name": metricName,
+			"stat":        stat,
+		},
+	}
+}
+
+// isAlarmQuery reports whether a lowercased query asks what is alarming
+// rather than for a list of alarms.
+func isAlarmQuery(query string) bool {
+	for _, keyword := range []string{"alarming", "alarms firing", "alarm firing", "in alarm", "alerting", "alerts firing", "what's firing", "monitoring"} {
+		if strings.Contains(query, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDatabasePerformanceQuery reports whether a lowercased query is about a
+// slow database or running out of connections.
+func isDatabasePerformanceQuery(query string) bool {
+	if strings.Contains(query, "too many connections") || strings.Contains(query, "max_connections") || strings.Contains(query, "connection pool") {
+		return true
+	}
+	database := false
+	for _, keyword := range []string{"database", "rds", "postgres", "mysql", "aurora", "db "} {
+		if strings.Contains(query, keyword) {
+			database = true
+			break
+		}
+	}
+	if !database {
+		return false
+	}
+	for _, keyword := range []string{"slow", "latency", "connection", "performance", "timeout", "cpu", "memory"} {
+		if strings.Contains(query, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func rdsPerformanceOperation(query string) awsclient.LLMOperation {
+	return awsclient.LLMOperation{Operation: "analyze_rds_performance", Reason: "Check RDS CPU, connections, memory, latency and top waits", Parameters: map[string]any{"query": query}}
+}
+
+var connectivitySourcePattern = regexp.MustCompile(`\b(i|eni)-[0-9a-f]{8,17}\b`)
+
+// eksWordPattern matches EKS as a word, so "weeks" does not route to EKS.
+var eksWordPattern = regexp.MustCompile(`\beks\b`)
+
+// ecsServicePattern marks a deploy question as being about ECS services, so a
+// Lambda or CodePipeline deploy does not route to ECS-only operations.
+var ecsServicePattern = regexp.MustCompile(`\b(ecs|fargate|services?|tasks?|containers?)\b`)
+
+// connectivityOperations traces the path from an instance or ENI named in the
+// query; without one it lists the pieces the answer needs.
+func connectivityOperations(query string) []awsclient.LLMOperation {
+	if connectivitySourcePattern.MatchString(query) {
+		return []awsclient.LLMOperation{
+			{Operation: "analyze_connectivity", Reason: "Find the first security group, NACL or route blocking the traffic", Parameters: map[string]any{"query": query}},
+		}
+	}
+	return []awsclient.LLMOperation{
+		{Operation: "list_vpcs", Reason: "List VPCs", Parameters: map[string]any{}},
+		{Operation: "list_subnets", Reason: "List subnets", Parameters: map[string]any{}},
+		{Operation: "list_route_tables", Reason: "Check routes to the internet and NAT gateways", Parameters: map[string]any{}},
+		{Operation: "list_security_groups", Reason: "List security groups", Parameters: map[string]any{}},
+	}
+}
+
+// isAPIGatewayQuery reports whether a lowercased query names API Gateway.
+func isAPIGatewayQuery(query string) bool {
+	for _, keyword := range []string{"api gateway", "api-gateway", "apigateway", "apigw", "http api", "rest api"} {
+		if strings.Contains(query, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func apigwErrorsOperation(query string) awsclient.LLMOperation {
+	return awsclient.LLMOperation{Operation: "analyze_apigw_errors", Reason: "Find the failing API Gateway route and whether latency is in the integration or the gateway", Parameters: map[string]any{"query": query}}
 }
 
 func generateInfrastructureOperations(ctx *model.AgentContext, params model.AWSData) []awsclient.LLMOperation {
@@ -71,6 +194,34 @@ func generateInfrastructureOperations(ctx *model.AgentContext, params model.AWSD
 		query = strings.ToLower(ctx.OriginalQuery)
 	}
 
+	// Connectivity failures: trace the path when the query names the source
+	if focus, _ := params["focus"].(string); focus == "connectivity" {
+		return connectivityOperations(query)
+	}
+	if focus, _ := params["focus"].(string); focus == "asg_activity" {
+		return []awsclient.LLMOperation{
+			{Operation: "analyze_asg_activity", Reason: "Find failed launches and unhealthy-target replacement loops keeping Auto Scaling groups below desired capacity", Parameters: map[string]any{"query": query}},
+		}
+	}
+	if focus, _ := params["focus"].(string); focus == "environment_diff" {
+		return []awsclient.LLMOperation{
+			{Operation: "compare_environments", Reason: "Diff resources and settings between the two environments", Parameters: map[string]any{"query": query}},
+		}
+	}
+
+	// API Gateway errors: per-route 4xx/5xx and where the latency goes
+	if isAPIGatewayQuery(query) {
+		return []awsclient.LLMOperation{apigwErrorsOperation(query)}
+	}
+
+	// Gateway errors usually mean unhealthy ALB targets
+	if strings.Contains(query, "502") || strings.Contains(query, "503") || strings.Contains(query, "504") ||
+		strings.Contains(query, "5xx") || strings.Contains(query, "alb") || strings.Contains(query, "load balancer") {
+		return []awsclient.LLMOperation{
+			{Operation: "analyze_alb_errors", Reason: "Correlate ALB 5xx with target health", Parameters: map[string]any{}},
+		}
+	}
+
 	// EC2/Instance queries
 	if strings.Contains(query, "ec2") || strings.Contains(query, "instance") {
 		return []awsclient.LLMOperation{
@@ -79,6 +230,11 @@ func generateInfrastructureOperations(ctx *model.AgentContext, params model.AWSD
 		}
 	}
 
+	// Slow databases or connection errors need metrics, not just the inventory
+	if isDatabasePerformanceQuery(query) {
+		return []awsclient.LLMOperation{rdsPerformanceOperation(query)}
+	}
+
 	// RDS queries
 	if strings.Contains(query, "rds") || strings.Contains(query, "database") {
 		return []awsclient.LLMOperation{
@@ -86,11 +242,26 @@ func generateInfrastructureOperations(ctx *model.AgentContext, params model.AWSD
 		}
 	}
 
-	// ECS queries
-	if strings.Contains(query, "ecs") || strings.Contains(query, "container") {
+	// EKS app logs live in the pods rather than CloudWatch
+	if eksWordPattern.MatchString(query) {
 		return []awsclient.LLMOperation{
+			{Operation: "list_eks_clusters", Reason: "List EKS clusters", Parameters: map[string]any{}},
+			{Operation: "describe_eks_workloads", Reason: "Check EKS pods for crashes and restarts", Parameters: map[string]any{}},
+		}
+	}
+
+	// Stuck or failing ECS deployments: surface ECS service events
+	deployTrouble := (strings.Contains(query, "deploy") || strings.Contains(query, "stuck")) && ecsServicePattern.MatchString(query)
+
+	// ECS queries
+	if strings.Contains(query, "ecs") || strings.Contains(query, "container") || deployTrouble {
+		ops := []awsclient.LLMOperation{
 			{Operation: "describe_ecs_clusters", Reason: "List ECS clusters", Parameters: map[string]any{}},
 		}
+		if deployTrouble {
+			ops = append(ops, awsclient.LLMOperation{Operation: "analyze_ecs_service_events", Reason: "Check ECS service events for deployment failures", Parameters: map[string]any{}})
+		}
+		return ops
 	}
 
 	// S3 queries
@@ -124,26 +295,101 @@ func generateInfrastructureOperations(ctx *model.AgentContext, params model.AWSD
 	}
 }
 
-func generateSecurityOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
-	return []awsclient.LLMOperation{{Operation: "describe_guardduty_findings", Reason: "Check GuardDuty alerts", Parameters: map[string]any{}}}
+func generateSecurityOperations(ctx *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
+	ops := []awsclient.LLMOperation{{Operation: "get_security_findings", Reason: "Summarize active GuardDuty and Security Hub findings by severity, compromises first", Parameters: map[string]any{}}}
+	if ctx != nil {
+		query := strings.ToLower(ctx.OriginalQuery)
+		for _, keyword := range []string{"permission", "access", "security"} {
+			if strings.Contains(query, keyword) {
+				ops = append(ops, awsclient.LLMOperation{Operation: "analyze_iam_role", Reason: "Flag over-permissive grants on roles named in the query", Parameters: map[string]any{"query": ctx.OriginalQuery}})
+				break
+			}
+		}
+		for _, keyword := range []string{"public", "exposed", "exposure", "s3 security"} {
+			if strings.Contains(query, keyword) {
+				ops = append(ops, awsclient.LLMOperation{Operation: "audit_s3_bucket", Reason: "Find publicly readable or writable S3 buckets and missing encryption or versioning", Parameters: map[string]any{}})
+				break
+			}
+		}
+		for _, keyword := range []string{"vulnerab", "cve", "image", "container", "ecr", "ecs", "eks", "security", "posture"} {
+			if strings.Contains(query, keyword) {
+				ops = append(ops, awsclient.LLMOperation{Operation: "analyze_ecr_image_scan", Reason: "Summarize known CVEs in the ECR images ECS and EKS workloads run", Parameters: map[string]any{}})
+				break
+			}
+		}
+	}
+	return ops
 }
 
-func generateCostOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
-	return []awsclient.LLMOperation{{Operation: "get_cost_and_usage", Reason: "Analyze spending", Parameters: map[string]any{}}}
+func generateCostOperations(ctx *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
+	ops := []awsclient.LLMOperation{{Operation: "get_cost_and_usage", Reason: "Analyze spending by service", Parameters: map[string]any{"group_by": "SERVICE"}}}
+	if tagKey := strings.TrimSpace(viper.GetString("aws.cost_tag_key")); tagKey != "" {
+		ops = append(ops, awsclient.LLMOperation{Operation: "get_cost_by_tag", Reason: "Break down spending by " + tagKey, Parameters: map[string]any{"tag_key": tagKey}})
+	}
+	if ctx != nil {
+		query := strings.ToLower(ctx.OriginalQuery)
+		for _, keyword := range []string{"spike", "anomal", "jump", "increase", "unexpected", "sudden"} {
+			if strings.Contains(query, keyword) {
+				ops = append(ops, awsclient.LLMOperation{Operation: "detect_cost_anomaly", Reason: "Find services with daily cost spikes", Parameters: map[string]any{}})
+				break
+			}
+		}
+		for _, keyword := range []string{"unused", "cleanup", "clean up", "save money", "orphan", "idle", "delete", "waste"} {
+			if strings.Contains(query, keyword) {
+				ops = append(ops, awsclient.LLMOperation{Operation: "find_orphaned_resources", Reason: "Find resources that bill while doing nothing", Parameters: map[string]any{}})
+				break
+			}
+		}
+	}
+	return ops
 }
 
-func generatePerformanceOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
+func generatePerformanceOperations(ctx *model.AgentContext, params model.AWSData) []awsclient.LLMOperation {
+	query := ""
+	if ctx != nil {
+		query = ctx.OriginalQuery
+	}
+	switch focus, _ := params["focus"].(string); focus {
+	case "lambda_config":
+		return []awsclient.LLMOperation{
+			{Operation: "audit_lambda_config", Reason: "Check Lambda timeouts, memory, failure handling, concurrency and runtimes", Parameters: map[string]any{"query": query}},
+			{Operation: "analyze_lambda_errors", Reason: "Pull recent errors for the function named in the query", Parameters: map[string]any{"query": query}},
+		}
+	case "xray_traces":
+		return []awsclient.LLMOperation{
+			{Operation: "get_xray_traces", Reason: "Find which downstream call dominates the latency of the slowest traced requests", Parameters: map[string]any{}},
+			{Operation: "describe_auto_scaling_groups", Reason: "Check scaling state", Parameters: map[string]any{}},
+		}
+	}
 	return []awsclient.LLMOperation{{Operation: "describe_auto_scaling_groups", Reason: "Check scaling state", Parameters: map[string]any{}}}
 }
 
-func generateDeploymentOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
+func generateDeploymentOperations(ctx *model.AgentContext, params model.AWSData) []awsclient.LLMOperation {
+	if focus, _ := params["focus"].(string); focus == "drift" {
+		query := ""
+		if ctx != nil {
+			query = ctx.OriginalQuery
+		}
+		return []awsclient.LLMOperation{
+			{Operation: "detect_stack_drift", Reason: "Find resources changed outside their CloudFormation templates", Parameters: map[string]any{"query": query}},
+		}
+	}
 	return []awsclient.LLMOperation{
 		{Operation: "list_codepipelines", Reason: "List active deployment pipelines", Parameters: map[string]any{}},
 		{Operation: "list_codebuild_projects", Reason: "Check build projects for recent failures", Parameters: map[string]any{}},
 	}
 }
 
-func generateDataPipelineOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
+func generateDataPipelineOperations(ctx *model.AgentContext, params model.AWSData) []awsclient.LLMOperation {
+	if focus, _ := params["focus"].(string); focus == "stepfunctions_failures" {
+		query := ""
+		if ctx != nil {
+			query = ctx.OriginalQuery
+		}
+		return []awsclient.LLMOperation{
+			{Operation: "analyze_stepfunctions_failures", Reason: "Find the state where recent Step Functions executions fail, with its error and cause", Parameters: map[string]any{"query": query}},
+		}
+	}
 	return []awsclient.LLMOperation{
 		{Operation: "list_glue_jobs", Reason: "Inspect Glue/ETL jobs", Parameters: map[string]any{}},
 		{Operation: "list_step_functions", Reason: "Check orchestration state machines", Parameters: map[string]any{}},
@@ -151,20 +397,94 @@ func generateDataPipelineOperations(_ *model.AgentContext, _ model.AWSData) []aw
 	}
 }
 
-func generateQueueOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
+func generateQueueOperations(ctx *model.AgentContext, params model.AWSData) []awsclient.LLMOperation {
+	query := ""
+	if ctx != nil {
+		query = ctx.OriginalQuery
+	}
+	if focus, _ := params["focus"].(string); focus == "stream_lag" {
+		return []awsclient.LLMOperation{
+			{Operation: "analyze_kinesis_lag", Reason: "Check Kinesis consumer lag, shard throttling and Firehose delivery failures", Parameters: map[string]any{"query": query}},
+			{Operation: "analyze_queue_health", Reason: "Flag dead-letter backlogs, stuck messages and failed SNS deliveries", Parameters: map[string]any{"query": query}},
+		}
+	}
 	return []awsclient.LLMOperation{
+		{Operation: "analyze_queue_health", Reason: "Flag dead-letter backlogs, stuck messages and failed SNS deliveries", Parameters: map[string]any{"query": query}},
 		{Operation: "list_sqs_queues", Reason: "List queues and their attributes", Parameters: map[string]any{}},
 		{Operation: "list_sns_topics", Reason: "Review SNS topics feeding queues", Parameters: map[string]any{}},
 	}
 }
 
-func generateAvailabilityOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
+func generateAvailabilityOperations(ctx *model.AgentContext, params model.AWSData) []awsclient.LLMOperation {
+	if focus, _ := params["focus"].(string); focus == "instance_health" {
+		query := ""
+		if ctx != nil {
+			query = ctx.OriginalQuery
+		}
+		return []awsclient.LLMOperation{
+			{Operation: "analyze_ec2_health", Reason: "Check EC2 status checks, state reasons and scheduled events", Parameters: map[string]any{"query": query}},
+		}
+	}
 	return []awsclient.LLMOperation{
 		{Operation: "check_route53_service", Reason: "Check DNS health", Parameters: map[string]any{}},
 		{Operation: "list_route53_zones", Reason: "Inspect hosted zones for issues", Parameters: map[string]any{}},
 	}
 }
 
+// quotaServiceCodes maps semantic service names to Service Quotas codes.
+var quotaServiceCodes = map[string]string{
+	"lambda":      "lambda",
+	"ec2":         "ec2",
+	"rds":         "rds",
+	"s3":          "s3",
+	"ecs":         "ecs",
+	"eks":         "eks",
+	"api_gateway": "apigateway",
+	"dynamodb":    "dynamodb",
+	"sqs":         "sqs",
+	"sns":         "sns",
+	"kinesis":     "kinesis",
+	"redshift":    "redshift",
+	"elasticache": "elasticache",
+	"cloudfront":  "cloudfront",
+	"route53":     "route53",
+}
+
+// defaultQuotaServices are checked when the query names no service.
+var defaultQuotaServices = []string{"lambda", "ec2", "vpc"}
+
+func generateCapacityOperations(ctx *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
+	var codes []string
+	if ctx != nil {
+		for _, service := range semantic.NewAnalyzer().AnalyzeQuery(ctx.OriginalQuery).TargetServices {
+			if code, ok := quotaServiceCodes[service]; ok {
+				codes = append(codes, code)
+			}
+		}
+	}
+	if len(codes) == 0 {
+		codes = defaultQuotaServices
+	}
+
+	ops := make([]awsclient.LLMOperation, 0, len(codes)+1)
+	if ctx != nil && isDynamoDBThrottlingQuery(strings.ToLower(ctx.OriginalQuery)) {
+		ops = append(ops, awsclient.LLMOperation{Operation: "analyze_dynamodb_throttling", Reason: "Find the throttled DynamoDB table or index and compare it with its capacity", Parameters: map[string]any{"query": ctx.OriginalQuery}})
+	}
+	for _, code := range uniqueStrings(codes) {
+		ops = append(ops, awsclient.LLMOperation{Operation: "get_service_quotas", Reason: "Check " + code + " quota utilization", Parameters: map[string]any{"service_code": code}})
+	}
+	return ops
+}
+
+// isDynamoDBThrottlingQuery reports whether a lowercased query is about
+// DynamoDB throttling or hot partitions.
+func isDynamoDBThrottlingQuery(query string) bool {
+	if !strings.Contains(query, "dynamo") && !strings.Contains(query, "ddb") {
+		return false
+	}
+	return strings.Contains(query, "throttl") || strings.Contains(query, "hot partition") || strings.Contains(query, "provisionedthroughputexceeded")
+}
+
 func generateLLMOperations(_ *model.AgentContext, _ model.AWSData) []awsclient.LLMOperation {
 	return []awsclient.LLMOperation{
 		{Operation: "list_bedrock_foundation_models", Reason: "Review Bedrock model status", Parameters: map[string]any{}},
@@ -28,13 +28,13 @@ func (c *Coordinator) minimalServiceDiscovery(ctx context.Context) (any, error)
 	services := make(map[string]any)
 	var errors []string
 
-	if lambdaResult, err := c.client.ExecuteOperation(ctx, "list_lambda_functions", map[string]any{}); err == nil {
+	if lambdaResult, err := c.executeOperation(ctx, "list_lambda_functions", map[string]any{}); err == nil {
 		services["lambda_functions"] = lambdaResult
 	} else {
 		errors = append(errors, fmt.Sprintf("lambda discovery: %v", err))
 	}
 
-	if logsResult, err := c.client.ExecuteOperation(ctx, "list_log_groups", map[string]any{}); err == nil {
+	if logsResult, err := c.executeOperation(ctx, "list_log_groups", map[string]any{}); err == nil {
 		services["log_groups"] = logsResult
 	} else {
 		errors = append(errors, fmt.Sprintf("log group discovery: %v", err))
@@ -81,7 +81,7 @@ func (c *Coordinator) fallbackLogInvestigation(ctx context.Context, discovered m
 		}
 		for _, name := range targets {
 			logGroup := fmt.Sprintf("/aws/lambda/%s", name)
-			out, err := c.client.ExecuteOperation(ctx, "get_recent_logs", map[string]any{
+			out, err := c.executeOperation(ctx, "get_recent_logs", map[string]any{
 				"log_group_name": logGroup,
 				"hours_back":     24,
 				"limit":          300,
@@ -94,33 +94,10 @@ func (c *Coordinator) fallbackLogInvestigation(ctx context.Context, discovered m
 	}
 
 	if strings.Contains(query, "api") || strings.Contains(query, "gateway") {
-		if data, ok := discovered["log_groups"]; ok {
-			lines := strings.Split(fmt.Sprintf("%v", data), "\n")
-			count := 0
-			for _, line := range lines {
-				if count >= 3 {
-					break
-				}
-				if strings.Contains(line, "API-Gateway-Execution-Logs") && strings.Contains(line, "|") {
-					parts := strings.Split(line, "|")
-					for _, part := range parts {
-						name := strings.TrimSpace(part)
-						if strings.HasPrefix(name, "/") {
-							out, err := c.client.ExecuteOperation(ctx, "get_recent_logs", map[string]any{
-								"log_group_name": name,
-								"hours_back":     24,
-								"limit":          200,
-								"filter_pattern": "?ERROR ?EXCEPTION ?5xx ?4xx",
-							})
-							if err == nil {
-								results[fmt.Sprintf("apigw_%d_logs", count)] = out
-								count++
-							}
-							break
-						}
-					}
-				}
-			}
+		op := apigwErrorsOperation(query)
+		// Without any API the general log scan below is the better answer.
+		if out, err := c.executeOperation(ctx, op.Operation, op.Parameters); err == nil && !strings.HasPrefix(out, "No API Gateway") {
+			results["apigw_errors"] = out
 		}
 	}
 
@@ -171,7 +148,7 @@ func (c *Coordinator) investigateGeneralLogs(ctx context.Context, logGroupData a
 	if logGroupName != "" {
 		params["log_group_name"] = logGroupName
 	}
-	return c.client.ExecuteOperation(ctx, "get_recent_logs", params)
+	return c.executeOperation(ctx, "get_recent_logs", params)
 }
 
 func (c *Coordinator) findRelevantServices(lambdaData any, query string) []string {
@@ -276,6 +253,34 @@ func uniqueStrings(in []string) []string {
 }
 
 func (c *Coordinator) lookupAgentType(name string) (AgentType, bool) {
+	agt, ok := agentTypeByName(name)
+	if !ok {
+		return AgentType{}, false
+	}
+	agt.Dependencies.WaitTimeout = ScaleWaitTimeout(agt.Dependencies.WaitTimeout, c.parallelTimeout)
+	return agt, true
+}
+
+// agentTypeNames lists every agent type agentTypeByName knows, in the
+// order the agents are documented.
+var agentTypeNames = []string{"k8s", "log", "metrics", "infrastructure", "security", "cost", "performance", "deployment", "datapipeline", "queue", "availability", "capacity", "llm"}
+
+// AgentTypeNames returns the names accepted by SetAgentOverrides.
+func AgentTypeNames() []string {
+	return append([]string(nil), agentTypeNames...)
+}
+
+// ValidateAgentTypes returns an error naming the first unknown agent type.
+func ValidateAgentTypes(names []string) error {
+	for _, name := range names {
+		if _, ok := agentTypeByName(name); !ok {
+			return fmt.Errorf("unknown agent type %q (available: %s)", name, strings.Join(agentTypeNames, ", "))
+		}
+	}
+	return nil
+}
+
+func agentTypeByName(name string) (AgentType, bool) {
 	switch name {
 	case "k8s":
 		return AgentTypeK8s, true
@@ -299,6 +304,8 @@ func (c *Coordinator) lookupAgentType(name string) (AgentType, bool) {
 		return AgentTypeQueue, true
 	case "availability":
 		return AgentTypeAvailability, true
+	case "capacity":
+		return AgentTypeCapacity, true
 	case "llm":
 		return AgentTypeLLM, true
 	default:
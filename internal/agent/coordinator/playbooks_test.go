@@ -3,6 +3,8 @@ package coordinator
 import (
 	"strings"
 	"testing"
+
+	"github.com/bgdnvk/clanker/internal/agent/model"
 )
 
 func TestExtractKeywords(t *testing.T) {
@@ -111,6 +113,61 @@ func TestInvestigateGeneralLogs_ExtractsLogGroup(t *testing.T) {
 	}
 }
 
+func TestGenerateInfrastructureOperations_APIGatewayErrors(t *testing.T) {
+	ops := generateInfrastructureOperations(&model.AgentContext{OriginalQuery: "API Gateway returning 5xx on orders"}, model.AWSData{})
+	if len(ops) != 1 || ops[0].Operation != "analyze_apigw_errors" {
+		t.Fatalf("expected analyze_apigw_errors ahead of the ALB check, got %+v", ops)
+	}
+	ops = generateInfrastructureOperations(&model.AgentContext{OriginalQuery: "alb returning 502"}, model.AWSData{})
+	if len(ops) != 1 || ops[0].Operation != "analyze_alb_errors" {
+		t.Fatalf("expected analyze_alb_errors for an ALB query, got %+v", ops)
+	}
+}
+
+func TestGenerateInfrastructureOperations_EKSIsAWord(t *testing.T) {
+	ops := generateInfrastructureOperations(&model.AgentContext{OriginalQuery: "pods crashlooping on eks"}, model.AWSData{})
+	if len(ops) == 0 || ops[0].Operation != "list_eks_clusters" {
+		t.Fatalf("expected EKS operations for an EKS query, got %+v", ops)
+	}
+	ops = generateInfrastructureOperations(&model.AgentContext{OriginalQuery: "cpu spikes over the last two weeks"}, model.AWSData{})
+	for _, op := range ops {
+		if op.Operation == "list_eks_clusters" {
+			t.Fatalf("\"weeks\" must not route to EKS, got %+v", ops)
+		}
+	}
+}
+
+func TestGenerateInfrastructureOperations_DeployNeedsECSSignal(t *testing.T) {
+	ops := generateInfrastructureOperations(&model.AgentContext{OriginalQuery: "why is the checkout service deployment stuck"}, model.AWSData{})
+	if len(ops) != 2 || ops[1].Operation != "analyze_ecs_service_events" {
+		t.Fatalf("expected ECS service events for a stuck service deployment, got %+v", ops)
+	}
+	ops = generateInfrastructureOperations(&model.AgentContext{OriginalQuery: "when did we last deploy the lambda"}, model.AWSData{})
+	if len(ops) > 0 && ops[0].Operation == "describe_ecs_clusters" {
+		t.Fatalf("a deploy question without an ECS signal must not route to ECS, got %+v", ops)
+	}
+	for _, op := range ops {
+		if op.Operation == "analyze_ecs_service_events" {
+			t.Fatalf("a deploy question without an ECS signal must not check ECS service events, got %+v", ops)
+		}
+	}
+}
+
+func TestGenerateMetricsOperations_ActiveAlarms(t *testing.T) {
+	ops := generateMetricsOperations(&model.AgentContext{OriginalQuery: "what's alarming in prod"}, model.AWSData{})
+	if len(ops) == 0 || ops[0].Operation != "analyze_active_alarms" {
+		t.Fatalf("expected analyze_active_alarms for an alarm query, got %+v", ops)
+	}
+	ops = generateMetricsOperations(&model.AgentContext{OriginalQuery: "show alarm state"}, model.AWSData{"focus": "alarms"})
+	if len(ops) == 0 || ops[0].Operation != "analyze_active_alarms" {
+		t.Fatalf("expected analyze_active_alarms for the alarms focus, got %+v", ops)
+	}
+	ops = generateMetricsOperations(&model.AgentContext{OriginalQuery: "why is the api slow"}, model.AWSData{})
+	if len(ops) == 0 || ops[0].Operation != "list_cloudwatch_alarms" {
+		t.Fatalf("expected the alarm list for a performance query, got %+v", ops)
+	}
+}
+
 // makeTestCoordinator creates a minimal coordinator for unit tests
 // that don't need a real AWS client.
 func makeTestCoordinator(t *testing.T) *Coordinator {
@@ -121,3 +178,35 @@ func makeTestCoordinator(t *testing.T) *Coordinator {
 		scheduler: NewDependencyScheduler(),
 	}
 }
+
+func TestGenerateDataPipelineOperations_StepFunctionsFailures(t *testing.T) {
+	ops := generateDataPipelineOperations(&model.AgentContext{OriginalQuery: "why is the order workflow failing"}, model.AWSData{"focus": "stepfunctions_failures"})
+	if len(ops) != 1 || ops[0].Operation != "analyze_stepfunctions_failures" || ops[0].Parameters["query"] != "why is the order workflow failing" {
+		t.Fatalf("expected analyze_stepfunctions_failures with the query, got %+v", ops)
+	}
+	ops = generateDataPipelineOperations(&model.AgentContext{OriginalQuery: "list glue jobs"}, model.AWSData{})
+	if len(ops) == 0 || ops[0].Operation != "list_glue_jobs" {
+		t.Fatalf("expected the pipeline overview without the focus, got %+v", ops)
+	}
+}
+
+func TestGenerateInfrastructureOperations_ASGActivity(t *testing.T) {
+	ops := generateInfrastructureOperations(&model.AgentContext{OriginalQuery: "autoscaling not working for web-asg"}, model.AWSData{"focus": "asg_activity"})
+	if len(ops) != 1 || ops[0].Operation != "analyze_asg_activity" {
+		t.Fatalf("expected analyze_asg_activity for the asg_activity focus, got %+v", ops)
+	}
+}
+
+func TestGeneratePerformanceOperations_XRayTraces(t *testing.T) {
+	ops := generatePerformanceOperations(&model.AgentContext{OriginalQuery: "why is checkout slow"}, model.AWSData{"focus": "xray_traces"})
+	if len(ops) == 0 || ops[0].Operation != "get_xray_traces" {
+		t.Fatalf("expected get_xray_traces first for the xray_traces focus, got %+v", ops)
+	}
+}
+
+func TestGenerateSecurityOperations_Findings(t *testing.T) {
+	ops := generateSecurityOperations(&model.AgentContext{OriginalQuery: "was the prod account compromised"}, model.AWSData{})
+	if len(ops) == 0 || ops[0].Operation != "get_security_findings" {
+		t.Fatalf("expected get_security_findings first, got %+v", ops)
+	}
+}
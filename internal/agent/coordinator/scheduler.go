@@ -3,6 +3,7 @@ package coordinator
 import (
 	"sort"
 
+	dt "github.com/bgdnvk/clanker/internal/agent/decisiontree"
 	"github.com/bgdnvk/clanker/internal/agent/model"
 )
 
@@ -11,6 +12,7 @@ type AgentConfig struct {
 	Priority   int
 	Parameters model.AWSData
 	AgentType  AgentType
+	Node       *dt.Node // node that selected this agent type
 }
 
 // OrderGroup represents a batch of agent configs that share the same execution order.
@@ -1,6 +1,9 @@
 package coordinator
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"sync"
 	"time"
 
@@ -48,6 +51,89 @@ func (b *SharedDataBus) HasAll(keys []string) bool {
 	return true
 }
 
+// OperationCache shares AWS operation results between the agents of one
+// coordinator, so identical (operation, parameters) pairs run once. Concurrent
+// callers wait for the first call; failed calls are not kept, so a later
+// caller runs the operation again.
+type OperationCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedOperation
+	hits    int
+}
+
+type cachedOperation struct {
+	done   chan struct{}
+	result string
+	err    error
+}
+
+// NewOperationCache returns an empty cache.
+func NewOperationCache() *OperationCache {
+	return &OperationCache{entries: make(map[string]*cachedOperation)}
+}
+
+// operationCacheKey identifies an operation call; json.Marshal sorts map keys
+// so equal parameter maps produce the same key.
+func operationCacheKey(operation string, params map[string]any) (string, bool) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+	return operation + "|" + string(encoded), true
+}
+
+// Do returns the cached result for operation and params, running fn when no
+// call is cached or in flight. shared reports whether the result came from
+// another caller.
+func (c *OperationCache) Do(ctx context.Context, operation string, params map[string]any, fn func() (string, error)) (result string, shared bool, err error) {
+	key, ok := operationCacheKey(operation, params)
+	if !ok {
+		result, err = fn()
+		return result, false, err
+	}
+
+	for {
+		c.mu.Lock()
+		entry, exists := c.entries[key]
+		if !exists {
+			entry = &cachedOperation{done: make(chan struct{})}
+			c.entries[key] = entry
+			c.mu.Unlock()
+
+			entry.result, entry.err = fn()
+			if entry.err != nil {
+				c.mu.Lock()
+				delete(c.entries, key)
+				c.mu.Unlock()
+			}
+			close(entry.done)
+			return entry.result, false, entry.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		}
+		// The first caller ran out of time; run it under our own context.
+		if errors.Is(entry.err, context.Canceled) || errors.Is(entry.err, context.DeadlineExceeded) {
+			continue
+		}
+		c.mu.Lock()
+		c.hits++
+		c.mu.Unlock()
+		return entry.result, true, entry.err
+	}
+}
+
+// Hits returns how many calls were served by another caller's result.
+func (c *OperationCache) Hits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits
+}
+
 // AgentStats tracks counts for coordinator telemetry.
 type AgentStats struct {
 	Total     int
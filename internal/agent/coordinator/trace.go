@@ -0,0 +1,48 @@
+package coordinator
+
+import (
+	dt "github.com/bgdnvk/clanker/internal/agent/decisiontree"
+	"github.com/bgdnvk/clanker/internal/agent/model"
+)
+
+// Trace reports the decision path, the keywords behind each matched node and
+// the agents spawned from them. Call it after SpawnAgents has returned.
+func (c *Coordinator) Trace() *model.CoordinatorTrace {
+	trace := &model.CoordinatorTrace{
+		Query: c.query,
+		Path:  append([]string(nil), c.DecisionTree.CurrentPath...),
+	}
+	for _, node := range c.matched {
+		trace.Nodes = append(trace.Nodes, model.TraceNode{
+			ID:              node.ID,
+			Name:            node.Name,
+			Condition:       node.Condition,
+			MatchedKeywords: dt.MatchedKeywords(node.Condition, c.query),
+			AgentTypes:      node.AgentTypes,
+		})
+	}
+
+	for _, p := range c.planned {
+		entry := model.TraceAgent{
+			AgentType: p.cfg.AgentType.Name,
+			Priority:  p.cfg.Priority,
+			Status:    "skipped: dependencies not ready",
+		}
+		if p.cfg.Node != nil {
+			entry.NodeID = p.cfg.Node.ID
+			entry.NodeName = p.cfg.Node.Name
+			entry.MatchedKeywords = dt.MatchedKeywords(p.cfg.Node.Condition, c.query)
+		}
+		if p.agent != nil {
+			entry.Status = p.agent.Status
+			for _, op := range p.agent.Operations {
+				entry.Operations = append(entry.Operations, op.Operation)
+			}
+			if p.agent.Error != nil {
+				entry.Error = p.agent.Error.Error()
+			}
+		}
+		trace.Agents = append(trace.Agents, entry)
+	}
+	return trace
+}
@@ -52,28 +52,110 @@ func New() *Tree {
 			AgentTypes: []string{"infrastructure"},
 			Parameters: model.AWSData{"scope": "targeted", "priority": "high"},
 		},
+		// Plain "errors" only warrants metrics when the query is not a log lookup.
 		{
 			ID:         "performance_check",
 			Name:       "Performance investigation",
-			Condition:  "contains_keywords(['performance', 'slow', 'laggy', 'lag', 'throughput', 'latency', 'jitter', 'metrics', 'cpu', 'memory', 'throttle', 'overload', 'errors'])",
+			Condition:  "or(contains_keywords(['performance', 'slow', 'laggy', 'lag', 'throughput', 'latency', 'jitter', 'metrics', 'cpu', 'memory', 'throttle', 'overload']), and(contains_keywords(['errors']), not_contains_keywords(['log', 'stacktrace', 'trace', 'exception', 'panic', 'crash'])))",
 			Action:     "focused_performance_check",
 			Priority:   7,
 			AgentTypes: []string{"metrics"},
 			Parameters: model.AWSData{"focus": "key_metrics", "priority": "medium"},
 		},
+		{
+			ID:         "active_alarms",
+			Name:       "Firing or misconfigured alarms",
+			Condition:  "contains_keywords(['alarm', 'alarming', 'alerting', 'alerts firing', 'firing', 'monitoring'])",
+			Action:     "analyze_active_alarms",
+			Priority:   8,
+			AgentTypes: []string{"metrics"},
+			Parameters: model.AWSData{"focus": "alarms"},
+		},
+		{
+			ID:         "lambda_config_audit",
+			Name:       "Lambda reliability or cost",
+			Condition:  "and(contains_keywords(['lambda', 'serverless function']), contains_keywords(['reliab', 'cost', 'expensive', 'timeout', 'timing out', 'memory', 'misconfig', 'config', 'audit', 'dead letter', 'dlq', 'runtime', 'deprecat', 'concurrency', 'failing', 'retries']))",
+			Action:     "audit_lambda_config",
+			Priority:   8,
+			AgentTypes: []string{"performance"},
+			Parameters: model.AWSData{"focus": "lambda_config"},
+		},
+		{
+			ID:         "xray_traces",
+			Name:       "Slow requests traced with X-Ray",
+			Condition:  "contains_keywords(['x-ray', 'xray', 'distributed trac', 'latency', 'response time', 'slow', 'taking long', 'takes long', 'p99', 'p95'])",
+			Action:     "get_xray_traces",
+			Priority:   7,
+			AgentTypes: []string{"performance"},
+			Parameters: model.AWSData{"focus": "xray_traces"},
+		},
+		{
+			ID:         "database_performance",
+			Name:       "Slow database or connection exhaustion",
+			Condition:  "or(contains_keywords(['too many connections', 'max_connections', 'connection pool']), and(contains_keywords(['database', 'rds', 'postgres', 'mysql', 'aurora']), contains_keywords(['slow', 'latency', 'connections', 'timeout', 'performance'])))",
+			Action:     "analyze_rds_performance",
+			Priority:   8,
+			AgentTypes: []string{"metrics"},
+			Parameters: model.AWSData{"focus": "database"},
+		},
+		{
+			ID:         "network_connectivity",
+			Name:       "Network connectivity failure",
+			Condition:  "contains_keywords(['connect to', 'cannot connect', 'unable to connect', 'unreachable', 'cannot reach', 'reach the', 'connection timed out', 'connectivity', 'security group', 'nacl', 'route table', 'nat gateway'])",
+			Action:     "analyze_connectivity",
+			Priority:   9,
+			AgentTypes: []string{"infrastructure"},
+			Parameters: model.AWSData{"focus": "connectivity"},
+		},
+		{
+			ID:         "environment_diff",
+			Name:       "Differences between environments",
+			Condition:  "contains_keywords(['compare environments', 'compare staging', 'compare prod', 'difference between', 'differences between', 'different between', 'diff between', 'differ from', 'staging vs prod', 'staging and prod'])",
+			Action:     "compare_environments",
+			Priority:   10,
+			AgentTypes: []string{"infrastructure"},
+			Parameters: model.AWSData{"focus": "environment_diff"},
+		},
+		{
+			ID:         "asg_activity",
+			Name:       "Auto Scaling not scaling or launching",
+			Condition:  "or(contains_keywords(['not scaling', 'not scaling out', 'failed to launch', 'launch failure', 'scaling failure', 'desired capacity']), and(contains_keywords(['autoscaling', 'auto scaling', 'auto-scaling', 'asg', 'scaling group']), contains_keywords(['not ', 'fail', 'error', 'stuck', 'loop', 'unhealthy', 'launch', 'desired', 'terminat'])))",
+			Action:     "analyze_asg_activity",
+			Priority:   8,
+			AgentTypes: []string{"infrastructure"},
+			Parameters: model.AWSData{"focus": "asg_activity"},
+		},
+		{
+			ID:         "capacity_quota",
+			Name:       "Capacity or quota headroom",
+			Condition:  "contains_keywords(['quota', 'limit', 'capacity', 'throttle', 'throttling', 'throttled', 'concurrency', 'headroom', 'hot partition', 'provisionedthroughputexceeded'])",
+			Action:     "check_service_quotas",
+			Priority:   8,
+			AgentTypes: []string{"capacity"},
+			Parameters: model.AWSData{"focus": "utilization"},
+		},
 		{
 			ID:         "security_alerts",
 			Name:       "Security or IAM issues",
-			Condition:  "contains_keywords(['security', 'breach', 'breached', 'unauthorized', 'iam', 'key', 'credential', 'secret', 'token', 'rotate', 'privilege', 'access', 'leak', 'compromise'])",
+			Condition:  "contains_keywords(['security', 'breach', 'breached', 'unauthorized', 'iam', 'key', 'credential', 'secret', 'token', 'rotate', 'privilege', 'access', 'leak', 'compromise', 'findings', 'guardduty', 'security hub', 'threat'])",
 			Action:     "investigate_security",
 			Priority:   9,
 			AgentTypes: []string{"security"},
 			Parameters: model.AWSData{"priority": "high"},
 		},
+		{
+			ID:         "public_exposure",
+			Name:       "Publicly exposed data",
+			Condition:  "or(contains_keywords(['publicly', 'exposed', 'exposure', 's3 security']), and(contains_keywords(['public']), contains_keywords(['s3', 'bucket', 'data', 'object'])))",
+			Action:     "audit_s3_bucket",
+			Priority:   9,
+			AgentTypes: []string{"security"},
+			Parameters: model.AWSData{"priority": "high"},
+		},
 		{
 			ID:         "cost_anomaly",
 			Name:       "Cost and usage anomaly",
-			Condition:  "contains_keywords(['cost', 'spend', 'bill', 'billing', 'budget', 'usage', 'savings', 'expensive', 'increase', 'spike', 'surge'])",
+			Condition:  "contains_keywords(['cost', 'spend', 'bill', 'billing', 'budget', 'usage', 'savings', 'expensive', 'increase', 'spike', 'surge', 'unused', 'cleanup', 'clean up', 'save money', 'orphaned'])",
 			Action:     "investigate_costs",
 			Priority:   6,
 			AgentTypes: []string{"cost"},
@@ -88,6 +170,24 @@ func New() *Tree {
 			AgentTypes: []string{"deployment"},
 			Parameters: model.AWSData{"scope": "recent"},
 		},
+		{
+			ID:         "stack_drift",
+			Name:       "Infrastructure drift from templates",
+			Condition:  "contains_keywords(['drift', 'drifted', 'out of sync', 'manual change', 'manually changed', 'changed in the console'])",
+			Action:     "detect_stack_drift",
+			Priority:   9,
+			AgentTypes: []string{"deployment"},
+			Parameters: model.AWSData{"focus": "drift"},
+		},
+		{
+			ID:         "stepfunctions_failures",
+			Name:       "Failing Step Functions executions",
+			Condition:  "and(contains_keywords(['step function', 'stepfunction', 'state machine', 'workflow', 'sfn']), contains_keywords(['fail', 'error', 'broken', 'timed out', 'timing out', 'aborted']))",
+			Action:     "analyze_stepfunctions_failures",
+			Priority:   8,
+			AgentTypes: []string{"datapipeline"},
+			Parameters: model.AWSData{"focus": "stepfunctions_failures"},
+		},
 		{
 			ID:         "data_pipeline_issues",
 			Name:       "Data or ETL pipeline failures",
@@ -100,12 +200,21 @@ func New() *Tree {
 		{
 			ID:         "queue_backlog",
 			Name:       "Queue depth or backlog",
-			Condition:  "contains_keywords(['queue', 'queued', 'backlog', 'message', 'kafka', 'sqs', 'sns', 'pubsub', 'mq', 'dlq', 'stuck'])",
+			Condition:  "contains_keywords(['queue', 'queued', 'backlog', 'message', 'kafka', 'sqs', 'sns', 'pubsub', 'mq', 'dlq', 'dead letter', 'dead-letter', 'stuck', 'backing up'])",
 			Action:     "inspect_queue_health",
 			Priority:   7,
 			AgentTypes: []string{"queue"},
 			Parameters: model.AWSData{"focus": "backlog"},
 		},
+		{
+			ID:         "stream_lag",
+			Name:       "Streaming consumer lag",
+			Condition:  "or(contains_keywords(['stream backing up', 'streams backing up', 'iterator age', 'backpressure', 'back pressure']), and(contains_keywords(['lag', 'behind']), contains_keywords(['stream', 'kinesis', 'firehose', 'shard', 'consumer'])))",
+			Action:     "analyze_kinesis_lag",
+			Priority:   8,
+			AgentTypes: []string{"queue"},
+			Parameters: model.AWSData{"focus": "stream_lag"},
+		},
 		{
 			ID:         "availability_incident",
 			Name:       "Availability or outage reports",
@@ -115,6 +224,15 @@ func New() *Tree {
 			AgentTypes: []string{"availability"},
 			Parameters: model.AWSData{"priority": "critical"},
 		},
+		{
+			ID:         "instance_health",
+			Name:       "EC2 instance down or unreachable",
+			Condition:  "or(contains_keywords(['status check', 'impaired', 'instance retirement', 'scheduled maintenance', 'spot interruption', 'insufficient capacity']), and(contains_keywords(['instance', 'ec2', 'server ', 'vm ']), contains_keywords(['down', 'unreachable', 'not responding', 'unresponsive', 'cannot ssh', 'cannot connect', 'stopped', 'terminated', 'retire']), not_contains_keywords(['rds', 'database', 'db instance'])))",
+			Action:     "analyze_ec2_health",
+			Priority:   10,
+			AgentTypes: []string{"availability"},
+			Parameters: model.AWSData{"focus": "instance_health"},
+		},
 		{
 			ID:         "llm_observability",
 			Name:       "LLM / inference support",
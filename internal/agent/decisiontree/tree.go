@@ -49,27 +49,150 @@ func (t *Tree) traverseNode(node *Node, query string, ctx *model.AgentContext, a
 	}
 }
 
+// evaluateCondition reports whether condition matches the query. The grammar
+// is deliberately tiny:
+//
+//	always
+//	contains_keywords(['a', 'b'])      any keyword appears in the query
+//	not_contains_keywords(['a', 'b'])  no keyword appears in the query
+//	and(cond, cond, ...)               every clause matches
+//	or(cond, cond, ...)                at least one clause matches
+//	not(cond)                          the clause does not match
+//
+// Malformed or unknown conditions never match.
 func (t *Tree) evaluateCondition(condition string, query string) bool {
-	queryLower := strings.ToLower(query)
+	return evaluateCondition(strings.TrimSpace(condition), strings.ToLower(query))
+}
 
+func evaluateCondition(condition, queryLower string) bool {
 	switch {
 	case condition == "always":
 		return true
+	case strings.HasPrefix(condition, "not_contains_keywords("):
+		keywords, ok := parseKeywords(condition)
+		return ok && !containsAnyKeyword(queryLower, keywords)
 	case strings.HasPrefix(condition, "contains_keywords"):
-		start := strings.Index(condition, "['")
-		end := strings.Index(condition, "']")
-		if start == -1 || end == -1 {
+		keywords, ok := parseKeywords(condition)
+		return ok && containsAnyKeyword(queryLower, keywords)
+	case strings.HasPrefix(condition, "and("):
+		clauses, ok := conditionArgs(condition, "and")
+		if !ok || len(clauses) == 0 {
 			return false
 		}
-		keywordsStr := condition[start+2 : end]
-		parts := strings.Split(keywordsStr, "', '")
-		for _, keyword := range parts {
-			if strings.Contains(queryLower, strings.ToLower(keyword)) {
+		for _, clause := range clauses {
+			if !evaluateCondition(clause, queryLower) {
+				return false
+			}
+		}
+		return true
+	case strings.HasPrefix(condition, "or("):
+		clauses, ok := conditionArgs(condition, "or")
+		if !ok {
+			return false
+		}
+		for _, clause := range clauses {
+			if evaluateCondition(clause, queryLower) {
 				return true
 			}
 		}
 		return false
+	case strings.HasPrefix(condition, "not("):
+		clauses, ok := conditionArgs(condition, "not")
+		if !ok || len(clauses) != 1 {
+			return false
+		}
+		return !evaluateCondition(clauses[0], queryLower)
 	default:
 		return false
 	}
 }
+
+// parseKeywords extracts the quoted keyword list of a keywords condition.
+func parseKeywords(condition string) ([]string, bool) {
+	start := strings.Index(condition, "['")
+	end := strings.LastIndex(condition, "']")
+	if start == -1 || end == -1 || end < start+2 {
+		return nil, false
+	}
+	return strings.Split(condition[start+2:end], "', '"), true
+}
+
+func containsAnyKeyword(queryLower string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(queryLower, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionArgs splits name(a, b, ...) into its top-level clauses, ignoring
+// commas nested inside parentheses, brackets or quotes.
+func conditionArgs(condition, name string) ([]string, bool) {
+	if !strings.HasPrefix(condition, name+"(") || !strings.HasSuffix(condition, ")") {
+		return nil, false
+	}
+	body := condition[len(name)+1 : len(condition)-1]
+
+	var (
+		clauses []string
+		depth   int
+		quoted  bool
+		start   int
+	)
+	for i, r := range body {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			depth--
+			if depth < 0 {
+				return nil, false
+			}
+		case r == ',' && depth == 0:
+			clauses = append(clauses, strings.TrimSpace(body[start:i]))
+			start = i + 1
+		}
+	}
+	if depth != 0 || quoted {
+		return nil, false
+	}
+	if last := strings.TrimSpace(body[start:]); last != "" || len(clauses) > 0 {
+		clauses = append(clauses, last)
+	}
+	return clauses, true
+}
+
+// MatchedKeywords returns the keywords of condition's positive
+// contains_keywords clauses that appear in query, explaining why a node
+// fired. Clauses under not(...) and not_contains_keywords are skipped since
+// they match on absence.
+func MatchedKeywords(condition, query string) []string {
+	var matched []string
+	seen := make(map[string]bool)
+	collectMatchedKeywords(strings.TrimSpace(condition), strings.ToLower(query), &matched, seen)
+	return matched
+}
+
+func collectMatchedKeywords(condition, queryLower string, matched *[]string, seen map[string]bool) {
+	switch {
+	case strings.HasPrefix(condition, "contains_keywords"):
+		keywords, _ := parseKeywords(condition)
+		for _, keyword := range keywords {
+			lower := strings.ToLower(keyword)
+			if !seen[lower] && strings.Contains(queryLower, lower) {
+				seen[lower] = true
+				*matched = append(*matched, keyword)
+			}
+		}
+	case strings.HasPrefix(condition, "and("), strings.HasPrefix(condition, "or("):
+		name := condition[:strings.Index(condition, "(")]
+		clauses, _ := conditionArgs(condition, name)
+		for _, clause := range clauses {
+			collectMatchedKeywords(clause, queryLower, matched, seen)
+		}
+	}
+}
@@ -104,6 +104,56 @@ func TestTraverse_CostKeywordMatch(t *testing.T) {
 	}
 }
 
+func TestTraverse_CapacityKeywordMatch(t *testing.T) {
+	tree := New()
+	nodes := tree.Traverse("how much headroom is left on the lambda concurrency limit", nil)
+
+	for _, n := range nodes {
+		if n.ID == "capacity_quota" {
+			if len(n.AgentTypes) != 1 || n.AgentTypes[0] != "capacity" {
+				t.Errorf("expected capacity agent, got %v", n.AgentTypes)
+			}
+			return
+		}
+	}
+	t.Error("expected 'capacity_quota' node to match for query containing 'headroom'")
+}
+
+func TestTraverse_DatabasePerformanceMatch(t *testing.T) {
+	tree := New()
+	for _, query := range []string{"why is the orders database so slow", "postgres says too many connections"} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "database_performance" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected 'database_performance' node to match %q", query)
+		}
+	}
+	for _, n := range tree.Traverse("list my rds instances", nil) {
+		if n.ID == "database_performance" {
+			t.Error("plain RDS listing should not trigger the database performance check")
+		}
+	}
+}
+
+func TestTraverse_NetworkConnectivityMatch(t *testing.T) {
+	tree := New()
+	for _, query := range []string{"i-0abc12345678 can't connect to the orders database", "why can my app not reach the internet"} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "network_connectivity" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected 'network_connectivity' node to match %q", query)
+		}
+	}
+}
+
 func TestTraverse_NoExtraMatchForUnrelatedQuery(t *testing.T) {
 	tree := New()
 	// This query should NOT match k8s, security, cost, etc.
@@ -182,3 +232,287 @@ func TestTraverse_ChildNodesMatch(t *testing.T) {
 		t.Error("expected child node 'service_logs' to match")
 	}
 }
+
+func TestEvaluateCondition_NotContainsKeywords(t *testing.T) {
+	tree := New()
+	if !tree.evaluateCondition("not_contains_keywords(['metrics', 'cpu'])", "show me the logs") {
+		t.Error("expected a match when no keyword appears")
+	}
+	if tree.evaluateCondition("not_contains_keywords(['metrics', 'cpu'])", "cpu is pegged") {
+		t.Error("expected no match when a keyword appears")
+	}
+	if tree.evaluateCondition("not_contains_keywords(broken)", "anything") {
+		t.Error("expected malformed negation to return false")
+	}
+}
+
+func TestEvaluateCondition_BooleanComposition(t *testing.T) {
+	tree := New()
+	cond := "and(contains_keywords(['logs', 'log']), not(contains_keywords(['metrics'])))"
+	if !tree.evaluateCondition(cond, "check the api logs") {
+		t.Error("expected logs AND NOT metrics to match a pure log query")
+	}
+	if tree.evaluateCondition(cond, "check the api logs and metrics") {
+		t.Error("expected logs AND NOT metrics to reject a query mentioning metrics")
+	}
+
+	or := "or(contains_keywords(['cost']), and(contains_keywords(['bill']), always))"
+	if !tree.evaluateCondition(or, "why is my bill high") || tree.evaluateCondition(or, "hello") {
+		t.Error("unexpected result for nested or/and")
+	}
+
+	for _, bad := range []string{"and()", "and(always", "not(always, always)", "or(contains_keywords(['a')"} {
+		if tree.evaluateCondition(bad, "a") {
+			t.Errorf("expected malformed condition %q to return false", bad)
+		}
+	}
+}
+
+func TestTraverse_PureLogQuerySkipsMetrics(t *testing.T) {
+	tree := New()
+	for _, n := range tree.Traverse("show recent errors in the payment logs", nil) {
+		if n.ID == "performance_check" {
+			t.Error("expected a log query about errors not to spawn the metrics agent")
+		}
+	}
+	found := false
+	for _, n := range tree.Traverse("why are there so many errors", nil) {
+		if n.ID == "performance_check" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a non-log errors query to still reach the metrics agent")
+	}
+}
+
+func TestMatchedKeywords(t *testing.T) {
+	cond := "or(contains_keywords(['latency', 'cpu']), and(contains_keywords(['errors']), not_contains_keywords(['log'])))"
+	got := MatchedKeywords(cond, "CPU spikes and errors on checkout")
+	if len(got) != 2 || got[0] != "cpu" || got[1] != "errors" {
+		t.Errorf("expected [cpu errors], got %v", got)
+	}
+	if got := MatchedKeywords("always", "anything"); len(got) != 0 {
+		t.Errorf("expected no keywords for always, got %v", got)
+	}
+	if got := MatchedKeywords("not(contains_keywords(['cost']))", "show cost"); len(got) != 0 {
+		t.Errorf("negated clauses should not report keywords, got %v", got)
+	}
+}
+
+func TestTraverse_StackDriftMatch(t *testing.T) {
+	tree := New()
+	for _, query := range []string{"is the prod stack out of sync with its template", "did anyone make a manual change to our infra"} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "stack_drift" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected 'stack_drift' node to match %q", query)
+		}
+	}
+}
+
+func TestTraverse_EnvironmentDiffMatch(t *testing.T) {
+	tree := New()
+	found := false
+	for _, n := range tree.Traverse("what's different between staging and prod", nil) {
+		if n.ID == "environment_diff" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected 'environment_diff' node to match")
+	}
+}
+
+func TestTraverse_LambdaConfigAuditMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"why is our lambda bill so expensive": true,
+		"checkout lambda keeps timing out":    true,
+		"list my lambda functions":            false,
+		"reduce cost of our ec2 fleet":        false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "lambda_config_audit" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("lambda_config_audit match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestTraverse_PublicExposureMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"are any of our s3 buckets public":    true,
+		"is customer data exposed":            true,
+		"review s3 security":                  true,
+		"which subnets have a public ip":      false,
+		"list the objects in my upload store": false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "public_exposure" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("public_exposure match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestTraverse_StreamLagMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"why is the kinesis consumer lagging":      true,
+		"our clickstream is backing up":            false,
+		"the orders stream backing up since noon":  true,
+		"consumers are behind on the events shard": true,
+		"what is the iterator age on ingest":       true,
+		"why is the api slow":                      false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "stream_lag" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("stream_lag match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestTraverse_InstanceHealthMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"my ec2 instance is unreachable":          true,
+		"web-1 instance went down overnight":      true,
+		"why did the status check fail on web-1":  true,
+		"is there a scheduled maintenance coming": true,
+		"the rds instance is down":                false,
+		"why is the checkout api slow":            false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "instance_health" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("instance_health match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestTraverse_ActiveAlarmsMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"what's alarming right now":           true,
+		"which alarms are firing in prod":     true,
+		"is our monitoring set up correctly":  true,
+		"why is the checkout api slow":        false,
+		"show me the lambda logs for billing": false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "active_alarms" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("active_alarms match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestTraverse_StepFunctionsFailuresMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"why is the order state machine failing":    true,
+		"step function executions keep timing out":  true,
+		"which workflow errors happened last night": true,
+		"list my step functions":                    false,
+		"why is checkout failing":                   false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "stepfunctions_failures" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("stepfunctions_failures match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestTraverse_ASGActivityMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"autoscaling not working for the web tier": true,
+		"why is my asg stuck below desired":        true,
+		"web instances failed to launch":           true,
+		"list my auto scaling groups":              false,
+		"why is checkout slow":                     false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "asg_activity" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("asg_activity match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestTraverse_XRayTracesMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"why is checkout slow":                     true,
+		"p99 latency on the orders api doubled":    true,
+		"show me x-ray traces for the payment api": true,
+		"list my lambda functions":                 false,
+		"why did the deploy fail":                  false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "xray_traces" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("xray_traces match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestTraverse_SecurityFindingsMatch(t *testing.T) {
+	tree := New()
+	for query, want := range map[string]bool{
+		"any open guardduty findings":      true,
+		"was the prod account compromised": true,
+		"summarize our security posture":   true,
+		"why is checkout slow":             false,
+	} {
+		found := false
+		for _, n := range tree.Traverse(query, nil) {
+			if n.ID == "security_alerts" {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("security_alerts match for %q = %v, want %v", query, found, want)
+		}
+	}
+}
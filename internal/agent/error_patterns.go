@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// errorSampleMaxLen bounds the representative line kept per category.
+const errorSampleMaxLen = 240
+
+// errorCategoryPatterns are the built-in failure classes, checked in order;
+// a log line counts toward the first category it matches, so the narrower
+// classes (rate limits, OOM) come before the broad ones (timeouts).
+var errorCategoryPatterns = []struct {
+	name     string
+	patterns []string
+}{
+	{"oom", []string{
+		`(?i)out of ?memory|outofmemoryerror|oom[- ]?kill|heap out of memory`,
+		`(?i)memory size exceeded|cannot allocate memory|signal: killed`,
+	}},
+	{"permission", []string{
+		`(?i)access ?denied|not authorized to perform|unauthorizedoperation|permission denied`,
+		`(?i)\bforbidden\b|\b403\b|invalidclienttokenid|expiredtoken`,
+	}},
+	{"rate_limit", []string{
+		`(?i)\b429\b|too many requests|throttl|rate exceeded|rate limit`,
+		`(?i)requestlimitexceeded|provisionedthroughputexceeded|slowdown`,
+	}},
+	{"network", []string{
+		`(?i)connection (refused|reset|failed|closed)|econnrefused|econnreset|broken pipe`,
+		`(?i)no such host|enotfound|getaddrinfo|name resolution|\bdns\b|network is unreachable|no route to host`,
+	}},
+	{"dependency_timeout", []string{
+		`(?i)timed out|timeout|deadline exceeded|etimedout|\b504\b`,
+	}},
+	{"stacktrace", []string{
+		`(?i)nullpointerexception|nil pointer dereference|panic:|segmentation fault`,
+		`(?i)cannot read propert(y|ies) of (undefined|null)|'nonetype' object|traceback \(most recent call last\)`,
+	}},
+	{"config_missing", []string{
+		`(?i)missing (required )?(env|environment variable|config|configuration|parameter)`,
+		`(?i)environment variable \S+ (is )?(not set|required|missing)|keyerror|config(uration)? (file )?not found`,
+	}},
+}
+
+// errorClassifier is a compiled failure category.
+type errorClassifier struct {
+	name     string
+	patterns []*regexp.Regexp
+}
+
+// errorClassifiers compiles the built-in categories plus agent.error_patterns
+// from config, a map of category name to regexes. Patterns for a built-in
+// category extend it; other names become new categories checked last.
+// Invalid regexes are skipped.
+func errorClassifiers() []errorClassifier {
+	extra := viper.GetStringMapStringSlice("agent.error_patterns")
+	verbose := viper.GetBool("debug")
+	compile := func(name string, patterns []string) []*regexp.Regexp {
+		var out []*regexp.Regexp
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				if verbose {
+					fmt.Printf("⚠️  Ignoring invalid error pattern for %s: %v\n", name, err)
+				}
+				continue
+			}
+			out = append(out, re)
+		}
+		return out
+	}
+
+	classifiers := make([]errorClassifier, 0, len(errorCategoryPatterns)+len(extra))
+	builtin := make(map[string]bool, len(errorCategoryPatterns))
+	for _, category := range errorCategoryPatterns {
+		builtin[category.name] = true
+		patterns := append(append([]string{}, category.patterns...), extra[category.name]...)
+		classifiers = append(classifiers, errorClassifier{name: category.name, patterns: compile(category.name, patterns)})
+	}
+
+	custom := make([]string, 0, len(extra))
+	for name := range extra {
+		if !builtin[name] {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	for _, name := range custom {
+		if patterns := compile(name, extra[name]); len(patterns) > 0 {
+			classifiers = append(classifiers, errorClassifier{name: name, patterns: patterns})
+		}
+	}
+	return classifiers
+}
+
+// classifyErrorLines assigns each line to the first matching category and
+// returns the categories found, most frequent first, with the first matching
+// line as the sample.
+func classifyErrorLines(lines []string, classifiers []errorClassifier) []ErrorCategory {
+	index := make(map[string]int)
+	var categories []ErrorCategory
+	for _, line := range lines {
+		for _, classifier := range classifiers {
+			if !matchesAny(classifier.patterns, line) {
+				continue
+			}
+			if i, ok := index[classifier.name]; ok {
+				categories[i].Count++
+			} else {
+				index[classifier.name] = len(categories)
+				categories = append(categories, ErrorCategory{Name: classifier.name, Count: 1, Sample: truncateSample(line)})
+			}
+			break
+		}
+	}
+	sort.SliceStable(categories, func(i, j int) bool { return categories[i].Count > categories[j].Count })
+	return categories
+}
+
+func matchesAny(patterns []*regexp.Regexp, line string) bool {
+	for _, re := range patterns {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateSample(line string) string {
+	runes := []rune(strings.TrimSpace(line))
+	if len(runes) <= errorSampleMaxLen {
+		return string(runes)
+	}
+	return string(runes[:errorSampleMaxLen]) + "..."
+}
+
+// formatErrorPatterns renders ErrorPatterns as the top failure categories
+// for the final context.
+func formatErrorPatterns(patterns ErrorPatterns) string {
+	var b strings.Builder
+	analyzed, _ := patterns["total_logs_analyzed"].(int)
+	errorCount, _ := patterns["total_errors"].(int)
+	b.WriteString(fmt.Sprintf("Analyzed %d log lines, %d mention errors\n", analyzed, errorCount))
+
+	categories, _ := patterns["categories"].([]ErrorCategory)
+	if len(categories) == 0 {
+		b.WriteString("No known failure categories matched.\n")
+		return b.String()
+	}
+	b.WriteString("Top failure categories:\n")
+	for _, category := range categories {
+		b.WriteString(fmt.Sprintf("- %s x%d: %s\n", category.Name, category.Count, category.Sample))
+	}
+	return b.String()
+}
@@ -0,0 +1,242 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serviceHealthKey holds the []ServiceHealthReport derived at the end of an
+// investigation.
+const serviceHealthKey = "service_health"
+
+// Health metrics recorded as HealthTrend points.
+const (
+	healthMetricErrors    = "errors"
+	healthMetricThrottles = "throttles"
+	healthMetricUnhealthy = "unhealthy_targets"
+)
+
+// healthUnhealthyErrorCount is the error count at which a service is
+// unhealthy rather than degraded.
+const healthUnhealthyErrorCount = 50
+
+// ServiceHealthReport is the health derived for one service in an
+// investigation, with what got worse since the previous one.
+type ServiceHealthReport struct {
+	Service          string   `json:"service"`
+	Status           string   `json:"status"`
+	Errors           int      `json:"errors"`
+	Throttles        int      `json:"throttles"`
+	UnhealthyTargets int      `json:"unhealthy_targets"`
+	Trends           []string `json:"trends,omitempty"`
+}
+
+var (
+	albTargetsHealthyPattern = regexp.MustCompile(`(\d+)/(\d+) targets healthy`)
+	unhealthyTargetPattern   = regexp.MustCompile(`"State":\s*"unhealthy"`)
+)
+
+// assessServiceHealth scores every service the investigation gathered data
+// for, records the result in memory when it is enabled, and stores the
+// reports under serviceHealthKey for the final report.
+func (a *Agent) assessServiceHealth(agentCtx *AgentContext, intent QueryIntent, now time.Time) {
+	var reports []ServiceHealthReport
+	for _, service := range healthServices(agentCtx, intent) {
+		report, ok := deriveServiceHealth(agentCtx, service, len(intent.TargetServices) == 1)
+		if !ok {
+			continue
+		}
+		if a.memory != nil {
+			previous := a.memory.ServiceHealth[service].Status
+			report.Trends = healthTrendChanges(a.memory.GetServiceHealthHistory(service), previous, report)
+			a.memory.UpdateServiceHealth(service, HealthStatus{
+				Service:     service,
+				Status:      report.Status,
+				LastChecked: now,
+				ErrorCount:  report.Errors,
+				Metrics: map[string]any{
+					healthMetricErrors:    report.Errors,
+					healthMetricThrottles: report.Throttles,
+					healthMetricUnhealthy: report.UnhealthyTargets,
+				},
+				Trends: []HealthTrend{
+					{Timestamp: now, Metric: healthMetricErrors, Value: float64(report.Errors)},
+					{Timestamp: now, Metric: healthMetricThrottles, Value: float64(report.Throttles)},
+					{Timestamp: now, Metric: healthMetricUnhealthy, Value: float64(report.UnhealthyTargets)},
+				},
+			})
+		}
+		reports = append(reports, report)
+	}
+	if len(reports) > 0 {
+		agentCtx.GatheredData[serviceHealthKey] = reports
+	}
+}
+
+// healthServices returns the target services plus any service logs were
+// gathered for, sorted.
+func healthServices(agentCtx *AgentContext, intent QueryIntent) []string {
+	seen := make(map[string]bool)
+	var services []string
+	add := func(service string) {
+		if service != "" && service != "general" && !seen[service] {
+			seen[service] = true
+			services = append(services, service)
+		}
+	}
+	for _, service := range intent.TargetServices {
+		add(service)
+	}
+	for key, data := range agentCtx.GatheredData {
+		if _, ok := data.([]LogData); ok && strings.HasSuffix(key, "_logs") {
+			add(strings.TrimSuffix(key, "_logs"))
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+// deriveServiceHealth counts errors and throttling in the service's logs and
+// unhealthy targets in agent output attributed to it: output whose key names
+// the service, or all of it when the query targets a single service. It
+// reports false when nothing was gathered for the service.
+func deriveServiceHealth(agentCtx *AgentContext, service string, soleTarget bool) (ServiceHealthReport, bool) {
+	report := ServiceHealthReport{Service: service}
+	found := false
+
+	if groups, ok := agentCtx.GatheredData[service+"_logs"].([]LogData); ok {
+		found = true
+		var errorLines []string
+		for _, group := range groups {
+			if n, ok := group["error_count"].(int); ok {
+				report.Errors += n
+			}
+			if lines, ok := group["error_logs"].([]string); ok {
+				errorLines = append(errorLines, lines...)
+			}
+		}
+		for _, category := range classifyErrorLines(errorLines, errorClassifiers()) {
+			if category.Name == "rate_limit" {
+				report.Throttles = category.Count
+			}
+		}
+	}
+
+	for key, data := range agentCtx.GatheredData {
+		text, ok := data.(string)
+		if !ok || skipHealthKey(key) {
+			continue
+		}
+		if !soleTarget && !strings.Contains(strings.ToLower(key), strings.ToLower(service)) {
+			continue
+		}
+		found = true
+		report.UnhealthyTargets += countUnhealthyTargets(text)
+	}
+	if !found {
+		return report, false
+	}
+
+	switch {
+	case report.UnhealthyTargets > 0 || report.Errors >= healthUnhealthyErrorCount:
+		report.Status = "unhealthy"
+	case report.Errors > 0 || report.Throttles > 0:
+		report.Status = "degraded"
+	default:
+		report.Status = "healthy"
+	}
+	return report, true
+}
+
+func skipHealthKey(key string) bool {
+	return key == "semantic_analysis" || key == "_metadata" || key == "error_patterns" || key == serviceHealthKey
+}
+
+// countUnhealthyTargets reads both the analyze_alb_errors summary lines and
+// raw describe-target-health output.
+func countUnhealthyTargets(text string) int {
+	total := 0
+	for _, m := range albTargetsHealthyPattern.FindAllStringSubmatch(text, -1) {
+		healthy, _ := strconv.Atoi(m[1])
+		targets, _ := strconv.Atoi(m[2])
+		if targets > healthy {
+			total += targets - healthy
+		}
+	}
+	if total == 0 {
+		total = len(unhealthyTargetPattern.FindAllStringIndex(text, -1))
+	}
+	return total
+}
+
+// healthTrendChanges describes what got worse since the last recorded check:
+// a metric that doubled or appeared, and a worse status. Improvements are not
+// reported.
+func healthTrendChanges(history []HealthTrend, previousStatus string, report ServiceHealthReport) []string {
+	last := make(map[string]float64)
+	for _, point := range history {
+		last[point.Metric] = point.Value
+	}
+
+	var changes []string
+	for _, metric := range []struct {
+		name  string
+		value int
+	}{
+		{healthMetricErrors, report.Errors},
+		{healthMetricThrottles, report.Throttles},
+		{healthMetricUnhealthy, report.UnhealthyTargets},
+	} {
+		before, ok := last[metric.name]
+		current := float64(metric.value)
+		label := strings.ReplaceAll(metric.name, "_", " ")
+		switch {
+		case !ok || current == 0:
+		case before == 0:
+			changes = append(changes, fmt.Sprintf("%s appeared (0 → %d) since last check", label, metric.value))
+		case current >= 2*before:
+			changes = append(changes, fmt.Sprintf("%s up %sx vs. last check (%.0f → %d)", label, formatRatio(current/before), before, metric.value))
+		}
+	}
+
+	if healthStatusRank(report.Status) > healthStatusRank(previousStatus) && previousStatus != "" {
+		changes = append(changes, fmt.Sprintf("status %s → %s", previousStatus, report.Status))
+	}
+	return changes
+}
+
+func formatRatio(ratio float64) string {
+	if ratio >= 10 || ratio == float64(int(ratio)) {
+		return strconv.Itoa(int(ratio))
+	}
+	return strconv.FormatFloat(ratio, 'f', 1, 64)
+}
+
+func healthStatusRank(status string) int {
+	switch status {
+	case "healthy":
+		return 1
+	case "degraded":
+		return 2
+	case "unhealthy":
+		return 3
+	}
+	return 0
+}
+
+// formatServiceHealth renders the reports for the final context.
+func formatServiceHealth(reports []ServiceHealthReport) string {
+	var b strings.Builder
+	for _, r := range reports {
+		b.WriteString(fmt.Sprintf("Service: %s\nStatus: %s (errors: %d, throttles: %d, unhealthy targets: %d)\n",
+			r.Service, r.Status, r.Errors, r.Throttles, r.UnhealthyTargets))
+		for _, change := range r.Trends {
+			b.WriteString("  ⚠️ " + change + "\n")
+		}
+	}
+	return b.String()
+}
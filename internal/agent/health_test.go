@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bgdnvk/clanker/internal/agent/memory"
+)
+
+func healthTestContext(errorLines []string, albOutput string) *AgentContext {
+	return &AgentContext{
+		OriginalQuery: "why is checkout failing",
+		GatheredData: AWSData{
+			"lambda_logs": []LogData{{
+				"log_group":   "/aws/lambda/checkout",
+				"error_logs":  errorLines,
+				"error_count": len(errorLines),
+			}},
+			"performance_analyze_alb_errors": albOutput,
+		},
+		ServiceData:   make(ServiceData),
+		Metrics:       make(MetricsData),
+		ServiceStatus: make(map[string]string),
+	}
+}
+
+func TestAssessServiceHealthTrends(t *testing.T) {
+	a := &Agent{memory: memory.New(10)}
+	intent := QueryIntent{Primary: "troubleshoot", TargetServices: []string{"lambda"}}
+	first := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	a.assessServiceHealth(healthTestContext([]string{"ERROR boom", "ERROR boom", "ERROR boom", "ERROR boom"}, "  2/2 targets healthy\n"), intent, first)
+	reports := func(ctx *AgentContext) []ServiceHealthReport {
+		r, _ := ctx.GatheredData[serviceHealthKey].([]ServiceHealthReport)
+		return r
+	}
+
+	errs := []string{"ThrottlingException: Rate exceeded"}
+	for i := 0; i < 11; i++ {
+		errs = append(errs, "ERROR boom")
+	}
+	ctx := healthTestContext(errs, "  1/3 targets healthy\n")
+	a.assessServiceHealth(ctx, intent, first.Add(time.Hour))
+
+	got := reports(ctx)
+	if len(got) != 1 {
+		t.Fatalf("expected one report, got %+v", got)
+	}
+	r := got[0]
+	if r.Status != "unhealthy" || r.Errors != 12 || r.Throttles != 1 || r.UnhealthyTargets != 2 {
+		t.Errorf("unexpected report: %+v", r)
+	}
+	trends := strings.Join(r.Trends, "\n")
+	for _, want := range []string{
+		"errors up 3x vs. last check (4 → 12)",
+		"throttles appeared",
+		"unhealthy targets appeared",
+		"status degraded → unhealthy",
+	} {
+		if !strings.Contains(trends, want) {
+			t.Errorf("trends missing %q:\n%s", want, trends)
+		}
+	}
+
+	history := a.memory.GetServiceHealthHistory("lambda")
+	if len(history) != 6 || !history[5].Timestamp.Equal(first.Add(time.Hour)) {
+		t.Errorf("expected two checks of three points each, got %+v", history)
+	}
+	if a.memory.ServiceHealth["lambda"].Status != "unhealthy" {
+		t.Errorf("expected the latest status stored, got %+v", a.memory.ServiceHealth["lambda"])
+	}
+
+	final := a.BuildFinalContext(ctx)
+	if !strings.Contains(final, "=== SERVICE HEALTH ===") || !strings.Contains(final, "errors up 3x") {
+		t.Errorf("expected the health section in the final context:\n%s", final)
+	}
+	if strings.Contains(final, "SERVICE_HEALTH:") {
+		t.Error("service health should not also be rendered as raw gathered data")
+	}
+}
+
+func TestAssessServiceHealthWithoutMemory(t *testing.T) {
+	a := &Agent{}
+	ctx := healthTestContext(nil, "no findings")
+	a.assessServiceHealth(ctx, QueryIntent{TargetServices: []string{"lambda", "rds"}}, time.Now())
+
+	got, _ := ctx.GatheredData[serviceHealthKey].([]ServiceHealthReport)
+	if len(got) != 1 || got[0].Service != "lambda" || got[0].Status != "healthy" || len(got[0].Trends) != 0 {
+		t.Errorf("expected only lambda scored, healthy and without trends, got %+v", got)
+	}
+}
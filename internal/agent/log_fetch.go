@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultMaxLogGroups caps how many log groups one gatherLogs call reads;
+	// override with agent.max_log_groups.
+	defaultMaxLogGroups = 10
+	// defaultLogFetchConcurrency bounds parallel CloudWatch Logs calls;
+	// override with agent.log_fetch_concurrency.
+	defaultLogFetchConcurrency = 4
+	// logActivityProbeMax bounds how many groups are probed for their last
+	// event when ranking; the rest are pre-ranked by stored bytes.
+	logActivityProbeMax = 50
+)
+
+// logsThrottleBackoffs are the waits between retries of a throttled
+// CloudWatch Logs call. The AWS client already retries briefly; these cover
+// the account-wide Logs API rate limits, which take longer to clear.
+var logsThrottleBackoffs = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+
+// logGroupInfo is a log group from describe-log-groups.
+type logGroupInfo struct {
+	Name        string
+	StoredBytes int64
+}
+
+func configuredMaxLogGroups() int {
+	if n := viper.GetInt("agent.max_log_groups"); n > 0 {
+		return n
+	}
+	return defaultMaxLogGroups
+}
+
+func configuredLogFetchConcurrency() int {
+	if n := viper.GetInt("agent.log_fetch_concurrency"); n > 0 {
+		return n
+	}
+	return defaultLogFetchConcurrency
+}
+
+// execLogsCLI runs a CloudWatch Logs CLI command, retrying throttling errors
+// with jittered backoff.
+func (a *Agent) execLogsCLI(ctx context.Context, args []string) (string, error) {
+	for attempt := 0; ; attempt++ {
+		out, err := a.client.ExecCLI(ctx, args)
+		if err == nil || attempt >= len(logsThrottleBackoffs) || !isLogsThrottle(err) {
+			return out, err
+		}
+		delay := logsThrottleBackoffs[attempt]
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isLogsThrottle reports whether err is CloudWatch Logs rate limiting.
+func isLogsThrottle(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "throttlingexception") || strings.Contains(msg, "rate exceeded") ||
+		strings.Contains(msg, "limitexceededexception")
+}
+
+// forEachBounded calls fn for 0..n-1 on at most workers goroutines and
+// waits for all calls to return. Indexes not yet started when ctx is done
+// are skipped.
+func forEachBounded(ctx context.Context, n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// getMostActiveLogGroups returns up to limit groups, most recently written
+// first. Recency comes from the lastEventTimestamp of each group's newest
+// stream, since describe-log-groups does not report it; when there are more
+// than logActivityProbeMax candidates only the largest groups by storedBytes
+// are probed. Groups with no events at all are dropped, and groups whose
+// probe fails rank last.
+func (a *Agent) getMostActiveLogGroups(ctx context.Context, groups []logGroupInfo, limit int) []string {
+	ranked := append([]logGroupInfo(nil), groups...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].StoredBytes > ranked[j].StoredBytes })
+	if len(ranked) <= limit {
+		return logGroupNames(ranked)
+	}
+	if len(ranked) > logActivityProbeMax {
+		ranked = ranked[:logActivityProbeMax]
+	}
+
+	lastEvent := make([]int64, len(ranked))
+	empty := make([]bool, len(ranked))
+	forEachBounded(ctx, len(ranked), configuredLogFetchConcurrency(), func(i int) {
+		ts, ok := a.lastLogEvent(ctx, ranked[i].Name)
+		lastEvent[i] = ts
+		empty[i] = ok && ts == 0
+	})
+	order := make([]int, 0, len(ranked))
+	for i := range ranked {
+		if !empty[i] {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return lastEvent[order[i]] > lastEvent[order[j]] })
+	if len(order) > limit {
+		order = order[:limit]
+	}
+
+	names := make([]string, 0, len(order))
+	for _, i := range order {
+		names = append(names, ranked[i].Name)
+	}
+	return names
+}
+
+// lastLogEvent returns the newest event time (ms) in logGroup, or 0 when the
+// group has no events. ok is false when the lookup itself failed.
+func (a *Agent) lastLogEvent(ctx context.Context, logGroup string) (ts int64, ok bool) {
+	out, err := a.execLogsCLI(ctx, []string{
+		"logs", "describe-log-streams",
+		"--log-group-name", logGroup,
+		"--order-by", "LastEventTime",
+		"--descending",
+		"--max-items", "1",
+		"--output", "json",
+	})
+	if err != nil {
+		return 0, false
+	}
+	var resp struct {
+		LogStreams []struct {
+			LastEventTimestamp int64 `json:"lastEventTimestamp"`
+		} `json:"logStreams"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return 0, false
+	}
+	if len(resp.LogStreams) == 0 {
+		return 0, true
+	}
+	return resp.LogStreams[0].LastEventTimestamp, true
+}
+
+func logGroupNames(groups []logGroupInfo) []string {
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	return names
+}
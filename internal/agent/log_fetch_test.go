@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	awsclient "github.com/bgdnvk/clanker/internal/aws"
+)
+
+func newExecAgent(fn awsclient.ExecFunc) *Agent {
+	c := &awsclient.Client{}
+	c.SetExecFunc(fn)
+	return &Agent{client: c}
+}
+
+func argValue(args []string, flag string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func TestGetMostActiveLogGroupsRanksByLastEvent(t *testing.T) {
+	lastEvent := map[string]int64{
+		"/aws/lambda/idle":   1000,
+		"/aws/lambda/busy":   9000,
+		"/aws/lambda/warm":   5000,
+		"/aws/lambda/broken": 0,
+	}
+	empty := "/aws/lambda/empty"
+	a := newExecAgent(func(_ context.Context, args []string, _ *awsclient.AIProfile) (string, error) {
+		group := argValue(args, "--log-group-name")
+		if group == "/aws/lambda/broken" {
+			return "", errors.New("AccessDeniedException")
+		}
+		if group == empty {
+			return `{"logStreams":[]}`, nil
+		}
+		return fmt.Sprintf(`{"logStreams":[{"lastEventTimestamp":%d}]}`, lastEvent[group]), nil
+	})
+
+	groups := []logGroupInfo{
+		{Name: "/aws/lambda/idle", StoredBytes: 900},
+		{Name: "/aws/lambda/broken", StoredBytes: 800},
+		{Name: "/aws/lambda/warm", StoredBytes: 10},
+		{Name: "/aws/lambda/busy", StoredBytes: 20},
+		{Name: empty},
+	}
+	got := a.getMostActiveLogGroups(context.Background(), groups, 2)
+	want := []string{"/aws/lambda/busy", "/aws/lambda/warm"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("ranked = %v, want %v", got, want)
+	}
+
+	// Empty groups are dropped; failed probes still rank, last.
+	got = a.getMostActiveLogGroups(context.Background(), groups, 4)
+	want = []string{"/aws/lambda/busy", "/aws/lambda/warm", "/aws/lambda/idle", "/aws/lambda/broken"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("ranked = %v, want %v", got, want)
+	}
+}
+
+func TestGetMostActiveLogGroupsSkipsProbeUnderLimit(t *testing.T) {
+	var calls int32
+	a := newExecAgent(func(context.Context, []string, *awsclient.AIProfile) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "", nil
+	})
+	got := a.getMostActiveLogGroups(context.Background(), []logGroupInfo{{Name: "a"}, {Name: "b"}}, 10)
+	if len(got) != 2 || calls != 0 {
+		t.Fatalf("got %v with %d probes, want both groups and no probes", got, calls)
+	}
+}
+
+func TestExecLogsCLIRetriesThrottling(t *testing.T) {
+	saved := logsThrottleBackoffs
+	logsThrottleBackoffs = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { logsThrottleBackoffs = saved }()
+
+	var calls int
+	a := newExecAgent(func(context.Context, []string, *awsclient.AIProfile) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("An error occurred (ThrottlingException): Rate exceeded")
+		}
+		return "ok", nil
+	})
+	out, err := a.execLogsCLI(context.Background(), []string{"logs", "describe-log-groups"})
+	if err != nil || out != "ok" || calls != 3 {
+		t.Fatalf("out=%q err=%v calls=%d, want ok after 3 calls", out, err, calls)
+	}
+
+	calls = 0
+	a = newExecAgent(func(context.Context, []string, *awsclient.AIProfile) (string, error) {
+		calls++
+		return "", errors.New("ResourceNotFoundException")
+	})
+	if _, err := a.execLogsCLI(context.Background(), []string{"logs", "describe-log-groups"}); err == nil || calls != 1 {
+		t.Fatalf("err=%v calls=%d, want a single failed call for non-throttling errors", err, calls)
+	}
+}
+
+func TestForEachBoundedLimitsConcurrency(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		running  int
+		peak     int
+		finished = make([]bool, 20)
+	)
+	forEachBounded(context.Background(), len(finished), 3, func(i int) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(2 * time.Millisecond)
+		mu.Lock()
+		running--
+		finished[i] = true
+		mu.Unlock()
+	})
+	if peak > 3 {
+		t.Fatalf("peak concurrency %d, want at most 3", peak)
+	}
+	for i, done := range finished {
+		if !done {
+			t.Fatalf("index %d was not processed", i)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultLogContextTokens is the total log budget for the final context
+	// when agent.log_context_tokens is unset.
+	defaultLogContextTokens = 4000
+	// minLogSourceTokens keeps each log source readable when many share the budget.
+	minLogSourceTokens = 200
+	logSummaryLineMax  = 300
+)
+
+var (
+	logLineTimestamp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	logLineUUID      = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	logLineHex       = regexp.MustCompile(`\b(0x)?[0-9a-fA-F]{12,}\b`)
+	logLineNumber    = regexp.MustCompile(`\b\d+(\.\d+)?\b`)
+	logLineError     = regexp.MustCompile(`(?i)error|exception|fatal|panic|traceback`)
+)
+
+// logContextTokens returns agent.log_context_tokens, the approximate token
+// budget for all logs in the final context.
+func logContextTokens() int {
+	if tokens := viper.GetInt("agent.log_context_tokens"); tokens > 0 {
+		return tokens
+	}
+	return defaultLogContextTokens
+}
+
+// estimateLogTokens approximates tokens as four characters each.
+func estimateLogTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// logLineTemplate strips timestamps, request IDs and numbers so lines that
+// differ only in those fields collapse together.
+func logLineTemplate(line string) string {
+	t := logLineTimestamp.ReplaceAllString(line, "<ts>")
+	t = logLineUUID.ReplaceAllString(t, "<id>")
+	t = logLineHex.ReplaceAllString(t, "<id>")
+	t = logLineNumber.ReplaceAllString(t, "<n>")
+	return strings.Join(strings.Fields(t), " ")
+}
+
+type logPattern struct {
+	sample  string
+	count   int
+	isError bool
+}
+
+// summarizeLogs collapses near-identical lines into patterns with counts and
+// renders them within roughly maxTokens. Error and exception patterns are
+// always kept; the remaining budget goes to the most frequent other patterns.
+func summarizeLogs(logs []string, maxTokens int) string {
+	if len(logs) == 0 {
+		return ""
+	}
+	if maxTokens <= 0 {
+		maxTokens = defaultLogContextTokens
+	}
+
+	index := make(map[string]int)
+	var patterns []logPattern
+	for _, line := range logs {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key := logLineTemplate(line)
+		if j, ok := index[key]; ok {
+			patterns[j].count++
+			continue
+		}
+		index[key] = len(patterns)
+		patterns = append(patterns, logPattern{sample: line, count: 1, isError: logLineError.MatchString(line)})
+	}
+
+	sort.SliceStable(patterns, func(i, j int) bool {
+		if patterns[i].isError != patterns[j].isError {
+			return patterns[i].isError
+		}
+		return patterns[i].count > patterns[j].count
+	})
+
+	var body strings.Builder
+	used, omitted := 0, 0
+	for _, p := range patterns {
+		line := formatLogPattern(p)
+		cost := estimateLogTokens(line)
+		if !p.isError && used+cost > maxTokens {
+			omitted++
+			continue
+		}
+		body.WriteString(line)
+		used += cost
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%d raw lines collapsed into %d distinct patterns", len(logs), len(patterns)))
+	if omitted > 0 {
+		b.WriteString(fmt.Sprintf("; %d less frequent patterns omitted to fit the budget", omitted))
+	}
+	b.WriteString(":\n")
+	b.WriteString(body.String())
+	return b.String()
+}
+
+func formatLogPattern(p logPattern) string {
+	sample := p.sample
+	if runes := []rune(sample); len(runes) > logSummaryLineMax {
+		sample = string(runes[:logSummaryLineMax]) + "..."
+	}
+	return fmt.Sprintf("[x%d] %s\n", p.count, sample)
+}
+
+// countLogSources returns how many log groups and raw log lists the final
+// context will summarize, so the token budget can be shared between them.
+func countLogSources(data AWSData) int {
+	n := 0
+	for key, value := range data {
+		switch v := value.(type) {
+		case []LogData:
+			if strings.HasSuffix(key, "_logs") {
+				n += len(v)
+			}
+		case []string:
+			if strings.HasSuffix(key, "_all_log_entries") && len(v) > 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// logSourceBudget splits the log budget evenly across sources.
+func logSourceBudget(sources int) int {
+	budget := logContextTokens()
+	if sources > 1 {
+		budget /= sources
+	}
+	if budget < minLogSourceTokens {
+		budget = minLogSourceTokens
+	}
+	return budget
+}
@@ -23,33 +23,34 @@ func (a *Agent) discoverLogGroups(ctx context.Context, serviceName, originalQuer
 		fmt.Printf("🔍 Discovered %d total log groups\n", len(allLogGroups))
 	}
 
-	var relevantGroups []string
+	var matched []logGroupInfo
 	queryLower := strings.ToLower(originalQuery)
 	serviceLower := strings.ToLower(serviceName)
 	queryKeywords := a.extractKeywordsFromQuery(queryLower)
 
 	for _, logGroup := range allLogGroups {
-		logGroupLower := strings.ToLower(logGroup)
+		logGroupLower := strings.ToLower(logGroup.Name)
 
 		if serviceLower != "" && serviceLower != "general" && strings.Contains(logGroupLower, serviceLower) {
-			relevantGroups = append(relevantGroups, logGroup)
+			matched = append(matched, logGroup)
 			continue
 		}
 
 		for _, keyword := range queryKeywords {
 			if strings.Contains(logGroupLower, keyword) {
-				relevantGroups = append(relevantGroups, logGroup)
+				matched = append(matched, logGroup)
 				break
 			}
 		}
 	}
 
-	if len(relevantGroups) == 0 {
+	if len(matched) == 0 {
 		if verbose {
 			fmt.Printf("🔍 No specific matches found, using most active log groups\n")
 		}
-		relevantGroups = a.getMostActiveLogGroups(allLogGroups, 10)
+		matched = allLogGroups
 	}
+	relevantGroups := a.getMostActiveLogGroups(ctx, matched, configuredMaxLogGroups())
 
 	if verbose {
 		fmt.Printf("📋 Selected %d relevant log groups: %v\n", len(relevantGroups), relevantGroups)
@@ -59,13 +60,13 @@ func (a *Agent) discoverLogGroups(ctx context.Context, serviceName, originalQuer
 }
 
 // getAllLogGroups gets all CloudWatch log groups via CLI
-func (a *Agent) getAllLogGroups(ctx context.Context) ([]string, error) {
+func (a *Agent) getAllLogGroups(ctx context.Context) ([]logGroupInfo, error) {
 	args := []string{
 		"logs", "describe-log-groups",
 		"--output", "json",
 	}
 
-	output, err := a.client.ExecCLI(ctx, args)
+	output, err := a.execLogsCLI(ctx, args)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +74,7 @@ func (a *Agent) getAllLogGroups(ctx context.Context) ([]string, error) {
 	var logData struct {
 		LogGroups []struct {
 			LogGroupName string `json:"logGroupName"`
+			StoredBytes  int64  `json:"storedBytes"`
 		} `json:"logGroups"`
 	}
 
@@ -80,9 +82,9 @@ func (a *Agent) getAllLogGroups(ctx context.Context) ([]string, error) {
 		return nil, err
 	}
 
-	logGroups := make([]string, 0, len(logData.LogGroups))
+	logGroups := make([]logGroupInfo, 0, len(logData.LogGroups))
 	for _, group := range logData.LogGroups {
-		logGroups = append(logGroups, group.LogGroupName)
+		logGroups = append(logGroups, logGroupInfo{Name: group.LogGroupName, StoredBytes: group.StoredBytes})
 	}
 
 	return logGroups, nil
@@ -101,53 +103,134 @@ func (a *Agent) extractKeywordsFromQuery(query string) []string {
 	return keywords
 }
 
-// getMostActiveLogGroups returns a limited slice of log groups as a fallback
-func (a *Agent) getMostActiveLogGroups(allGroups []string, limit int) []string {
-	if len(allGroups) <= limit {
-		return allGroups
-	}
-	return allGroups[:limit]
+const (
+	// recentLogsWindow and recentLogsLimit bound the recent logs gathered per
+	// log group; the investigation's time range replaces the window when set.
+	recentLogsWindow = time.Hour
+	recentLogsLimit  = 100
+
+	// tailInitialWindow is the first slice tailLogs reads back from now; each
+	// following slice grows by tailWindowGrowth, and a slice holding more events
+	// than needed is halved down to tailMinWindow.
+	tailInitialWindow = time.Minute
+	tailMinWindow     = time.Second
+	tailWindowGrowth  = 4
+	tailMaxRequests   = 30
+)
+
+// logEvent is a single CloudWatch log event with its timestamp.
+type logEvent struct {
+	Timestamp time.Time
+	Message   string
 }
 
-// getRecentLogsFromGroup fetches recent log messages for a specific log group
-func (a *Agent) getRecentLogsFromGroup(ctx context.Context, logGroup string) ([]string, error) {
-	endTime := time.Now()
-	startTime := endTime.Add(-1 * time.Hour)
+func (e logEvent) String() string {
+	return e.Timestamp.UTC().Format(time.RFC3339Nano) + " " + strings.TrimRight(e.Message, "\n")
+}
 
-	args := []string{
-		"logs", "filter-log-events",
-		"--log-group-name", logGroup,
-		"--start-time", fmt.Sprintf("%d", startTime.Unix()*1000),
-		"--end-time", fmt.Sprintf("%d", endTime.Unix()*1000),
-		"--limit", "100",
-		"--output", "json",
-	}
+// logEventFetcher returns up to max events in [start, end] oldest first and
+// whether the window held more events than were returned.
+type logEventFetcher func(ctx context.Context, start, end time.Time, max int) ([]logEvent, bool, error)
 
-	output, err := a.client.ExecCLI(ctx, args)
-	if err != nil {
-		return nil, err
+// tailLogs returns the most recent events (at most limit, oldest first) from
+// the since before end of logGroup by reading backwards from end.
+func (a *Agent) tailLogs(ctx context.Context, logGroup string, end time.Time, since time.Duration, limit int) ([]logEvent, error) {
+	fetch := func(ctx context.Context, start, end time.Time, max int) ([]logEvent, bool, error) {
+		args := []string{
+			"logs", "filter-log-events",
+			"--log-group-name", logGroup,
+			"--start-time", fmt.Sprintf("%d", start.UnixMilli()),
+			"--end-time", fmt.Sprintf("%d", end.UnixMilli()),
+			"--max-items", fmt.Sprintf("%d", max),
+			"--output", "json",
+		}
+
+		output, err := a.execLogsCLI(ctx, args)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var logData struct {
+			Events []struct {
+				Message   string `json:"message"`
+				Timestamp int64  `json:"timestamp"`
+			} `json:"events"`
+			NextToken string `json:"NextToken"`
+		}
+		if err := json.Unmarshal([]byte(output), &logData); err != nil {
+			return nil, false, err
+		}
+
+		events := make([]logEvent, 0, len(logData.Events))
+		for _, event := range logData.Events {
+			events = append(events, logEvent{Timestamp: time.UnixMilli(event.Timestamp), Message: event.Message})
+		}
+		return events, logData.NextToken != "", nil
 	}
+	return tailLogEvents(ctx, fetch, end, since, limit)
+}
 
-	var logData struct {
-		Events []struct {
-			Message   string `json:"message"`
-			Timestamp int64  `json:"timestamp"`
-		} `json:"events"`
+// tailLogEvents walks windows backwards from now so a chatty log group yields
+// its newest events instead of the oldest events of a fixed window.
+func tailLogEvents(ctx context.Context, fetch logEventFetcher, now time.Time, since time.Duration, limit int) ([]logEvent, error) {
+	if limit <= 0 {
+		return nil, nil
 	}
+	oldest := now.Add(-since)
+	end := now
+	window := tailInitialWindow
+	var chunks [][]logEvent // newest window first, each oldest first
+	collected := 0
 
-	if err := json.Unmarshal([]byte(output), &logData); err != nil {
-		return nil, err
+	for i := 0; i < tailMaxRequests && !end.Before(oldest) && collected < limit; i++ {
+		start := end.Add(-window)
+		if start.Before(oldest) {
+			start = oldest
+		}
+		events, truncated, err := fetch(ctx, start, end, limit-collected)
+		if err != nil {
+			if collected > 0 {
+				break
+			}
+			return nil, err
+		}
+		if truncated && window > tailMinWindow {
+			// More events than we need: narrow the window so we keep the newest.
+			window /= 2
+			if window < tailMinWindow {
+				window = tailMinWindow
+			}
+			continue
+		}
+		if len(events) > 0 {
+			chunks = append(chunks, events)
+			collected += len(events)
+		}
+		end = start.Add(-time.Millisecond)
+		window *= tailWindowGrowth
 	}
 
-	logs := make([]string, 0, len(logData.Events))
-	for _, event := range logData.Events {
-		logs = append(logs, event.Message)
+	result := make([]logEvent, 0, collected)
+	for i := len(chunks) - 1; i >= 0; i-- {
+		result = append(result, chunks[i]...)
+	}
+	if len(result) > limit {
+		result = result[len(result)-limit:]
 	}
+	return result, nil
+}
 
-	return logs, nil
+// formatLogEvents renders events as "timestamp message" lines.
+func formatLogEvents(events []logEvent) []string {
+	logs := make([]string, 0, len(events))
+	for _, event := range events {
+		logs = append(logs, event.String())
+	}
+	return logs
 }
 
-// findErrorPatterns counts common error keywords within aggregated logs
+// findErrorPatterns counts common error keywords within aggregated logs and
+// classifies the lines into failure categories (see errorClassifiers).
 func (a *Agent) findErrorPatterns(allLogs []string) ErrorPatterns {
 	patterns := make(ErrorPatterns)
 	errorCount := 0
@@ -171,6 +254,7 @@ func (a *Agent) findErrorPatterns(allLogs []string) ErrorPatterns {
 	patterns["timeout_errors"] = timeoutCount
 	patterns["connection_errors"] = connectionCount
 	patterns["total_logs_analyzed"] = len(allLogs)
+	patterns["categories"] = classifyErrorLines(allLogs, errorClassifiers())
 
 	return patterns
 }
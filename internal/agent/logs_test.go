@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// fakeLogGroup serves one event per second over the last hour.
+func fakeLogGroup(now time.Time) logEventFetcher {
+	var all []logEvent
+	for ts := now.Add(-time.Hour); !ts.After(now); ts = ts.Add(time.Second) {
+		all = append(all, logEvent{Timestamp: ts, Message: ts.Format(time.RFC3339)})
+	}
+	return func(ctx context.Context, start, end time.Time, max int) ([]logEvent, bool, error) {
+		var events []logEvent
+		for _, e := range all {
+			if e.Timestamp.Before(start) || e.Timestamp.After(end) {
+				continue
+			}
+			if len(events) == max {
+				return events, true, nil
+			}
+			events = append(events, e)
+		}
+		return events, false, nil
+	}
+}
+
+func TestTailLogEventsReturnsNewest(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	events, err := tailLogEvents(context.Background(), fakeLogGroup(now), now, time.Hour, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 10 {
+		t.Fatalf("got %d events, want 10", len(events))
+	}
+	if !events[len(events)-1].Timestamp.Equal(now) {
+		t.Fatalf("last event = %s, want the newest event %s", events[len(events)-1].Timestamp, now)
+	}
+	for i := 1; i < len(events); i++ {
+		if !events[i].Timestamp.After(events[i-1].Timestamp) {
+			t.Fatalf("events not in ascending order at %d: %v", i, events)
+		}
+	}
+}
+
+func TestTailLogEventsSparseGroup(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-50 * time.Minute)
+	fetch := func(ctx context.Context, start, end time.Time, max int) ([]logEvent, bool, error) {
+		if !old.Before(start) && !old.After(end) {
+			return []logEvent{{Timestamp: old, Message: "only event"}}, false, nil
+		}
+		return nil, false, nil
+	}
+	events, err := tailLogEvents(context.Background(), fetch, now, time.Hour, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Message != "only event" {
+		t.Fatalf("expected the single older event, got %v", events)
+	}
+	if got := formatLogEvents(events)[0]; got != old.Format(time.RFC3339Nano)+" only event" {
+		t.Fatalf("formatted event = %q", got)
+	}
+}
+
+func TestFindErrorPatternsClassifiesLines(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("agent.error_patterns", map[string][]string{
+		"disk_full":  {`(?i)no space left on device`},
+		"permission": {`(?i)kms key is disabled`},
+	})
+
+	a := &Agent{}
+	patterns := a.findErrorPatterns([]string{
+		"REPORT RequestId: 1 Error: Runtime exited with error: signal: killed (out of memory)",
+		"ERROR AccessDeniedException: User is not authorized to perform: dynamodb:PutItem",
+		"ERROR ThrottlingException: Rate exceeded",
+		"ERROR Task timed out after 30.00 seconds",
+		"ERROR Task timed out after 30.01 seconds",
+		"ERROR getaddrinfo ENOTFOUND db.internal",
+		"TypeError: Cannot read properties of undefined (reading 'id')",
+		"Error: environment variable DATABASE_URL is not set",
+		"write /tmp/cache: no space left on device",
+		"ERROR KMS key is disabled",
+		"INFO request completed",
+	})
+
+	categories, ok := patterns["categories"].([]ErrorCategory)
+	if !ok {
+		t.Fatalf("categories missing from %v", patterns)
+	}
+	counts := make(map[string]int)
+	for _, c := range categories {
+		counts[c.Name] = c.Count
+		if c.Sample == "" {
+			t.Errorf("category %s has no sample line", c.Name)
+		}
+	}
+	want := map[string]int{
+		"oom":                1,
+		"permission":         2,
+		"rate_limit":         1,
+		"dependency_timeout": 2,
+		"network":            1,
+		"stacktrace":         1,
+		"config_missing":     1,
+		"disk_full":          1,
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("%s count = %d, want %d (categories %+v)", name, counts[name], n, categories)
+		}
+	}
+	if categories[0].Count < categories[len(categories)-1].Count {
+		t.Errorf("categories not ordered by count: %+v", categories)
+	}
+	if got := formatErrorPatterns(patterns); !strings.Contains(got, "Top failure categories:") || !strings.Contains(got, "- dependency_timeout x2: ERROR Task timed out after 30.00 seconds") {
+		t.Errorf("unexpected formatted patterns:\n%s", got)
+	}
+}
+
+func TestSummarizeLogsCollapsesAndKeepsErrors(t *testing.T) {
+	var logs []string
+	for i := 0; i < 50; i++ {
+		logs = append(logs, fmt.Sprintf("2024-05-01T12:00:%02dZ START RequestId: 8f1c2a4e-1b2c-4d5e-9f00-%012d Version: $LATEST", i, i))
+	}
+	for i := 0; i < 200; i++ {
+		logs = append(logs, fmt.Sprintf("2024-05-01T12:01:%02dZ health check %d ok %s", i%60, i, strings.Repeat("x", 40)))
+	}
+	logs = append(logs, "2024-05-01T12:02:00Z ERROR Exception in handler: connection refused")
+
+	out := summarizeLogs(logs, 10000)
+	if !strings.HasPrefix(out, "251 raw lines collapsed into 3 distinct patterns:") {
+		t.Errorf("unexpected header:\n%s", out)
+	}
+	if !strings.Contains(out, "[x200] ") || !strings.Contains(out, "[x50] ") {
+		t.Errorf("expected counts for repeated patterns:\n%s", out)
+	}
+
+	tight := summarizeLogs(logs, 10)
+	if !strings.Contains(tight, "[x1] 2024-05-01T12:02:00Z ERROR Exception in handler") {
+		t.Errorf("error line must survive a tight budget:\n%s", tight)
+	}
+	if !strings.Contains(tight, "patterns omitted to fit the budget") {
+		t.Errorf("expected omitted note under a tight budget:\n%s", tight)
+	}
+}
+
+func TestWriteLogGroupDataKeepsErrorLogs(t *testing.T) {
+	var errorLogs []string
+	for i := 0; i < 20; i++ {
+		errorLogs = append(errorLogs, fmt.Sprintf("FATAL worker %d lost its lease", i))
+	}
+	errorLogs = append(errorLogs, "GET /checkout status=503")
+
+	var b strings.Builder
+	writeLogGroupData(&b, LogData{"log_group": "/ecs/api", "error_logs": errorLogs}, 10)
+	out := b.String()
+	for _, line := range errorLogs {
+		if !strings.Contains(out, "ERROR: "+line+"\n") {
+			t.Errorf("expected error entry %q kept verbatim in:\n%s", line, out)
+		}
+	}
+}
+
+func TestLogSourceBudget(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("agent.log_context_tokens", 1000)
+	if got := logSourceBudget(4); got != 250 {
+		t.Errorf("logSourceBudget(4) = %d, want 250", got)
+	}
+	if got := logSourceBudget(50); got != minLogSourceTokens {
+		t.Errorf("logSourceBudget(50) = %d, want %d", got, minLogSourceTokens)
+	}
+}
@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownExcerptLines caps the log lines shown per log group in a Markdown
+// report; the full data stays available through --output json.
+const markdownExcerptLines = 20
+
+// BuildMarkdownReport renders the investigation as Markdown for pull
+// requests and incident docs: a summary, a table of the services
+// investigated, the error-pattern breakdown, and log excerpts and raw
+// findings in collapsible sections. Unlike BuildFinalContext it is meant for
+// people, not the LLM, so it has no prompt markers or internal keys.
+func (a *Agent) BuildMarkdownReport(agentCtx *AgentContext) string {
+	result := a.BuildStructuredResult(agentCtx)
+	if result == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Investigation: %s\n\n", markdownInline(result.Query)))
+
+	b.WriteString("## Summary\n\n")
+	totalErrors := 0
+	for _, svc := range result.Services {
+		totalErrors += svc.ErrorCount
+	}
+	b.WriteString(fmt.Sprintf("- **Services investigated:** %d\n", len(result.Services)))
+	b.WriteString(fmt.Sprintf("- **Errors found in logs:** %d\n", totalErrors))
+	if intent := result.SemanticAnalysis; intent != nil {
+		if intent.Primary != "" {
+			b.WriteString(fmt.Sprintf("- **Intent:** %s", intent.Primary))
+			if intent.Urgency != "" {
+				b.WriteString(fmt.Sprintf(" (urgency: %s)", intent.Urgency))
+			}
+			b.WriteString("\n")
+		}
+		if len(intent.TargetServices) > 0 {
+			b.WriteString(fmt.Sprintf("- **Target services:** %s\n", strings.Join(intent.TargetServices, ", ")))
+		}
+	}
+	if !agentCtx.TimeRange.IsZero() {
+		b.WriteString(fmt.Sprintf("- **Window:** %s\n", agentCtx.TimeRange.String()))
+	}
+	b.WriteString(fmt.Sprintf("- **Steps:** %d\n", result.Steps))
+	if !result.CompletedAt.IsZero() {
+		b.WriteString(fmt.Sprintf("- **Completed:** %s\n", result.CompletedAt.UTC().Format("2006-01-02 15:04 MST")))
+	}
+	b.WriteString("\n")
+
+	if len(result.Failures) > 0 {
+		b.WriteString("> [!WARNING]\n")
+		b.WriteString("> Some agents could not gather their data; treat these areas as unchecked, not healthy.\n")
+		for _, f := range result.Failures {
+			line := fmt.Sprintf("> - **%s**: %s", f.Agent, f.Error)
+			if len(f.FailedOperations) > 0 {
+				line += fmt.Sprintf(" (failed: %s)", strings.Join(f.FailedOperations, ", "))
+			}
+			if len(f.SkippedOperations) > 0 {
+				line += fmt.Sprintf(" (not run: %s)", strings.Join(f.SkippedOperations, ", "))
+			}
+			b.WriteString(markdownInline(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Services) > 0 {
+		b.WriteString("## Services\n\n")
+		b.WriteString("| Service | Log groups | Log entries | Errors |\n")
+		b.WriteString("|---|---:|---:|---:|\n")
+		for _, svc := range result.Services {
+			b.WriteString(fmt.Sprintf("| %s | %d | %d | %d |\n", markdownCell(svc.Service), len(svc.LogGroups), svc.TotalLogs, svc.ErrorCount))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.ServiceHealth) > 0 {
+		b.WriteString("## Service health\n\n")
+		b.WriteString("| Service | Status | Errors | Throttles | Unhealthy targets | Since last check |\n")
+		b.WriteString("|---|---|---:|---:|---:|---|\n")
+		for _, h := range result.ServiceHealth {
+			b.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %d | %s |\n", markdownCell(h.Service), h.Status, h.Errors, h.Throttles, h.UnhealthyTargets, markdownCell(strings.Join(h.Trends, "; "))))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.ErrorPatterns) > 0 {
+		b.WriteString("## Error patterns\n\n")
+		analyzed, _ := result.ErrorPatterns["total_logs_analyzed"].(int)
+		errorCount, _ := result.ErrorPatterns["total_errors"].(int)
+		timeouts, _ := result.ErrorPatterns["timeout_errors"].(int)
+		connections, _ := result.ErrorPatterns["connection_errors"].(int)
+		b.WriteString(fmt.Sprintf("Analyzed %d log lines: %d mention errors, %d timeouts, %d connection failures.\n\n", analyzed, errorCount, timeouts, connections))
+		if categories, _ := result.ErrorPatterns["categories"].([]ErrorCategory); len(categories) > 0 {
+			b.WriteString("| Category | Count | Sample |\n")
+			b.WriteString("|---|---:|---|\n")
+			for _, category := range categories {
+				b.WriteString(fmt.Sprintf("| %s | %d | `%s` |\n", markdownCell(category.Name), category.Count, markdownCell(strings.ReplaceAll(category.Sample, "`", "'"))))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	var excerpts strings.Builder
+	for _, svc := range result.Services {
+		for _, group := range svc.LogGroups {
+			if len(group.RecentErrors) == 0 {
+				continue
+			}
+			lines := group.RecentErrors
+			if len(lines) > markdownExcerptLines {
+				lines = lines[:markdownExcerptLines]
+			}
+			summary := fmt.Sprintf("%s: %s (%d errors)", svc.Service, group.LogGroup, group.ErrorCount)
+			writeMarkdownDetails(&excerpts, summary, strings.Join(lines, "\n"))
+		}
+	}
+	if excerpts.Len() > 0 {
+		b.WriteString("## Log excerpts\n\n")
+		b.WriteString(excerpts.String())
+	}
+
+	if len(result.Findings) > 0 {
+		b.WriteString("## Findings\n\n")
+		for _, finding := range result.Findings {
+			summary := finding.Key
+			if finding.Source != "agent" {
+				summary = finding.Source + ": " + finding.Key
+			}
+			writeMarkdownDetails(&b, summary, finding.Data)
+		}
+	}
+
+	report := strings.TrimRight(b.String(), "\n") + "\n"
+	if a.redactor != nil {
+		report = markdownPlaceholders(a.redactor.redactSensitive(report))
+	}
+	return report
+}
+
+// writeMarkdownDetails writes body as a fenced block inside a collapsible
+// <details> section.
+func writeMarkdownDetails(b *strings.Builder, summary, body string) {
+	fence := "```"
+	for strings.Contains(body, fence) {
+		fence += "`"
+	}
+	b.WriteString(fmt.Sprintf("<details>\n<summary>%s</summary>\n\n", markdownHTMLEscape(summary)))
+	b.WriteString(fence + "text\n")
+	b.WriteString(strings.TrimRight(body, "\n") + "\n")
+	b.WriteString(fence + "\n\n</details>\n\n")
+}
+
+// markdownInline collapses a value onto one line.
+func markdownInline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// markdownCell makes s safe inside a table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(markdownInline(s), "|", `\|`)
+}
+
+func markdownHTMLEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(markdownInline(s))
+}
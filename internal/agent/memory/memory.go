@@ -2,20 +2,30 @@
 package memory
 
 import (
+	"encoding/json"
+	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"sort"
 	"time"
 
 	"github.com/bgdnvk/clanker/internal/agent/model"
 )
 
+// DefaultMaxQueries is the rolling window size used when none is configured.
+const DefaultMaxQueries = 50
+
+// MaxHealthTrends bounds the trend points kept per service.
+const MaxHealthTrends = 300
+
 type AgentMemory struct {
-	PreviousQueries []model.QueryContext
-	ServiceHealth   map[string]model.HealthStatus
-	UserPreferences map[string]any
-	LearnedPatterns []model.Pattern
-	LastUpdated     time.Time
-	MaxQueries      int
+	PreviousQueries []model.QueryContext          `json:"previous_queries"`
+	ServiceHealth   map[string]model.HealthStatus `json:"service_health"`
+	UserPreferences map[string]any                `json:"user_preferences"`
+	LearnedPatterns []model.Pattern               `json:"learned_patterns"`
+	LastUpdated     time.Time                     `json:"last_updated"`
+	MaxQueries      int                           `json:"max_queries"`
 }
 
 func New(maxQueries int) *AgentMemory {
@@ -29,19 +39,105 @@ func New(maxQueries int) *AgentMemory {
 	}
 }
 
+// Load reads agent memory from a JSON file. A missing or corrupt file yields an
+// empty memory so callers can always proceed; the MaxQueries window is
+// re-applied after loading.
+func Load(path string) *AgentMemory {
+	am := New(DefaultMaxQueries)
+	if path == "" {
+		return am
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return am
+	}
+	var loaded AgentMemory
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return am
+	}
+
+	if loaded.PreviousQueries != nil {
+		am.PreviousQueries = loaded.PreviousQueries
+	}
+	if loaded.ServiceHealth != nil {
+		am.ServiceHealth = loaded.ServiceHealth
+	}
+	if loaded.UserPreferences != nil {
+		am.UserPreferences = loaded.UserPreferences
+	}
+	if loaded.LearnedPatterns != nil {
+		am.LearnedPatterns = loaded.LearnedPatterns
+	}
+	if !loaded.LastUpdated.IsZero() {
+		am.LastUpdated = loaded.LastUpdated
+	}
+	if loaded.MaxQueries > 0 {
+		am.MaxQueries = loaded.MaxQueries
+	}
+	am.trim()
+	return am
+}
+
+// Save writes the memory to path as JSON, creating the parent directory.
+func (am *AgentMemory) Save(path string) error {
+	if path == "" {
+		return fmt.Errorf("memory path is empty")
+	}
+	am.trim()
+	data, err := json.MarshalIndent(am, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal agent memory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create memory directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write agent memory: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write agent memory: %w", err)
+	}
+	return nil
+}
+
+// trim enforces the rolling MaxQueries window, keeping the newest entries.
+func (am *AgentMemory) trim() {
+	if am.MaxQueries <= 0 {
+		am.MaxQueries = DefaultMaxQueries
+	}
+	if extra := len(am.PreviousQueries) - am.MaxQueries; extra > 0 {
+		am.PreviousQueries = append([]model.QueryContext(nil), am.PreviousQueries[extra:]...)
+	}
+}
+
 func (am *AgentMemory) AddQueryContext(ctx model.QueryContext) {
 	am.PreviousQueries = append(am.PreviousQueries, ctx)
-	if len(am.PreviousQueries) > am.MaxQueries {
-		am.PreviousQueries = am.PreviousQueries[1:]
-	}
+	am.trim()
 	am.LastUpdated = time.Now()
 }
 
+// UpdateServiceHealth replaces the service's current health. status.Trends
+// holds the points from this check; they are appended to the service's
+// history, which keeps the newest MaxHealthTrends points.
 func (am *AgentMemory) UpdateServiceHealth(service string, status model.HealthStatus) {
+	trends := append(append([]model.HealthTrend(nil), am.ServiceHealth[service].Trends...), status.Trends...)
+	if extra := len(trends) - MaxHealthTrends; extra > 0 {
+		trends = trends[extra:]
+	}
+	status.Trends = trends
 	am.ServiceHealth[service] = status
 	am.LastUpdated = time.Now()
 }
 
+// GetServiceHealthHistory returns a copy of the service's trend points,
+// oldest first.
+func (am *AgentMemory) GetServiceHealthHistory(service string) []model.HealthTrend {
+	return append([]model.HealthTrend(nil), am.ServiceHealth[service].Trends...)
+}
+
 func (am *AgentMemory) GetSimilarQueries(intent model.QueryIntent, limit int) []model.QueryContext {
 	var similar []model.QueryContext
 	for _, prev := range am.PreviousQueries {
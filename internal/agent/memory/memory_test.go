@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bgdnvk/clanker/internal/agent/model"
+)
+
+func TestLoad_MissingAndCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+
+	am := Load(filepath.Join(dir, "missing.json"))
+	if len(am.PreviousQueries) != 0 || am.MaxQueries != DefaultMaxQueries {
+		t.Fatalf("expected empty memory for missing file, got %+v", am)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	am = Load(corrupt)
+	if len(am.PreviousQueries) != 0 || am.ServiceHealth == nil {
+		t.Fatalf("expected empty memory for corrupt file, got %+v", am)
+	}
+}
+
+func TestSaveAndLoad_EnforcesMaxQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "agent_memory.json")
+
+	am := New(2)
+	for _, q := range []string{"first", "second", "third"} {
+		am.AddQueryContext(model.QueryContext{Query: q})
+	}
+	am.LearnPattern("troubleshoot:lambda", "lambda errors", []string{"logs"})
+	if err := am.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := Load(path)
+	if loaded.MaxQueries != 2 {
+		t.Errorf("MaxQueries = %d, want 2", loaded.MaxQueries)
+	}
+	if len(loaded.PreviousQueries) != 2 || loaded.PreviousQueries[0].Query != "second" {
+		t.Errorf("unexpected queries after load: %+v", loaded.PreviousQueries)
+	}
+	if len(loaded.LearnedPatterns) != 1 {
+		t.Errorf("expected 1 learned pattern, got %d", len(loaded.LearnedPatterns))
+	}
+
+	// A hand-edited file that exceeds its own window is trimmed on load.
+	raw := `{"previous_queries":[{"query":"a"},{"query":"b"},{"query":"c"}],"max_queries":1}`
+	if err := os.WriteFile(path, []byte(raw), 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded = Load(path)
+	if len(loaded.PreviousQueries) != 1 || loaded.PreviousQueries[0].Query != "c" {
+		t.Errorf("expected only the newest query after trim, got %+v", loaded.PreviousQueries)
+	}
+}
+
+func TestUpdateServiceHealth_AccumulatesTrends(t *testing.T) {
+	am := New(10)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < MaxHealthTrends+5; i++ {
+		am.UpdateServiceHealth("lambda", model.HealthStatus{
+			Service: "lambda",
+			Status:  "degraded",
+			Trends:  []model.HealthTrend{{Timestamp: start.Add(time.Duration(i) * time.Hour), Metric: "errors", Value: float64(i)}},
+		})
+	}
+
+	history := am.GetServiceHealthHistory("lambda")
+	if len(history) != MaxHealthTrends || history[0].Value != 5 || history[len(history)-1].Value != MaxHealthTrends+4 {
+		t.Fatalf("expected the newest %d points, got %d starting at %v", MaxHealthTrends, len(history), history[0].Value)
+	}
+	history[0].Value = -1
+	if am.GetServiceHealthHistory("lambda")[0].Value == -1 {
+		t.Error("GetServiceHealthHistory should return a copy")
+	}
+	if am.GetServiceHealthHistory("rds") != nil {
+		t.Error("expected no history for an unseen service")
+	}
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"sync"
+	"time"
+)
+
+// AgentEventType identifies what happened during an investigation.
+type AgentEventType string
+
+const (
+	EventAgentStarted          AgentEventType = "agent_started"
+	EventAgentCompleted        AgentEventType = "agent_completed"
+	EventAgentFailed           AgentEventType = "agent_failed"
+	EventThought               AgentEventType = "thought"
+	EventInvestigationComplete AgentEventType = "investigation_complete"
+)
+
+// AgentEvent is a structured progress update emitted while an investigation
+// runs, so callers can render live progress.
+type AgentEvent struct {
+	Type      AgentEventType `json:"type"`
+	AgentID   string         `json:"agent_id,omitempty"`
+	AgentType string         `json:"agent_type,omitempty"`
+	Action    string         `json:"action,omitempty"`
+	Message   string         `json:"message"`
+	Error     string         `json:"error,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// EventSink delivers AgentEvents to a consumer channel without ever blocking
+// the sender. Events are dropped when the channel is full, and emitting after
+// Close is a no-op, so late agents cannot panic on a closed channel.
+// A nil *EventSink is valid and discards everything.
+type EventSink struct {
+	mu      sync.Mutex
+	ch      chan<- AgentEvent
+	closed  bool
+	dropped int
+}
+
+// NewEventSink wraps ch. It returns nil when ch is nil.
+func NewEventSink(ch chan<- AgentEvent) *EventSink {
+	if ch == nil {
+		return nil
+	}
+	return &EventSink{ch: ch}
+}
+
+// Emit sends event if the consumer has room, stamping it when unset.
+func (s *EventSink) Emit(event AgentEvent) {
+	if s == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- event:
+	default:
+		s.dropped++
+	}
+}
+
+// Close closes the consumer channel. It is safe to call more than once.
+func (s *EventSink) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+}
+
+// Dropped reports how many events were discarded because the consumer was slow.
+func (s *EventSink) Dropped() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Emit forwards event to the context's progress sink, if any.
+func (c *AgentContext) Emit(event AgentEvent) {
+	if c == nil {
+		return
+	}
+	c.Progress.Emit(event)
+}
@@ -0,0 +1,44 @@
+package model
+
+import "testing"
+
+func TestEventSinkNeverBlocks(t *testing.T) {
+	ch := make(chan AgentEvent, 1)
+	sink := NewEventSink(ch)
+
+	sink.Emit(AgentEvent{Type: EventThought, Message: "first"})
+	sink.Emit(AgentEvent{Type: EventThought, Message: "second"})
+
+	if got := sink.Dropped(); got != 1 {
+		t.Fatalf("expected 1 dropped event, got %d", got)
+	}
+	event := <-ch
+	if event.Message != "first" || event.Timestamp.IsZero() {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+}
+
+func TestEventSinkCloseIsIdempotent(t *testing.T) {
+	ch := make(chan AgentEvent, 4)
+	ctx := &AgentContext{Progress: NewEventSink(ch)}
+
+	ctx.Emit(AgentEvent{Type: EventAgentStarted, Message: "started"})
+	ctx.Progress.Close()
+	ctx.Progress.Close()
+	ctx.Emit(AgentEvent{Type: EventAgentCompleted, Message: "late"})
+
+	var got []AgentEvent
+	for event := range ch {
+		got = append(got, event)
+	}
+	if len(got) != 1 || got[0].Type != EventAgentStarted {
+		t.Fatalf("expected only the event sent before close, got %+v", got)
+	}
+}
+
+func TestNilSinkDiscards(t *testing.T) {
+	var ctx *AgentContext
+	ctx.Emit(AgentEvent{Message: "ignored"})
+	(&AgentContext{}).Emit(AgentEvent{Message: "ignored"})
+	NewEventSink(nil).Close()
+}
@@ -16,12 +16,13 @@ type (
 )
 
 type QueryIntent struct {
-	Primary        string   `json:"primary"`
-	Confidence     float64  `json:"confidence"`
-	TargetServices []string `json:"target_services"`
-	Urgency        string   `json:"urgency"`
-	TimeFrame      string   `json:"time_frame"`
-	DataTypes      []string `json:"data_types"`
+	Primary           string             `json:"primary"`
+	Confidence        float64            `json:"confidence"`
+	TargetServices    []string           `json:"target_services"`
+	ServiceConfidence map[string]float64 `json:"service_confidence,omitempty"`
+	Urgency           string             `json:"urgency"`
+	TimeFrame         string             `json:"time_frame"`
+	DataTypes         []string           `json:"data_types"`
 }
 
 type QueryContext struct {
@@ -76,6 +77,24 @@ type AgentDecision struct {
 	Parameters   AWSData             `json:"parameters"`
 }
 
+// AgentFailure records a parallel agent that failed outright or could not
+// run some of its operations, so the answer can say which data is missing.
+type AgentFailure struct {
+	Agent             string   `json:"agent"`
+	Error             string   `json:"error,omitempty"`
+	FailedOperations  []string `json:"failed_operations,omitempty"`
+	SkippedOperations []string `json:"skipped_operations,omitempty"`
+}
+
+// ErrorCategory is one class of failure found in investigated logs, with a
+// representative line. It is stored under the "categories" key of
+// ErrorPatterns, most frequent first.
+type ErrorCategory struct {
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+	Sample string `json:"sample"`
+}
+
 type ChainOfThought struct {
 	Step      int       `json:"step"`
 	Thought   string    `json:"thought"`
@@ -95,4 +114,12 @@ type AgentContext struct {
 	Metrics        MetricsData       `json:"metrics"`
 	ServiceStatus  map[string]string `json:"service_status"`
 	LastUpdateTime time.Time         `json:"last_update_time"`
+	Progress       *EventSink        `json:"-"`
+	// TimeRange is the window log and metric operations investigate.
+	TimeRange eaws.TimeRange `json:"time_range"`
+	// Trace is filled in by the coordinator after the decision tree runs.
+	Trace *CoordinatorTrace `json:"trace,omitempty"`
+	// PriorQueries are the earlier questions of a follow-up session, oldest
+	// first; OriginalQuery is the latest.
+	PriorQueries []string `json:"prior_queries,omitempty"`
 }
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CoordinatorTrace explains what the coordinator did for one investigation:
+// which decision tree nodes matched, which keywords made them match, and
+// which agents each node spawned with what operations.
+type CoordinatorTrace struct {
+	Query  string       `json:"query"`
+	Path   []string     `json:"path"`
+	Nodes  []TraceNode  `json:"nodes"`
+	Agents []TraceAgent `json:"agents"`
+}
+
+// TraceNode is a decision tree node whose condition matched the query.
+type TraceNode struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Condition       string   `json:"condition"`
+	MatchedKeywords []string `json:"matched_keywords,omitempty"`
+	AgentTypes      []string `json:"agent_types,omitempty"`
+}
+
+// TraceAgent is an agent the coordinator planned, with the node that won the
+// agent type (highest priority) and how the run ended.
+type TraceAgent struct {
+	AgentType       string   `json:"agent_type"`
+	NodeID          string   `json:"node_id"`
+	NodeName        string   `json:"node_name"`
+	MatchedKeywords []string `json:"matched_keywords,omitempty"`
+	Priority        int      `json:"priority"`
+	Operations      []string `json:"operations"`
+	Status          string   `json:"status"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// Format renders the trace for the --explain output.
+func (t *CoordinatorTrace) Format() string {
+	if t == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("🌳 Decision path: ")
+	if len(t.Path) == 0 {
+		b.WriteString("(no nodes matched)\n")
+	} else {
+		b.WriteString(strings.Join(t.Path, " → ") + "\n")
+	}
+
+	for _, node := range t.Nodes {
+		b.WriteString(fmt.Sprintf("  • %s (%s)\n", node.ID, node.Name))
+		b.WriteString(fmt.Sprintf("      condition: %s\n", node.Condition))
+		if len(node.MatchedKeywords) > 0 {
+			b.WriteString(fmt.Sprintf("      matched: %s\n", strings.Join(node.MatchedKeywords, ", ")))
+		}
+		if len(node.AgentTypes) > 0 {
+			b.WriteString(fmt.Sprintf("      agents: %s\n", strings.Join(node.AgentTypes, ", ")))
+		}
+	}
+
+	if len(t.Agents) == 0 {
+		b.WriteString("🤖 No parallel agents spawned; the sequential planner ran instead\n")
+		return b.String()
+	}
+	b.WriteString("🤖 Agents:\n")
+	for _, agent := range t.Agents {
+		b.WriteString(fmt.Sprintf("  • %s [%s] via %s (priority %d)", agent.AgentType, agent.Status, agent.NodeID, agent.Priority))
+		if len(agent.MatchedKeywords) > 0 {
+			b.WriteString(fmt.Sprintf(" on %s", strings.Join(agent.MatchedKeywords, ", ")))
+		}
+		b.WriteString("\n")
+		if len(agent.Operations) > 0 {
+			b.WriteString(fmt.Sprintf("      operations: %s\n", strings.Join(agent.Operations, ", ")))
+		}
+		if agent.Error != "" {
+			b.WriteString(fmt.Sprintf("      error: %s\n", agent.Error))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoordinatorTraceFormat(t *testing.T) {
+	trace := &CoordinatorTrace{
+		Query: "why is checkout slow",
+		Path:  []string{"root", "performance_check"},
+		Nodes: []TraceNode{
+			{ID: "performance_check", Name: "Performance investigation", Condition: "contains_keywords(['slow'])", MatchedKeywords: []string{"slow"}, AgentTypes: []string{"metrics"}},
+		},
+		Agents: []TraceAgent{
+			{AgentType: "metrics", NodeID: "performance_check", MatchedKeywords: []string{"slow"}, Priority: 7, Operations: []string{"get_metric_statistics"}, Status: "failed", Error: "boom"},
+		},
+	}
+	out := trace.Format()
+	for _, want := range []string{"root → performance_check", "matched: slow", "metrics [failed] via performance_check (priority 7) on slow", "operations: get_metric_statistics", "error: boom"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	empty := (&CoordinatorTrace{Path: []string{"root"}}).Format()
+	if !strings.Contains(empty, "sequential planner") {
+		t.Errorf("expected the sequential fallback note, got:\n%s", empty)
+	}
+	if (*CoordinatorTrace)(nil).Format() != "" {
+		t.Error("nil trace should format to an empty string")
+	}
+}
@@ -163,40 +163,23 @@ func (a *Agent) gatherLogs(ctx context.Context, agentCtx *AgentContext, decision
 		fmt.Printf("🔍 Found %d relevant log groups\n", len(logGroups))
 	}
 
-	for _, logGroup := range logGroups {
-		if verbose {
-			fmt.Printf("📋 Fetching recent logs from: %s\n", logGroup)
-		}
+	start, end := agentCtx.TimeRange.Bounds(time.Now(), recentLogsWindow)
 
-		recentLogs, err := a.getRecentLogsFromGroup(ctx, logGroup)
-		if err != nil {
-			if verbose {
-				fmt.Printf("⚠️  Failed to get recent logs from %s: %v\n", logGroup, err)
-			}
-			continue
-		}
+	// Fetch groups concurrently into fixed slots so GatheredData keeps the
+	// ranked group order regardless of which fetch finishes first.
+	results := make([]*groupLogs, len(logGroups))
+	forEachBounded(ctx, len(logGroups), configuredLogFetchConcurrency(), func(i int) {
+		results[i] = a.fetchGroupLogs(ctx, logGroups[i], start, end, verbose)
+	})
 
-		errorLogs, err := a.getErrorLogsFromGroup(ctx, logGroup)
-		if err != nil {
-			if verbose {
-				fmt.Printf("⚠️  Failed to get error logs from %s: %v\n", logGroup, err)
-			}
-		}
-
-		logStreams, err := a.getLogStreamsFromGroup(ctx, logGroup)
-		if err != nil {
-			if verbose {
-				fmt.Printf("⚠️  Failed to get log streams from %s: %v\n", logGroup, err)
-			}
-		}
-
-		if verbose {
-			fmt.Printf("✅ Retrieved %d recent log entries, %d error logs, %d streams from %s\n",
-				len(recentLogs), len(errorLogs), len(logStreams), logGroup)
+	for _, result := range results {
+		if result == nil {
+			continue
 		}
+		recentLogs, errorLogs, logStreams := result.recent, result.errors, result.streams
 
 		logData := LogData{
-			"log_group":     logGroup,
+			"log_group":     result.group,
 			"recent_logs":   recentLogs,
 			"error_logs":    errorLogs,
 			"log_streams":   logStreams,
@@ -339,3 +322,45 @@ func (a *Agent) executeAWSFunction(ctx context.Context, awsFunc AWSFunctionCall)
 	}
 	return result, nil
 }
+
+// groupLogs is what gatherLogs fetched from one log group.
+type groupLogs struct {
+	group   string
+	recent  []string
+	errors  []string
+	streams []string
+}
+
+// fetchGroupLogs reads recent entries, errors and streams from one log
+// group. It returns nil when the recent entries cannot be read; the error
+// and stream lookups are best effort.
+func (a *Agent) fetchGroupLogs(ctx context.Context, logGroup string, start, end time.Time, verbose bool) *groupLogs {
+	if verbose {
+		fmt.Printf("📋 Fetching recent logs from: %s\n", logGroup)
+	}
+
+	recentEvents, err := a.tailLogs(ctx, logGroup, end, end.Sub(start), recentLogsLimit)
+	if err != nil {
+		if verbose {
+			fmt.Printf("⚠️  Failed to get recent logs from %s: %v\n", logGroup, err)
+		}
+		return nil
+	}
+	result := &groupLogs{group: logGroup, recent: formatLogEvents(recentEvents)}
+
+	result.errors, err = a.getErrorLogsFromGroup(ctx, logGroup, start, end)
+	if err != nil && verbose {
+		fmt.Printf("⚠️  Failed to get error logs from %s: %v\n", logGroup, err)
+	}
+
+	result.streams, err = a.getLogStreamsFromGroup(ctx, logGroup)
+	if err != nil && verbose {
+		fmt.Printf("⚠️  Failed to get log streams from %s: %v\n", logGroup, err)
+	}
+
+	if verbose {
+		fmt.Printf("✅ Retrieved %d recent log entries, %d error logs, %d streams from %s\n",
+			len(result.recent), len(result.errors), len(result.streams), logGroup)
+	}
+	return result
+}
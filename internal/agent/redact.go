@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// arnAccountPattern captures the account field of an ARN; S3 and other
+	// global ARNs leave the region empty.
+	arnAccountPattern = regexp.MustCompile(`(arn:aws[a-z-]*:[a-z0-9-]*:[a-z0-9-]*:)(\d{12})(:)`)
+	// ecrHostPattern catches account IDs in ECR registry hosts.
+	ecrHostPattern   = regexp.MustCompile(`\b(\d{12})(\.dkr\.ecr\.)`)
+	accountIDPattern = regexp.MustCompile(`\b\d{12}\b`)
+	ipv4Pattern      = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	// ec2HostPattern matches EC2 private DNS names such as ip-10-0-1-5.
+	ec2HostPattern   = regexp.MustCompile(`\bip-(\d{1,3}-\d{1,3}-\d{1,3}-\d{1,3})\b`)
+	accessKeyPattern = regexp.MustCompile(`\b(?:AKIA|ASIA|AIDA|AROA)[A-Z0-9]{16}\b`)
+	placeholderMatch = regexp.MustCompile(`<(?:ACCOUNT|IP|ACCESS_KEY)_\d+>`)
+)
+
+// redactor replaces account IDs, private IPs and access key IDs with
+// placeholders such as <ACCOUNT_1>. The mapping lives as long as the
+// redactor, so the same value gets the same placeholder across every output
+// of a run and relationships between resources survive redaction.
+type redactor struct {
+	mu     sync.Mutex
+	values map[string]map[string]string
+}
+
+func newRedactor() *redactor {
+	return &redactor{values: make(map[string]map[string]string)}
+}
+
+// placeholder returns the stable placeholder for value within kind.
+func (r *redactor) placeholder(kind, value string) string {
+	seen := r.values[kind]
+	if seen == nil {
+		seen = make(map[string]string)
+		r.values[kind] = seen
+	}
+	if p, ok := seen[value]; ok {
+		return p
+	}
+	p := fmt.Sprintf("<%s_%d>", kind, len(seen)+1)
+	seen[value] = p
+	return p
+}
+
+// redactSensitive rewrites s with every sensitive value replaced. Bare
+// 12-digit numbers are only redacted once they were seen as an account ID,
+// so counters and timestamps are left alone.
+func (r *redactor) redactSensitive(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s = arnAccountPattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := arnAccountPattern.FindStringSubmatch(m)
+		return parts[1] + r.placeholder("ACCOUNT", parts[2]) + parts[3]
+	})
+	s = ecrHostPattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := ecrHostPattern.FindStringSubmatch(m)
+		return r.placeholder("ACCOUNT", parts[1]) + parts[2]
+	})
+	s = accountIDPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if p, ok := r.values["ACCOUNT"][m]; ok {
+			return p
+		}
+		return m
+	})
+	s = accessKeyPattern.ReplaceAllStringFunc(s, func(m string) string {
+		return r.placeholder("ACCESS_KEY", m)
+	})
+	s = ec2HostPattern.ReplaceAllStringFunc(s, func(m string) string {
+		ip := strings.ReplaceAll(strings.TrimPrefix(m, "ip-"), "-", ".")
+		if !isPrivateIP(ip) {
+			return m
+		}
+		return "ip-" + r.placeholder("IP", ip)
+	})
+	return ipv4Pattern.ReplaceAllStringFunc(s, func(m string) string {
+		if !isPrivateIP(m) {
+			return m
+		}
+		return r.placeholder("IP", m)
+	})
+}
+
+// isPrivateIP reports whether s is an RFC 1918 address.
+func isPrivateIP(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && ip.IsPrivate()
+}
+
+// markdownPlaceholders escapes redaction placeholders outside fenced code
+// blocks; Markdown renderers would otherwise drop them as unknown HTML tags.
+func markdownPlaceholders(s string) string {
+	lines := strings.Split(s, "\n")
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, "`") == "" {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") {
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, "`"))]
+			continue
+		}
+		lines[i] = placeholderMatch.ReplaceAllStringFunc(line, func(m string) string {
+			return "&lt;" + m[1:len(m)-1] + "&gt;"
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Redact applies the agent's redaction to s when agent.redact is set and
+// returns s unchanged otherwise. Callers use it for output built outside the
+// agent, such as the final answer, so it shares the investigation's mapping.
+func (a *Agent) Redact(s string) string {
+	return a.redactor.redactSensitive(s)
+}
+
+// RedactFunc returns the --redact rewrite for output built without an agent
+// investigation, such as the dynamic analysis answer. It returns s unchanged
+// when agent.redact is off.
+func RedactFunc() func(string) string {
+	if !viper.GetBool("agent.redact") {
+		return func(s string) string { return s }
+	}
+	return newRedactor().redactSensitive
+}
@@ -3,7 +3,9 @@ package semantic
 
 import (
 	"math"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/bgdnvk/clanker/internal/agent/model"
 )
@@ -59,14 +61,7 @@ func (sa *Analyzer) AnalyzeQuery(query string) model.QueryIntent {
 		intent.Confidence = math.Min(maxScore/float64(len(words)), 1.0)
 	}
 
-	for service, keywords := range sa.ServiceMapping {
-		for _, keyword := range keywords {
-			if strings.Contains(queryLower, keyword) {
-				intent.TargetServices = append(intent.TargetServices, service)
-				break
-			}
-		}
-	}
+	intent.TargetServices, intent.ServiceConfidence = sa.matchServices(tokenize(words))
 
 	urgencyScore := 0.0
 	for _, word := range words {
@@ -114,6 +109,8 @@ func (sa *Analyzer) AnalyzeQuery(query string) model.QueryIntent {
 			intent.DataTypes = []string{"metrics", "status"}
 		case "analyze":
 			intent.DataTypes = []string{"logs", "metrics"}
+		case "capacity":
+			intent.DataTypes = []string{"quotas", "metrics"}
 		default:
 			intent.DataTypes = []string{"status"}
 		}
@@ -121,3 +118,97 @@ func (sa *Analyzer) AnalyzeQuery(query string) model.QueryIntent {
 
 	return intent
 }
+
+// serviceMatch records how strongly a service was referenced by a query.
+type serviceMatch struct {
+	service  string
+	matches  int
+	firstPos int
+}
+
+// matchServices tags services whose keywords appear as whole tokens (or
+// consecutive token phrases for multi-word keywords). Services are ordered by
+// the number of distinct keywords matched, then by where they were first
+// mentioned, so the most likely service comes first.
+func (sa *Analyzer) matchServices(tokens []string) ([]string, map[string]float64) {
+	var matched []serviceMatch
+	for service, keywords := range sa.ServiceMapping {
+		m := serviceMatch{service: service, firstPos: len(tokens)}
+		seen := make(map[string]bool, len(keywords))
+		for _, keyword := range keywords {
+			if seen[keyword] {
+				continue
+			}
+			seen[keyword] = true
+			if pos := indexPhrase(tokens, strings.Fields(keyword)); pos >= 0 {
+				m.matches++
+				if pos < m.firstPos {
+					m.firstPos = pos
+				}
+			}
+		}
+		if m.matches > 0 {
+			matched = append(matched, m)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].matches != matched[j].matches {
+			return matched[i].matches > matched[j].matches
+		}
+		if matched[i].firstPos != matched[j].firstPos {
+			return matched[i].firstPos < matched[j].firstPos
+		}
+		return matched[i].service < matched[j].service
+	})
+
+	services := make([]string, 0, len(matched))
+	confidence := make(map[string]float64, len(matched))
+	for _, m := range matched {
+		services = append(services, m.service)
+		// Each additional distinct keyword halves the remaining uncertainty.
+		confidence[m.service] = 1.0 - math.Pow(0.5, float64(m.matches))
+	}
+	return services, confidence
+}
+
+// tokenize strips surrounding punctuation from whitespace-separated words so
+// "lambda?" and "(rds)" compare equal to their keywords.
+func tokenize(words []string) []string {
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		token := strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// indexPhrase returns the position of phrase within tokens, or -1. The final
+// phrase word also matches its simple plural ("bucket" matches "buckets").
+func indexPhrase(tokens, phrase []string) int {
+	if len(phrase) == 0 || len(phrase) > len(tokens) {
+		return -1
+	}
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		ok := true
+		for j, word := range phrase {
+			token := tokens[i+j]
+			if token == word {
+				continue
+			}
+			if j == len(phrase)-1 && (token == word+"s" || token == word+"es") {
+				continue
+			}
+			ok = false
+			break
+		}
+		if ok {
+			return i
+		}
+	}
+	return -1
+}
@@ -149,3 +149,83 @@ func TestAnalyzeQuery_ConfidenceRange(t *testing.T) {
 		}
 	}
 }
+
+func TestAnalyzeQuery_ServiceMatchingUsesWordBoundaries(t *testing.T) {
+	a := NewAnalyzer()
+
+	intent := a.AnalyzeQuery("enable cross3region replication")
+	for _, svc := range intent.TargetServices {
+		if svc == "s3" {
+			t.Errorf("expected s3 not to match inside a larger token, got %v", intent.TargetServices)
+		}
+	}
+
+	intent = a.AnalyzeQuery("check the route 53 records")
+	if len(intent.TargetServices) == 0 || intent.TargetServices[0] != "route53" {
+		t.Errorf("expected multi-word phrase to match route53 first, got %v", intent.TargetServices)
+	}
+}
+
+func TestAnalyzeQuery_ServicesOrderedByMatchWeight(t *testing.T) {
+	a := NewAnalyzer()
+
+	intent := a.AnalyzeQuery("postgres database migration api is slow")
+	if len(intent.TargetServices) == 0 || intent.TargetServices[0] != "rds" {
+		t.Fatalf("expected rds first, got %v", intent.TargetServices)
+	}
+	if intent.ServiceConfidence["rds"] <= intent.ServiceConfidence["api_gateway"] {
+		t.Errorf("expected rds confidence %.2f > api_gateway %.2f",
+			intent.ServiceConfidence["rds"], intent.ServiceConfidence["api_gateway"])
+	}
+
+	intent = a.AnalyzeQuery("why is my lambda? failing")
+	if len(intent.TargetServices) == 0 || intent.TargetServices[0] != "lambda" {
+		t.Errorf("expected punctuation to be ignored, got %v", intent.TargetServices)
+	}
+}
+
+func TestAnalyzeQuery_CapacityIntent(t *testing.T) {
+	a := NewAnalyzer()
+
+	intent := a.AnalyzeQuery("how close am i to my lambda concurrency quota")
+	if intent.Primary != "capacity" {
+		t.Fatalf("expected primary intent 'capacity', got %q", intent.Primary)
+	}
+	if len(intent.TargetServices) == 0 || intent.TargetServices[0] != "lambda" {
+		t.Errorf("expected lambda as target service, got %v", intent.TargetServices)
+	}
+	if len(intent.DataTypes) == 0 || intent.DataTypes[0] != "quotas" {
+		t.Errorf("expected quota data types for capacity intent, got %v", intent.DataTypes)
+	}
+}
+
+func TestCompareFollowUp_InheritsServicesAndWindow(t *testing.T) {
+	a := NewAnalyzer()
+	prior := a.AnalyzeQuery("why is my lambda slow in the past week")
+
+	f := a.CompareFollowUp(prior, "show me the worst 5 requests")
+	if !f.Reusable() {
+		t.Errorf("expected a reusable follow-up, got %+v", f)
+	}
+	if len(f.Intent.TargetServices) == 0 || f.Intent.TargetServices[0] != prior.TargetServices[0] {
+		t.Errorf("services not inherited: %v (prior %v)", f.Intent.TargetServices, prior.TargetServices)
+	}
+	if f.Intent.TimeFrame != prior.TimeFrame {
+		t.Errorf("time frame = %q, want the prior %q", f.Intent.TimeFrame, prior.TimeFrame)
+	}
+}
+
+func TestCompareFollowUp_NewServiceOrWindow(t *testing.T) {
+	a := NewAnalyzer()
+	prior := a.AnalyzeQuery("why is my lambda slow")
+
+	f := a.CompareFollowUp(prior, "is rds slow too")
+	if f.Reusable() || len(f.NewServices) == 0 {
+		t.Errorf("a new service should not be reusable: %+v", f)
+	}
+
+	f = a.CompareFollowUp(prior, "what did lambda look like in the past")
+	if !f.TimeFrameChanged || f.Reusable() {
+		t.Errorf("a new time frame should not be reusable: %+v", f)
+	}
+}
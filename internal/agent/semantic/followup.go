@@ -0,0 +1,81 @@
+package semantic
+
+import (
+	"strings"
+
+	"github.com/bgdnvk/clanker/internal/agent/model"
+)
+
+// FollowUp describes how a follow-up question relates to the question before
+// it, so an investigation can decide what it already has.
+type FollowUp struct {
+	// Intent is the follow-up's intent with the prior question's services and
+	// time frame filled in where the follow-up does not name its own.
+	Intent model.QueryIntent
+	// SameIntent is true when both questions share a primary intent.
+	SameIntent bool
+	// NewServices are services the follow-up names that the prior did not.
+	NewServices []string
+	// NewDataTypes are data types the follow-up needs that the prior did not.
+	NewDataTypes []string
+	// TimeFrameChanged is true when the follow-up names a different window.
+	TimeFrameChanged bool
+}
+
+// Reusable reports whether data gathered for the prior question still applies:
+// the window is the same and no new services are involved. Agents for new
+// data types may still need to run on top of it.
+func (f FollowUp) Reusable() bool {
+	return !f.TimeFrameChanged && len(f.NewServices) == 0
+}
+
+// CompareFollowUp analyzes a follow-up question against the prior question's
+// intent. A follow-up such as "show me the worst 5 requests" names no service
+// or time frame of its own, so it inherits both from the prior question.
+func (sa *Analyzer) CompareFollowUp(prior model.QueryIntent, query string) FollowUp {
+	intent := sa.AnalyzeQuery(query)
+	f := FollowUp{SameIntent: intent.Primary != "" && intent.Primary == prior.Primary}
+
+	if len(intent.TargetServices) == 0 {
+		intent.TargetServices = append([]string(nil), prior.TargetServices...)
+		intent.ServiceConfidence = prior.ServiceConfidence
+	} else {
+		f.NewServices = missingFrom(prior.TargetServices, intent.TargetServices)
+	}
+
+	if sa.namesTimeFrame(query) {
+		f.TimeFrameChanged = intent.TimeFrame != prior.TimeFrame
+	} else if prior.TimeFrame != "" {
+		intent.TimeFrame = prior.TimeFrame
+	}
+
+	f.NewDataTypes = missingFrom(prior.DataTypes, intent.DataTypes)
+	f.Intent = intent
+	return f
+}
+
+// namesTimeFrame reports whether the query contains a time frame keyword;
+// AnalyzeQuery falls back to "recent" when it does not.
+func (sa *Analyzer) namesTimeFrame(query string) bool {
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if _, ok := sa.TimeFrameWords[word]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// missingFrom returns the entries of next that are not in prior.
+func missingFrom(prior, next []string) []string {
+	seen := make(map[string]bool, len(prior))
+	for _, v := range prior {
+		seen[v] = true
+	}
+	var missing []string
+	for _, v := range next {
+		if !seen[v] {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
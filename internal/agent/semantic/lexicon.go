@@ -23,6 +23,7 @@ var (
 		"monitor":      {"status", "health", "performance", "metrics", "dashboard"},
 		"analyze":      {"data", "logs", "patterns", "trends", "analysis"},
 		"investigate":  {"investigate", "find", "search", "look", "check"},
+		"capacity":     {"quota", "limit", "capacity", "throttle", "concurrency", "headroom"},
 	}
 
 	defaultServiceMapping = ServiceMapping{
@@ -82,6 +83,18 @@ var (
 			"patterns": 0.8,
 			"trends":   0.6,
 		},
+		"capacity": {
+			"quota":       1.0,
+			"quotas":      1.0,
+			"limit":       0.9,
+			"limits":      0.9,
+			"capacity":    0.8,
+			"headroom":    0.9,
+			"concurrency": 0.7,
+			"throttle":    0.6,
+			"throttled":   0.6,
+			"throttling":  0.6,
+		},
 	}
 
 	defaultUrgencyKeywords = UrgencyKeywords{
@@ -0,0 +1,43 @@
+package agent
+
+import "context"
+
+// Session is a conversational investigation. Each question after the first
+// extends the previous AgentContext rather than starting over, so a follow-up
+// such as "show me the worst 5 requests" reuses what "why is my service
+// slow" already gathered. The agent's memory carries across questions too.
+type Session struct {
+	agent *Agent
+	last  *AgentContext
+}
+
+// NewSession starts a follow-up session on this agent.
+func (a *Agent) NewSession() *Session {
+	return &Session{agent: a}
+}
+
+// Ask investigates query, extending the previous question's context when
+// there is one. opts.Prior is set by the session.
+func (s *Session) Ask(ctx context.Context, query string, opts AgentOptions) (*AgentContext, error) {
+	opts.Prior = s.last
+	agentCtx, err := s.agent.InvestigateQueryWithOptions(ctx, query, opts)
+	if agentCtx != nil {
+		s.last = agentCtx
+	}
+	return agentCtx, err
+}
+
+// Agent returns the agent the session investigates with.
+func (s *Session) Agent() *Agent {
+	return s.agent
+}
+
+// Context returns the latest investigation, or nil before the first question.
+func (s *Session) Context() *AgentContext {
+	return s.last
+}
+
+// Reset forgets the gathered context; the next question starts over.
+func (s *Session) Reset() {
+	s.last = nil
+}
@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StructuredResult is the machine-readable counterpart of BuildFinalContext.
+// It carries the same findings without the prompt-oriented formatting so
+// scripts can consume agent output reliably.
+type StructuredResult struct {
+	Query            string              `json:"query"`
+	Steps            int                 `json:"steps"`
+	SemanticAnalysis *QueryIntent        `json:"semantic_analysis,omitempty"`
+	Services         []ServiceLogSummary `json:"services"`
+	ErrorPatterns    ErrorPatterns       `json:"error_patterns,omitempty"`
+	Findings         []AgentFinding      `json:"findings"`
+	ChainOfThought   []ChainOfThought    `json:"chain_of_thought"`
+	CompletedAt      time.Time           `json:"completed_at"`
+
+	// ServiceHealth is the health derived for each investigated service.
+	ServiceHealth []ServiceHealthReport `json:"service_health,omitempty"`
+
+	// Warning and Failures are set when some agents could not gather data.
+	Warning  string         `json:"warning,omitempty"`
+	Failures []AgentFailure `json:"failures,omitempty"`
+
+	// Error is set when the investigation could not run at all.
+	Error string `json:"error,omitempty"`
+}
+
+// FailedResult is the structured result of an investigation that failed, so
+// --output json callers get JSON rather than a prose fallback answer.
+func FailedResult(query string, err error) *StructuredResult {
+	return &StructuredResult{
+		Query:          query,
+		Services:       []ServiceLogSummary{},
+		Findings:       []AgentFinding{},
+		ChainOfThought: []ChainOfThought{},
+		CompletedAt:    time.Now(),
+		Error:          err.Error(),
+	}
+}
+
+// ServiceLogSummary aggregates the log data gathered for a single service.
+type ServiceLogSummary struct {
+	Service    string            `json:"service"`
+	LogGroups  []LogGroupSummary `json:"log_groups"`
+	TotalLogs  int               `json:"total_logs"`
+	ErrorCount int               `json:"error_count"`
+}
+
+// LogGroupSummary describes what was read from one CloudWatch log group.
+type LogGroupSummary struct {
+	LogGroup     string   `json:"log_group"`
+	TotalEntries int      `json:"total_entries"`
+	ErrorCount   int      `json:"error_count"`
+	StreamCount  int      `json:"stream_count"`
+	RecentErrors []string `json:"recent_errors,omitempty"`
+}
+
+// AgentFinding is a single piece of data produced by a parallel agent or
+// direct AWS function call.
+type AgentFinding struct {
+	Source string `json:"source"`
+	Key    string `json:"key"`
+	Data   string `json:"data"`
+}
+
+// structuredSkipKeys are gathered-data keys that are either rendered in a
+// dedicated section or are internal bookkeeping.
+var structuredSkipKeys = map[string]bool{
+	"semantic_analysis": true,
+	"_metadata":         true,
+	"error_patterns":    true,
+	serviceHealthKey:    true,
+}
+
+// BuildStructuredResult converts the agent context into a StructuredResult.
+func (a *Agent) BuildStructuredResult(agentCtx *AgentContext) *StructuredResult {
+	if agentCtx == nil {
+		return nil
+	}
+
+	result := &StructuredResult{
+		Query:          agentCtx.OriginalQuery,
+		Steps:          agentCtx.CurrentStep,
+		Services:       []ServiceLogSummary{},
+		Findings:       []AgentFinding{},
+		ChainOfThought: append([]ChainOfThought{}, agentCtx.ChainOfThought...),
+		CompletedAt:    agentCtx.LastUpdateTime,
+	}
+
+	if semData, ok := agentCtx.GatheredData["semantic_analysis"].(map[string]any); ok {
+		if intent, ok := semData["intent"].(QueryIntent); ok {
+			result.SemanticAnalysis = &intent
+		}
+	}
+
+	if failures := agentFailures(agentCtx); len(failures) > 0 {
+		result.Warning = incompleteDataWarning
+		result.Failures = failures
+	}
+
+	if patterns, ok := agentCtx.GatheredData["error_patterns"].(ErrorPatterns); ok {
+		result.ErrorPatterns = patterns
+	}
+	if reports, ok := agentCtx.GatheredData[serviceHealthKey].([]ServiceHealthReport); ok {
+		result.ServiceHealth = reports
+	}
+
+	keys := make([]string, 0, len(agentCtx.GatheredData))
+	for key := range agentCtx.GatheredData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if structuredSkipKeys[key] {
+			continue
+		}
+		data := agentCtx.GatheredData[key]
+
+		if strings.HasSuffix(key, "_all_log_entries") {
+			continue
+		}
+		if logGroups, ok := data.([]LogData); ok && strings.HasSuffix(key, "_logs") {
+			result.Services = append(result.Services, summarizeServiceLogs(strings.TrimSuffix(key, "_logs"), logGroups))
+			continue
+		}
+
+		if awsData, ok := data.(AWSData); ok {
+			subKeys := make([]string, 0, len(awsData))
+			for subKey := range awsData {
+				subKeys = append(subKeys, subKey)
+			}
+			sort.Strings(subKeys)
+			for _, subKey := range subKeys {
+				result.Findings = append(result.Findings, AgentFinding{
+					Source: key,
+					Key:    subKey,
+					Data:   stringifyData(awsData[subKey]),
+				})
+			}
+			continue
+		}
+
+		// Parallel agents store both a nested AWSData entry and flattened
+		// "<agent>_<key>" copies; the nested form above already covers them.
+		if isFlattenedAgentKey(key, agentCtx.GatheredData) {
+			continue
+		}
+
+		result.Findings = append(result.Findings, AgentFinding{
+			Source: "agent",
+			Key:    key,
+			Data:   stringifyData(data),
+		})
+	}
+
+	return result
+}
+
+// ToJSON renders the structured result as indented JSON.
+func (r *StructuredResult) ToJSON() (string, error) {
+	if r == nil {
+		return "null", nil
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal structured result: %w", err)
+	}
+	return string(data), nil
+}
+
+// summarizeServiceLogs condenses the LogData entries gathered for a service.
+func summarizeServiceLogs(service string, logGroups []LogData) ServiceLogSummary {
+	summary := ServiceLogSummary{Service: service, LogGroups: []LogGroupSummary{}}
+	for _, lgd := range logGroups {
+		group := LogGroupSummary{}
+		if name, ok := lgd["log_group"].(string); ok {
+			group.LogGroup = name
+		}
+		if n, ok := lgd["total_entries"].(int); ok {
+			group.TotalEntries = n
+		}
+		if n, ok := lgd["error_count"].(int); ok {
+			group.ErrorCount = n
+		}
+		if n, ok := lgd["stream_count"].(int); ok {
+			group.StreamCount = n
+		}
+		if errs, ok := lgd["error_logs"].([]string); ok && len(errs) > 0 {
+			limit := 10
+			if len(errs) < limit {
+				limit = len(errs)
+			}
+			group.RecentErrors = append([]string{}, errs[:limit]...)
+		}
+		summary.TotalLogs += group.TotalEntries
+		summary.ErrorCount += group.ErrorCount
+		summary.LogGroups = append(summary.LogGroups, group)
+	}
+	return summary
+}
+
+// isFlattenedAgentKey reports whether key is a "<agent>_<subkey>" copy of a
+// value already present under the agent's nested AWSData entry.
+func isFlattenedAgentKey(key string, gathered AWSData) bool {
+	for agentKey, value := range gathered {
+		nested, ok := value.(AWSData)
+		if !ok || !strings.HasPrefix(key, agentKey+"_") {
+			continue
+		}
+		if _, exists := nested[strings.TrimPrefix(key, agentKey+"_")]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+func stringifyData(data any) string {
+	if s, ok := data.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", data)
+}
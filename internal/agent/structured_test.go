@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuildStructuredResult_NilContext(t *testing.T) {
+	a := &Agent{}
+	if result := a.BuildStructuredResult(nil); result != nil {
+		t.Errorf("expected nil result for nil context, got %+v", result)
+	}
+}
+
+func TestBuildStructuredResult_SummarizesLogsAndFindings(t *testing.T) {
+	a := &Agent{}
+	ctx := &AgentContext{
+		OriginalQuery: "why is chat failing",
+		CurrentStep:   2,
+		GatheredData: AWSData{
+			"semantic_analysis": map[string]any{
+				"intent": QueryIntent{Primary: "troubleshoot", TargetServices: []string{"lambda"}},
+			},
+			"chat_logs": []LogData{
+				{"log_group": "/aws/lambda/chat", "total_entries": 40, "error_count": 2, "error_logs": []string{"boom", "bang"}},
+			},
+			"chat_all_log_entries":      []string{"boom", "bang"},
+			"error_patterns":            ErrorPatterns{"total_errors": 2},
+			"log":                       AWSData{"log_discover_services": "lambda list"},
+			"log_log_discover_services": "lambda list",
+			"_metadata":                 AWSData{"total_agents": 1},
+		},
+	}
+
+	result := a.BuildStructuredResult(ctx)
+	if result.SemanticAnalysis == nil || result.SemanticAnalysis.Primary != "troubleshoot" {
+		t.Fatalf("expected semantic analysis to be extracted, got %+v", result.SemanticAnalysis)
+	}
+	if len(result.Services) != 1 || result.Services[0].Service != "chat" {
+		t.Fatalf("expected one chat service summary, got %+v", result.Services)
+	}
+	if got := result.Services[0].ErrorCount; got != 2 {
+		t.Errorf("expected error count 2, got %d", got)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Source != "log" {
+		t.Errorf("expected flattened agent keys to be deduplicated, got %+v", result.Findings)
+	}
+
+	out, err := result.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if strings.Contains(out, "_metadata") || strings.Contains(out, "DEBUG") {
+		t.Errorf("structured output should not include internal keys: %s", out)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("ToJSON produced invalid JSON: %v", err)
+	}
+}
+
+func TestBuildStructuredResult_ReportsIncompleteData(t *testing.T) {
+	a := &Agent{}
+	ctx := &AgentContext{
+		OriginalQuery: "any errors in checkout",
+		GatheredData: AWSData{
+			"_metadata": AWSData{
+				"failed_agents": []AgentFailure{
+					{Agent: "log", Error: "AccessDenied", FailedOperations: []string{"get_recent_logs"}, SkippedOperations: []string{"analyze_lambda_errors"}},
+				},
+			},
+		},
+	}
+
+	result := a.BuildStructuredResult(ctx)
+	if result.Warning == "" || len(result.Failures) != 1 || result.Failures[0].Agent != "log" {
+		t.Fatalf("expected an incomplete data warning for the log agent, got %q %+v", result.Warning, result.Failures)
+	}
+
+	final := a.BuildFinalContext(ctx)
+	for _, want := range []string{"INCOMPLETE DATA", "log agent: AccessDenied", "failed: get_recent_logs", "not run: analyze_lambda_errors", "do not conclude that there are no errors"} {
+		if !strings.Contains(final, want) {
+			t.Errorf("expected %q in final context:\n%s", want, final)
+		}
+	}
+}
+
+func TestBuildMarkdownReport(t *testing.T) {
+	a := &Agent{}
+	ctx := &AgentContext{
+		OriginalQuery: "why is chat failing",
+		CurrentStep:   3,
+		GatheredData: AWSData{
+			"semantic_analysis": map[string]any{
+				"intent": QueryIntent{Primary: "troubleshoot", Urgency: "high"},
+			},
+			"chat_logs": []LogData{
+				{"log_group": "/aws/lambda/chat", "total_entries": 40, "error_count": 2, "error_logs": []string{"ERROR boom", "ERROR ```bang```"}},
+			},
+			"error_patterns": ErrorPatterns{"total_errors": 2, "total_logs_analyzed": 40, "categories": []ErrorCategory{{Name: "timeout", Count: 2, Sample: "Task timed out | retrying"}}},
+			"log":            AWSData{"log_discover_services": "🔍 lambda list"},
+			"_metadata":      AWSData{"failed_agents": []AgentFailure{{Agent: "metrics", Error: "AccessDenied"}}},
+		},
+	}
+
+	out := a.BuildMarkdownReport(ctx)
+	for _, want := range []string{
+		"# Investigation: why is chat failing",
+		"- **Errors found in logs:** 2",
+		"- **Intent:** troubleshoot (urgency: high)",
+		"> - **metrics**: AccessDenied",
+		"| chat | 1 | 40 | 2 |",
+		"| timeout | 2 | `Task timed out \\| retrying` |",
+		"<summary>chat: /aws/lambda/chat (2 errors)</summary>",
+		"````text\nERROR boom\nERROR ```bang```\n````",
+		"<summary>log: log_discover_services</summary>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in report:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"DEBUG", "_metadata", "===", "PARALLEL AGENT RESULTS"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("report should not contain %q:\n%s", unwanted, out)
+		}
+	}
+	if a.BuildMarkdownReport(nil) != "" {
+		t.Error("expected empty report for nil context")
+	}
+}
+
+func TestFailedResultJSON(t *testing.T) {
+	out, err := FailedResult("why is api failing", errors.New("throttled")).ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if decoded["error"] != "throttled" || decoded["query"] != "why is api failing" {
+		t.Errorf("unexpected failure result: %s", out)
+	}
+	if findings, ok := decoded["findings"].([]any); !ok || len(findings) != 0 {
+		t.Errorf("expected an empty findings list: %s", out)
+	}
+}
@@ -3,6 +3,8 @@ package agent
 import (
 	"fmt"
 	"time"
+
+	"github.com/bgdnvk/clanker/internal/agent/model"
 )
 
 // addThought adds a reasoning step to the chain of thought and timestamps it.
@@ -15,6 +17,12 @@ func (a *Agent) addThought(agentCtx *AgentContext, thought, action, outcome stri
 		Timestamp: time.Now(),
 	}
 	agentCtx.ChainOfThought = append(agentCtx.ChainOfThought, chainStep)
+	agentCtx.Emit(AgentEvent{
+		Type:      model.EventThought,
+		Action:    action,
+		Message:   thought,
+		Timestamp: chainStep.Timestamp,
+	})
 }
 
 // displayChainOfThought streams the latest reasoning entries to stdout for transparency.
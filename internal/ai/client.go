@@ -84,8 +84,14 @@ type Client struct {
 	awsClient    *awsclient.Client
 	githubClient *ghclient.Client
 	aiProfile    string
+	modelRole    string // awsclient.ModelRole*; selects decision_model/analysis_model
 	debug        bool
 
+	// agentSession is set once StartAgentSession is called; follow-up
+	// investigations extend its context.
+	sessionMode  bool
+	agentSession *agent.Session
+
 	// AWS SDK fields - commented out but kept for future use
 	// bedrockClient *bedrockruntime.Client
 	// awsConfig     aws.Config
@@ -571,6 +577,10 @@ func NewClient(provider, apiKey string, debug bool, aiProfile ...string) *Client
 		client.baseURL = "https://api.deepseek.com/v1"
 	case "minimax":
 		client.baseURL = "https://api.minimax.io/anthropic"
+	case "ollama":
+		client.baseURL = resolveOllamaBaseURL()
+	case "openrouter":
+		client.baseURL = resolveOpenRouterBaseURL()
 	default:
 		// Default to OpenAI for best compatibility when no provider specified
 		client.provider = "openai"
@@ -684,8 +694,11 @@ func (c *Client) Ask(ctx context.Context, question, awsContext, codeContext stri
 
 // AskWithTools performs the full AWS tool calling workflow
 func (c *Client) AskWithTools(ctx context.Context, question, awsContext, codeContext, profileInfraAnalysis string, githubContext ...string) (string, error) {
-	// Check if this query would benefit from intelligent agent investigation
-	if c.shouldUseAgent(question) && c.awsClient != nil {
+	// Check if this query would benefit from intelligent agent investigation.
+	// Structured output is only produced by the agent, so it always opts in.
+	// Follow-ups in a session stay with the agent so they can reuse its data.
+	inSession := c.agentSession != nil && c.agentSession.Context() != nil
+	if (inSession || c.shouldUseAgent(question) || agentOutputFormat() == "json") && c.awsClient != nil {
 		return c.askWithAgentInvestigation(ctx, question, awsContext, codeContext, profileInfraAnalysis, githubContext...)
 	}
 
@@ -695,6 +708,7 @@ func (c *Client) AskWithTools(ctx context.Context, question, awsContext, codeCon
 
 // askWithDynamicAnalysis implements the three-stage dynamic analysis approach for all AI providers
 func (c *Client) askWithDynamicAnalysis(ctx context.Context, question, awsContext, codeContext, profileInfraAnalysis string, githubContext ...string) (string, error) {
+	ctx = awsclient.WithTimeRange(ctx, awsclient.ConfiguredTimeRange())
 	emitProgressTrace("analysis", "Analyzing the request and selecting the live data to gather.")
 	if c.debug {
 		fmt.Printf("🔍 Stage 1: Analyzing query with dynamic tool selection...\n")
@@ -710,26 +724,7 @@ func (c *Client) askWithDynamicAnalysis(ctx context.Context, question, awsContex
 	// Get analysis from the configured AI provider (uses AI profile for LLM calls)
 	var analysisResponse string
 	var err error
-	switch c.provider {
-	case "bedrock", "claude":
-		analysisResponse, err = c.askBedrock(ctx, analysisPrompt)
-	case "openai":
-		analysisResponse, err = c.askOpenAI(ctx, analysisPrompt)
-	case "clanker-cloud":
-		analysisResponse, err = c.askClankerCloud(ctx, analysisPrompt)
-	case "github-models":
-		analysisResponse, err = c.askGitHubModels(ctx, analysisPrompt)
-	case "anthropic":
-		analysisResponse, err = c.askAnthropic(ctx, analysisPrompt)
-	case "cohere":
-		analysisResponse, err = c.askCohere(ctx, analysisPrompt)
-	case "minimax":
-		analysisResponse, err = c.askMiniMax(ctx, analysisPrompt)
-	case "gemini", "gemini-api":
-		analysisResponse, err = c.askGemini(ctx, analysisPrompt)
-	default:
-		analysisResponse, err = c.askBedrock(ctx, analysisPrompt)
-	}
+	analysisResponse, err = c.Provider().Ask(ctx, analysisPrompt)
 	if err != nil {
 		return "", fmt.Errorf("failed to analyze query: %w", err)
 	}
@@ -854,15 +849,19 @@ func (c *Client) askWithDynamicAnalysis(ctx context.Context, question, awsContex
 		}
 	}
 
+	// --redact covers everything sent to the model and the answer.
+	redact := agent.RedactFunc()
+	contextText := redact(finalContext.String())
+
 	// Summarize context if too large to avoid CLI arg limits and reduce token usage
-	summarizedContext, err := c.summarizeContextIfNeeded(ctx, question, finalContext.String())
+	summarizedContext, err := c.summarizeContextIfNeeded(ctx, question, contextText)
 	if err != nil {
 		// Fallback: truncate context if summarization fails
 		fallbackLimit := c.contextPromptBudget().fallbackChars
-		if len(finalContext.String()) > fallbackLimit {
-			summarizedContext = finalContext.String()[:fallbackLimit]
+		if len(contextText) > fallbackLimit {
+			summarizedContext = contextText[:fallbackLimit]
 		} else {
-			summarizedContext = finalContext.String()
+			summarizedContext = contextText
 		}
 	}
 
@@ -882,26 +881,11 @@ Please provide a comprehensive answer based on the live data above.`, question,
 	emitProgressTrace("provider", fmt.Sprintf("Sending the final request to %s.", c.provider))
 
 	// Use the same provider switching logic as in the analysis phase
-	switch c.provider {
-	case "bedrock", "claude":
-		return c.askBedrock(ctx, finalPrompt)
-	case "openai":
-		return c.askOpenAI(ctx, finalPrompt)
-	case "clanker-cloud":
-		return c.askClankerCloud(ctx, finalPrompt)
-	case "github-models":
-		return c.askGitHubModels(ctx, finalPrompt)
-	case "anthropic":
-		return c.askAnthropic(ctx, finalPrompt)
-	case "cohere":
-		return c.askCohere(ctx, finalPrompt)
-	case "minimax":
-		return c.askMiniMax(ctx, finalPrompt)
-	case "gemini", "gemini-api":
-		return c.askGemini(ctx, finalPrompt)
-	default:
-		return c.askBedrock(ctx, finalPrompt)
+	response, err := c.Provider().Ask(ctx, finalPrompt)
+	if err != nil {
+		return "", err
 	}
+	return redact(response), nil
 }
 
 // Original Ask method for backward compatibility - replaced above
@@ -946,28 +930,7 @@ func (c *Client) AskOriginal(ctx context.Context, question, awsContext, codeCont
 	// Build the prompt with enhanced context
 	prompt := c.buildPrompt(question, enhancedContext.String(), "", "")
 
-	switch c.provider {
-	case "bedrock", "claude":
-		return c.askBedrock(ctx, prompt)
-	case "gemini", "gemini-api":
-		return c.askGemini(ctx, prompt)
-	case "github-models":
-		return c.askGitHubModels(ctx, prompt)
-	case "anthropic":
-		return c.askAnthropic(ctx, prompt)
-	case "cohere":
-		return c.askCohere(ctx, prompt)
-	case "minimax":
-		return c.askMiniMax(ctx, prompt)
-	case "openai":
-		return c.askOpenAI(ctx, prompt)
-	case "clanker-cloud":
-		return c.askClankerCloud(ctx, prompt)
-	default:
-		// Default to Bedrock (currently uses AWS CLI)
-		// AWS SDK fallback was: if c.bedrockClient != nil { return c.askBedrock(ctx, prompt) }
-		return c.askBedrock(ctx, prompt)
-	}
+	return c.Provider().Ask(ctx, prompt)
 }
 
 func firstNonEmptyString(values ...string) string {
@@ -2112,26 +2075,7 @@ func (c *Client) extractAndCleanJSON(response string) string {
 
 // AskPrompt sends a raw prompt to the configured provider without adding additional wrapper context.
 func (c *Client) AskPrompt(ctx context.Context, prompt string) (string, error) {
-	switch c.provider {
-	case "bedrock", "claude":
-		return c.askBedrock(ctx, prompt)
-	case "openai":
-		return c.askOpenAI(ctx, prompt)
-	case "clanker-cloud":
-		return c.askClankerCloud(ctx, prompt)
-	case "github-models":
-		return c.askGitHubModels(ctx, prompt)
-	case "anthropic":
-		return c.askAnthropic(ctx, prompt)
-	case "cohere":
-		return c.askCohere(ctx, prompt)
-	case "minimax":
-		return c.askMiniMax(ctx, prompt)
-	case "gemini", "gemini-api":
-		return c.askGemini(ctx, prompt)
-	default:
-		return c.askBedrock(ctx, prompt)
-	}
+	return c.Provider().Ask(ctx, prompt)
 }
 
 // AskWithContext sends a prompt maintaining conversation context for multi-turn interactions.
@@ -2160,6 +2104,10 @@ func (c *Client) AskWithContext(ctx context.Context, conv *ConversationContext,
 		response, err = c.askMiniMaxWithHistory(ctx, conv)
 	case "gemini", "gemini-api":
 		response, err = c.askGeminiWithHistory(ctx, conv)
+	case "ollama":
+		response, err = c.askOllama(ctx, flattenConversation(conv))
+	case "openrouter":
+		response, err = c.askOpenRouterWithHistory(ctx, conv)
 	default:
 		response, err = c.askBedrockWithHistory(ctx, conv)
 	}
@@ -2927,6 +2875,50 @@ func (c *Client) shouldUseAgent(question string) bool {
 	return false
 }
 
+// agentOutputFormat returns the requested agent output format (text, json or
+// markdown).
+func agentOutputFormat() string {
+	return strings.ToLower(strings.TrimSpace(viper.GetString("agent.output")))
+}
+
+// agentFailureJSON reports a failed investigation as a JSON error object, since
+// scripts asking for --output json cannot parse the prose fallback answer.
+func agentFailureJSON(question string, err error) (string, error) {
+	structured, jsonErr := agent.FailedResult(question, err).ToJSON()
+	return agent.RedactFunc()(structured), jsonErr
+}
+
+// StartAgentSession makes agent investigations a follow-up session: each
+// question after the first extends the previous investigation's context and
+// only gathers data the follow-up adds.
+func (c *Client) StartAgentSession() {
+	c.sessionMode = true
+}
+
+// agentInvestigator returns the agent for an investigation, and the session
+// to run it in when StartAgentSession was called.
+func (c *Client) agentInvestigator() (*agent.Agent, *agent.Session) {
+	if c.agentSession != nil {
+		return c.agentSession.Agent(), c.agentSession
+	}
+	investigator := agent.NewAgent(c.awsClient, c.debug)
+	// Set AI decision function so agent can make intelligent decisions
+	decider := c.WithModelRole(awsclient.ModelRoleDecision)
+	investigator.SetAIDecisionFunction(func(ctx context.Context, prompt string) (string, error) {
+		// Ask's path minus the agent routing, so a decision prompt
+		// never starts a nested investigation.
+		profile, err := decider.getAIProfile(decider.aiProfile)
+		if err != nil {
+			return "", fmt.Errorf("failed to get AI profile for LLM calls: %w", err)
+		}
+		return decider.askWithDynamicAnalysis(ctx, prompt, "", "", profile.AWSProfile)
+	})
+	if c.sessionMode {
+		c.agentSession = investigator.NewSession()
+	}
+	return investigator, c.agentSession
+}
+
 // askWithAgentInvestigation uses the intelligent agent to gather context before answering
 func (c *Client) askWithAgentInvestigation(ctx context.Context, question, awsContext, codeContext, profileInfraAnalysis string, githubContext ...string) (string, error) {
 	if c.debug {
@@ -2936,6 +2928,9 @@ func (c *Client) askWithAgentInvestigation(ctx context.Context, question, awsCon
 	// Find the appropriate AI profile for agent operations
 	profile := c.findAgentProfile(profileInfraAnalysis)
 	if profile == nil {
+		if agentOutputFormat() == "json" {
+			return agentFailureJSON(question, fmt.Errorf("no suitable AI profile found for agent operations"))
+		}
 		if c.debug {
 			fmt.Printf("⚠️  No suitable AI profile found for agent operations, falling back to standard approach\n")
 		}
@@ -2943,20 +2938,40 @@ func (c *Client) askWithAgentInvestigation(ctx context.Context, question, awsCon
 	}
 
 	// Create and run the investigative agent
-	investigator := agent.NewAgent(c.awsClient, c.debug)
-
-	// Set AI decision function so agent can make intelligent decisions
-	investigator.SetAIDecisionFunction(func(ctx context.Context, prompt string) (string, error) {
-		return c.Ask(ctx, prompt, "", "")
-	})
+	investigator, session := c.agentInvestigator()
 
-	agentContext, err := investigator.InvestigateQuery(ctx, question)
+	progress, waitProgress := startAgentProgressTrace()
+	var agentContext *agent.AgentContext
+	var err error
+	if session != nil {
+		agentContext, err = session.Ask(ctx, question, agent.AgentOptions{ProgressChan: progress})
+	} else {
+		agentContext, err = investigator.InvestigateQueryWithOptions(ctx, question, agent.AgentOptions{ProgressChan: progress})
+	}
+	waitProgress()
 	if err != nil {
+		if agentOutputFormat() == "json" {
+			return agentFailureJSON(question, err)
+		}
 		if c.debug {
 			fmt.Printf("⚠️  Agent investigation failed: %v, falling back to standard approach\n", err)
 		}
 		return c.askWithDynamicAnalysis(ctx, question, awsContext, codeContext, profileInfraAnalysis, githubContext...)
 	}
+	// Stderr keeps --explain usable alongside --output json.
+	if viper.GetBool("agent.explain") && agentContext.Trace != nil {
+		fmt.Fprint(os.Stderr, agentContext.Trace.Format())
+	}
+
+	// Scripts asked for machine-readable findings: skip the final LLM pass.
+	if agentOutputFormat() == "json" {
+		structured, err := investigator.BuildStructuredResult(agentContext).ToJSON()
+		return investigator.Redact(structured), err
+	}
+	// A Markdown report is the findings themselves, ready to paste.
+	if agentOutputFormat() == "markdown" {
+		return investigator.BuildMarkdownReport(agentContext), nil
+	}
 
 	// Build final context with agent's findings
 	finalContext := investigator.BuildFinalContext(agentContext)
@@ -2978,6 +2993,9 @@ func (c *Client) askWithAgentInvestigation(ctx context.Context, question, awsCon
 	}
 
 	combinedContext += finalContext
+	// The other contexts were gathered outside the agent; --redact covers
+	// them too so the final answer cannot echo what the agent hid.
+	combinedContext = investigator.Redact(combinedContext)
 
 	// Summarize context if too large to avoid CLI arg limits and reduce token usage
 	summarizedContext, sErr := c.summarizeContextIfNeeded(ctx, question, combinedContext)
@@ -3009,32 +3027,13 @@ Take your time to thoroughly analyze the data. Think extremely hard about what t
 
 	// Use the same AI provider for the final response
 	var response string
-	switch c.provider {
-	case "bedrock", "claude":
-		response, err = c.askBedrock(ctx, finalPrompt)
-	case "openai":
-		response, err = c.askOpenAI(ctx, finalPrompt)
-	case "clanker-cloud":
-		response, err = c.askClankerCloud(ctx, finalPrompt)
-	case "github-models":
-		response, err = c.askGitHubModels(ctx, finalPrompt)
-	case "anthropic":
-		response, err = c.askAnthropic(ctx, finalPrompt)
-	case "cohere":
-		response, err = c.askCohere(ctx, finalPrompt)
-	case "minimax":
-		response, err = c.askMiniMax(ctx, finalPrompt)
-	case "gemini", "gemini-api":
-		response, err = c.askGemini(ctx, finalPrompt)
-	default:
-		response, err = c.askBedrock(ctx, finalPrompt)
-	}
+	response, err = c.Provider().Ask(ctx, finalPrompt)
 
 	if err != nil {
 		return "", fmt.Errorf("failed to get final AI response: %w", err)
 	}
 
-	return response, nil
+	return investigator.Redact(response), nil
 }
 
 func (c *Client) contextPromptBudget() contextPromptBudget {
@@ -3203,26 +3202,7 @@ Summaries:\n%s`, question, mode, text)
 
 // dispatchLLM routes a small prompt to the configured LLM provider
 func (c *Client) dispatchLLM(ctx context.Context, prompt string) (string, error) {
-	switch c.provider {
-	case "bedrock", "claude":
-		return c.askBedrock(ctx, prompt)
-	case "openai":
-		return c.askOpenAI(ctx, prompt)
-	case "clanker-cloud":
-		return c.askClankerCloud(ctx, prompt)
-	case "github-models":
-		return c.askGitHubModels(ctx, prompt)
-	case "anthropic":
-		return c.askAnthropic(ctx, prompt)
-	case "cohere":
-		return c.askCohere(ctx, prompt)
-	case "minimax":
-		return c.askMiniMax(ctx, prompt)
-	case "gemini", "gemini-api":
-		return c.askGemini(ctx, prompt)
-	default:
-		return c.askBedrock(ctx, prompt)
-	}
+	return c.Provider().Ask(ctx, prompt)
 }
 
 // chunkString splits s into chunks up to size n runes (approx by bytes here)
@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultOpenRouterBaseURL is OpenRouter's OpenAI-compatible API root.
+	DefaultOpenRouterBaseURL = "https://openrouter.ai/api/v1"
+	// DefaultOpenRouterModel is used when the openrouter profile does not set a model.
+	DefaultOpenRouterModel = "anthropic/claude-3.5-sonnet"
+
+	// OpenRouter attributes traffic to an app through these two headers.
+	openRouterReferer = "https://github.com/bgdnvk/clanker"
+	openRouterTitle   = "clanker"
+)
+
+// resolveOpenRouterBaseURL reads ai.providers.openrouter.base_url, falling
+// back to DefaultOpenRouterBaseURL.
+func resolveOpenRouterBaseURL() string {
+	raw := strings.TrimSpace(viper.GetString("ai.providers.openrouter.base_url"))
+	if raw == "" {
+		return DefaultOpenRouterBaseURL
+	}
+	return strings.TrimRight(raw, "/")
+}
+
+// applyOpenRouterHeaders sets auth plus the attribution headers OpenRouter
+// expects. The referer and title can be overridden in config.
+func applyOpenRouterHeaders(req *http.Request, apiKey string) {
+	req.Header.Set("Content-Type", "application/json")
+	applyModelProviderAuthHeader(req, apiKey)
+	req.Header.Set("HTTP-Referer", firstNonEmptyString(viper.GetString("ai.providers.openrouter.referer"), openRouterReferer))
+	req.Header.Set("X-Title", firstNonEmptyString(viper.GetString("ai.providers.openrouter.title"), openRouterTitle))
+}
+
+func (c *Client) askOpenRouter(ctx context.Context, prompt string) (string, error) {
+	return c.askOpenRouterMessages(ctx, []Message{{Role: "user", Content: sanitizeASCII(prompt)}})
+}
+
+func (c *Client) askOpenRouterWithHistory(ctx context.Context, conv *ConversationContext) (string, error) {
+	messages := make([]Message, 0, len(conv.Messages)+1)
+	if conv.SystemPrompt != "" {
+		messages = append(messages, Message{Role: "system", Content: conv.SystemPrompt})
+	}
+	messages = append(messages, conv.Messages...)
+	return c.askOpenRouterMessages(ctx, messages)
+}
+
+// askOpenRouterMessages posts a chat completion to OpenRouter, retrying
+// transient failures the same way the OpenAI path does.
+func (c *Client) askOpenRouterMessages(ctx context.Context, messages []Message) (string, error) {
+	if strings.TrimSpace(c.apiKey) == "" {
+		return "", fmt.Errorf("OpenRouter API key not configured (set OPENROUTER_API_KEY or ai.providers.openrouter.api_key_env)")
+	}
+
+	model := DefaultOpenRouterModel
+	if profile, err := c.getAIProfile(c.aiProfile); err == nil && strings.TrimSpace(profile.Model) != "" {
+		model = strings.TrimSpace(profile.Model)
+	}
+	baseURL := c.baseURL
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = resolveOpenRouterBaseURL()
+	}
+
+	jsonData, err := json.Marshal(OpenAIRequest{Model: model, Messages: messages})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	endpoint := strings.TrimRight(baseURL, "/") + "/chat/completions"
+	emitProgressTrace("provider", fmt.Sprintf("Calling OpenRouter with model %s.", model))
+
+	client := &http.Client{Timeout: aiHTTPClientTimeout}
+	var body []byte
+	for attempt := 1; attempt <= aiRetryMaxAttempts; attempt++ {
+		req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(jsonData))
+		if reqErr != nil {
+			return "", fmt.Errorf("failed to create request: %w", reqErr)
+		}
+		applyOpenRouterHeaders(req, c.apiKey)
+
+		resp, doErr := client.Do(req)
+		if doErr != nil {
+			if attempt == aiRetryMaxAttempts || !isRetryableProviderErrorText(doErr.Error()) {
+				return "", fmt.Errorf("failed to send request: %w", doErr)
+			}
+			if wErr := waitForAIRetry(ctx, aiRetryDelay(attempt-1)); wErr != nil {
+				return "", wErr
+			}
+			continue
+		}
+
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if attempt == aiRetryMaxAttempts || !(isRetryableHTTPStatus(resp.StatusCode) || isRetryableProviderErrorText(string(body))) {
+			return "", fmt.Errorf("OpenRouter API request failed with status %d: %s", resp.StatusCode, string(body))
+		}
+
+		delay := aiRetryDelay(attempt - 1)
+		if ra, ok := retryAfterDelay(resp.Header); ok {
+			delay = ra
+		}
+		if wErr := waitForAIRetry(ctx, delay); wErr != nil {
+			return "", wErr
+		}
+	}
+
+	var parsed OpenAIResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(parsed.Choices) == 0 || strings.TrimSpace(parsed.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("no response content from OpenRouter")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
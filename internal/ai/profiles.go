@@ -7,9 +7,23 @@ import (
 	"github.com/spf13/viper"
 )
 
-// getAIProfile returns the AI configuration for the given profile name
+// getAIProfile returns the AI configuration for the given profile name. When
+// the client has a model role, Model is replaced by that role's model.
 func (c *Client) getAIProfile(profileName string) (*awsclient.AIProfile, error) {
-	return awsclient.GetAIProfile(profileName)
+	profile, err := awsclient.GetAIProfile(profileName)
+	if err != nil || c.modelRole == "" {
+		return profile, err
+	}
+	profile.Model = profile.ModelFor(c.modelRole)
+	return profile, nil
+}
+
+// WithModelRole returns a copy of the client whose calls use the profile's
+// model for role (awsclient.ModelRoleDecision or ModelRoleAnalysis).
+func (c *Client) WithModelRole(role string) *Client {
+	clone := *c
+	clone.modelRole = role
+	return &clone
 }
 
 // getRegionForAWSProfile returns the region for the given AWS profile from configuration
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/bgdnvk/clanker/internal/agent"
 )
 
 const progressTracePrefix = "::clanker-progress "
@@ -46,3 +48,32 @@ func emitProgressTrace(phase, message string) {
 	}
 	fmt.Fprintln(os.Stderr, progressTracePrefix+string(payload))
 }
+
+// agentProgressBuffer is sized so a burst of agent starts never drops events
+// while the trace writer catches up.
+const agentProgressBuffer = 64
+
+// startAgentProgressTrace returns a channel for agent progress events and a
+// wait function that blocks until the channel has been closed and drained.
+// When tracing is disabled both are no-ops.
+func startAgentProgressTrace() (chan agent.AgentEvent, func()) {
+	if !progressTraceEnabled() {
+		return nil, func() {}
+	}
+	events := make(chan agent.AgentEvent, agentProgressBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range events {
+			message := event.Message
+			if event.AgentType != "" {
+				message = event.AgentType + ": " + message
+			}
+			if event.Error != "" {
+				message += " (" + event.Error + ")"
+			}
+			emitProgressTrace("agent."+string(event.Type), message)
+		}
+	}()
+	return events, func() { <-done }
+}
@@ -0,0 +1,190 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultOllamaBaseURL is where a local Ollama server listens by default.
+	DefaultOllamaBaseURL = "http://localhost:11434"
+	// DefaultOllamaModel is used when the ollama profile does not set a model.
+	DefaultOllamaModel = "llama3.1"
+	// ollamaRequestTimeout is generous because local models can be slow on CPU.
+	ollamaRequestTimeout = 10 * time.Minute
+)
+
+// AIProvider is a backend that answers a single prompt. The agent's decision
+// calls and the deploy deep-analysis calls only need this.
+type AIProvider interface {
+	Ask(ctx context.Context, prompt string) (string, error)
+}
+
+// AIProviderFunc adapts a plain function to AIProvider.
+type AIProviderFunc func(ctx context.Context, prompt string) (string, error)
+
+// Ask calls f(ctx, prompt).
+func (f AIProviderFunc) Ask(ctx context.Context, prompt string) (string, error) {
+	return f(ctx, prompt)
+}
+
+// Provider returns the AIProvider for the client's configured provider.
+func (c *Client) Provider() AIProvider {
+	switch c.provider {
+	case "bedrock", "claude":
+		return AIProviderFunc(c.askBedrock)
+	case "openai":
+		return AIProviderFunc(c.askOpenAI)
+	case "clanker-cloud":
+		return AIProviderFunc(c.askClankerCloud)
+	case "github-models":
+		return AIProviderFunc(c.askGitHubModels)
+	case "anthropic":
+		return AIProviderFunc(c.askAnthropic)
+	case "cohere":
+		return AIProviderFunc(c.askCohere)
+	case "minimax":
+		return AIProviderFunc(c.askMiniMax)
+	case "gemini", "gemini-api":
+		return AIProviderFunc(c.askGemini)
+	case "ollama":
+		return AIProviderFunc(c.askOllama)
+	case "openrouter":
+		return AIProviderFunc(c.askOpenRouter)
+	default:
+		return AIProviderFunc(c.askBedrock)
+	}
+}
+
+// askOllama calls the Ollama server of the client's profile; the profile's
+// base_url beats the provider-wide one resolved at construction.
+func (c *Client) askOllama(ctx context.Context, prompt string) (string, error) {
+	model := ""
+	baseURL := c.baseURL
+	if profile, err := c.getAIProfile(c.aiProfile); err == nil {
+		model = profile.Model
+		if strings.TrimSpace(profile.BaseURL) != "" {
+			baseURL = normalizeOllamaBaseURL(profile.BaseURL)
+		}
+	}
+	emitProgressTrace("provider", fmt.Sprintf("Calling Ollama at %s.", baseURL))
+	return NewOllamaProvider(baseURL, model).Ask(ctx, prompt)
+}
+
+// resolveOllamaBaseURL picks the Ollama server from config, OLLAMA_HOST, or
+// the default local address.
+func resolveOllamaBaseURL() string {
+	return normalizeOllamaBaseURL(firstNonEmptyString(
+		viper.GetString("ai.providers.ollama.base_url"),
+		os.Getenv("OLLAMA_HOST"),
+		DefaultOllamaBaseURL,
+	))
+}
+
+// normalizeOllamaBaseURL accepts OLLAMA_HOST-style host:port values.
+func normalizeOllamaBaseURL(raw string) string {
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		raw = "http://" + raw
+	}
+	return raw
+}
+
+// OllamaProvider calls a local or self-hosted Ollama server's generate API.
+type OllamaProvider struct {
+	BaseURL    string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// NewOllamaProvider returns an OllamaProvider, defaulting the base URL and model.
+func NewOllamaProvider(baseURL, model string) *OllamaProvider {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	if strings.TrimSpace(model) == "" {
+		model = DefaultOllamaModel
+	}
+	return &OllamaProvider{
+		BaseURL:    strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		Model:      strings.TrimSpace(model),
+		HTTPClient: &http.Client{Timeout: ollamaRequestTimeout},
+	}
+}
+
+type ollamaGenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Ask sends prompt to /api/generate without streaming.
+func (p *OllamaProvider) Ask(ctx context.Context, prompt string) (string, error) {
+	body, err := json.Marshal(ollamaGenerateRequest{Model: p.Model, Prompt: prompt})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ollama request to %s failed (is `ollama serve` running?): %w", p.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	var out ollamaGenerateResponse
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return "", fmt.Errorf("failed to parse ollama response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(out.Error)
+		if msg == "" {
+			msg = strings.TrimSpace(string(respBody))
+		}
+		return "", fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, msg)
+	}
+	return out.Response, nil
+}
+
+// flattenConversation renders a conversation as a single prompt for
+// providers that are called through a plain generate endpoint.
+func flattenConversation(conv *ConversationContext) string {
+	var b strings.Builder
+	if conv.SystemPrompt != "" {
+		b.WriteString(conv.SystemPrompt)
+		b.WriteString("\n\n")
+	}
+	for _, msg := range conv.GetMessages() {
+		if msg.Role == "assistant" {
+			b.WriteString("Assistant: ")
+		} else {
+			b.WriteString("User: ")
+		}
+		b.WriteString(msg.Content)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Assistant:")
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestOllamaProviderAsk(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req ollamaGenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Model != "qwen3" || req.Stream || req.Prompt != "ping" {
+			t.Errorf("unexpected request %+v", req)
+		}
+		_ = json.NewEncoder(w).Encode(ollamaGenerateResponse{Response: "pong"})
+	}))
+	defer srv.Close()
+
+	got, err := NewOllamaProvider(srv.URL+"/", "qwen3").Ask(context.Background(), "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "pong" {
+		t.Fatalf("Ask = %q, want pong", got)
+	}
+}
+
+func TestOllamaProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(ollamaGenerateResponse{Error: "model 'nope' not found"})
+	}))
+	defer srv.Close()
+
+	_, err := NewOllamaProvider(srv.URL, "nope").Ask(context.Background(), "ping")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected model-not-found error, got %v", err)
+	}
+}
+
+func TestNewOllamaProviderDefaults(t *testing.T) {
+	p := NewOllamaProvider("", "")
+	if p.BaseURL != DefaultOllamaBaseURL || p.Model != DefaultOllamaModel {
+		t.Fatalf("unexpected defaults %+v", p)
+	}
+}
+
+func TestFlattenConversation(t *testing.T) {
+	conv := NewConversationContext("be terse")
+	conv.AddUserMessage("hi")
+	conv.AddAssistantMessage("hello")
+	got := flattenConversation(conv)
+	want := "be terse\n\nUser: hi\n\nAssistant: hello\n\nAssistant:"
+	if got != want {
+		t.Fatalf("flattenConversation = %q, want %q", got, want)
+	}
+}
+
+func TestAskOpenRouterSendsAttributionHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer or-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if r.Header.Get("HTTP-Referer") == "" || r.Header.Get("X-Title") == "" {
+			t.Errorf("missing OpenRouter attribution headers: %v", r.Header)
+		}
+		var req OpenAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Model != DefaultOpenRouterModel || len(req.Messages) != 1 || req.Messages[0].Content != "ping" {
+			t.Errorf("unexpected request %+v", req)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"pong"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{provider: "openrouter", apiKey: "or-key", baseURL: srv.URL, aiProfile: "openrouter"}
+	got, err := c.Provider().Ask(context.Background(), "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "pong" {
+		t.Fatalf("Ask = %q, want pong", got)
+	}
+}
+
+func TestAskOllamaUsesProfileBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ollamaGenerateResponse{Response: "pong"})
+	}))
+	defer srv.Close()
+	viper.Set("ai.providers.gpu-box", map[string]any{"provider": "ollama", "model": "qwen3", "base_url": srv.URL})
+	defer viper.Set("ai.providers.gpu-box", nil)
+
+	c := &Client{provider: "ollama", aiProfile: "gpu-box", baseURL: "http://127.0.0.1:1"}
+	got, err := c.askOllama(context.Background(), "ping")
+	if err != nil || got != "pong" {
+		t.Fatalf("askOllama = %q, %v; want the profile's server to answer", got, err)
+	}
+}
@@ -0,0 +1,522 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// accountSummaryRecentWindow is how far back "recently changed" looks.
+	accountSummaryRecentWindow = 7 * 24 * time.Hour
+	// accountSummaryMaxRecent caps the recently changed list.
+	accountSummaryMaxRecent = 10
+	// accountSummaryMaxBucketChecks bounds the per-bucket policy status
+	// lookups used to find public buckets.
+	accountSummaryMaxBucketChecks = 50
+	// accountSummaryMaxListed caps the alarm and exposure lists.
+	accountSummaryMaxListed = 10
+)
+
+// serviceCount is one row of the resource table.
+type serviceCount struct {
+	Service string
+	Count   int
+	Detail  string // e.g. "9 running"
+}
+
+// recentChange is a resource created or modified within the recent window.
+type recentChange struct {
+	When time.Time
+	What string
+}
+
+// accountSummary is what BuildAccountSummary collected. Collectors run
+// concurrently and record through the add methods.
+type accountSummary struct {
+	mu          sync.Mutex
+	counts      []serviceCount
+	public      []string
+	recent      []recentChange
+	alarms      []string
+	monthToDate float64
+	monthly     float64
+	costMonth   string
+	costKnown   bool
+	unavailable []string
+}
+
+func (s *accountSummary) addCount(service string, count int, detail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts = append(s.counts, serviceCount{Service: service, Count: count, Detail: detail})
+}
+
+func (s *accountSummary) addPublic(finding string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.public = append(s.public, finding)
+}
+
+// addRecent records a change when it falls within the recent window of now.
+func (s *accountSummary) addRecent(now, when time.Time, what string) {
+	if when.IsZero() || now.Sub(when) > accountSummaryRecentWindow {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recent = append(s.recent, recentChange{When: when, What: what})
+}
+
+// summaryCollector gathers one part of the account summary.
+type summaryCollector struct {
+	Name    string // for "Not checked"
+	Service string // for categorizeAWSError
+	Run     func(ctx context.Context, c *Client, profile *AIProfile, now time.Time, s *accountSummary) error
+}
+
+var summaryCollectors = []summaryCollector{
+	{Name: "EC2 instances", Service: "EC2", Run: summarizeEC2},
+	{Name: "ECS clusters", Service: "ECS", Run: summarizeECS},
+	{Name: "Lambda functions", Service: "Lambda", Run: summarizeLambda},
+	{Name: "RDS instances", Service: "RDS", Run: summarizeRDS},
+	{Name: "S3 buckets", Service: "S3", Run: summarizeS3},
+	{Name: "DynamoDB tables", Service: "DynamoDB", Run: summarizeDynamoDB},
+	{Name: "Load balancers", Service: "ELBv2", Run: summarizeLoadBalancers},
+	{Name: "SQS queues", Service: "SQS", Run: summarizeSQS},
+	{Name: "SNS topics", Service: "SNS", Run: summarizeSNS},
+	{Name: "Security groups", Service: "EC2", Run: summarizeSecurityGroups},
+	{Name: "CloudWatch alarms", Service: "CloudWatch", Run: summarizeAlarms},
+	{Name: "Cost", Service: "Cost Explorer", Run: summarizeCost},
+}
+
+// BuildAccountSummary renders a one-page overview of the account for a
+// standup: resource counts per service, month-to-date and estimated monthly
+// cost, alarms firing, public-facing resources and what changed in the last
+// week. A nil profile uses the client's default infrastructure profile.
+// Parts that cannot be read are listed at the end rather than failing the
+// summary.
+func (c *Client) BuildAccountSummary(ctx context.Context, profile *AIProfile) (string, error) {
+	if profile == nil {
+		profile = c.aiProfile()
+	}
+	now := time.Now().UTC()
+	s := &accountSummary{}
+
+	var wg sync.WaitGroup
+	for _, collector := range summaryCollectors {
+		wg.Add(1)
+		go func(collector summaryCollector) {
+			defer wg.Done()
+			if err := collector.Run(ctx, c, profile, now, s); err != nil {
+				s.mu.Lock()
+				s.unavailable = append(s.unavailable, fmt.Sprintf("%s: %s", collector.Name, categorizeAWSError(err, collector.Service)))
+				s.mu.Unlock()
+			}
+		}(collector)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return formatAccountSummary(s, resolvedProfile(profile)), nil
+}
+
+func formatAccountSummary(s *accountSummary, profile *AIProfile) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("📋 Account summary (profile %s, region %s)\n", profile.AWSProfile, profile.Region))
+	out.WriteString("============================\n")
+
+	if s.costKnown {
+		out.WriteString(fmt.Sprintf("💰 Cost: $%.2f month to date, ~$%.2f estimated for %s\n", s.monthToDate, s.monthly, s.costMonth))
+	}
+	switch {
+	case len(s.alarms) > 0:
+		sort.Strings(s.alarms)
+		out.WriteString(fmt.Sprintf("🚨 %d alarms in ALARM: %s\n", len(s.alarms), joinCapped(s.alarms, accountSummaryMaxListed)))
+	case !summaryFailed(s, "CloudWatch alarms"):
+		out.WriteString("✅ No alarms in ALARM\n")
+	}
+
+	order := make(map[string]int, len(summaryCollectors))
+	for i, collector := range summaryCollectors {
+		order[collector.Name] = i
+	}
+	sort.SliceStable(s.counts, func(i, j int) bool { return order[s.counts[i].Service] < order[s.counts[j].Service] })
+	if len(s.counts) > 0 {
+		out.WriteString("\nResources:\n")
+		for _, row := range s.counts {
+			line := fmt.Sprintf("  %-18s %5d", row.Service, row.Count)
+			if row.Detail != "" {
+				line += "  (" + row.Detail + ")"
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+
+	out.WriteString("\n🌐 Public exposure:\n")
+	if len(s.public) == 0 {
+		out.WriteString("  ✅ No public S3 buckets, world-open security groups or public RDS instances found\n")
+	} else {
+		sort.Strings(s.public)
+		for i, finding := range s.public {
+			if i == accountSummaryMaxListed {
+				out.WriteString(fmt.Sprintf("  … %d more\n", len(s.public)-i))
+				break
+			}
+			out.WriteString("  ⚠️  " + finding + "\n")
+		}
+	}
+
+	out.WriteString(fmt.Sprintf("\n🕒 Changed in the last %s:\n", formatLookback(accountSummaryRecentWindow)))
+	if len(s.recent) == 0 {
+		out.WriteString("  Nothing created or modified\n")
+	} else {
+		sort.SliceStable(s.recent, func(i, j int) bool { return s.recent[i].When.After(s.recent[j].When) })
+		for i, change := range s.recent {
+			if i == accountSummaryMaxRecent {
+				out.WriteString(fmt.Sprintf("  … %d more\n", len(s.recent)-i))
+				break
+			}
+			out.WriteString(fmt.Sprintf("  • %s  %s\n", change.When.Format("Jan 02 15:04"), change.What))
+		}
+	}
+
+	if len(s.unavailable) > 0 {
+		sort.Strings(s.unavailable)
+		out.WriteString("\nNot checked:\n")
+		for _, u := range s.unavailable {
+			out.WriteString("  " + u + "\n")
+		}
+	}
+	return out.String()
+}
+
+func summaryFailed(s *accountSummary, name string) bool {
+	for _, u := range s.unavailable {
+		if strings.HasPrefix(u, name+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func joinCapped(items []string, max int) string {
+	if len(items) <= max {
+		return strings.Join(items, ", ")
+	}
+	return strings.Join(items[:max], ", ") + fmt.Sprintf(" and %d more", len(items)-max)
+}
+
+// parseAWSTime parses the timestamp formats AWS CLI output uses.
+func parseAWSTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000-0700", "2006-01-02T15:04:05-0700"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// summaryJSON runs a read-only CLI call and decodes its JSON output into v.
+func summaryJSON(ctx context.Context, c *Client, profile *AIProfile, args []string, v interface{}) error {
+	raw, err := c.execAWSCLI(ctx, append(args, "--output", "json"), profile)
+	if err != nil {
+		return err
+	}
+	if trimmed := strings.TrimSpace(raw); trimmed == "" || trimmed == "null" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return fmt.Errorf("failed to parse %s %s output: %w", args[0], args[1], err)
+	}
+	return nil
+}
+
+func summarizeEC2(ctx context.Context, c *Client, profile *AIProfile, now time.Time, s *accountSummary) error {
+	var instances []struct {
+		ID     string `json:"Id"`
+		Name   string `json:"Name"`
+		State  string `json:"State"`
+		Launch string `json:"Launch"`
+	}
+	err := summaryJSON(ctx, c, profile, []string{"ec2", "describe-instances",
+		"--filters", "Name=instance-state-name,Values=pending,running,stopping,stopped",
+		"--query", "Reservations[].Instances[].{Id:InstanceId,Name:Tags[?Key=='Name']|[0].Value,State:State.Name,Launch:LaunchTime}"}, &instances)
+	if err != nil {
+		return err
+	}
+	running := 0
+	for _, inst := range instances {
+		if inst.State == "running" {
+			running++
+		}
+		label := inst.ID
+		if inst.Name != "" {
+			label = inst.Name + " (" + inst.ID + ")"
+		}
+		s.addRecent(now, parseAWSTime(inst.Launch), "EC2 instance "+label+" launched")
+	}
+	s.addCount("EC2 instances", len(instances), fmt.Sprintf("%d running", running))
+	return nil
+}
+
+func summarizeECS(ctx context.Context, c *Client, profile *AIProfile, _ time.Time, s *accountSummary) error {
+	var clusters []string
+	if err := summaryJSON(ctx, c, profile, []string{"ecs", "list-clusters", "--query", "clusterArns"}, &clusters); err != nil {
+		return err
+	}
+	s.addCount("ECS clusters", len(clusters), "")
+	return nil
+}
+
+func summarizeLambda(ctx context.Context, c *Client, profile *AIProfile, now time.Time, s *accountSummary) error {
+	var functions []struct {
+		Name         string `json:"Name"`
+		LastModified string `json:"LastModified"`
+	}
+	err := summaryJSON(ctx, c, profile, []string{"lambda", "list-functions",
+		"--query", "Functions[].{Name:FunctionName,LastModified:LastModified}"}, &functions)
+	if err != nil {
+		return err
+	}
+	for _, fn := range functions {
+		s.addRecent(now, parseAWSTime(fn.LastModified), "Lambda "+fn.Name+" updated")
+	}
+	s.addCount("Lambda functions", len(functions), "")
+	return nil
+}
+
+func summarizeRDS(ctx context.Context, c *Client, profile *AIProfile, now time.Time, s *accountSummary) error {
+	var instances []struct {
+		ID      string `json:"Id"`
+		Engine  string `json:"Engine"`
+		Public  bool   `json:"Public"`
+		Created string `json:"Created"`
+	}
+	err := summaryJSON(ctx, c, profile, []string{"rds", "describe-db-instances",
+		"--query", "DBInstances[].{Id:DBInstanceIdentifier,Engine:Engine,Public:PubliclyAccessible,Created:InstanceCreateTime}"}, &instances)
+	if err != nil {
+		return err
+	}
+	public := 0
+	for _, db := range instances {
+		if db.Public {
+			public++
+			s.addPublic(fmt.Sprintf("RDS %s (%s) is publicly accessible", db.ID, db.Engine))
+		}
+		s.addRecent(now, parseAWSTime(db.Created), "RDS "+db.ID+" created")
+	}
+	detail := ""
+	if public > 0 {
+		detail = fmt.Sprintf("%d public", public)
+	}
+	s.addCount("RDS instances", len(instances), detail)
+	return nil
+}
+
+func summarizeS3(ctx context.Context, c *Client, profile *AIProfile, now time.Time, s *accountSummary) error {
+	var buckets []struct {
+		Name    string `json:"Name"`
+		Created string `json:"Created"`
+	}
+	if err := summaryJSON(ctx, c, profile, []string{"s3api", "list-buckets", "--query", "Buckets[].{Name:Name,Created:CreationDate}"}, &buckets); err != nil {
+		return err
+	}
+	for i, bucket := range buckets {
+		s.addRecent(now, parseAWSTime(bucket.Created), "S3 bucket "+bucket.Name+" created")
+		if i >= accountSummaryMaxBucketChecks {
+			continue
+		}
+		// Buckets without a policy return NoSuchBucketPolicy; only a policy
+		// granting public access is reported.
+		var status struct {
+			PolicyStatus struct {
+				IsPublic bool `json:"IsPublic"`
+			} `json:"PolicyStatus"`
+		}
+		if summaryJSON(ctx, c, profile, []string{"s3api", "get-bucket-policy-status", "--bucket", bucket.Name}, &status) == nil && status.PolicyStatus.IsPublic {
+			s.addPublic("S3 bucket " + bucket.Name + " has a public bucket policy")
+		}
+	}
+	detail := ""
+	if len(buckets) > accountSummaryMaxBucketChecks {
+		detail = fmt.Sprintf("public access checked on the first %d", accountSummaryMaxBucketChecks)
+	}
+	s.addCount("S3 buckets", len(buckets), detail)
+	return nil
+}
+
+func summarizeDynamoDB(ctx context.Context, c *Client, profile *AIProfile, _ time.Time, s *accountSummary) error {
+	var tables []string
+	if err := summaryJSON(ctx, c, profile, []string{"dynamodb", "list-tables", "--query", "TableNames"}, &tables); err != nil {
+		return err
+	}
+	s.addCount("DynamoDB tables", len(tables), "")
+	return nil
+}
+
+func summarizeLoadBalancers(ctx context.Context, c *Client, profile *AIProfile, now time.Time, s *accountSummary) error {
+	var lbs []struct {
+		Name    string `json:"Name"`
+		Scheme  string `json:"Scheme"`
+		Created string `json:"Created"`
+	}
+	err := summaryJSON(ctx, c, profile, []string{"elbv2", "describe-load-balancers",
+		"--query", "LoadBalancers[].{Name:LoadBalancerName,Scheme:Scheme,Created:CreatedTime}"}, &lbs)
+	if err != nil {
+		return err
+	}
+	internetFacing := 0
+	for _, lb := range lbs {
+		if lb.Scheme == "internet-facing" {
+			internetFacing++
+		}
+		s.addRecent(now, parseAWSTime(lb.Created), "Load balancer "+lb.Name+" created")
+	}
+	s.addCount("Load balancers", len(lbs), fmt.Sprintf("%d internet-facing", internetFacing))
+	return nil
+}
+
+func summarizeSQS(ctx context.Context, c *Client, profile *AIProfile, _ time.Time, s *accountSummary) error {
+	var queues []string
+	if err := summaryJSON(ctx, c, profile, []string{"sqs", "list-queues", "--query", "QueueUrls"}, &queues); err != nil {
+		return err
+	}
+	s.addCount("SQS queues", len(queues), "")
+	return nil
+}
+
+func summarizeSNS(ctx context.Context, c *Client, profile *AIProfile, _ time.Time, s *accountSummary) error {
+	var topics []string
+	if err := summaryJSON(ctx, c, profile, []string{"sns", "list-topics", "--query", "Topics[].TopicArn"}, &topics); err != nil {
+		return err
+	}
+	s.addCount("SNS topics", len(topics), "")
+	return nil
+}
+
+// summarizeSecurityGroups reports groups that open anything other than
+// HTTP and HTTPS to the whole internet.
+func summarizeSecurityGroups(ctx context.Context, c *Client, profile *AIProfile, _ time.Time, s *accountSummary) error {
+	var groups []struct {
+		ID    string         `json:"Id"`
+		Name  string         `json:"Name"`
+		Perms []sgPermission `json:"Perms"`
+	}
+	err := summaryJSON(ctx, c, profile, []string{"ec2", "describe-security-groups",
+		"--query", "SecurityGroups[].{Id:GroupId,Name:GroupName,Perms:IpPermissions}"}, &groups)
+	if err != nil {
+		return err
+	}
+	open := 0
+	for _, g := range groups {
+		if rules := worldOpenRules(g.Perms); len(rules) > 0 {
+			open++
+			s.addPublic(fmt.Sprintf("Security group %s (%s) allows %s from anywhere", g.ID, g.Name, strings.Join(rules, ", ")))
+		}
+	}
+	detail := ""
+	if open > 0 {
+		detail = fmt.Sprintf("%d open to the internet", open)
+	}
+	s.addCount("Security groups", len(groups), detail)
+	return nil
+}
+
+// worldOpenRules lists the protocol/port ranges perms open to 0.0.0.0/0 or
+// ::/0, leaving out plain HTTP and HTTPS.
+func worldOpenRules(perms []sgPermission) []string {
+	var rules []string
+	for _, p := range perms {
+		world := false
+		for _, r := range p.IpRanges {
+			world = world || r.CidrIp == "0.0.0.0/0"
+		}
+		for _, r := range p.Ipv6Ranges {
+			world = world || r.CidrIpv6 == "::/0"
+		}
+		if !world {
+			continue
+		}
+		if p.IpProtocol == "-1" {
+			rules = append(rules, "all traffic")
+			continue
+		}
+		if p.FromPort != nil && p.ToPort != nil && *p.FromPort == *p.ToPort && (*p.FromPort == 80 || *p.FromPort == 443) {
+			continue
+		}
+		rule := p.IpProtocol
+		if p.FromPort != nil && p.ToPort != nil {
+			rule += "/" + portSpan(*p.FromPort, *p.ToPort)
+		}
+		rules = append(rules, rule)
+	}
+	return uniqueStrings(rules)
+}
+
+func summarizeAlarms(ctx context.Context, c *Client, profile *AIProfile, _ time.Time, s *accountSummary) error {
+	var alarms []string
+	err := summaryJSON(ctx, c, profile, []string{"cloudwatch", "describe-alarms", "--state-value", "ALARM",
+		"--query", "[MetricAlarms[].AlarmName, CompositeAlarms[].AlarmName][]"}, &alarms)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.alarms = alarms
+	s.mu.Unlock()
+	return nil
+}
+
+// summarizeCost reads month-to-date spend and extrapolates it linearly over
+// the month. On the first of the month Cost Explorer reports last month,
+// which is used as is.
+func summarizeCost(ctx context.Context, c *Client, profile *AIProfile, now time.Time, s *accountSummary) error {
+	var resp struct {
+		ResultsByTime []struct {
+			TimePeriod struct {
+				Start string `json:"Start"`
+				End   string `json:"End"`
+			} `json:"TimePeriod"`
+			Total map[string]struct {
+				Amount string `json:"Amount"`
+			} `json:"Total"`
+		} `json:"ResultsByTime"`
+	}
+	err := summaryJSON(ctx, c, profile, []string{"ce", "get-cost-and-usage",
+		"--time-period", currentMonthPeriod(now),
+		"--granularity", "MONTHLY",
+		"--metrics", "UnblendedCost"}, &resp)
+	if err != nil {
+		return err
+	}
+	if len(resp.ResultsByTime) == 0 {
+		return fmt.Errorf("no cost data returned")
+	}
+	period := resp.ResultsByTime[0]
+	var amount float64
+	fmt.Sscanf(period.Total["UnblendedCost"].Amount, "%f", &amount)
+	start, errStart := time.Parse("2006-01-02", period.TimePeriod.Start)
+	end, errEnd := time.Parse("2006-01-02", period.TimePeriod.End)
+
+	monthly, month := amount, now.Format("January")
+	if errStart == nil {
+		month = start.Format("January")
+	}
+	if errStart == nil && errEnd == nil && start.Month() == end.Month() {
+		elapsed := end.Sub(start).Hours() / 24
+		daysInMonth := float64(time.Date(start.Year(), start.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day())
+		if elapsed > 0 {
+			monthly = amount / elapsed * daysInMonth
+		}
+	}
+	s.mu.Lock()
+	s.monthToDate, s.monthly, s.costMonth, s.costKnown = amount, monthly, month, true
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildAccountSummary(t *testing.T) {
+	now := time.Now().UTC()
+	recent := now.Add(-2 * time.Hour).Format(time.RFC3339)
+	old := now.AddDate(0, -3, 0).Format(time.RFC3339)
+
+	f := newFakeCLI()
+	f.fixtures["ec2 describe-instances"] = `[{"Id":"i-1","Name":"web","State":"running","Launch":"` + recent + `"},{"Id":"i-2","State":"stopped","Launch":"` + old + `"}]`
+	f.fixtures["ecs list-clusters"] = `["arn:aws:ecs:us-east-1:1:cluster/main"]`
+	f.fixtures["lambda list-functions"] = `[{"Name":"checkout","LastModified":"` + now.Add(-time.Hour).Format("2006-01-02T15:04:05.000-0700") + `"}]`
+	f.fixtures["rds describe-db-instances"] = `[{"Id":"orders","Engine":"postgres","Public":true,"Created":"` + old + `"}]`
+	f.fixtures["s3api list-buckets"] = `[{"Name":"assets","Created":"` + old + `"},{"Name":"private","Created":"` + old + `"}]`
+	f.fixtures["s3api get-bucket-policy-status --bucket assets"] = `{"PolicyStatus":{"IsPublic":true}}`
+	f.failures["s3api get-bucket-policy-status --bucket private"] = errors.New("NoSuchBucketPolicy")
+	f.fixtures["dynamodb list-tables"] = `["sessions"]`
+	f.fixtures["elbv2 describe-load-balancers"] = `[]`
+	f.fixtures["sqs list-queues"] = ``
+	f.fixtures["sns list-topics"] = `["arn:aws:sns:us-east-1:1:alerts"]`
+	f.fixtures["ec2 describe-security-groups"] = `[
+		{"Id":"sg-web","Name":"web","Perms":[{"IpProtocol":"tcp","FromPort":443,"ToPort":443,"IpRanges":[{"CidrIp":"0.0.0.0/0"}]}]},
+		{"Id":"sg-ssh","Name":"bastion","Perms":[{"IpProtocol":"tcp","FromPort":22,"ToPort":22,"Ipv6Ranges":[{"CidrIpv6":"::/0"}]}]}]`
+	f.fixtures["cloudwatch describe-alarms"] = `["checkout-5xx","orders-cpu"]`
+	f.failures["ce get-cost-and-usage"] = errors.New("AccessDeniedException: not authorized to perform ce:GetCostAndUsage")
+
+	out, err := newFakeClient(f).BuildAccountSummary(context.Background(), &AIProfile{AWSProfile: "prod", Region: "us-east-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Account summary (profile prod, region us-east-1)",
+		"🚨 2 alarms in ALARM: checkout-5xx, orders-cpu",
+		"EC2 instances          2  (1 running)",
+		"SQS queues             0",
+		"RDS orders (postgres) is publicly accessible",
+		"S3 bucket assets has a public bucket policy",
+		"Security group sg-ssh (bastion) allows tcp/22 from anywhere",
+		"EC2 instance web (i-1) launched",
+		"Lambda checkout updated",
+		"Not checked:\n  Cost:",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"sg-web", "private has", "i-2", "RDS orders created"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("summary should not mention %q:\n%s", unwanted, out)
+		}
+	}
+	// Ordered like summaryCollectors, regardless of completion order.
+	if strings.Index(out, "EC2 instances") > strings.Index(out, "SNS topics") {
+		t.Errorf("resource rows out of order:\n%s", out)
+	}
+}
+
+func TestSummarizeCostExtrapolatesMonth(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["ce get-cost-and-usage"] = `{"ResultsByTime":[{"TimePeriod":{"Start":"2026-04-01","End":"2026-04-11"},"Total":{"UnblendedCost":{"Amount":"100.0","Unit":"USD"}}}]}`
+	s := &accountSummary{}
+	if err := summarizeCost(context.Background(), newFakeClient(f), &AIProfile{}, time.Date(2026, 4, 11, 9, 0, 0, 0, time.UTC), s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.costKnown || s.monthToDate != 100 || s.monthly != 300 || s.costMonth != "April" {
+		t.Errorf("cost = %+v, want $100 to date and $300 for April", s)
+	}
+}
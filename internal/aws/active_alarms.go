@@ -0,0 +1,322 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// activeAlarmHistoryWindow is how far back analyze_active_alarms reads
+	// alarm history unless the investigation window says otherwise.
+	activeAlarmHistoryWindow = 24 * time.Hour
+	// activeAlarmFlapTransitions is how many trips into ALARM within the
+	// window count as flapping.
+	activeAlarmFlapTransitions = 3
+	// activeAlarmMaxDetailed bounds the per-alarm history and metric calls.
+	activeAlarmMaxDetailed = 10
+)
+
+// cloudWatchAlarm is a metric or composite alarm from describe-alarms.
+type cloudWatchAlarm struct {
+	Name               string            `json:"AlarmName"`
+	State              string            `json:"StateValue"`
+	StateReason        string            `json:"StateReason"`
+	StateReasonData    string            `json:"StateReasonData"`
+	StateUpdated       time.Time         `json:"StateUpdatedTimestamp"`
+	Namespace          string            `json:"Namespace"`
+	MetricName         string            `json:"MetricName"`
+	Dimensions         []metricDimension `json:"Dimensions"`
+	Statistic          string            `json:"Statistic"`
+	ExtendedStatistic  string            `json:"ExtendedStatistic"`
+	Period             int               `json:"Period"`
+	EvaluationPeriods  int               `json:"EvaluationPeriods"`
+	Threshold          *float64          `json:"Threshold"`
+	ComparisonOperator string            `json:"ComparisonOperator"`
+	TreatMissingData   string            `json:"TreatMissingData"`
+	ActionsEnabled     bool              `json:"ActionsEnabled"`
+	Metrics            []json.RawMessage `json:"Metrics"`   // metric math alarms
+	AlarmRule          string            `json:"AlarmRule"` // composite alarms
+}
+
+func (a cloudWatchAlarm) metric() string {
+	switch {
+	case a.AlarmRule != "":
+		return "composite: " + a.AlarmRule
+	case a.MetricName == "" && len(a.Metrics) > 0:
+		return "metric math"
+	}
+	label := strings.TrimSpace(a.Namespace + " " + a.MetricName)
+	if len(a.Dimensions) > 0 {
+		dims := make([]string, 0, len(a.Dimensions))
+		for _, d := range a.Dimensions {
+			dims = append(dims, d.Name+"="+d.Value)
+		}
+		label += ", " + strings.Join(dims, ", ")
+	}
+	return label
+}
+
+func (a cloudWatchAlarm) stat() string {
+	return valueOr(a.ExtendedStatistic, a.Statistic)
+}
+
+// breaches reports whether value crosses the alarm's threshold.
+func (a cloudWatchAlarm) breaches(value float64) bool {
+	if a.Threshold == nil {
+		return false
+	}
+	switch a.ComparisonOperator {
+	case "GreaterThanOrEqualToThreshold":
+		return value >= *a.Threshold
+	case "GreaterThanThreshold":
+		return value > *a.Threshold
+	case "LessThanThreshold":
+		return value < *a.Threshold
+	case "LessThanOrEqualToThreshold":
+		return value <= *a.Threshold
+	}
+	return false
+}
+
+var alarmComparisonSymbols = map[string]string{
+	"GreaterThanOrEqualToThreshold": ">=",
+	"GreaterThanThreshold":          ">",
+	"LessThanThreshold":             "<",
+	"LessThanOrEqualToThreshold":    "<=",
+}
+
+// activeAlarm is an alarm in ALARM with its recent history and the metric's
+// current value.
+type activeAlarm struct {
+	cloudWatchAlarm
+	Trips   int // transitions into ALARM within the window
+	Current *float64
+}
+
+// insufficientAlarm is an alarm in INSUFFICIENT_DATA and whether its metric
+// exists with the alarm's dimensions at all.
+type insufficientAlarm struct {
+	cloudWatchAlarm
+	MetricMissing bool
+}
+
+// analyzeActiveAlarms is the analyze_active_alarms operation: it lists the
+// alarms in ALARM with when they tripped, how often they tripped over the
+// investigation window (the last day by default), and the metric's current
+// value against the threshold. Alarms in INSUFFICIENT_DATA are listed apart
+// as likely misconfigured, noting when their metric has no data with the
+// alarm's dimensions.
+func (c *Client) analyzeActiveAlarms(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	prefix := getStringParam(input, "alarm_name_prefix", "")
+	firing, err := c.describeAlarmsInState(ctx, "ALARM", prefix, profile)
+	if err != nil {
+		return categorizeAWSError(err, "CloudWatch"), nil
+	}
+	insufficient, err := c.describeAlarmsInState(ctx, "INSUFFICIENT_DATA", prefix, profile)
+	if err != nil {
+		return categorizeAWSError(err, "CloudWatch"), nil
+	}
+
+	start, end := operationWindow(ctx, input, activeAlarmHistoryWindow)
+	var active []activeAlarm
+	for i, alarm := range firing {
+		a := activeAlarm{cloudWatchAlarm: alarm}
+		if i < activeAlarmMaxDetailed {
+			a.Trips = c.alarmTrips(ctx, alarm.Name, start, end, profile)
+			a.Current = c.alarmCurrentValue(ctx, alarm, end, profile)
+		}
+		active = append(active, a)
+	}
+	var missing []insufficientAlarm
+	for i, alarm := range insufficient {
+		a := insufficientAlarm{cloudWatchAlarm: alarm}
+		if i < activeAlarmMaxDetailed && alarm.MetricName != "" {
+			a.MetricMissing = !c.alarmMetricExists(ctx, alarm, profile)
+		}
+		missing = append(missing, a)
+	}
+	return formatActiveAlarms(active, missing, windowPhrase(start, end), end), nil
+}
+
+// describeAlarmsInState lists metric and composite alarms in one state,
+// longest in that state first.
+func (c *Client) describeAlarmsInState(ctx context.Context, state, prefix string, profile *AIProfile) ([]cloudWatchAlarm, error) {
+	args := []string{"cloudwatch", "describe-alarms", "--state-value", state, "--alarm-types", "MetricAlarm", "CompositeAlarm"}
+	if prefix != "" {
+		args = append(args, "--alarm-name-prefix", prefix)
+	}
+	raw, err := c.execAWSCLI(ctx, append(args, "--output", "json"), profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		MetricAlarms    []cloudWatchAlarm `json:"MetricAlarms"`
+		CompositeAlarms []cloudWatchAlarm `json:"CompositeAlarms"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse alarms: %w", err)
+	}
+	alarms := append(resp.MetricAlarms, resp.CompositeAlarms...)
+	sort.SliceStable(alarms, func(i, j int) bool { return alarms[i].StateUpdated.Before(alarms[j].StateUpdated) })
+	return alarms, nil
+}
+
+// alarmTrips counts the alarm's transitions into ALARM within the window.
+func (c *Client) alarmTrips(ctx context.Context, name string, start, end time.Time, profile *AIProfile) int {
+	raw, err := c.execAWSCLI(ctx, []string{"cloudwatch", "describe-alarm-history",
+		"--alarm-name", name,
+		"--history-item-type", "StateUpdate",
+		"--start-date", start.Format(time.RFC3339),
+		"--end-date", end.Format(time.RFC3339),
+		"--max-records", "100",
+		"--output", "json"}, profile)
+	if err != nil {
+		return 0
+	}
+	var resp struct {
+		Items []struct {
+			Summary string `json:"HistorySummary"`
+		} `json:"AlarmHistoryItems"`
+	}
+	if json.Unmarshal([]byte(raw), &resp) != nil {
+		return 0
+	}
+	trips := 0
+	for _, item := range resp.Items {
+		if strings.HasSuffix(item.Summary, " to ALARM") {
+			trips++
+		}
+	}
+	return trips
+}
+
+// alarmCurrentValue returns the latest datapoint of the alarm's metric over
+// its evaluation range, falling back to the datapoints CloudWatch recorded
+// when the state last changed.
+func (c *Client) alarmCurrentValue(ctx context.Context, alarm cloudWatchAlarm, end time.Time, profile *AIProfile) *float64 {
+	if alarm.MetricName != "" && alarm.Period > 0 {
+		evaluations := max(alarm.EvaluationPeriods, 1)
+		req := metricStatisticsRequest{
+			Namespace:  alarm.Namespace,
+			MetricName: alarm.MetricName,
+			Dimensions: alarm.Dimensions,
+			Period:     alarm.Period,
+			Stat:       valueOr(alarm.Statistic, "Average"),
+			Window:     max(time.Duration(alarm.Period*evaluations*2)*time.Second, 15*time.Minute),
+		}
+		if alarm.ExtendedStatistic != "" {
+			req.Stat, req.Extended = alarm.ExtendedStatistic, true
+		}
+		if raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile); err == nil {
+			if points, _ := decodeMetricDatapoints(raw, req); len(points) > 0 {
+				return &points[len(points)-1].Value
+			}
+		}
+	}
+	var reason struct {
+		RecentDatapoints []float64 `json:"recentDatapoints"`
+	}
+	if json.Unmarshal([]byte(alarm.StateReasonData), &reason) == nil && len(reason.RecentDatapoints) > 0 {
+		return &reason.RecentDatapoints[len(reason.RecentDatapoints)-1]
+	}
+	return nil
+}
+
+// alarmMetricExists reports whether CloudWatch has the alarm's metric with
+// exactly its dimensions. It errs on true when the lookup fails.
+func (c *Client) alarmMetricExists(ctx context.Context, alarm cloudWatchAlarm, profile *AIProfile) bool {
+	args := []string{"cloudwatch", "list-metrics", "--namespace", alarm.Namespace, "--metric-name", alarm.MetricName}
+	if len(alarm.Dimensions) > 0 {
+		args = append(args, "--dimensions")
+		for _, d := range alarm.Dimensions {
+			args = append(args, fmt.Sprintf("Name=%s,Value=%s", d.Name, d.Value))
+		}
+	}
+	raw, err := c.execAWSCLI(ctx, append(args, "--output", "json"), profile)
+	if err != nil {
+		return true
+	}
+	var resp struct {
+		Metrics []json.RawMessage `json:"Metrics"`
+	}
+	if json.Unmarshal([]byte(raw), &resp) != nil {
+		return true
+	}
+	return len(resp.Metrics) > 0
+}
+
+func formatActiveAlarms(active []activeAlarm, insufficient []insufficientAlarm, window string, now time.Time) string {
+	var out strings.Builder
+	out.WriteString("🔔 ACTIVE CLOUDWATCH ALARMS\n")
+	out.WriteString("============================\n")
+	if len(active) == 0 && len(insufficient) == 0 {
+		out.WriteString("✅ No alarms in ALARM or INSUFFICIENT_DATA\n")
+		return out.String()
+	}
+
+	if len(active) == 0 {
+		out.WriteString("✅ No alarms in ALARM\n")
+	} else {
+		out.WriteString(fmt.Sprintf("🚨 In ALARM (%d):\n", len(active)))
+	}
+	for i, a := range active {
+		if i == activeAlarmMaxDetailed {
+			names := make([]string, 0, len(active)-i)
+			for _, rest := range active[i:] {
+				names = append(names, rest.Name)
+			}
+			out.WriteString(fmt.Sprintf("\n... and %d more: %s\n", len(names), strings.Join(limitStrings(names, 20), ", ")))
+			break
+		}
+		out.WriteString(fmt.Sprintf("\n- %s (%s)\n", a.Name, a.metric()))
+		if !a.StateUpdated.IsZero() {
+			out.WriteString(fmt.Sprintf("  In ALARM since %s (%s)\n", a.StateUpdated.UTC().Format(time.RFC3339), formatLookback(now.Sub(a.StateUpdated))))
+		}
+		if a.Threshold != nil && a.MetricName != "" {
+			line := fmt.Sprintf("  Threshold: %s %s %g over %d x %ds", a.stat(), alarmComparisonSymbols[a.ComparisonOperator], *a.Threshold, max(a.EvaluationPeriods, 1), a.Period)
+			if a.Current != nil {
+				state := "still breaching"
+				if !a.breaches(*a.Current) {
+					state = "back within the threshold, the alarm should clear"
+				}
+				line += fmt.Sprintf("; current %g (%s)", *a.Current, state)
+			}
+			out.WriteString(line + "\n")
+		}
+		switch {
+		case a.Trips >= activeAlarmFlapTransitions:
+			out.WriteString(fmt.Sprintf("  ⚠️  Tripped %d times %s: flapping; the threshold may sit inside normal variation or the issue comes and goes\n", a.Trips, window))
+		case a.Trips > 0:
+			out.WriteString(fmt.Sprintf("  Tripped %d time(s) %s\n", a.Trips, window))
+		}
+		if !a.ActionsEnabled {
+			out.WriteString("  Actions are disabled: nobody is being notified\n")
+		}
+		if a.StateReason != "" {
+			out.WriteString(fmt.Sprintf("  Reason: %s\n", a.StateReason))
+		}
+	}
+
+	if len(insufficient) > 0 {
+		out.WriteString(fmt.Sprintf("\n⚠️  In INSUFFICIENT_DATA, possibly misconfigured (%d):\n", len(insufficient)))
+		for i, a := range insufficient {
+			if i == activeAlarmMaxDetailed {
+				out.WriteString(fmt.Sprintf("  ... and %d more\n", len(insufficient)-i))
+				break
+			}
+			note := "no datapoints in the evaluation range"
+			switch {
+			case a.MetricMissing:
+				note = "no such metric with these dimensions: the resource may be gone or a dimension value is wrong"
+			case a.TreatMissingData == "" || a.TreatMissingData == "missing":
+				note += "; if the metric is sparse, set TreatMissingData to notBreaching"
+			}
+			out.WriteString(fmt.Sprintf("  - %s (%s): %s\n", a.Name, a.metric(), note))
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeActiveAlarmsFlappingAndMisconfigured(t *testing.T) {
+	tripped := time.Now().UTC().Add(-42 * time.Minute).Format(time.RFC3339)
+	f := newFakeCLI()
+	f.fixtures["cloudwatch describe-alarms --state-value ALARM"] = `{"MetricAlarms": [{
+		"AlarmName": "api-5xx-high", "StateValue": "ALARM", "StateUpdatedTimestamp": "` + tripped + `",
+		"StateReason": "Threshold Crossed: 1 datapoint [37.0] was greater than the threshold (10.0).",
+		"StateReasonData": "{\"recentDatapoints\":[37.0],\"threshold\":10.0}",
+		"Namespace": "AWS/ApplicationELB", "MetricName": "HTTPCode_Target_5XX_Count",
+		"Dimensions": [{"Name": "LoadBalancer", "Value": "app/web/123"}],
+		"Statistic": "Sum", "Period": 60, "EvaluationPeriods": 1, "Threshold": 10,
+		"ComparisonOperator": "GreaterThanThreshold", "ActionsEnabled": true}]}`
+	f.fixtures["cloudwatch describe-alarms --state-value INSUFFICIENT_DATA"] = `{"MetricAlarms": [
+		{"AlarmName": "old-worker-cpu", "StateValue": "INSUFFICIENT_DATA", "Namespace": "AWS/EC2", "MetricName": "CPUUtilization",
+		 "Dimensions": [{"Name": "InstanceId", "Value": "i-gone"}], "Statistic": "Average", "Period": 300, "Threshold": 80,
+		 "ComparisonOperator": "GreaterThanThreshold", "ActionsEnabled": true},
+		{"AlarmName": "queue-depth", "StateValue": "INSUFFICIENT_DATA", "Namespace": "AWS/SQS", "MetricName": "ApproximateNumberOfMessagesVisible",
+		 "Dimensions": [{"Name": "QueueName", "Value": "jobs"}], "Statistic": "Maximum", "Period": 300, "Threshold": 100,
+		 "ComparisonOperator": "GreaterThanThreshold", "ActionsEnabled": true}]}`
+	f.fixtures["cloudwatch describe-alarm-history --alarm-name api-5xx-high"] = `{"AlarmHistoryItems": [
+		{"HistorySummary": "Alarm updated from OK to ALARM"},
+		{"HistorySummary": "Alarm updated from ALARM to OK"},
+		{"HistorySummary": "Alarm updated from OK to ALARM"},
+		{"HistorySummary": "Alarm updated from ALARM to OK"},
+		{"HistorySummary": "Alarm updated from INSUFFICIENT_DATA to ALARM"}]}`
+	f.fixtures["cloudwatch get-metric-statistics"] = `{"Datapoints": [{"Timestamp": "2026-10-16T10:00:00Z", "Sum": 4}, {"Timestamp": "2026-10-16T10:01:00Z", "Sum": 52}]}`
+	f.fixtures["cloudwatch list-metrics --namespace AWS/EC2"] = `{"Metrics": []}`
+	f.fixtures["cloudwatch list-metrics --namespace AWS/SQS"] = `{"Metrics": [{"MetricName": "ApproximateNumberOfMessagesVisible"}]}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_active_alarms", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"🚨 In ALARM (1)",
+		"- api-5xx-high (AWS/ApplicationELB HTTPCode_Target_5XX_Count, LoadBalancer=app/web/123)",
+		"(42m)",
+		"Threshold: Sum > 10 over 1 x 60s; current 52 (still breaching)",
+		"Tripped 3 times in the last 1d: flapping",
+		"In INSUFFICIENT_DATA, possibly misconfigured (2)",
+		"old-worker-cpu (AWS/EC2 CPUUtilization, InstanceId=i-gone): no such metric with these dimensions",
+		"queue-depth (AWS/SQS ApproximateNumberOfMessagesVisible, QueueName=jobs): no datapoints in the evaluation range; if the metric is sparse",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestAnalyzeActiveAlarmsNoneFiring(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["cloudwatch describe-alarms"] = `{"MetricAlarms": [], "CompositeAlarms": []}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_active_alarms", map[string]interface{}{"alarm_name_prefix": "prod-"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "No alarms in ALARM or INSUFFICIENT_DATA") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, "cloudwatch describe-alarms") && !strings.Contains(call, "--alarm-name-prefix prod-") {
+			t.Errorf("prefix not passed: %s", call)
+		}
+	}
+}
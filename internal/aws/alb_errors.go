@@ -0,0 +1,336 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// albErrorWindow is how far back analyze_alb_errors looks.
+	albErrorWindow = time.Hour
+	albErrorPeriod = 60
+	// albMaxTargetGroups bounds the describe-target-health fan-out.
+	albMaxTargetGroups = 10
+)
+
+type albLoadBalancer struct {
+	Arn  string `json:"LoadBalancerArn"`
+	Name string `json:"LoadBalancerName"`
+	Type string `json:"Type"`
+}
+
+type albTargetGroup struct {
+	Arn  string `json:"TargetGroupArn"`
+	Name string `json:"TargetGroupName"`
+}
+
+// albTargetHealth is one registered target and its health check state.
+type albTargetHealth struct {
+	ID          string
+	Port        int
+	State       string
+	Reason      string
+	Description string
+}
+
+type albTargetHealthResponse struct {
+	TargetHealthDescriptions []struct {
+		Target struct {
+			ID   string `json:"Id"`
+			Port int    `json:"Port"`
+		} `json:"Target"`
+		TargetHealth struct {
+			State       string `json:"State"`
+			Reason      string `json:"Reason"`
+			Description string `json:"Description"`
+		} `json:"TargetHealth"`
+	} `json:"TargetHealthDescriptions"`
+}
+
+// albTargetGroupHealth is the health snapshot and unhealthy-host series of
+// one target group.
+type albTargetGroupHealth struct {
+	Group     albTargetGroup
+	Targets   []albTargetHealth
+	Unhealthy []metricDatapoint
+	Err       string
+}
+
+// albCorrelation compares the minutes with ELB 5xx against the minutes where
+// any target group reported unhealthy hosts.
+type albCorrelation struct {
+	ErrorMinutes   int
+	Overlap        int
+	Total5xx       float64
+	Peak5xx        float64
+	PeakAt         time.Time
+	UnhealthySeen  bool
+	UnhealthyPeak  float64
+	UnhealthyFirst time.Time
+	// Window phrases the analyzed window, e.g. "in the last 1h".
+	Window string
+}
+
+// analyzeALBErrors is the analyze_alb_errors operation: it correlates ELB 5xx
+// over the investigation window (the last hour by default) with target health
+// for one application load balancer.
+func (c *Client) analyzeALBErrors(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	ref := ""
+	for _, key := range []string{"load_balancer", "load_balancer_arn", "load_balancer_name", "name"} {
+		if v, ok := input[key].(string); ok && strings.TrimSpace(v) != "" {
+			ref = strings.TrimSpace(v)
+			break
+		}
+	}
+
+	lb, msg, err := c.resolveALB(ctx, ref, profile)
+	if err != nil {
+		return categorizeAWSError(err, "ELBv2"), nil
+	}
+	if msg != "" {
+		return msg, nil
+	}
+
+	raw, err := c.execAWSCLI(ctx, []string{"elbv2", "describe-target-groups", "--load-balancer-arn", lb.Arn, "--output", "json"}, profile)
+	if err != nil {
+		return categorizeAWSError(err, "ELBv2"), nil
+	}
+	var tgResp struct {
+		TargetGroups []albTargetGroup `json:"TargetGroups"`
+	}
+	if err := json.Unmarshal([]byte(raw), &tgResp); err != nil {
+		return "", fmt.Errorf("failed to parse target groups: %w", err)
+	}
+
+	start, end := operationWindow(ctx, input, albErrorWindow)
+	window := end.Sub(start)
+	period := metricPeriodForWindow(albErrorPeriod, window)
+	lbDim := albMetricDimension(lb.Arn)
+	errReq := metricStatisticsRequest{
+		Namespace:  "AWS/ApplicationELB",
+		MetricName: "HTTPCode_ELB_5XX_Count",
+		Dimensions: []metricDimension{{Name: "LoadBalancer", Value: lbDim}},
+		Period:     period,
+		Stat:       "Sum",
+		Window:     window,
+	}
+	var errPoints []metricDatapoint
+	if raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(errReq, end), profile); err == nil {
+		errPoints, _ = decodeMetricDatapoints(raw, errReq)
+	}
+
+	var groups []albTargetGroupHealth
+	for _, tg := range tgResp.TargetGroups {
+		if len(groups) == albMaxTargetGroups {
+			break
+		}
+		health := albTargetGroupHealth{Group: tg}
+		raw, err := c.execAWSCLI(ctx, []string{"elbv2", "describe-target-health", "--target-group-arn", tg.Arn, "--output", "json"}, profile)
+		if err != nil {
+			health.Err = err.Error()
+		} else if health.Targets, err = parseTargetHealth(raw); err != nil {
+			health.Err = err.Error()
+		}
+
+		unhealthyReq := metricStatisticsRequest{
+			Namespace:  "AWS/ApplicationELB",
+			MetricName: "UnHealthyHostCount",
+			Dimensions: []metricDimension{
+				{Name: "TargetGroup", Value: albMetricDimension(tg.Arn)},
+				{Name: "LoadBalancer", Value: lbDim},
+			},
+			Period: period,
+			Stat:   "Maximum",
+			Window: window,
+		}
+		if raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(unhealthyReq, end), profile); err == nil {
+			health.Unhealthy, _ = decodeMetricDatapoints(raw, unhealthyReq)
+		}
+		groups = append(groups, health)
+	}
+
+	var unhealthySeries [][]metricDatapoint
+	for _, g := range groups {
+		unhealthySeries = append(unhealthySeries, g.Unhealthy)
+	}
+	corr := correlateALB5xx(errPoints, unhealthySeries)
+	corr.Window = windowPhrase(start, end)
+	return formatALBErrorAnalysis(lb, groups, corr, len(tgResp.TargetGroups)), nil
+}
+
+// resolveALB finds the load balancer named by ref (an ARN or a name). With no
+// ref it picks the account's only ALB, or returns a message listing them.
+func (c *Client) resolveALB(ctx context.Context, ref string, profile *AIProfile) (albLoadBalancer, string, error) {
+	args := []string{"elbv2", "describe-load-balancers", "--output", "json"}
+	switch {
+	case strings.HasPrefix(ref, "arn:"):
+		args = append(args, "--load-balancer-arns", ref)
+	case ref != "":
+		args = append(args, "--names", ref)
+	}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return albLoadBalancer{}, "", err
+	}
+	var resp struct {
+		LoadBalancers []albLoadBalancer `json:"LoadBalancers"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return albLoadBalancer{}, "", fmt.Errorf("failed to parse load balancers: %w", err)
+	}
+
+	var albs []albLoadBalancer
+	for _, lb := range resp.LoadBalancers {
+		if lb.Type == "" || lb.Type == "application" {
+			albs = append(albs, lb)
+		}
+	}
+	switch {
+	case len(albs) == 1:
+		return albs[0], "", nil
+	case len(albs) == 0 && ref != "":
+		return albLoadBalancer{}, fmt.Sprintf("Load balancer %s is not an application load balancer; analyze_alb_errors only covers ALBs.\n", ref), nil
+	case len(albs) == 0:
+		return albLoadBalancer{}, "No application load balancers found.", nil
+	}
+	names := make([]string, 0, len(albs))
+	for _, lb := range albs {
+		names = append(names, lb.Name)
+	}
+	return albLoadBalancer{}, fmt.Sprintf("Found %d application load balancers (%s); pass load_balancer to analyze one.\n", len(albs), strings.Join(names, ", ")), nil
+}
+
+// albMetricDimension turns a load balancer or target group ARN into the
+// CloudWatch dimension value: "app/web/50dc6c495c0c9188" for a load balancer,
+// "targetgroup/web-tg/73e2d6bc24d8a067" for a target group.
+func albMetricDimension(arn string) string {
+	resource := arn
+	if i := strings.LastIndex(arn, ":"); i >= 0 {
+		resource = arn[i+1:]
+	}
+	return strings.TrimPrefix(resource, "loadbalancer/")
+}
+
+// parseTargetHealth decodes describe-target-health, unhealthy targets first.
+func parseTargetHealth(raw string) ([]albTargetHealth, error) {
+	var resp albTargetHealthResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse target health: %w", err)
+	}
+	targets := make([]albTargetHealth, 0, len(resp.TargetHealthDescriptions))
+	for _, d := range resp.TargetHealthDescriptions {
+		targets = append(targets, albTargetHealth{
+			ID:          d.Target.ID,
+			Port:        d.Target.Port,
+			State:       d.TargetHealth.State,
+			Reason:      d.TargetHealth.Reason,
+			Description: d.TargetHealth.Description,
+		})
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].State != "healthy" && targets[j].State == "healthy"
+	})
+	return targets, nil
+}
+
+// correlateALB5xx lines up per-minute ELB 5xx counts with the per-minute
+// maximum unhealthy host count across target groups.
+func correlateALB5xx(errPoints []metricDatapoint, unhealthySeries [][]metricDatapoint) albCorrelation {
+	var corr albCorrelation
+	unhealthyAt := make(map[time.Time]float64)
+	for _, series := range unhealthySeries {
+		for _, p := range series {
+			if p.Value <= 0 {
+				continue
+			}
+			ts := p.Timestamp.UTC().Truncate(time.Minute)
+			unhealthyAt[ts] += p.Value
+			if !corr.UnhealthySeen || ts.Before(corr.UnhealthyFirst) {
+				corr.UnhealthyFirst = ts
+			}
+			corr.UnhealthySeen = true
+		}
+	}
+	for _, v := range unhealthyAt {
+		if v > corr.UnhealthyPeak {
+			corr.UnhealthyPeak = v
+		}
+	}
+
+	for _, p := range errPoints {
+		if p.Value <= 0 {
+			continue
+		}
+		corr.ErrorMinutes++
+		corr.Total5xx += p.Value
+		if p.Value > corr.Peak5xx {
+			corr.Peak5xx = p.Value
+			corr.PeakAt = p.Timestamp.UTC()
+		}
+		if unhealthyAt[p.Timestamp.UTC().Truncate(time.Minute)] > 0 {
+			corr.Overlap++
+		}
+	}
+	return corr
+}
+
+func formatALBErrorAnalysis(lb albLoadBalancer, groups []albTargetGroupHealth, corr albCorrelation, totalGroups int) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("⚖️  ALB ERROR ANALYSIS: %s\n", lb.Name))
+	out.WriteString("==============================\n\n")
+
+	window := corr.Window
+	if window == "" {
+		window = "in the last hour"
+	}
+	switch {
+	case corr.ErrorMinutes == 0 && !corr.UnhealthySeen:
+		out.WriteString(fmt.Sprintf("✅ No ELB 5xx and no unhealthy targets %s\n", window))
+	case corr.ErrorMinutes == 0:
+		out.WriteString(fmt.Sprintf("⚠️  No ELB 5xx %s, but up to %.0f unhealthy host(s) were reported from %s\n", window, corr.UnhealthyPeak, corr.UnhealthyFirst.Format(time.RFC3339)))
+	case corr.Overlap*2 >= corr.ErrorMinutes:
+		out.WriteString(fmt.Sprintf("🚨 ELB 5xx line up with unhealthy targets: %d of %d minutes with 5xx had unhealthy hosts (%.0f errors, peak %.0f/min at %s).\n",
+			corr.Overlap, corr.ErrorMinutes, corr.Total5xx, corr.Peak5xx, corr.PeakAt.Format(time.RFC3339)))
+		out.WriteString("Targets failing health checks are the likely cause; fix the reasons below.\n")
+	default:
+		out.WriteString(fmt.Sprintf("⚠️  %.0f ELB 5xx over %d minutes (peak %.0f/min at %s), but only %d of those minutes had unhealthy hosts.\n",
+			corr.Total5xx, corr.ErrorMinutes, corr.Peak5xx, corr.PeakAt.Format(time.RFC3339), corr.Overlap))
+		out.WriteString("Look beyond health checks: target timeouts or connection resets (502/504), no registered targets (503), or the app itself.\n")
+	}
+
+	for _, g := range groups {
+		out.WriteString(fmt.Sprintf("\nTarget group %s:\n", g.Group.Name))
+		if g.Err != "" {
+			out.WriteString(fmt.Sprintf("  ❌ could not read target health: %s\n", g.Err))
+			continue
+		}
+		if len(g.Targets) == 0 {
+			out.WriteString("  ⚠️  no registered targets (the ALB returns 503)\n")
+			continue
+		}
+		healthy := 0
+		for _, t := range g.Targets {
+			if t.State == "healthy" {
+				healthy++
+				continue
+			}
+			out.WriteString(fmt.Sprintf("  - %s:%d %s", t.ID, t.Port, valueOr(t.State, "unknown")))
+			if t.Reason != "" {
+				out.WriteString(" (" + t.Reason + ")")
+			}
+			if t.Description != "" {
+				out.WriteString(": " + t.Description)
+			}
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("  %d/%d targets healthy\n", healthy, len(g.Targets)))
+	}
+	if totalGroups > len(groups) {
+		out.WriteString(fmt.Sprintf("\n... %d more target groups not checked\n", totalGroups-len(groups)))
+	}
+	return out.String()
+}
@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlbMetricDimension(t *testing.T) {
+	lb := "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/web/50dc6c495c0c9188"
+	if got := albMetricDimension(lb); got != "app/web/50dc6c495c0c9188" {
+		t.Errorf("load balancer dimension = %q", got)
+	}
+	tg := "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web-tg/73e2d6bc24d8a067"
+	if got := albMetricDimension(tg); got != "targetgroup/web-tg/73e2d6bc24d8a067" {
+		t.Errorf("target group dimension = %q", got)
+	}
+}
+
+func TestParseTargetHealth(t *testing.T) {
+	raw := `{"TargetHealthDescriptions":[
+		{"Target":{"Id":"i-healthy","Port":80},"TargetHealth":{"State":"healthy"}},
+		{"Target":{"Id":"i-bad","Port":80},"TargetHealth":{"State":"unhealthy","Reason":"Target.ResponseCodeMismatch","Description":"Health checks failed with these codes: [500]"}}
+	]}`
+	targets, err := parseTargetHealth(raw)
+	if err != nil {
+		t.Fatalf("parseTargetHealth: %v", err)
+	}
+	if len(targets) != 2 || targets[0].ID != "i-bad" || targets[0].Reason != "Target.ResponseCodeMismatch" {
+		t.Errorf("expected the unhealthy target first, got %+v", targets)
+	}
+}
+
+func TestCorrelateALB5xx(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(min int, v float64) metricDatapoint {
+		return metricDatapoint{Timestamp: base.Add(time.Duration(min) * time.Minute), Value: v}
+	}
+	errs := []metricDatapoint{at(0, 0), at(1, 12), at(2, 30), at(3, 4)}
+	unhealthy := [][]metricDatapoint{{at(1, 1), at(2, 2)}, {at(2, 1)}}
+
+	corr := correlateALB5xx(errs, unhealthy)
+	if corr.ErrorMinutes != 3 || corr.Overlap != 2 || corr.Total5xx != 46 || corr.Peak5xx != 30 {
+		t.Errorf("unexpected correlation: %+v", corr)
+	}
+	if !corr.PeakAt.Equal(base.Add(2*time.Minute)) || corr.UnhealthyPeak != 3 {
+		t.Errorf("unexpected peaks: %+v", corr)
+	}
+
+	out := formatALBErrorAnalysis(albLoadBalancer{Name: "web"}, []albTargetGroupHealth{{
+		Group:   albTargetGroup{Name: "web-tg"},
+		Targets: []albTargetHealth{{ID: "i-bad", Port: 80, State: "unhealthy", Reason: "Target.Timeout"}},
+	}}, corr, 1)
+	for _, want := range []string{"line up with unhealthy targets", "i-bad:80 unhealthy (Target.Timeout)", "0/1 targets healthy"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	quiet := formatALBErrorAnalysis(albLoadBalancer{Name: "web"}, nil, correlateALB5xx(errs, nil), 0)
+	if !strings.Contains(quiet, "Look beyond health checks") {
+		t.Errorf("expected non-health-check guidance when targets stayed healthy:\n%s", quiet)
+	}
+}
@@ -0,0 +1,480 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// apigwErrorWindow is how far back analyze_apigw_errors looks unless the
+	// investigation window says otherwise.
+	apigwErrorWindow = 3 * time.Hour
+	apigwErrorPeriod = 300
+	apigwMaxAPIs     = 3
+	apigwMaxStages   = 2
+	// apigwMaxRoutes bounds the per-route metric fan-out for each stage.
+	apigwMaxRoutes = 15
+	// apigwIntegrationShare: integration latency at or above this share of
+	// the total puts the time in the backend rather than the gateway.
+	apigwIntegrationShare = 0.8
+)
+
+// apigwAPI is a REST (v1) or HTTP (v2) API. REST API metrics are keyed by
+// name, HTTP API metrics by ID, and the two spell their error metrics
+// differently.
+type apigwAPI struct {
+	ID   string
+	Name string
+	HTTP bool
+}
+
+func (a apigwAPI) kind() string {
+	if a.HTTP {
+		return "HTTP API"
+	}
+	return "REST API"
+}
+
+func (a apigwAPI) errorMetrics() (client, server string) {
+	if a.HTTP {
+		return "4xx", "5xx"
+	}
+	return "4XXError", "5XXError"
+}
+
+// dimensions returns the metric dimensions for the whole API (stage ""), or
+// for one route of a stage. Per-route metrics only exist when detailed
+// metrics are enabled on the stage.
+func (a apigwAPI) dimensions(stage string, route apigwRoute) []metricDimension {
+	dims := []metricDimension{{Name: "ApiName", Value: a.Name}}
+	if a.HTTP {
+		dims = []metricDimension{{Name: "ApiId", Value: a.ID}}
+	}
+	if stage == "" {
+		return dims
+	}
+	dims = append(dims, metricDimension{Name: "Stage", Value: stage})
+	switch {
+	case a.HTTP && route.Key != "":
+		dims = append(dims, metricDimension{Name: "Route", Value: route.Key})
+	case !a.HTTP && route.Resource != "":
+		dims = append(dims, metricDimension{Name: "Method", Value: route.Method}, metricDimension{Name: "Resource", Value: route.Resource})
+	}
+	return dims
+}
+
+// apigwRoute is an HTTP API route key ("GET /orders") or a REST API method
+// on a resource path.
+type apigwRoute struct {
+	Key      string
+	Method   string
+	Resource string
+}
+
+func (r apigwRoute) String() string {
+	if r.Key != "" {
+		return r.Key
+	}
+	return r.Method + " " + r.Resource
+}
+
+// apigwTotals is the whole API over the window; latencies are averages in
+// milliseconds.
+type apigwTotals struct {
+	Count              float64
+	Client4xx          float64
+	Server5xx          float64
+	Latency            float64
+	IntegrationLatency float64
+	LatencyPeak        float64
+}
+
+type apigwRouteErrors struct {
+	Stage     string
+	Route     apigwRoute
+	Client4xx float64
+	Server5xx float64
+	Count     float64
+}
+
+// analyzeAPIGWErrors is the analyze_apigw_errors operation: for REST and HTTP
+// APIs it sums 4xx, 5xx and requests over the investigation window (the last
+// three hours by default), finds the noisiest failing route, and splits
+// latency between the integration and the gateway.
+func (c *Client) analyzeAPIGWErrors(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	apis, err := c.listAPIGatewayAPIs(ctx, profile)
+	if err != nil {
+		return categorizeAWSError(err, "API Gateway"), nil
+	}
+	if len(apis) == 0 {
+		return "No API Gateway REST or HTTP APIs found.", nil
+	}
+	selected := apigwAPIsNamed(apis, getStringParam(input, "api_id", getStringParam(input, "api_name", "")))
+	if len(selected) == 0 {
+		selected = apigwAPIsInQuery(apis, getStringParam(input, "query", ""))
+	}
+	if len(selected) == 0 {
+		if len(apis) > apigwMaxAPIs {
+			names := make([]string, 0, len(apis))
+			for _, api := range apis {
+				names = append(names, fmt.Sprintf("%s (%s)", api.Name, api.ID))
+			}
+			return fmt.Sprintf("Name an API to analyze (api_name or api_id). APIs: %s\n", strings.Join(limitStrings(names, 20), ", ")), nil
+		}
+		selected = apis
+	}
+
+	start, end := operationWindow(ctx, input, apigwErrorWindow)
+	stage := getStringParam(input, "stage", "")
+	var out strings.Builder
+	for i, api := range selected {
+		if i == apigwMaxAPIs {
+			out.WriteString(fmt.Sprintf("\n... %d more APIs not analyzed; pass api_name to pick one\n", len(selected)-i))
+			break
+		}
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		totals := c.apigwTotals(ctx, api, start, end, profile)
+		var routes []apigwRouteErrors
+		var stages []string
+		if totals.Client4xx+totals.Server5xx > 0 {
+			stages = []string{stage}
+			if stage == "" {
+				stages = limitStrings(c.apigwStages(ctx, api, profile), apigwMaxStages)
+			}
+			routes = c.apigwRouteErrors(ctx, api, stages, start, end, profile)
+		}
+		out.WriteString(formatAPIGWErrors(api, totals, routes, len(stages) > 0, windowPhrase(start, end)))
+	}
+	return out.String(), nil
+}
+
+// listAPIGatewayAPIs lists REST APIs and HTTP APIs; WebSocket APIs have no
+// request/response error metrics and are skipped. One API family failing
+// (e.g. no apigatewayv2 permission) still returns the other.
+func (c *Client) listAPIGatewayAPIs(ctx context.Context, profile *AIProfile) ([]apigwAPI, error) {
+	var apis []apigwAPI
+	restRaw, restErr := c.execAWSCLI(ctx, []string{"apigateway", "get-rest-apis", "--output", "json"}, profile)
+	if restErr == nil {
+		var resp struct {
+			Items []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal([]byte(restRaw), &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse REST APIs: %w", err)
+		}
+		for _, item := range resp.Items {
+			apis = append(apis, apigwAPI{ID: item.ID, Name: item.Name})
+		}
+	}
+	httpRaw, httpErr := c.execAWSCLI(ctx, []string{"apigatewayv2", "get-apis", "--output", "json"}, profile)
+	if httpErr == nil {
+		var resp struct {
+			Items []struct {
+				ApiID        string `json:"ApiId"`
+				Name         string `json:"Name"`
+				ProtocolType string `json:"ProtocolType"`
+			} `json:"Items"`
+		}
+		if err := json.Unmarshal([]byte(httpRaw), &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse HTTP APIs: %w", err)
+		}
+		for _, item := range resp.Items {
+			if strings.EqualFold(item.ProtocolType, "HTTP") {
+				apis = append(apis, apigwAPI{ID: item.ApiID, Name: item.Name, HTTP: true})
+			}
+		}
+	}
+	if restErr != nil && httpErr != nil {
+		return nil, restErr
+	}
+	return apis, nil
+}
+
+// apigwAPIsNamed returns the APIs whose ID or name is ref.
+func apigwAPIsNamed(apis []apigwAPI, ref string) []apigwAPI {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil
+	}
+	var matched []apigwAPI
+	for _, api := range apis {
+		if api.ID == ref || strings.EqualFold(api.Name, ref) {
+			matched = append(matched, api)
+		}
+	}
+	return matched
+}
+
+// apigwAPIsInQuery returns the APIs a query names by ID or name, falling back
+// to APIs whose name contains a query word.
+func apigwAPIsInQuery(apis []apigwAPI, query string) []apigwAPI {
+	if query == "" {
+		return nil
+	}
+	var refs []string
+	for _, api := range apis {
+		refs = append(refs, api.ID, api.Name)
+	}
+	var matched []apigwAPI
+	for _, ref := range rolesReferencedInQuery(refs, query) {
+		for _, api := range apigwAPIsNamed(apis, ref) {
+			if !containsAPIGWAPI(matched, api) {
+				matched = append(matched, api)
+			}
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+	ignore := map[string]bool{"gateway": true, "apigw": true, "apigateway": true, "error": true, "errors": true, "throwing": true, "latency": true, "route": true, "routes": true, "with": true, "from": true, "what": true, "why": true}
+	for _, api := range apis {
+		lower := strings.ToLower(api.Name)
+		for _, word := range strings.Fields(strings.ToLower(query)) {
+			word = strings.Trim(word, ".,?!'\"")
+			if len(word) >= 4 && !ignore[word] && strings.Contains(lower, word) {
+				matched = append(matched, api)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func containsAPIGWAPI(apis []apigwAPI, api apigwAPI) bool {
+	for _, existing := range apis {
+		if existing.ID == api.ID && existing.HTTP == api.HTTP {
+			return true
+		}
+	}
+	return false
+}
+
+// apigwSeries fetches one API Gateway metric for the given dimensions.
+func (c *Client) apigwSeries(ctx context.Context, metric, stat string, dims []metricDimension, start, end time.Time, profile *AIProfile) []metricDatapoint {
+	window := end.Sub(start)
+	req := metricStatisticsRequest{
+		Namespace:  "AWS/ApiGateway",
+		MetricName: metric,
+		Dimensions: dims,
+		Period:     metricPeriodForWindow(apigwErrorPeriod, window),
+		Stat:       stat,
+		Window:     window,
+	}
+	raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile)
+	if err != nil {
+		return nil
+	}
+	points, _ := decodeMetricDatapoints(raw, req)
+	return points
+}
+
+func (c *Client) apigwTotals(ctx context.Context, api apigwAPI, start, end time.Time, profile *AIProfile) apigwTotals {
+	dims := api.dimensions("", apigwRoute{})
+	clientMetric, serverMetric := api.errorMetrics()
+	var t apigwTotals
+	t.Count = sumDatapoints(c.apigwSeries(ctx, "Count", "Sum", dims, start, end, profile))
+	t.Client4xx = sumDatapoints(c.apigwSeries(ctx, clientMetric, "Sum", dims, start, end, profile))
+	t.Server5xx = sumDatapoints(c.apigwSeries(ctx, serverMetric, "Sum", dims, start, end, profile))
+	_, t.LatencyPeak, t.Latency = summarizeMetricDatapoints(c.apigwSeries(ctx, "Latency", "Average", dims, start, end, profile))
+	_, _, t.IntegrationLatency = summarizeMetricDatapoints(c.apigwSeries(ctx, "IntegrationLatency", "Average", dims, start, end, profile))
+	return t
+}
+
+// apigwStages returns the API's stage names.
+func (c *Client) apigwStages(ctx context.Context, api apigwAPI, profile *AIProfile) []string {
+	args := []string{"apigateway", "get-stages", "--rest-api-id", api.ID, "--output", "json"}
+	if api.HTTP {
+		args = []string{"apigatewayv2", "get-stages", "--api-id", api.ID, "--output", "json"}
+	}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return nil
+	}
+	var resp struct {
+		Item []struct {
+			StageName string `json:"stageName"`
+		} `json:"item"`
+		Items []struct {
+			StageName string `json:"StageName"`
+		} `json:"Items"`
+	}
+	if json.Unmarshal([]byte(raw), &resp) != nil {
+		return nil
+	}
+	var stages []string
+	for _, s := range resp.Item {
+		stages = append(stages, s.StageName)
+	}
+	for _, s := range resp.Items {
+		stages = append(stages, s.StageName)
+	}
+	return stages
+}
+
+// apigwRoutes returns the API's routes: HTTP API route keys, or each method
+// on each REST API resource.
+func (c *Client) apigwRoutes(ctx context.Context, api apigwAPI, profile *AIProfile) []apigwRoute {
+	var routes []apigwRoute
+	if api.HTTP {
+		raw, err := c.execAWSCLI(ctx, []string{"apigatewayv2", "get-routes", "--api-id", api.ID, "--output", "json"}, profile)
+		if err != nil {
+			return nil
+		}
+		var resp struct {
+			Items []struct {
+				RouteKey string `json:"RouteKey"`
+			} `json:"Items"`
+		}
+		if json.Unmarshal([]byte(raw), &resp) != nil {
+			return nil
+		}
+		for _, item := range resp.Items {
+			routes = append(routes, apigwRoute{Key: item.RouteKey})
+		}
+		return routes
+	}
+
+	raw, err := c.execAWSCLI(ctx, []string{"apigateway", "get-resources", "--rest-api-id", api.ID, "--limit", "500", "--output", "json"}, profile)
+	if err != nil {
+		return nil
+	}
+	var resp struct {
+		Items []struct {
+			Path            string                     `json:"path"`
+			ResourceMethods map[string]json.RawMessage `json:"resourceMethods"`
+		} `json:"items"`
+	}
+	if json.Unmarshal([]byte(raw), &resp) != nil {
+		return nil
+	}
+	for _, item := range resp.Items {
+		for method := range item.ResourceMethods {
+			if method != "OPTIONS" {
+				routes = append(routes, apigwRoute{Method: method, Resource: item.Path})
+			}
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].String() < routes[j].String() })
+	return routes
+}
+
+// apigwRouteErrors sums 4xx and 5xx per route for each stage, noisiest
+// first, and fetches the request count of the noisiest route for its rate.
+// Routes with no errors are dropped.
+func (c *Client) apigwRouteErrors(ctx context.Context, api apigwAPI, stages []string, start, end time.Time, profile *AIProfile) []apigwRouteErrors {
+	if len(stages) == 0 {
+		return nil
+	}
+	routes := c.apigwRoutes(ctx, api, profile)
+	if len(routes) > apigwMaxRoutes {
+		routes = routes[:apigwMaxRoutes]
+	}
+	clientMetric, serverMetric := api.errorMetrics()
+	var failing []apigwRouteErrors
+	for _, stage := range stages {
+		for _, route := range routes {
+			dims := api.dimensions(stage, route)
+			r := apigwRouteErrors{
+				Stage:     stage,
+				Route:     route,
+				Client4xx: sumDatapoints(c.apigwSeries(ctx, clientMetric, "Sum", dims, start, end, profile)),
+				Server5xx: sumDatapoints(c.apigwSeries(ctx, serverMetric, "Sum", dims, start, end, profile)),
+			}
+			if r.Client4xx+r.Server5xx > 0 {
+				failing = append(failing, r)
+			}
+		}
+	}
+	sortAPIGWRouteErrors(failing)
+	if len(failing) > 0 {
+		top := &failing[0]
+		top.Count = sumDatapoints(c.apigwSeries(ctx, "Count", "Sum", api.dimensions(top.Stage, top.Route), start, end, profile))
+	}
+	return failing
+}
+
+// sortAPIGWRouteErrors orders routes by 5xx, then 4xx: server errors are the
+// ones the API owner has to fix.
+func sortAPIGWRouteErrors(routes []apigwRouteErrors) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Server5xx != routes[j].Server5xx {
+			return routes[i].Server5xx > routes[j].Server5xx
+		}
+		return routes[i].Client4xx > routes[j].Client4xx
+	})
+}
+
+// apigwLatencySplit says where the API's latency goes.
+func apigwLatencySplit(t apigwTotals) string {
+	if t.Latency <= 0 {
+		return ""
+	}
+	overhead := t.Latency - t.IntegrationLatency
+	if overhead < 0 {
+		overhead = 0
+	}
+	if t.IntegrationLatency >= t.Latency*apigwIntegrationShare {
+		return fmt.Sprintf("Latency averages %.0f ms, %.0f ms of it in the integration: the backend is the slow part", t.Latency, t.IntegrationLatency)
+	}
+	return fmt.Sprintf("Latency averages %.0f ms but the integration only %.0f ms: %.0f ms is spent in the gateway (authorizers, mapping templates, request validation)", t.Latency, t.IntegrationLatency, overhead)
+}
+
+func formatAPIGWErrors(api apigwAPI, t apigwTotals, routes []apigwRouteErrors, checkedRoutes bool, window string) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("🌐 API Gateway errors: %s (%s %s)\n", api.Name, api.kind(), api.ID))
+	out.WriteString("============================\n")
+
+	if t.Count == 0 && t.Client4xx+t.Server5xx == 0 {
+		out.WriteString(fmt.Sprintf("No requests %s\n", window))
+		return out.String()
+	}
+	rate := func(n, total float64) string {
+		if total <= 0 {
+			return ""
+		}
+		return fmt.Sprintf(" (%.1f%%)", n/total*100)
+	}
+	out.WriteString(fmt.Sprintf("Requests %s: %.0f, 4xx: %.0f%s, 5xx: %.0f%s\n", window, t.Count, t.Client4xx, rate(t.Client4xx, t.Count), t.Server5xx, rate(t.Server5xx, t.Count)))
+	if split := apigwLatencySplit(t); split != "" {
+		out.WriteString(split + fmt.Sprintf(" (worst period %.0f ms)\n", t.LatencyPeak))
+	}
+
+	switch {
+	case t.Client4xx+t.Server5xx == 0:
+		out.WriteString(fmt.Sprintf("✅ No 4xx or 5xx %s\n", window))
+		return out.String()
+	case len(routes) > 0:
+		top := routes[0]
+		out.WriteString(fmt.Sprintf("🚨 Noisiest failing route: %s on stage %s with %.0f 5xx and %.0f 4xx", top.Route, top.Stage, top.Server5xx, top.Client4xx))
+		if top.Count > 0 {
+			out.WriteString(fmt.Sprintf(" of %.0f requests", top.Count))
+		}
+		out.WriteString("\n")
+		if top.Server5xx > 0 {
+			out.WriteString("  5xx come from the integration or the gateway itself; check the integration's logs and, for Lambda, its errors and timeouts\n")
+		} else {
+			out.WriteString("  4xx are client errors: missing auth, failed validation or unmatched routes; check the authorizer and request models\n")
+		}
+		if len(routes) > 1 {
+			out.WriteString("Other failing routes:\n")
+			for i, r := range routes[1:] {
+				if i == 5 {
+					out.WriteString(fmt.Sprintf("  ... and %d more\n", len(routes)-1-i))
+					break
+				}
+				out.WriteString(fmt.Sprintf("  - %s (%s): %.0f 5xx, %.0f 4xx\n", r.Route, r.Stage, r.Server5xx, r.Client4xx))
+			}
+		}
+	case checkedRoutes:
+		out.WriteString("⚠️  No per-route error metrics: enable detailed metrics on the stage to see which route fails\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,116 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeAPIGWErrorsNamesNoisiestRESTRoute(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["apigateway get-rest-apis"] = `{"items": [{"id": "a1b2c3", "name": "orders-api"}, {"id": "d4e5f6", "name": "billing-api"}]}`
+	f.fixtures["apigatewayv2 get-apis"] = `{"Items": [{"ApiId": "h7", "Name": "chat", "ProtocolType": "WEBSOCKET"}]}`
+	f.fixtures["apigateway get-stages --rest-api-id a1b2c3"] = `{"item": [{"stageName": "prod"}]}`
+	f.fixtures["apigateway get-resources --rest-api-id a1b2c3"] = `{"items": [
+		{"path": "/orders", "resourceMethods": {"GET": {}, "POST": {}, "OPTIONS": {}}},
+		{"path": "/orders/{id}", "resourceMethods": {"GET": {}}}
+	]}`
+	f.fixtures["cloudwatch get-metric-statistics"] = `{"Datapoints": []}`
+	c := newFakeClient(f)
+	point := func(stat, value string) string {
+		return `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "` + stat + `": ` + value + `}]}`
+	}
+	c.execFunc = func(ctx context.Context, args []string, profile *AIProfile) (string, error) {
+		cmd := strings.Join(args, " ")
+		if !strings.HasPrefix(cmd, "cloudwatch get-metric-statistics") || !strings.Contains(cmd, "Value=orders-api") {
+			return f.exec(ctx, args, profile)
+		}
+		route := strings.Contains(cmd, "Name=Stage,Value=prod")
+		postOrders := strings.Contains(cmd, "Name=Method,Value=POST Name=Resource,Value=/orders ")
+		getOrders := strings.Contains(cmd, "Name=Method,Value=GET Name=Resource,Value=/orders ")
+		switch {
+		case strings.Contains(cmd, "--metric-name Count") && postOrders:
+			return point("Sum", "400"), nil
+		case strings.Contains(cmd, "--metric-name Count") && !route:
+			return point("Sum", "1000"), nil
+		case strings.Contains(cmd, "--metric-name 5XXError") && postOrders, strings.Contains(cmd, "--metric-name 5XXError") && !route:
+			return point("Sum", "30"), nil
+		case strings.Contains(cmd, "--metric-name 4XXError") && getOrders:
+			return point("Sum", "12"), nil
+		case strings.Contains(cmd, "--metric-name 4XXError") && !route:
+			return point("Sum", "12"), nil
+		case strings.Contains(cmd, "--metric-name IntegrationLatency"):
+			return point("Average", "900"), nil
+		case strings.Contains(cmd, "--metric-name Latency"):
+			return point("Average", "950"), nil
+		}
+		return f.exec(ctx, args, profile)
+	}
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_apigw_errors", map[string]interface{}{"query": "why is orders-api throwing errors"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"API Gateway errors: orders-api (REST API a1b2c3)",
+		"4xx: 12 (1.2%), 5xx: 30 (3.0%)",
+		"Noisiest failing route: POST /orders on stage prod with 30 5xx and 0 4xx of 400 requests",
+		"GET /orders (prod): 0 5xx, 12 4xx",
+		"900 ms of it in the integration: the backend is the slow part",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "billing-api") || strings.Contains(out, "OPTIONS") {
+		t.Errorf("only orders-api routes should be analyzed:\n%s", out)
+	}
+}
+
+func TestAnalyzeAPIGWErrorsHTTPAPIWithoutDetailedMetrics(t *testing.T) {
+	f := newFakeCLI()
+	f.failures["apigateway get-rest-apis"] = errors.New("AccessDeniedException: not authorized to perform apigateway:GET")
+	f.fixtures["apigatewayv2 get-apis"] = `{"Items": [{"ApiId": "x9y8", "Name": "public", "ProtocolType": "HTTP"}]}`
+	f.fixtures["apigatewayv2 get-stages --api-id x9y8"] = `{"Items": [{"StageName": "$default"}]}`
+	f.fixtures["apigatewayv2 get-routes --api-id x9y8"] = `{"Items": [{"RouteKey": "GET /items"}]}`
+	f.fixtures["cloudwatch get-metric-statistics"] = `{"Datapoints": []}`
+	c := newFakeClient(f)
+	c.execFunc = func(ctx context.Context, args []string, profile *AIProfile) (string, error) {
+		cmd := strings.Join(args, " ")
+		if strings.HasPrefix(cmd, "cloudwatch") && !strings.Contains(cmd, "Name=Stage") {
+			switch {
+			case strings.Contains(cmd, "--metric-name 5xx"):
+				return `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Sum": 5}]}`, nil
+			case strings.Contains(cmd, "--metric-name Latency"):
+				return `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Average": 400}]}`, nil
+			case strings.Contains(cmd, "--metric-name IntegrationLatency"):
+				return `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Average": 50}]}`, nil
+			}
+		}
+		return f.exec(ctx, args, profile)
+	}
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_apigw_errors", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"API Gateway errors: public (HTTP API x9y8)",
+		"350 ms is spent in the gateway",
+		"enable detailed metrics on the stage",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	var routeCall bool
+	for _, call := range f.calls {
+		if strings.Contains(call, "Name=Route,Value=GET /items") && strings.Contains(call, "--metric-name 5xx") {
+			routeCall = true
+		}
+	}
+	if !routeCall {
+		t.Errorf("expected a per-route 5xx lookup, calls: %v", f.calls)
+	}
+}
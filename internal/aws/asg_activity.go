@@ -0,0 +1,367 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// asgActivityWindow is how far back analyze_asg_activity reads scaling
+	// activities unless the investigation window says otherwise.
+	asgActivityWindow = 24 * time.Hour
+	// asgMaxGroups bounds the per-group activity and target health calls.
+	asgMaxGroups = 10
+	// asgLoopTerminations is how many health check replacements within the
+	// window count as a launch/terminate loop.
+	asgLoopTerminations = 3
+	asgMaxMessageChars  = 200
+)
+
+// autoScalingGroup is a group from describe-auto-scaling-groups.
+type autoScalingGroup struct {
+	Name            string   `json:"AutoScalingGroupName"`
+	MinSize         int      `json:"MinSize"`
+	MaxSize         int      `json:"MaxSize"`
+	DesiredCapacity int      `json:"DesiredCapacity"`
+	HealthCheckType string   `json:"HealthCheckType"`
+	TargetGroupARNs []string `json:"TargetGroupARNs"`
+	Instances       []struct {
+		InstanceID     string `json:"InstanceId"`
+		LifecycleState string `json:"LifecycleState"`
+		HealthStatus   string `json:"HealthStatus"`
+	} `json:"Instances"`
+	SuspendedProcesses []struct {
+		ProcessName string `json:"ProcessName"`
+	} `json:"SuspendedProcesses"`
+}
+
+// inService counts the instances that are InService and healthy.
+func (g autoScalingGroup) inService() int {
+	n := 0
+	for _, i := range g.Instances {
+		if i.LifecycleState == "InService" && i.HealthStatus == "Healthy" {
+			n++
+		}
+	}
+	return n
+}
+
+// scalingActivity is an activity from describe-scaling-activities.
+type scalingActivity struct {
+	StatusCode    string    `json:"StatusCode"`
+	StatusMessage string    `json:"StatusMessage"`
+	Description   string    `json:"Description"`
+	Cause         string    `json:"Cause"`
+	StartTime     time.Time `json:"StartTime"`
+}
+
+// asgLaunchFailureKinds maps substrings of a failed activity's status
+// message to a short reason, checked in order.
+var asgLaunchFailureKinds = []struct {
+	label    string
+	patterns []string
+}{
+	{"insufficient capacity", []string{"insufficientinstancecapacity", "insufficient capacity", "do not have sufficient", "capacity-not-available"}},
+	{"launch template invalid", []string{"launch template", "launchtemplate", "launch configuration", "ami-", "image id", "imageid"}},
+	{"instance limit reached", []string{"vcpulimitexceeded", "instancelimitexceeded", "limit exceeded", "maxspotinstancecountexceeded"}},
+	{"no free IPs in subnet", []string{"insufficientfreeaddressesinsubnet", "free ip", "no available ip"}},
+	{"permission denied", []string{"not authorized", "unauthorized", "accessdenied", "iam instance profile", "kms"}},
+	{"spot request failed", []string{"spot"}},
+}
+
+// asgLaunchFailureKind classifies a failed launch by its status message.
+func asgLaunchFailureKind(message string) string {
+	lower := strings.ToLower(message)
+	for _, kind := range asgLaunchFailureKinds {
+		for _, p := range kind.patterns {
+			if strings.Contains(lower, p) {
+				return kind.label
+			}
+		}
+	}
+	return "other"
+}
+
+// asgTargetGroupHealth is the health of a group's instances in one target
+// group.
+type asgTargetGroupHealth struct {
+	Name      string
+	Total     int
+	Unhealthy int
+	Reasons   []string
+	Err       string
+}
+
+// asgGroupActivity is the scaling picture of one group.
+type asgGroupActivity struct {
+	Group autoScalingGroup
+	// Failures groups failed launches by reason; Samples keeps the newest
+	// message for each.
+	Failures map[string]int
+	Samples  map[string]string
+	// HealthTerminations counts instances replaced for failing health checks.
+	HealthTerminations int
+	TargetGroups       []asgTargetGroupHealth
+	Err                string
+}
+
+func (a asgGroupActivity) failedLaunches() int {
+	n := 0
+	for _, count := range a.Failures {
+		n += count
+	}
+	return n
+}
+
+func (a asgGroupActivity) healthy() bool {
+	if a.Err != "" || a.failedLaunches() > 0 || a.HealthTerminations >= asgLoopTerminations || len(a.Group.SuspendedProcesses) > 0 {
+		return false
+	}
+	for _, tg := range a.TargetGroups {
+		if tg.Unhealthy > 0 || tg.Err != "" {
+			return false
+		}
+	}
+	return a.Group.inService() >= a.Group.DesiredCapacity
+}
+
+// analyzeASGActivity is the analyze_asg_activity operation: for each Auto
+// Scaling group it compares in-service instances with the desired
+// capacity, groups recent failed launches by reason, and checks whether the
+// attached target groups mark instances unhealthy so the group keeps
+// replacing them.
+func (c *Client) analyzeASGActivity(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	start, end := operationWindow(ctx, input, asgActivityWindow)
+	window := windowPhrase(start, end)
+
+	var out strings.Builder
+	out.WriteString("⚖️  AUTO SCALING ACTIVITY\n")
+	out.WriteString("============================\n")
+
+	groups, msg := c.asgGroups(ctx, getStringParam(input, "asg_name", ""), getStringParam(input, "query", ""), profile)
+	if msg != "" {
+		out.WriteString(msg + "\n")
+		return out.String(), nil
+	}
+
+	var healthy []string
+	for _, g := range groups {
+		a := c.asgGroupActivity(ctx, g, start, end, profile)
+		if a.healthy() {
+			healthy = append(healthy, fmt.Sprintf("%s (%d/%d)", g.Name, g.inService(), g.DesiredCapacity))
+			continue
+		}
+		out.WriteString(formatASGActivity(a, window))
+	}
+	if len(healthy) > 0 {
+		out.WriteString(fmt.Sprintf("\n✅ At desired capacity with no failed launches %s: %s\n", window, strings.Join(healthy, ", ")))
+	}
+	return out.String(), nil
+}
+
+// asgGroups resolves the groups to check: the named one, the ones a query
+// names, or every group up to asgMaxGroups.
+func (c *Client) asgGroups(ctx context.Context, name, query string, profile *AIProfile) ([]autoScalingGroup, string) {
+	args := []string{"autoscaling", "describe-auto-scaling-groups", "--output", "json"}
+	if name != "" {
+		args = append(args, "--auto-scaling-group-names", name)
+	}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return nil, categorizeAWSError(err, "Auto Scaling")
+	}
+	var resp struct {
+		AutoScalingGroups []autoScalingGroup `json:"AutoScalingGroups"`
+	}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return nil, fmt.Sprintf("Failed to parse Auto Scaling groups: %v", err)
+		}
+	}
+	if len(resp.AutoScalingGroups) == 0 {
+		if name != "" {
+			return nil, fmt.Sprintf("No Auto Scaling group named %s.", name)
+		}
+		return nil, "No Auto Scaling groups found."
+	}
+	if name != "" {
+		return resp.AutoScalingGroups, ""
+	}
+
+	names := make([]string, 0, len(resp.AutoScalingGroups))
+	for _, g := range resp.AutoScalingGroups {
+		names = append(names, g.Name)
+	}
+	matched := make(map[string]bool)
+	for _, n := range rolesReferencedInQuery(names, query) {
+		matched[n] = true
+	}
+	var groups []autoScalingGroup
+	for _, g := range resp.AutoScalingGroups {
+		if len(matched) == 0 || matched[g.Name] {
+			groups = append(groups, g)
+		}
+	}
+	if len(groups) > asgMaxGroups {
+		groups = groups[:asgMaxGroups]
+	}
+	return groups, ""
+}
+
+func (c *Client) asgGroupActivity(ctx context.Context, g autoScalingGroup, start, end time.Time, profile *AIProfile) asgGroupActivity {
+	a := asgGroupActivity{Group: g, Failures: make(map[string]int), Samples: make(map[string]string)}
+	raw, err := c.execAWSCLI(ctx, []string{"autoscaling", "describe-scaling-activities", "--auto-scaling-group-name", g.Name, "--max-items", "100", "--output", "json"}, profile)
+	if err != nil {
+		a.Err = categorizeAWSError(err, "Auto Scaling")
+		return a
+	}
+	var resp struct {
+		Activities []scalingActivity `json:"Activities"`
+	}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			a.Err = fmt.Sprintf("Failed to parse scaling activities: %v", err)
+			return a
+		}
+	}
+	// Activities come newest first, so the first sample kept is the newest.
+	for _, act := range resp.Activities {
+		if act.StartTime.Before(start) || act.StartTime.After(end) {
+			continue
+		}
+		switch {
+		case act.StatusCode == "Failed" || act.StatusCode == "Cancelled":
+			message := valueOr(act.StatusMessage, act.Description)
+			kind := asgLaunchFailureKind(message)
+			a.Failures[kind]++
+			if a.Samples[kind] == "" {
+				a.Samples[kind] = truncateASGMessage(message)
+			}
+		case strings.HasPrefix(act.Description, "Terminating EC2 instance") && strings.Contains(strings.ToLower(act.Cause), "health check"):
+			a.HealthTerminations++
+		}
+	}
+
+	instances := make(map[string]bool, len(g.Instances))
+	for _, i := range g.Instances {
+		instances[i.InstanceID] = true
+	}
+	for _, arn := range g.TargetGroupARNs {
+		tg := asgTargetGroupHealth{Name: targetGroupName(arn)}
+		raw, err := c.execAWSCLI(ctx, []string{"elbv2", "describe-target-health", "--target-group-arn", arn, "--output", "json"}, profile)
+		if err != nil {
+			tg.Err = categorizeAWSError(err, "ELBv2")
+			a.TargetGroups = append(a.TargetGroups, tg)
+			continue
+		}
+		targets, err := parseTargetHealth(raw)
+		if err != nil {
+			tg.Err = err.Error()
+			a.TargetGroups = append(a.TargetGroups, tg)
+			continue
+		}
+		reasons := make(map[string]bool)
+		for _, t := range targets {
+			if !instances[t.ID] {
+				continue
+			}
+			tg.Total++
+			if t.State == "unhealthy" {
+				tg.Unhealthy++
+				if reason := valueOr(t.Reason, t.Description); reason != "" && !reasons[reason] {
+					reasons[reason] = true
+					tg.Reasons = append(tg.Reasons, reason)
+				}
+			}
+		}
+		a.TargetGroups = append(a.TargetGroups, tg)
+	}
+	return a
+}
+
+// targetGroupName returns the name part of a target group ARN
+// (arn:...:targetgroup/<name>/<id>).
+func targetGroupName(arn string) string {
+	parts := strings.Split(arn, "/")
+	if len(parts) >= 3 {
+		return parts[len(parts)-2]
+	}
+	return arn
+}
+
+func truncateASGMessage(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > asgMaxMessageChars {
+		return s[:asgMaxMessageChars] + "…"
+	}
+	return s
+}
+
+func formatASGActivity(a asgGroupActivity, window string) string {
+	var out strings.Builder
+	g := a.Group
+	if a.Err != "" {
+		out.WriteString(fmt.Sprintf("\n❌ %s: %s\n", g.Name, a.Err))
+		return out.String()
+	}
+
+	icon := "⚠️ "
+	if g.inService() < g.DesiredCapacity {
+		icon = "🚨"
+	}
+	out.WriteString(fmt.Sprintf("\n%s %s: %d of %d desired instances in service (min %d, max %d)\n", icon, g.Name, g.inService(), g.DesiredCapacity, g.MinSize, g.MaxSize))
+
+	if failed := a.failedLaunches(); failed > 0 {
+		out.WriteString(fmt.Sprintf("   Failed launches %s (%d):\n", window, failed))
+		kinds := make([]string, 0, len(a.Failures))
+		for kind := range a.Failures {
+			kinds = append(kinds, kind)
+		}
+		sort.Slice(kinds, func(i, j int) bool {
+			if a.Failures[kinds[i]] != a.Failures[kinds[j]] {
+				return a.Failures[kinds[i]] > a.Failures[kinds[j]]
+			}
+			return kinds[i] < kinds[j]
+		})
+		for _, kind := range kinds {
+			out.WriteString(fmt.Sprintf("     - %s (%d): %s\n", kind, a.Failures[kind], a.Samples[kind]))
+		}
+	}
+
+	var unhealthyGroups []string
+	for _, tg := range a.TargetGroups {
+		switch {
+		case tg.Err != "":
+			out.WriteString(fmt.Sprintf("   Target group %s: health unavailable: %s\n", tg.Name, tg.Err))
+		case tg.Unhealthy > 0:
+			unhealthyGroups = append(unhealthyGroups, tg.Name)
+			line := fmt.Sprintf("   Target group %s: %d of %d instances unhealthy", tg.Name, tg.Unhealthy, tg.Total)
+			if len(tg.Reasons) > 0 {
+				line += " (" + strings.Join(tg.Reasons, ", ") + ")"
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+	if a.HealthTerminations >= asgLoopTerminations {
+		line := fmt.Sprintf("   🔁 Launch/terminate loop: %d instances replaced for failing health checks %s", a.HealthTerminations, window)
+		if len(unhealthyGroups) > 0 {
+			line += "; fix the target group health check or the app behind it (" + strings.Join(unhealthyGroups, ", ") + ")"
+		} else if g.HealthCheckType == "ELB" {
+			line += "; the group uses ELB health checks, so check the target group's health check path and grace period"
+		}
+		out.WriteString(line + "\n")
+	}
+
+	if len(g.SuspendedProcesses) > 0 {
+		processes := make([]string, 0, len(g.SuspendedProcesses))
+		for _, p := range g.SuspendedProcesses {
+			processes = append(processes, p.ProcessName)
+		}
+		out.WriteString(fmt.Sprintf("   Suspended processes: %s\n", strings.Join(processes, ", ")))
+	}
+	return out.String()
+}
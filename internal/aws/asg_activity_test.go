@@ -0,0 +1,73 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeASGActivity(t *testing.T) {
+	at := func(ago time.Duration) string { return time.Now().UTC().Add(-ago).Format(time.RFC3339) }
+	f := newFakeCLI()
+	f.fixtures["autoscaling describe-auto-scaling-groups"] = `{"AutoScalingGroups": [
+		{"AutoScalingGroupName": "web-asg", "MinSize": 2, "MaxSize": 6, "DesiredCapacity": 3, "HealthCheckType": "ELB",
+		 "TargetGroupARNs": ["arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web-tg/abc"],
+		 "Instances": [
+			{"InstanceId": "i-1", "LifecycleState": "InService", "HealthStatus": "Healthy"},
+			{"InstanceId": "i-2", "LifecycleState": "Pending", "HealthStatus": "Healthy"}],
+		 "SuspendedProcesses": [{"ProcessName": "AZRebalance"}]},
+		{"AutoScalingGroupName": "worker-asg", "MinSize": 1, "MaxSize": 2, "DesiredCapacity": 1,
+		 "Instances": [{"InstanceId": "i-9", "LifecycleState": "InService", "HealthStatus": "Healthy"}]}]}`
+	f.fixtures["autoscaling describe-scaling-activities --auto-scaling-group-name web-asg"] = `{"Activities": [
+		{"StatusCode": "Failed", "StartTime": "` + at(time.Hour) + `", "Description": "Launching a new EC2 instance.  Status Reason: We currently do not have sufficient t3.large capacity in the Availability Zone you requested (us-east-1a)."},
+		{"StatusCode": "Failed", "StartTime": "` + at(2*time.Hour) + `", "StatusMessage": "We currently do not have sufficient t3.large capacity. InsufficientInstanceCapacity"},
+		{"StatusCode": "Failed", "StartTime": "` + at(3*time.Hour) + `", "StatusMessage": "The specified launch template, with template ID lt-0abc, does not exist."},
+		{"StatusCode": "Successful", "StartTime": "` + at(4*time.Hour) + `", "Description": "Terminating EC2 instance: i-a", "Cause": "an instance was taken out of service in response to an ELB system health check failure."},
+		{"StatusCode": "Successful", "StartTime": "` + at(5*time.Hour) + `", "Description": "Terminating EC2 instance: i-b", "Cause": "an instance was taken out of service in response to an ELB system health check failure."},
+		{"StatusCode": "Successful", "StartTime": "` + at(6*time.Hour) + `", "Description": "Terminating EC2 instance: i-c", "Cause": "an instance was taken out of service in response to an ELB system health check failure."},
+		{"StatusCode": "Failed", "StartTime": "` + at(72*time.Hour) + `", "StatusMessage": "You are not authorized to perform this operation."}]}`
+	f.fixtures["autoscaling describe-scaling-activities --auto-scaling-group-name worker-asg"] = `{"Activities": []}`
+	f.fixtures["elbv2 describe-target-health"] = `{"TargetHealthDescriptions": [
+		{"Target": {"Id": "i-1", "Port": 80}, "TargetHealth": {"State": "healthy"}},
+		{"Target": {"Id": "i-2", "Port": 80}, "TargetHealth": {"State": "unhealthy", "Reason": "Target.FailedHealthChecks"}},
+		{"Target": {"Id": "i-other", "Port": 80}, "TargetHealth": {"State": "unhealthy", "Reason": "Target.Timeout"}}]}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_asg_activity", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"🚨 web-asg: 1 of 3 desired instances in service (min 2, max 6)",
+		"Failed launches in the last 1d (3):",
+		"- insufficient capacity (2): Launching a new EC2 instance. Status Reason: We currently do not have sufficient t3.large capacity",
+		"- launch template invalid (1): The specified launch template, with template ID lt-0abc, does not exist.",
+		"Target group web-tg: 1 of 2 instances unhealthy (Target.FailedHealthChecks)",
+		"🔁 Launch/terminate loop: 3 instances replaced for failing health checks in the last 1d; fix the target group health check or the app behind it (web-tg)",
+		"Suspended processes: AZRebalance",
+		"✅ At desired capacity with no failed launches in the last 1d: worker-asg (1/1)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"permission denied", "Target.Timeout"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("did not expect %q (outside the window or not a group instance):\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestASGLaunchFailureKind(t *testing.T) {
+	for message, want := range map[string]string{
+		"Launching a new EC2 instance. Status Reason: You have requested more vCPU capacity than your current vCPU limit. VcpuLimitExceeded": "instance limit reached",
+		"There are not enough free addresses in subnet 'subnet-1'. InsufficientFreeAddressesInSubnet":                                        "no free IPs in subnet",
+		"The image id '[ami-0123]' does not exist": "launch template invalid",
+		"Something unexpected happened":            "other",
+	} {
+		if got := asgLaunchFailureKind(message); got != want {
+			t.Errorf("asgLaunchFailureKind(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ResolveAWSContext returns the AWS profile and region a CLI call should use.
+// Precedence, highest first:
+//
+//  1. explicit --profile / --region flags (aws.profile_override, aws.region_override)
+//  2. AWS_PROFILE, then AWS_REGION / AWS_DEFAULT_REGION
+//  3. the profile's configured aws_profile / region
+//
+// A region pinned by a multi-region fan-out always wins, since each copy of
+// the profile is meant to query exactly one region. Likewise a profile pinned
+// by compare_environments keeps its own aws_profile, since comparing two
+// environments through the same credentials would find no differences.
+func ResolveAWSContext(profile *AIProfile) (effectiveProfile, effectiveRegion string) {
+	var configProfile, configRegion string
+	pinned := false
+	if profile != nil {
+		configProfile = strings.TrimSpace(profile.AWSProfile)
+		configRegion = strings.TrimSpace(profile.Region)
+		pinned = profile.regionPinned
+	}
+
+	if profile != nil && profile.profilePinned && configProfile != "" {
+		effectiveProfile = configProfile
+	} else {
+		effectiveProfile = firstNonEmpty(
+			viper.GetString("aws.profile_override"),
+			os.Getenv("AWS_PROFILE"),
+			configProfile,
+		)
+	}
+	if pinned && configRegion != "" {
+		return effectiveProfile, configRegion
+	}
+	effectiveRegion = firstNonEmpty(
+		viper.GetString("aws.region_override"),
+		os.Getenv("AWS_REGION"),
+		os.Getenv("AWS_DEFAULT_REGION"),
+		configRegion,
+	)
+	return effectiveProfile, effectiveRegion
+}
+
+// resolvedProfile returns a copy of profile carrying the effective AWS
+// profile and region, or profile itself when nothing changes.
+func resolvedProfile(profile *AIProfile) *AIProfile {
+	awsProfile, region := ResolveAWSContext(profile)
+	if profile != nil && profile.AWSProfile == awsProfile && profile.Region == region {
+		return profile
+	}
+	resolved := AIProfile{}
+	if profile != nil {
+		resolved = *profile
+	}
+	resolved.AWSProfile = awsProfile
+	resolved.Region = region
+	return &resolved
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveAWSContextPrecedence(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	config := &AIProfile{AWSProfile: "config-profile", Region: "us-west-2"}
+
+	if p, r := ResolveAWSContext(config); p != "config-profile" || r != "us-west-2" {
+		t.Errorf("config only: got %s/%s", p, r)
+	}
+
+	t.Setenv("AWS_PROFILE", "env-profile")
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	if p, r := ResolveAWSContext(config); p != "env-profile" || r != "eu-west-1" {
+		t.Errorf("env should beat config: got %s/%s", p, r)
+	}
+	t.Setenv("AWS_REGION", "eu-central-1")
+	if _, r := ResolveAWSContext(config); r != "eu-central-1" {
+		t.Errorf("AWS_REGION should beat AWS_DEFAULT_REGION: got %s", r)
+	}
+
+	viper.Set("aws.profile_override", "flag-profile")
+	viper.Set("aws.region_override", "ap-south-1")
+	if p, r := ResolveAWSContext(config); p != "flag-profile" || r != "ap-south-1" {
+		t.Errorf("flags should beat env: got %s/%s", p, r)
+	}
+
+	if _, r := ResolveAWSContext(profileForRegion(config, "sa-east-1")); r != "sa-east-1" {
+		t.Errorf("multi-region pinned region should win: got %s", r)
+	}
+}
+
+func TestExecAWSCLIUsesResolvedContext(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Setenv("AWS_PROFILE", "env-profile")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	var got *AIProfile
+	c := &Client{}
+	c.SetExecFunc(func(_ context.Context, _ []string, profile *AIProfile) (string, error) {
+		got = profile
+		return "", nil
+	})
+	config := &AIProfile{AWSProfile: "config-profile", Region: "us-east-1"}
+	if _, err := c.execAWSCLI(context.Background(), []string{"sts", "get-caller-identity"}, config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.AWSProfile != "env-profile" || got.Region != "eu-west-1" {
+		t.Errorf("exec func got %s/%s, want env-profile/eu-west-1", got.AWSProfile, got.Region)
+	}
+	if config.AWSProfile != "config-profile" {
+		t.Error("the caller's profile must not be modified")
+	}
+}
@@ -0,0 +1,179 @@
+package aws
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultBreakerFailures = 3
+	defaultBreakerCooldown = 2 * time.Minute
+)
+
+// hardAWSFailureMarkers identify failures that will keep failing for every
+// call to the same service with the same credentials, such as missing IAM
+// permissions or a service the account never opted into.
+var hardAWSFailureMarkers = []string{
+	"accessdenied",
+	"access denied",
+	"unauthorizedoperation",
+	"not authorized",
+	"optinrequired",
+	"subscriptionrequired",
+	"not subscribed",
+}
+
+// isHardAWSFailure reports whether output describes a failure that should
+// count toward opening the breaker. Retryable errors never do.
+func isHardAWSFailure(output string) bool {
+	if isRetryableAWSError(output) {
+		return false
+	}
+	lower := strings.ToLower(output)
+	for _, marker := range hardAWSFailureMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// breakerState tracks one (service, profile) pair.
+type breakerState struct {
+	failures int
+	openedAt time.Time
+	lastErr  error
+	probing  bool
+}
+
+// circuitBreaker short-circuits AWS CLI calls to a service that keeps failing
+// hard. After the configured number of consecutive hard failures it opens for
+// the cooldown, then half-opens to let exactly one call through: success or a
+// soft failure closes it again, another hard failure reopens it.
+type circuitBreaker struct {
+	mu     sync.Mutex
+	states map[string]*breakerState
+	now    func() time.Time
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{states: make(map[string]*breakerState), now: time.Now}
+}
+
+// awsCLIBreaker is shared by every Client so repeated failures are remembered
+// across regions and parallel runners.
+var awsCLIBreaker = newCircuitBreaker()
+
+// breakerKey identifies the service an AWS CLI call targets and the
+// credentials it uses.
+func breakerKey(args []string, profile *AIProfile) string {
+	service := ""
+	if len(args) > 0 {
+		service = args[0]
+	}
+	awsProfile := ""
+	if profile != nil {
+		awsProfile = profile.AWSProfile
+	}
+	return service + "|" + awsProfile
+}
+
+// allow returns an error carrying the cached failure when the breaker for key
+// is open, and otherwise lets the call proceed.
+func (b *circuitBreaker) allow(key string) error {
+	threshold, cooldown := awsBreakerFailures(), awsBreakerCooldown()
+	if threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[key]
+	if !ok || state.failures < threshold {
+		return nil
+	}
+
+	service, awsProfile, _ := strings.Cut(key, "|")
+	remaining := state.openedAt.Add(cooldown).Sub(b.now())
+	if remaining <= 0 && !state.probing {
+		// Half-open: this caller is the single probe.
+		state.probing = true
+		return nil
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return fmt.Errorf("AWS CLI call to %s skipped: circuit open for profile %q after %d consecutive failures (retry in %s): %w",
+		service, awsProfile, state.failures, remaining.Round(time.Second), state.lastErr)
+}
+
+// record updates the breaker with the outcome of a call that allow let through.
+func (b *circuitBreaker) record(key string, err error, output string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[key]
+	if err == nil || !isHardAWSFailure(output+" "+err.Error()) {
+		delete(b.states, key)
+		return
+	}
+	if !ok {
+		state = &breakerState{}
+		b.states[key] = state
+	}
+	state.failures++
+	state.lastErr = err
+	state.probing = false
+	if state.failures >= awsBreakerFailures() {
+		state.openedAt = b.now()
+	}
+}
+
+// abandon releases a half-open probe whose call was cancelled before it
+// produced a meaningful outcome.
+func (b *circuitBreaker) abandon(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if state, ok := b.states[key]; ok {
+		state.probing = false
+	}
+}
+
+// awsBreakerFailures reads aws.breaker_failures; 0 or less disables the breaker.
+func awsBreakerFailures() int {
+	if !viper.IsSet("aws.breaker_failures") {
+		return defaultBreakerFailures
+	}
+	return viper.GetInt("aws.breaker_failures")
+}
+
+// awsBreakerCooldown reads aws.breaker_cooldown as a Go duration ("90s") or
+// a number of seconds.
+func awsBreakerCooldown() time.Duration {
+	raw := strings.TrimSpace(viper.GetString("aws.breaker_cooldown"))
+	if raw == "" {
+		return defaultBreakerCooldown
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+		return d
+	}
+	if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultBreakerCooldown
+}
+
+// withJitter spreads a backoff over [d/2, d] so parallel runners that failed
+// together do not retry in lockstep.
+func withJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestCircuitBreakerOpensAndHalfOpens(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("aws.breaker_failures", 2)
+	viper.Set("aws.breaker_cooldown", "1m")
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker()
+	b.now = func() time.Time { return now }
+	key := breakerKey([]string{"guardduty", "list-detectors"}, &AIProfile{AWSProfile: "dev"})
+	denied := errors.New("exit status 254")
+	output := "An error occurred (AccessDeniedException) when calling the ListDetectors operation"
+
+	for i := 0; i < 2; i++ {
+		if err := b.allow(key); err != nil {
+			t.Fatalf("call %d should be allowed: %v", i, err)
+		}
+		b.record(key, denied, output)
+	}
+
+	err := b.allow(key)
+	if err == nil || !strings.Contains(err.Error(), "circuit open") || !errors.Is(err, denied) {
+		t.Fatalf("expected open breaker carrying the cached error, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.allow(key); err != nil {
+		t.Fatalf("expected a half-open probe after cooldown, got %v", err)
+	}
+	if err := b.allow(key); err == nil {
+		t.Fatal("only one probe may run while half-open")
+	}
+	b.record(key, nil, "")
+	if err := b.allow(key); err != nil {
+		t.Fatalf("expected the breaker to close after a successful probe, got %v", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresTransientErrors(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("aws.breaker_failures", 1)
+
+	b := newCircuitBreaker()
+	key := breakerKey([]string{"ec2"}, &AIProfile{AWSProfile: "dev"})
+	b.record(key, errors.New("exit status 255"), "ThrottlingException: Rate exceeded")
+	b.record(key, errors.New("exit status 255"), "InvalidParameterValue: bad filter")
+	if err := b.allow(key); err != nil {
+		t.Fatalf("transient and per-call errors must not trip the breaker: %v", err)
+	}
+}
+
+func TestAWSBreakerCooldown(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	if got := awsBreakerCooldown(); got != defaultBreakerCooldown {
+		t.Errorf("default cooldown = %v", got)
+	}
+	viper.Set("aws.breaker_cooldown", "45")
+	if got := awsBreakerCooldown(); got != 45*time.Second {
+		t.Errorf("numeric cooldown = %v, want 45s", got)
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		if got := withJitter(time.Second); got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("jittered delay %v outside [500ms, 1s]", got)
+		}
+	}
+}
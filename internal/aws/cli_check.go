@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minAWSCLIVersion is the oldest AWS CLI release that ships every service
+// subcommand executeAWSOperation uses (Q Business, DataZone, Pipes,
+// Scheduler and the rest).
+const minAWSCLIVersion = "2.15.0"
+
+const awsCLIInstallURL = "https://docs.aws.amazon.com/cli/latest/userguide/getting-started-install.html"
+
+// ErrAWSCLINotInstalled is returned by every operation when the aws binary
+// is not on PATH.
+var ErrAWSCLINotInstalled = errors.New("AWS CLI not found")
+
+// These are swapped out in tests.
+var (
+	awsCLILookPath = exec.LookPath
+	awsCLIVersion  = func(ctx context.Context, path string) (string, error) {
+		out, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+		return string(out), err
+	}
+)
+
+var (
+	awsCLICheckOnce sync.Once
+	awsCLICheckErr  error
+)
+
+var awsCLIVersionPattern = regexp.MustCompile(`aws-cli/(\d+(?:\.\d+)*)`)
+
+// ensureAWSCLI verifies once per process that the aws binary is installed,
+// returning ErrAWSCLINotInstalled with installation instructions when it is
+// not. An installed CLI older than minAWSCLIVersion only gets a warning on
+// stderr, since most operations still work.
+func ensureAWSCLI() error {
+	awsCLICheckOnce.Do(func() {
+		awsCLICheckErr = checkAWSCLI()
+	})
+	return awsCLICheckErr
+}
+
+func checkAWSCLI() error {
+	path, err := awsCLILookPath("aws")
+	if err != nil {
+		return fmt.Errorf("%w on PATH: install AWS CLI v2 (%s or newer) from %s", ErrAWSCLINotInstalled, minAWSCLIVersion, awsCLIInstallURL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := awsCLIVersion(ctx, path)
+	if err != nil {
+		// A CLI that cannot report its version may still run commands;
+		// leave the failure to the command itself.
+		return nil
+	}
+	if warning := awsCLIVersionWarning(out); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	return nil
+}
+
+// awsCLIVersionWarning returns a warning when the `aws --version` output
+// reports a CLI older than minAWSCLIVersion, or "" otherwise.
+func awsCLIVersionWarning(versionOutput string) string {
+	m := awsCLIVersionPattern.FindStringSubmatch(versionOutput)
+	if m == nil {
+		return ""
+	}
+	if compareVersions(m[1], minAWSCLIVersion) >= 0 {
+		return ""
+	}
+	return fmt.Sprintf("⚠️  AWS CLI %s is older than %s; operations on newer services may fail with \"invalid choice\". Upgrade: %s", m[1], minAWSCLIVersion, awsCLIInstallURL)
+}
+
+// compareVersions compares dotted numeric versions, treating missing parts
+// as 0. It returns -1, 0 or 1.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
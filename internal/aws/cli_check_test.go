@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEnsureAWSCLIMissing(t *testing.T) {
+	lookPath := awsCLILookPath
+	defer func() {
+		awsCLILookPath = lookPath
+		awsCLICheckOnce, awsCLICheckErr = sync.Once{}, nil
+	}()
+	awsCLICheckOnce, awsCLICheckErr = sync.Once{}, nil
+
+	lookups := 0
+	awsCLILookPath = func(string) (string, error) {
+		lookups++
+		return "", exec.ErrNotFound
+	}
+	for i := 0; i < 2; i++ {
+		err := ensureAWSCLI()
+		if !errors.Is(err, ErrAWSCLINotInstalled) || !strings.Contains(err.Error(), minAWSCLIVersion) {
+			t.Fatalf("err = %v, want ErrAWSCLINotInstalled with the minimum version", err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("expected the check to run once, ran %d times", lookups)
+	}
+
+	if _, err := (&Client{}).executeAWSOperation(context.Background(), "list_vpcs", map[string]interface{}{}, &AIProfile{}); !errors.Is(err, ErrAWSCLINotInstalled) {
+		t.Errorf("operation err = %v, want ErrAWSCLINotInstalled", err)
+	}
+}
+
+func TestAWSCLIVersionWarning(t *testing.T) {
+	for out, warn := range map[string]bool{
+		"aws-cli/2.9.19 Python/3.9.11 Linux/5.15 exe/x86_64.ubuntu.22 prompt/off": true,
+		"aws-cli/1.29.0 Python/3.11.4 Darwin/23.0.0 botocore/1.31.0":              true,
+		"aws-cli/2.15.0 Python/3.11.6 Linux/6.1 exe/x86_64.amzn.2023 prompt/off":  false,
+		"aws-cli/2.17.41 Python/3.11.9 Darwin/23.6.0 exe/x86_64 prompt/off":       false,
+		"unexpected output": false,
+	} {
+		if got := awsCLIVersionWarning(out) != ""; got != warn {
+			t.Errorf("awsCLIVersionWarning(%q) warned=%t, want %t", out, got, warn)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package aws
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// defaultMaxConcurrentCLI caps simultaneous aws subprocesses when
+// aws.max_concurrent_cli is unset.
+const defaultMaxConcurrentCLI = 8
+
+// cliSemaphore bounds how many aws subprocesses run at once. It is shared by
+// every Client in the process, so parallel discovery across several regions
+// still respects one cap.
+type cliSemaphore struct {
+	slots chan struct{}
+}
+
+func newCLISemaphore(size int) *cliSemaphore {
+	if size <= 0 {
+		size = defaultMaxConcurrentCLI
+	}
+	return &cliSemaphore{slots: make(chan struct{}, size)}
+}
+
+// acquire blocks until a slot is free or ctx is done, so a cancelled
+// investigation stops spawning new commands.
+func (s *cliSemaphore) acquire(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *cliSemaphore) release() {
+	<-s.slots
+}
+
+var (
+	awsCLISlotsOnce sync.Once
+	awsCLISlots     *cliSemaphore
+)
+
+// awsCLISemaphore returns the process-wide limiter, sized from
+// aws.max_concurrent_cli on first use.
+func awsCLISemaphore() *cliSemaphore {
+	awsCLISlotsOnce.Do(func() {
+		awsCLISlots = newCLISemaphore(awsCLIMaxConcurrent())
+	})
+	return awsCLISlots
+}
+
+// awsCLIMaxConcurrent reads aws.max_concurrent_cli, defaulting to
+// defaultMaxConcurrentCLI.
+func awsCLIMaxConcurrent() int {
+	if n := viper.GetInt("aws.max_concurrent_cli"); n > 0 {
+		return n
+	}
+	return defaultMaxConcurrentCLI
+}
@@ -24,10 +24,18 @@ import (
 	"github.com/spf13/viper"
 )
 
+// ExecFunc runs one AWS CLI command (args exclude the leading "aws") with the
+// credentials and region of profile and returns its output.
+type ExecFunc func(ctx context.Context, args []string, profile *AIProfile) (string, error)
+
 type Client struct {
 	cfg            aws.Config
 	profile        string
 	debug          bool
+	dryRun         bool
+	allowMutations bool
+	// execFunc replaces the AWS CLI subprocess when set; nil runs the real CLI.
+	execFunc       ExecFunc
 	ec2            *ec2.Client
 	ecs            *ecs.Client
 	iam            *iam.Client
@@ -47,6 +55,8 @@ func NewClient(ctx context.Context) (*Client, error) {
 
 	return &Client{
 		cfg:            cfg,
+		dryRun:         viper.GetBool("aws.dry_run"),
+		allowMutations: viper.GetBool("aws.allow_mutations"),
 		ec2:            ec2.NewFromConfig(cfg),
 		ecs:            ecs.NewFromConfig(cfg),
 		iam:            iam.NewFromConfig(cfg),
@@ -127,6 +137,8 @@ func NewClientWithProfileAndDebug(ctx context.Context, profile string, debug boo
 			cfg:            cfg,
 			profile:        profile,
 			debug:          debug,
+			dryRun:         viper.GetBool("aws.dry_run"),
+			allowMutations: viper.GetBool("aws.allow_mutations"),
 			ec2:            ec2.NewFromConfig(cfg),
 			ecs:            ecs.NewFromConfig(cfg),
 			iam:            iam.NewFromConfig(cfg),
@@ -158,6 +170,8 @@ func NewClientWithProfileAndDebug(ctx context.Context, profile string, debug boo
 		cfg:            cfg,
 		profile:        profile,
 		debug:          debug,
+		dryRun:         viper.GetBool("aws.dry_run"),
+		allowMutations: viper.GetBool("aws.allow_mutations"),
 		ec2:            ec2.NewFromConfig(cfg),
 		ecs:            ecs.NewFromConfig(cfg),
 		iam:            iam.NewFromConfig(cfg),
@@ -207,6 +221,8 @@ func NewClientWithCredentials(ctx context.Context, creds *BackendAWSCredentials,
 		cfg:            cfg,
 		profile:        "backend",
 		debug:          debug,
+		dryRun:         viper.GetBool("aws.dry_run"),
+		allowMutations: viper.GetBool("aws.allow_mutations"),
 		ec2:            ec2.NewFromConfig(cfg),
 		ecs:            ecs.NewFromConfig(cfg),
 		iam:            iam.NewFromConfig(cfg),
@@ -1122,6 +1138,15 @@ func (c *Client) GetAIProfiles() map[string]AIProfile {
 			if apiKeyEnv, ok := profileMap["api_key_env"].(string); ok {
 				profile.APIKeyEnv = apiKeyEnv
 			}
+			if decisionModel, ok := profileMap["decision_model"].(string); ok {
+				profile.DecisionModel = decisionModel
+			}
+			if analysisModel, ok := profileMap["analysis_model"].(string); ok {
+				profile.AnalysisModel = analysisModel
+			}
+			if partition, ok := profileMap["partition"].(string); ok {
+				profile.Partition = partition
+			}
 
 			profiles[name] = profile
 		}
@@ -1149,6 +1174,15 @@ func (c *Client) GetAIProfiles() map[string]AIProfile {
 				if apiKeyEnv, ok := providerMap["api_key_env"].(string); ok {
 					profile.APIKeyEnv = apiKeyEnv
 				}
+				if decisionModel, ok := providerMap["decision_model"].(string); ok {
+					profile.DecisionModel = decisionModel
+				}
+				if analysisModel, ok := providerMap["analysis_model"].(string); ok {
+					profile.AnalysisModel = analysisModel
+				}
+				if partition, ok := providerMap["partition"].(string); ok {
+					profile.Partition = partition
+				}
 
 				profiles[name] = profile
 			}
@@ -1212,6 +1246,24 @@ func (c *Client) execCLI(ctx context.Context, args []string) (string, error) {
 	return c.execAWSCLI(ctx, args, c.aiProfile())
 }
 
+// SetDryRun toggles dry-run mode. When enabled, AWS CLI commands are printed
+// instead of executed and an empty payload is returned.
+func (c *Client) SetDryRun(dryRun bool) {
+	c.dryRun = dryRun
+}
+
+// SetExecFunc routes every AWS CLI command through fn instead of the aws
+// binary, so tests can serve canned output. Passing nil restores the real CLI.
+// Dry-run mode still takes precedence.
+func (c *Client) SetExecFunc(fn ExecFunc) {
+	c.execFunc = fn
+}
+
+// DryRun reports whether the client is in dry-run mode.
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
+
 // ExecCLI exposes the CLI helper to other packages.
 func (c *Client) ExecCLI(ctx context.Context, args []string) (string, error) {
 	return c.execCLI(ctx, args)
@@ -0,0 +1,599 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+const envDiffMaxListed = 15
+
+// envResource is one resource as seen in one environment. Key matches the
+// same resource across environments: its name with environment words such
+// as "staging" or "prod" removed.
+type envResource struct {
+	Key   string
+	Name  string
+	Attrs map[string]string
+}
+
+// envDiscovery lists one service's resources in an environment.
+type envDiscovery struct {
+	Service string
+	CLIName string // for categorizeAWSError
+	Run     func(ctx context.Context, c *Client, profile *AIProfile) ([]envResource, error)
+}
+
+var envDiscoveries = []envDiscovery{
+	{Service: "Lambda functions", CLIName: "Lambda", Run: discoverEnvLambdas},
+	{Service: "ECS services", CLIName: "ECS", Run: discoverEnvECSServices},
+	{Service: "EC2 instances", CLIName: "EC2", Run: discoverEnvInstances},
+	{Service: "RDS instances", CLIName: "RDS", Run: discoverEnvRDS},
+	{Service: "Security groups", CLIName: "EC2", Run: discoverEnvSecurityGroups},
+}
+
+// envInventory is one environment's discovery result per service.
+type envInventory struct {
+	Resources map[string][]envResource
+	Errors    map[string]string
+}
+
+// envAliases are the words stripped from resource names when matching them
+// across environments, grouped so "stg" and "staging" count as one word.
+var envAliases = [][]string{
+	{"prod", "production", "prd", "live"},
+	{"staging", "stage", "stg"},
+	{"dev", "development"},
+	{"qa", "test", "testing"},
+	{"uat"},
+	{"sandbox"},
+}
+
+var envNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// compareEnvironments is the compare_environments operation: it runs the
+// same focused discovery (Lambda, ECS, EC2, RDS, security groups) against two
+// environments from infra.aws.environments (or two AWS CLI profiles) and
+// reports, per service, what exists in only one of them and which settings
+// differ for resources present in both.
+func (c *Client) compareEnvironments(ctx context.Context, input map[string]interface{}, base *AIProfile) (string, error) {
+	names := environmentParams(input)
+	if len(names) != 2 {
+		configured := configuredEnvironmentNames()
+		hint := "pass environments, e.g. [\"staging\", \"prod\"]"
+		if len(configured) > 0 {
+			hint += "; configured environments: " + strings.Join(configured, ", ")
+		}
+		return "", fmt.Errorf("compare_environments needs exactly two environments: %s", hint)
+	}
+
+	profiles := make([]*AIProfile, 2)
+	for i, name := range names {
+		profiles[i] = profileForEnvironment(base, name)
+	}
+
+	inventories := make([]envInventory, 2)
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			inventories[i] = c.discoverEnvironment(ctx, profiles[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("🔀 Environment diff: %s ↔ %s\n", names[0], names[1]))
+	out.WriteString("============================\n")
+	for i, name := range names {
+		resolved := resolvedProfile(profiles[i])
+		out.WriteString(fmt.Sprintf("%s: profile %s, region %s\n", name, resolved.AWSProfile, resolved.Region))
+	}
+
+	differences := 0
+	for _, d := range envDiscoveries {
+		differences += writeServiceDiff(&out, d.Service, names, inventories)
+	}
+	if differences == 0 {
+		out.WriteString(fmt.Sprintf("\n✅ No differences found between %s and %s\n", names[0], names[1]))
+	}
+	return out.String(), nil
+}
+
+// environmentParams reads the two environments to compare from
+// "environments" (a list or comma-separated string), "environment_a" and
+// "environment_b", or the configured environment names a query mentions.
+func environmentParams(input map[string]interface{}) []string {
+	var names []string
+	switch v := input["environments"].(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+	case []string:
+		names = append(names, v...)
+	case string:
+		names = strings.Split(v, ",")
+	}
+	if len(names) == 0 {
+		names = []string{getStringParam(input, "environment_a", ""), getStringParam(input, "environment_b", "")}
+	}
+
+	var cleaned []string
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			cleaned = append(cleaned, n)
+		}
+	}
+	if len(cleaned) > 0 {
+		return cleaned
+	}
+
+	query := strings.ToLower(getStringParam(input, "query", ""))
+	if query == "" {
+		return nil
+	}
+	type mention struct {
+		name string
+		at   int
+	}
+	var mentions []mention
+	for _, name := range configuredEnvironmentNames() {
+		if at := wordIndex(query, strings.ToLower(name)); at >= 0 {
+			mentions = append(mentions, mention{name, at})
+		}
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].at < mentions[j].at })
+	for _, m := range mentions {
+		cleaned = append(cleaned, m.name)
+	}
+	return cleaned
+}
+
+// wordIndex returns where word first appears in s as a whole word, or -1.
+func wordIndex(s, word string) int {
+	re, err := regexp.Compile(`\b` + regexp.QuoteMeta(word) + `\b`)
+	if err != nil {
+		return -1
+	}
+	if loc := re.FindStringIndex(s); loc != nil {
+		return loc[0]
+	}
+	return -1
+}
+
+func configuredEnvironmentNames() []string {
+	envs := viper.GetStringMap("infra.aws.environments")
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileForEnvironment returns base pointed at the named environment from
+// infra.aws.environments, or at the AWS CLI profile of that name when no such
+// environment is configured. The AWS profile is pinned so --profile and
+// AWS_PROFILE cannot collapse both sides of a comparison into one account.
+func profileForEnvironment(base *AIProfile, name string) *AIProfile {
+	env := AIProfile{}
+	if base != nil {
+		env = *base
+	}
+	env.AWSProfile = name
+	if p := strings.TrimSpace(viper.GetString(fmt.Sprintf("infra.aws.environments.%s.profile", name))); p != "" {
+		env.AWSProfile = p
+	}
+	if r := strings.TrimSpace(viper.GetString(fmt.Sprintf("infra.aws.environments.%s.region", name))); r != "" {
+		env.Region = r
+		env.regionPinned = true
+	}
+	env.profilePinned = true
+	return &env
+}
+
+func (c *Client) discoverEnvironment(ctx context.Context, profile *AIProfile) envInventory {
+	inv := envInventory{Resources: make(map[string][]envResource), Errors: make(map[string]string)}
+	for _, d := range envDiscoveries {
+		resources, err := d.Run(ctx, c, profile)
+		if err != nil {
+			inv.Errors[d.Service] = categorizeAWSError(err, d.CLIName)
+			continue
+		}
+		inv.Resources[d.Service] = resources
+	}
+	return inv
+}
+
+// writeServiceDiff writes one service's section and returns how many
+// differences it found.
+func writeServiceDiff(out *strings.Builder, service string, names []string, inventories []envInventory) int {
+	var problems []string
+	for i, inv := range inventories {
+		if msg, ok := inv.Errors[service]; ok {
+			problems = append(problems, fmt.Sprintf("  ❓ %s: %s", names[i], msg))
+		}
+	}
+	if len(problems) > 0 {
+		out.WriteString("\n" + service + "\n")
+		for _, p := range problems {
+			out.WriteString(p + "\n")
+		}
+		return 0
+	}
+
+	left, right := indexEnvResources(inventories[0].Resources[service]), indexEnvResources(inventories[1].Resources[service])
+	var onlyLeft, onlyRight, changed []string
+	identical := 0
+	for _, key := range sortedEnvKeys(left) {
+		r, ok := right[key]
+		if !ok {
+			onlyLeft = append(onlyLeft, left[key].Name)
+			continue
+		}
+		if diffs := diffEnvAttrs(left[key].Attrs, r.Attrs); len(diffs) > 0 {
+			label := left[key].Name
+			if r.Name != label {
+				label += " ↔ " + r.Name
+			}
+			changed = append(changed, fmt.Sprintf("%s: %s", label, strings.Join(diffs, "; ")))
+		} else {
+			identical++
+		}
+	}
+	for _, key := range sortedEnvKeys(right) {
+		if _, ok := left[key]; !ok {
+			onlyRight = append(onlyRight, right[key].Name)
+		}
+	}
+
+	differences := len(onlyLeft) + len(onlyRight) + len(changed)
+	if differences == 0 {
+		return 0
+	}
+	out.WriteString("\n" + service + "\n")
+	writeEnvList(out, "Only in "+names[0], onlyLeft)
+	writeEnvList(out, "Only in "+names[1], onlyRight)
+	if len(changed) > 0 {
+		out.WriteString(fmt.Sprintf("  Different (%s ↔ %s):\n", names[0], names[1]))
+		for i, line := range changed {
+			if i == envDiffMaxListed {
+				out.WriteString(fmt.Sprintf("    … %d more\n", len(changed)-i))
+				break
+			}
+			out.WriteString("    • " + line + "\n")
+		}
+	}
+	if identical > 0 {
+		out.WriteString(fmt.Sprintf("  %d identical\n", identical))
+	}
+	return differences
+}
+
+func writeEnvList(out *strings.Builder, label string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	if len(names) > envDiffMaxListed {
+		names = append(names[:envDiffMaxListed:envDiffMaxListed], fmt.Sprintf("… %d more", len(names)-envDiffMaxListed))
+	}
+	out.WriteString(fmt.Sprintf("  %s: %s\n", label, strings.Join(names, ", ")))
+}
+
+// indexEnvResources keys resources by Key. Resources sharing a key (several
+// instances of one Auto Scaling group, say) are merged, with differing
+// attribute values listed together.
+func indexEnvResources(resources []envResource) map[string]envResource {
+	index := make(map[string]envResource, len(resources))
+	counts := make(map[string]int)
+	for _, r := range resources {
+		counts[r.Key]++
+		existing, ok := index[r.Key]
+		if !ok {
+			index[r.Key] = envResource{Key: r.Key, Name: r.Name, Attrs: copyAttrs(r.Attrs)}
+			continue
+		}
+		for k, v := range r.Attrs {
+			if cur, ok := existing.Attrs[k]; !ok {
+				existing.Attrs[k] = v
+			} else if !containsValue(strings.Split(cur, " | "), v) {
+				values := append(strings.Split(cur, " | "), v)
+				sort.Strings(values)
+				existing.Attrs[k] = strings.Join(values, " | ")
+			}
+		}
+	}
+	for key, n := range counts {
+		if n > 1 {
+			index[key].Attrs["count"] = strconv.Itoa(n)
+		}
+	}
+	return index
+}
+
+func copyAttrs(attrs map[string]string) map[string]string {
+	copied := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		copied[k] = v
+	}
+	return copied
+}
+
+func containsValue(values []string, v string) bool {
+	for _, existing := range values {
+		if existing == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedEnvKeys(index map[string]envResource) []string {
+	keys := make([]string, 0, len(index))
+	for k := range index {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffEnvAttrs lists "attr a ↔ b" for every attribute that differs.
+func diffEnvAttrs(left, right map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, attrs := range []map[string]string{left, right} {
+		for k := range attrs {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []string
+	for _, k := range keys {
+		l, r := left[k], right[k]
+		if l == r {
+			continue
+		}
+		if l == "" {
+			l = "(none)"
+		}
+		if r == "" {
+			r = "(none)"
+		}
+		diffs = append(diffs, fmt.Sprintf("%s %s ↔ %s", k, l, r))
+	}
+	return diffs
+}
+
+// envResourceKey strips environment words from a resource name so
+// "orders-api-staging" and "orders-api-prod" match.
+func envResourceKey(name string) string {
+	parts := envNameSeparators.Split(strings.ToLower(name), -1)
+	kept := parts[:0]
+	for _, part := range parts {
+		if part != "" && !isEnvWord(part) {
+			kept = append(kept, part)
+		}
+	}
+	if len(kept) == 0 {
+		return strings.ToLower(name)
+	}
+	return strings.Join(kept, "-")
+}
+
+func isEnvWord(word string) bool {
+	for _, group := range envAliases {
+		for _, alias := range group {
+			if word == alias {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newEnvResource(name string, attrs map[string]string) envResource {
+	return envResource{Key: envResourceKey(name), Name: name, Attrs: attrs}
+}
+
+func discoverEnvLambdas(ctx context.Context, c *Client, profile *AIProfile) ([]envResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"lambda", "list-functions", "--output", "json",
+		"--query", "Functions[].{Name:FunctionName,Runtime:Runtime,Memory:MemorySize,Timeout:Timeout,Arch:Architectures[0]}"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var fns []struct {
+		Name    string `json:"Name"`
+		Runtime string `json:"Runtime"`
+		Memory  int    `json:"Memory"`
+		Timeout int    `json:"Timeout"`
+		Arch    string `json:"Arch"`
+	}
+	if err := json.Unmarshal([]byte(raw), &fns); err != nil {
+		return nil, fmt.Errorf("failed to parse Lambda functions: %w", err)
+	}
+	resources := make([]envResource, 0, len(fns))
+	for _, fn := range fns {
+		resources = append(resources, newEnvResource(fn.Name, map[string]string{
+			"runtime": fn.Runtime,
+			"memory":  fmt.Sprintf("%dMB", fn.Memory),
+			"timeout": fmt.Sprintf("%ds", fn.Timeout),
+			"arch":    fn.Arch,
+		}))
+	}
+	return resources, nil
+}
+
+func discoverEnvECSServices(ctx context.Context, c *Client, profile *AIProfile) ([]envResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"ecs", "list-clusters", "--output", "json", "--query", "clusterArns"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []string
+	if err := json.Unmarshal([]byte(raw), &clusters); err != nil {
+		return nil, fmt.Errorf("failed to parse ECS clusters: %w", err)
+	}
+
+	var resources []envResource
+	for _, cluster := range clusters {
+		raw, err := c.execAWSCLI(ctx, []string{"ecs", "list-services", "--cluster", cluster, "--output", "json", "--query", "serviceArns"}, profile)
+		if err != nil {
+			return nil, err
+		}
+		var arns []string
+		if err := json.Unmarshal([]byte(raw), &arns); err != nil {
+			return nil, fmt.Errorf("failed to parse ECS services: %w", err)
+		}
+		clusterName := cluster[strings.LastIndex(cluster, "/")+1:]
+		for start := 0; start < len(arns); start += 10 {
+			end := start + 10
+			if end > len(arns) {
+				end = len(arns)
+			}
+			args := append([]string{"ecs", "describe-services", "--cluster", cluster, "--services"}, arns[start:end]...)
+			args = append(args, "--output", "json", "--query", "services[].{Name:serviceName,Desired:desiredCount,LaunchType:launchType,TaskDefinition:taskDefinition}")
+			raw, err := c.execAWSCLI(ctx, args, profile)
+			if err != nil {
+				return nil, err
+			}
+			var services []struct {
+				Name           string `json:"Name"`
+				Desired        int    `json:"Desired"`
+				LaunchType     string `json:"LaunchType"`
+				TaskDefinition string `json:"TaskDefinition"`
+			}
+			if err := json.Unmarshal([]byte(raw), &services); err != nil {
+				return nil, fmt.Errorf("failed to parse ECS services: %w", err)
+			}
+			for _, svc := range services {
+				// The family names the task; revisions always differ between accounts.
+				family := svc.TaskDefinition[strings.LastIndex(svc.TaskDefinition, "/")+1:]
+				if i := strings.LastIndex(family, ":"); i > 0 {
+					family = family[:i]
+				}
+				r := newEnvResource(clusterName+"/"+svc.Name, map[string]string{
+					"desired":     strconv.Itoa(svc.Desired),
+					"launch type": svc.LaunchType,
+					"task family": envResourceKey(family),
+				})
+				r.Key = envResourceKey(clusterName) + "/" + envResourceKey(svc.Name)
+				resources = append(resources, r)
+			}
+		}
+	}
+	return resources, nil
+}
+
+func discoverEnvInstances(ctx context.Context, c *Client, profile *AIProfile) ([]envResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-instances", "--filters", "Name=instance-state-name,Values=running",
+		"--output", "json", "--query", "Reservations[].Instances[].{Name:Tags[?Key=='Name']|[0].Value,Type:InstanceType,Arch:Architecture}"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var instances []struct {
+		Name string `json:"Name"`
+		Type string `json:"Type"`
+		Arch string `json:"Arch"`
+	}
+	if err := json.Unmarshal([]byte(raw), &instances); err != nil {
+		return nil, fmt.Errorf("failed to parse EC2 instances: %w", err)
+	}
+	var resources []envResource
+	for _, inst := range instances {
+		// Unnamed instances cannot be matched across environments.
+		if inst.Name == "" {
+			continue
+		}
+		resources = append(resources, newEnvResource(inst.Name, map[string]string{"type": inst.Type, "arch": inst.Arch}))
+	}
+	return resources, nil
+}
+
+func discoverEnvRDS(ctx context.Context, c *Client, profile *AIProfile) ([]envResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"rds", "describe-db-instances", "--output", "json",
+		"--query", "DBInstances[].{Id:DBInstanceIdentifier,Engine:Engine,Version:EngineVersion,Class:DBInstanceClass,MultiAZ:MultiAZ,Storage:AllocatedStorage}"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var dbs []struct {
+		ID      string `json:"Id"`
+		Engine  string `json:"Engine"`
+		Version string `json:"Version"`
+		Class   string `json:"Class"`
+		MultiAZ bool   `json:"MultiAZ"`
+		Storage int    `json:"Storage"`
+	}
+	if err := json.Unmarshal([]byte(raw), &dbs); err != nil {
+		return nil, fmt.Errorf("failed to parse RDS instances: %w", err)
+	}
+	resources := make([]envResource, 0, len(dbs))
+	for _, db := range dbs {
+		resources = append(resources, newEnvResource(db.ID, map[string]string{
+			"engine":   db.Engine + " " + db.Version,
+			"class":    db.Class,
+			"multi-az": strconv.FormatBool(db.MultiAZ),
+			"storage":  fmt.Sprintf("%dGB", db.Storage),
+		}))
+	}
+	return resources, nil
+}
+
+func discoverEnvSecurityGroups(ctx context.Context, c *Client, profile *AIProfile) ([]envResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-security-groups", "--output", "json",
+		"--query", "SecurityGroups[].{Name:GroupName,Ingress:IpPermissions}"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Name    string         `json:"Name"`
+		Ingress []sgPermission `json:"Ingress"`
+	}
+	if err := json.Unmarshal([]byte(raw), &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse security groups: %w", err)
+	}
+	resources := make([]envResource, 0, len(groups))
+	for _, g := range groups {
+		resources = append(resources, newEnvResource(g.Name, map[string]string{"ingress": ingressSummary(g.Ingress)}))
+	}
+	return resources, nil
+}
+
+// ingressSummary renders ingress rules as sorted "tcp/443 from 0.0.0.0/0"
+// entries. Rules referencing another group say "from a security group", since
+// group IDs never match across accounts.
+func ingressSummary(perms []sgPermission) string {
+	var rules []string
+	for _, p := range perms {
+		proto, port := p.IpProtocol, ""
+		if proto == "-1" {
+			proto = "all"
+		} else if p.FromPort != nil && p.ToPort != nil {
+			port = "/" + portSpan(*p.FromPort, *p.ToPort)
+		}
+		for _, r := range p.IpRanges {
+			rules = append(rules, fmt.Sprintf("%s%s from %s", proto, port, r.CidrIp))
+		}
+		if len(p.UserIdGroupPairs) > 0 {
+			rules = append(rules, fmt.Sprintf("%s%s from a security group", proto, port))
+		}
+	}
+	if len(rules) == 0 {
+		return "none"
+	}
+	sort.Strings(rules)
+	return strings.Join(uniqueStrings(rules), ", ")
+}
@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func envFixtures(lambdas, rds, groups string) *fakeCLI {
+	f := newFakeCLI()
+	f.fixtures["lambda list-functions"] = lambdas
+	f.fixtures["ecs list-clusters"] = `[]`
+	f.fixtures["ec2 describe-instances"] = `[]`
+	f.fixtures["rds describe-db-instances"] = rds
+	f.fixtures["ec2 describe-security-groups"] = groups
+	return f
+}
+
+func TestCompareEnvironments(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("infra.aws.environments", map[string]interface{}{
+		"staging": map[string]interface{}{"profile": "acme-staging", "region": "us-east-1"},
+		"prod":    map[string]interface{}{"profile": "acme-prod", "region": "us-east-1"},
+	})
+	// --profile must not collapse both sides into one account.
+	viper.Set("aws.profile_override", "acme-staging")
+
+	envs := map[string]*fakeCLI{
+		"acme-staging": envFixtures(
+			`[{"Name": "orders-api-staging", "Runtime": "nodejs18.x", "Memory": 512, "Timeout": 30, "Arch": "x86_64"},
+			  {"Name": "debug-tools-staging", "Runtime": "python3.12", "Memory": 128, "Timeout": 3, "Arch": "arm64"}]`,
+			`[{"Id": "orders-stg", "Engine": "postgres", "Version": "15.4", "Class": "db.t3.medium", "MultiAZ": false, "Storage": 100}]`,
+			`[{"Name": "web", "Ingress": [{"IpProtocol": "tcp", "FromPort": 443, "ToPort": 443, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]}]`),
+		"acme-prod": envFixtures(
+			`[{"Name": "orders-api-prod", "Runtime": "nodejs20.x", "Memory": 1024, "Timeout": 30, "Arch": "x86_64"}]`,
+			`[{"Id": "orders-prod", "Engine": "postgres", "Version": "15.4", "Class": "db.r6g.large", "MultiAZ": true, "Storage": 100}]`,
+			`[{"Name": "web", "Ingress": [{"IpProtocol": "tcp", "FromPort": 443, "ToPort": 443, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]}]`),
+	}
+	c := &Client{}
+	c.SetExecFunc(func(ctx context.Context, args []string, profile *AIProfile) (string, error) {
+		return envs[profile.AWSProfile].exec(ctx, args, profile)
+	})
+
+	out, err := c.executeAWSOperation(context.Background(), "compare_environments", map[string]interface{}{"query": "what's different between staging and prod?"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"🔀 Environment diff: staging ↔ prod",
+		"staging: profile acme-staging, region us-east-1",
+		"prod: profile acme-prod, region us-east-1",
+		"  Only in staging: debug-tools-staging",
+		"    • orders-api-staging ↔ orders-api-prod: memory 512MB ↔ 1024MB; runtime nodejs18.x ↔ nodejs20.x",
+		"    • orders-stg ↔ orders-prod: class db.t3.medium ↔ db.r6g.large; multi-az false ↔ true",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Security groups") {
+		t.Errorf("identical security groups should not be listed:\n%s", out)
+	}
+}
+
+func TestCompareEnvironmentsNeedsTwo(t *testing.T) {
+	_, err := newFakeClient(newFakeCLI()).executeAWSOperation(context.Background(), "compare_environments", map[string]interface{}{"environments": "prod"}, &AIProfile{})
+	if err == nil || !strings.Contains(err.Error(), "exactly two environments") {
+		t.Errorf("err = %v, want a request for two environments", err)
+	}
+}
+
+func TestEnvResourceKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"orders-api-staging": "orders-api",
+		"prod_orders_api":    "orders-api",
+		"Orders.API.stg":     "orders-api",
+		"prod":               "prod",
+	} {
+		if got := envResourceKey(name); got != want {
+			t.Errorf("envResourceKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,787 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// connectivityInternet is the target that means "anywhere outside the VPC".
+	connectivityInternet = "internet"
+	// connectivityEphemeralFrom..To is the Linux ephemeral range return
+	// traffic comes back on; NACLs are stateless and must allow it.
+	connectivityEphemeralFrom = 32768
+	connectivityEphemeralTo   = 65535
+	connectivityDefaultPort   = 443
+	// connectivityMaxTargetENIs caps the network interfaces inspected for a
+	// security group target.
+	connectivityMaxTargetENIs = 5
+)
+
+var (
+	connectivitySourcePattern = regexp.MustCompile(`\b(i-[0-9a-f]{8,17}|eni-[0-9a-f]{8,17})\b`)
+	connectivityTargetPattern = regexp.MustCompile(`\b(sg-[0-9a-f]{8,17}|[a-z0-9-]+\.[a-z0-9]+\.[a-z0-9-]+\.rds\.amazonaws\.com)\b`)
+)
+
+// connectivityEndpoint is one side of the path being analyzed.
+type connectivityEndpoint struct {
+	Label     string
+	VpcID     string
+	SubnetIDs []string
+	// CIDRs are the addresses traffic is sent to or from: a /32 per private
+	// IP, a database's subnet CIDRs, or 0.0.0.0/0 for the internet.
+	CIDRs    []string
+	GroupIDs []string
+	PublicIP string
+	Internet bool
+}
+
+// connectivityHop is one check along the path, in the order traffic meets it.
+type connectivityHop struct {
+	Name    string
+	Allowed bool
+	// Unknown is set when the hop could not be checked; it does not block.
+	Unknown bool
+	Detail  string
+}
+
+type sgPermission struct {
+	IpProtocol string `json:"IpProtocol"`
+	FromPort   *int   `json:"FromPort"`
+	ToPort     *int   `json:"ToPort"`
+	IpRanges   []struct {
+		CidrIp string `json:"CidrIp"`
+	} `json:"IpRanges"`
+	Ipv6Ranges []struct {
+		CidrIpv6 string `json:"CidrIpv6"`
+	} `json:"Ipv6Ranges"`
+	UserIdGroupPairs []struct {
+		GroupID string `json:"GroupId"`
+	} `json:"UserIdGroupPairs"`
+}
+
+type securityGroupRules struct {
+	GroupID             string         `json:"GroupId"`
+	VpcID               string         `json:"VpcId"`
+	IpPermissions       []sgPermission `json:"IpPermissions"`
+	IpPermissionsEgress []sgPermission `json:"IpPermissionsEgress"`
+}
+
+type naclEntry struct {
+	RuleNumber int    `json:"RuleNumber"`
+	Protocol   string `json:"Protocol"`
+	RuleAction string `json:"RuleAction"`
+	Egress     bool   `json:"Egress"`
+	CidrBlock  string `json:"CidrBlock"`
+	PortRange  *struct {
+		From int `json:"From"`
+		To   int `json:"To"`
+	} `json:"PortRange"`
+}
+
+type routeTable struct {
+	RouteTableID string `json:"RouteTableId"`
+	Associations []struct {
+		Main     bool   `json:"Main"`
+		SubnetID string `json:"SubnetId"`
+	} `json:"Associations"`
+	Routes []vpcRoute `json:"Routes"`
+}
+
+type vpcRoute struct {
+	DestinationCidrBlock   string `json:"DestinationCidrBlock"`
+	GatewayID              string `json:"GatewayId"`
+	NatGatewayID           string `json:"NatGatewayId"`
+	TransitGatewayID       string `json:"TransitGatewayId"`
+	VpcPeeringConnectionID string `json:"VpcPeeringConnectionId"`
+	NetworkInterfaceID     string `json:"NetworkInterfaceId"`
+	State                  string `json:"State"`
+}
+
+func (r vpcRoute) target() string {
+	for _, id := range []string{r.GatewayID, r.NatGatewayID, r.TransitGatewayID, r.VpcPeeringConnectionID, r.NetworkInterfaceID} {
+		if id != "" {
+			return id
+		}
+	}
+	return "unknown target"
+}
+
+// analyzeConnectivity is the analyze_connectivity operation: given a source
+// instance or ENI and a target (the internet, an RDS instance, or a security
+// group) it walks the path traffic takes — source security groups, source
+// NACL, route table and NAT gateway, then the target's NACL and security
+// groups, and the stateless return path — and reports the first hop that
+// blocks it.
+func (c *Client) analyzeConnectivity(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	sourceRef := getStringParam(input, "source", getStringParam(input, "instance_id", ""))
+	if sourceRef == "" {
+		sourceRef = connectivitySourcePattern.FindString(getStringParam(input, "query", ""))
+	}
+	if sourceRef == "" {
+		return "", fmt.Errorf("source parameter required (an instance ID, ENI ID or instance Name tag)")
+	}
+	// Without a target, use a security group or RDS endpoint the query
+	// names, and otherwise the internet.
+	targetRef := strings.TrimSpace(getStringParam(input, "target", ""))
+	if targetRef == "" {
+		targetRef = connectivityTargetPattern.FindString(strings.ToLower(getStringParam(input, "query", "")))
+	}
+	port, portSet := intParam(input, "port")
+
+	source, err := c.connectivitySource(ctx, sourceRef, profile)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not resolve source %s: %s", sourceRef, categorizeAWSError(err, "EC2")), nil
+	}
+	target, targetPort, err := c.connectivityTarget(ctx, targetRef, profile)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not resolve target %s: %s", targetRef, categorizeAWSError(err, "EC2")), nil
+	}
+	if !portSet || port <= 0 {
+		port = targetPort
+	}
+
+	groups, groupErr := c.describeSecurityGroupRules(ctx, append(append([]string{}, source.GroupIDs...), target.GroupIDs...), profile)
+
+	var hops []connectivityHop
+	hops = append(hops, securityGroupHop("Source security group egress", source.GroupIDs, groups, groupErr, true, port, target.CIDRs, target.GroupIDs))
+	hops = append(hops, c.naclHops(ctx, "Source subnet NACL outbound", source.SubnetIDs, true, port, port, target.CIDRs, profile)...)
+	hops = append(hops, c.routeHop(ctx, source, target, profile))
+	if !target.Internet {
+		hops = append(hops, c.naclHops(ctx, "Target subnet NACL inbound", target.SubnetIDs, false, port, port, source.CIDRs, profile)...)
+		hops = append(hops, securityGroupHop("Target security group ingress", target.GroupIDs, groups, groupErr, false, port, source.CIDRs, source.GroupIDs))
+		hops = append(hops, c.naclHops(ctx, "Target subnet NACL outbound (return traffic)", target.SubnetIDs, true, connectivityEphemeralFrom, connectivityEphemeralTo, source.CIDRs, profile)...)
+	}
+	hops = append(hops, c.naclHops(ctx, "Source subnet NACL inbound (return traffic)", source.SubnetIDs, false, connectivityEphemeralFrom, connectivityEphemeralTo, target.CIDRs, profile)...)
+
+	return formatConnectivity(source, target, port, hops), nil
+}
+
+func formatConnectivity(source, target *connectivityEndpoint, port int, hops []connectivityHop) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("🔌 Connectivity: %s → %s on tcp/%d\n", source.Label, target.Label, port))
+	out.WriteString("============================\n")
+	var blocked *connectivityHop
+	for i := range hops {
+		hop := hops[i]
+		icon := "✅"
+		switch {
+		case hop.Unknown:
+			icon = "⚠️ "
+		case !hop.Allowed:
+			icon = "❌"
+			if blocked == nil {
+				blocked = &hops[i]
+			}
+		}
+		out.WriteString(fmt.Sprintf("%s %d. %s: %s\n", icon, i+1, hop.Name, hop.Detail))
+	}
+	out.WriteString("\n")
+	if blocked != nil {
+		out.WriteString(fmt.Sprintf("🚫 Traffic is blocked at: %s — %s\n", blocked.Name, blocked.Detail))
+	} else {
+		out.WriteString(fmt.Sprintf("✅ No blocking hop found: security groups, NACLs and routes allow tcp/%d from %s to %s. If it still fails, check the target is listening and DNS resolves to the expected address.\n", port, source.Label, target.Label))
+	}
+	return out.String()
+}
+
+// connectivitySource resolves an instance ID, ENI ID or instance Name tag.
+func (c *Client) connectivitySource(ctx context.Context, ref string, profile *AIProfile) (*connectivityEndpoint, error) {
+	if strings.HasPrefix(ref, "eni-") {
+		enis, err := c.describeNetworkInterfaces(ctx, []string{"--network-interface-ids", ref}, profile)
+		if err != nil {
+			return nil, err
+		}
+		if len(enis) == 0 {
+			return nil, fmt.Errorf("network interface %s not found", ref)
+		}
+		eni := enis[0]
+		return &connectivityEndpoint{
+			Label:     fmt.Sprintf("%s (%s)", eni.NetworkInterfaceID, eni.PrivateIPAddress),
+			VpcID:     eni.VpcID,
+			SubnetIDs: []string{eni.SubnetID},
+			CIDRs:     []string{eni.PrivateIPAddress + "/32"},
+			GroupIDs:  eni.groupIDs(),
+			PublicIP:  eni.Association.PublicIP,
+		}, nil
+	}
+
+	args := []string{"ec2", "describe-instances", "--output", "json"}
+	if strings.HasPrefix(ref, "i-") {
+		args = append(args, "--instance-ids", ref)
+	} else {
+		args = append(args, "--filters", "Name=tag:Name,Values="+ref, "Name=instance-state-name,Values=running")
+	}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Reservations []struct {
+			Instances []struct {
+				InstanceID       string `json:"InstanceId"`
+				SubnetID         string `json:"SubnetId"`
+				VpcID            string `json:"VpcId"`
+				PrivateIPAddress string `json:"PrivateIpAddress"`
+				PublicIPAddress  string `json:"PublicIpAddress"`
+				SecurityGroups   []struct {
+					GroupID string `json:"GroupId"`
+				} `json:"SecurityGroups"`
+			} `json:"Instances"`
+		} `json:"Reservations"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse instances: %w", err)
+	}
+	for _, r := range resp.Reservations {
+		for _, inst := range r.Instances {
+			ep := &connectivityEndpoint{
+				Label:     fmt.Sprintf("instance %s (%s)", inst.InstanceID, inst.PrivateIPAddress),
+				VpcID:     inst.VpcID,
+				SubnetIDs: []string{inst.SubnetID},
+				CIDRs:     []string{inst.PrivateIPAddress + "/32"},
+				PublicIP:  inst.PublicIPAddress,
+			}
+			for _, g := range inst.SecurityGroups {
+				ep.GroupIDs = append(ep.GroupIDs, g.GroupID)
+			}
+			return ep, nil
+		}
+	}
+	return nil, fmt.Errorf("no instance matches %s", ref)
+}
+
+// connectivityTarget resolves "internet", a security group ID, or an RDS
+// instance identifier or endpoint, returning the port it listens on by
+// default.
+func (c *Client) connectivityTarget(ctx context.Context, ref string, profile *AIProfile) (*connectivityEndpoint, int, error) {
+	switch {
+	case ref == "" || strings.EqualFold(ref, connectivityInternet) || ref == "0.0.0.0/0":
+		return &connectivityEndpoint{Label: "the internet", CIDRs: []string{"0.0.0.0/0"}, Internet: true}, connectivityDefaultPort, nil
+
+	case strings.HasPrefix(ref, "sg-"):
+		target := &connectivityEndpoint{Label: "security group " + ref, GroupIDs: []string{ref}}
+		enis, err := c.describeNetworkInterfaces(ctx, []string{"--filters", "Name=group-id,Values=" + ref}, profile)
+		if err != nil {
+			return nil, 0, err
+		}
+		seen := map[string]bool{}
+		for i, eni := range enis {
+			if i == connectivityMaxTargetENIs {
+				break
+			}
+			target.VpcID = eni.VpcID
+			target.CIDRs = append(target.CIDRs, eni.PrivateIPAddress+"/32")
+			if !seen[eni.SubnetID] {
+				seen[eni.SubnetID] = true
+				target.SubnetIDs = append(target.SubnetIDs, eni.SubnetID)
+			}
+		}
+		if len(target.CIDRs) == 0 {
+			return nil, 0, fmt.Errorf("no network interfaces use %s", ref)
+		}
+		return target, connectivityDefaultPort, nil
+	}
+
+	// An RDS endpoint's first label is the instance identifier.
+	identifier := ref
+	if strings.Contains(ref, ".rds.amazonaws.com") {
+		identifier = strings.SplitN(ref, ".", 2)[0]
+	}
+	raw, err := c.execAWSCLI(ctx, []string{"rds", "describe-db-instances", "--db-instance-identifier", identifier, "--output", "json"}, profile)
+	if err != nil {
+		return nil, 0, err
+	}
+	var resp struct {
+		DBInstances []struct {
+			DBInstanceIdentifier string `json:"DBInstanceIdentifier"`
+			Engine               string `json:"Engine"`
+			Endpoint             struct {
+				Port int `json:"Port"`
+			} `json:"Endpoint"`
+			VpcSecurityGroups []struct {
+				VpcSecurityGroupID string `json:"VpcSecurityGroupId"`
+			} `json:"VpcSecurityGroups"`
+			DBSubnetGroup struct {
+				VpcID   string `json:"VpcId"`
+				Subnets []struct {
+					SubnetIdentifier string `json:"SubnetIdentifier"`
+				} `json:"Subnets"`
+			} `json:"DBSubnetGroup"`
+		} `json:"DBInstances"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse RDS instance: %w", err)
+	}
+	if len(resp.DBInstances) == 0 {
+		return nil, 0, fmt.Errorf("RDS instance %s not found", identifier)
+	}
+	db := resp.DBInstances[0]
+	target := &connectivityEndpoint{
+		Label: fmt.Sprintf("RDS %s (%s)", db.DBInstanceIdentifier, db.Engine),
+		VpcID: db.DBSubnetGroup.VpcID,
+	}
+	for _, g := range db.VpcSecurityGroups {
+		target.GroupIDs = append(target.GroupIDs, g.VpcSecurityGroupID)
+	}
+	for _, s := range db.DBSubnetGroup.Subnets {
+		target.SubnetIDs = append(target.SubnetIDs, s.SubnetIdentifier)
+	}
+	// The instance can sit in (or fail over to) any subnet of its group, so
+	// every subnet's CIDR has to be reachable.
+	if len(target.SubnetIDs) > 0 {
+		raw, err := c.execAWSCLI(ctx, append([]string{"ec2", "describe-subnets", "--output", "json", "--subnet-ids"}, target.SubnetIDs...), profile)
+		if err != nil {
+			return nil, 0, err
+		}
+		var subnets struct {
+			Subnets []struct {
+				CidrBlock string `json:"CidrBlock"`
+			} `json:"Subnets"`
+		}
+		if err := json.Unmarshal([]byte(raw), &subnets); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse subnets: %w", err)
+		}
+		for _, s := range subnets.Subnets {
+			target.CIDRs = append(target.CIDRs, s.CidrBlock)
+		}
+	}
+	return target, db.Endpoint.Port, nil
+}
+
+type networkInterface struct {
+	NetworkInterfaceID string `json:"NetworkInterfaceId"`
+	SubnetID           string `json:"SubnetId"`
+	VpcID              string `json:"VpcId"`
+	PrivateIPAddress   string `json:"PrivateIpAddress"`
+	Groups             []struct {
+		GroupID string `json:"GroupId"`
+	} `json:"Groups"`
+	Association struct {
+		PublicIP string `json:"PublicIp"`
+	} `json:"Association"`
+}
+
+func (n networkInterface) groupIDs() []string {
+	ids := make([]string, 0, len(n.Groups))
+	for _, g := range n.Groups {
+		ids = append(ids, g.GroupID)
+	}
+	return ids
+}
+
+func (c *Client) describeNetworkInterfaces(ctx context.Context, selector []string, profile *AIProfile) ([]networkInterface, error) {
+	raw, err := c.execAWSCLI(ctx, append([]string{"ec2", "describe-network-interfaces", "--output", "json"}, selector...), profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		NetworkInterfaces []networkInterface `json:"NetworkInterfaces"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse network interfaces: %w", err)
+	}
+	return resp.NetworkInterfaces, nil
+}
+
+func (c *Client) describeSecurityGroupRules(ctx context.Context, ids []string, profile *AIProfile) (map[string]securityGroupRules, error) {
+	groups := map[string]securityGroupRules{}
+	ids = uniqueStrings(ids)
+	if len(ids) == 0 {
+		return groups, nil
+	}
+	raw, err := c.execAWSCLI(ctx, append([]string{"ec2", "describe-security-groups", "--output", "json", "--group-ids"}, ids...), profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		SecurityGroups []securityGroupRules `json:"SecurityGroups"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse security groups: %w", err)
+	}
+	for _, g := range resp.SecurityGroups {
+		groups[g.GroupID] = g
+	}
+	return groups, nil
+}
+
+// securityGroupHop checks that at least one of groupIDs allows tcp/port to
+// (egress) or from (ingress) every peer CIDR, or to one of peerGroups.
+// Security groups are stateful, so there is no return-path check.
+func securityGroupHop(name string, groupIDs []string, groups map[string]securityGroupRules, groupErr error, egress bool, port int, peerCIDRs, peerGroups []string) connectivityHop {
+	hop := connectivityHop{Name: fmt.Sprintf("%s (%s)", name, strings.Join(groupIDs, ", "))}
+	if groupErr != nil {
+		hop.Unknown = true
+		hop.Detail = "could not read rules: " + categorizeAWSError(groupErr, "EC2")
+		return hop
+	}
+	if len(groupIDs) == 0 {
+		hop.Unknown = true
+		hop.Detail = "no security groups found"
+		return hop
+	}
+	direction := "from"
+	if egress {
+		direction = "to"
+	}
+
+	var allowed []string
+	for _, peer := range peerCIDRs {
+		rule, ok := securityGroupsAllow(groupIDs, groups, egress, port, peer, peerGroups)
+		if !ok {
+			hop.Detail = fmt.Sprintf("no rule allows tcp/%d %s %s", port, direction, peer)
+			if len(peerGroups) > 0 {
+				hop.Detail += fmt.Sprintf(" or %s", strings.Join(peerGroups, ", "))
+			}
+			if egress {
+				hop.Detail += "; add an outbound rule"
+			} else {
+				hop.Detail += "; add an inbound rule referencing the source security group"
+			}
+			return hop
+		}
+		allowed = append(allowed, rule)
+	}
+	hop.Allowed = true
+	hop.Detail = strings.Join(uniqueStrings(allowed), "; ")
+	return hop
+}
+
+// securityGroupsAllow returns a description of the first rule across
+// groupIDs that allows tcp/port to or from peer (a CIDR) or one of
+// peerGroups.
+func securityGroupsAllow(groupIDs []string, groups map[string]securityGroupRules, egress bool, port int, peer string, peerGroups []string) (string, bool) {
+	for _, id := range groupIDs {
+		g, ok := groups[id]
+		if !ok {
+			continue
+		}
+		perms := g.IpPermissions
+		if egress {
+			perms = g.IpPermissionsEgress
+		}
+		for _, p := range perms {
+			if !p.coversPort(port) {
+				continue
+			}
+			for _, r := range p.IpRanges {
+				if cidrCovers(r.CidrIp, peer) {
+					return fmt.Sprintf("%s allows %s %s", id, p.describe(), r.CidrIp), true
+				}
+			}
+			for _, pair := range p.UserIdGroupPairs {
+				for _, pg := range peerGroups {
+					if pair.GroupID == pg {
+						return fmt.Sprintf("%s allows %s %s", id, p.describe(), pg), true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func (p sgPermission) coversPort(port int) bool {
+	switch p.IpProtocol {
+	case "-1":
+		return true
+	case "tcp", "6":
+		if p.FromPort == nil || p.ToPort == nil {
+			return true
+		}
+		return port >= *p.FromPort && port <= *p.ToPort
+	}
+	return false
+}
+
+func (p sgPermission) describe() string {
+	if p.IpProtocol == "-1" {
+		return "all traffic with"
+	}
+	if p.FromPort == nil || p.ToPort == nil || *p.FromPort == *p.ToPort {
+		if p.FromPort != nil {
+			return fmt.Sprintf("tcp/%d with", *p.FromPort)
+		}
+		return "tcp with"
+	}
+	return fmt.Sprintf("tcp/%d-%d with", *p.FromPort, *p.ToPort)
+}
+
+// naclHops evaluates the NACL of each subnet for tcp traffic on ports
+// from..to to or from every peer CIDR. NACLs are stateless, so callers
+// check the return path on the ephemeral range separately.
+func (c *Client) naclHops(ctx context.Context, name string, subnetIDs []string, egress bool, from, to int, peerCIDRs []string, profile *AIProfile) []connectivityHop {
+	var hops []connectivityHop
+	for _, subnet := range uniqueStrings(subnetIDs) {
+		hop := connectivityHop{Name: fmt.Sprintf("%s (%s)", name, subnet)}
+		raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-network-acls", "--filters", "Name=association.subnet-id,Values=" + subnet, "--output", "json"}, profile)
+		var resp struct {
+			NetworkAcls []struct {
+				NetworkACLID string      `json:"NetworkAclId"`
+				Entries      []naclEntry `json:"Entries"`
+			} `json:"NetworkAcls"`
+		}
+		if err == nil {
+			err = json.Unmarshal([]byte(raw), &resp)
+		}
+		if err != nil || len(resp.NetworkAcls) == 0 {
+			hop.Unknown = true
+			hop.Detail = "could not read the subnet's network ACL"
+			if err != nil {
+				hop.Detail += ": " + categorizeAWSError(err, "EC2")
+			}
+			hops = append(hops, hop)
+			continue
+		}
+		acl := resp.NetworkAcls[0]
+		hop.Name = fmt.Sprintf("%s (%s, %s)", name, acl.NetworkACLID, subnet)
+		hop.Allowed = true
+		direction := "from"
+		if egress {
+			direction = "to"
+		}
+		var allowed []string
+		for _, peer := range peerCIDRs {
+			entry, ok := naclVerdict(acl.Entries, egress, from, to, peer)
+			desc := fmt.Sprintf("tcp/%s %s %s", portSpan(from, to), direction, peer)
+			if !ok {
+				hop.Allowed = false
+				if entry != nil {
+					hop.Detail = fmt.Sprintf("rule %d denies %s", entry.RuleNumber, desc)
+				} else {
+					hop.Detail = fmt.Sprintf("no rule allows %s (default deny)", desc)
+				}
+				break
+			}
+			allowed = append(allowed, fmt.Sprintf("rule %d allows %s", entry.RuleNumber, desc))
+		}
+		if hop.Allowed {
+			hop.Detail = strings.Join(allowed, "; ")
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// naclVerdict evaluates entries in rule-number order and returns the entry
+// that decides traffic on ports from..to with peer, and whether it is
+// allowed. A deny decides as soon as it overlaps the traffic; an allow must
+// cover all of it. A nil entry means nothing matched (the implicit deny).
+func naclVerdict(entries []naclEntry, egress bool, from, to int, peer string) (*naclEntry, bool) {
+	sorted := append([]naclEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].RuleNumber < sorted[j].RuleNumber })
+	for i := range sorted {
+		e := &sorted[i]
+		if e.Egress != egress || e.CidrBlock == "" || (e.Protocol != "-1" && e.Protocol != "6") {
+			continue
+		}
+		ruleFrom, ruleTo := 0, 65535
+		if e.Protocol != "-1" && e.PortRange != nil {
+			ruleFrom, ruleTo = e.PortRange.From, e.PortRange.To
+		}
+		if e.RuleAction == "deny" {
+			if ruleFrom <= to && from <= ruleTo && cidrOverlaps(e.CidrBlock, peer) {
+				return e, false
+			}
+			continue
+		}
+		if ruleFrom <= from && to <= ruleTo && cidrCovers(e.CidrBlock, peer) {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// routeHop checks the source subnet's route table for a route to each
+// target CIDR, following a default route through its NAT gateway.
+func (c *Client) routeHop(ctx context.Context, source, target *connectivityEndpoint, profile *AIProfile) connectivityHop {
+	hop := connectivityHop{Name: "Source route table"}
+	if source.VpcID == "" || len(source.SubnetIDs) == 0 {
+		hop.Unknown = true
+		hop.Detail = "source subnet unknown"
+		return hop
+	}
+	tables, err := c.describeRouteTables(ctx, source.VpcID, profile)
+	if err != nil {
+		hop.Unknown = true
+		hop.Detail = "could not read route tables: " + categorizeAWSError(err, "EC2")
+		return hop
+	}
+	table := subnetRouteTable(tables, source.SubnetIDs[0])
+	if table == nil {
+		hop.Unknown = true
+		hop.Detail = fmt.Sprintf("no route table found for %s", source.SubnetIDs[0])
+		return hop
+	}
+	hop.Name = fmt.Sprintf("Source route table (%s)", table.RouteTableID)
+
+	var details []string
+	for _, dest := range target.CIDRs {
+		route := longestPrefixRoute(table.Routes, dest)
+		if route == nil {
+			hop.Detail = fmt.Sprintf("no route to %s", dest)
+			if target.Internet {
+				hop.Detail = "no default route (0.0.0.0/0): the subnet is private with no NAT gateway; add a NAT gateway route"
+			}
+			return hop
+		}
+		if route.State == "blackhole" {
+			hop.Detail = fmt.Sprintf("route %s → %s is a blackhole (the target was deleted)", route.DestinationCidrBlock, route.target())
+			return hop
+		}
+		switch {
+		case route.GatewayID == "local":
+			details = append(details, fmt.Sprintf("%s is in the VPC (local route)", dest))
+		case strings.HasPrefix(route.GatewayID, "igw-"):
+			if target.Internet && source.PublicIP == "" {
+				hop.Detail = fmt.Sprintf("default route goes to %s but the source has no public IP; assign one or route through a NAT gateway", route.GatewayID)
+				return hop
+			}
+			details = append(details, fmt.Sprintf("%s via internet gateway %s", route.DestinationCidrBlock, route.GatewayID))
+		case route.NatGatewayID != "":
+			detail, ok := c.natGatewayPath(ctx, tables, route.NatGatewayID, profile)
+			if !ok {
+				hop.Detail = detail
+				return hop
+			}
+			details = append(details, fmt.Sprintf("%s via %s", route.DestinationCidrBlock, detail))
+		default:
+			details = append(details, fmt.Sprintf("%s via %s (not traced further)", route.DestinationCidrBlock, route.target()))
+		}
+	}
+	hop.Allowed = true
+	hop.Detail = strings.Join(uniqueStrings(details), "; ")
+	return hop
+}
+
+// natGatewayPath checks that a NAT gateway is available and that its own
+// subnet routes to an internet gateway.
+func (c *Client) natGatewayPath(ctx context.Context, tables []routeTable, natID string, profile *AIProfile) (string, bool) {
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-nat-gateways", "--nat-gateway-ids", natID, "--output", "json"}, profile)
+	var resp struct {
+		NatGateways []struct {
+			State    string `json:"State"`
+			SubnetID string `json:"SubnetId"`
+		} `json:"NatGateways"`
+	}
+	if err == nil {
+		err = json.Unmarshal([]byte(raw), &resp)
+	}
+	if err != nil || len(resp.NatGateways) == 0 {
+		return fmt.Sprintf("NAT gateway %s (state not checked)", natID), true
+	}
+	nat := resp.NatGateways[0]
+	if nat.State != "available" {
+		return fmt.Sprintf("default route goes to NAT gateway %s, which is %s; recreate it or point the route at an available one", natID, nat.State), false
+	}
+	natTable := subnetRouteTable(tables, nat.SubnetID)
+	if natTable != nil {
+		if r := longestPrefixRoute(natTable.Routes, "0.0.0.0/0"); r == nil || !strings.HasPrefix(r.GatewayID, "igw-") {
+			return fmt.Sprintf("NAT gateway %s sits in %s, whose route table %s has no default route to an internet gateway; put the NAT in a public subnet", natID, nat.SubnetID, natTable.RouteTableID), false
+		}
+	}
+	return fmt.Sprintf("NAT gateway %s (available, in public subnet %s)", natID, nat.SubnetID), true
+}
+
+func (c *Client) describeRouteTables(ctx context.Context, vpcID string, profile *AIProfile) ([]routeTable, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-route-tables", "--filters", "Name=vpc-id,Values=" + vpcID, "--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		RouteTables []routeTable `json:"RouteTables"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse route tables: %w", err)
+	}
+	return resp.RouteTables, nil
+}
+
+// subnetRouteTable returns the table explicitly associated with the subnet,
+// or the VPC's main table.
+func subnetRouteTable(tables []routeTable, subnetID string) *routeTable {
+	var main *routeTable
+	for i := range tables {
+		for _, a := range tables[i].Associations {
+			if a.SubnetID == subnetID {
+				return &tables[i]
+			}
+			if a.Main {
+				main = &tables[i]
+			}
+		}
+	}
+	return main
+}
+
+// longestPrefixRoute picks the most specific route covering dest, the way
+// the VPC router does.
+func longestPrefixRoute(routes []vpcRoute, dest string) *vpcRoute {
+	var best *vpcRoute
+	bestOnes := -1
+	for i := range routes {
+		r := &routes[i]
+		if r.DestinationCidrBlock == "" || !cidrCovers(r.DestinationCidrBlock, dest) {
+			continue
+		}
+		_, n, _ := net.ParseCIDR(r.DestinationCidrBlock)
+		if ones, _ := n.Mask.Size(); ones > bestOnes {
+			best, bestOnes = r, ones
+		}
+	}
+	return best
+}
+
+// parseCIDR accepts a CIDR or a bare IPv4 address (as a /32).
+func parseCIDR(s string) (*net.IPNet, bool) {
+	if !strings.Contains(s, "/") {
+		s += "/32"
+	}
+	_, n, err := net.ParseCIDR(strings.TrimSpace(s))
+	return n, err == nil
+}
+
+// cidrCovers reports whether every address in inner is inside outer.
+func cidrCovers(outer, inner string) bool {
+	o, ok1 := parseCIDR(outer)
+	i, ok2 := parseCIDR(inner)
+	if !ok1 || !ok2 {
+		return false
+	}
+	oOnes, oBits := o.Mask.Size()
+	iOnes, iBits := i.Mask.Size()
+	return oBits == iBits && oOnes <= iOnes && o.Contains(i.IP)
+}
+
+// cidrOverlaps reports whether a and b share any address.
+func cidrOverlaps(a, b string) bool {
+	x, ok1 := parseCIDR(a)
+	y, ok2 := parseCIDR(b)
+	return ok1 && ok2 && (x.Contains(y.IP) || y.Contains(x.IP))
+}
+
+func portSpan(from, to int) string {
+	if from == to {
+		return strconv.Itoa(from)
+	}
+	return fmt.Sprintf("%d-%d", from, to)
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
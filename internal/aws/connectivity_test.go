@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const connectivityOpenNACL = `{"NetworkAcls": [{"NetworkAclId": "acl-open", "Entries": [
+	{"RuleNumber": 100, "Protocol": "-1", "RuleAction": "allow", "Egress": true, "CidrBlock": "0.0.0.0/0"},
+	{"RuleNumber": 100, "Protocol": "-1", "RuleAction": "allow", "Egress": false, "CidrBlock": "0.0.0.0/0"},
+	{"RuleNumber": 32767, "Protocol": "-1", "RuleAction": "deny", "Egress": true, "CidrBlock": "0.0.0.0/0"},
+	{"RuleNumber": 32767, "Protocol": "-1", "RuleAction": "deny", "Egress": false, "CidrBlock": "0.0.0.0/0"}
+]}]}`
+
+func connectivityFixtures() *fakeCLI {
+	f := newFakeCLI()
+	f.fixtures["ec2 describe-instances --output json --instance-ids i-0abc12345678"] = `{"Reservations": [{"Instances": [{
+		"InstanceId": "i-0abc12345678", "SubnetId": "subnet-app", "VpcId": "vpc-1",
+		"PrivateIpAddress": "10.0.1.15", "SecurityGroups": [{"GroupId": "sg-app"}]
+	}]}]}`
+	f.fixtures["ec2 describe-network-acls"] = connectivityOpenNACL
+	f.fixtures["ec2 describe-route-tables --filters Name=vpc-id,Values=vpc-1"] = `{"RouteTables": [
+		{"RouteTableId": "rtb-main", "Associations": [{"Main": true}], "Routes": [
+			{"DestinationCidrBlock": "10.0.0.0/16", "GatewayId": "local", "State": "active"},
+			{"DestinationCidrBlock": "0.0.0.0/0", "GatewayId": "igw-1", "State": "active"}
+		]},
+		{"RouteTableId": "rtb-private", "Associations": [{"SubnetId": "subnet-app"}], "Routes": [
+			{"DestinationCidrBlock": "10.0.0.0/16", "GatewayId": "local", "State": "active"}
+		]}
+	]}`
+	return f
+}
+
+func TestAnalyzeConnectivityPrivateSubnetWithoutNAT(t *testing.T) {
+	f := connectivityFixtures()
+	f.fixtures["ec2 describe-security-groups"] = `{"SecurityGroups": [{"GroupId": "sg-app", "IpPermissionsEgress": [
+		{"IpProtocol": "-1", "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}
+	]}]}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "analyze_connectivity", map[string]interface{}{"query": "why can't i-0abc12345678 reach the internet"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"instance i-0abc12345678 (10.0.1.15) → the internet on tcp/443",
+		"✅ 1. Source security group egress (sg-app): sg-app allows all traffic with 0.0.0.0/0",
+		"🚫 Traffic is blocked at: Source route table (rtb-private) — no default route (0.0.0.0/0): the subnet is private with no NAT gateway",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAnalyzeConnectivityNATInPrivateSubnet(t *testing.T) {
+	f := connectivityFixtures()
+	f.fixtures["ec2 describe-security-groups"] = `{"SecurityGroups": [{"GroupId": "sg-app", "IpPermissionsEgress": [
+		{"IpProtocol": "tcp", "FromPort": 443, "ToPort": 443, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}
+	]}]}`
+	f.fixtures["ec2 describe-route-tables --filters Name=vpc-id,Values=vpc-1"] = `{"RouteTables": [
+		{"RouteTableId": "rtb-private", "Associations": [{"Main": true}, {"SubnetId": "subnet-app"}], "Routes": [
+			{"DestinationCidrBlock": "10.0.0.0/16", "GatewayId": "local", "State": "active"},
+			{"DestinationCidrBlock": "0.0.0.0/0", "NatGatewayId": "nat-1", "State": "active"}
+		]}
+	]}`
+	f.fixtures["ec2 describe-nat-gateways --nat-gateway-ids nat-1"] = `{"NatGateways": [{"State": "available", "SubnetId": "subnet-private-b"}]}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "analyze_connectivity", map[string]interface{}{"source": "i-0abc12345678"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "NAT gateway nat-1 sits in subnet-private-b, whose route table rtb-private has no default route to an internet gateway"
+	if !strings.Contains(out, "🚫 Traffic is blocked at: Source route table (rtb-private) — "+want) {
+		t.Errorf("output missing %q:\n%s", want, out)
+	}
+}
+
+func TestAnalyzeConnectivityRDSSecurityGroup(t *testing.T) {
+	f := connectivityFixtures()
+	f.fixtures["rds describe-db-instances --db-instance-identifier orders"] = `{"DBInstances": [{
+		"DBInstanceIdentifier": "orders", "Engine": "postgres", "Endpoint": {"Port": 5432},
+		"VpcSecurityGroups": [{"VpcSecurityGroupId": "sg-db"}],
+		"DBSubnetGroup": {"VpcId": "vpc-1", "Subnets": [{"SubnetIdentifier": "subnet-db-a"}, {"SubnetIdentifier": "subnet-db-b"}]}
+	}]}`
+	f.fixtures["ec2 describe-subnets"] = `{"Subnets": [{"CidrBlock": "10.0.20.0/24"}, {"CidrBlock": "10.0.21.0/24"}]}`
+	f.fixtures["ec2 describe-security-groups"] = `{"SecurityGroups": [
+		{"GroupId": "sg-app", "IpPermissionsEgress": [{"IpProtocol": "-1", "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}]},
+		{"GroupId": "sg-db", "IpPermissions": [{"IpProtocol": "tcp", "FromPort": 5432, "ToPort": 5432, "UserIdGroupPairs": [{"GroupId": "sg-bastion"}]}]}
+	]}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "analyze_connectivity", map[string]interface{}{
+		"source": "i-0abc12345678",
+		"target": "orders.abc123xyz.us-east-1.rds.amazonaws.com",
+	}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"→ RDS orders (postgres) on tcp/5432",
+		"✅ 3. Source route table (rtb-private): 10.0.20.0/24 is in the VPC (local route); 10.0.21.0/24 is in the VPC (local route)",
+		"🚫 Traffic is blocked at: Target security group ingress (sg-db) — no rule allows tcp/5432 from 10.0.1.15/32 or sg-app",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestNACLVerdict(t *testing.T) {
+	entries := []naclEntry{
+		{RuleNumber: 200, Protocol: "6", RuleAction: "allow", CidrBlock: "0.0.0.0/0", PortRange: &struct {
+			From int `json:"From"`
+			To   int `json:"To"`
+		}{From: 1024, To: 65535}},
+		{RuleNumber: 100, Protocol: "-1", RuleAction: "deny", CidrBlock: "10.0.20.7/32"},
+		{RuleNumber: 32767, Protocol: "-1", RuleAction: "deny", CidrBlock: "0.0.0.0/0"},
+	}
+	if e, ok := naclVerdict(entries, false, 443, 443, "10.0.1.15/32"); ok || e == nil || e.RuleNumber != 32767 {
+		t.Errorf("tcp/443 should hit the default deny, got %+v %t", e, ok)
+	}
+	if e, ok := naclVerdict(entries, false, connectivityEphemeralFrom, connectivityEphemeralTo, "10.0.1.15/32"); !ok || e.RuleNumber != 200 {
+		t.Errorf("ephemeral ports should be allowed by rule 200, got %+v %t", e, ok)
+	}
+	if e, ok := naclVerdict(entries, false, connectivityEphemeralFrom, connectivityEphemeralTo, "10.0.20.0/24"); ok || e.RuleNumber != 100 {
+		t.Errorf("a deny overlapping the peer range should win, got %+v %t", e, ok)
+	}
+	if _, ok := naclVerdict(entries, true, 443, 443, "0.0.0.0/0"); ok {
+		t.Error("no egress entries means the implicit deny")
+	}
+}
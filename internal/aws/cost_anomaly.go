@@ -0,0 +1,231 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// costAnomalyWindowDays is the trailing window analyzed by detect_cost_anomaly.
+	costAnomalyWindowDays = 30
+	// costAnomalyMinDelta ignores spikes too small to matter (in the billing currency).
+	costAnomalyMinDelta = 1.0
+	costAnomalyTopN     = 10
+)
+
+// costAnomaly is a day on which a service's cost exceeded mean + 2·stddev.
+type costAnomaly struct {
+	Service string
+	Date    string
+	Amount  float64
+	Mean    float64
+	StdDev  float64
+	Delta   float64
+	Unit    string
+}
+
+type costExplorerResult struct {
+	TimePeriod struct {
+		Start string `json:"Start"`
+		End   string `json:"End"`
+	} `json:"TimePeriod"`
+	Groups []struct {
+		Keys    []string `json:"Keys"`
+		Metrics map[string]struct {
+			Amount string `json:"Amount"`
+			Unit   string `json:"Unit"`
+		} `json:"Metrics"`
+	} `json:"Groups"`
+}
+
+type costExplorerResponse struct {
+	ResultsByTime []costExplorerResult `json:"ResultsByTime"`
+	NextPageToken string               `json:"NextPageToken"`
+}
+
+// merge appends a later page. Cost Explorer pages by group, so one day's
+// groups can continue on the next page.
+func (r *costExplorerResponse) merge(page costExplorerResponse) {
+	for _, result := range page.ResultsByTime {
+		i := slices.IndexFunc(r.ResultsByTime, func(existing costExplorerResult) bool {
+			return existing.TimePeriod.Start == result.TimePeriod.Start
+		})
+		if i < 0 {
+			r.ResultsByTime = append(r.ResultsByTime, result)
+			continue
+		}
+		r.ResultsByTime[i].Groups = append(r.ResultsByTime[i].Groups, result.Groups...)
+	}
+}
+
+// costTimePeriod returns a Cost Explorer time period covering the trailing
+// number of days. Cost Explorer treats End as exclusive, so today is End.
+func costTimePeriod(now time.Time, days int) string {
+	end := now.UTC().Truncate(24 * time.Hour)
+	start := end.AddDate(0, 0, -days)
+	return fmt.Sprintf("Start=%s,End=%s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+}
+
+// detectCostAnomaly queries daily per-service cost for the trailing window and
+// reports services whose daily spend spiked above mean + 2·stddev.
+func (c *Client) detectCostAnomaly(ctx context.Context, profile *AIProfile) (string, error) {
+	args := []string{"ce", "get-cost-and-usage",
+		"--time-period", costTimePeriod(time.Now(), costAnomalyWindowDays),
+		"--granularity", "DAILY",
+		"--metrics", "UnblendedCost",
+		"--group-by", "Type=DIMENSION,Key=SERVICE",
+		"--output", "json"}
+
+	// The CLI does not paginate get-cost-and-usage itself; an account with
+	// many services spills over onto further pages.
+	var resp costExplorerResponse
+	seenTokens := make(map[string]bool)
+	for token := ""; ; {
+		pageArgs := args
+		if token != "" {
+			pageArgs = slices.Insert(slices.Clone(args), 2, "--next-page-token", token)
+		}
+		raw, err := c.execAWSCLI(ctx, pageArgs, profile)
+		if err != nil {
+			return categorizeAWSError(err, "Cost Explorer"), nil
+		}
+		page, err := parseCostExplorerResponse(raw)
+		if err != nil {
+			return "", err
+		}
+		resp.merge(page)
+		token = page.NextPageToken
+		if token == "" || seenTokens[token] {
+			break
+		}
+		seenTokens[token] = true
+	}
+
+	return formatCostAnomalies(costAnomaliesIn(resp)), nil
+}
+
+func parseCostExplorerResponse(raw string) (costExplorerResponse, error) {
+	var resp costExplorerResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return resp, fmt.Errorf("failed to parse cost explorer response: %w", err)
+	}
+	return resp, nil
+}
+
+// costAnomaliesIn returns the largest spike per service in resp, ordered by
+// delta.
+func costAnomaliesIn(resp costExplorerResponse) []costAnomaly {
+	days := len(resp.ResultsByTime)
+	if days == 0 {
+		return nil
+	}
+
+	// Services missing from a day's groups spent nothing that day.
+	series := make(map[string][]float64)
+	units := make(map[string]string)
+	dates := make([]string, days)
+	for i, result := range resp.ResultsByTime {
+		dates[i] = result.TimePeriod.Start
+		for _, group := range result.Groups {
+			if len(group.Keys) == 0 {
+				continue
+			}
+			metric, ok := group.Metrics["UnblendedCost"]
+			if !ok {
+				continue
+			}
+			amount, err := strconv.ParseFloat(metric.Amount, 64)
+			if err != nil {
+				continue
+			}
+			service := group.Keys[0]
+			if _, exists := series[service]; !exists {
+				series[service] = make([]float64, days)
+			}
+			series[service][i] = amount
+			units[service] = metric.Unit
+		}
+	}
+
+	var anomalies []costAnomaly
+	for service, values := range series {
+		mean, stddev := meanStdDev(values)
+		if stddev == 0 {
+			continue
+		}
+		threshold := mean + 2*stddev
+		var worst *costAnomaly
+		for i, amount := range values {
+			delta := amount - mean
+			if amount <= threshold || delta < costAnomalyMinDelta {
+				continue
+			}
+			if worst == nil || delta > worst.Delta {
+				worst = &costAnomaly{
+					Service: service,
+					Date:    dates[i],
+					Amount:  amount,
+					Mean:    mean,
+					StdDev:  stddev,
+					Delta:   delta,
+					Unit:    units[service],
+				}
+			}
+		}
+		if worst != nil {
+			anomalies = append(anomalies, *worst)
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].Delta != anomalies[j].Delta {
+			return anomalies[i].Delta > anomalies[j].Delta
+		}
+		return anomalies[i].Service < anomalies[j].Service
+	})
+	return anomalies
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+func formatCostAnomalies(anomalies []costAnomaly) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("💸 COST ANOMALY DETECTION (trailing %d days, daily, by service)\n", costAnomalyWindowDays))
+	out.WriteString("================================================================\n\n")
+	if len(anomalies) == 0 {
+		out.WriteString("No service exceeded mean + 2·stddev on any day in the window.\n")
+		return out.String()
+	}
+
+	out.WriteString(fmt.Sprintf("%d services spiked above mean + 2·stddev:\n", len(anomalies)))
+	for i, a := range anomalies {
+		if i == costAnomalyTopN {
+			out.WriteString(fmt.Sprintf("... and %d more\n", len(anomalies)-i))
+			break
+		}
+		out.WriteString(fmt.Sprintf("%d. %s on %s: %.2f %s (mean %.2f, stddev %.2f, +%.2f above mean)\n",
+			i+1, a.Service, a.Date, a.Amount, a.Unit, a.Mean, a.StdDev, a.Delta))
+	}
+	return out.String()
+}
@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCostTimePeriod(t *testing.T) {
+	now := time.Date(2025, 3, 15, 18, 30, 0, 0, time.UTC)
+	if got, want := costTimePeriod(now, 30), "Start=2025-02-13,End=2025-03-15"; got != want {
+		t.Errorf("costTimePeriod = %q, want %q", got, want)
+	}
+}
+
+func TestCostAnomaliesAcrossPages(t *testing.T) {
+	type metric struct {
+		Amount string `json:"Amount"`
+		Unit   string `json:"Unit"`
+	}
+	type group struct {
+		Keys    []string          `json:"Keys"`
+		Metrics map[string]metric `json:"Metrics"`
+	}
+	type day struct {
+		TimePeriod map[string]string `json:"TimePeriod"`
+		Groups     []group           `json:"Groups"`
+	}
+
+	// Cost Explorer pages by group: every day's Lambda cost is on the first
+	// page and its S3 cost continues on the second.
+	var lambdaDays, s3Days []day
+	for i := 0; i < 30; i++ {
+		lambdaCost := 10.0
+		if i == 20 {
+			lambdaCost = 90.0
+		}
+		start := map[string]string{"Start": fmt.Sprintf("2025-01-%02d", i+1)}
+		lambdaDays = append(lambdaDays, day{TimePeriod: start, Groups: []group{
+			{Keys: []string{"AWS Lambda"}, Metrics: map[string]metric{"UnblendedCost": {Amount: fmt.Sprintf("%.2f", lambdaCost), Unit: "USD"}}},
+		}})
+		s3Days = append(s3Days, day{TimePeriod: start, Groups: []group{
+			{Keys: []string{"Amazon S3"}, Metrics: map[string]metric{"UnblendedCost": {Amount: "5.00", Unit: "USD"}}},
+		}})
+	}
+
+	var resp costExplorerResponse
+	for _, page := range []map[string]any{
+		{"ResultsByTime": lambdaDays, "NextPageToken": "p2"},
+		{"ResultsByTime": s3Days},
+	} {
+		raw, err := json.Marshal(page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := parseCostExplorerResponse(string(raw))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.merge(parsed)
+	}
+	if len(resp.ResultsByTime) != 30 || len(resp.ResultsByTime[0].Groups) != 2 {
+		t.Fatalf("expected a day's groups merged across pages, got %d days, %d groups on day 1", len(resp.ResultsByTime), len(resp.ResultsByTime[0].Groups))
+	}
+
+	anomalies := costAnomaliesIn(resp)
+	if len(anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly, got %+v", anomalies)
+	}
+	a := anomalies[0]
+	if a.Service != "AWS Lambda" || a.Date != "2025-01-21" || a.Amount != 90 {
+		t.Errorf("unexpected anomaly: %+v", a)
+	}
+	if a.Delta <= 0 || a.Delta >= 90 {
+		t.Errorf("unexpected delta %.2f", a.Delta)
+	}
+}
+
+func TestCostAnomaliesEmpty(t *testing.T) {
+	resp, err := parseCostExplorerResponse("{}")
+	if err != nil || len(costAnomaliesIn(resp)) != 0 {
+		t.Errorf("expected no anomalies and no error, got %v, %v", costAnomaliesIn(resp), err)
+	}
+	if _, err := parseCostExplorerResponse("not json"); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestDetectCostAnomalyFollowsPages(t *testing.T) {
+	// Day 21's Lambda spike only shows up on the second page.
+	page := func(from, to int, lambdaOnDay21 bool, next string) string {
+		var days []string
+		for i := from; i < to; i++ {
+			groups := `{"Keys": ["Amazon S3"], "Metrics": {"UnblendedCost": {"Amount": "5.00", "Unit": "USD"}}}`
+			if lambdaOnDay21 {
+				amount := "10.00"
+				if i == 20 {
+					amount = "90.00"
+				}
+				groups = fmt.Sprintf(`{"Keys": ["AWS Lambda"], "Metrics": {"UnblendedCost": {"Amount": %q, "Unit": "USD"}}}`, amount)
+			}
+			days = append(days, fmt.Sprintf(`{"TimePeriod": {"Start": "2025-01-%02d"}, "Groups": [%s]}`, i+1, groups))
+		}
+		return fmt.Sprintf(`{"ResultsByTime": [%s], "NextPageToken": %q}`, strings.Join(days, ","), next)
+	}
+	f := newFakeCLI()
+	f.fixtures["ce get-cost-and-usage"] = page(0, 30, false, "p2")
+	f.fixtures["ce get-cost-and-usage --next-page-token p2"] = page(0, 30, true, "")
+
+	out, err := newFakeClient(f).detectCostAnomaly(context.Background(), &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.calls) != 2 {
+		t.Errorf("expected both pages to be read, calls: %v", f.calls)
+	}
+	if !strings.Contains(out, "AWS Lambda") || !strings.Contains(out, "2025-01-21") {
+		t.Errorf("expected the second page's Lambda spike in:\n%s", out)
+	}
+}
@@ -0,0 +1,169 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// costGroupTopN bounds how many groups a cost breakdown lists individually.
+const costGroupTopN = 15
+
+// untaggedCostLabel names spend on resources that do not carry the tag.
+const untaggedCostLabel = "(untagged)"
+
+// costGroup is the month-to-date spend of one service or tag value.
+type costGroup struct {
+	Key    string
+	Amount float64
+	Unit   string
+}
+
+// currentMonthPeriod returns the Cost Explorer period for the current month
+// to date. On the 1st there is no completed day yet, so the previous month is
+// used instead.
+func currentMonthPeriod(now time.Time) string {
+	today := now.UTC().Truncate(24 * time.Hour)
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !start.Before(today) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return fmt.Sprintf("Start=%s,End=%s", start.Format("2006-01-02"), today.Format("2006-01-02"))
+}
+
+// getCostGrouped returns month-to-date spend grouped by a SERVICE dimension
+// or a cost allocation tag, sorted by spend.
+func (c *Client) getCostGrouped(ctx context.Context, groupType, key string, profile *AIProfile) (string, error) {
+	args := []string{"ce", "get-cost-and-usage",
+		"--time-period", currentMonthPeriod(time.Now()),
+		"--granularity", "MONTHLY",
+		"--metrics", "UnblendedCost",
+		"--group-by", fmt.Sprintf("Type=%s,Key=%s", groupType, key),
+		"--output", "json"}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		if groupType == "TAG" && isCostTagNotActivated(err.Error()) {
+			return costTagNotActivatedMessage(key), nil
+		}
+		return categorizeAWSError(err, "Cost Explorer"), nil
+	}
+
+	groups, err := parseCostGroups(raw, groupType == "TAG")
+	if err != nil {
+		return "", err
+	}
+	return formatCostGroups(groupType, key, groups), nil
+}
+
+// getCostByTag is the get_cost_by_tag operation.
+func (c *Client) getCostByTag(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	tagKey, _ := input["tag_key"].(string)
+	tagKey = strings.TrimSpace(tagKey)
+	if tagKey == "" {
+		return "", fmt.Errorf("tag_key parameter required (e.g. Environment, Team)")
+	}
+	return c.getCostGrouped(ctx, "TAG", tagKey, profile)
+}
+
+func isCostTagNotActivated(text string) bool {
+	lower := strings.ToLower(text)
+	return strings.Contains(lower, "cost allocation") || strings.Contains(lower, "not activated") ||
+		strings.Contains(lower, "tag key") && strings.Contains(lower, "invalid")
+}
+
+func costTagNotActivatedMessage(tagKey string) string {
+	return fmt.Sprintf("⚠️  Tag %q is not activated for cost allocation, so Cost Explorer cannot group spend by it.\n"+
+		"Activate it under Billing → Cost allocation tags (or `aws ce update-cost-allocation-tags-status`); data appears within 24 hours and only for usage after activation.\n", tagKey)
+}
+
+// parseCostGroups sums each group's UnblendedCost across the result periods.
+// Tag groups come back as "Key$value"; an empty value means untagged.
+func parseCostGroups(raw string, isTag bool) ([]costGroup, error) {
+	var resp costExplorerResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse cost explorer response: %w", err)
+	}
+
+	totals := make(map[string]*costGroup)
+	for _, result := range resp.ResultsByTime {
+		for _, group := range result.Groups {
+			if len(group.Keys) == 0 {
+				continue
+			}
+			metric, ok := group.Metrics["UnblendedCost"]
+			if !ok {
+				continue
+			}
+			amount, err := strconv.ParseFloat(metric.Amount, 64)
+			if err != nil {
+				continue
+			}
+			key := group.Keys[0]
+			if isTag {
+				if _, value, found := strings.Cut(key, "$"); found {
+					key = value
+				}
+				if key == "" {
+					key = untaggedCostLabel
+				}
+			}
+			if totals[key] == nil {
+				totals[key] = &costGroup{Key: key, Unit: metric.Unit}
+			}
+			totals[key].Amount += amount
+		}
+	}
+
+	groups := make([]costGroup, 0, len(totals))
+	for _, g := range totals {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Amount != groups[j].Amount {
+			return groups[i].Amount > groups[j].Amount
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups, nil
+}
+
+func formatCostGroups(groupType, key string, groups []costGroup) string {
+	var out strings.Builder
+	label := "service"
+	if groupType == "TAG" {
+		label = "tag " + key
+	}
+	out.WriteString(fmt.Sprintf("💰 MONTH-TO-DATE COST BY %s\n", strings.ToUpper(label)))
+	out.WriteString("==============================\n\n")
+	if len(groups) == 0 {
+		out.WriteString("No spend recorded this month.\n")
+		return out.String()
+	}
+
+	total := 0.0
+	for _, g := range groups {
+		total += g.Amount
+	}
+	unit := groups[0].Unit
+	for i, g := range groups {
+		if i == costGroupTopN {
+			out.WriteString(fmt.Sprintf("... and %d more\n", len(groups)-i))
+			break
+		}
+		share := 0.0
+		if total > 0 {
+			share = g.Amount / total * 100
+		}
+		out.WriteString(fmt.Sprintf("%d. %s: %.2f %s (%.1f%%)\n", i+1, g.Key, g.Amount, g.Unit, share))
+	}
+	out.WriteString(fmt.Sprintf("\nTotal: %.2f %s\n", total, unit))
+
+	if groupType == "TAG" && len(groups) == 1 && groups[0].Key == untaggedCostLabel {
+		out.WriteString(fmt.Sprintf("\nAll spend is untagged. If resources do carry %q, the tag is probably not activated for cost allocation.\n", key))
+	}
+	return out.String()
+}
@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCurrentMonthPeriod(t *testing.T) {
+	mid := time.Date(2024, 5, 17, 15, 0, 0, 0, time.UTC)
+	if got := currentMonthPeriod(mid); got != "Start=2024-05-01,End=2024-05-17" {
+		t.Errorf("mid-month period = %s", got)
+	}
+	first := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	if got := currentMonthPeriod(first); got != "Start=2024-04-01,End=2024-05-01" {
+		t.Errorf("first-of-month period = %s", got)
+	}
+}
+
+func TestParseCostGroupsByTag(t *testing.T) {
+	raw := `{"ResultsByTime":[{"TimePeriod":{"Start":"2024-05-01","End":"2024-05-17"},"Groups":[
+		{"Keys":["Environment$staging"],"Metrics":{"UnblendedCost":{"Amount":"40.5","Unit":"USD"}}},
+		{"Keys":["Environment$prod"],"Metrics":{"UnblendedCost":{"Amount":"310","Unit":"USD"}}},
+		{"Keys":["Environment$"],"Metrics":{"UnblendedCost":{"Amount":"12","Unit":"USD"}}}
+	]}]}`
+
+	groups, err := parseCostGroups(raw, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 3 || groups[0].Key != "prod" || groups[1].Key != "staging" || groups[2].Key != untaggedCostLabel {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+
+	out := formatCostGroups("TAG", "Environment", groups)
+	for _, want := range []string{"BY TAG ENVIRONMENT", "1. prod: 310.00 USD", "Total: 362.50 USD"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatCostGroupsAllUntagged(t *testing.T) {
+	out := formatCostGroups("TAG", "Team", []costGroup{{Key: untaggedCostLabel, Amount: 10, Unit: "USD"}})
+	if !strings.Contains(out, "not activated for cost allocation") {
+		t.Errorf("expected an activation hint:\n%s", out)
+	}
+	if !isCostTagNotActivated("An error occurred (ValidationException): Tag key Team is not activated for cost allocation") {
+		t.Error("expected the activation error to be recognized")
+	}
+}
@@ -0,0 +1,234 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bgdnvk/clanker/internal/logging"
+	"github.com/spf13/viper"
+)
+
+// defaultDiscoveryCheckpointMaxAge is how long a recorded service check is
+// reused by --resume before it is re-run.
+const defaultDiscoveryCheckpointMaxAge = 24 * time.Hour
+
+// discoveryCheckpointEntry is one service check result recorded during
+// discovery.
+type discoveryCheckpointEntry struct {
+	Profile   string    `json:"profile"`
+	Region    string    `json:"region"`
+	Service   string    `json:"service"`
+	Result    string    `json:"result"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// discoveryCheckpoint persists service check results as they return so an
+// interrupted discovery run can be resumed without repeating finished checks.
+type discoveryCheckpoint struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]discoveryCheckpointEntry
+}
+
+type discoveryCheckpointFile struct {
+	Entries []discoveryCheckpointEntry `json:"entries"`
+}
+
+func discoveryCheckpointKey(profile, region, service string) string {
+	return profile + "|" + region + "|" + service
+}
+
+// discoveryCheckpointPath returns aws.discovery_checkpoint, defaulting to
+// ~/.clanker/discovery-checkpoint.json.
+func discoveryCheckpointPath() (string, error) {
+	path := strings.TrimSpace(viper.GetString("aws.discovery_checkpoint"))
+	if path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine discovery checkpoint path: %w", err)
+	}
+	return filepath.Join(home, ".clanker", "discovery-checkpoint.json"), nil
+}
+
+// discoveryCheckpointMaxAge returns aws.discovery_checkpoint_max_age (a Go
+// duration such as "6h"), defaulting to 24h.
+func discoveryCheckpointMaxAge() time.Duration {
+	if d := viper.GetDuration("aws.discovery_checkpoint_max_age"); d > 0 {
+		return d
+	}
+	return defaultDiscoveryCheckpointMaxAge
+}
+
+var (
+	discoveryCheckpointsMu sync.Mutex
+	discoveryCheckpoints   = make(map[string]*discoveryCheckpoint)
+)
+
+// openDiscoveryCheckpoint returns the checkpoint at path, loading it on first
+// use. Multi-region discovery runs regions concurrently, so every run in the
+// process shares one instance per file instead of overwriting each other.
+func openDiscoveryCheckpoint(path string) (*discoveryCheckpoint, error) {
+	discoveryCheckpointsMu.Lock()
+	defer discoveryCheckpointsMu.Unlock()
+	if cp, ok := discoveryCheckpoints[path]; ok {
+		return cp, nil
+	}
+	cp, err := loadDiscoveryCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	discoveryCheckpoints[path] = cp
+	return cp, nil
+}
+
+// loadDiscoveryCheckpoint reads the checkpoint at path. A missing file is an
+// empty checkpoint. Entries older than the max age can never be reused, so
+// they are dropped and the file does not grow across runs.
+func loadDiscoveryCheckpoint(path string) (*discoveryCheckpoint, error) {
+	cp := &discoveryCheckpoint{path: path, entries: make(map[string]discoveryCheckpointEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read discovery checkpoint %s: %w", path, err)
+	}
+	var file discoveryCheckpointFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("cannot parse discovery checkpoint %s: %w", path, err)
+	}
+	cutoff := time.Now().Add(-discoveryCheckpointMaxAge())
+	for _, entry := range file.Entries {
+		if entry.CheckedAt.Before(cutoff) {
+			continue
+		}
+		cp.entries[discoveryCheckpointKey(entry.Profile, entry.Region, entry.Service)] = entry
+	}
+	return cp, nil
+}
+
+// lookup returns the recorded result for a check if it is younger than maxAge.
+func (cp *discoveryCheckpoint) lookup(profile, region, service string, maxAge time.Duration, now time.Time) (discoveryCheckpointEntry, bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	entry, ok := cp.entries[discoveryCheckpointKey(profile, region, service)]
+	if !ok || now.Sub(entry.CheckedAt) > maxAge {
+		return discoveryCheckpointEntry{}, false
+	}
+	return entry, true
+}
+
+// record stores a check result and rewrites the checkpoint file so the result
+// survives an interrupted run.
+func (cp *discoveryCheckpoint) record(entry discoveryCheckpointEntry) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.entries[discoveryCheckpointKey(entry.Profile, entry.Region, entry.Service)] = entry
+	return cp.saveLocked()
+}
+
+func (cp *discoveryCheckpoint) saveLocked() error {
+	file := discoveryCheckpointFile{Entries: make([]discoveryCheckpointEntry, 0, len(cp.entries))}
+	for _, entry := range cp.entries {
+		file.Entries = append(file.Entries, entry)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cp.path), 0700); err != nil {
+		return fmt.Errorf("cannot create discovery checkpoint directory: %w", err)
+	}
+	// Write to a temp file and rename so an interrupt never leaves a
+	// truncated checkpoint behind.
+	tmp := cp.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("cannot write discovery checkpoint: %w", err)
+	}
+	return os.Rename(tmp, cp.path)
+}
+
+// checkpointScope returns the AWS profile and region of a checkpoint key,
+// resolved the way the CLI calls resolve them. The region is empty when no
+// flag, environment variable or profile sets one.
+func checkpointScope(profile *AIProfile) (string, string) {
+	awsProfile, region := ResolveAWSContext(profile)
+	if awsProfile == "" {
+		awsProfile = "default"
+	}
+	return awsProfile, region
+}
+
+// runDiscoveryChecks runs service checks, recording each result in the
+// discovery checkpoint as it returns. With aws.discovery_resume set, checks
+// recorded for the same profile and region within the max age are reused
+// instead of re-run.
+func (c *Client) runDiscoveryChecks(ctx context.Context, checks []string, profile *AIProfile) string {
+	if c.dryRun {
+		return formatOperationResults(c.runOperations(ctx, discoveryOperations(checks), profile, nil))
+	}
+
+	profileName, region := checkpointScope(profile)
+	var cp *discoveryCheckpoint
+	if region == "" {
+		// Keyed without a region, results from different regions would collide.
+		logging.Verbosef("⚠️  Discovery checkpoint disabled: no AWS region resolved for %s", profileName)
+	} else if path, err := discoveryCheckpointPath(); err != nil {
+		logging.Infof("⚠️  Discovery checkpoint disabled: %v", err)
+	} else if cp, err = openDiscoveryCheckpoint(path); err != nil {
+		logging.Infof("⚠️  Discovery checkpoint disabled: %v", err)
+	}
+
+	results := make([]LLMOperationResult, len(checks))
+	var pending []string
+	var pendingIndex []int
+	resume := cp != nil && viper.GetBool("aws.discovery_resume")
+	maxAge := discoveryCheckpointMaxAge()
+	now := time.Now()
+	for i, check := range checks {
+		if resume {
+			if entry, ok := cp.lookup(profileName, region, check, maxAge, now); ok {
+				results[i] = LLMOperationResult{Operation: check, Result: entry.Result, Index: i}
+				continue
+			}
+		}
+		pending = append(pending, check)
+		pendingIndex = append(pendingIndex, i)
+	}
+	if resume {
+		logging.Infof("♻️  Discovery resume: reusing %d of %d service checks for %s/%s", len(checks)-len(pending), len(checks), profileName, region)
+	}
+
+	onResult := func(result LLMOperationResult) {
+		// Failed or interrupted checks are left out so a resumed run retries them.
+		if cp == nil || result.Error != nil || ctx.Err() != nil {
+			return
+		}
+		err := cp.record(discoveryCheckpointEntry{
+			Profile:   profileName,
+			Region:    region,
+			Service:   result.Operation,
+			Result:    result.Result,
+			CheckedAt: time.Now(),
+		})
+		if err != nil {
+			logging.Verbosef("⚠️  Failed to update discovery checkpoint: %v", err)
+		}
+	}
+
+	for _, result := range c.runOperations(ctx, discoveryOperations(pending), profile, onResult) {
+		index := pendingIndex[result.Index]
+		result.Index = index
+		results[index] = result
+	}
+	return formatOperationResults(results)
+}
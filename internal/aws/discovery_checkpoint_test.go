@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestDiscoveryCheckpointRecordAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "checkpoint.json")
+	cp, err := loadDiscoveryCheckpoint(path)
+	if err != nil {
+		t.Fatalf("load missing checkpoint: %v", err)
+	}
+	now := time.Now()
+	if err := cp.record(discoveryCheckpointEntry{Profile: "prod", Region: "us-east-1", Service: "check_s3_service", Result: "buckets", CheckedAt: now}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	reloaded, err := loadDiscoveryCheckpoint(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if entry, ok := reloaded.lookup("prod", "us-east-1", "check_s3_service", time.Hour, now); !ok || entry.Result != "buckets" {
+		t.Errorf("lookup = %+v, %v; want recorded entry", entry, ok)
+	}
+	if _, ok := reloaded.lookup("prod", "eu-west-1", "check_s3_service", time.Hour, now); ok {
+		t.Error("entry from another region should not match")
+	}
+	if _, ok := reloaded.lookup("prod", "us-east-1", "check_s3_service", time.Hour, now.Add(2*time.Hour)); ok {
+		t.Error("stale entry should not match")
+	}
+}
+
+func TestRunDiscoveryChecksResume(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	viper.Set("aws.discovery_checkpoint", path)
+	viper.Set("aws.discovery_resume", true)
+	viper.Set("aws.discovery_checkpoint_max_age", "1h")
+
+	cp, err := loadDiscoveryCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.record(discoveryCheckpointEntry{Profile: "prod", Region: "us-east-1", Service: "check_s3_service", Result: "recorded s3", CheckedAt: time.Now()})
+	cp.record(discoveryCheckpointEntry{Profile: "prod", Region: "us-east-1", Service: "check_sqs_service", Result: "stale sqs", CheckedAt: time.Now().Add(-2 * time.Hour)})
+
+	// Pending checks hit a cancelled context, so nothing reaches the CLI.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := &Client{}
+	profile := &AIProfile{AWSProfile: "prod", Region: "us-east-1"}
+	out := c.runDiscoveryChecks(ctx, []string{"check_s3_service", "check_sqs_service"}, profile)
+
+	if !strings.Contains(out, "✅ check_s3_service:\nrecorded s3") {
+		t.Errorf("fresh checkpoint entry not reused:\n%s", out)
+	}
+	if strings.Contains(out, "stale sqs") || !strings.Contains(out, "❌ check_sqs_service failed") {
+		t.Errorf("stale checkpoint entry should be re-checked:\n%s", out)
+	}
+
+	shared, err := openDiscoveryCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The stale entry was pruned on open and the interrupted re-check was
+	// not recorded in its place.
+	if entry, ok := shared.lookup("prod", "us-east-1", "check_sqs_service", 24*time.Hour, time.Now()); ok {
+		t.Errorf("stale or interrupted check left in checkpoint: %+v", entry)
+	}
+}
+
+func TestRunDiscoveryChecksWithoutRegion(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	viper.Set("aws.discovery_checkpoint", path)
+
+	f := newFakeCLI()
+	f.fixtures["s3api list-buckets"] = `{"Buckets": []}`
+	newFakeClient(f).runDiscoveryChecks(context.Background(), []string{"check_s3_service"}, &AIProfile{AWSProfile: "prod"})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no checkpoint without a resolved region, stat err = %v", err)
+	}
+}
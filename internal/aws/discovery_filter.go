@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"path"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// discoveryServiceChecks returns activeServiceChecks narrowed by
+// aws.discovery.include and aws.discovery.exclude. Each entry is a check name
+// (check_ec2_service), a service name (ec2) or a glob of either (iot*);
+// exclude wins over include, and an empty include keeps every check.
+func discoveryServiceChecks() []string {
+	include := normalizeRegions(viper.GetStringSlice("aws.discovery.include"))
+	exclude := normalizeRegions(viper.GetStringSlice("aws.discovery.exclude"))
+	return filterServiceChecks(activeServiceChecks, include, exclude)
+}
+
+// filterServiceChecks keeps the checks matching include (all when empty)
+// and not matching exclude.
+func filterServiceChecks(checks, include, exclude []string) []string {
+	if len(include) == 0 && len(exclude) == 0 {
+		return checks
+	}
+	filtered := make([]string, 0, len(checks))
+	for _, check := range checks {
+		if len(include) > 0 && !serviceCheckMatches(check, include) {
+			continue
+		}
+		if serviceCheckMatches(check, exclude) {
+			continue
+		}
+		filtered = append(filtered, check)
+	}
+	return filtered
+}
+
+// serviceCheckMatches reports whether check matches any pattern, comparing
+// against both the check name and its service name.
+func serviceCheckMatches(check string, patterns []string) bool {
+	service := strings.TrimSuffix(strings.TrimPrefix(check, "check_"), "_service")
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		for _, name := range []string{check, service} {
+			if ok, err := path.Match(pattern, name); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestFilterServiceChecks(t *testing.T) {
+	checks := []string{"check_ec2_service", "check_lambda_service", "check_rds_service", "check_iot_core_service", "check_iot_events_service"}
+
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{"no filters", nil, nil, checks},
+		{"short names", []string{"ec2", "RDS"}, nil, []string{"check_ec2_service", "check_rds_service"}},
+		{"full name and glob", []string{"check_lambda_service", "iot*"}, nil, []string{"check_lambda_service", "check_iot_core_service", "check_iot_events_service"}},
+		{"exclude only", nil, []string{"check_iot_*"}, []string{"check_ec2_service", "check_lambda_service", "check_rds_service"}},
+		{"exclude wins", []string{"iot*"}, []string{"iot_events"}, []string{"check_iot_core_service"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterServiceChecks(checks, tt.include, tt.exclude); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("filterServiceChecks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoveryServiceChecksReadsConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("aws.discovery.include", []string{"ec2,lambda", "rds"})
+	viper.Set("aws.discovery.exclude", []string{"lambda"})
+
+	want := []string{"check_ec2_service", "check_rds_service"}
+	if got := discoveryServiceChecks(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("discoveryServiceChecks() = %v, want %v", got, want)
+	}
+}
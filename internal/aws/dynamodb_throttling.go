@@ -0,0 +1,356 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// dynamoThrottleWindow is how far back analyze_dynamodb_throttling looks
+	// unless the investigation window says otherwise.
+	dynamoThrottleWindow = 3 * time.Hour
+	dynamoThrottlePeriod = 300
+	// dynamoCapacityWarnPercent flags consumed capacity close to the
+	// provisioned or maximum throughput.
+	dynamoCapacityWarnPercent = 80.0
+	// dynamoHotPartitionPercent: throttling while the table as a whole uses
+	// less than this share of its capacity points at a hot partition.
+	dynamoHotPartitionPercent = 50.0
+	// dynamoPartitionReadUnits and dynamoPartitionWriteUnits are the per
+	// partition limits that on-demand tables can still hit.
+	dynamoPartitionReadUnits  = 3000
+	dynamoPartitionWriteUnits = 1000
+	dynamoMaxTables           = 3
+)
+
+// dynamoThrottledOperations are the operations ThrottledRequests is fetched
+// for; the metric only exists per operation.
+var dynamoThrottledOperations = []string{"GetItem", "PutItem", "UpdateItem", "DeleteItem", "Query", "Scan", "BatchGetItem", "BatchWriteItem"}
+
+type dynamoThroughput struct {
+	ReadCapacityUnits  float64 `json:"ReadCapacityUnits"`
+	WriteCapacityUnits float64 `json:"WriteCapacityUnits"`
+}
+
+type dynamoOnDemandThroughput struct {
+	MaxReadRequestUnits  float64 `json:"MaxReadRequestUnits"`
+	MaxWriteRequestUnits float64 `json:"MaxWriteRequestUnits"`
+}
+
+type dynamoTable struct {
+	Name        string `json:"TableName"`
+	BillingMode struct {
+		Mode string `json:"BillingMode"`
+	} `json:"BillingModeSummary"`
+	Provisioned dynamoThroughput         `json:"ProvisionedThroughput"`
+	OnDemand    dynamoOnDemandThroughput `json:"OnDemandThroughput"`
+	Indexes     []struct {
+		Name        string                   `json:"IndexName"`
+		Provisioned dynamoThroughput         `json:"ProvisionedThroughput"`
+		OnDemand    dynamoOnDemandThroughput `json:"OnDemandThroughput"`
+	} `json:"GlobalSecondaryIndexes"`
+}
+
+// throughput returns the provisioned and maximum on-demand throughput of the
+// base table (index "") or one of its global secondary indexes.
+func (t dynamoTable) throughput(index string) (dynamoThroughput, dynamoOnDemandThroughput) {
+	for _, gsi := range t.Indexes {
+		if gsi.Name == index {
+			return gsi.Provisioned, gsi.OnDemand
+		}
+	}
+	return t.Provisioned, t.OnDemand
+}
+
+// onDemand reports whether the table bills per request. Tables created
+// before billing modes existed have no summary and are provisioned.
+func (t dynamoTable) onDemand() bool {
+	return t.BillingMode.Mode == "PAY_PER_REQUEST"
+}
+
+// dynamoTargetUsage is the throttling and capacity picture of a table or one
+// of its global secondary indexes over the window.
+type dynamoTargetUsage struct {
+	Index          string // empty for the base table
+	ReadThrottles  float64
+	WriteThrottles float64
+	// PeakRead and PeakWrite are the busiest period's consumed units per second.
+	PeakRead  float64
+	PeakWrite float64
+	// ReadLimit and WriteLimit are the provisioned or maximum units per
+	// second, zero when unknown.
+	ReadLimit  float64
+	WriteLimit float64
+}
+
+func (u dynamoTargetUsage) label() string {
+	if u.Index == "" {
+		return "table"
+	}
+	return "GSI " + u.Index
+}
+
+// analyzeDynamoDBThrottling is the analyze_dynamodb_throttling operation: it
+// compares throttle events with consumed and provisioned capacity for a table
+// and each of its global secondary indexes over the investigation window
+// (the last three hours by default).
+func (c *Client) analyzeDynamoDBThrottling(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	names := []string{getStringParam(input, "table_name", "")}
+	if names[0] == "" {
+		raw, err := c.execAWSCLI(ctx, []string{"dynamodb", "list-tables", "--output", "json"}, profile)
+		if err != nil {
+			return categorizeAWSError(err, "DynamoDB"), nil
+		}
+		var resp struct {
+			TableNames []string `json:"TableNames"`
+		}
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return "", fmt.Errorf("failed to parse DynamoDB tables: %w", err)
+		}
+		names = dynamoTablesInQuery(resp.TableNames, getStringParam(input, "query", ""))
+		if len(names) == 0 {
+			if len(resp.TableNames) == 0 {
+				return "No DynamoDB tables found.", nil
+			}
+			return fmt.Sprintf("Name a table to analyze (table_name). Tables: %s\n", strings.Join(limitStrings(resp.TableNames, 20), ", ")), nil
+		}
+	}
+
+	limits := c.dynamoAccountLimits(ctx, profile)
+	start, end := operationWindow(ctx, input, dynamoThrottleWindow)
+	var out strings.Builder
+	for i, name := range limitStrings(names, dynamoMaxTables) {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		raw, err := c.execAWSCLI(ctx, []string{"dynamodb", "describe-table", "--table-name", name, "--output", "json"}, profile)
+		if err != nil {
+			out.WriteString(categorizeAWSError(err, "DynamoDB") + "\n")
+			continue
+		}
+		var resp struct {
+			Table dynamoTable `json:"Table"`
+		}
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return "", fmt.Errorf("failed to parse table %s: %w", name, err)
+		}
+		table := resp.Table
+		usages := []dynamoTargetUsage{c.dynamoTargetUsage(ctx, table, "", limits, start, end, profile)}
+		for _, gsi := range table.Indexes {
+			usages = append(usages, c.dynamoTargetUsage(ctx, table, gsi.Name, limits, start, end, profile))
+		}
+		throttled := c.dynamoThrottledRequests(ctx, table.Name, start, end, profile)
+		out.WriteString(formatDynamoDBThrottling(table, usages, throttled, windowPhrase(start, end)))
+	}
+	return out.String(), nil
+}
+
+// dynamoTablesInQuery returns the tables a query names, preferring exact
+// names and falling back to tables whose name contains a query word.
+func dynamoTablesInQuery(tables []string, query string) []string {
+	if matched := rolesReferencedInQuery(tables, query); len(matched) > 0 {
+		return matched
+	}
+	ignore := map[string]bool{"dynamodb": true, "dynamo": true, "table": true, "tables": true, "throttling": true, "throttled": true, "throttle": true, "capacity": true, "partition": true, "index": true, "with": true, "from": true, "what": true, "why": true}
+	var matched []string
+	for _, table := range tables {
+		lower := strings.ToLower(table)
+		for _, word := range strings.Fields(strings.ToLower(query)) {
+			word = strings.Trim(word, ".,?!'\"")
+			if len(word) >= 4 && !ignore[word] && strings.Contains(lower, word) {
+				matched = append(matched, table)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// dynamoAccountLimits returns the per-table maximum read and write capacity
+// from describe-limits, or zeros when the call fails.
+func (c *Client) dynamoAccountLimits(ctx context.Context, profile *AIProfile) dynamoThroughput {
+	raw, err := c.execAWSCLI(ctx, []string{"dynamodb", "describe-limits", "--output", "json"}, profile)
+	if err != nil {
+		return dynamoThroughput{}
+	}
+	var resp struct {
+		TableMaxReadCapacityUnits  float64 `json:"TableMaxReadCapacityUnits"`
+		TableMaxWriteCapacityUnits float64 `json:"TableMaxWriteCapacityUnits"`
+	}
+	if json.Unmarshal([]byte(raw), &resp) != nil {
+		return dynamoThroughput{}
+	}
+	return dynamoThroughput{ReadCapacityUnits: resp.TableMaxReadCapacityUnits, WriteCapacityUnits: resp.TableMaxWriteCapacityUnits}
+}
+
+// dynamoTargetUsage fetches throttle events and consumed capacity for the
+// table (index "") or one GSI, with the limit they are measured against:
+// provisioned capacity, or for on-demand tables the configured maximum
+// throughput and then the account's per-table limit.
+func (c *Client) dynamoTargetUsage(ctx context.Context, table dynamoTable, index string, limits dynamoThroughput, start, end time.Time, profile *AIProfile) dynamoTargetUsage {
+	usage := dynamoTargetUsage{Index: index}
+	provisioned, maxThroughput := table.throughput(index)
+	window := end.Sub(start)
+	period := metricPeriodForWindow(dynamoThrottlePeriod, window)
+	dims := []metricDimension{{Name: "TableName", Value: table.Name}}
+	if index != "" {
+		dims = append(dims, metricDimension{Name: "GlobalSecondaryIndexName", Value: index})
+	}
+	series := func(metric, stat string) []metricDatapoint {
+		req := metricStatisticsRequest{Namespace: "AWS/DynamoDB", MetricName: metric, Dimensions: dims, Period: period, Stat: stat, Window: window}
+		raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile)
+		if err != nil {
+			return nil
+		}
+		points, _ := decodeMetricDatapoints(raw, req)
+		return points
+	}
+	sum := func(points []metricDatapoint) float64 {
+		total := 0.0
+		for _, p := range points {
+			total += p.Value
+		}
+		return total
+	}
+	perSecondPeak := func(points []metricDatapoint) float64 {
+		_, peak, _ := summarizeMetricDatapoints(points)
+		return peak / float64(period)
+	}
+
+	usage.ReadThrottles = sum(series("ReadThrottleEvents", "Sum"))
+	usage.WriteThrottles = sum(series("WriteThrottleEvents", "Sum"))
+	usage.PeakRead = perSecondPeak(series("ConsumedReadCapacityUnits", "Sum"))
+	usage.PeakWrite = perSecondPeak(series("ConsumedWriteCapacityUnits", "Sum"))
+
+	switch {
+	case !table.onDemand():
+		usage.ReadLimit, usage.WriteLimit = provisioned.ReadCapacityUnits, provisioned.WriteCapacityUnits
+		// Auto scaling changes provisioned capacity; the metric has the
+		// value in effect during the window.
+		if _, peak, _ := summarizeMetricDatapoints(series("ProvisionedReadCapacityUnits", "Maximum")); peak > 0 {
+			usage.ReadLimit = peak
+		}
+		if _, peak, _ := summarizeMetricDatapoints(series("ProvisionedWriteCapacityUnits", "Maximum")); peak > 0 {
+			usage.WriteLimit = peak
+		}
+	default:
+		usage.ReadLimit, usage.WriteLimit = limits.ReadCapacityUnits, limits.WriteCapacityUnits
+		if maxThroughput.MaxReadRequestUnits > 0 {
+			usage.ReadLimit = maxThroughput.MaxReadRequestUnits
+		}
+		if maxThroughput.MaxWriteRequestUnits > 0 {
+			usage.WriteLimit = maxThroughput.MaxWriteRequestUnits
+		}
+	}
+	return usage
+}
+
+// dynamoThrottledRequests sums ThrottledRequests per operation for the table.
+func (c *Client) dynamoThrottledRequests(ctx context.Context, table string, start, end time.Time, profile *AIProfile) map[string]float64 {
+	throttled := make(map[string]float64)
+	window := end.Sub(start)
+	for _, op := range dynamoThrottledOperations {
+		req := metricStatisticsRequest{
+			Namespace:  "AWS/DynamoDB",
+			MetricName: "ThrottledRequests",
+			Dimensions: []metricDimension{{Name: "Operation", Value: op}, {Name: "TableName", Value: table}},
+			Period:     metricPeriodForWindow(dynamoThrottlePeriod, window),
+			Stat:       "Sum",
+			Window:     window,
+		}
+		raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile)
+		if err != nil {
+			continue
+		}
+		points, _ := decodeMetricDatapoints(raw, req)
+		for _, p := range points {
+			throttled[op] += p.Value
+		}
+	}
+	return throttled
+}
+
+// dynamoFindings flags throttling per table or index, capacity close to the
+// limit, and throttling that the overall capacity does not explain.
+func dynamoFindings(onDemand bool, u dynamoTargetUsage) []string {
+	var findings []string
+	check := func(kind string, throttles, peak, limit, partitionLimit float64) {
+		pct := 0.0
+		if limit > 0 {
+			pct = peak / limit * 100
+		}
+		limitName := "provisioned"
+		if onDemand {
+			limitName = "maximum"
+		}
+		if throttles > 0 {
+			msg := fmt.Sprintf("%s: %.0f %s throttle events", u.label(), throttles, kind)
+			switch {
+			case limit > 0 && pct >= dynamoCapacityWarnPercent:
+				msg += fmt.Sprintf("; consumed %s peaked at %.0f/s of %.0f %s (%.0f%%), so capacity is too low", kind, peak, limit, limitName, pct)
+			case !onDemand && limit > 0 && pct < dynamoHotPartitionPercent:
+				msg += fmt.Sprintf(" while using only %.0f%% of %.0f %s %s units; likely a hot partition key", pct, limit, limitName, kind)
+			case onDemand && peak < partitionLimit:
+				msg += fmt.Sprintf(" below the %.0f/s per-partition limit; likely a hot partition key or a traffic spike faster than on-demand scaling", partitionLimit)
+			case onDemand:
+				msg += fmt.Sprintf(" at %.0f/s consumed; a single partition is capped at %.0f/s, so spread the key space", peak, partitionLimit)
+			}
+			findings = append(findings, msg)
+			return
+		}
+		if limit > 0 && pct >= dynamoCapacityWarnPercent {
+			findings = append(findings, fmt.Sprintf("%s: consumed %s peaked at %.0f/s of %.0f %s (%.0f%%); throttling is close", u.label(), kind, peak, limit, limitName, pct))
+		}
+	}
+	check("read", u.ReadThrottles, u.PeakRead, u.ReadLimit, dynamoPartitionReadUnits)
+	check("write", u.WriteThrottles, u.PeakWrite, u.WriteLimit, dynamoPartitionWriteUnits)
+	return findings
+}
+
+func formatDynamoDBThrottling(table dynamoTable, usages []dynamoTargetUsage, throttled map[string]float64, window string) string {
+	mode := "provisioned"
+	if table.onDemand() {
+		mode = "on-demand"
+	}
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("🧮 DynamoDB throttling: %s (%s, %d GSI)\n", table.Name, mode, len(table.Indexes)))
+	out.WriteString("============================\n")
+
+	var findings []string
+	for _, u := range usages {
+		findings = append(findings, dynamoFindings(table.onDemand(), u)...)
+	}
+	if len(findings) == 0 {
+		out.WriteString(fmt.Sprintf("✅ No throttling and capacity headroom on the table and its indexes %s\n", window))
+	}
+	for _, f := range findings {
+		out.WriteString(fmt.Sprintf("⚠️  %s\n", f))
+	}
+
+	var ops []string
+	for _, op := range dynamoThrottledOperations {
+		if throttled[op] > 0 {
+			ops = append(ops, fmt.Sprintf("%s %.0f", op, throttled[op]))
+		}
+	}
+	if len(ops) > 0 {
+		out.WriteString(fmt.Sprintf("Throttled requests by operation: %s\n", strings.Join(ops, ", ")))
+	}
+
+	out.WriteString("\nCapacity (peak consumed units/s vs limit):\n")
+	for _, u := range usages {
+		out.WriteString(fmt.Sprintf("  • %s: read %s, write %s; throttle events read %.0f, write %.0f\n",
+			u.label(), formatDynamoCapacity(u.PeakRead, u.ReadLimit), formatDynamoCapacity(u.PeakWrite, u.WriteLimit), u.ReadThrottles, u.WriteThrottles))
+	}
+	return out.String()
+}
+
+func formatDynamoCapacity(peak, limit float64) string {
+	if limit <= 0 {
+		return fmt.Sprintf("%.1f/s", peak)
+	}
+	return fmt.Sprintf("%.1f/s of %.0f", peak, limit)
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDynamoTablesInQuery(t *testing.T) {
+	tables := []string{"orders", "orders-archive", "customers-prod"}
+	if got := dynamoTablesInQuery(tables, "dynamodb throttling on orders table"); len(got) != 1 || got[0] != "orders" {
+		t.Errorf("exact name should win, got %v", got)
+	}
+	if got := dynamoTablesInQuery(tables, "why is the customers table throttled"); len(got) != 1 || got[0] != "customers-prod" {
+		t.Errorf("expected a partial match on customers, got %v", got)
+	}
+	if got := dynamoTablesInQuery(tables, "dynamodb throttling"); len(got) != 0 {
+		t.Errorf("generic words should not match, got %v", got)
+	}
+}
+
+func TestAnalyzeDynamoDBThrottlingNamesThrottledIndex(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["dynamodb list-tables"] = `{"TableNames": ["orders", "users"]}`
+	f.fixtures["dynamodb describe-limits"] = `{"TableMaxReadCapacityUnits": 40000, "TableMaxWriteCapacityUnits": 40000}`
+	f.fixtures["dynamodb describe-table --table-name orders"] = `{"Table": {
+		"TableName": "orders",
+		"BillingModeSummary": {"BillingMode": "PROVISIONED"},
+		"ProvisionedThroughput": {"ReadCapacityUnits": 100, "WriteCapacityUnits": 50},
+		"GlobalSecondaryIndexes": [{"IndexName": "by-customer", "ProvisionedThroughput": {"ReadCapacityUnits": 10, "WriteCapacityUnits": 5}}]
+	}}`
+	f.fixtures["cloudwatch get-metric-statistics"] = `{"Datapoints": []}`
+	idx := "Name=TableName,Value=orders Name=GlobalSecondaryIndexName,Value=by-customer"
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/DynamoDB --metric-name WriteThrottleEvents"] = `{"Datapoints": []}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/DynamoDB --metric-name ConsumedWriteCapacityUnits"] = `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Sum": 600}]}`
+	c := newFakeClient(f)
+	c.execFunc = func(ctx context.Context, args []string, profile *AIProfile) (string, error) {
+		cmd := strings.Join(args, " ")
+		if strings.Contains(cmd, idx) && strings.Contains(cmd, "--metric-name WriteThrottleEvents") {
+			return `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Sum": 42}]}`, nil
+		}
+		if strings.Contains(cmd, idx) && strings.Contains(cmd, "--metric-name ConsumedWriteCapacityUnits") {
+			return `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Sum": 1470}]}`, nil
+		}
+		if strings.Contains(cmd, "--metric-name ThrottledRequests") && strings.Contains(cmd, "Value=PutItem") {
+			return `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Sum": 42}]}`, nil
+		}
+		return f.exec(ctx, args, profile)
+	}
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_dynamodb_throttling", map[string]interface{}{"query": "dynamodb throttling on orders table"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"DynamoDB throttling: orders (provisioned, 1 GSI)",
+		"GSI by-customer: 42 write throttle events; consumed write peaked at 5/s of 5 provisioned (98%)",
+		"Throttled requests by operation: PutItem 42",
+		"table: read 0.0/s of 100, write 2.0/s of 50",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "⚠️  table:") {
+		t.Errorf("the base table is not throttled:\n%s", out)
+	}
+}
+
+func TestDynamoFindingsOnDemandHotPartition(t *testing.T) {
+	findings := dynamoFindings(true, dynamoTargetUsage{WriteThrottles: 7, PeakWrite: 400, WriteLimit: 40000})
+	if len(findings) != 1 || !strings.Contains(findings[0], "per-partition limit; likely a hot partition key") {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
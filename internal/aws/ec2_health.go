@@ -0,0 +1,351 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ec2HealthMaxListed caps the per-instance overview lines.
+const ec2HealthMaxListed = 20
+
+// ec2InstanceHealth combines describe-instances and describe-instance-status
+// for one instance.
+type ec2InstanceHealth struct {
+	ID        string
+	Name      string
+	Type      string
+	Zone      string
+	State     string
+	Lifecycle string // "spot" for Spot instances
+	// StateReasonCode and StateReasonMessage explain the last state change,
+	// e.g. Server.SpotInstanceTermination.
+	StateReasonCode    string
+	StateReasonMessage string
+	TransitionReason   string
+	InstanceStatus     ec2StatusSummary
+	SystemStatus       ec2StatusSummary
+	EBSStatus          ec2StatusSummary
+	Events             []ec2ScheduledEvent
+}
+
+// ec2StatusSummary is one status check: ok, impaired, initializing,
+// insufficient-data or not-applicable, with the failed details.
+type ec2StatusSummary struct {
+	Status  string `json:"Status"`
+	Details []struct {
+		Name          string `json:"Name"`
+		Status        string `json:"Status"`
+		ImpairedSince string `json:"ImpairedSince"`
+	} `json:"Details"`
+}
+
+type ec2ScheduledEvent struct {
+	Code        string    `json:"Code"`
+	Description string    `json:"Description"`
+	NotBefore   time.Time `json:"NotBefore"`
+	NotAfter    time.Time `json:"NotAfter"`
+}
+
+// ec2StateReasons explains the StateReason codes behind an unexpected stop
+// or termination.
+var ec2StateReasons = map[string]string{
+	"Server.SpotInstanceTermination":  "Spot interruption: AWS reclaimed the capacity; use an Auto Scaling group with capacity-rebalance or on-demand for this workload",
+	"Server.SpotInstanceShutdown":     "Spot interruption: AWS stopped the instance to reclaim capacity; it restarts when capacity returns",
+	"Server.InternalError":            "an internal AWS error stopped the instance; start it again and open a support case if it repeats",
+	"Server.ScheduledStop":            "stopped by a scheduled retirement or maintenance event",
+	"Client.VolumeLimitExceeded":      "the account hit its EBS volume limit while starting",
+	"Client.InvalidSnapshot.NotFound": "the root volume's snapshot no longer exists",
+	"Client.InternalError":            "the instance could not start, usually because its EBS volumes are encrypted with a KMS key it cannot use",
+}
+
+// analyzeEC2Health is the analyze_ec2_health operation: it flags failed
+// system, instance and EBS status checks, instances stopped or terminated by
+// AWS (Spot interruptions, insufficient capacity), and upcoming scheduled
+// maintenance or retirement.
+func (c *Client) analyzeEC2Health(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	instanceID := getStringParam(input, "instance_id", "")
+	query := getStringParam(input, "query", "")
+
+	var out strings.Builder
+	out.WriteString("🖥️  EC2 instance health\n")
+	out.WriteString("============================\n")
+
+	args := []string{"ec2", "describe-instances", "--output", "json"}
+	if instanceID != "" {
+		args = append(args, "--instance-ids", instanceID)
+	}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return categorizeAWSError(err, "EC2"), nil
+	}
+	instances, err := parseEC2HealthInstances(raw)
+	if err != nil {
+		return fmt.Sprintf("Failed to parse instances: %v", err), nil
+	}
+	if len(instances) == 0 {
+		out.WriteString("No EC2 instances found\n")
+		return out.String(), nil
+	}
+	targeted := instanceID != ""
+	if !targeted {
+		if matched := ec2InstancesInQuery(instances, query); len(matched) > 0 {
+			instances, targeted = matched, true
+		}
+	}
+
+	args = []string{"ec2", "describe-instance-status", "--include-all-instances", "--output", "json"}
+	if targeted {
+		args = append(args, "--instance-ids")
+		for _, inst := range instances {
+			args = append(args, inst.ID)
+		}
+	}
+	statusErr := ""
+	if raw, err := c.execAWSCLI(ctx, args, profile); err != nil {
+		statusErr = categorizeAWSError(err, "EC2")
+	} else if err := mergeEC2InstanceStatus(instances, raw); err != nil {
+		statusErr = fmt.Sprintf("failed to parse instance status: %v", err)
+	}
+
+	out.WriteString(formatEC2Health(instances, targeted, time.Now().UTC()))
+	if statusErr != "" {
+		out.WriteString("\nStatus checks and scheduled events could not be read: " + statusErr + "\n")
+	}
+	return out.String(), nil
+}
+
+func parseEC2HealthInstances(raw string) ([]*ec2InstanceHealth, error) {
+	var resp struct {
+		Reservations []struct {
+			Instances []struct {
+				InstanceID        string `json:"InstanceId"`
+				InstanceType      string `json:"InstanceType"`
+				InstanceLifecycle string `json:"InstanceLifecycle"`
+				Placement         struct {
+					AvailabilityZone string `json:"AvailabilityZone"`
+				} `json:"Placement"`
+				State struct {
+					Name string `json:"Name"`
+				} `json:"State"`
+				StateReason struct {
+					Code    string `json:"Code"`
+					Message string `json:"Message"`
+				} `json:"StateReason"`
+				StateTransitionReason string `json:"StateTransitionReason"`
+				Tags                  []struct {
+					Key   string `json:"Key"`
+					Value string `json:"Value"`
+				} `json:"Tags"`
+			} `json:"Instances"`
+		} `json:"Reservations"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, err
+	}
+	var instances []*ec2InstanceHealth
+	for _, r := range resp.Reservations {
+		for _, inst := range r.Instances {
+			h := &ec2InstanceHealth{
+				ID:                 inst.InstanceID,
+				Type:               inst.InstanceType,
+				Zone:               inst.Placement.AvailabilityZone,
+				State:              inst.State.Name,
+				Lifecycle:          inst.InstanceLifecycle,
+				StateReasonCode:    inst.StateReason.Code,
+				StateReasonMessage: inst.StateReason.Message,
+				TransitionReason:   inst.StateTransitionReason,
+			}
+			for _, tag := range inst.Tags {
+				if tag.Key == "Name" {
+					h.Name = tag.Value
+				}
+			}
+			instances = append(instances, h)
+		}
+	}
+	return instances, nil
+}
+
+// ec2InstancesInQuery returns the instances whose ID or Name tag the query
+// mentions.
+func ec2InstancesInQuery(instances []*ec2InstanceHealth, query string) []*ec2InstanceHealth {
+	var refs []string
+	for _, inst := range instances {
+		refs = append(refs, inst.ID)
+		if inst.Name != "" {
+			refs = append(refs, inst.Name)
+		}
+	}
+	mentioned := make(map[string]bool)
+	for _, ref := range rolesReferencedInQuery(refs, query) {
+		mentioned[strings.ToLower(ref)] = true
+	}
+	var matched []*ec2InstanceHealth
+	for _, inst := range instances {
+		if mentioned[strings.ToLower(inst.ID)] || (inst.Name != "" && mentioned[strings.ToLower(inst.Name)]) {
+			matched = append(matched, inst)
+		}
+	}
+	return matched
+}
+
+func mergeEC2InstanceStatus(instances []*ec2InstanceHealth, raw string) error {
+	var resp struct {
+		InstanceStatuses []struct {
+			InstanceID        string              `json:"InstanceId"`
+			InstanceStatus    ec2StatusSummary    `json:"InstanceStatus"`
+			SystemStatus      ec2StatusSummary    `json:"SystemStatus"`
+			AttachedEbsStatus ec2StatusSummary    `json:"AttachedEbsStatus"`
+			Events            []ec2ScheduledEvent `json:"Events"`
+		} `json:"InstanceStatuses"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return err
+	}
+	byID := make(map[string]*ec2InstanceHealth, len(instances))
+	for _, inst := range instances {
+		byID[inst.ID] = inst
+	}
+	for _, s := range resp.InstanceStatuses {
+		inst, ok := byID[s.InstanceID]
+		if !ok {
+			continue
+		}
+		inst.InstanceStatus = s.InstanceStatus
+		inst.SystemStatus = s.SystemStatus
+		inst.EBSStatus = s.AttachedEbsStatus
+		inst.Events = s.Events
+	}
+	return nil
+}
+
+// ec2HealthFindings flags failed status checks, stops and terminations AWS
+// caused, and scheduled events that have not completed. Stops a user or the
+// OS initiated are only reported for instances the caller asked about.
+func ec2HealthFindings(inst *ec2InstanceHealth, targeted bool, now time.Time) []string {
+	var findings []string
+	if inst.SystemStatus.Status == "impaired" {
+		findings = append(findings, "🔴 system status check failed"+impairedSince(inst.SystemStatus)+": the underlying host or its network is failing on the AWS side; stop and start the instance (a reboot stays on the same host) to move it to healthy hardware")
+	}
+	if inst.InstanceStatus.Status == "impaired" {
+		findings = append(findings, "🔴 instance status check failed"+impairedSince(inst.InstanceStatus)+": the OS is not answering; check get-console-output for a kernel panic, full disk, bad fstab entry or network misconfiguration, then reboot")
+	}
+	if inst.EBSStatus.Status == "impaired" {
+		findings = append(findings, "🔴 attached EBS status check failed"+impairedSince(inst.EBSStatus)+": a volume is unreachable or cannot complete I/O")
+	}
+
+	switch inst.State {
+	case "stopped", "stopping", "terminated", "shutting-down":
+		reason, ok := ec2StateReasons[inst.StateReasonCode]
+		if inst.StateReasonCode == "Server.InsufficientInstanceCapacity" {
+			reason, ok = fmt.Sprintf("AWS had no %s capacity in %s; retry later, or start it in another Availability Zone or as another instance type",
+				valueOr(inst.Type, "instance"), valueOr(inst.Zone, "its Availability Zone")), true
+		}
+		if ok {
+			findings = append(findings, fmt.Sprintf("🔴 %s (%s): %s", inst.State, inst.StateReasonCode, reason))
+		} else if targeted {
+			why := valueOr(inst.StateReasonMessage, valueOr(inst.TransitionReason, "no reason recorded"))
+			findings = append(findings, fmt.Sprintf("ℹ️  %s: %s", inst.State, why))
+		}
+	}
+
+	for _, event := range inst.Events {
+		if strings.HasPrefix(event.Description, "[Completed]") || strings.HasPrefix(event.Description, "[Canceled]") {
+			continue
+		}
+		when := "scheduled for " + event.NotBefore.UTC().Format("2006-01-02 15:04 UTC")
+		if !event.NotBefore.IsZero() && event.NotBefore.Before(now) {
+			when = "due since " + event.NotBefore.UTC().Format("2006-01-02 15:04 UTC")
+		}
+		advice := ""
+		switch event.Code {
+		case "instance-retirement", "instance-stop":
+			advice = "; stop and start it before then to move to new hardware on your own schedule"
+		case "system-reboot", "system-maintenance":
+			advice = "; AWS will reboot or service the host, so plan for the downtime"
+		case "instance-reboot":
+			advice = "; reboot it yourself before then to complete the event early"
+		}
+		findings = append(findings, fmt.Sprintf("📅 %s %s: %s%s", event.Code, when, strings.TrimSpace(event.Description), advice))
+	}
+	return findings
+}
+
+func impairedSince(s ec2StatusSummary) string {
+	var failed []string
+	since := ""
+	for _, d := range s.Details {
+		if d.Status == "failed" {
+			failed = append(failed, d.Name)
+			if d.ImpairedSince != "" && since == "" {
+				since = d.ImpairedSince
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	text := " (" + strings.Join(failed, ", ")
+	if since != "" {
+		text += " since " + since
+	}
+	return text + ")"
+}
+
+func formatEC2Health(instances []*ec2InstanceHealth, targeted bool, now time.Time) string {
+	var out strings.Builder
+	type flaggedInstance struct {
+		inst     *ec2InstanceHealth
+		findings []string
+	}
+	var flagged []flaggedInstance
+	for _, inst := range instances {
+		if findings := ec2HealthFindings(inst, targeted, now); len(findings) > 0 {
+			flagged = append(flagged, flaggedInstance{inst, findings})
+		}
+	}
+	sort.SliceStable(flagged, func(i, j int) bool {
+		return strings.HasPrefix(flagged[i].findings[0], "🔴") && !strings.HasPrefix(flagged[j].findings[0], "🔴")
+	})
+
+	for _, f := range flagged {
+		out.WriteString(fmt.Sprintf("⚠️  %s:\n", ec2InstanceLabel(f.inst)))
+		for _, finding := range f.findings {
+			out.WriteString("    " + finding + "\n")
+		}
+	}
+	if len(flagged) == 0 {
+		out.WriteString(fmt.Sprintf("✅ All %d instances pass their status checks with no pending scheduled events\n", len(instances)))
+	}
+
+	out.WriteString("\nInstances:\n")
+	for i, inst := range instances {
+		if i == ec2HealthMaxListed {
+			out.WriteString(fmt.Sprintf("  … %d more; pass instance_id to check one\n", len(instances)-i))
+			break
+		}
+		line := fmt.Sprintf("  • %s: %s", ec2InstanceLabel(inst), valueOr(inst.State, "unknown state"))
+		if inst.Type != "" {
+			line += ", " + inst.Type
+		}
+		if inst.Lifecycle == "spot" {
+			line += " (spot)"
+		}
+		if inst.SystemStatus.Status != "" || inst.InstanceStatus.Status != "" {
+			line += fmt.Sprintf(", system %s / instance %s", valueOr(inst.SystemStatus.Status, "n/a"), valueOr(inst.InstanceStatus.Status, "n/a"))
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
+
+func ec2InstanceLabel(inst *ec2InstanceHealth) string {
+	if inst.Name == "" {
+		return inst.ID
+	}
+	return fmt.Sprintf("%s (%s)", inst.Name, inst.ID)
+}
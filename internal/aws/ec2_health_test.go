@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeEC2Health(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["ec2 describe-instances"] = `{"Reservations": [{"Instances": [
+		{"InstanceId": "i-web", "InstanceType": "t3.small", "State": {"Name": "running"},
+		 "Placement": {"AvailabilityZone": "us-east-1a"}, "Tags": [{"Key": "Name", "Value": "web-1"}]},
+		{"InstanceId": "i-worker", "InstanceType": "c5.large", "InstanceLifecycle": "spot", "State": {"Name": "terminated"},
+		 "StateReason": {"Code": "Server.SpotInstanceTermination", "Message": "Server.SpotInstanceTermination: Spot instance termination"},
+		 "Tags": [{"Key": "Name", "Value": "worker"}]},
+		{"InstanceId": "i-batch", "InstanceType": "m5.large", "State": {"Name": "stopped"},
+		 "StateReason": {"Code": "Client.UserInitiatedShutdown", "Message": "Client.UserInitiatedShutdown: User initiated shutdown"}}
+	]}]}`
+	f.fixtures["ec2 describe-instance-status --include-all-instances"] = `{"InstanceStatuses": [
+		{"InstanceId": "i-web",
+		 "SystemStatus": {"Status": "ok", "Details": [{"Name": "reachability", "Status": "passed"}]},
+		 "InstanceStatus": {"Status": "impaired", "Details": [{"Name": "reachability", "Status": "failed", "ImpairedSince": "2024-05-01T09:00:00Z"}]},
+		 "Events": [
+			{"Code": "instance-retirement", "Description": "The instance is running on degraded hardware", "NotBefore": "2099-01-05T10:00:00Z"},
+			{"Code": "system-reboot", "Description": "[Completed] Scheduled reboot", "NotBefore": "2024-01-01T00:00:00Z"}]},
+		{"InstanceId": "i-batch", "SystemStatus": {"Status": "not-applicable"}, "InstanceStatus": {"Status": "not-applicable"}}
+	]}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "analyze_ec2_health", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"⚠️  web-1 (i-web):",
+		"🔴 instance status check failed (reachability since 2024-05-01T09:00:00Z)",
+		"📅 instance-retirement scheduled for 2099-01-05 10:00 UTC",
+		"⚠️  worker (i-worker):",
+		"🔴 terminated (Server.SpotInstanceTermination): Spot interruption",
+		"worker (i-worker): terminated, c5.large (spot)",
+		"web-1 (i-web): running, t3.small, system ok / instance impaired",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"Scheduled reboot", "User initiated shutdown"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output should not contain %q:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestAnalyzeEC2HealthTargetsQueriedInstance(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["ec2 describe-instances"] = `{"Reservations": [{"Instances": [
+		{"InstanceId": "i-web", "State": {"Name": "running"}, "Tags": [{"Key": "Name", "Value": "web-1"}]},
+		{"InstanceId": "i-batch", "InstanceType": "m5.large", "State": {"Name": "stopped"},
+		 "StateReason": {"Code": "Client.UserInitiatedShutdown", "Message": "Client.UserInitiatedShutdown: User initiated shutdown"}}
+	]}]}`
+	f.fixtures["ec2 describe-instance-status --include-all-instances --output json --instance-ids i-batch"] = `{"InstanceStatuses": []}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "analyze_ec2_health", map[string]interface{}{"query": "why is i-batch down"}, &AIProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "ℹ️  stopped: Client.UserInitiatedShutdown: User initiated shutdown") {
+		t.Errorf("expected the user-initiated stop explained for the queried instance:\n%s", out)
+	}
+	if strings.Contains(out, "web-1") {
+		t.Errorf("expected only the queried instance:\n%s", out)
+	}
+}
+
+func TestEC2HealthFindingsInsufficientCapacity(t *testing.T) {
+	inst := &ec2InstanceHealth{ID: "i-1", Type: "p4d.24xlarge", Zone: "us-east-1b", State: "stopped", StateReasonCode: "Server.InsufficientInstanceCapacity"}
+	findings := ec2HealthFindings(inst, false, time.Now())
+	if len(findings) != 1 || !strings.Contains(findings[0], "no p4d.24xlarge capacity in us-east-1b") {
+		t.Errorf("findings = %v", findings)
+	}
+}
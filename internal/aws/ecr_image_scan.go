@@ -0,0 +1,353 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// ecrScanImageLimit bounds how many discovered workload images are scanned.
+	ecrScanImageLimit = 5
+	// ecrTopFindings is how many CVEs are listed per image.
+	ecrTopFindings = 5
+)
+
+// ecrSeverities orders scan severities from most to least urgent.
+var ecrSeverities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "INFORMATIONAL", "UNDEFINED"}
+
+// ecrImagePattern matches private ECR image URIs:
+// <account>.dkr.ecr.<region>.amazonaws.com[.cn]/<repo>[:tag|@digest].
+var ecrImagePattern = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?/([^:@\s]+)(?::([^@\s]+))?(?:@(sha256:[a-f0-9]+))?$`)
+
+// ecrImageRef identifies one image in a private ECR registry.
+type ecrImageRef struct {
+	RegistryID string
+	Region     string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+func (r ecrImageRef) String() string {
+	if r.Digest != "" {
+		return r.Repository + "@" + r.Digest
+	}
+	return r.Repository + ":" + r.Tag
+}
+
+// imageIDArg is the --image-id value for the ECR CLI.
+func (r ecrImageRef) imageIDArg() string {
+	if r.Digest != "" {
+		return "imageDigest=" + r.Digest
+	}
+	return "imageTag=" + r.Tag
+}
+
+// parseECRImage parses an ECR image URI; images hosted elsewhere (Docker Hub,
+// public ECR, GHCR) return false.
+func parseECRImage(image string) (ecrImageRef, bool) {
+	m := ecrImagePattern.FindStringSubmatch(strings.TrimSpace(image))
+	if m == nil {
+		return ecrImageRef{}, false
+	}
+	ref := ecrImageRef{RegistryID: m[1], Region: m[2], Repository: m[3], Tag: m[4], Digest: m[5]}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, true
+}
+
+type ecrScanFinding struct {
+	Name       string `json:"name"`
+	Severity   string `json:"severity"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+}
+
+type ecrEnhancedFinding struct {
+	Severity                    string `json:"severity"`
+	PackageVulnerabilityDetails struct {
+		VulnerabilityID    string `json:"vulnerabilityId"`
+		VulnerablePackages []struct {
+			Name string `json:"name"`
+		} `json:"vulnerablePackages"`
+	} `json:"packageVulnerabilityDetails"`
+}
+
+type ecrScanFindingsResponse struct {
+	ImageScanStatus struct {
+		Status      string `json:"status"`
+		Description string `json:"description"`
+	} `json:"imageScanStatus"`
+	ImageScanFindings struct {
+		FindingSeverityCounts map[string]int       `json:"findingSeverityCounts"`
+		Findings              []ecrScanFinding     `json:"findings"`
+		EnhancedFindings      []ecrEnhancedFinding `json:"enhancedFindings"`
+	} `json:"imageScanFindings"`
+}
+
+// ecrCVE is one vulnerability with the packages it affects.
+type ecrCVE struct {
+	ID       string
+	Severity string
+	Packages []string
+}
+
+// analyzeECRImageScan is the analyze_ecr_image_scan operation: it summarizes
+// ECR scan findings by severity for one image (repository_name plus
+// image_tag or image_digest, or a full image URI), or for the ECR images
+// referenced by running ECS services and EKS pods when none is given.
+func (c *Client) analyzeECRImageScan(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	profile = resolvedProfile(profile)
+	var refs []ecrImageRef
+	var notes []string
+	switch {
+	case getStringParam(input, "image", "") != "":
+		image := getStringParam(input, "image", "")
+		ref, ok := parseECRImage(image)
+		if !ok {
+			return fmt.Sprintf("%s is not a private ECR image, so there are no ECR scan findings for it.\n", image), nil
+		}
+		refs = append(refs, ref)
+	case getStringParam(input, "repository_name", "") != "":
+		refs = append(refs, ecrImageRef{
+			Repository: getStringParam(input, "repository_name", ""),
+			Tag:        getStringParam(input, "image_tag", "latest"),
+			Digest:     getStringParam(input, "image_digest", ""),
+		})
+	default:
+		var images []string
+		images, notes = c.workloadImages(ctx, profile)
+		for _, image := range images {
+			if ref, ok := parseECRImage(image); ok {
+				refs = append(refs, ref)
+			}
+		}
+		if len(refs) == 0 {
+			msg := "No ECR images found in running ECS services or EKS pods; pass repository_name and image_tag to check one image.\n"
+			for _, note := range notes {
+				msg += "ℹ️  " + note + "\n"
+			}
+			return msg, nil
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("🛡️  ECR image scan findings\n")
+	out.WriteString("==========================\n")
+	if len(refs) > ecrScanImageLimit {
+		out.WriteString(fmt.Sprintf("Checking the first %d of %d workload images.\n", ecrScanImageLimit, len(refs)))
+		refs = refs[:ecrScanImageLimit]
+	}
+	for _, note := range notes {
+		out.WriteString("ℹ️  " + note + "\n")
+	}
+	for _, ref := range refs {
+		out.WriteString("\n")
+		out.WriteString(c.ecrImageScanSummary(ctx, ref, profile))
+	}
+	return out.String(), nil
+}
+
+// ecrImageScanSummary fetches and summarizes the scan findings of one image.
+func (c *Client) ecrImageScanSummary(ctx context.Context, ref ecrImageRef, profile *AIProfile) string {
+	if ref.Region != "" && ref.Region != profile.Region {
+		profile = profileForRegion(profile, ref.Region)
+	}
+	args := []string{"ecr", "describe-image-scan-findings", "--repository-name", ref.Repository, "--image-id", ref.imageIDArg(), "--output", "json"}
+	if ref.RegistryID != "" {
+		args = append(args, "--registry-id", ref.RegistryID)
+	}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		if strings.Contains(err.Error(), "ScanNotFoundException") {
+			return c.ecrScanNotFound(ctx, ref, profile)
+		}
+		return fmt.Sprintf("%s: %s\n", ref, strings.TrimSpace(categorizeAWSError(err, "ECR")))
+	}
+	var resp ecrScanFindingsResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return fmt.Sprintf("%s: could not parse scan findings: %v\n", ref, err)
+	}
+	return formatECRScanFindings(ref, resp)
+}
+
+// ecrScanNotFound explains a missing scan, pointing at scan-on-push when the
+// repository does not scan new images.
+func (c *Client) ecrScanNotFound(ctx context.Context, ref ecrImageRef, profile *AIProfile) string {
+	args := []string{"ecr", "describe-repositories", "--repository-names", ref.Repository, "--output", "json", "--query", "repositories[0].imageScanningConfiguration.scanOnPush"}
+	if ref.RegistryID != "" {
+		args = append(args, "--registry-id", ref.RegistryID)
+	}
+	raw, _ := c.execAWSCLI(ctx, args, profile)
+	if strings.TrimSpace(raw) == "true" {
+		return fmt.Sprintf("%s: ⚠️  no scan results; scan-on-push is enabled, so the image was likely pushed before it was turned on. Run: aws ecr start-image-scan --repository-name %s --image-id %s\n", ref, ref.Repository, ref.imageIDArg())
+	}
+	return fmt.Sprintf("%s: ⚠️  image scanning is not enabled for this repository, so known CVEs are not surfaced. Enable scan-on-push: aws ecr put-image-scanning-configuration --repository-name %s --image-scanning-configuration scanOnPush=true (or turn on enhanced scanning for the registry)\n", ref, ref.Repository)
+}
+
+func formatECRScanFindings(ref ecrImageRef, resp ecrScanFindingsResponse) string {
+	status := strings.ToUpper(resp.ImageScanStatus.Status)
+	switch status {
+	case "IN_PROGRESS", "PENDING":
+		return fmt.Sprintf("%s: ⏳ scan %s\n", ref, strings.ToLower(strings.ReplaceAll(status, "_", " ")))
+	case "FAILED", "UNSUPPORTED_IMAGE", "SCAN_ELIGIBILITY_EXPIRED", "FINDINGS_UNAVAILABLE":
+		return fmt.Sprintf("%s: ⚠️  scan status %s: %s\n", ref, status, resp.ImageScanStatus.Description)
+	}
+
+	counts := resp.ImageScanFindings.FindingSeverityCounts
+	cves := ecrCVEs(resp)
+	if len(counts) == 0 {
+		counts = make(map[string]int)
+		for _, cve := range cves {
+			counts[cve.Severity]++
+		}
+	}
+	total := 0
+	var parts []string
+	for _, severity := range ecrSeverities {
+		if n := counts[severity]; n > 0 {
+			total += n
+			parts = append(parts, fmt.Sprintf("%s %d", severity, n))
+		}
+	}
+	if total == 0 {
+		return fmt.Sprintf("%s: ✅ no known vulnerabilities\n", ref)
+	}
+
+	icon := "ℹ️ "
+	if counts["CRITICAL"] > 0 || counts["HIGH"] > 0 {
+		icon = "🚨"
+	}
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("%s: %s %d findings (%s)\n", ref, icon, total, strings.Join(parts, ", ")))
+	for i, cve := range cves {
+		if i == ecrTopFindings {
+			out.WriteString(fmt.Sprintf("  ... and %d more\n", len(cves)-i))
+			break
+		}
+		line := fmt.Sprintf("  - %s %s", cve.Severity, cve.ID)
+		if len(cve.Packages) > 0 {
+			line += " in " + strings.Join(cve.Packages, ", ")
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
+
+// ecrCVEs merges basic and enhanced findings, most severe first.
+func ecrCVEs(resp ecrScanFindingsResponse) []ecrCVE {
+	var cves []ecrCVE
+	for _, f := range resp.ImageScanFindings.Findings {
+		cve := ecrCVE{ID: f.Name, Severity: strings.ToUpper(f.Severity)}
+		for _, attr := range f.Attributes {
+			if attr.Key == "package_name" && attr.Value != "" {
+				cve.Packages = append(cve.Packages, attr.Value)
+			}
+		}
+		cves = append(cves, cve)
+	}
+	for _, f := range resp.ImageScanFindings.EnhancedFindings {
+		cve := ecrCVE{ID: f.PackageVulnerabilityDetails.VulnerabilityID, Severity: strings.ToUpper(f.Severity)}
+		for _, pkg := range f.PackageVulnerabilityDetails.VulnerablePackages {
+			cve.Packages = append(cve.Packages, pkg.Name)
+		}
+		cves = append(cves, cve)
+	}
+	rank := make(map[string]int, len(ecrSeverities))
+	for i, severity := range ecrSeverities {
+		rank[severity] = i
+	}
+	sort.SliceStable(cves, func(i, j int) bool {
+		ri, iok := rank[cves[i].Severity]
+		rj, jok := rank[cves[j].Severity]
+		if !iok {
+			ri = len(ecrSeverities)
+		}
+		if !jok {
+			rj = len(ecrSeverities)
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return cves[i].ID < cves[j].ID
+	})
+	return cves
+}
+
+// workloadImages returns the images of running ECS services and, when
+// kubectl is installed, EKS pods, with notes on what could not be checked.
+func (c *Client) workloadImages(ctx context.Context, profile *AIProfile) ([]string, []string) {
+	var images, notes []string
+	seen := make(map[string]bool)
+	add := func(image string) {
+		if image != "" && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+
+	clusters, err := c.listECSArns(ctx, []string{"ecs", "list-clusters", "--output", "json", "--query", "clusterArns"}, profile)
+	if err != nil {
+		notes = append(notes, "could not list ECS clusters: "+strings.TrimSpace(categorizeAWSError(err, "ECS")))
+	}
+	for _, cluster := range limitStrings(clusters, 5) {
+		services, err := c.listECSArns(ctx, []string{"ecs", "list-services", "--cluster", cluster, "--output", "json", "--query", "serviceArns"}, profile)
+		if err != nil || len(services) == 0 {
+			continue
+		}
+		args := append([]string{"ecs", "describe-services", "--cluster", cluster, "--services"}, limitStrings(services, 10)...)
+		args = append(args, "--output", "json", "--query", "services[].taskDefinition")
+		taskDefs, err := c.listECSArns(ctx, args, profile)
+		if err != nil {
+			continue
+		}
+		for _, taskDef := range taskDefs {
+			td, err := c.describeECSTaskDefinition(ctx, taskDef, profile)
+			if err != nil {
+				continue
+			}
+			for _, container := range td.Containers {
+				add(container.Image)
+			}
+		}
+	}
+
+	eksClusters, err := c.listEKSClusterNames(ctx, profile)
+	if err != nil {
+		return images, append(notes, "EKS clusters could not be listed, so images of EKS pods were not checked: "+err.Error())
+	}
+	if len(eksClusters) == 0 {
+		return images, notes
+	}
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return images, append(notes, "kubectl is not installed, so images of EKS pods were not checked")
+	}
+	if c.dryRun {
+		return images, notes
+	}
+	for _, cluster := range limitStrings(eksClusters, 3) {
+		kubeconfig, cleanup, err := c.eksKubeconfig(ctx, cluster, profile)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("could not access EKS cluster %s: %v", cluster, err))
+			continue
+		}
+		raw, err := runKubectl(ctx, kubeconfig, "get", "pods", "-A", "-o", "jsonpath={.items[*].spec.containers[*].image}")
+		cleanup()
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("could not list pods in EKS cluster %s: %v", cluster, err))
+			continue
+		}
+		for _, image := range strings.Fields(raw) {
+			add(image)
+		}
+	}
+	return images, notes
+}
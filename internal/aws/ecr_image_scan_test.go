@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseECRImage(t *testing.T) {
+	ref, ok := parseECRImage("123456789012.dkr.ecr.eu-west-1.amazonaws.com/team/api:v1.2")
+	if !ok || ref.RegistryID != "123456789012" || ref.Region != "eu-west-1" || ref.Repository != "team/api" || ref.Tag != "v1.2" {
+		t.Errorf("unexpected ref %+v", ref)
+	}
+	ref, ok = parseECRImage("123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn/api@sha256:abc123")
+	if !ok || ref.Digest != "sha256:abc123" || ref.imageIDArg() != "imageDigest=sha256:abc123" {
+		t.Errorf("unexpected digest ref %+v", ref)
+	}
+	if ref, _ := parseECRImage("123456789012.dkr.ecr.us-east-1.amazonaws.com/api"); ref.Tag != "latest" {
+		t.Errorf("untagged image should default to latest, got %+v", ref)
+	}
+	for _, image := range []string{"nginx:1.25", "public.ecr.aws/docker/library/redis:7", "ghcr.io/org/app:main"} {
+		if _, ok := parseECRImage(image); ok {
+			t.Errorf("%s is not a private ECR image", image)
+		}
+	}
+}
+
+func TestAnalyzeECRImageScanSummarizesBySeverity(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["ecr describe-image-scan-findings --repository-name api --image-id imageTag=v2"] = `{
+		"imageScanStatus": {"status": "COMPLETE"},
+		"imageScanFindings": {
+			"findingSeverityCounts": {"CRITICAL": 1, "HIGH": 2, "LOW": 4},
+			"findings": [
+				{"name": "CVE-2024-0002", "severity": "HIGH", "attributes": [{"key": "package_name", "value": "curl"}]},
+				{"name": "CVE-2024-0001", "severity": "CRITICAL", "attributes": [{"key": "package_name", "value": "openssl"}, {"key": "package_version", "value": "3.0.1"}]},
+				{"name": "CVE-2024-0003", "severity": "LOW", "attributes": []}
+			]
+		}
+	}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_ecr_image_scan", map[string]interface{}{"repository_name": "api", "image_tag": "v2"}, &AIProfile{Region: "us-east-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"api:v2: 🚨 7 findings (CRITICAL 1, HIGH 2, LOW 4)",
+		"  - CRITICAL CVE-2024-0001 in openssl\n  - HIGH CVE-2024-0002 in curl\n  - LOW CVE-2024-0003",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestAnalyzeECRImageScanSuggestsScanOnPush(t *testing.T) {
+	f := newFakeCLI()
+	f.failures["ecr describe-image-scan-findings"] = errors.New("AWS CLI command failed: exit status 254, output: An error occurred (ScanNotFoundException)")
+	f.fixtures["ecr describe-repositories --repository-names api"] = "false\n"
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_ecr_image_scan", map[string]interface{}{"repository_name": "api"}, &AIProfile{Region: "us-east-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "api:latest: ⚠️  image scanning is not enabled") || !strings.Contains(out, "scanOnPush=true") {
+		t.Errorf("expected a scan-on-push suggestion, got:\n%s", out)
+	}
+}
+
+func TestAnalyzeECRImageScanDiscoversWorkloadImages(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["ecs list-clusters"] = `["arn:aws:ecs:us-east-1:123456789012:cluster/prod"]`
+	f.fixtures["ecs list-services"] = `["arn:aws:ecs:us-east-1:123456789012:service/prod/web"]`
+	f.fixtures["ecs describe-services"] = `["arn:aws:ecs:us-east-1:123456789012:task-definition/web:7"]`
+	f.fixtures["ecs describe-task-definition"] = `{"containers": [
+		{"name": "web", "image": "123456789012.dkr.ecr.eu-west-1.amazonaws.com/web:abc"},
+		{"name": "proxy", "image": "nginx:1.25"}
+	]}`
+	f.fixtures["ecr describe-image-scan-findings --repository-name web --image-id imageTag=abc"] = `{"imageScanStatus": {"status": "COMPLETE"}, "imageScanFindings": {}}`
+	var scanRegion string
+	c := newFakeClient(f)
+	c.SetExecFunc(func(ctx context.Context, args []string, profile *AIProfile) (string, error) {
+		if args[1] == "describe-image-scan-findings" {
+			scanRegion = profile.Region
+		}
+		return f.exec(ctx, args, profile)
+	})
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_ecr_image_scan", map[string]interface{}{}, &AIProfile{Region: "us-east-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "web:abc: ✅ no known vulnerabilities") {
+		t.Errorf("expected the ECS image to be scanned, got:\n%s", out)
+	}
+	if strings.Contains(out, "nginx") {
+		t.Errorf("non-ECR images should be skipped:\n%s", out)
+	}
+	if scanRegion != "eu-west-1" {
+		t.Errorf("scan findings should be read in the image's region, got %q", scanRegion)
+	}
+	if !strings.Contains(strings.Join(f.calls, "\n"), "--registry-id 123456789012") {
+		t.Errorf("expected the registry id to be passed: %v", f.calls)
+	}
+}
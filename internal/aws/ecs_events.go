@@ -0,0 +1,268 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ecsEventLimit bounds how many recent events are inspected per service.
+const ecsEventLimit = 25
+
+// ecsFailureMarkers are substrings of ECS service events that indicate a
+// deployment is not stabilizing.
+var ecsFailureMarkers = []string{
+	"unable to place",
+	"failed to start",
+	"cannotpullcontainererror",
+	"resourceinitializationerror",
+	"essential container",
+	"is unhealthy",
+	"insufficient",
+	"deployment failed",
+	"rolling back",
+	"circuit breaker",
+}
+
+type ecsServiceEvent struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Message   string    `json:"message"`
+}
+
+type ecsServiceState struct {
+	ServiceName    string            `json:"serviceName"`
+	Status         string            `json:"status"`
+	DesiredCount   int               `json:"desiredCount"`
+	RunningCount   int               `json:"runningCount"`
+	PendingCount   int               `json:"pendingCount"`
+	TaskDefinition string            `json:"taskDefinition"`
+	Events         []ecsServiceEvent `json:"events"`
+}
+
+type ecsContainerReservation struct {
+	Name              string `json:"name"`
+	Image             string `json:"image"`
+	CPU               int    `json:"cpu"`
+	Memory            int    `json:"memory"`
+	MemoryReservation int    `json:"memoryReservation"`
+}
+
+type ecsTaskDefinitionSummary struct {
+	CPU        string                    `json:"cpu"`
+	Memory     string                    `json:"memory"`
+	Containers []ecsContainerReservation `json:"containers"`
+}
+
+// analyzeECSServiceEvents summarizes recent ECS service events, highlighting
+// failures and correlating task start failures with the task definition's
+// images and resource reservations.
+func (c *Client) analyzeECSServiceEvents(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	serviceName, _ := input["service_name"].(string)
+	clusterName, _ := input["cluster_name"].(string)
+
+	clusters := []string{clusterName}
+	if clusterName == "" {
+		if serviceName != "" {
+			clusters = []string{"default"}
+		} else {
+			listed, err := c.listECSArns(ctx, []string{"ecs", "list-clusters", "--output", "json", "--query", "clusterArns"}, profile)
+			if err != nil {
+				return categorizeAWSError(err, "ECS"), nil
+			}
+			clusters = limitStrings(listed, 5)
+		}
+	}
+	if len(clusters) == 0 {
+		return "No ECS clusters found.", nil
+	}
+
+	var out strings.Builder
+	out.WriteString("🔍 ECS SERVICE EVENT ANALYSIS\n")
+	out.WriteString("=============================\n\n")
+
+	for _, cluster := range clusters {
+		services := []string{serviceName}
+		if serviceName == "" {
+			listed, err := c.listECSArns(ctx, []string{"ecs", "list-services", "--cluster", cluster, "--output", "json", "--query", "serviceArns"}, profile)
+			if err != nil {
+				out.WriteString(fmt.Sprintf("❌ Could not list services in %s: %v\n\n", shortArn(cluster), err))
+				continue
+			}
+			// describe-services accepts at most 10 services per call.
+			services = limitStrings(listed, 10)
+		}
+		if len(services) == 0 {
+			continue
+		}
+
+		args := []string{"ecs", "describe-services", "--cluster", cluster, "--services"}
+		args = append(args, services...)
+		args = append(args, "--output", "json", "--query",
+			fmt.Sprintf("services[].{serviceName:serviceName,status:status,desiredCount:desiredCount,runningCount:runningCount,pendingCount:pendingCount,taskDefinition:taskDefinition,events:events[:%d]}", ecsEventLimit))
+		raw, err := c.execAWSCLI(ctx, args, profile)
+		if err != nil {
+			out.WriteString(fmt.Sprintf("❌ Could not describe services in %s: %v\n\n", shortArn(cluster), err))
+			continue
+		}
+		var states []ecsServiceState
+		if err := json.Unmarshal([]byte(raw), &states); err != nil {
+			out.WriteString(fmt.Sprintf("❌ Could not parse services in %s: %v\n\n", shortArn(cluster), err))
+			continue
+		}
+
+		for _, state := range states {
+			out.WriteString(c.summarizeECSService(ctx, shortArn(cluster), state, profile))
+		}
+	}
+
+	return out.String(), nil
+}
+
+// summarizeECSService renders one service's event summary.
+func (c *Client) summarizeECSService(ctx context.Context, cluster string, state ecsServiceState, profile *AIProfile) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("Service %s (cluster %s): %s, running %d/%d, pending %d\n",
+		state.ServiceName, cluster, state.Status, state.RunningCount, state.DesiredCount, state.PendingCount))
+
+	failures := ecsFailureEvents(state.Events)
+	if len(failures) == 0 {
+		out.WriteString("  ✅ No failure events in the most recent service events\n")
+		if len(state.Events) > 0 {
+			out.WriteString(fmt.Sprintf("  Latest: %s\n", state.Events[0].Message))
+		}
+		out.WriteString("\n")
+		return out.String()
+	}
+
+	out.WriteString(fmt.Sprintf("  🚨 %d failure events (most recent first):\n", len(failures)))
+	for i, event := range failures {
+		if i == 5 {
+			out.WriteString(fmt.Sprintf("  ... and %d more\n", len(failures)-i))
+			break
+		}
+		out.WriteString(fmt.Sprintf("  - [%s] %s\n", event.CreatedAt.Format(time.RFC3339), event.Message))
+	}
+
+	if state.TaskDefinition != "" && hasTaskStartFailure(failures) {
+		td, err := c.describeECSTaskDefinition(ctx, state.TaskDefinition, profile)
+		if err != nil {
+			out.WriteString(fmt.Sprintf("  ⚠️  Could not load task definition %s: %v\n", shortArn(state.TaskDefinition), err))
+		} else {
+			out.WriteString(fmt.Sprintf("  Task definition %s (task cpu=%s, memory=%s):\n", shortArn(state.TaskDefinition), valueOr(td.CPU, "unset"), valueOr(td.Memory, "unset")))
+			for _, container := range td.Containers {
+				out.WriteString(fmt.Sprintf("    - %s image=%s cpu=%d memory=%d memoryReservation=%d\n",
+					container.Name, container.Image, container.CPU, container.Memory, container.MemoryReservation))
+			}
+		}
+		for _, cause := range likelyECSCauses(failures) {
+			out.WriteString(fmt.Sprintf("  💡 Likely cause: %s\n", cause))
+		}
+	}
+	out.WriteString("\n")
+	return out.String()
+}
+
+func (c *Client) describeECSTaskDefinition(ctx context.Context, taskDefinition string, profile *AIProfile) (*ecsTaskDefinitionSummary, error) {
+	args := []string{"ecs", "describe-task-definition", "--task-definition", taskDefinition, "--output", "json", "--query",
+		"taskDefinition.{cpu:cpu,memory:memory,containers:containerDefinitions[].{name:name,image:image,cpu:cpu,memory:memory,memoryReservation:memoryReservation}}"}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return nil, err
+	}
+	var td ecsTaskDefinitionSummary
+	if err := json.Unmarshal([]byte(raw), &td); err != nil {
+		// Dry-run and unexpected payloads yield an empty summary rather than an error.
+		return &ecsTaskDefinitionSummary{}, nil
+	}
+	return &td, nil
+}
+
+func (c *Client) listECSArns(ctx context.Context, args []string, profile *AIProfile) ([]string, error) {
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return nil, err
+	}
+	var arns []string
+	if err := json.Unmarshal([]byte(raw), &arns); err != nil {
+		return nil, nil
+	}
+	return arns, nil
+}
+
+// ecsFailureEvents returns events whose message matches a failure marker.
+func ecsFailureEvents(events []ecsServiceEvent) []ecsServiceEvent {
+	var failures []ecsServiceEvent
+	for _, event := range events {
+		lower := strings.ToLower(event.Message)
+		for _, marker := range ecsFailureMarkers {
+			if strings.Contains(lower, marker) {
+				failures = append(failures, event)
+				break
+			}
+		}
+	}
+	return failures
+}
+
+func hasTaskStartFailure(events []ecsServiceEvent) bool {
+	for _, event := range events {
+		lower := strings.ToLower(event.Message)
+		if strings.Contains(lower, "failed to start") || strings.Contains(lower, "unable to place") ||
+			strings.Contains(lower, "cannotpullcontainererror") || strings.Contains(lower, "resourceinitializationerror") ||
+			strings.Contains(lower, "essential container") {
+			return true
+		}
+	}
+	return false
+}
+
+// likelyECSCauses maps failure messages to human-readable root-cause hints.
+func likelyECSCauses(events []ecsServiceEvent) []string {
+	seen := make(map[string]bool)
+	var causes []string
+	add := func(cause string) {
+		if !seen[cause] {
+			seen[cause] = true
+			causes = append(causes, cause)
+		}
+	}
+	for _, event := range events {
+		lower := strings.ToLower(event.Message)
+		switch {
+		case strings.Contains(lower, "cannotpullcontainererror"):
+			add("image cannot be pulled - check the image tag exists, ECR permissions on the execution role, and outbound network access (NAT or VPC endpoints)")
+		case strings.Contains(lower, "resourceinitializationerror"):
+			add("task failed to initialize - usually secrets/SSM parameters or log configuration unreachable from the task's subnets")
+		case strings.Contains(lower, "insufficient memory") || strings.Contains(lower, "insufficient cpu"):
+			add("cluster capacity is too small for the task's cpu/memory reservations - scale the capacity provider or reduce reservations")
+		case strings.Contains(lower, "unable to place"):
+			add("no container instance satisfies the task's placement constraints or resource reservations")
+		case strings.Contains(lower, "essential container"):
+			add("an essential container exited - check the container's logs for the startup error")
+		}
+	}
+	return causes
+}
+
+func shortArn(arn string) string {
+	if idx := strings.LastIndex(arn, "/"); idx >= 0 && idx < len(arn)-1 {
+		return arn[idx+1:]
+	}
+	return arn
+}
+
+func limitStrings(values []string, limit int) []string {
+	if len(values) > limit {
+		return values[:limit]
+	}
+	return values
+}
+
+func valueOr(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,34 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestECSFailureEventsAndCauses(t *testing.T) {
+	events := []ecsServiceEvent{
+		{Message: "(service api) has reached a steady state."},
+		{Message: "(service api) was unable to place a task because no container instance met all of its requirements. The closest matching container-instance has insufficient memory available."},
+		{Message: "(service api) tasks failed to start: CannotPullContainerError: pull image manifest has been retried 5 time(s)"},
+	}
+
+	failures := ecsFailureEvents(events)
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 failure events, got %d", len(failures))
+	}
+	if !hasTaskStartFailure(failures) {
+		t.Error("expected task start failure to be detected")
+	}
+
+	causes := strings.Join(likelyECSCauses(failures), "\n")
+	if !strings.Contains(causes, "capacity") || !strings.Contains(causes, "image cannot be pulled") {
+		t.Errorf("expected capacity and image pull causes, got:\n%s", causes)
+	}
+}
+
+func TestECSFailureEvents_SteadyState(t *testing.T) {
+	events := []ecsServiceEvent{{Message: "(service api) has reached a steady state."}}
+	if failures := ecsFailureEvents(events); len(failures) != 0 {
+		t.Errorf("expected no failures, got %v", failures)
+	}
+}
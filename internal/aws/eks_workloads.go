@@ -0,0 +1,266 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bgdnvk/clanker/internal/logging"
+)
+
+const (
+	// eksDescribePodLimit bounds how many unhealthy pods get a kubectl describe.
+	eksDescribePodLimit = 5
+	// eksDescribeEventLines keeps the tail of each describe's Events section.
+	eksDescribeEventLines = 10
+)
+
+// eksPodList is the subset of `kubectl get pods -o json` used to spot crashes.
+type eksPodList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Status struct {
+			Phase             string               `json:"phase"`
+			Reason            string               `json:"reason"`
+			ContainerStatuses []eksContainerStatus `json:"containerStatuses"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type eksContainerStatus struct {
+	Name         string            `json:"name"`
+	Ready        bool              `json:"ready"`
+	RestartCount int               `json:"restartCount"`
+	State        eksContainerState `json:"state"`
+	LastState    eksContainerState `json:"lastState"`
+}
+
+type eksContainerState struct {
+	Waiting *struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	} `json:"waiting"`
+	Terminated *struct {
+		Reason   string `json:"reason"`
+		ExitCode int    `json:"exitCode"`
+	} `json:"terminated"`
+}
+
+// eksPodSummary is one pod with its restart total and the reasons it is not healthy.
+type eksPodSummary struct {
+	Namespace string
+	Name      string
+	Phase     string
+	Restarts  int
+	Reasons   []string
+}
+
+func (p eksPodSummary) unhealthy() bool {
+	return len(p.Reasons) > 0
+}
+
+// describeEKSWorkloads inspects the pods of an EKS cluster through a temporary
+// kubeconfig, reporting crash reasons and restart counts for unhealthy pods.
+func (c *Client) describeEKSWorkloads(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	clusterName, _ := input["cluster_name"].(string)
+	clusterName = strings.TrimSpace(clusterName)
+	if clusterName == "" {
+		// With a single cluster in the account there is nothing to ask.
+		clusters, err := c.listEKSClusterNames(ctx, profile)
+		if err != nil {
+			return categorizeAWSError(err, "EKS"), nil
+		}
+		switch len(clusters) {
+		case 0:
+			return "No EKS clusters found.", nil
+		case 1:
+		default:
+			return fmt.Sprintf("Found %d EKS clusters (%s); pass cluster_name to inspect one.\n", len(clusters), strings.Join(clusters, ", ")), nil
+		}
+		clusterName = clusters[0]
+	}
+
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return fmt.Sprintf("⚠️  kubectl is not installed, so the pods in EKS cluster %s cannot be inspected. Install kubectl to enable workload investigation.\n", clusterName), nil
+	}
+
+	kubeconfig, cleanup, err := c.eksKubeconfig(ctx, clusterName, profile)
+	if err != nil {
+		return categorizeAWSError(err, "EKS"), nil
+	}
+	defer cleanup()
+	if c.dryRun {
+		return fmt.Sprintf("[dry-run] would inspect pods in EKS cluster %s with kubectl\n", clusterName), nil
+	}
+
+	raw, err := runKubectl(ctx, kubeconfig, "get", "pods", "-A", "-o", "json")
+	if err != nil {
+		return fmt.Sprintf("❌ kubectl get pods failed for EKS cluster %s: %v\n", clusterName, err), nil
+	}
+	pods, err := parseEKSPods(raw)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.WriteString(formatEKSWorkloads(clusterName, pods))
+
+	// Pods are sorted unhealthy first.
+	for i, pod := range pods {
+		if i == eksDescribePodLimit || !pod.unhealthy() {
+			break
+		}
+		details, err := runKubectl(ctx, kubeconfig, "describe", "pod", pod.Name, "-n", pod.Namespace)
+		if err != nil {
+			out.WriteString(fmt.Sprintf("\n⚠️  Could not describe %s/%s: %v\n", pod.Namespace, pod.Name, err))
+			continue
+		}
+		if events := describeEvents(details, eksDescribeEventLines); events != "" {
+			out.WriteString(fmt.Sprintf("\nEvents for %s/%s:\n%s\n", pod.Namespace, pod.Name, events))
+		}
+	}
+	return out.String(), nil
+}
+
+func (c *Client) listEKSClusterNames(ctx context.Context, profile *AIProfile) ([]string, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"eks", "list-clusters", "--output", "json", "--query", "clusters"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(raw), &names); err != nil {
+		return nil, fmt.Errorf("failed to parse EKS cluster list: %w", err)
+	}
+	return names, nil
+}
+
+// eksKubeconfig writes a temporary kubeconfig for clusterName; cleanup
+// removes it.
+func (c *Client) eksKubeconfig(ctx context.Context, clusterName string, profile *AIProfile) (kubeconfig string, cleanup func(), err error) {
+	dir, err := os.MkdirTemp("", "clanker-eks-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp kubeconfig dir: %w", err)
+	}
+	kubeconfig = filepath.Join(dir, "kubeconfig")
+	if _, err := c.execAWSCLI(ctx, []string{"eks", "update-kubeconfig", "--name", clusterName, "--kubeconfig", kubeconfig}, profile); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	return kubeconfig, func() { os.RemoveAll(dir) }, nil
+}
+
+// runKubectl runs kubectl against kubeconfig and returns its output.
+func runKubectl(ctx context.Context, kubeconfig string, args ...string) (string, error) {
+	cmdArgs := append([]string{"--kubeconfig", kubeconfig}, args...)
+	cmd := exec.CommandContext(ctx, "kubectl", cmdArgs...)
+	logging.Debugf("🚀 Executing: kubectl %s", strings.Join(cmdArgs, " "))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// parseEKSPods summarizes a pod list, most troubled pods first.
+func parseEKSPods(raw string) ([]eksPodSummary, error) {
+	var list eksPodList
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse kubectl pod list: %w", err)
+	}
+
+	pods := make([]eksPodSummary, 0, len(list.Items))
+	for _, item := range list.Items {
+		pod := eksPodSummary{
+			Namespace: item.Metadata.Namespace,
+			Name:      item.Metadata.Name,
+			Phase:     item.Status.Phase,
+		}
+		if pod.Phase != "Running" && pod.Phase != "Succeeded" {
+			pod.Reasons = append(pod.Reasons, "phase "+valueOr(pod.Phase, "Unknown")+reasonSuffix(item.Status.Reason))
+		}
+		for _, cs := range item.Status.ContainerStatuses {
+			pod.Restarts += cs.RestartCount
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+				pod.Reasons = append(pod.Reasons, fmt.Sprintf("%s waiting: %s", cs.Name, cs.State.Waiting.Reason))
+			}
+			if t := cs.State.Terminated; t != nil && t.ExitCode != 0 {
+				pod.Reasons = append(pod.Reasons, fmt.Sprintf("%s terminated: %s (exit %d)", cs.Name, t.Reason, t.ExitCode))
+			}
+			if t := cs.LastState.Terminated; t != nil && cs.RestartCount > 0 {
+				pod.Reasons = append(pod.Reasons, fmt.Sprintf("%s last exit: %s (exit %d)", cs.Name, t.Reason, t.ExitCode))
+			}
+		}
+		pods = append(pods, pod)
+	}
+
+	sort.SliceStable(pods, func(i, j int) bool {
+		if pods[i].unhealthy() != pods[j].unhealthy() {
+			return pods[i].unhealthy()
+		}
+		return pods[i].Restarts > pods[j].Restarts
+	})
+	return pods, nil
+}
+
+func reasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " (" + reason + ")"
+}
+
+func formatEKSWorkloads(clusterName string, pods []eksPodSummary) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("☸️  EKS WORKLOADS: %s\n", clusterName))
+	out.WriteString("==========================\n\n")
+
+	unhealthy := 0
+	for _, pod := range pods {
+		if pod.unhealthy() {
+			unhealthy++
+		}
+	}
+	out.WriteString(fmt.Sprintf("%d pods, %d unhealthy\n", len(pods), unhealthy))
+	if unhealthy == 0 {
+		out.WriteString("✅ All pods are running or completed without container failures\n")
+		return out.String()
+	}
+
+	out.WriteString("\n🚨 Unhealthy pods:\n")
+	for _, pod := range pods {
+		if !pod.unhealthy() {
+			break
+		}
+		out.WriteString(fmt.Sprintf("- %s/%s phase=%s restarts=%d\n", pod.Namespace, pod.Name, valueOr(pod.Phase, "Unknown"), pod.Restarts))
+		for _, reason := range pod.Reasons {
+			out.WriteString(fmt.Sprintf("    %s\n", reason))
+		}
+	}
+	return out.String()
+}
+
+// describeEvents returns the last maxLines lines of a kubectl describe Events section.
+func describeEvents(describe string, maxLines int) string {
+	idx := strings.LastIndex(describe, "\nEvents:")
+	if idx < 0 {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(describe[idx+len("\nEvents:"):], "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	return strings.Join(lines, "\n")
+}
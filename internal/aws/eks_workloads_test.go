@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEKSPods(t *testing.T) {
+	raw := `{"items":[
+		{"metadata":{"name":"web-1","namespace":"app"},"status":{"phase":"Running","containerStatuses":[{"name":"web","ready":true,"restartCount":0,"state":{"running":{}}}]}},
+		{"metadata":{"name":"api-1","namespace":"app"},"status":{"phase":"Running","containerStatuses":[{"name":"api","ready":false,"restartCount":7,
+			"state":{"waiting":{"reason":"CrashLoopBackOff"}},"lastState":{"terminated":{"reason":"Error","exitCode":1}}}]}},
+		{"metadata":{"name":"job-1","namespace":"batch"},"status":{"phase":"Pending","reason":"Unschedulable"}}
+	]}`
+
+	pods, err := parseEKSPods(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods) != 3 {
+		t.Fatalf("expected 3 pods, got %d", len(pods))
+	}
+	if pods[0].Name != "api-1" || pods[0].Restarts != 7 {
+		t.Fatalf("expected the crashlooping pod first, got %+v", pods[0])
+	}
+	if pods[1].Name != "job-1" || !pods[1].unhealthy() {
+		t.Fatalf("expected the pending pod second, got %+v", pods[1])
+	}
+	if pods[2].unhealthy() {
+		t.Fatalf("expected the running pod to be healthy, got %+v", pods[2])
+	}
+
+	out := formatEKSWorkloads("prod", pods)
+	for _, want := range []string{"3 pods, 2 unhealthy", "api waiting: CrashLoopBackOff", "api last exit: Error (exit 1)", "phase Pending (Unschedulable)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "web-1") {
+		t.Errorf("healthy pod should not be listed:\n%s", out)
+	}
+}
+
+func TestDescribeEvents(t *testing.T) {
+	describe := "Name: api-1\nStatus: Running\nEvents:\n  Type     Reason   Age  Message\n  Warning  BackOff  1m   Back-off restarting failed container\n"
+	got := describeEvents(describe, 1)
+	if got != "  Warning  BackOff  1m   Back-off restarting failed container" {
+		t.Fatalf("unexpected events %q", got)
+	}
+	if describeEvents("Name: x\n", 5) != "" {
+		t.Fatal("expected no events without an Events section")
+	}
+}
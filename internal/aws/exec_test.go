@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCLI serves canned AWS CLI output keyed by the leading args, e.g.
+// "sqs list-queues". A matching failure wins, then the longest matching
+// fixture; unmatched commands fail.
+type fakeCLI struct {
+	mu       sync.Mutex
+	fixtures map[string]string
+	failures map[string]error
+	calls    []string
+}
+
+func newFakeCLI() *fakeCLI {
+	return &fakeCLI{fixtures: make(map[string]string), failures: make(map[string]error)}
+}
+
+func (f *fakeCLI) exec(_ context.Context, args []string, _ *AIProfile) (string, error) {
+	cmd := strings.Join(args, " ")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, cmd)
+
+	for prefix, err := range f.failures {
+		if strings.HasPrefix(cmd, prefix) {
+			return "", err
+		}
+	}
+	best := ""
+	for prefix := range f.fixtures {
+		if strings.HasPrefix(cmd, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no fixture for %q", cmd)
+	}
+	return f.fixtures[best], nil
+}
+
+func newFakeClient(f *fakeCLI) *Client {
+	c := &Client{}
+	c.SetExecFunc(f.exec)
+	return c
+}
+
+func TestSetExecFuncRoutesOperations(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["sqs list-queues --max-items"] = "queues"
+	f.fixtures["sqs list-queues --output json"] = "3\n"
+	c := newFakeClient(f)
+	profile := &AIProfile{AWSProfile: "dev", Region: "us-east-1"}
+
+	out, err := c.executeAWSOperation(context.Background(), "check_sqs_service", map[string]interface{}{}, profile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "✅ SQS service is available. Queue count: 3" {
+		t.Errorf("unexpected output %q", out)
+	}
+	if len(f.calls) != 2 {
+		t.Errorf("expected 2 CLI calls, got %v", f.calls)
+	}
+
+	f.failures["sqs"] = errors.New("AccessDenied")
+	out, _ = c.executeAWSOperation(context.Background(), "check_sqs_service", map[string]interface{}{}, profile)
+	if !strings.Contains(out, "not available") {
+		t.Errorf("expected unavailable message on CLI failure, got %q", out)
+	}
+}
+
+func TestDryRunTakesPrecedenceOverExecFunc(t *testing.T) {
+	f := newFakeCLI()
+	c := newFakeClient(f)
+	c.SetDryRun(true)
+
+	if _, err := c.execAWSCLI(context.Background(), []string{"sts", "get-caller-identity"}, &AIProfile{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.calls) != 0 {
+		t.Errorf("dry-run should not reach the exec func, got %v", f.calls)
+	}
+}
+
+func TestGetServiceQuotasWithFixtures(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["service-quotas list-service-quotas --service-code lambda"] = `{"Quotas": [
+		{"QuotaCode": "L-B99A9384", "QuotaName": "Concurrent executions", "Value": 1000, "Adjustable": true,
+		 "UsageMetric": {"MetricNamespace": "AWS/Lambda", "MetricName": "ConcurrentExecutions", "MetricStatisticRecommendation": "Maximum"}},
+		{"QuotaCode": "L-2ACBD22F", "QuotaName": "Function and layer storage", "Value": 75}
+	]}`
+	f.fixtures["cloudwatch get-metric-statistics"] = `{"Datapoints": [
+		{"Timestamp": "2024-05-01T12:00:00Z", "Maximum": 640, "Unit": "Count"},
+		{"Timestamp": "2024-05-01T12:05:00Z", "Maximum": 850, "Unit": "Count"}
+	]}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "get_service_quotas", map[string]interface{}{"service_code": "Lambda"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Concurrent executions (L-B99A9384): 850 of 1000 used, 85.0%") {
+		t.Errorf("expected peak utilization for concurrency quota:\n%s", out)
+	}
+	if !strings.Contains(out, "1 quota(s) at or above 80% utilization") {
+		t.Errorf("expected near-limit warning:\n%s", out)
+	}
+
+	if _, err := c.executeAWSOperation(context.Background(), "get_service_quotas", map[string]interface{}{"service_code": []interface{}{"lambda"}}, &AIProfile{}); err == nil {
+		t.Error("expected an error for a malformed service_code")
+	}
+}
@@ -0,0 +1,376 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// iamRoleAnalysisLimit bounds how many roles one analyze_iam_role call inspects
+// when it picks roles out of a query.
+const iamRoleAnalysisLimit = 5
+
+// iamFinding severities, highest first in the report.
+const (
+	iamSeverityLow = iota + 1
+	iamSeverityMedium
+	iamSeverityHigh
+	iamSeverityCritical
+)
+
+var iamSeverityLabels = map[int]string{
+	iamSeverityLow:      "LOW",
+	iamSeverityMedium:   "MEDIUM",
+	iamSeverityHigh:     "HIGH",
+	iamSeverityCritical: "CRITICAL",
+}
+
+// iamAdminManagedPolicies are AWS managed policies, keyed by policy path in
+// any partition, that are admin-equivalent or allow privilege escalation on
+// their own.
+var iamAdminManagedPolicies = map[string]struct {
+	severity int
+	reason   string
+}{
+	"AdministratorAccess": {iamSeverityCritical, "full administrator access"},
+	"IAMFullAccess":       {iamSeverityCritical, "full IAM access allows granting itself any permission"},
+	"PowerUserAccess":     {iamSeverityHigh, "every service except IAM management"},
+}
+
+// iamFinding is one dangerous grant found in a role's policies.
+type iamFinding struct {
+	Severity int
+	Policy   string
+	Detail   string
+}
+
+// iamStringList decodes IAM policy fields that may be a string or a list.
+type iamStringList []string
+
+func (l *iamStringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = iamStringList{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*l = many
+	return nil
+}
+
+type iamStatement struct {
+	Sid       string          `json:"Sid"`
+	Effect    string          `json:"Effect"`
+	Principal json.RawMessage `json:"Principal"` // resource policies only
+	Action    iamStringList   `json:"Action"`
+	NotAction iamStringList   `json:"NotAction"`
+	Resource  iamStringList   `json:"Resource"`
+	Condition json.RawMessage `json:"Condition"`
+}
+
+// iamPolicyDocument holds the statements of a policy; Statement may be a
+// single object or a list.
+type iamPolicyDocument struct {
+	Statements []iamStatement
+}
+
+func (d *iamPolicyDocument) UnmarshalJSON(data []byte) error {
+	// get-policy-version can return the document URL-encoded as a string.
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err == nil {
+		decoded, err := url.QueryUnescape(encoded)
+		if err != nil {
+			return err
+		}
+		data = []byte(decoded)
+	}
+	var raw struct {
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw.Statement) == 0 {
+		return nil
+	}
+	if raw.Statement[0] == '{' {
+		var one iamStatement
+		if err := json.Unmarshal(raw.Statement, &one); err != nil {
+			return err
+		}
+		d.Statements = []iamStatement{one}
+		return nil
+	}
+	return json.Unmarshal(raw.Statement, &d.Statements)
+}
+
+// analyzeIAMRole is the analyze_iam_role operation: it fetches a role's
+// attached and inline policies and ranks dangerous grants by risk. Without
+// role_name it analyzes the account's roles named in the query parameter.
+func (c *Client) analyzeIAMRole(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	roles := []string{}
+	if name := getStringParam(input, "role_name", ""); name != "" {
+		roles = append(roles, iamRoleNameFromARN(name))
+	} else if query := getStringParam(input, "query", ""); query != "" {
+		raw, err := c.execAWSCLI(ctx, []string{"iam", "list-roles", "--output", "json", "--query", "Roles[].RoleName"}, profile)
+		if err != nil {
+			return categorizeAWSError(err, "IAM"), nil
+		}
+		var all []string
+		if err := json.Unmarshal([]byte(raw), &all); err != nil {
+			return "", fmt.Errorf("failed to parse IAM roles: %w", err)
+		}
+		roles = rolesReferencedInQuery(all, query)
+		if len(roles) == 0 {
+			return fmt.Sprintf("No IAM role named in the query; the account has %d roles. Pass role_name to analyze a specific role.", len(all)), nil
+		}
+	} else {
+		return "", fmt.Errorf("role_name parameter required")
+	}
+
+	var out strings.Builder
+	for i, role := range limitStrings(roles, iamRoleAnalysisLimit) {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		findings, policies, err := c.iamRoleFindings(ctx, role, profile)
+		if err != nil {
+			out.WriteString(fmt.Sprintf("🔐 IAM role %s: %s\n", role, categorizeAWSError(err, "IAM")))
+			continue
+		}
+		out.WriteString(formatIAMFindings(role, policies, findings))
+	}
+	return out.String(), nil
+}
+
+// rolesReferencedInQuery returns the role names that appear as whole words in
+// the query (or as the last segment of a role ARN), in listing order.
+func rolesReferencedInQuery(roles []string, query string) []string {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(query, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("+=,.@_-/:", r))
+	}) {
+		word = strings.ToLower(strings.TrimRight(iamRoleNameFromARN(word), ".,"))
+		words[word] = true
+	}
+	var matched []string
+	for _, role := range roles {
+		if words[strings.ToLower(role)] {
+			matched = append(matched, role)
+		}
+	}
+	return matched
+}
+
+func iamRoleNameFromARN(role string) string {
+	if strings.HasPrefix(role, "arn:") {
+		if i := strings.LastIndex(role, "/"); i >= 0 {
+			return role[i+1:]
+		}
+	}
+	return role
+}
+
+// iamRoleFindings collects findings across a role's attached and inline
+// policies and returns them with the number of policies inspected.
+func (c *Client) iamRoleFindings(ctx context.Context, role string, profile *AIProfile) ([]iamFinding, int, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"iam", "list-attached-role-policies", "--role-name", role, "--output", "json"}, profile)
+	if err != nil {
+		return nil, 0, err
+	}
+	var attached struct {
+		AttachedPolicies []struct {
+			PolicyName string `json:"PolicyName"`
+			PolicyArn  string `json:"PolicyArn"`
+		} `json:"AttachedPolicies"`
+	}
+	if err := json.Unmarshal([]byte(raw), &attached); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse attached policies: %w", err)
+	}
+
+	var findings []iamFinding
+	policies := 0
+	for _, p := range attached.AttachedPolicies {
+		policies++
+		path, _ := ManagedPolicyPath(p.PolicyArn)
+		if admin, ok := iamAdminManagedPolicies[path]; ok {
+			findings = append(findings, iamFinding{Severity: admin.severity, Policy: p.PolicyName, Detail: "managed policy grants " + admin.reason})
+			continue
+		}
+		doc, err := c.managedPolicyDocument(ctx, p.PolicyArn, profile)
+		if err != nil {
+			continue
+		}
+		findings = append(findings, analyzePolicyDocument(p.PolicyName, doc)...)
+	}
+
+	raw, err = c.execAWSCLI(ctx, []string{"iam", "list-role-policies", "--role-name", role, "--output", "json"}, profile)
+	if err != nil {
+		return nil, 0, err
+	}
+	var inline struct {
+		PolicyNames []string `json:"PolicyNames"`
+	}
+	if err := json.Unmarshal([]byte(raw), &inline); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse inline policies: %w", err)
+	}
+	for _, name := range inline.PolicyNames {
+		policies++
+		raw, err := c.execAWSCLI(ctx, []string{"iam", "get-role-policy", "--role-name", role, "--policy-name", name, "--output", "json"}, profile)
+		if err != nil {
+			continue
+		}
+		var resp struct {
+			PolicyDocument iamPolicyDocument `json:"PolicyDocument"`
+		}
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			continue
+		}
+		findings = append(findings, analyzePolicyDocument(name+" (inline)", resp.PolicyDocument)...)
+	}
+	return findings, policies, nil
+}
+
+func (c *Client) managedPolicyDocument(ctx context.Context, arn string, profile *AIProfile) (iamPolicyDocument, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"iam", "get-policy", "--policy-arn", arn, "--output", "json", "--query", "Policy.DefaultVersionId"}, profile)
+	if err != nil {
+		return iamPolicyDocument{}, err
+	}
+	var version string
+	if err := json.Unmarshal([]byte(raw), &version); err != nil {
+		return iamPolicyDocument{}, fmt.Errorf("failed to parse policy version: %w", err)
+	}
+	raw, err = c.execAWSCLI(ctx, []string{"iam", "get-policy-version", "--policy-arn", arn, "--version-id", version, "--output", "json"}, profile)
+	if err != nil {
+		return iamPolicyDocument{}, err
+	}
+	var resp struct {
+		PolicyVersion struct {
+			Document iamPolicyDocument `json:"Document"`
+		} `json:"PolicyVersion"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return iamPolicyDocument{}, fmt.Errorf("failed to parse policy document: %w", err)
+	}
+	return resp.PolicyVersion.Document, nil
+}
+
+// analyzePolicyDocument flags wildcard actions and resources, wildcard
+// iam:PassRole and Allow+NotAction in a policy's Allow statements.
+func analyzePolicyDocument(policy string, doc iamPolicyDocument) []iamFinding {
+	var findings []iamFinding
+	add := func(severity int, detail string, st iamStatement) {
+		if len(st.Condition) > 0 && string(st.Condition) != "null" {
+			detail += " (with conditions)"
+		}
+		findings = append(findings, iamFinding{Severity: severity, Policy: policy, Detail: detail})
+	}
+
+	for _, st := range doc.Statements {
+		if !strings.EqualFold(st.Effect, "Allow") {
+			continue
+		}
+		anyResource := containsString(st.Resource, "*")
+		if len(st.NotAction) > 0 {
+			add(iamSeverityHigh, fmt.Sprintf("Allow with NotAction %s grants every other action", strings.Join(st.NotAction, ", ")), st)
+			continue
+		}
+		if containsString(st.Action, "*") {
+			if anyResource {
+				add(iamSeverityCritical, `allows "*" actions on "*" resources (admin-equivalent)`, st)
+			} else {
+				add(iamSeverityHigh, fmt.Sprintf(`allows "*" actions on %s`, strings.Join(st.Resource, ", ")), st)
+			}
+			continue
+		}
+
+		var serviceWildcards []string
+		passRole, writes := false, false
+		for _, action := range st.Action {
+			lower := strings.ToLower(action)
+			switch {
+			case lower == "iam:*" || lower == "iam:passrole" || lower == "iam:pass*":
+				passRole = true
+				if lower == "iam:*" {
+					serviceWildcards = append(serviceWildcards, action)
+				}
+			case strings.HasSuffix(lower, ":*"):
+				serviceWildcards = append(serviceWildcards, action)
+			}
+			if !iamReadOnlyAction(lower) {
+				writes = true
+			}
+		}
+
+		if passRole && anyResource {
+			add(iamSeverityHigh, `allows iam:PassRole on "*", so any role can be handed to a service (privilege escalation)`, st)
+		}
+		for _, wildcard := range serviceWildcards {
+			severity := iamSeverityMedium
+			if strings.EqualFold(wildcard, "iam:*") {
+				severity = iamSeverityCritical
+			}
+			where := "scoped resources"
+			if anyResource {
+				where = `"*" resources`
+			}
+			add(severity, fmt.Sprintf("allows every %s action on %s", strings.TrimSuffix(wildcard, ":*"), where), st)
+		}
+		if anyResource && len(serviceWildcards) == 0 && !passRole {
+			if writes {
+				add(iamSeverityMedium, fmt.Sprintf(`allows write actions %s on "*" resources`, strings.Join(limitStrings(st.Action, 5), ", ")), st)
+			} else {
+				add(iamSeverityLow, fmt.Sprintf(`allows read actions %s on "*" resources`, strings.Join(limitStrings(st.Action, 5), ", ")), st)
+			}
+		}
+	}
+	return findings
+}
+
+func iamReadOnlyAction(action string) bool {
+	if i := strings.Index(action, ":"); i >= 0 {
+		action = action[i+1:]
+	}
+	for _, prefix := range []string{"get", "list", "describe", "head", "batchget", "query", "scan", "lookup", "view"} {
+		if strings.HasPrefix(action, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// formatIAMFindings ranks a role's findings by severity for least-privilege
+// review.
+func formatIAMFindings(role string, policies int, findings []iamFinding) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("🔐 IAM role analysis: %s\n", role))
+	out.WriteString("============================\n")
+	out.WriteString(fmt.Sprintf("Policies inspected: %d\n", policies))
+	if len(findings) == 0 {
+		out.WriteString("✅ No wildcard or admin-equivalent grants found.\n")
+		return out.String()
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Severity > findings[j].Severity })
+	out.WriteString(fmt.Sprintf("⚠️  %d risky grant(s), highest risk first:\n", len(findings)))
+	for _, f := range findings {
+		out.WriteString(fmt.Sprintf("  [%s] %s: %s\n", iamSeverityLabels[f.Severity], f.Policy, f.Detail))
+	}
+	out.WriteString("Least privilege: replace wildcards with the specific actions and resource ARNs the role uses.\n")
+	return out.String()
+}
@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePolicyDocument(t *testing.T) {
+	var doc iamPolicyDocument
+	raw := `{"Version": "2012-10-17", "Statement": [
+		{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket/*"},
+		{"Effect": "Allow", "Action": ["iam:PassRole"], "Resource": "*"},
+		{"Effect": "Allow", "Action": "dynamodb:*", "Resource": "*"},
+		{"Effect": "Deny", "Action": "*", "Resource": "*"},
+		{"Effect": "Allow", "Action": ["logs:DescribeLogGroups"], "Resource": "*"}
+	]}`
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	findings := analyzePolicyDocument("app", doc)
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", findings)
+	}
+	if findings[0].Severity != iamSeverityHigh || !strings.Contains(findings[0].Detail, "iam:PassRole") {
+		t.Errorf("expected wildcard PassRole finding, got %+v", findings[0])
+	}
+	if findings[1].Severity != iamSeverityMedium || !strings.Contains(findings[1].Detail, "every dynamodb action") {
+		t.Errorf("expected service wildcard finding, got %+v", findings[1])
+	}
+	if findings[2].Severity != iamSeverityLow {
+		t.Errorf("expected read-only wildcard resource to be low risk, got %+v", findings[2])
+	}
+
+	var single iamPolicyDocument
+	if err := json.Unmarshal([]byte(`"%7B%22Statement%22%3A%7B%22Effect%22%3A%22Allow%22%2C%22Action%22%3A%22%2A%22%2C%22Resource%22%3A%22%2A%22%7D%7D"`), &single); err != nil {
+		t.Fatalf("unmarshal encoded: %v", err)
+	}
+	if got := analyzePolicyDocument("encoded", single); len(got) != 1 || got[0].Severity != iamSeverityCritical {
+		t.Errorf("expected admin-equivalent finding from URL-encoded single statement, got %+v", got)
+	}
+}
+
+func TestAnalyzeIAMRoleFromQuery(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["iam list-roles"] = `["api", "api-prod-role", "ci-deployer"]`
+	f.fixtures["iam list-attached-role-policies --role-name api-prod-role"] = `{"AttachedPolicies": [
+		{"PolicyName": "AdministratorAccess", "PolicyArn": "arn:aws:iam::aws:policy/AdministratorAccess"},
+		{"PolicyName": "app-data", "PolicyArn": "arn:aws:iam::123456789012:policy/app-data"}
+	]}`
+	f.fixtures["iam get-policy --policy-arn arn:aws:iam::123456789012:policy/app-data"] = `"v3"`
+	f.fixtures["iam get-policy-version --policy-arn arn:aws:iam::123456789012:policy/app-data --version-id v3"] = `{"PolicyVersion": {"Document":
+		{"Statement": [{"Effect": "Allow", "Action": "s3:PutObject", "Resource": "*"}]}}}`
+	f.fixtures["iam list-role-policies --role-name api-prod-role"] = `{"PolicyNames": ["passer"]}`
+	f.fixtures["iam get-role-policy --role-name api-prod-role --policy-name passer"] = `{"PolicyDocument":
+		{"Statement": {"Effect": "Allow", "Action": "iam:PassRole", "Resource": "*"}}}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_iam_role", map[string]interface{}{"query": "why does api-prod-role have so much access?"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	critical := strings.Index(out, "[CRITICAL] AdministratorAccess")
+	high := strings.Index(out, "[HIGH] passer (inline)")
+	medium := strings.Index(out, "[MEDIUM] app-data")
+	if critical < 0 || high < critical || medium < high {
+		t.Errorf("expected findings ranked critical, high, medium:\n%s", out)
+	}
+	if strings.Contains(out, "api:") || strings.Contains(out, "IAM role analysis: api\n") {
+		t.Errorf("role names should match whole words only:\n%s", out)
+	}
+
+	if _, err := c.executeAWSOperation(context.Background(), "analyze_iam_role", map[string]interface{}{}, &AIProfile{}); err == nil {
+		t.Error("expected an error without role_name or query")
+	}
+}
+
+func TestRolesReferencedInQuery(t *testing.T) {
+	roles := []string{"api", "api-prod-role", "Deployer.v2", "ci"}
+	got := rolesReferencedInQuery(roles, "Check arn:aws:iam::123456789012:role/service/API and deployer.v2.")
+	if strings.Join(got, ",") != "api,Deployer.v2" {
+		t.Errorf("got %v", got)
+	}
+}
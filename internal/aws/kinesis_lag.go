@@ -0,0 +1,402 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// kinesisLagWindow is how far back analyze_kinesis_lag reads metrics
+	// unless the investigation window says otherwise.
+	kinesisLagWindow = 3 * time.Hour
+	// kinesisIteratorAgeThreshold flags consumers whose iterator age exceeds
+	// it; override with iterator_age_threshold_seconds.
+	kinesisIteratorAgeThreshold = time.Minute
+	kinesisMetricPeriod         = 300
+	kinesisLagMaxStreams        = 10
+	firehoseLagMaxStreams       = 10
+)
+
+// kinesisStreamLag is the consumer lag and throttling picture of one stream.
+type kinesisStreamLag struct {
+	Name      string
+	Mode      string
+	Shards    int
+	Consumers int
+	Retention time.Duration
+	// IteratorAge is the per-period maximum of GetRecords.IteratorAgeMilliseconds,
+	// oldest first.
+	IteratorAge    []metricDatapoint
+	WriteThrottled float64
+	ReadThrottled  float64
+	Err            string
+}
+
+// firehoseDestinations maps describe-delivery-stream destination fields to a
+// label and the CloudWatch prefix of their delivery metrics.
+var firehoseDestinations = []struct {
+	field, label, metric string
+}{
+	{"RedshiftDestinationDescription", "Redshift", "DeliveryToRedshift"},
+	{"AmazonopensearchserviceDestinationDescription", "OpenSearch", "DeliveryToAmazonOpenSearchService"},
+	{"ElasticsearchDestinationDescription", "Elasticsearch", "DeliveryToElasticsearch"},
+	{"SplunkDestinationDescription", "Splunk", "DeliveryToSplunk"},
+	{"HttpEndpointDestinationDescription", "HTTP endpoint", "DeliveryToHttpEndpoint"},
+	{"ExtendedS3DestinationDescription", "S3", "DeliveryToS3"},
+	{"S3DestinationDescription", "S3", "DeliveryToS3"},
+}
+
+// firehoseDelivery is the delivery picture of one Firehose stream.
+type firehoseDelivery struct {
+	Name        string
+	Status      string
+	Destination string
+	// SuccessRate is the lowest per-period average of the destination's
+	// .Success metric; -1 when there were no deliveries.
+	SuccessRate float64
+	// Freshness is the oldest record age still waiting for delivery.
+	Freshness time.Duration
+	Throttled float64
+	Err       string
+}
+
+// analyzeKinesisLag is the analyze_kinesis_lag operation: it flags Kinesis
+// consumers whose iterator age is high or growing, streams throttling reads
+// or writes, and Firehose streams failing to deliver to their destination.
+func (c *Client) analyzeKinesisLag(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	threshold := kinesisIteratorAgeThreshold
+	if seconds, ok := intParam(input, "iterator_age_threshold_seconds"); ok && seconds > 0 {
+		threshold = time.Duration(seconds) * time.Second
+	}
+	streamName := getStringParam(input, "stream_name", "")
+	deliveryName := getStringParam(input, "delivery_stream_name", "")
+	query := getStringParam(input, "query", "")
+	start, end := operationWindow(ctx, input, kinesisLagWindow)
+
+	var out strings.Builder
+	out.WriteString("🌊 Stream lag\n")
+	out.WriteString("============================\n")
+
+	if deliveryName == "" {
+		names, msg := c.kinesisLagStreams(ctx, streamName, query, profile)
+		if msg != "" {
+			out.WriteString(msg + "\n")
+		} else {
+			var streams []kinesisStreamLag
+			for _, name := range names {
+				streams = append(streams, c.kinesisStreamLag(ctx, name, start, end, profile))
+			}
+			out.WriteString(formatKinesisLag(streams, threshold, windowPhrase(start, end)))
+		}
+	}
+
+	if streamName == "" {
+		if deliveryName == "" {
+			out.WriteString("\n")
+		}
+		out.WriteString(c.firehoseDeliveryHealth(ctx, deliveryName, query, start, end, profile))
+	}
+	return out.String(), nil
+}
+
+// kinesisLagStreams resolves the streams to check: the named stream, the
+// streams a query names, or every stream up to kinesisLagMaxStreams.
+func (c *Client) kinesisLagStreams(ctx context.Context, streamName, query string, profile *AIProfile) (names []string, msg string) {
+	if streamName != "" {
+		return []string{streamName}, ""
+	}
+	raw, err := c.execAWSCLI(ctx, []string{"kinesis", "list-streams", "--output", "json"}, profile)
+	if err != nil {
+		return nil, categorizeAWSError(err, "Kinesis")
+	}
+	var resp struct {
+		StreamNames []string `json:"StreamNames"`
+	}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return nil, fmt.Sprintf("Failed to parse Kinesis streams: %v", err)
+		}
+	}
+	if len(resp.StreamNames) == 0 {
+		return nil, "No Kinesis data streams found."
+	}
+	if matched := rolesReferencedInQuery(resp.StreamNames, query); len(matched) > 0 {
+		return matched, ""
+	}
+	return limitStrings(resp.StreamNames, kinesisLagMaxStreams), ""
+}
+
+func (c *Client) kinesisStreamLag(ctx context.Context, name string, start, end time.Time, profile *AIProfile) kinesisStreamLag {
+	s := kinesisStreamLag{Name: name}
+	raw, err := c.execAWSCLI(ctx, []string{"kinesis", "describe-stream-summary", "--stream-name", name, "--output", "json"}, profile)
+	if err != nil {
+		s.Err = categorizeAWSError(err, "Kinesis")
+		return s
+	}
+	var resp struct {
+		StreamDescriptionSummary struct {
+			RetentionPeriodHours int `json:"RetentionPeriodHours"`
+			OpenShardCount       int `json:"OpenShardCount"`
+			ConsumerCount        int `json:"ConsumerCount"`
+			StreamModeDetails    struct {
+				StreamMode string `json:"StreamMode"`
+			} `json:"StreamModeDetails"`
+		} `json:"StreamDescriptionSummary"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		s.Err = fmt.Sprintf("failed to parse stream summary: %v", err)
+		return s
+	}
+	summary := resp.StreamDescriptionSummary
+	s.Shards = summary.OpenShardCount
+	s.Consumers = summary.ConsumerCount
+	s.Mode = summary.StreamModeDetails.StreamMode
+	s.Retention = time.Duration(summary.RetentionPeriodHours) * time.Hour
+
+	dims := []metricDimension{{Name: "StreamName", Value: name}}
+	s.IteratorAge = c.streamMetric(ctx, "AWS/Kinesis", "GetRecords.IteratorAgeMilliseconds", "Maximum", dims, start, end, profile)
+	s.WriteThrottled = sumDatapoints(c.streamMetric(ctx, "AWS/Kinesis", "WriteProvisionedThroughputExceeded", "Sum", dims, start, end, profile))
+	s.ReadThrottled = sumDatapoints(c.streamMetric(ctx, "AWS/Kinesis", "ReadProvisionedThroughputExceeded", "Sum", dims, start, end, profile))
+	return s
+}
+
+// streamMetric reads one metric over the window, oldest first; failures
+// read as no datapoints.
+func (c *Client) streamMetric(ctx context.Context, namespace, metric, stat string, dims []metricDimension, start, end time.Time, profile *AIProfile) []metricDatapoint {
+	window := end.Sub(start)
+	req := metricStatisticsRequest{
+		Namespace:  namespace,
+		MetricName: metric,
+		Dimensions: dims,
+		Period:     metricPeriodForWindow(kinesisMetricPeriod, window),
+		Stat:       stat,
+		Window:     window,
+	}
+	raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile)
+	if err != nil {
+		return nil
+	}
+	points, _ := decodeMetricDatapoints(raw, req)
+	return points
+}
+
+func sumDatapoints(points []metricDatapoint) float64 {
+	total := 0.0
+	for _, p := range points {
+		total += p.Value
+	}
+	return total
+}
+
+// kinesisLagFindings flags consumers that are behind, with whether the lag
+// is still growing and whether it threatens retention, and throttling that
+// points at too few shards.
+func kinesisLagFindings(s kinesisStreamLag, threshold time.Duration) []string {
+	var findings []string
+	if n := len(s.IteratorAge); n > 0 {
+		first := time.Duration(s.IteratorAge[0].Value) * time.Millisecond
+		latest := time.Duration(s.IteratorAge[n-1].Value) * time.Millisecond
+		peak := time.Duration(0)
+		for _, p := range s.IteratorAge {
+			if age := time.Duration(p.Value) * time.Millisecond; age > peak {
+				peak = age
+			}
+		}
+		switch {
+		case latest > threshold && n > 1 && latest > 2*first:
+			findings = append(findings, fmt.Sprintf("consumer lag is growing: iterator age rose from %s to %s; consumers read slower than producers write", formatQueueAge(first), formatQueueAge(latest)))
+		case latest > threshold:
+			findings = append(findings, fmt.Sprintf("consumers are %s behind (threshold %s); check consumer errors and processing time", formatQueueAge(latest), formatQueueAge(threshold)))
+		case peak > threshold:
+			findings = append(findings, fmt.Sprintf("consumers fell %s behind during the window and have since caught up to %s", formatQueueAge(peak), formatQueueAge(latest)))
+		}
+		if s.Retention > 0 && peak > s.Retention/2 {
+			findings = append(findings, fmt.Sprintf("iterator age %s is over half the %s retention; records will expire before they are read", formatQueueAge(peak), formatLookback(s.Retention)))
+		}
+	}
+
+	onDemand := s.Mode == "ON_DEMAND"
+	if s.WriteThrottled > 0 {
+		advice := "producers exceed 1 MB/s or 1,000 records/s per shard; add shards, switch to on-demand, or spread partition keys"
+		if onDemand {
+			advice = "writes exceed the on-demand capacity or concentrate on hot partition keys; spread partition keys"
+		}
+		findings = append(findings, fmt.Sprintf("%.0f writes throttled: %s", s.WriteThrottled, advice))
+	}
+	if s.ReadThrottled > 0 {
+		advice := "consumers exceed 2 MB/s or 5 GetRecords calls/s per shard; add shards or move consumers to enhanced fan-out"
+		if s.Consumers > 0 {
+			advice = "shared-throughput consumers exceed 2 MB/s or 5 GetRecords calls/s per shard; add shards or move the rest to enhanced fan-out"
+		}
+		findings = append(findings, fmt.Sprintf("%.0f reads throttled: %s", s.ReadThrottled, advice))
+	}
+	return findings
+}
+
+func formatKinesisLag(streams []kinesisStreamLag, threshold time.Duration, window string) string {
+	var out strings.Builder
+	var lines, errs []string
+	flagged := 0
+	for _, s := range streams {
+		if s.Err != "" {
+			errs = append(errs, fmt.Sprintf("  • %s: %s", s.Name, s.Err))
+			continue
+		}
+		for _, f := range kinesisLagFindings(s, threshold) {
+			out.WriteString(fmt.Sprintf("⚠️  %s: %s\n", s.Name, f))
+			flagged++
+		}
+		line := fmt.Sprintf("  • %s: %d open shards", s.Name, s.Shards)
+		if s.Mode != "" {
+			line += ", " + strings.ToLower(strings.ReplaceAll(s.Mode, "_", "-"))
+		}
+		if s.Consumers > 0 {
+			line += fmt.Sprintf(", %d enhanced fan-out consumers", s.Consumers)
+		}
+		if n := len(s.IteratorAge); n > 0 {
+			line += ", iterator age " + formatQueueAge(time.Duration(s.IteratorAge[n-1].Value)*time.Millisecond)
+		} else {
+			line += ", no GetRecords activity"
+		}
+		lines = append(lines, line)
+	}
+	if flagged == 0 && len(lines) > 0 {
+		out.WriteString(fmt.Sprintf("✅ No consumer lag over %s and no throttling across %d Kinesis streams %s\n", formatQueueAge(threshold), len(lines), window))
+	}
+	if len(lines) > 0 {
+		out.WriteString(fmt.Sprintf("\nKinesis streams %s:\n", window))
+		out.WriteString(strings.Join(lines, "\n") + "\n")
+	}
+	if len(errs) > 0 {
+		out.WriteString("\nCould not read:\n")
+		out.WriteString(strings.Join(errs, "\n") + "\n")
+	}
+	return out.String()
+}
+
+// firehoseDeliveryHealth checks the named delivery stream, the ones a query
+// names, or every stream up to firehoseLagMaxStreams.
+func (c *Client) firehoseDeliveryHealth(ctx context.Context, deliveryName, query string, start, end time.Time, profile *AIProfile) string {
+	names := []string{deliveryName}
+	if deliveryName == "" {
+		raw, err := c.execAWSCLI(ctx, []string{"firehose", "list-delivery-streams", "--output", "json"}, profile)
+		if err != nil {
+			return categorizeAWSError(err, "Firehose") + "\n"
+		}
+		var resp struct {
+			DeliveryStreamNames []string `json:"DeliveryStreamNames"`
+		}
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return fmt.Sprintf("Failed to parse Firehose delivery streams: %v\n", err)
+		}
+		if len(resp.DeliveryStreamNames) == 0 {
+			return "No Firehose delivery streams found.\n"
+		}
+		names = rolesReferencedInQuery(resp.DeliveryStreamNames, query)
+		if len(names) == 0 {
+			names = limitStrings(resp.DeliveryStreamNames, firehoseLagMaxStreams)
+		}
+	}
+
+	var streams []firehoseDelivery
+	for _, name := range names {
+		streams = append(streams, c.firehoseDelivery(ctx, name, start, end, profile))
+	}
+	return formatFirehoseDelivery(streams, windowPhrase(start, end))
+}
+
+func (c *Client) firehoseDelivery(ctx context.Context, name string, start, end time.Time, profile *AIProfile) firehoseDelivery {
+	d := firehoseDelivery{Name: name, SuccessRate: -1}
+	raw, err := c.execAWSCLI(ctx, []string{"firehose", "describe-delivery-stream", "--delivery-stream-name", name, "--output", "json"}, profile)
+	if err != nil {
+		d.Err = categorizeAWSError(err, "Firehose")
+		return d
+	}
+	var resp struct {
+		DeliveryStreamDescription struct {
+			DeliveryStreamStatus string                       `json:"DeliveryStreamStatus"`
+			Destinations         []map[string]json.RawMessage `json:"Destinations"`
+		} `json:"DeliveryStreamDescription"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		d.Err = fmt.Sprintf("failed to parse delivery stream: %v", err)
+		return d
+	}
+	desc := resp.DeliveryStreamDescription
+	d.Status = desc.DeliveryStreamStatus
+
+	metricPrefix := ""
+	if len(desc.Destinations) > 0 {
+		for _, dest := range firehoseDestinations {
+			if _, ok := desc.Destinations[0][dest.field]; ok {
+				d.Destination, metricPrefix = dest.label, dest.metric
+				break
+			}
+		}
+	}
+	dims := []metricDimension{{Name: "DeliveryStreamName", Value: name}}
+	if metricPrefix != "" {
+		for _, p := range c.streamMetric(ctx, "AWS/Firehose", metricPrefix+".Success", "Average", dims, start, end, profile) {
+			if d.SuccessRate < 0 || p.Value < d.SuccessRate {
+				d.SuccessRate = p.Value
+			}
+		}
+		if points := c.streamMetric(ctx, "AWS/Firehose", metricPrefix+".DataFreshness", "Maximum", dims, start, end, profile); len(points) > 0 {
+			d.Freshness = time.Duration(points[len(points)-1].Value) * time.Second
+		}
+	}
+	d.Throttled = sumDatapoints(c.streamMetric(ctx, "AWS/Firehose", "ThrottledRecords", "Sum", dims, start, end, profile))
+	return d
+}
+
+// firehoseFindings flags failed deliveries, records waiting longer than the
+// default 15 minute buffer allows, and throttled producers.
+func firehoseFindings(d firehoseDelivery) []string {
+	var findings []string
+	if d.Status != "" && d.Status != "ACTIVE" {
+		findings = append(findings, "delivery stream is "+d.Status)
+	}
+	if d.SuccessRate >= 0 && d.SuccessRate < 1 {
+		findings = append(findings, fmt.Sprintf("as low as %.0f%% of deliveries to %s succeeded; check the destination's permissions, availability and the stream's error logs", d.SuccessRate*100, d.Destination))
+	}
+	if d.Freshness > 15*time.Minute {
+		findings = append(findings, fmt.Sprintf("oldest undelivered record is %s old; delivery to %s is backing up", formatQueueAge(d.Freshness), d.Destination))
+	}
+	if d.Throttled > 0 {
+		findings = append(findings, fmt.Sprintf("%.0f records throttled: producers exceed the delivery stream's throughput quota", d.Throttled))
+	}
+	return findings
+}
+
+func formatFirehoseDelivery(streams []firehoseDelivery, window string) string {
+	var out strings.Builder
+	var lines []string
+	flagged, checked := 0, 0
+	for _, d := range streams {
+		if d.Err != "" {
+			lines = append(lines, fmt.Sprintf("  • %s: %s", d.Name, d.Err))
+			continue
+		}
+		checked++
+		for _, f := range firehoseFindings(d) {
+			out.WriteString(fmt.Sprintf("⚠️  %s: %s\n", d.Name, f))
+			flagged++
+		}
+		line := fmt.Sprintf("  • %s: %s", d.Name, valueOr(d.Destination, "unknown destination"))
+		if d.SuccessRate >= 0 {
+			line += fmt.Sprintf(", lowest delivery success %.0f%%", d.SuccessRate*100)
+		} else {
+			line += ", no deliveries"
+		}
+		lines = append(lines, line)
+	}
+	if flagged == 0 && checked > 0 {
+		out.WriteString(fmt.Sprintf("✅ No Firehose delivery failures or backlog across %d delivery streams %s\n", checked, window))
+	}
+	out.WriteString(fmt.Sprintf("\nFirehose delivery streams %s:\n", window))
+	out.WriteString(strings.Join(lines, "\n") + "\n")
+	return out.String()
+}
@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeKinesisLag(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["kinesis list-streams"] = `{"StreamNames": ["clicks", "audit"]}`
+	f.fixtures["kinesis describe-stream-summary --stream-name clicks"] = `{"StreamDescriptionSummary": {
+		"RetentionPeriodHours": 24, "OpenShardCount": 2, "ConsumerCount": 0,
+		"StreamModeDetails": {"StreamMode": "PROVISIONED"}}}`
+	f.fixtures["kinesis describe-stream-summary --stream-name audit"] = `{"StreamDescriptionSummary": {
+		"RetentionPeriodHours": 24, "OpenShardCount": 1, "StreamModeDetails": {"StreamMode": "ON_DEMAND"}}}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/Kinesis --metric-name GetRecords.IteratorAgeMilliseconds"] = `{"Datapoints": []}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/Kinesis --metric-name GetRecords.IteratorAgeMilliseconds --start-time"] = `{"Datapoints": []}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/Kinesis --metric-name WriteProvisionedThroughputExceeded"] = `{"Datapoints": []}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/Kinesis --metric-name ReadProvisionedThroughputExceeded"] = `{"Datapoints": []}`
+	f.fixtures["firehose list-delivery-streams"] = `{"DeliveryStreamNames": ["logs-to-s3"]}`
+	f.fixtures["firehose describe-delivery-stream --delivery-stream-name logs-to-s3"] = `{"DeliveryStreamDescription": {
+		"DeliveryStreamStatus": "ACTIVE",
+		"Destinations": [{"DestinationId": "d-1", "ExtendedS3DestinationDescription": {"BucketARN": "arn:aws:s3:::logs"}}]}}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/Firehose --metric-name DeliveryToS3.Success"] = `{"Datapoints": [
+		{"Timestamp": "2024-05-01T12:00:00Z", "Average": 1},
+		{"Timestamp": "2024-05-01T12:05:00Z", "Average": 0.25}]}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/Firehose --metric-name DeliveryToS3.DataFreshness"] = `{"Datapoints": [{"Timestamp": "2024-05-01T12:05:00Z", "Maximum": 2700}]}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/Firehose --metric-name ThrottledRecords"] = `{"Datapoints": []}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "analyze_kinesis_lag", map[string]interface{}{"query": "is the pipeline behind"}, &AIProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"✅ No consumer lag over 1m and no throttling across 2 Kinesis streams",
+		"clicks: 2 open shards, provisioned, no GetRecords activity",
+		"audit: 1 open shards, on-demand",
+		"logs-to-s3: as low as 25% of deliveries to S3 succeeded",
+		"logs-to-s3: oldest undelivered record is 45m old",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestKinesisLagFindings(t *testing.T) {
+	at := func(minutes int, ms float64) metricDatapoint {
+		return metricDatapoint{Timestamp: time.Date(2024, 5, 1, 12, minutes, 0, 0, time.UTC), Value: ms}
+	}
+	s := kinesisStreamLag{
+		Name:           "clicks",
+		Mode:           "PROVISIONED",
+		Retention:      24 * time.Hour,
+		IteratorAge:    []metricDatapoint{at(0, 30_000), at(5, 240_000), at(10, 900_000)},
+		WriteThrottled: 120,
+		ReadThrottled:  8,
+	}
+	got := strings.Join(kinesisLagFindings(s, kinesisIteratorAgeThreshold), "\n")
+	for _, want := range []string{
+		"consumer lag is growing: iterator age rose from 30s to 15m",
+		"120 writes throttled: producers exceed 1 MB/s or 1,000 records/s per shard",
+		"8 reads throttled",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("findings missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "retention") {
+		t.Errorf("15m of lag should not threaten 24h retention:\n%s", got)
+	}
+
+	s = kinesisStreamLag{Name: "audit", Retention: 24 * time.Hour, IteratorAge: []metricDatapoint{at(0, 14*3600_000), at(5, 1_000)}}
+	got = strings.Join(kinesisLagFindings(s, kinesisIteratorAgeThreshold), "\n")
+	if !strings.Contains(got, "fell 14h0m behind during the window and have since caught up to 1s") || !strings.Contains(got, "over half the 1d retention") {
+		t.Errorf("unexpected findings:\n%s", got)
+	}
+}
@@ -0,0 +1,344 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// lambdaAuditMaxFunctions caps how many functions one audit checks when
+	// neither function_name nor the query names any.
+	lambdaAuditMaxFunctions = 10
+	// lambdaAuditMaxReports bounds the REPORT lines read per function.
+	lambdaAuditMaxReports = 1000
+	// lambdaTimeoutRiskPercent: a max duration above this share of the
+	// timeout means some invocations are timing out or about to.
+	lambdaTimeoutRiskPercent = 80.0
+	// lambdaTimeoutSlackFactor: a timeout this many times the max observed
+	// duration lets hung invocations bill for a long time.
+	lambdaTimeoutSlackFactor = 10.0
+	// lambdaRuntimeWarnWindow is how far ahead of a runtime's deprecation
+	// date the audit starts warning.
+	lambdaRuntimeWarnWindow = 180 * 24 * time.Hour
+)
+
+// lambdaRuntimeDeprecations lists when AWS stopped (or stops) applying
+// security patches to each runtime.
+var lambdaRuntimeDeprecations = map[string]string{
+	"nodejs12.x":    "2023-03-31",
+	"nodejs14.x":    "2023-12-04",
+	"nodejs16.x":    "2024-06-12",
+	"nodejs18.x":    "2025-09-01",
+	"nodejs20.x":    "2026-04-30",
+	"python3.7":     "2023-12-04",
+	"python3.8":     "2024-10-14",
+	"python3.9":     "2025-12-15",
+	"ruby2.7":       "2023-12-07",
+	"ruby3.2":       "2026-03-31",
+	"java8":         "2024-01-08",
+	"go1.x":         "2023-12-31",
+	"provided":      "2023-12-31",
+	"dotnetcore3.1": "2023-04-03",
+	"dotnet6":       "2024-12-20",
+	"dotnet7":       "2024-05-14",
+}
+
+// lambdaFunctionConfig is the part of a function configuration the audit
+// reads.
+type lambdaFunctionConfig struct {
+	FunctionName     string `json:"FunctionName"`
+	Runtime          string `json:"Runtime"`
+	PackageType      string `json:"PackageType"`
+	Timeout          int    `json:"Timeout"`    // seconds
+	MemorySize       int    `json:"MemorySize"` // MB
+	DeadLetterConfig *struct {
+		TargetArn string `json:"TargetArn"`
+	} `json:"DeadLetterConfig"`
+}
+
+// lambdaProvisioned is one alias or version's provisioned concurrency.
+type lambdaProvisioned struct {
+	Qualifier string
+	Allocated int
+	Requested int
+	Status    string // IN_PROGRESS, READY or FAILED
+	Reason    string
+}
+
+func (p lambdaProvisioned) String() string {
+	s := fmt.Sprintf("%s: %d/%d %s", p.Qualifier, p.Allocated, p.Requested, p.Status)
+	if p.Reason != "" {
+		s += " (" + p.Reason + ")"
+	}
+	return s
+}
+
+// lambdaAudit is what audit_lambda_config found for one function.
+type lambdaAudit struct {
+	Config      lambdaFunctionConfig
+	Performance lambdaPerformance
+	Reserved    *int // reserved concurrency, nil when unset
+	Provisioned []lambdaProvisioned
+	OnFailure   string // async on-failure destination, "" when unset
+	Findings    []string
+	Err         string
+}
+
+// auditLambdaConfig is the audit_lambda_config operation: for one function
+// (function_name), the functions named in query, or the first
+// lambdaAuditMaxFunctions functions, it compares the configuration with
+// observed REPORT lines and flags timeout and memory mismatches, missing
+// failure handling for async invocations, concurrency settings and
+// deprecated runtimes, each with a recommendation.
+func (c *Client) auditLambdaConfig(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	configs, skipped, err := c.lambdaAuditTargets(ctx, input, profile)
+	if err != nil {
+		return categorizeAWSError(err, "Lambda"), nil
+	}
+
+	var out strings.Builder
+	out.WriteString("🩺 Lambda configuration audit\n")
+	out.WriteString("============================\n")
+	if len(configs) == 0 {
+		out.WriteString("No Lambda functions found\n")
+		return out.String(), nil
+	}
+
+	start, end := operationWindow(ctx, input, 24*time.Hour)
+	now := time.Now().UTC()
+	audits := make([]*lambdaAudit, 0, len(configs))
+	for _, cfg := range configs {
+		audit := &lambdaAudit{Config: cfg}
+		audits = append(audits, audit)
+		reports, err := c.lambdaReports(ctx, cfg.FunctionName, start, end, []string{"--max-items", fmt.Sprint(lambdaAuditMaxReports)}, profile)
+		if err != nil {
+			audit.Err = categorizeAWSError(err, "CloudWatch Logs")
+		}
+		audit.Performance = summarizeLambdaReports(reports)
+		c.lambdaAuditConcurrency(ctx, audit, profile)
+		c.lambdaAuditFailureHandling(ctx, audit, profile)
+		audit.Findings = lambdaAuditFindings(audit, now)
+	}
+
+	out.WriteString(fmt.Sprintf("Observed invocations %s\n", windowPhrase(start, end)))
+	formatLambdaAudit(&out, audits)
+	if skipped > 0 {
+		out.WriteString(fmt.Sprintf("\n… %d more functions not audited; pass function_name to audit one\n", skipped))
+	}
+	return out.String(), nil
+}
+
+// lambdaAuditTargets picks the functions to audit and how many were left out
+// by the lambdaAuditMaxFunctions cap.
+func (c *Client) lambdaAuditTargets(ctx context.Context, input map[string]interface{}, profile *AIProfile) ([]lambdaFunctionConfig, int, error) {
+	if name := strings.TrimSpace(getStringParam(input, "function_name", "")); name != "" {
+		raw, err := c.execAWSCLI(ctx, []string{"lambda", "get-function-configuration", "--function-name", name, "--output", "json"}, profile)
+		if err != nil {
+			return nil, 0, err
+		}
+		var cfg lambdaFunctionConfig
+		if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse configuration of %s: %w", name, err)
+		}
+		return []lambdaFunctionConfig{cfg}, 0, nil
+	}
+
+	all, err := c.listLambdaConfigs(ctx, profile)
+	if err != nil {
+		return nil, 0, err
+	}
+	if named := lambdaFunctionsNamedIn(all, getStringParam(input, "query", "")); len(named) > 0 {
+		all = named
+	}
+	if len(all) > lambdaAuditMaxFunctions {
+		return all[:lambdaAuditMaxFunctions], len(all) - lambdaAuditMaxFunctions, nil
+	}
+	return all, 0, nil
+}
+
+// listLambdaConfigs returns every function's configuration, sorted by name.
+func (c *Client) listLambdaConfigs(ctx context.Context, profile *AIProfile) ([]lambdaFunctionConfig, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"lambda", "list-functions", "--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Functions []lambdaFunctionConfig `json:"Functions"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse function list: %w", err)
+	}
+	sort.Slice(resp.Functions, func(i, j int) bool { return resp.Functions[i].FunctionName < resp.Functions[j].FunctionName })
+	return resp.Functions, nil
+}
+
+// lambdaFunctionsNamedIn returns the functions whose names appear in query.
+func lambdaFunctionsNamedIn(all []lambdaFunctionConfig, query string) []lambdaFunctionConfig {
+	query = strings.ToLower(query)
+	if query == "" {
+		return nil
+	}
+	var named []lambdaFunctionConfig
+	for _, cfg := range all {
+		if strings.Contains(query, strings.ToLower(cfg.FunctionName)) {
+			named = append(named, cfg)
+		}
+	}
+	return named
+}
+
+// lambdaAuditConcurrency fills in reserved and provisioned concurrency.
+// Lookup failures leave them unset.
+func (c *Client) lambdaAuditConcurrency(ctx context.Context, audit *lambdaAudit, profile *AIProfile) {
+	name := audit.Config.FunctionName
+	if raw, err := c.execAWSCLI(ctx, []string{"lambda", "get-function-concurrency", "--function-name", name, "--output", "json"}, profile); err == nil {
+		var resp struct {
+			ReservedConcurrentExecutions *int `json:"ReservedConcurrentExecutions"`
+		}
+		if json.Unmarshal([]byte(raw), &resp) == nil {
+			audit.Reserved = resp.ReservedConcurrentExecutions
+		}
+	}
+	if raw, err := c.execAWSCLI(ctx, []string{"lambda", "list-provisioned-concurrency-configs", "--function-name", name, "--output", "json"}, profile); err == nil {
+		var resp struct {
+			ProvisionedConcurrencyConfigs []struct {
+				FunctionArn  string `json:"FunctionArn"`
+				Requested    int    `json:"RequestedProvisionedConcurrentExecutions"`
+				Allocated    int    `json:"AllocatedProvisionedConcurrentExecutions"`
+				Status       string `json:"Status"`
+				StatusReason string `json:"StatusReason"`
+			} `json:"ProvisionedConcurrencyConfigs"`
+		}
+		if json.Unmarshal([]byte(raw), &resp) == nil {
+			for _, p := range resp.ProvisionedConcurrencyConfigs {
+				audit.Provisioned = append(audit.Provisioned, lambdaProvisioned{
+					Qualifier: p.FunctionArn[strings.LastIndex(p.FunctionArn, ":")+1:],
+					Allocated: p.Allocated,
+					Requested: p.Requested,
+					Status:    p.Status,
+					Reason:    p.StatusReason,
+				})
+			}
+		}
+	}
+}
+
+// lambdaAuditFailureHandling looks up the async on-failure destination when
+// the function has no dead-letter queue.
+func (c *Client) lambdaAuditFailureHandling(ctx context.Context, audit *lambdaAudit, profile *AIProfile) {
+	if audit.Config.DeadLetterConfig != nil && audit.Config.DeadLetterConfig.TargetArn != "" {
+		return
+	}
+	raw, err := c.execAWSCLI(ctx, []string{"lambda", "get-function-event-invoke-config", "--function-name", audit.Config.FunctionName, "--output", "json"}, profile)
+	if err != nil {
+		return // ResourceNotFoundException: no event invoke config
+	}
+	var resp struct {
+		DestinationConfig struct {
+			OnFailure struct {
+				Destination string `json:"Destination"`
+			} `json:"OnFailure"`
+		} `json:"DestinationConfig"`
+	}
+	if json.Unmarshal([]byte(raw), &resp) == nil {
+		audit.OnFailure = resp.DestinationConfig.OnFailure.Destination
+	}
+}
+
+// lambdaAuditFindings turns one function's configuration and observed
+// behaviour into recommendations.
+func lambdaAuditFindings(audit *lambdaAudit, now time.Time) []string {
+	cfg, p := audit.Config, audit.Performance
+	var findings []string
+
+	if cfg.Runtime != "" {
+		if date, ok := lambdaRuntimeDeprecations[cfg.Runtime]; ok {
+			if deprecated, err := time.Parse("2006-01-02", date); err == nil {
+				switch {
+				case !now.Before(deprecated):
+					findings = append(findings, fmt.Sprintf("🚨 Runtime %s reached end of life on %s and no longer gets security patches; upgrade to a supported version of the language.", cfg.Runtime, date))
+				case deprecated.Sub(now) <= lambdaRuntimeWarnWindow:
+					findings = append(findings, fmt.Sprintf("⚠️  Runtime %s is deprecated on %s; plan the upgrade before then.", cfg.Runtime, date))
+				}
+			}
+		}
+	}
+
+	if timeoutMs := float64(cfg.Timeout) * 1000; timeoutMs > 0 && p.Invocations > 0 {
+		switch pct := p.MaxDuration / timeoutMs * 100; {
+		case pct >= lambdaTimeoutRiskPercent:
+			findings = append(findings, fmt.Sprintf("⚠️  Max duration %s is %.0f%% of the %ds timeout (p99 %s); invocations are timing out or close to it. Raise the timeout or speed up the slow path.", formatLambdaMillis(p.MaxDuration), pct, cfg.Timeout, formatLambdaMillis(p.P99)))
+		case cfg.Timeout >= 30 && timeoutMs >= lambdaTimeoutSlackFactor*p.MaxDuration:
+			suggested := int(p.MaxDuration*3/1000) + 1
+			findings = append(findings, fmt.Sprintf("💡 Timeout %ds is far above the max duration %s; a hung invocation bills for the full timeout. Consider about %ds.", cfg.Timeout, formatLambdaMillis(p.MaxDuration), suggested))
+		}
+	}
+
+	if cfg.MemorySize > 0 && p.PeakMemory > 0 {
+		pct := float64(p.PeakMemory) / float64(cfg.MemorySize) * 100
+		switch suggested := suggestedLambdaMemory(p.PeakMemory); {
+		case pct >= lambdaMemoryHighPercent:
+			findings = append(findings, fmt.Sprintf("⚠️  Memory is under-provisioned: peak %d MB of %d MB (%.0f%%). Raise it to %d MB.", p.PeakMemory, cfg.MemorySize, pct, suggested))
+		case pct < lambdaMemoryLowPercent && suggested < cfg.MemorySize:
+			findings = append(findings, fmt.Sprintf("💡 Memory is over-provisioned: peak %d MB of %d MB (%.0f%%). Try %d MB and keep the current size if duration rises.", p.PeakMemory, cfg.MemorySize, pct, suggested))
+		}
+	}
+
+	hasDLQ := cfg.DeadLetterConfig != nil && cfg.DeadLetterConfig.TargetArn != ""
+	if !hasDLQ && audit.OnFailure == "" {
+		findings = append(findings, "💡 No dead-letter queue or on-failure destination: failed asynchronous invocations are dropped after retries. Add an SQS DLQ or an OnFailure destination if the function is invoked asynchronously (S3, SNS, EventBridge).")
+	}
+
+	if audit.Reserved != nil && *audit.Reserved == 0 {
+		findings = append(findings, "🚨 Reserved concurrency is 0, so every invocation is throttled. Remove the setting or raise it if the function should run.")
+	}
+	for _, pc := range audit.Provisioned {
+		if pc.Status != "READY" {
+			findings = append(findings, "⚠️  Provisioned concurrency is not ready: "+pc.String())
+		}
+	}
+	return findings
+}
+
+// formatLambdaAudit lists functions with findings first, then the ones
+// without.
+func formatLambdaAudit(out *strings.Builder, audits []*lambdaAudit) {
+	var clean []string
+	for _, audit := range audits {
+		if len(audit.Findings) == 0 && audit.Err == "" {
+			clean = append(clean, audit.Config.FunctionName)
+			continue
+		}
+		cfg := audit.Config
+		runtime := cfg.Runtime
+		if runtime == "" {
+			runtime = strings.ToLower(cfg.PackageType)
+		}
+		out.WriteString(fmt.Sprintf("\n%s (%s, %d MB, %ds timeout", cfg.FunctionName, runtime, cfg.MemorySize, cfg.Timeout))
+		if audit.Reserved != nil {
+			out.WriteString(fmt.Sprintf(", reserved concurrency %d", *audit.Reserved))
+		}
+		for _, pc := range audit.Provisioned {
+			out.WriteString(fmt.Sprintf(", provisioned %d on %s", pc.Requested, pc.Qualifier))
+		}
+		out.WriteString(")\n")
+		if audit.Performance.Invocations > 0 {
+			out.WriteString(fmt.Sprintf("  %d invocations, p99 %s, peak memory %d MB\n", audit.Performance.Invocations, formatLambdaMillis(audit.Performance.P99), audit.Performance.PeakMemory))
+		} else if audit.Err == "" {
+			out.WriteString("  No invocations observed; duration and memory were not checked\n")
+		}
+		if audit.Err != "" {
+			out.WriteString("  ❓ Could not read REPORT lines: " + audit.Err + "\n")
+		}
+		for _, f := range audit.Findings {
+			out.WriteString("  " + f + "\n")
+		}
+	}
+	if len(clean) > 0 {
+		out.WriteString(fmt.Sprintf("\n✅ No findings: %s\n", strings.Join(clean, ", ")))
+	}
+}
@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLambdaAuditFindings(t *testing.T) {
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	zero := 0
+	audit := &lambdaAudit{
+		Config:      lambdaFunctionConfig{FunctionName: "checkout", Runtime: "nodejs14.x", Timeout: 3, MemorySize: 1024},
+		Performance: lambdaPerformance{Invocations: 50, P99: 2800, MaxDuration: 2950, PeakMemory: 200},
+		Reserved:    &zero,
+		Provisioned: []lambdaProvisioned{{Qualifier: "live", Allocated: 0, Requested: 5, Status: "FAILED", Reason: "quota exceeded"}},
+	}
+	got := strings.Join(lambdaAuditFindings(audit, now), "\n")
+	for _, want := range []string{
+		"Runtime nodejs14.x reached end of life on 2023-12-04",
+		"Max duration 2.95s is 98% of the 3s timeout",
+		"over-provisioned: peak 200 MB of 1024 MB (20%). Try 320 MB",
+		"No dead-letter queue or on-failure destination",
+		"Reserved concurrency is 0",
+		"Provisioned concurrency is not ready: live: 0/5 FAILED (quota exceeded)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("findings missing %q:\n%s", want, got)
+		}
+	}
+
+	// A generous timeout, soon-deprecated runtime and a DLQ.
+	audit = &lambdaAudit{
+		Config: lambdaFunctionConfig{FunctionName: "reports", Runtime: "ruby3.2", Timeout: 900, MemorySize: 512,
+			DeadLetterConfig: &struct {
+				TargetArn string `json:"TargetArn"`
+			}{TargetArn: "arn:aws:sqs:us-east-1:123456789012:reports-dlq"}},
+		Performance: lambdaPerformance{Invocations: 10, P99: 1200, MaxDuration: 1500, PeakMemory: 400},
+	}
+	got = strings.Join(lambdaAuditFindings(audit, now), "\n")
+	if !strings.Contains(got, "Runtime ruby3.2 is deprecated on 2026-03-31") {
+		t.Errorf("expected a deprecation warning, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Timeout 900s is far above the max duration 1.50s") || !strings.Contains(got, "Consider about 5s") {
+		t.Errorf("expected a timeout slack finding, got:\n%s", got)
+	}
+	if strings.Contains(got, "dead-letter") || strings.Contains(got, "Memory") {
+		t.Errorf("unexpected DLQ or memory finding:\n%s", got)
+	}
+}
+
+func TestAuditLambdaConfigOperation(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["lambda list-functions"] = `{"Functions":[
+		{"FunctionName":"checkout","Runtime":"python3.8","Timeout":10,"MemorySize":256},
+		{"FunctionName":"healthy","Runtime":"python3.12","Timeout":10,"MemorySize":256},
+		{"FunctionName":"other","Runtime":"python3.12","Timeout":10,"MemorySize":256}]}`
+	var events []map[string]interface{}
+	for i := 0; i < 5; i++ {
+		events = append(events, map[string]interface{}{"Timestamp": 1714564800000 + i,
+			"Message": fmt.Sprintf("REPORT RequestId: r%d\tDuration: 9500.00 ms\tBilled Duration: 9500 ms\tMemory Size: 256 MB\tMax Memory Used: 250 MB\t", i)})
+	}
+	raw, _ := json.Marshal(events)
+	f.fixtures["logs filter-log-events --log-group-name /aws/lambda/checkout"] = string(raw)
+	f.fixtures["logs filter-log-events --log-group-name /aws/lambda/healthy"] = "[]"
+	f.fixtures["lambda get-function-concurrency"] = "{}"
+	f.fixtures["lambda list-provisioned-concurrency-configs"] = `{"ProvisionedConcurrencyConfigs":[]}`
+	f.fixtures["lambda get-function-event-invoke-config --function-name checkout"] = `{"DestinationConfig":{"OnFailure":{}}}`
+	f.fixtures["lambda get-function-event-invoke-config --function-name healthy"] = `{"DestinationConfig":{"OnFailure":{"Destination":"arn:aws:sqs:us-east-1:123456789012:dlq"}}}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "audit_lambda_config",
+		map[string]interface{}{"query": "is checkout or healthy misconfigured"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"checkout (python3.8, 256 MB, 10s timeout)",
+		"5 invocations, p99 9.50s, peak memory 250 MB",
+		"Runtime python3.8 reached end of life",
+		"95% of the 10s timeout",
+		"Memory is under-provisioned",
+		"No dead-letter queue",
+		"✅ No findings: healthy",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "other") {
+		t.Errorf("functions not named in the query should be skipped:\n%s", out)
+	}
+}
+
+func TestAnalyzeLambdaErrorsResolvesFunctionFromQuery(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["lambda list-functions"] = `{"Functions":[{"FunctionName":"checkout"}]}`
+	f.fixtures["logs filter-log-events --log-group-name /aws/lambda/checkout"] = "[]"
+	f.fixtures["lambda get-function-configuration"] = "{}"
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_lambda_errors", map[string]interface{}{"query": "checkout keeps failing"}, &AIProfile{})
+	if err != nil || !strings.Contains(out, "ERROR ANALYSIS FOR checkout") {
+		t.Fatalf("out=%q err=%v, want checkout analysis", out, err)
+	}
+	out, err = c.executeAWSOperation(context.Background(), "analyze_lambda_errors", map[string]interface{}{"query": "lambda costs"}, &AIProfile{})
+	if err != nil || !strings.Contains(out, "No Lambda function named") {
+		t.Fatalf("out=%q err=%v, want a note that no function was named", out, err)
+	}
+}
@@ -0,0 +1,289 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// lambdaMemoryHighPercent: peak usage above this share of the memory
+	// size risks out-of-memory kills and usually means CPU starvation too.
+	lambdaMemoryHighPercent = 90.0
+	// lambdaMemoryLowPercent: peak usage below this share pays for memory
+	// the function never touches.
+	lambdaMemoryLowPercent = 40.0
+	// lambdaMemoryHeadroom is the margin above peak usage a suggested size
+	// keeps.
+	lambdaMemoryHeadroom = 1.3
+	// lambdaColdStartHighPercent flags cold starts frequent enough to be
+	// worth provisioned concurrency or a smaller package.
+	lambdaColdStartHighPercent = 10.0
+	lambdaMinMemoryMB          = 128
+	lambdaMaxMemoryMB          = 10240
+)
+
+// lambdaReport is one parsed REPORT line.
+type lambdaReport struct {
+	Duration       float64 // ms
+	BilledDuration float64 // ms
+	MemorySize     int     // MB
+	MaxMemoryUsed  int     // MB
+	InitDuration   float64 // ms, set on cold starts only
+}
+
+func (r lambdaReport) coldStart() bool {
+	return r.InitDuration > 0
+}
+
+var lambdaReportFields = map[string]*regexp.Regexp{
+	"Duration":        regexp.MustCompile(`RequestId: \S+\s+Duration: ([\d.]+) ms`),
+	"Billed Duration": regexp.MustCompile(`Billed Duration: ([\d.]+) ms`),
+	"Memory Size":     regexp.MustCompile(`Memory Size: (\d+) MB`),
+	"Max Memory Used": regexp.MustCompile(`Max Memory Used: (\d+) MB`),
+	"Init Duration":   regexp.MustCompile(`Init Duration: ([\d.]+) ms`),
+}
+
+// parseLambdaReport parses a REPORT log line:
+//
+//	REPORT RequestId: … Duration: 102.25 ms Billed Duration: 103 ms Memory Size: 512 MB Max Memory Used: 87 MB Init Duration: 310.42 ms
+func parseLambdaReport(message string) (lambdaReport, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(message), "REPORT") {
+		return lambdaReport{}, false
+	}
+	field := func(name string) (string, bool) {
+		m := lambdaReportFields[name].FindStringSubmatch(message)
+		if m == nil {
+			return "", false
+		}
+		return m[1], true
+	}
+
+	var r lambdaReport
+	duration, ok := field("Duration")
+	if !ok {
+		return r, false
+	}
+	r.Duration, _ = strconv.ParseFloat(duration, 64)
+	if v, ok := field("Billed Duration"); ok {
+		r.BilledDuration, _ = strconv.ParseFloat(v, 64)
+	}
+	if v, ok := field("Memory Size"); ok {
+		r.MemorySize, _ = strconv.Atoi(v)
+	}
+	if v, ok := field("Max Memory Used"); ok {
+		r.MaxMemoryUsed, _ = strconv.Atoi(v)
+	}
+	if v, ok := field("Init Duration"); ok {
+		r.InitDuration, _ = strconv.ParseFloat(v, 64)
+	}
+	return r, true
+}
+
+// lambdaPerformance summarizes the REPORT lines of one function.
+type lambdaPerformance struct {
+	Invocations   int
+	P50, P95, P99 float64
+	MaxDuration   float64
+	BilledP50     float64
+	ColdStarts    int
+	InitP50       float64
+	InitMax       float64
+	MemorySize    int // the latest invocation's, in case it changed in the window
+	PeakMemory    int
+	AverageMemory float64
+}
+
+func (p lambdaPerformance) coldStartPercent() float64 {
+	if p.Invocations == 0 {
+		return 0
+	}
+	return float64(p.ColdStarts) / float64(p.Invocations) * 100
+}
+
+func (p lambdaPerformance) memoryPercent() float64 {
+	if p.MemorySize == 0 {
+		return 0
+	}
+	return float64(p.PeakMemory) / float64(p.MemorySize) * 100
+}
+
+// summarizeLambdaReports computes duration percentiles, cold-start frequency
+// and memory usage. reports are in log order, oldest first.
+func summarizeLambdaReports(reports []lambdaReport) lambdaPerformance {
+	p := lambdaPerformance{Invocations: len(reports)}
+	if len(reports) == 0 {
+		return p
+	}
+	var durations, billed, inits []float64
+	memoryTotal := 0
+	for _, r := range reports {
+		durations = append(durations, r.Duration)
+		billed = append(billed, r.BilledDuration)
+		if r.coldStart() {
+			p.ColdStarts++
+			inits = append(inits, r.InitDuration)
+		}
+		if r.MaxMemoryUsed > p.PeakMemory {
+			p.PeakMemory = r.MaxMemoryUsed
+		}
+		memoryTotal += r.MaxMemoryUsed
+		if r.MemorySize > 0 {
+			p.MemorySize = r.MemorySize
+		}
+	}
+	sort.Float64s(durations)
+	sort.Float64s(billed)
+	sort.Float64s(inits)
+	p.P50 = nearestRankPercentile(durations, 50)
+	p.P95 = nearestRankPercentile(durations, 95)
+	p.P99 = nearestRankPercentile(durations, 99)
+	p.MaxDuration = durations[len(durations)-1]
+	p.BilledP50 = nearestRankPercentile(billed, 50)
+	if len(inits) > 0 {
+		p.InitP50 = nearestRankPercentile(inits, 50)
+		p.InitMax = inits[len(inits)-1]
+	}
+	p.AverageMemory = float64(memoryTotal) / float64(len(reports))
+	return p
+}
+
+// nearestRankPercentile returns the pth percentile of sorted values.
+func nearestRankPercentile(sorted []float64, pct float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(pct / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// suggestedLambdaMemory is peak usage plus headroom, rounded up to 64 MB and
+// kept within Lambda's limits.
+func suggestedLambdaMemory(peak int) int {
+	size := int(math.Ceil(float64(peak)*lambdaMemoryHeadroom/64)) * 64
+	if size < lambdaMinMemoryMB {
+		size = lambdaMinMemoryMB
+	}
+	if size > lambdaMaxMemoryMB {
+		size = lambdaMaxMemoryMB
+	}
+	return size
+}
+
+// lambdaVerdict turns the summary into tuning recommendations.
+func lambdaVerdict(p lambdaPerformance) []string {
+	var verdict []string
+	switch pct := p.memoryPercent(); {
+	case p.MemorySize == 0:
+	case pct >= lambdaMemoryHighPercent:
+		verdict = append(verdict, fmt.Sprintf("⚠️  Memory is under-provisioned: peak %d MB of %d MB (%.0f%%). Raise it to %d MB to avoid out-of-memory kills; Lambda also adds CPU with memory, which can cut duration.", p.PeakMemory, p.MemorySize, pct, suggestedLambdaMemory(p.PeakMemory)))
+	case pct < lambdaMemoryLowPercent && p.MemorySize > lambdaMinMemoryMB:
+		suggested := suggestedLambdaMemory(p.PeakMemory)
+		if suggested < p.MemorySize {
+			verdict = append(verdict, fmt.Sprintf("💡 Memory is over-provisioned: peak %d MB of %d MB (%.0f%%). Try %d MB to cut cost, and keep the current size if p95 duration rises, since CPU scales with memory.", p.PeakMemory, p.MemorySize, pct, suggested))
+		}
+	}
+	if pct := p.coldStartPercent(); pct >= lambdaColdStartHighPercent {
+		verdict = append(verdict, fmt.Sprintf("⚠️  %.0f%% of invocations were cold starts (init p50 %s, max %s). Consider provisioned concurrency or SnapStart, a smaller deployment package, or lazy initialization.", pct, formatLambdaMillis(p.InitP50), formatLambdaMillis(p.InitMax)))
+	}
+	if p.P50 > 0 && p.P99 >= 5*p.P50 && p.P99-p.P50 >= 1000 {
+		verdict = append(verdict, fmt.Sprintf("⚠️  Long tail: p99 %s is %.0fx p50 %s. Look for slow downstream calls, retries or cold starts in the slowest invocations.", formatLambdaMillis(p.P99), p.P99/p.P50, formatLambdaMillis(p.P50)))
+	}
+	if len(verdict) == 0 {
+		verdict = append(verdict, "✅ Memory size fits peak usage, cold starts are rare and duration has no long tail.")
+	}
+	return verdict
+}
+
+func formatLambdaPerformance(functionName string, p lambdaPerformance, window string) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("📊 PERFORMANCE ANALYSIS FOR %s\n", functionName))
+	out.WriteString("=====================================\n\n")
+	if p.Invocations == 0 {
+		out.WriteString(fmt.Sprintf("No REPORT lines %s; the function was not invoked or its logs are not in /aws/lambda/%s.\n", window, functionName))
+		return out.String()
+	}
+	out.WriteString(fmt.Sprintf("⏱️ %d invocations %s\n", p.Invocations, window))
+	out.WriteString(fmt.Sprintf("Duration: p50 %s, p95 %s, p99 %s, max %s (billed p50 %s)\n",
+		formatLambdaMillis(p.P50), formatLambdaMillis(p.P95), formatLambdaMillis(p.P99), formatLambdaMillis(p.MaxDuration), formatLambdaMillis(p.BilledP50)))
+	cold := fmt.Sprintf("Cold starts: %d of %d (%.1f%%)", p.ColdStarts, p.Invocations, p.coldStartPercent())
+	if p.ColdStarts > 0 {
+		cold += fmt.Sprintf(", init p50 %s, max %s", formatLambdaMillis(p.InitP50), formatLambdaMillis(p.InitMax))
+	}
+	out.WriteString(cold + "\n")
+	if p.MemorySize > 0 {
+		out.WriteString(fmt.Sprintf("Memory: %d MB allocated, peak %d MB used (%.0f%%), average %.0f MB\n", p.MemorySize, p.PeakMemory, p.memoryPercent(), p.AverageMemory))
+	}
+	out.WriteString("\nVerdict:\n")
+	for _, line := range lambdaVerdict(p) {
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
+
+func formatLambdaMillis(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+	return fmt.Sprintf("%.0fms", ms)
+}
+
+// analyzeLambdaPerformance is the analyze_lambda_performance operation: it
+// parses the function's REPORT lines over the window (the last day by
+// default) into a performance verdict.
+func (c *Client) analyzeLambdaPerformance(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	functionName := getStringParam(input, "function_name", "")
+	if functionName == "" {
+		return "", fmt.Errorf("function_name parameter required")
+	}
+
+	start, end := operationWindow(ctx, input, 24*time.Hour)
+	reports, err := c.lambdaReports(ctx, functionName, start, end, nil, profile)
+	if err != nil {
+		return fmt.Sprintf("❌ Failed to get performance logs for %s: %v", functionName, err), nil
+	}
+	return formatLambdaPerformance(functionName, summarizeLambdaReports(reports), windowPhrase(start, end)), nil
+}
+
+// lambdaReports returns the function's parsed REPORT lines between start
+// and end, oldest first. extra is appended to the filter-log-events call.
+func (c *Client) lambdaReports(ctx context.Context, functionName string, start, end time.Time, extra []string, profile *AIProfile) ([]lambdaReport, error) {
+	args := append([]string{"logs", "filter-log-events", "--log-group-name", "/aws/lambda/" + functionName}, logTimeArgs(start, end)...)
+	args = append(args,
+		"--filter-pattern", "[REPORT]",
+		"--output", "json",
+		"--query", "events[*].{Timestamp:timestamp,Message:message}",
+	)
+	args = append(args, extra...)
+	result, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []struct {
+		Timestamp int64  `json:"Timestamp"`
+		Message   string `json:"Message"`
+	}
+	if strings.TrimSpace(result) != "" && strings.TrimSpace(result) != "null" {
+		if err := json.Unmarshal([]byte(result), &events); err != nil {
+			return nil, fmt.Errorf("failed to parse REPORT lines for %s: %w", functionName, err)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp < events[j].Timestamp })
+	var reports []lambdaReport
+	for _, e := range events {
+		if r, ok := parseLambdaReport(e.Message); ok {
+			reports = append(reports, r)
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseLambdaReport(t *testing.T) {
+	line := "REPORT RequestId: 3f5d2a1e-1111-2222-3333-444455556666\tDuration: 102.25 ms\tBilled Duration: 103 ms\tMemory Size: 512 MB\tMax Memory Used: 87 MB\tInit Duration: 310.42 ms\t\n"
+	r, ok := parseLambdaReport(line)
+	if !ok {
+		t.Fatal("expected REPORT line to parse")
+	}
+	want := lambdaReport{Duration: 102.25, BilledDuration: 103, MemorySize: 512, MaxMemoryUsed: 87, InitDuration: 310.42}
+	if r != want {
+		t.Fatalf("parseLambdaReport() = %+v, want %+v", r, want)
+	}
+	if _, ok := parseLambdaReport("START RequestId: abc Version: $LATEST"); ok {
+		t.Error("START line should not parse")
+	}
+}
+
+func TestSummarizeLambdaReports(t *testing.T) {
+	var reports []lambdaReport
+	for i := 1; i <= 100; i++ {
+		r := lambdaReport{Duration: float64(i * 10), BilledDuration: float64(i * 10), MemorySize: 1024, MaxMemoryUsed: 200}
+		if i%20 == 0 {
+			r.InitDuration = 400
+		}
+		reports = append(reports, r)
+	}
+	p := summarizeLambdaReports(reports)
+	if p.P50 != 500 || p.P95 != 950 || p.P99 != 990 || p.MaxDuration != 1000 {
+		t.Errorf("unexpected percentiles: %+v", p)
+	}
+	if p.ColdStarts != 5 || p.InitP50 != 400 {
+		t.Errorf("unexpected cold starts: %+v", p)
+	}
+	verdict := strings.Join(lambdaVerdict(p), "\n")
+	if !strings.Contains(verdict, "over-provisioned: peak 200 MB of 1024 MB (20%). Try 320 MB") {
+		t.Errorf("expected an over-provisioned verdict, got %s", verdict)
+	}
+}
+
+func TestAnalyzeLambdaPerformanceFlagsMemoryPressure(t *testing.T) {
+	type event struct {
+		Timestamp int64  `json:"Timestamp"`
+		Message   string `json:"Message"`
+	}
+	var events []event
+	for i := 0; i < 10; i++ {
+		msg := fmt.Sprintf("REPORT RequestId: req-%d\tDuration: %d.00 ms\tBilled Duration: %d ms\tMemory Size: 256 MB\tMax Memory Used: 250 MB\t", i, 100+i, 100+i)
+		if i < 3 {
+			msg += "Init Duration: 800.00 ms\t"
+		}
+		events = append(events, event{Timestamp: int64(1714564800000 + i), Message: msg})
+	}
+	raw, _ := json.Marshal(events)
+	f := newFakeCLI()
+	f.fixtures["logs filter-log-events --log-group-name /aws/lambda/checkout"] = string(raw)
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "analyze_lambda_performance", map[string]interface{}{"function_name": "checkout"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"10 invocations in the last 1d",
+		"Cold starts: 3 of 10 (30.0%), init p50 800ms, max 800ms",
+		"Memory is under-provisioned: peak 250 MB of 256 MB (98%). Raise it to 384 MB",
+		"30% of invocations were cold starts",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
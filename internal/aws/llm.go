@@ -2,11 +2,15 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bgdnvk/clanker/internal/logging"
 	tfclient "github.com/bgdnvk/clanker/internal/terraform"
 	"github.com/spf13/viper"
 )
@@ -40,6 +44,34 @@ type AIProfile struct {
 	Region                 string `mapstructure:"region"`
 	APIKeyEnv              string `mapstructure:"api_key_env"`
 	LocalModelInferenceURL string `mapstructure:"local_model_inference_url"`
+	BaseURL                string `mapstructure:"base_url"`       // self-hosted or gateway endpoint, e.g. ollama, openrouter
+	DecisionModel          string `mapstructure:"decision_model"` // cheap model for routing decisions; defaults to Model
+	AnalysisModel          string `mapstructure:"analysis_model"` // strong model for deep analysis; defaults to Model
+	Partition              string `mapstructure:"partition"`      // aws, aws-us-gov or aws-cn; derived from Region when empty
+
+	regionPinned  bool // set by profileForRegion; Region beats env and flag overrides
+	profilePinned bool // set by profileForEnvironment; AWSProfile beats env and flag overrides
+}
+
+// Model roles select which of a profile's models serves a call.
+const (
+	ModelRoleDecision = "decision"
+	ModelRoleAnalysis = "analysis"
+)
+
+// ModelFor returns the model configured for role, falling back to Model.
+func (p *AIProfile) ModelFor(role string) string {
+	var model string
+	switch role {
+	case ModelRoleDecision:
+		model = p.DecisionModel
+	case ModelRoleAnalysis:
+		model = p.AnalysisModel
+	}
+	if strings.TrimSpace(model) == "" {
+		return p.Model
+	}
+	return model
 }
 
 // GetAIProfile returns the AI configuration for the given provider name
@@ -85,6 +117,19 @@ func GetAIProfile(providerName string) (*AIProfile, error) {
 				Model:     "MiniMax-M2.5",
 				APIKeyEnv: "MINIMAX_API_KEY",
 			}, nil
+		case "ollama":
+			// Local models need no key; the base URL defaults to localhost.
+			return &AIProfile{
+				Provider: "ollama",
+				Model:    "llama3.1",
+			}, nil
+		case "openrouter":
+			return &AIProfile{
+				Provider:  "openrouter",
+				Model:     "anthropic/claude-3.5-sonnet",
+				APIKeyEnv: "OPENROUTER_API_KEY",
+				BaseURL:   "https://openrouter.ai/api/v1",
+			}, nil
 		}
 		return nil, fmt.Errorf("AI provider '%s' not found in configuration", providerName)
 	}
@@ -96,16 +141,41 @@ func GetAIProfile(providerName string) (*AIProfile, error) {
 
 	// Set the provider name
 	profile.Provider = providerName
+	profile.DecisionModel = profile.ModelFor(ModelRoleDecision)
+	profile.AnalysisModel = profile.ModelFor(ModelRoleAnalysis)
 
 	return &profile, nil
 }
 
 // executeAWSOperation executes a specific AWS operation with the given parameters
 func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input map[string]interface{}, profile *AIProfile) (string, error) {
-	verbose := viper.GetBool("debug")
+	logging.Verbosef("🔍 %s: Starting AWS operation with profile: %s, region: %s\n", toolName, profile.AWSProfile, profile.Region)
 
-	if verbose {
-		fmt.Printf("🔍 %s: Starting AWS operation with profile: %s, region: %s\n", toolName, profile.AWSProfile, profile.Region)
+	// Fail with installation instructions up front rather than an exec
+	// error from the first CLI call.
+	if c.execFunc == nil && !c.dryRun {
+		if err := ensureAWSCLI(); err != nil {
+			return "", err
+		}
+	}
+
+	// Service checks for services missing from the partition (GovCloud,
+	// China) report that directly instead of a generic failure.
+	if service, ok := strings.CutPrefix(toolName, "check_"); ok {
+		service = strings.TrimSuffix(service, "_service")
+		resolved := resolvedProfile(profile)
+		if partition := resolved.EffectivePartition(); !ServiceAvailableInPartition(service, partition) {
+			return fmt.Sprintf("ℹ️  %s is not offered in the %s partition (region %s)", service, partition, resolved.Region), nil
+		}
+	}
+
+	// Mutating operations are gated separately; everything below is read-only.
+	if IsMutatingOperation(toolName) {
+		return c.executeMutation(ctx, toolName, input, profile)
+	}
+
+	if fn, ok := registeredOperations().Lookup(toolName); ok {
+		return fn(c, ctx, input, profile)
 	}
 
 	// All operations are read-only and safe - no modifications or deletions possible
@@ -113,39 +183,25 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 	// SERVICE EXISTENCE CHECKS - Quick checks to see if services exist/are configured
 	case "check_sqs_service":
 		args := []string{"sqs", "list-queues", "--max-items", "1", "--output", "table"}
-		if verbose {
-			fmt.Printf("🔍 %s: Checking service availability with: aws %s\n", toolName, strings.Join(args, " "))
-		}
+		logging.Debugf("🔍 %s: Checking service availability with: aws %s\n", toolName, strings.Join(args, " "))
 		_, err := c.execAWSCLI(ctx, args, profile)
 		if err != nil {
-			if verbose {
-				fmt.Printf("❌ %s: Service check failed: %v\n", toolName, err)
-			}
+			logging.Verbosef("❌ %s: Service check failed: %v\n", toolName, err)
 			return "❌ SQS service not available or no access", nil
 		}
-		if verbose {
-			fmt.Printf("✅ %s: Service is available, getting count...\n", toolName)
-		}
+		logging.Debugf("✅ %s: Service is available, getting count...\n", toolName)
 		queueCountArgs := []string{"sqs", "list-queues", "--output", "json", "--query", "length(QueueUrls)"}
-		if verbose {
-			fmt.Printf("🔍 %s: Getting count with: aws %s\n", toolName, strings.Join(queueCountArgs, " "))
-		}
+		logging.Debugf("🔍 %s: Getting count with: aws %s\n", toolName, strings.Join(queueCountArgs, " "))
 		countResult, _ := c.execAWSCLI(ctx, queueCountArgs, profile)
-		if verbose {
-			fmt.Printf("📊 %s: Raw count result: '%s'\n", toolName, countResult)
-		}
+		logging.Debugf("📊 %s: Raw count result: '%s'\n", toolName, countResult)
 		return fmt.Sprintf("✅ SQS service is available. Queue count: %s", strings.TrimSpace(countResult)), nil
 
 	case "check_eventbridge_service":
 		args := []string{"events", "list-event-buses", "--limit", "1", "--output", "table"}
-		if verbose {
-			fmt.Printf("🔍 %s: Checking service availability with: aws %s\n", toolName, strings.Join(args, " "))
-		}
+		logging.Debugf("🔍 %s: Checking service availability with: aws %s\n", toolName, strings.Join(args, " "))
 		_, err := c.execAWSCLI(ctx, args, profile)
 		if err != nil {
-			if verbose {
-				fmt.Printf("❌ %s: Service check failed: %v\n", toolName, err)
-			}
+			logging.Verbosef("❌ %s: Service check failed: %v\n", toolName, err)
 			return "❌ EventBridge service not available or no access", nil
 		}
 		// Count rules on default bus
@@ -274,21 +330,21 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 
 	case "check_ecr_service":
 		args := []string{"ecr", "describe-repositories", "--max-items", "1", "--output", "table"}
-		fmt.Printf("🔍 ECR: Checking service availability with: aws %s\n", strings.Join(args, " "))
+		logging.Debugf("🔍 ECR: Checking service availability with: aws %s\n", strings.Join(args, " "))
 		_, err := c.execAWSCLI(ctx, args, profile)
 		if err != nil {
-			fmt.Printf("❌ ECR: Service check failed: %v\n", err)
+			logging.Verbosef("❌ ECR: Service check failed: %v\n", err)
 			return "❌ ECR service not available or no access", nil
 		}
-		fmt.Printf("✅ ECR: Service is available, getting count...\n")
+		logging.Debugf("✅ ECR: Service is available, getting count...\n")
 		countArgs := []string{"ecr", "describe-repositories", "--output", "json", "--query", "length(repositories)"}
-		fmt.Printf("🔍 ECR: Getting count with: aws %s\n", strings.Join(countArgs, " "))
+		logging.Debugf("🔍 ECR: Getting count with: aws %s\n", strings.Join(countArgs, " "))
 		countResult, err := c.execAWSCLI(ctx, countArgs, profile)
 		if err != nil {
-			fmt.Printf("❌ ECR: Count query failed: %v\n", err)
+			logging.Verbosef("❌ ECR: Count query failed: %v\n", err)
 			return "❌ ECR count query failed", nil
 		}
-		fmt.Printf("📊 ECR: Raw count result: '%s'\n", countResult)
+		logging.Debugf("📊 ECR: Raw count result: '%s'\n", countResult)
 		return fmt.Sprintf("✅ ECR service is available. Repository count: %s", strings.TrimSpace(countResult)), nil
 
 	// ADDITIONAL AWS SERVICES
@@ -1831,8 +1887,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_instance":
-		instanceID, ok := input["instance_id"].(string)
-		if !ok {
+		instanceID := getStringParam(input, "instance_id", "")
+		if instanceID == "" {
 			return "", fmt.Errorf("instance_id parameter required")
 		}
 		args := []string{"ec2", "describe-instances", "--instance-ids", instanceID, "--output", "json"}
@@ -1864,14 +1920,11 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return fmt.Sprintf("AWS Batch Job Queues:\n%s\n\nAWS Batch Compute Environments:\n%s", queues, environments), nil
 
 	case "analyze_ecs_service_logs":
-		serviceName, ok := input["service_name"].(string)
-		if !ok {
+		serviceName := getStringParam(input, "service_name", "")
+		if serviceName == "" {
 			return "", fmt.Errorf("service_name parameter required")
 		}
-		clusterName, _ := input["cluster_name"].(string) // optional; absent → nil interface
-		if clusterName == "" {
-			clusterName = "default"
-		}
+		clusterName := getStringParam(input, "cluster_name", "default")
 
 		// ECS services typically log to /ecs/service-name or /aws/ecs/service-name
 		logGroups := []string{
@@ -1880,22 +1933,19 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 			fmt.Sprintf("/aws/ecs/containerinsights/%s/performance", clusterName),
 		}
 
-		analysis := fmt.Sprintf("🔍 ECS SERVICE LOG ANALYSIS FOR %s\n", serviceName)
+		start, end := operationWindow(ctx, input, 6*time.Hour)
+		analysis := fmt.Sprintf("🔍 ECS SERVICE LOG ANALYSIS FOR %s (%s)\n", serviceName, describeWindow(start, end))
 		analysis += "=========================================\n\n"
 
 		for _, logGroup := range logGroups {
-			args := []string{
-				"logs", "filter-log-events",
-				"--log-group-name", logGroup,
-				"--start-time", fmt.Sprintf("%d", time.Now().Add(-6*time.Hour).Unix()*1000),
+			args := append([]string{"logs", "filter-log-events", "--log-group-name", logGroup}, logTimeArgs(start, end)...)
+			args = append(args,
 				"--filter-pattern", "ERROR",
 				"--output", "json",
 				"--query", "events[*].{Timestamp:timestamp,Message:message}",
-			}
+			)
 
-			if verbose {
-				fmt.Printf("🔍 %s: Checking log group %s for service %s\n", toolName, logGroup, serviceName)
-			}
+			logging.Verbosef("🔍 %s: Checking log group %s for service %s\n", toolName, logGroup, serviceName)
 
 			result, err := c.execAWSCLI(ctx, args, profile)
 			if err == nil && result != "[]" {
@@ -1907,14 +1957,11 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return analysis, nil
 
 	case "get_ecs_task_logs":
-		taskArn, ok := input["task_arn"].(string)
-		if !ok {
+		taskArn := getStringParam(input, "task_arn", "")
+		if taskArn == "" {
 			return "", fmt.Errorf("task_arn parameter required")
 		}
-		clusterName, _ := input["cluster_name"].(string) // optional; absent → nil interface
-		if clusterName == "" {
-			clusterName = "default"
-		}
+		clusterName := getStringParam(input, "cluster_name", "default")
 
 		// Get task definition to find log configuration
 		taskArgs := []string{
@@ -1924,9 +1971,7 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 			"--output", "json",
 		}
 
-		if verbose {
-			fmt.Printf("🔍 %s: Getting task details for %s\n", toolName, taskArn)
-		}
+		logging.Verbosef("🔍 %s: Getting task details for %s\n", toolName, taskArn)
 
 		taskResult, err := c.execAWSCLI(ctx, taskArgs, profile)
 		if err != nil {
@@ -1950,33 +1995,43 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_lambda_function":
-		functionName, ok := input["function_name"].(string)
-		if !ok {
+		functionName := getStringParam(input, "function_name", "")
+		if functionName == "" {
 			return "", fmt.Errorf("function_name parameter required")
 		}
 		args := []string{"lambda", "get-function", "--function-name", functionName, "--output", "json"}
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "analyze_lambda_errors":
-		functionName, ok := input["function_name"].(string)
-		if !ok {
-			return "", fmt.Errorf("function_name parameter required")
+		functionName := getStringParam(input, "function_name", "")
+		if functionName == "" {
+			query := getStringParam(input, "query", "")
+			if query == "" {
+				return "", fmt.Errorf("function_name parameter required")
+			}
+			// Fall back to the first function the query names.
+			all, err := c.listLambdaConfigs(ctx, profile)
+			if err != nil {
+				return categorizeAWSError(err, "Lambda"), nil
+			}
+			named := lambdaFunctionsNamedIn(all, query)
+			if len(named) == 0 {
+				return "No Lambda function named in the query; pass function_name to analyze its errors", nil
+			}
+			functionName = named[0].FunctionName
 		}
 		logGroupName := fmt.Sprintf("/aws/lambda/%s", functionName)
 
-		// Get recent error logs from the last 24 hours
-		args := []string{
-			"logs", "filter-log-events",
-			"--log-group-name", logGroupName,
-			"--start-time", fmt.Sprintf("%d", time.Now().Add(-24*time.Hour).Unix()*1000),
+		// Error logs from the investigation window, the last 24 hours by default
+		start, end := operationWindow(ctx, input, 24*time.Hour)
+		args := append([]string{"logs", "filter-log-events", "--log-group-name", logGroupName}, logTimeArgs(start, end)...)
+		args = append(args,
 			"--filter-pattern", "ERROR",
 			"--output", "json",
 			"--query", "events[*].{Timestamp:timestamp,Message:message}",
-		}
+		)
 
-		if verbose {
-			fmt.Printf("🔍 %s: Analyzing errors for %s in log group %s\n", toolName, functionName, logGroupName)
-		}
+		logging.Verbosef("🔍 %s: Analyzing errors for %s in log group %s\n", toolName, functionName, logGroupName)
 
 		result, err := c.execAWSCLI(ctx, args, profile)
 		if err != nil {
@@ -1995,63 +2050,27 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 			analysis += configResult + "\n\n"
 		}
 
-		analysis += "🚨 ERROR LOGS (Last 24 hours):\n"
-		analysis += result + "\n"
-
-		return analysis, nil
-
-	case "analyze_lambda_performance":
-		functionName, ok := input["function_name"].(string)
-		if !ok {
-			return "", fmt.Errorf("function_name parameter required")
-		}
-		logGroupName := fmt.Sprintf("/aws/lambda/%s", functionName)
-
-		// Get recent logs with duration and memory usage patterns
-		args := []string{
-			"logs", "filter-log-events",
-			"--log-group-name", logGroupName,
-			"--start-time", fmt.Sprintf("%d", time.Now().Add(-24*time.Hour).Unix()*1000),
-			"--filter-pattern", "[REPORT]",
-			"--output", "json",
-			"--query", "events[*].{Timestamp:timestamp,Message:message}",
-		}
-
-		if verbose {
-			fmt.Printf("🔍 %s: Analyzing performance for %s\n", toolName, functionName)
-		}
-
-		result, err := c.execAWSCLI(ctx, args, profile)
-		if err != nil {
-			return fmt.Sprintf("❌ Failed to get performance logs for %s: %v", functionName, err), nil
-		}
-
-		analysis := fmt.Sprintf("📊 PERFORMANCE ANALYSIS FOR %s\n", functionName)
-		analysis += "=====================================\n\n"
-		analysis += "⏱️ EXECUTION REPORTS (Last 24 hours):\n"
+		analysis += fmt.Sprintf("🚨 ERROR LOGS (%s):\n", describeWindow(start, end))
 		analysis += result + "\n"
 
 		return analysis, nil
 
 	case "get_lambda_recent_logs":
-		functionName, ok := input["function_name"].(string)
-		if !ok {
+		functionName := getStringParam(input, "function_name", "")
+		if functionName == "" {
 			return "", fmt.Errorf("function_name parameter required")
 		}
 		logGroupName := fmt.Sprintf("/aws/lambda/%s", functionName)
 
-		// Get all recent logs (last 6 hours)
-		args := []string{
-			"logs", "filter-log-events",
-			"--log-group-name", logGroupName,
-			"--start-time", fmt.Sprintf("%d", time.Now().Add(-6*time.Hour).Unix()*1000),
+		// Get all recent logs (last 6 hours by default)
+		start, end := operationWindow(ctx, input, 6*time.Hour)
+		args := append([]string{"logs", "filter-log-events", "--log-group-name", logGroupName}, logTimeArgs(start, end)...)
+		args = append(args,
 			"--output", "json",
 			"--query", "events[*].{Timestamp:timestamp,Message:message}",
-		}
+		)
 
-		if verbose {
-			fmt.Printf("🔍 %s: Getting recent logs for %s\n", toolName, functionName)
-		}
+		logging.Verbosef("🔍 %s: Getting recent logs for %s\n", toolName, functionName)
 
 		result, err := c.execAWSCLI(ctx, args, profile)
 		if err != nil {
@@ -2060,7 +2079,7 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 
 		analysis := fmt.Sprintf("📝 RECENT LOGS FOR %s\n", functionName)
 		analysis += "============================\n\n"
-		analysis += "🕐 LOGS (Last 6 hours):\n"
+		analysis += fmt.Sprintf("🕐 LOGS (%s):\n", describeWindow(start, end))
 		analysis += result + "\n"
 
 		return analysis, nil
@@ -2071,8 +2090,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_s3_bucket":
-		bucketName, ok := input["bucket_name"].(string)
-		if !ok {
+		bucketName := getStringParam(input, "bucket_name", "")
+		if bucketName == "" {
 			return "", fmt.Errorf("bucket_name parameter required")
 		}
 		args := []string{"s3api", "head-bucket", "--bucket", bucketName}
@@ -2084,8 +2103,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_rds_instance":
-		instanceID, ok := input["instance_id"].(string)
-		if !ok {
+		instanceID := getStringParam(input, "instance_id", "")
+		if instanceID == "" {
 			return "", fmt.Errorf("instance_id parameter required")
 		}
 		args := []string{"rds", "describe-db-instances", "--db-instance-identifier", instanceID, "--output", "json"}
@@ -2096,8 +2115,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_dynamodb_table":
-		tableName, ok := input["table_name"].(string)
-		if !ok {
+		tableName := getStringParam(input, "table_name", "")
+		if tableName == "" {
 			return "", fmt.Errorf("table_name parameter required")
 		}
 		args := []string{"dynamodb", "describe-table", "--table-name", tableName, "--output", "json"}
@@ -2138,63 +2157,33 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 	case "get_recent_logs":
 		// If specific log group parameters are provided, fetch targeted logs
 		if input != nil {
-			if lg, ok := input["log_group_name"].(string); ok && lg != "" {
+			if lg := getStringParam(input, "log_group_name", ""); lg != "" {
 				// Defaults
-				hoursBack := 24
-				if hb, ok := input["hours_back"]; ok {
-					switch v := hb.(type) {
-					case int:
-						hoursBack = v
-					case int32:
-						hoursBack = int(v)
-					case int64:
-						hoursBack = int(v)
-					case float64:
-						hoursBack = int(v)
-					}
-				}
+				start, end := operationWindow(ctx, input, 24*time.Hour)
 				limit := 200
-				if lim, ok := input["limit"]; ok {
-					switch v := lim.(type) {
-					case int:
-						limit = v
-					case int32:
-						limit = int(v)
-					case int64:
-						limit = int(v)
-					case float64:
-						limit = int(v)
-					}
-				}
-				filterPattern := ""
-				if fp, ok := input["filter_pattern"].(string); ok {
-					filterPattern = fp
+				if lim, ok := intParam(input, "limit"); ok && lim > 0 {
+					limit = lim
 				}
+				filterPattern := getStringParam(input, "filter_pattern", "")
 
-				startMillis := fmt.Sprintf("%d", time.Now().Add(-time.Duration(hoursBack)*time.Hour).Unix()*1000)
-
-				args := []string{
-					"logs", "filter-log-events",
-					"--log-group-name", lg,
-					"--start-time", startMillis,
+				args := append([]string{"logs", "filter-log-events", "--log-group-name", lg}, logTimeArgs(start, end)...)
+				args = append(args,
 					"--output", "json",
 					"--query", "events[*].{Timestamp:timestamp,Message:message}",
 					"--limit", fmt.Sprintf("%d", limit),
-				}
+				)
 				if filterPattern != "" {
 					args = append(args, "--filter-pattern", filterPattern)
 				}
 
-				if verbose {
-					fmt.Printf("🔍 %s: Filtering logs for %s (last %dh, limit %d, pattern '%s')\n", toolName, lg, hoursBack, limit, filterPattern)
-				}
+				logging.Verbosef("🔍 %s: Filtering logs for %s (%s, limit %d, pattern '%s')\n", toolName, lg, describeWindow(start, end), limit, filterPattern)
 
 				result, err := c.execAWSCLI(ctx, args, profile)
 				if err != nil {
 					return fmt.Sprintf("❌ Failed to get logs for %s: %v", lg, err), nil
 				}
 
-				analysis := fmt.Sprintf("📝 RECENT LOGS FROM %s\n", lg)
+				analysis := fmt.Sprintf("📝 RECENT LOGS FROM %s (%s)\n", lg, describeWindow(start, end))
 				analysis += "===============================\n\n"
 				analysis += result + "\n"
 				return analysis, nil
@@ -2268,8 +2257,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return fmt.Sprintf("EKS Clusters:\n%s\n\nCluster Details:\n%s", clusters, details), nil
 
 	case "describe_ecr_repository":
-		repoName, ok := input["repository_name"].(string)
-		if !ok {
+		repoName := getStringParam(input, "repository_name", "")
+		if repoName == "" {
 			return "", fmt.Errorf("repository_name parameter required")
 		}
 		args := []string{"ecr", "describe-images", "--repository-name", repoName, "--output", "table"}
@@ -2281,8 +2270,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_sqs_queue":
-		queueURL, ok := input["queue_url"].(string)
-		if !ok {
+		queueURL := getStringParam(input, "queue_url", "")
+		if queueURL == "" {
 			return "", fmt.Errorf("queue_url parameter required")
 		}
 		args := []string{"sqs", "get-queue-attributes", "--queue-url", queueURL, "--attribute-names", "All", "--output", "json"}
@@ -2293,8 +2282,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_sns_topic":
-		topicArn, ok := input["topic_arn"].(string)
-		if !ok {
+		topicArn := getStringParam(input, "topic_arn", "")
+		if topicArn == "" {
 			return "", fmt.Errorf("topic_arn parameter required")
 		}
 		args := []string{"sns", "get-topic-attributes", "--topic-arn", topicArn, "--output", "json"}
@@ -2322,8 +2311,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_auto_scaling_group":
-		asgName, ok := input["asg_name"].(string)
-		if !ok {
+		asgName := getStringParam(input, "asg_name", "")
+		if asgName == "" {
 			return "", fmt.Errorf("asg_name parameter required")
 		}
 		args := []string{"autoscaling", "describe-auto-scaling-groups", "--auto-scaling-group-names", asgName, "--output", "json"}
@@ -2334,8 +2323,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_launch_template":
-		templateID, ok := input["template_id"].(string)
-		if !ok {
+		templateID := getStringParam(input, "template_id", "")
+		if templateID == "" {
 			return "", fmt.Errorf("template_id parameter required")
 		}
 		args := []string{"ec2", "describe-launch-template-versions", "--launch-template-id", templateID, "--output", "json"}
@@ -2347,8 +2336,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_ebs_volume":
-		volumeID, ok := input["volume_id"].(string)
-		if !ok {
+		volumeID := getStringParam(input, "volume_id", "")
+		if volumeID == "" {
 			return "", fmt.Errorf("volume_id parameter required")
 		}
 		args := []string{"ec2", "describe-volumes", "--volume-ids", volumeID, "--output", "json"}
@@ -2359,8 +2348,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_efs_filesystem":
-		fsID, ok := input["filesystem_id"].(string)
-		if !ok {
+		fsID := getStringParam(input, "filesystem_id", "")
+		if fsID == "" {
 			return "", fmt.Errorf("filesystem_id parameter required")
 		}
 		args := []string{"efs", "describe-file-systems", "--file-system-id", fsID, "--output", "json"}
@@ -2372,8 +2361,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_kms_key":
-		keyID, ok := input["key_id"].(string)
-		if !ok {
+		keyID := getStringParam(input, "key_id", "")
+		if keyID == "" {
 			return "", fmt.Errorf("key_id parameter required")
 		}
 		args := []string{"kms", "describe-key", "--key-id", keyID, "--output", "json"}
@@ -2384,8 +2373,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_acm_certificate":
-		certArn, ok := input["certificate_arn"].(string)
-		if !ok {
+		certArn := getStringParam(input, "certificate_arn", "")
+		if certArn == "" {
 			return "", fmt.Errorf("certificate_arn parameter required")
 		}
 		args := []string{"acm", "describe-certificate", "--certificate-arn", certArn, "--output", "json"}
@@ -2426,8 +2415,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_codepipeline":
-		pipelineName, ok := input["pipeline_name"].(string)
-		if !ok {
+		pipelineName := getStringParam(input, "pipeline_name", "")
+		if pipelineName == "" {
 			return "", fmt.Errorf("pipeline_name parameter required")
 		}
 		args := []string{"codepipeline", "get-pipeline", "--name", pipelineName, "--output", "json"}
@@ -2447,8 +2436,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_kinesis_stream":
-		streamName, ok := input["stream_name"].(string)
-		if !ok {
+		streamName := getStringParam(input, "stream_name", "")
+		if streamName == "" {
 			return "", fmt.Errorf("stream_name parameter required")
 		}
 		args := []string{"kinesis", "describe-stream", "--stream-name", streamName, "--output", "json"}
@@ -2489,8 +2478,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_elasticache_cluster":
-		clusterID, ok := input["cluster_id"].(string)
-		if !ok {
+		clusterID := getStringParam(input, "cluster_id", "")
+		if clusterID == "" {
 			return "", fmt.Errorf("cluster_id parameter required")
 		}
 		args := []string{"elasticache", "describe-cache-clusters", "--cache-cluster-id", clusterID, "--output", "json"}
@@ -2502,8 +2491,8 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 		return c.execAWSCLI(ctx, args, profile)
 
 	case "describe_step_function":
-		stateMachineArn, ok := input["state_machine_arn"].(string)
-		if !ok {
+		stateMachineArn := getStringParam(input, "state_machine_arn", "")
+		if stateMachineArn == "" {
 			return "", fmt.Errorf("state_machine_arn parameter required")
 		}
 		args := []string{"stepfunctions", "describe-state-machine", "--state-machine-arn", stateMachineArn, "--output", "json"}
@@ -2511,25 +2500,54 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 
 	// COST MANAGEMENT operations
 	case "get_cost_and_usage":
+		if strings.EqualFold(getStringParam(input, "group_by", ""), "SERVICE") {
+			return c.getCostGrouped(ctx, "DIMENSION", "SERVICE", profile)
+		}
 		// Get cost for last 30 days
 		args := []string{"ce", "get-cost-and-usage",
-			"--time-period", "Start=2024-07-01,End=2024-08-01",
+			"--time-period", costTimePeriod(time.Now(), 30),
 			"--granularity", "MONTHLY",
 			"--metrics", "BlendedCost",
 			"--output", "json"}
 		return c.execAWSCLI(ctx, args, profile)
 
+	case "detect_cost_anomaly":
+		return c.detectCostAnomaly(ctx, profile)
+
 	case "list_budgets":
 		args := []string{"budgets", "describe-budgets", "--output", "table"}
 		return c.execAWSCLI(ctx, args, profile)
 
 	// INFRASTRUCTURE DISCOVERY operations
 	case "discover_all_active_services":
+		regions := configuredRegions()
+		if raw, ok := input["regions"]; ok {
+			switch v := raw.(type) {
+			case string:
+				regions = normalizeRegions([]string{v})
+			case []string:
+				regions = normalizeRegions(v)
+			case []interface{}:
+				regions = regions[:0]
+				for _, r := range v {
+					if str, ok := r.(string); ok {
+						regions = append(regions, str)
+					}
+				}
+				regions = normalizeRegions(regions)
+			}
+		}
+		if len(regions) > 0 {
+			return c.discoverAllActiveServicesMultiRegion(ctx, profile, regions)
+		}
 		return c.discoverAllActiveServices(ctx, profile)
 
 	case "get_infrastructure_overview":
 		return c.getInfrastructureOverview(ctx, profile)
 
+	case "get_account_summary":
+		return c.BuildAccountSummary(ctx, profile)
+
 	case "check_all_services_parallel":
 		return c.checkAllServicesParallel(ctx, profile)
 
@@ -2548,6 +2566,9 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 	case "get_terraform_state_summary":
 		return c.getTerraformStateSummary(ctx, profile)
 
+	case "get_terraform_plan_diff":
+		return c.getTerraformPlanDiff(ctx, profile)
+
 	// AI/ML SERVICES operations
 	case "list_bedrock_foundation_models":
 		args := []string{"bedrock", "list-foundation-models", "--output", "table", "--query", "modelSummaries[*].{ModelId:modelId,Provider:providerName,Name:modelName,Status:modelLifecycle.status}"}
@@ -2595,244 +2616,572 @@ func (c *Client) executeAWSOperation(ctx context.Context, toolName string, input
 	}
 }
 
-// execAWSCLI executes AWS CLI commands directly
+// defaultAWSCommandTimeout bounds a single aws invocation when
+// aws.command_timeout is unset, so a subprocess stuck on a pager or prompt
+// is killed instead of hanging the run.
+const defaultAWSCommandTimeout = 5 * time.Minute
+
+// awsCLIWaitDelay is how long a killed aws process gets to release its
+// output pipes; a pager it spawned can otherwise hold them open.
+var awsCLIWaitDelay = time.Second
+
+// awsCommandTimeout returns the per-invocation timeout (aws.command_timeout).
+func awsCommandTimeout() time.Duration {
+	if d := viper.GetDuration("aws.command_timeout"); d > 0 {
+		return d
+	}
+	return defaultAWSCommandTimeout
+}
+
+// awsCLIBackoffs mirrors the retry schedule used by the GKE/EKS providers.
+var awsCLIBackoffs = []time.Duration{200 * time.Millisecond, 500 * time.Millisecond, 1200 * time.Millisecond}
+
+// awsCLIMaxRetries returns how many times a retryable AWS CLI failure is retried.
+// Configurable via aws.max_retries; defaults to one retry per backoff step.
+func awsCLIMaxRetries() int {
+	if !viper.IsSet("aws.max_retries") {
+		return len(awsCLIBackoffs)
+	}
+	retries := viper.GetInt("aws.max_retries")
+	if retries < 0 {
+		return 0
+	}
+	return retries
+}
+
+// awsCLIBackoff returns the delay before the given retry attempt (0-based).
+// Attempts past the end of the schedule reuse the longest backoff.
+func awsCLIBackoff(attempt int) time.Duration {
+	if attempt < len(awsCLIBackoffs) {
+		return awsCLIBackoffs[attempt]
+	}
+	return awsCLIBackoffs[len(awsCLIBackoffs)-1]
+}
+
+// execAWSCLI executes an AWS CLI command for profile. Every operation goes
+// through here: dry-run mode prints the command instead, a client with an
+// ExecFunc (see SetExecFunc) hands the command to it, and otherwise the real
+// CLI runs via runAWSCLIWithRetries. The profile and region are first
+// resolved with ResolveAWSContext.
 func (c *Client) execAWSCLI(ctx context.Context, args []string, profile *AIProfile) (string, error) {
-	verbose := viper.GetBool("debug")
+	profile = resolvedProfile(profile)
+	if c.dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] %s\n", strings.Join(awsCLICommandArgs(args, profile), " "))
+		return dryRunAWSOutput(args), nil
+	}
+	if len(args) > 0 {
+		if partition := profile.EffectivePartition(); !ServiceAvailableInPartition(args[0], partition) {
+			return "", &partitionUnavailableError{Service: args[0], Partition: partition, Region: profile.Region}
+		}
+	}
+	if c.execFunc != nil {
+		return c.execFunc(ctx, args, profile)
+	}
+	return c.runAWSCLIWithRetries(ctx, args, profile)
+}
 
-	// Build AWS CLI command
-	cmd := exec.CommandContext(ctx, "aws")
-	cmd.Args = append(cmd.Args, args...)
-	cmd.Args = append(cmd.Args, "--profile", profile.AWSProfile, "--region", profile.Region, "--no-cli-pager")
+// runAWSCLIWithRetries runs the AWS CLI, retrying throttling and transient
+// service errors with jittered backoff. Services that keep failing hard for
+// the same profile are short-circuited by awsCLIBreaker.
+func (c *Client) runAWSCLIWithRetries(ctx context.Context, args []string, profile *AIProfile) (string, error) {
+	if err := ensureAWSCLI(); err != nil {
+		return "", err
+	}
+	key := breakerKey(args, profile)
+	if err := awsCLIBreaker.allow(key); err != nil {
+		return "", err
+	}
+
+	maxRetries := awsCLIMaxRetries()
+
+	var (
+		output []byte
+		err    error
+	)
+	for attempt := 0; ; attempt++ {
+		output, err = c.runAWSCLI(ctx, args, profile)
+		if err == nil {
+			awsCLIBreaker.record(key, nil, "")
+			return string(output), nil
+		}
+		if ctx.Err() != nil || attempt >= maxRetries || !isRetryableAWSError(string(output)) {
+			break
+		}
 
-	if c.debug || verbose {
-		fmt.Printf("🚀 Executing: %s\n", strings.Join(cmd.Args, " "))
+		delay := withJitter(awsCLIBackoff(attempt))
+		logging.Verbosef("🔁 Retryable AWS CLI error, retrying in %v (attempt %d/%d)", delay, attempt+1, maxRetries)
+		select {
+		case <-ctx.Done():
+			awsCLIBreaker.abandon(key)
+			return "", fmt.Errorf("AWS CLI command failed: %w, output: %s", err, string(output))
+		case <-time.After(delay):
+		}
 	}
 
+	if ctx.Err() != nil {
+		awsCLIBreaker.abandon(key)
+	} else {
+		awsCLIBreaker.record(key, err, string(output))
+	}
+	return "", fmt.Errorf("AWS CLI command failed: %w, output: %s", err, string(output))
+}
+
+// runAWSCLI runs a single AWS CLI invocation and returns its combined output.
+func (c *Client) runAWSCLI(ctx context.Context, args []string, profile *AIProfile) ([]byte, error) {
+	return c.runAWSCLIWithInput(ctx, args, "", profile)
+}
+
+// runAWSCLIWithInput is runAWSCLI with stdin set to input when it is not
+// empty, for commands that read --cli-input-json from stdin.
+func (c *Client) runAWSCLIWithInput(ctx context.Context, args []string, input string, profile *AIProfile) ([]byte, error) {
+	slots := awsCLISemaphore()
+	if err := slots.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer slots.release()
+
+	// The command deadline is the earlier of the context's and
+	// aws.command_timeout.
+	timeout := awsCommandTimeout()
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Build AWS CLI command
+	cmdArgs := awsCLICommandArgs(args, profile)
+	cmd := exec.CommandContext(cmdCtx, cmdArgs[0], cmdArgs[1:]...)
+	// --no-cli-pager is not honoured by every subcommand and CLI version;
+	// the environment covers the rest, and stdin stays closed (or carries
+	// only the command's input) so nothing can wait on a prompt.
+	cmd.Env = append(os.Environ(), "AWS_PAGER=", "AWS_CLI_AUTO_PROMPT=off")
+	cmd.WaitDelay = awsCLIWaitDelay
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	c.debugf("🚀 Executing: %s", strings.Join(cmd.Args, " "))
+
 	start := time.Now()
 	output, err := cmd.CombinedOutput()
 	duration := time.Since(start)
 
 	if err != nil {
-		if c.debug || verbose {
-			fmt.Printf("❌ Command failed (%v): %v\nOutput: %s\nCommand: %s\n",
-				duration, err, string(output), strings.Join(cmd.Args, " "))
+		if cmdCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			err = fmt.Errorf("aws %s timed out after %v and was killed (raise aws.command_timeout if it needs longer): %w",
+				strings.Join(args[:min(2, len(args))], " "), timeout, context.DeadlineExceeded)
 		}
-		return "", fmt.Errorf("AWS CLI command failed: %w, output: %s", err, string(output))
+		c.debugf("❌ Command failed (%v): %v\nOutput: %s\nCommand: %s",
+			duration, err, string(output), strings.Join(cmd.Args, " "))
+		return output, err
 	}
 
-	if c.debug || verbose {
-		outputLen := len(output)
-		if outputLen > 200 {
-			fmt.Printf("✅ Command succeeded (%v): %d bytes output (truncated): %s...\n",
-				duration, outputLen, string(output[:200]))
-		} else {
-			fmt.Printf("✅ Command succeeded (%v): %s\n", duration, string(output))
+	if outputLen := len(output); outputLen > 200 {
+		c.debugf("✅ Command succeeded (%v): %d bytes output (truncated): %s...",
+			duration, outputLen, string(output[:200]))
+	} else {
+		c.debugf("✅ Command succeeded (%v): %s", duration, string(output))
+	}
+	return output, nil
+}
+
+// debugf prints commands and raw output at log_level debug, or always for a
+// client created with debug on.
+func (c *Client) debugf(format string, args ...any) {
+	if c.debug {
+		logging.Printf(format, args...)
+		return
+	}
+	logging.Debugf(format, args...)
+}
+
+// awsCLICommandArgs assembles the full aws invocation, including the profile,
+// region and pager flags appended to every call.
+func awsCLICommandArgs(args []string, profile *AIProfile) []string {
+	cmdArgs := make([]string, 0, len(args)+7)
+	cmdArgs = append(cmdArgs, "aws")
+	cmdArgs = append(cmdArgs, args...)
+	return append(cmdArgs, "--profile", profile.AWSProfile, "--region", profile.Region, "--no-cli-pager")
+}
+
+// dryRunAWSOutput returns an empty but well-formed payload for the requested
+// output format so callers that parse CLI output keep working in dry-run mode.
+func dryRunAWSOutput(args []string) string {
+	output := "json"
+	hasQuery := false
+	for i, arg := range args {
+		switch {
+		case arg == "--output" && i+1 < len(args):
+			output = args[i+1]
+		case strings.HasPrefix(arg, "--output="):
+			output = strings.TrimPrefix(arg, "--output=")
+		case arg == "--query" || strings.HasPrefix(arg, "--query="):
+			hasQuery = true
 		}
 	}
-	return string(output), nil
+
+	switch {
+	case output == "text" || output == "table":
+		return ""
+	case hasQuery:
+		// JMESPath projections almost always yield lists.
+		return "[]"
+	default:
+		return "{}"
+	}
+}
+
+// isRetryableAWSError determines if an AWS CLI error should be retried.
+// Permission and validation failures are never retried.
+func isRetryableAWSError(stderr string) bool {
+	lower := strings.ToLower(stderr)
+
+	// Hard failures: retrying will not help
+	if strings.Contains(lower, "accessdenied") || strings.Contains(lower, "access denied") ||
+		strings.Contains(lower, "unauthorizedoperation") || strings.Contains(lower, "not authorized") {
+		return false
+	}
+	if strings.Contains(lower, "invalidparameter") || strings.Contains(lower, "invalid parameter") ||
+		strings.Contains(lower, "validationexception") || strings.Contains(lower, "parameter validation failed") {
+		return false
+	}
+
+	// Throttling errors
+	if strings.Contains(lower, "throttl") || strings.Contains(lower, "rate exceeded") {
+		return true
+	}
+	if strings.Contains(lower, "too many requests") || strings.Contains(lower, "toomanyrequests") ||
+		strings.Contains(lower, "requestlimitexceeded") ||
+		strings.Contains(lower, "request limit exceeded") {
+		return true
+	}
+
+	// Timeout errors
+	if strings.Contains(lower, "timeout") || strings.Contains(lower, "timed out") {
+		return true
+	}
+
+	// Transient 5xx service errors
+	if strings.Contains(lower, "internalerror") || strings.Contains(lower, "internal error") ||
+		strings.Contains(lower, "internalfailure") || strings.Contains(lower, "serviceunavailable") ||
+		strings.Contains(lower, "service unavailable") {
+		return true
+	}
+	if strings.Contains(lower, "(500)") || strings.Contains(lower, "(502)") ||
+		strings.Contains(lower, "(503)") || strings.Contains(lower, "(504)") {
+		return true
+	}
+
+	return false
+}
+
+// activeServiceChecks are the per-service existence checks run during discovery.
+var activeServiceChecks = []string{
+	"check_ec2_service",
+	"check_ecs_service",
+	"check_lambda_service",
+	"check_rds_service",
+	"check_s3_service",
+	"check_dynamodb_service",
+	"check_sqs_service",
+	"check_sns_service",
+	"check_eventbridge_service",
+	"check_scheduler_service",
+	"check_pipes_service",
+	"check_ecr_service",
+	"check_elasticsearch_service",
+	"check_opensearch_service",
+	"check_eks_service",
+	"check_elasticache_service",
+	"check_redshift_service",
+	"check_kinesis_service",
+	"check_cloudformation_service",
+	"check_kms_service",
+	"check_secretsmanager_service",
+	"check_route53_service",
+	"check_cloudfront_service",
+	"check_apigateway_service",
+	"check_bedrock_service",
+	"check_codecommit_service",
+	"check_codebuild_service",
+	"check_codepipeline_service",
+	"check_sagemaker_service",
+	"check_glue_service",
+	"check_athena_service",
+	"check_emr_service",
+	"check_stepfunctions_service",
+	"check_cloudwatch_service",
+	"check_logs_service",
+	"check_xray_service",
+	"check_cognito_service",
+	"check_wafv2_service",
+	"check_shield_service",
+	"check_acm_service",
+	"check_cloudtrail_service",
+	"check_config_service",
+	"check_guardduty_service",
+	"check_ssm_service",
+	"check_batch_service",
+	"check_appsync_service",
+	"check_amplify_service",
+	"check_comprehend_service",
+	"check_textract_service",
+	"check_rekognition_service",
+	"check_polly_service",
+	"check_transcribe_service",
+	"check_translate_service",
+	"check_personalize_service",
+	"check_kendra_service",
+	"check_lex_service",
+	"check_apprunner_service",
+	"check_documentdb_service",
+	"check_neptune_service",
+	"check_timestream_service",
+	"check_inspector_service",
+	"check_macie_service",
+	"check_backup_service",
+	"check_organizations_service",
+	"check_quicksight_service",
+	"check_msk_service",
+	"check_transitgateway_service",
+	"check_securityhub_service",
+	"check_securitylake_service",
+	"check_verifiedpermissions_service",
+	"check_servicecatalog_service",
+	"check_lakeformation_service",
+	"check_datazone_service",
+	"check_mq_service",
+	"check_fsx_service",
+	"check_directconnect_service",
+	"check_dms_service",
+	"check_globalaccelerator_service",
+	"check_networkfirewall_service",
+	"check_workspaces_service",
+	"check_connect_service",
+	"check_iot_service",
+	"check_codeartifact_service",
+	"check_codeguru_service",
+	"check_devicefarm_service",
+	"check_pinpoint_service",
+	"check_storagegateway_service",
+	"check_transferfamily_service",
+	"check_appmesh_service",
+	"check_privatelink_service",
+	"check_controltower_service",
+	"check_licensemanager_service",
+	"check_resourcegroups_service",
+	"check_directoryservice_service",
+	"check_sso_service",
+	"check_privateca_service",
+	"check_memorydb_service",
+	"check_keyspaces_service",
+	"check_qldb_service",
+	"check_swf_service",
+	"check_costexplorer_service",
+	"check_budgets_service",
+	"check_datasync_service",
+	"check_migrationhub_service",
+	"check_elasticbeanstalk_service",
+	"check_cloudshell_service",
+	"check_autoscaling_service",
+	"check_elb_service",
+	"check_elbv2_service",
+	"check_efs_service",
+	"check_glacier_service",
+	"check_lightsail_service",
+	"check_ses_service",
+	"check_codedeploy_service",
+	"check_codestar_service",
+	"check_cloud9_service",
+	"check_emrserverless_service",
+	"check_datapipeline_service",
+	"check_firehose_service",
+	"check_kinesisanalytics_service",
+	"check_elastictranscoder_service",
+	"check_kinesisvideo_service",
+	"check_mediaconvert_service",
+	"check_medialive_service",
+	"check_iotanalytics_service",
+	"check_iotevents_service",
+	"check_iotsitewise_service",
+	"check_greengrass_service",
+	"check_auditmanager_service",
+	"check_wellarchitected_service",
+	"check_support_service",
+	"check_braket_service",
+	"check_robomaker_service",
+	"check_groundstation_service",
+	"check_gamelift_service",
+	"check_workmail_service",
+	"check_workdocs_service",
+	"check_chime_service",
+	"check_mediapackage_service",
+	"check_mediastore_service",
+	"check_mediatailor_service",
+	"check_ivs_service",
+	"check_appflow_service",
+	"check_cleanrooms_service",
+	"check_qbusiness_service",
+	"check_cloudsearch_service",
+	"check_dataexchange_service",
+	"check_finspace_service",
+	"check_forecast_service",
+	"check_frauddetector_service",
+	"check_lookoutequipment_service",
+	"check_lookoutmetrics_service",
+	"check_lookoutvision_service",
+	"check_monitron_service",
+	"check_detective_service",
+	"check_signer_service",
+	"check_artifact_service",
+	"check_chatbot_service",
+	"check_computeoptimizer_service",
+	"check_launchwizard_service",
+	"check_managedservices_service",
+	"check_proton_service",
+	"check_resiliencehub_service",
+	"check_resourceexplorer_service",
+	"check_snowball_service",
+	"check_mgn_service",
+	"check_m2_service",
+	"check_discovery_service",
+	"check_cur_service",
+	"check_applicationcostprofiler_service",
+	"check_managedblockchain_service",
+	"check_alexaforbusiness_service",
+	"check_outposts_service",
+	"check_serverlessrepo_service",
+	"check_wavelength_service",
+	"check_redhatopenshiftaws_service",
+	"check_location_service",
+	"check_iot1click_service",
+	"check_iotfleetwise_service",
+	"check_iotthingsgraph_service",
+	"check_iottwinmaker_service",
+	"check_cloudhsm_service",
+	"check_fms_service",
+	"check_inspector2_service",
+	"check_networkfirewall_service",
+	"check_shield_service",
+}
+
+// globalServiceChecks are checks against global (non-regional) APIs. During
+// multi-region discovery they run once instead of once per region.
+var globalServiceChecks = map[string]bool{
+	"check_s3_service":                true,
+	"check_route53_service":           true,
+	"check_cloudfront_service":        true,
+	"check_organizations_service":     true,
+	"check_shield_service":            true,
+	"check_globalaccelerator_service": true,
+	"check_budgets_service":           true,
+	"check_costexplorer_service":      true,
+	"check_cur_service":               true,
+	"check_support_service":           true,
 }
 
 // discoverAllActiveServices discovers all active AWS services by running service checks in parallel
 func (c *Client) discoverAllActiveServices(ctx context.Context, profile *AIProfile) (string, error) {
-	serviceChecks := []string{
-		"check_ec2_service",
-		"check_ecs_service",
-		"check_lambda_service",
-		"check_rds_service",
-		"check_s3_service",
-		"check_dynamodb_service",
-		"check_sqs_service",
-		"check_sns_service",
-		"check_eventbridge_service",
-		"check_scheduler_service",
-		"check_pipes_service",
-		"check_ecr_service",
-		"check_elasticsearch_service",
-		"check_opensearch_service",
-		"check_eks_service",
-		"check_elasticache_service",
-		"check_redshift_service",
-		"check_kinesis_service",
-		"check_cloudformation_service",
-		"check_kms_service",
-		"check_secretsmanager_service",
-		"check_route53_service",
-		"check_cloudfront_service",
-		"check_apigateway_service",
-		"check_bedrock_service",
-		"check_codecommit_service",
-		"check_codebuild_service",
-		"check_codepipeline_service",
-		"check_sagemaker_service",
-		"check_glue_service",
-		"check_athena_service",
-		"check_emr_service",
-		"check_stepfunctions_service",
-		"check_cloudwatch_service",
-		"check_logs_service",
-		"check_xray_service",
-		"check_cognito_service",
-		"check_wafv2_service",
-		"check_shield_service",
-		"check_acm_service",
-		"check_cloudtrail_service",
-		"check_config_service",
-		"check_guardduty_service",
-		"check_ssm_service",
-		"check_batch_service",
-		"check_appsync_service",
-		"check_amplify_service",
-		"check_comprehend_service",
-		"check_textract_service",
-		"check_rekognition_service",
-		"check_polly_service",
-		"check_transcribe_service",
-		"check_translate_service",
-		"check_personalize_service",
-		"check_kendra_service",
-		"check_lex_service",
-		"check_apprunner_service",
-		"check_documentdb_service",
-		"check_neptune_service",
-		"check_timestream_service",
-		"check_inspector_service",
-		"check_macie_service",
-		"check_backup_service",
-		"check_organizations_service",
-		"check_quicksight_service",
-		"check_msk_service",
-		"check_transitgateway_service",
-		"check_securityhub_service",
-		"check_securitylake_service",
-		"check_verifiedpermissions_service",
-		"check_servicecatalog_service",
-		"check_lakeformation_service",
-		"check_datazone_service",
-		"check_mq_service",
-		"check_fsx_service",
-		"check_directconnect_service",
-		"check_dms_service",
-		"check_globalaccelerator_service",
-		"check_networkfirewall_service",
-		"check_workspaces_service",
-		"check_connect_service",
-		"check_iot_service",
-		"check_codeartifact_service",
-		"check_codeguru_service",
-		"check_devicefarm_service",
-		"check_pinpoint_service",
-		"check_storagegateway_service",
-		"check_transferfamily_service",
-		"check_appmesh_service",
-		"check_privatelink_service",
-		"check_controltower_service",
-		"check_licensemanager_service",
-		"check_resourcegroups_service",
-		"check_directoryservice_service",
-		"check_sso_service",
-		"check_privateca_service",
-		"check_memorydb_service",
-		"check_keyspaces_service",
-		"check_qldb_service",
-		"check_swf_service",
-		"check_costexplorer_service",
-		"check_budgets_service",
-		"check_datasync_service",
-		"check_migrationhub_service",
-		"check_elasticbeanstalk_service",
-		"check_cloudshell_service",
-		"check_autoscaling_service",
-		"check_elb_service",
-		"check_elbv2_service",
-		"check_efs_service",
-		"check_glacier_service",
-		"check_lightsail_service",
-		"check_ses_service",
-		"check_codedeploy_service",
-		"check_codestar_service",
-		"check_cloud9_service",
-		"check_emrserverless_service",
-		"check_datapipeline_service",
-		"check_firehose_service",
-		"check_kinesisanalytics_service",
-		"check_elastictranscoder_service",
-		"check_kinesisvideo_service",
-		"check_mediaconvert_service",
-		"check_medialive_service",
-		"check_iotanalytics_service",
-		"check_iotevents_service",
-		"check_iotsitewise_service",
-		"check_greengrass_service",
-		"check_auditmanager_service",
-		"check_wellarchitected_service",
-		"check_support_service",
-		"check_braket_service",
-		"check_robomaker_service",
-		"check_groundstation_service",
-		"check_gamelift_service",
-		"check_workmail_service",
-		"check_workdocs_service",
-		"check_chime_service",
-		"check_mediapackage_service",
-		"check_mediastore_service",
-		"check_mediatailor_service",
-		"check_ivs_service",
-		"check_appflow_service",
-		"check_cleanrooms_service",
-		"check_qbusiness_service",
-		"check_cloudsearch_service",
-		"check_dataexchange_service",
-		"check_finspace_service",
-		"check_forecast_service",
-		"check_frauddetector_service",
-		"check_lookoutequipment_service",
-		"check_lookoutmetrics_service",
-		"check_lookoutvision_service",
-		"check_monitron_service",
-		"check_detective_service",
-		"check_signer_service",
-		"check_artifact_service",
-		"check_chatbot_service",
-		"check_computeoptimizer_service",
-		"check_launchwizard_service",
-		"check_managedservices_service",
-		"check_proton_service",
-		"check_resiliencehub_service",
-		"check_resourceexplorer_service",
-		"check_snowball_service",
-		"check_mgn_service",
-		"check_m2_service",
-		"check_discovery_service",
-		"check_cur_service",
-		"check_applicationcostprofiler_service",
-		"check_managedblockchain_service",
-		"check_alexaforbusiness_service",
-		"check_outposts_service",
-		"check_serverlessrepo_service",
-		"check_wavelength_service",
-		"check_redhatopenshiftaws_service",
-		"check_location_service",
-		"check_iot1click_service",
-		"check_iotfleetwise_service",
-		"check_iotthingsgraph_service",
-		"check_iottwinmaker_service",
-		"check_cloudhsm_service",
-		"check_fms_service",
-		"check_inspector2_service",
-		"check_networkfirewall_service",
-		"check_shield_service",
+	return c.runDiscoveryChecks(ctx, discoveryServiceChecks(), profile), nil
+}
+
+// discoverAllActiveServicesMultiRegion runs discovery across several regions
+// concurrently. Global services are checked once; everything else is checked
+// per region and the output is grouped by region.
+func (c *Client) discoverAllActiveServicesMultiRegion(ctx context.Context, profile *AIProfile, regions []string) (string, error) {
+	regions = normalizeRegions(regions)
+	if len(regions) <= 1 {
+		if len(regions) == 1 {
+			profile = profileForRegion(profile, regions[0])
+		}
+		return c.discoverAllActiveServices(ctx, profile)
 	}
 
-	operations := make([]LLMOperation, len(serviceChecks))
-	for i, check := range serviceChecks {
+	var globalChecks, regionalChecks []string
+	for _, check := range discoveryServiceChecks() {
+		if globalServiceChecks[check] {
+			globalChecks = append(globalChecks, check)
+		} else {
+			regionalChecks = append(regionalChecks, check)
+		}
+	}
+
+	type regionResult struct {
+		label  string
+		output string
+	}
+
+	labels := append([]string{"global"}, regions...)
+	results := make([]regionResult, len(labels))
+
+	var wg sync.WaitGroup
+	run := func(index int, checks []string, regionProfile *AIProfile) {
+		defer wg.Done()
+		output := c.runDiscoveryChecks(ctx, checks, regionProfile)
+		results[index] = regionResult{label: labels[index], output: output}
+	}
+
+	wg.Add(len(labels))
+	go run(0, globalChecks, profileForRegion(profile, regions[0]))
+	for i, region := range regions {
+		go run(i+1, regionalChecks, profileForRegion(profile, region))
+	}
+	wg.Wait()
+
+	var out strings.Builder
+	for i, result := range results {
+		if i == 0 {
+			out.WriteString("=== Global services ===\n")
+		} else {
+			out.WriteString(fmt.Sprintf("=== Region: %s ===\n", result.label))
+		}
+		out.WriteString(result.output)
+		out.WriteString("\n")
+	}
+	return out.String(), nil
+}
+
+// discoveryOperations wraps service check names as discovery operations.
+func discoveryOperations(checks []string) []LLMOperation {
+	operations := make([]LLMOperation, len(checks))
+	for i, check := range checks {
 		operations[i] = LLMOperation{
 			Operation:  check,
 			Reason:     "Infrastructure discovery",
 			Parameters: make(map[string]interface{}),
 		}
 	}
+	return operations
+}
+
+// profileForRegion returns a copy of profile targeting region.
+func profileForRegion(profile *AIProfile, region string) *AIProfile {
+	copied := *profile
+	copied.Region = region
+	copied.regionPinned = true
+	return &copied
+}
+
+// configuredRegions returns the regions from aws.regions. Both YAML lists and
+// comma-separated strings are accepted.
+func configuredRegions() []string {
+	return normalizeRegions(viper.GetStringSlice("aws.regions"))
+}
 
-	return c.executeOperationsWithProfile(ctx, operations, profile)
+// normalizeRegions splits comma-separated entries, trims whitespace and drops
+// duplicates while preserving order.
+func normalizeRegions(regions []string) []string {
+	seen := make(map[string]bool, len(regions))
+	out := make([]string, 0, len(regions))
+	for _, entry := range regions {
+		for _, region := range strings.Split(entry, ",") {
+			region = strings.TrimSpace(region)
+			if region == "" || seen[region] {
+				continue
+			}
+			seen[region] = true
+			out = append(out, region)
+		}
+	}
+	return out
 }
 
 // getInfrastructureOverview gets a comprehensive overview of the entire infrastructure
@@ -2931,12 +3280,72 @@ func (c *Client) getTerraformStateSummary(ctx context.Context, profile *AIProfil
 	return context, nil
 }
 
+// getTerraformPlanDiff runs terraform plan in the configured workspace and
+// groups the planned changes by action, destroys first
+func (c *Client) getTerraformPlanDiff(ctx context.Context, profile *AIProfile) (string, error) {
+	workspace := viper.GetString("terraform.default_workspace")
+	if workspace == "" {
+		workspace = "dev"
+	}
+
+	tfClient, err := tfclient.NewClient(workspace)
+	if err != nil {
+		return fmt.Sprintf("❌ Unable to get terraform plan: %v", err), nil
+	}
+
+	diff, err := tfClient.GetPlanDiff(ctx)
+	if err != nil {
+		return fmt.Sprintf("❌ Failed to run terraform plan: %v", err), nil
+	}
+
+	return formatTerraformPlanDiff(diff), nil
+}
+
+func formatTerraformPlanDiff(diff *tfclient.PlanDiff) string {
+	if diff.NoChanges || (diff.ToAdd+diff.ToChange+diff.ToDestroy == 0 && len(diff.Created)+len(diff.Updated)+len(diff.Replaced)+len(diff.Destroyed) == 0) {
+		return "✅ Terraform plan: no changes. Infrastructure matches the configuration."
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Terraform Plan: %d to add, %d to change, %d to destroy\n", diff.ToAdd, diff.ToChange, diff.ToDestroy))
+	if len(diff.Destroyed) > 0 || len(diff.Replaced) > 0 {
+		result.WriteString("\n🚨 DESTRUCTIVE CHANGES:\n")
+		for _, address := range diff.Destroyed {
+			result.WriteString(fmt.Sprintf("  - destroy: %s\n", address))
+		}
+		for _, address := range diff.Replaced {
+			result.WriteString(fmt.Sprintf("  - replace (destroy then create): %s\n", address))
+		}
+	}
+	for _, group := range []struct {
+		title     string
+		addresses []string
+	}{
+		{"To create", diff.Created},
+		{"To update in-place", diff.Updated},
+	} {
+		if len(group.addresses) == 0 {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("\n%s:\n", group.title))
+		for _, address := range group.addresses {
+			result.WriteString(fmt.Sprintf("  - %s\n", address))
+		}
+	}
+	return result.String()
+}
+
 // categorizeAWSError categorizes AWS CLI errors to provide better user feedback
 func categorizeAWSError(err error, serviceName string) string {
 	if err == nil {
 		return ""
 	}
 
+	var partitionErr *partitionUnavailableError
+	if errors.As(err, &partitionErr) {
+		return fmt.Sprintf("ℹ️  %s service is not offered in the %s partition", serviceName, partitionErr.Partition)
+	}
+
 	errStr := strings.ToLower(err.Error())
 
 	switch {
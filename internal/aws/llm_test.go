@@ -0,0 +1,239 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	tfclient "github.com/bgdnvk/clanker/internal/terraform"
+	"github.com/spf13/viper"
+)
+
+func TestIsRetryableAWSError(t *testing.T) {
+	tests := []struct {
+		name      string
+		stderr    string
+		retryable bool
+	}{
+		{"throttling", "An error occurred (ThrottlingException) when calling the ListFunctions operation: Rate exceeded", true},
+		{"rate exceeded", "Rate exceeded", true},
+		{"request limit exceeded", "An error occurred (RequestLimitExceeded): Request limit exceeded.", true},
+		{"too many requests", "An error occurred (TooManyRequestsException)", true},
+		{"read timeout", "Read timeout on endpoint URL: \"https://lambda.us-east-1.amazonaws.com/\"", true},
+		{"internal error", "An error occurred (InternalError) when calling the DescribeInstances operation (reached max retries: 2)", true},
+		{"service unavailable", "An error occurred (ServiceUnavailable) when calling the ListBuckets operation", true},
+		{"http 503", "An error occurred (503) when calling the HeadBucket operation: Service Unavailable", true},
+		{"access denied", "An error occurred (AccessDeniedException) when calling the ListFunctions operation", false},
+		{"unauthorized", "An error occurred (UnauthorizedOperation) when calling the DescribeInstances operation", false},
+		{"invalid parameter", "An error occurred (InvalidParameterValue) when calling the DescribeInstances operation", false},
+		{"validation", "An error occurred (ValidationException): 1 validation error detected", false},
+		{"unknown", "Unable to locate credentials", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableAWSError(tt.stderr); got != tt.retryable {
+				t.Errorf("isRetryableAWSError(%q) = %v, want %v", tt.stderr, got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestAWSCLIMaxRetries(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	if got := awsCLIMaxRetries(); got != len(awsCLIBackoffs) {
+		t.Errorf("default max retries = %d, want %d", got, len(awsCLIBackoffs))
+	}
+
+	viper.Set("aws.max_retries", 5)
+	if got := awsCLIMaxRetries(); got != 5 {
+		t.Errorf("configured max retries = %d, want 5", got)
+	}
+
+	viper.Set("aws.max_retries", -1)
+	if got := awsCLIMaxRetries(); got != 0 {
+		t.Errorf("negative max retries = %d, want 0", got)
+	}
+}
+
+// fakeAWSBinary puts an aws script on PATH that echoes AWS_PAGER, or hangs
+// the way a pager does when its first argument is "hang".
+func fakeAWSBinary(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$1\" = hang ]; then sleep 30; fi\necho \"pager=[$AWS_PAGER] prompt=$AWS_CLI_AUTO_PROMPT\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "aws"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRunAWSCLIDisablesPager(t *testing.T) {
+	fakeAWSBinary(t)
+	out, err := (&Client{}).runAWSCLI(context.Background(), []string{"sts", "get-caller-identity"}, &AIProfile{AWSProfile: "dev", Region: "us-east-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "pager=[] prompt=off" {
+		t.Errorf("aws ran with %q, want an empty AWS_PAGER and auto-prompt off", got)
+	}
+}
+
+func TestRunAWSCLICommandTimeout(t *testing.T) {
+	fakeAWSBinary(t)
+	t.Cleanup(viper.Reset)
+	viper.Set("aws.command_timeout", "200ms")
+
+	start := time.Now()
+	_, err := (&Client{}).runAWSCLI(context.Background(), []string{"hang", "forever"}, &AIProfile{AWSProfile: "dev", Region: "us-east-1"})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("hung command ran for %v; expected it killed after the timeout", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "aws hang forever timed out after 200ms") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestAWSCommandTimeout(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	if got := awsCommandTimeout(); got != defaultAWSCommandTimeout {
+		t.Errorf("default timeout = %v, want %v", got, defaultAWSCommandTimeout)
+	}
+	viper.Set("aws.command_timeout", "45s")
+	if got := awsCommandTimeout(); got != 45*time.Second {
+		t.Errorf("configured timeout = %v, want 45s", got)
+	}
+}
+
+func TestAWSCLIBackoff(t *testing.T) {
+	if got := awsCLIBackoff(0); got != 200*time.Millisecond {
+		t.Errorf("first backoff = %v, want 200ms", got)
+	}
+	if got := awsCLIBackoff(10); got != 1200*time.Millisecond {
+		t.Errorf("backoff past schedule = %v, want 1200ms", got)
+	}
+}
+
+func TestDryRunAWSOutput(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"logs", "describe-log-groups", "--output", "json"}, "{}"},
+		{[]string{"lambda", "list-functions"}, "{}"},
+		{[]string{"ec2", "describe-instances", "--query", "Reservations[].Instances[]"}, "[]"},
+		{[]string{"s3api", "list-buckets", "--output", "text"}, ""},
+		{[]string{"ecs", "list-clusters", "--output=table"}, ""},
+	}
+	for _, tt := range tests {
+		if got := dryRunAWSOutput(tt.args); got != tt.want {
+			t.Errorf("dryRunAWSOutput(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestExecAWSCLIDryRun(t *testing.T) {
+	c := &Client{dryRun: true}
+	profile := &AIProfile{AWSProfile: "dev", Region: "us-east-1"}
+
+	out, err := c.execAWSCLI(context.Background(), []string{"logs", "describe-log-groups", "--output", "json"}, profile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "{}" {
+		t.Errorf("expected canned JSON payload, got %q", out)
+	}
+
+	args := awsCLICommandArgs([]string{"sts", "get-caller-identity"}, profile)
+	want := "aws sts get-caller-identity --profile dev --region us-east-1 --no-cli-pager"
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("awsCLICommandArgs = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeRegions(t *testing.T) {
+	got := normalizeRegions([]string{"us-east-1, eu-west-1", " us-east-1 ", "", "ap-south-1"})
+	want := []string{"us-east-1", "eu-west-1", "ap-south-1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("normalizeRegions = %v, want %v", got, want)
+	}
+}
+
+func TestDiscoverAllActiveServicesMultiRegion(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("local_mode", false)
+
+	c := &Client{dryRun: true}
+	profile := &AIProfile{AWSProfile: "dev", Region: "us-east-1"}
+
+	out, err := c.discoverAllActiveServicesMultiRegion(context.Background(), profile, []string{"us-east-1", "eu-west-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	global := strings.Index(out, "=== Global services ===")
+	east := strings.Index(out, "=== Region: us-east-1 ===")
+	west := strings.Index(out, "=== Region: eu-west-1 ===")
+	if global < 0 || east < global || west < east {
+		t.Fatalf("expected global, us-east-1, eu-west-1 sections in order, got:\n%s", out)
+	}
+	if n := strings.Count(out, "check_route53_service"); n != 1 {
+		t.Errorf("expected global check to run once, ran %d times", n)
+	}
+	if n := strings.Count(out, "check_lambda_service"); n != 2 {
+		t.Errorf("expected regional check to run per region, ran %d times", n)
+	}
+	if profile.Region != "us-east-1" {
+		t.Errorf("caller profile should not be mutated, got region %q", profile.Region)
+	}
+}
+
+func TestFormatTerraformPlanDiff(t *testing.T) {
+	if got := formatTerraformPlanDiff(&tfclient.PlanDiff{NoChanges: true}); !strings.Contains(got, "no changes") {
+		t.Fatalf("expected no-changes message, got %q", got)
+	}
+
+	got := formatTerraformPlanDiff(&tfclient.PlanDiff{
+		ToAdd: 1, ToChange: 1, ToDestroy: 1,
+		Created:   []string{"aws_subnet.a"},
+		Updated:   []string{"aws_instance.web"},
+		Destroyed: []string{"aws_s3_bucket.logs"},
+	})
+	destroy := strings.Index(got, "destroy: aws_s3_bucket.logs")
+	create := strings.Index(got, "aws_subnet.a")
+	if destroy < 0 || create < 0 || destroy > create {
+		t.Fatalf("expected destroys listed before creates, got:\n%s", got)
+	}
+	if !strings.Contains(got, "1 to add, 1 to change, 1 to destroy") {
+		t.Fatalf("expected summary counts, got:\n%s", got)
+	}
+}
+
+func TestGetAIProfileRoleModels(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("ai.providers.anthropic.model", "big-model")
+	viper.Set("ai.providers.anthropic.decision_model", "small-model")
+
+	profile, err := GetAIProfile("anthropic")
+	if err != nil {
+		t.Fatalf("GetAIProfile: %v", err)
+	}
+	if profile.DecisionModel != "small-model" || profile.ModelFor(ModelRoleDecision) != "small-model" {
+		t.Errorf("decision model = %q, want small-model", profile.DecisionModel)
+	}
+	if profile.AnalysisModel != "big-model" || profile.ModelFor(ModelRoleAnalysis) != "big-model" {
+		t.Errorf("analysis model = %q, want fallback to big-model", profile.AnalysisModel)
+	}
+	if got := profile.ModelFor(""); got != "big-model" {
+		t.Errorf("ModelFor(\"\") = %q, want big-model", got)
+	}
+}
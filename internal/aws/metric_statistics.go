@@ -0,0 +1,339 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// metricStatisticsWindow is the trailing window fetched by get_metric_statistics
+	// unless window_minutes overrides it.
+	metricStatisticsWindow = 3 * time.Hour
+	metricStatisticsPeriod = 300
+	// metricStatisticsMaxClusters bounds the per-cluster fan-out for AWS/ECS
+	// metrics requested without a ClusterName dimension.
+	metricStatisticsMaxClusters = 5
+)
+
+// standardMetricStatistics are the values cloudwatch accepts for --statistics;
+// anything else (p99, tm90, ...) is an extended statistic.
+var standardMetricStatistics = map[string]string{
+	"average":     "Average",
+	"avg":         "Average",
+	"maximum":     "Maximum",
+	"max":         "Maximum",
+	"minimum":     "Minimum",
+	"min":         "Minimum",
+	"sum":         "Sum",
+	"samplecount": "SampleCount",
+}
+
+// metricStatisticsRequest is a validated get_metric_statistics input.
+type metricStatisticsRequest struct {
+	Namespace  string
+	MetricName string
+	Dimensions []metricDimension
+	Period     int
+	Stat       string
+	Extended   bool
+	Window     time.Duration
+	// End is the end of the window; zero means now.
+	End time.Time
+}
+
+type metricDimension struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// metricDatapoint is one decoded datapoint for the requested statistic.
+type metricDatapoint struct {
+	Timestamp time.Time
+	Value     float64
+	Unit      string
+}
+
+type metricStatisticsResponse struct {
+	Label      string `json:"Label"`
+	Datapoints []struct {
+		Timestamp          time.Time          `json:"Timestamp"`
+		Average            *float64           `json:"Average"`
+		Maximum            *float64           `json:"Maximum"`
+		Minimum            *float64           `json:"Minimum"`
+		Sum                *float64           `json:"Sum"`
+		SampleCount        *float64           `json:"SampleCount"`
+		ExtendedStatistics map[string]float64 `json:"ExtendedStatistics"`
+		Unit               string             `json:"Unit"`
+	} `json:"Datapoints"`
+}
+
+// parseMetricStatisticsInput validates the operation parameters and fills defaults.
+func parseMetricStatisticsInput(input map[string]interface{}) (metricStatisticsRequest, error) {
+	req := metricStatisticsRequest{Period: metricStatisticsPeriod, Stat: "Average", Window: metricStatisticsWindow}
+
+	req.Namespace, _ = input["namespace"].(string)
+	req.MetricName, _ = input["metric_name"].(string)
+	req.Namespace = strings.TrimSpace(req.Namespace)
+	req.MetricName = strings.TrimSpace(req.MetricName)
+	if req.Namespace == "" || req.MetricName == "" {
+		return req, fmt.Errorf("namespace and metric_name parameters required")
+	}
+
+	if period, ok := intParam(input, "period"); ok && period > 0 {
+		// CloudWatch only accepts periods that are multiples of 60 seconds.
+		req.Period = int(math.Ceil(float64(period)/60)) * 60
+	}
+	if minutes, ok := intParam(input, "window_minutes"); ok && minutes > 0 {
+		req.Window = time.Duration(minutes) * time.Minute
+	}
+
+	if stat, _ := input["stat"].(string); strings.TrimSpace(stat) != "" {
+		stat = strings.TrimSpace(stat)
+		if standard, ok := standardMetricStatistics[strings.ToLower(stat)]; ok {
+			req.Stat = standard
+		} else {
+			req.Stat = strings.ToLower(stat)
+			req.Extended = true
+		}
+	}
+
+	req.Dimensions = parseMetricDimensions(input["dimensions"])
+	return req, nil
+}
+
+// parseMetricDimensions accepts dimensions as a {"Name": "Value"} map, a list
+// of {"Name": .., "Value": ..} objects, or "Name=X,Value=Y" strings.
+func parseMetricDimensions(raw interface{}) []metricDimension {
+	var dims []metricDimension
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			dims = append(dims, metricDimension{Name: name, Value: fmt.Sprintf("%v", value)})
+		}
+	case map[string]string:
+		for name, value := range v {
+			dims = append(dims, metricDimension{Name: name, Value: value})
+		}
+	case []interface{}:
+		for _, item := range v {
+			switch d := item.(type) {
+			case map[string]interface{}:
+				name, _ := d["Name"].(string)
+				if name == "" {
+					name, _ = d["name"].(string)
+				}
+				value := d["Value"]
+				if value == nil {
+					value = d["value"]
+				}
+				if name != "" && value != nil {
+					dims = append(dims, metricDimension{Name: name, Value: fmt.Sprintf("%v", value)})
+				}
+			case string:
+				dims = append(dims, parseMetricDimensions(d)...)
+			}
+		}
+	case string:
+		for _, part := range strings.Fields(v) {
+			var dim metricDimension
+			for _, kv := range strings.Split(part, ",") {
+				key, value, ok := strings.Cut(kv, "=")
+				if !ok {
+					continue
+				}
+				switch strings.ToLower(key) {
+				case "name":
+					dim.Name = value
+				case "value":
+					dim.Value = value
+				}
+			}
+			if dim.Name != "" && dim.Value != "" {
+				dims = append(dims, dim)
+			}
+		}
+	}
+	sort.Slice(dims, func(i, j int) bool { return dims[i].Name < dims[j].Name })
+	return dims
+}
+
+func hasMetricDimension(dims []metricDimension, name string) bool {
+	for _, d := range dims {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getMetricStatistics fetches a metric over the recent window and renders the
+// sorted datapoints with a min/max/avg summary. AWS/ECS metrics without a
+// ClusterName are fetched per cluster, since ECS publishes none account-wide.
+func (c *Client) getMetricStatistics(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	req, err := parseMetricStatisticsInput(input)
+	if err != nil {
+		return "", err
+	}
+	// An explicit window_minutes wins; otherwise follow the investigation
+	// window, widening the period so the window fits in one call.
+	if _, explicit := intParam(input, "window_minutes"); !explicit {
+		start, end := operationWindow(ctx, input, req.Window)
+		req.Window, req.End = end.Sub(start), end
+	}
+	if _, explicit := intParam(input, "period"); !explicit {
+		req.Period = metricPeriodForWindow(req.Period, req.Window)
+	}
+
+	if req.Namespace != "AWS/ECS" || hasMetricDimension(req.Dimensions, "ClusterName") {
+		return c.fetchMetricStatistics(ctx, req, profile)
+	}
+
+	clusters, err := c.listECSArns(ctx, []string{"ecs", "list-clusters", "--output", "json", "--query", "clusterArns"}, profile)
+	if err != nil {
+		return categorizeAWSError(err, "ECS"), nil
+	}
+	if len(clusters) == 0 {
+		return "No ECS clusters found.", nil
+	}
+	var out strings.Builder
+	for _, cluster := range limitStrings(clusters, metricStatisticsMaxClusters) {
+		clusterReq := req
+		clusterReq.Dimensions = append([]metricDimension{{Name: "ClusterName", Value: shortArn(cluster)}}, req.Dimensions...)
+		result, err := c.fetchMetricStatistics(ctx, clusterReq, profile)
+		if err != nil {
+			out.WriteString(fmt.Sprintf("❌ %s %s for cluster %s: %v\n\n", req.Namespace, req.MetricName, shortArn(cluster), err))
+			continue
+		}
+		out.WriteString(result)
+		out.WriteString("\n")
+	}
+	return out.String(), nil
+}
+
+func (c *Client) fetchMetricStatistics(ctx context.Context, req metricStatisticsRequest, profile *AIProfile) (string, error) {
+	end := req.End
+	if end.IsZero() {
+		end = time.Now().UTC()
+	}
+	raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile)
+	if err != nil {
+		return categorizeAWSError(err, "CloudWatch"), nil
+	}
+	points, err := decodeMetricDatapoints(raw, req)
+	if err != nil {
+		return "", err
+	}
+	return formatMetricStatistics(req, points), nil
+}
+
+// metricStatisticsArgs builds the get-metric-statistics call for the window
+// ending at end.
+func metricStatisticsArgs(req metricStatisticsRequest, end time.Time) []string {
+	start := end.Add(-req.Window)
+	args := []string{"cloudwatch", "get-metric-statistics",
+		"--namespace", req.Namespace,
+		"--metric-name", req.MetricName,
+		"--start-time", start.Format(time.RFC3339),
+		"--end-time", end.Format(time.RFC3339),
+		"--period", strconv.Itoa(req.Period),
+	}
+	if req.Extended {
+		args = append(args, "--extended-statistics", req.Stat)
+	} else {
+		args = append(args, "--statistics", req.Stat)
+	}
+	if len(req.Dimensions) > 0 {
+		args = append(args, "--dimensions")
+		for _, d := range req.Dimensions {
+			args = append(args, fmt.Sprintf("Name=%s,Value=%s", d.Name, d.Value))
+		}
+	}
+	return append(args, "--output", "json")
+}
+
+// decodeMetricDatapoints extracts the requested statistic from a
+// get-metric-statistics payload, sorted oldest first.
+func decodeMetricDatapoints(raw string, req metricStatisticsRequest) ([]metricDatapoint, error) {
+	var resp metricStatisticsResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse metric statistics: %w", err)
+	}
+
+	points := make([]metricDatapoint, 0, len(resp.Datapoints))
+	for _, dp := range resp.Datapoints {
+		var value *float64
+		if req.Extended {
+			if v, ok := dp.ExtendedStatistics[req.Stat]; ok {
+				value = &v
+			}
+		} else {
+			switch req.Stat {
+			case "Average":
+				value = dp.Average
+			case "Maximum":
+				value = dp.Maximum
+			case "Minimum":
+				value = dp.Minimum
+			case "Sum":
+				value = dp.Sum
+			case "SampleCount":
+				value = dp.SampleCount
+			}
+		}
+		if value == nil {
+			continue
+		}
+		points = append(points, metricDatapoint{Timestamp: dp.Timestamp, Value: *value, Unit: dp.Unit})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	return points, nil
+}
+
+// summarizeMetricDatapoints returns the min, max and mean of the datapoints.
+func summarizeMetricDatapoints(points []metricDatapoint) (minimum, maximum, mean float64) {
+	if len(points) == 0 {
+		return 0, 0, 0
+	}
+	minimum, maximum = points[0].Value, points[0].Value
+	sum := 0.0
+	for _, p := range points {
+		minimum = math.Min(minimum, p.Value)
+		maximum = math.Max(maximum, p.Value)
+		sum += p.Value
+	}
+	return minimum, maximum, sum / float64(len(points))
+}
+
+func formatMetricStatistics(req metricStatisticsRequest, points []metricDatapoint) string {
+	var out strings.Builder
+	window := "last " + formatLookback(req.Window)
+	if !req.End.IsZero() {
+		window = describeWindow(req.End.Add(-req.Window), req.End)
+	}
+	out.WriteString(fmt.Sprintf("📈 %s %s (%s, %ds period, %s)\n", req.Namespace, req.MetricName, req.Stat, req.Period, window))
+	if len(req.Dimensions) > 0 {
+		dims := make([]string, 0, len(req.Dimensions))
+		for _, d := range req.Dimensions {
+			dims = append(dims, d.Name+"="+d.Value)
+		}
+		out.WriteString(fmt.Sprintf("Dimensions: %s\n", strings.Join(dims, ", ")))
+	}
+	if len(points) == 0 {
+		out.WriteString("No datapoints in the window.\n")
+		return out.String()
+	}
+
+	unit := points[0].Unit
+	minimum, maximum, mean := summarizeMetricDatapoints(points)
+	out.WriteString(fmt.Sprintf("Summary: min %.2f, max %.2f, avg %.2f %s over %d datapoints\n", minimum, maximum, mean, unit, len(points)))
+	for _, p := range points {
+		out.WriteString(fmt.Sprintf("  %s  %.2f\n", p.Timestamp.UTC().Format(time.RFC3339), p.Value))
+	}
+	return out.String()
+}
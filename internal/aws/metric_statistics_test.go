@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMetricStatisticsInput(t *testing.T) {
+	req, err := parseMetricStatisticsInput(map[string]interface{}{
+		"namespace":   "AWS/Lambda",
+		"metric_name": "Duration",
+		"stat":        "P99",
+		"period":      float64(90),
+		"dimensions":  map[string]interface{}{"FunctionName": "api"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !req.Extended || req.Stat != "p99" {
+		t.Fatalf("expected extended p99 stat, got %q (extended=%v)", req.Stat, req.Extended)
+	}
+	if req.Period != 120 {
+		t.Fatalf("expected period rounded up to 120, got %d", req.Period)
+	}
+	if len(req.Dimensions) != 1 || req.Dimensions[0] != (metricDimension{Name: "FunctionName", Value: "api"}) {
+		t.Fatalf("unexpected dimensions: %+v", req.Dimensions)
+	}
+
+	req, err = parseMetricStatisticsInput(map[string]interface{}{"namespace": "AWS/ECS", "metric_name": "CPUUtilization", "stat": "max"})
+	if err != nil || req.Extended || req.Stat != "Maximum" || req.Period != metricStatisticsPeriod {
+		t.Fatalf("unexpected request %+v, err %v", req, err)
+	}
+
+	if _, err := parseMetricStatisticsInput(map[string]interface{}{"namespace": "AWS/Lambda"}); err == nil {
+		t.Fatal("expected an error without metric_name")
+	}
+}
+
+func TestParseMetricDimensions(t *testing.T) {
+	dims := parseMetricDimensions([]interface{}{
+		map[string]interface{}{"Name": "ServiceName", "Value": "web"},
+		"Name=ClusterName,Value=prod",
+	})
+	if len(dims) != 2 || dims[0].Name != "ClusterName" || dims[1].Value != "web" {
+		t.Fatalf("unexpected dimensions: %+v", dims)
+	}
+}
+
+func TestDecodeMetricDatapoints(t *testing.T) {
+	raw := `{"Label":"Duration","Datapoints":[
+		{"Timestamp":"2024-05-01T10:10:00Z","Average":30,"ExtendedStatistics":{"p99":300},"Unit":"Milliseconds"},
+		{"Timestamp":"2024-05-01T10:00:00Z","Average":10,"ExtendedStatistics":{"p99":100},"Unit":"Milliseconds"},
+		{"Timestamp":"2024-05-01T10:05:00Z","Average":20,"Unit":"Milliseconds"}
+	]}`
+
+	points, err := decodeMetricDatapoints(raw, metricStatisticsRequest{Stat: "p99", Extended: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 2 || points[0].Value != 100 || points[1].Value != 300 {
+		t.Fatalf("expected sorted p99 datapoints, got %+v", points)
+	}
+
+	points, err = decodeMetricDatapoints(raw, metricStatisticsRequest{Stat: "Average"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	minimum, maximum, mean := summarizeMetricDatapoints(points)
+	if minimum != 10 || maximum != 30 || mean != 20 {
+		t.Fatalf("unexpected summary min=%v max=%v avg=%v", minimum, maximum, mean)
+	}
+
+	req := metricStatisticsRequest{Namespace: "AWS/Lambda", MetricName: "Duration", Stat: "Average", Period: 300, Window: time.Hour}
+	out := formatMetricStatistics(req, points)
+	if !strings.Contains(out, "min 10.00, max 30.00, avg 20.00 Milliseconds over 3 datapoints") {
+		t.Fatalf("unexpected summary output:\n%s", out)
+	}
+	if strings.Index(out, "10:00:00Z") > strings.Index(out, "10:10:00Z") {
+		t.Fatalf("datapoints are not in timestamp order:\n%s", out)
+	}
+}
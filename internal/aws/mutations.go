@@ -0,0 +1,253 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bgdnvk/clanker/internal/logging"
+	"github.com/spf13/viper"
+)
+
+// mutatingOperations are the only operations that change account state. They
+// are refused unless the client has mutations enabled and the individual call
+// carries confirmed: true.
+var mutatingOperations = map[string]bool{
+	"restart_ecs_service":      true,
+	"update_lambda_env":        true,
+	"set_asg_desired_capacity": true,
+}
+
+// IsMutatingOperation reports whether toolName changes account state.
+func IsMutatingOperation(toolName string) bool {
+	return mutatingOperations[toolName]
+}
+
+// SetAllowMutations enables or disables the mutating operations.
+func (c *Client) SetAllowMutations(allow bool) {
+	c.allowMutations = allow
+}
+
+// auditEntry is one line of the mutation audit log.
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Profile   string    `json:"profile"`
+	Region    string    `json:"region"`
+	Command   string    `json:"command"`
+	Input     string    `json:"input,omitempty"` // --cli-input-json with values redacted
+	DryRun    bool      `json:"dry_run,omitempty"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// executeMutation runs one of the mutatingOperations after checking the gate
+// and records the exact command, with secret values redacted, in the audit log.
+func (c *Client) executeMutation(ctx context.Context, toolName string, input map[string]interface{}, profile *AIProfile) (string, error) {
+	if !c.allowMutations {
+		return "", fmt.Errorf("%s refused: mutating operations are disabled (enable with --allow-mutations or aws.allow_mutations)", toolName)
+	}
+	if !boolParam(input, "confirmed") {
+		return "", fmt.Errorf("%s refused: mutating operations require confirmed: true", toolName)
+	}
+
+	args, cliInput, err := c.mutationArgs(ctx, toolName, input, profile)
+	if err != nil {
+		return "", err
+	}
+
+	// Open the audit log before touching the account so a broken audit path
+	// never results in an unrecorded change.
+	audit, err := openAuditLog()
+	if err != nil {
+		return "", fmt.Errorf("%s refused: %w", toolName, err)
+	}
+	defer audit.Close()
+
+	output, execErr := c.execMutationCLI(ctx, args, cliInput, profile)
+
+	entry := auditEntry{
+		Time:      time.Now().UTC(),
+		Operation: toolName,
+		Profile:   profile.AWSProfile,
+		Region:    profile.Region,
+		Command:   strings.Join(awsCLICommandArgs(args, profile), " "),
+		Input:     redactCLIInput(cliInput),
+		DryRun:    c.dryRun,
+		Status:    "success",
+	}
+	if execErr != nil {
+		entry.Status = "failed"
+		entry.Error = execErr.Error()
+	}
+	line, err := json.Marshal(entry)
+	if err == nil {
+		_, err = audit.Write(append(line, '\n'))
+	}
+	if err != nil {
+		logging.Infof("⚠️  Failed to write mutation audit log: %v", err)
+	}
+
+	if execErr != nil {
+		return "", execErr
+	}
+	return output, nil
+}
+
+// execMutationCLI runs a mutating command exactly once. Unlike execAWSCLI it
+// neither retries nor consults the circuit breaker: a retried write that had
+// in fact succeeded would be applied twice. cliInput, when set, is passed on
+// stdin so values never appear in the process list.
+func (c *Client) execMutationCLI(ctx context.Context, args []string, cliInput string, profile *AIProfile) (string, error) {
+	profile = resolvedProfile(profile)
+	if c.dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] %s\n", strings.Join(awsCLICommandArgs(args, profile), " "))
+		return dryRunAWSOutput(args), nil
+	}
+	if c.execFunc != nil {
+		return c.execFunc(ctx, args, profile)
+	}
+	if err := ensureAWSCLI(); err != nil {
+		return "", err
+	}
+	output, err := c.runAWSCLIWithInput(ctx, args, cliInput, profile)
+	if err != nil {
+		return "", fmt.Errorf("AWS CLI command failed: %w, output: %s", err, string(output))
+	}
+	return string(output), nil
+}
+
+// redactCLIInput replaces every environment variable value in a
+// --cli-input-json document so the audit log records which keys changed
+// without their values.
+func redactCLIInput(cliInput string) string {
+	if cliInput == "" {
+		return ""
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(cliInput), &doc); err != nil {
+		return "[REDACTED]"
+	}
+	if env, ok := doc["Environment"].(map[string]interface{}); ok {
+		if vars, ok := env["Variables"].(map[string]interface{}); ok {
+			for key := range vars {
+				vars[key] = "[REDACTED]"
+			}
+		}
+	}
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return "[REDACTED]"
+	}
+	return string(redacted)
+}
+
+// mutationArgs builds the AWS CLI arguments for a mutating operation, plus a
+// --cli-input-json document to pass on stdin for operations that carry
+// secret values.
+func (c *Client) mutationArgs(ctx context.Context, toolName string, input map[string]interface{}, profile *AIProfile) ([]string, string, error) {
+	switch toolName {
+	case "restart_ecs_service":
+		serviceName, ok := input["service_name"].(string)
+		if !ok || serviceName == "" {
+			return nil, "", fmt.Errorf("service_name parameter required")
+		}
+		clusterName, _ := input["cluster_name"].(string)
+		if clusterName == "" {
+			clusterName = "default"
+		}
+		return []string{"ecs", "update-service",
+			"--cluster", clusterName,
+			"--service", serviceName,
+			"--force-new-deployment",
+			"--output", "json",
+			"--query", "service.{Service:serviceName,Status:status,Desired:desiredCount,Deployments:length(deployments)}"}, "", nil
+
+	case "update_lambda_env":
+		functionName, ok := input["function_name"].(string)
+		if !ok || functionName == "" {
+			return nil, "", fmt.Errorf("function_name parameter required")
+		}
+		updates, ok := input["environment"].(map[string]interface{})
+		if !ok || len(updates) == 0 {
+			return nil, "", fmt.Errorf("environment parameter required (map of variables to set)")
+		}
+
+		// update-function-configuration replaces the whole environment, so
+		// merge the requested changes into the current variables.
+		current, err := c.execAWSCLI(ctx, []string{"lambda", "get-function-configuration", "--function-name", functionName, "--output", "json"}, profile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read current environment for %s: %w", functionName, err)
+		}
+		var config struct {
+			Environment struct {
+				Variables map[string]string `json:"Variables"`
+			} `json:"Environment"`
+		}
+		if err := json.Unmarshal([]byte(current), &config); err != nil {
+			return nil, "", fmt.Errorf("failed to parse current environment for %s: %w", functionName, err)
+		}
+		variables := config.Environment.Variables
+		if variables == nil {
+			variables = make(map[string]string, len(updates))
+		}
+		for key, value := range updates {
+			variables[key] = fmt.Sprintf("%v", value)
+		}
+		// Environment values are often secrets: send them on stdin rather
+		// than as arguments visible to every process on the host.
+		cliInput, err := json.Marshal(map[string]interface{}{
+			"FunctionName": functionName,
+			"Environment":  map[string]interface{}{"Variables": variables},
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode environment: %w", err)
+		}
+		return []string{"lambda", "update-function-configuration",
+			"--cli-input-json", "file:///dev/stdin",
+			"--output", "json",
+			"--query", "{Function:FunctionName,LastUpdateStatus:LastUpdateStatus,State:State}"}, string(cliInput), nil
+
+	case "set_asg_desired_capacity":
+		asgName, ok := input["asg_name"].(string)
+		if !ok || asgName == "" {
+			return nil, "", fmt.Errorf("asg_name parameter required")
+		}
+		desired, ok := intParam(input, "desired_capacity")
+		if !ok || desired < 0 {
+			return nil, "", fmt.Errorf("desired_capacity parameter required (non-negative integer)")
+		}
+		return []string{"autoscaling", "set-desired-capacity",
+			"--auto-scaling-group-name", asgName,
+			"--desired-capacity", strconv.Itoa(desired)}, "", nil
+
+	default:
+		return nil, "", fmt.Errorf("unknown mutating operation: %s", toolName)
+	}
+}
+
+// openAuditLog opens the mutation audit log for appending. The path comes from
+// aws.audit_log and defaults to ~/.clanker/aws-audit.log.
+func openAuditLog() (*os.File, error) {
+	path := strings.TrimSpace(viper.GetString("aws.audit_log"))
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine audit log path: %w", err)
+		}
+		path = filepath.Join(home, ".clanker", "aws-audit.log")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("cannot create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log %s: %w", path, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestExecuteMutation_Gate(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("aws.audit_log", filepath.Join(t.TempDir(), "audit.log"))
+	profile := &AIProfile{AWSProfile: "dev", Region: "us-east-1"}
+	input := map[string]interface{}{"service_name": "api", "cluster_name": "prod"}
+
+	c := &Client{dryRun: true}
+	if _, err := c.executeAWSOperation(context.Background(), "restart_ecs_service", input, profile); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Fatalf("expected refusal when mutations are disabled, got %v", err)
+	}
+
+	c.SetAllowMutations(true)
+	if _, err := c.executeAWSOperation(context.Background(), "restart_ecs_service", input, profile); err == nil || !strings.Contains(err.Error(), "confirmed") {
+		t.Fatalf("expected refusal without confirmed: true, got %v", err)
+	}
+}
+
+func TestExecuteMutation_AuditLog(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	auditPath := filepath.Join(t.TempDir(), "nested", "audit.log")
+	viper.Set("aws.audit_log", auditPath)
+	profile := &AIProfile{AWSProfile: "dev", Region: "us-east-1"}
+
+	c := &Client{dryRun: true, allowMutations: true}
+	input := map[string]interface{}{"asg_name": "web", "desired_capacity": float64(3), "confirmed": true}
+	if _, err := c.executeAWSOperation(context.Background(), "set_asg_desired_capacity", input, profile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("expected audit log to be written: %v", err)
+	}
+	var entry auditEntry
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(data))), &entry); err != nil {
+		t.Fatalf("audit log line is not JSON: %v", err)
+	}
+	want := "aws autoscaling set-desired-capacity --auto-scaling-group-name web --desired-capacity 3 --profile dev --region us-east-1 --no-cli-pager"
+	if entry.Command != want {
+		t.Errorf("audit command = %q, want %q", entry.Command, want)
+	}
+	if entry.Operation != "set_asg_desired_capacity" || entry.Status != "success" || !entry.DryRun {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+}
+
+func TestMutationArgs_UpdateLambdaEnvRequiresVariables(t *testing.T) {
+	c := &Client{dryRun: true}
+	profile := &AIProfile{AWSProfile: "dev", Region: "us-east-1"}
+
+	if _, _, err := c.mutationArgs(context.Background(), "update_lambda_env", map[string]interface{}{"function_name": "fn"}, profile); err == nil {
+		t.Fatal("expected error when environment is missing")
+	}
+
+	args, cliInput, err := c.mutationArgs(context.Background(), "update_lambda_env", map[string]interface{}{
+		"function_name": "fn",
+		"environment":   map[string]interface{}{"LOG_LEVEL": "debug"},
+	}, profile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(cliInput, `"Environment":{"Variables":{"LOG_LEVEL":"debug"}}`) {
+		t.Errorf("expected merged environment in the cli input, got %s", cliInput)
+	}
+	if joined := strings.Join(args, " "); strings.Contains(joined, "debug") || !strings.Contains(joined, "--cli-input-json file:///dev/stdin") {
+		t.Errorf("environment values must not be passed as arguments, got %v", args)
+	}
+}
+
+func TestExecuteMutation_RedactsLambdaEnv(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	viper.Set("aws.audit_log", auditPath)
+
+	f := newFakeCLI()
+	f.fixtures["lambda get-function-configuration"] = `{"Environment":{"Variables":{"DB_PASSWORD":"hunter2"}}}`
+	f.fixtures["lambda update-function-configuration"] = `{"Function":"fn"}`
+	c := newFakeClient(f)
+	c.SetAllowMutations(true)
+	input := map[string]interface{}{"function_name": "fn", "environment": map[string]interface{}{"API_KEY": "s3cret"}, "confirmed": true}
+	if _, err := c.executeAWSOperation(context.Background(), "update_lambda_env", input, &AIProfile{AWSProfile: "dev", Region: "us-east-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") || strings.Contains(string(data), "hunter2") {
+		t.Errorf("audit log leaks environment values: %s", data)
+	}
+	var entry auditEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(entry.Input, `"API_KEY":"[REDACTED]"`) {
+		t.Errorf("expected redacted keys in the audit input, got %s", entry.Input)
+	}
+}
@@ -0,0 +1,362 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bgdnvk/clanker/internal/logging"
+	"github.com/spf13/viper"
+)
+
+// OperationFunc runs a read-only operation. Methods with the signature
+// func(c *Client, ctx, input, profile) register directly as method
+// expressions, e.g. (*Client).auditS3Buckets.
+type OperationFunc func(c *Client, ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error)
+
+// OperationInfo describes a registered operation.
+type OperationInfo struct {
+	Name        string
+	Description string
+	Builtin     bool // documented in the analysis prompt already
+}
+
+type registeredOperation struct {
+	info OperationInfo
+	fn   OperationFunc
+}
+
+// OperationRegistry maps operation names to their implementations.
+// executeAWSOperation consults it before its built-in switch.
+type OperationRegistry struct {
+	mu  sync.RWMutex
+	ops map[string]registeredOperation
+}
+
+// NewOperationRegistry returns an empty registry.
+func NewOperationRegistry() *OperationRegistry {
+	return &OperationRegistry{ops: make(map[string]registeredOperation)}
+}
+
+var operationNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Register adds an operation. Names must be snake_case, unique, and not one
+// of the mutating operations, which stay behind their own gate.
+func (r *OperationRegistry) Register(name, description string, fn OperationFunc) error {
+	return r.register(OperationInfo{Name: name, Description: description}, fn)
+}
+
+func (r *OperationRegistry) register(info OperationInfo, fn OperationFunc) error {
+	if !operationNamePattern.MatchString(info.Name) {
+		return fmt.Errorf("invalid operation name %q: use lowercase snake_case", info.Name)
+	}
+	if fn == nil {
+		return fmt.Errorf("operation %s has no implementation", info.Name)
+	}
+	if IsMutatingOperation(info.Name) {
+		return fmt.Errorf("operation %s is a mutating operation and cannot be replaced", info.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.ops[info.Name]; exists {
+		return fmt.Errorf("operation %s is already registered", info.Name)
+	}
+	r.ops[info.Name] = registeredOperation{info: info, fn: fn}
+	return nil
+}
+
+// Lookup returns the implementation registered under name.
+func (r *OperationRegistry) Lookup(name string) (OperationFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	op, ok := r.ops[name]
+	return op.fn, ok
+}
+
+// List returns every registered operation sorted by name.
+func (r *OperationRegistry) List() []OperationInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	infos := make([]OperationInfo, 0, len(r.ops))
+	for _, op := range r.ops {
+		infos = append(infos, op.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// builtinOperations are the operations that have moved out of the switch in
+// executeAWSOperation. Their descriptions live in the analysis prompt.
+var builtinOperations = map[string]OperationFunc{
+	"analyze_active_alarms":          (*Client).analyzeActiveAlarms,
+	"analyze_alb_errors":             (*Client).analyzeALBErrors,
+	"analyze_asg_activity":           (*Client).analyzeASGActivity,
+	"analyze_apigw_errors":           (*Client).analyzeAPIGWErrors,
+	"analyze_connectivity":           (*Client).analyzeConnectivity,
+	"analyze_dynamodb_throttling":    (*Client).analyzeDynamoDBThrottling,
+	"analyze_ecr_image_scan":         (*Client).analyzeECRImageScan,
+	"analyze_ec2_health":             (*Client).analyzeEC2Health,
+	"analyze_ecs_service_events":     (*Client).analyzeECSServiceEvents,
+	"get_ecs_service_events":         (*Client).analyzeECSServiceEvents,
+	"analyze_iam_role":               (*Client).analyzeIAMRole,
+	"analyze_kinesis_lag":            (*Client).analyzeKinesisLag,
+	"analyze_lambda_performance":     (*Client).analyzeLambdaPerformance,
+	"analyze_queue_health":           (*Client).analyzeQueueHealth,
+	"analyze_rds_performance":        (*Client).analyzeRDSPerformance,
+	"analyze_stepfunctions_failures": (*Client).analyzeStepFunctionsFailures,
+	"audit_lambda_config":            (*Client).auditLambdaConfig,
+	"audit_s3_bucket":                (*Client).auditS3Buckets,
+	"compare_environments":           (*Client).compareEnvironments,
+	"describe_eks_workloads":         (*Client).describeEKSWorkloads,
+	"detect_stack_drift":             (*Client).detectStackDrift,
+	"find_orphaned_resources":        (*Client).findOrphanedResources,
+	"get_cost_by_tag":                (*Client).getCostByTag,
+	"get_metric_statistics":          (*Client).getMetricStatistics,
+	"get_security_findings":          (*Client).getSecurityFindings,
+	"get_service_quotas":             (*Client).getServiceQuotas,
+	"get_xray_traces":                (*Client).getXRayTraces,
+	"raw_aws_query":                  (*Client).rawAWSQuery,
+}
+
+var (
+	defaultOperations        = newBuiltinOperationRegistry()
+	configuredOperationsOnce sync.Once
+)
+
+func newBuiltinOperationRegistry() *OperationRegistry {
+	r := NewOperationRegistry()
+	for name, fn := range builtinOperations {
+		if err := r.register(OperationInfo{Name: name, Builtin: true}, fn); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// RegisterOperation adds a read-only operation to the default registry so
+// the analysis prompt offers it and executeAWSOperation runs it.
+func RegisterOperation(name string, fn OperationFunc) error {
+	return defaultOperations.Register(name, "", fn)
+}
+
+// RegisterDescribedOperation is RegisterOperation with a description the
+// LLM sees when choosing operations.
+func RegisterDescribedOperation(name, description string, fn OperationFunc) error {
+	return defaultOperations.Register(name, description, fn)
+}
+
+// ListOperations returns every operation in the default registry, including
+// the ones declared under aws.custom_operations.
+func ListOperations() []OperationInfo {
+	return registeredOperations().List()
+}
+
+// registeredOperations returns the default registry after loading
+// aws.custom_operations into it once.
+func registeredOperations() *OperationRegistry {
+	configuredOperationsOnce.Do(func() {
+		var specs []CustomOperationSpec
+		if err := viper.UnmarshalKey("aws.custom_operations", &specs); err != nil {
+			logging.Infof("⚠️  ignoring aws.custom_operations: %v", err)
+			return
+		}
+		for _, err := range registerCustomOperations(defaultOperations, specs) {
+			logging.Infof("⚠️  ignoring aws.custom_operations entry: %v", err)
+		}
+	})
+	return defaultOperations
+}
+
+// customOperationsPromptSection lists the non-built-in operations for the
+// analysis prompt; it is empty when there are none.
+func customOperationsPromptSection() string {
+	var b strings.Builder
+	for _, info := range ListOperations() {
+		if info.Builtin {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("\nCUSTOM OPERATIONS (registered for this account):\n")
+		}
+		b.WriteString("- " + info.Name)
+		if info.Description != "" {
+			b.WriteString(": " + info.Description)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// CustomOperationSpec declares an operation in config as a fixed aws CLI
+// command:
+//
+//	aws:
+//	  custom_operations:
+//	    - name: list_team_functions
+//	      description: Lambda functions owned by the payments team
+//	      command: [lambda, list-functions, --query, "Functions[?starts_with(FunctionName, 'payments-')].FunctionName"]
+//
+// "{param}" in an argument is replaced by that operation parameter.
+type CustomOperationSpec struct {
+	Name        string   `mapstructure:"name"`
+	Description string   `mapstructure:"description"`
+	Command     []string `mapstructure:"command"`
+}
+
+// customOperationReadVerbs are the CLI action prefixes a configured
+// operation or raw_aws_query may use.
+var customOperationReadVerbs = []string{"describe-", "list-", "get-", "head-", "lookup-", "search-", "filter-"}
+
+// customOperationDeniedActions read secrets or credentials, or write local
+// files, and are refused even though their verbs look read-only.
+var customOperationDeniedActions = map[string]bool{
+	"get-secret-value":        true,
+	"get-parameter":           true,
+	"get-parameters":          true,
+	"get-parameters-by-path":  true,
+	"get-password-data":       true,
+	"get-login-password":      true,
+	"get-authorization-token": true,
+	"get-session-token":       true,
+	"get-federation-token":    true,
+	"get-object":              true,
+	"get-snapshot-block":      true,
+}
+
+// customOperationDeniedWords mark actions that return secrets or credentials
+// or stream their result to a local file, whatever their exact name:
+// lightsail get-relational-database-master-user-password, redshift
+// get-cluster-credentials, apigateway get-export, glacier get-job-output.
+var customOperationDeniedWords = []string{"password", "credential", "secret", "token", "export", "sdk", "job-output", "torrent", "access-details"}
+
+var customOperationParam = regexp.MustCompile(`\{([a-z][a-z0-9_]*)\}`)
+
+// registerCustomOperations registers each spec that names a read-only CLI
+// command and returns why the others were refused.
+func registerCustomOperations(r *OperationRegistry, specs []CustomOperationSpec) []error {
+	var errs []error
+	for _, spec := range specs {
+		if err := validateCustomOperation(spec); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		command := append([]string(nil), spec.Command...)
+		fn := func(c *Client, ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+			args, err := customOperationArgs(command, input)
+			if err != nil {
+				return "", err
+			}
+			return c.execAWSCLI(ctx, args, profile)
+		}
+		if err := r.Register(spec.Name, spec.Description, fn); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func validateCustomOperation(spec CustomOperationSpec) error {
+	if len(spec.Command) < 2 {
+		return fmt.Errorf("%s: command needs a service and an action, e.g. [lambda, list-functions]", spec.Name)
+	}
+	if err := checkReadOnlyAction(spec.Command[0], spec.Command[1]); err != nil {
+		return fmt.Errorf("%s: %w", spec.Name, err)
+	}
+	if err := checkReadOnlyArgs(spec.Command[2:]); err != nil {
+		return fmt.Errorf("%s: %w", spec.Name, err)
+	}
+	return nil
+}
+
+// readOnlyDeniedFlags decrypt secrets, write local files, read request input
+// from local files, or point the CLI at another account or endpoint.
+var readOnlyDeniedFlags = []string{"--with-decryption", "--outfile", "--profile", "--endpoint-url", "--cli-input-json", "--cli-input-yaml", "--ca-bundle", "--no-verify-ssl"}
+
+// readOnlyBooleanFlags are common flags that take no value, so a bare
+// argument after them is positional.
+var readOnlyBooleanFlags = map[string]bool{"--debug": true, "--dry-run": true, "--all-regions": true, "--include-all-instances": true}
+
+// checkReadOnlyArgs refuses denied flags, local file references and
+// positional arguments. Positional arguments are how actions such as
+// s3api get-object-torrent and ebs get-snapshot-block name the local file
+// they write, so every bare argument must be a value of the flag before it.
+// List flags take several values (--instance-ids i-1 i-2); values after the
+// first may not look like a local path.
+func checkReadOnlyArgs(args []string) error {
+	expectValue, inList := false, false
+	for _, arg := range args {
+		trimmed := strings.TrimSpace(arg)
+		lower := strings.ToLower(trimmed)
+		if strings.Contains(lower, "file://") || strings.Contains(lower, "fileb://") {
+			return fmt.Errorf("arguments may not read local files (%s)", arg)
+		}
+		if strings.HasPrefix(lower, "--") {
+			name, _, hasValue := strings.Cut(lower, "=")
+			for _, flag := range readOnlyDeniedFlags {
+				if name == flag {
+					return fmt.Errorf("%s is not allowed", flag)
+				}
+			}
+			expectValue = !hasValue && !readOnlyBooleanFlags[name] && !strings.HasPrefix(name, "--no-")
+			inList = false
+			continue
+		}
+		if !expectValue && !(inList && !looksLikeLocalPath(trimmed)) {
+			return fmt.Errorf("positional argument %q is not allowed; pass values with flags", arg)
+		}
+		inList = expectValue || inList
+		expectValue = false
+	}
+	return nil
+}
+
+// looksLikeLocalPath reports whether a bare argument could name a local
+// file; ARNs contain slashes but are not paths.
+func looksLikeLocalPath(arg string) bool {
+	if strings.HasPrefix(arg, "arn:") {
+		return false
+	}
+	return strings.ContainsAny(arg, `/\`) || strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "~")
+}
+
+// checkReadOnlyAction allows a CLI action only when it starts with a
+// read-only verb and is not one of the denied actions.
+func checkReadOnlyAction(service, action string) error {
+	action = strings.ToLower(action)
+	denied := customOperationDeniedActions[action]
+	for _, word := range customOperationDeniedWords {
+		denied = denied || strings.Contains(action, word)
+	}
+	if denied {
+		return fmt.Errorf("%s %s reads secrets or writes local files and is not allowed", service, action)
+	}
+	for _, verb := range customOperationReadVerbs {
+		if strings.HasPrefix(action, verb) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s is not a read-only action", service, action)
+}
+
+// customOperationArgs fills "{param}" placeholders in command from input.
+func customOperationArgs(command []string, input map[string]interface{}) ([]string, error) {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		var missing string
+		args[i] = customOperationParam.ReplaceAllStringFunc(arg, func(m string) string {
+			name := m[1 : len(m)-1]
+			value := strings.TrimSpace(getStringParam(input, name, ""))
+			if value == "" && missing == "" {
+				missing = name
+			}
+			return value
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("%s parameter required", missing)
+		}
+	}
+	return args, nil
+}
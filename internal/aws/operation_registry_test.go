@@ -0,0 +1,94 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestOperationRegistryRegister(t *testing.T) {
+	r := NewOperationRegistry()
+	noop := func(*Client, context.Context, map[string]interface{}, *AIProfile) (string, error) { return "", nil }
+
+	if err := r.Register("list_team_buckets", "Team buckets", noop); err != nil {
+		t.Fatal(err)
+	}
+	for name, fn := range map[string]OperationFunc{
+		"list_team_buckets":   noop, // duplicate
+		"List-Buckets":        noop,
+		"restart_ecs_service": noop, // mutating
+		"missing_impl":        nil,
+	} {
+		if err := r.Register(name, "", fn); err == nil {
+			t.Errorf("Register(%q) succeeded, want an error", name)
+		}
+	}
+	if _, ok := r.Lookup("list_team_buckets"); !ok {
+		t.Error("expected the registered operation to be found")
+	}
+	if got := r.List(); len(got) != 1 || got[0].Name != "list_team_buckets" || got[0].Description != "Team buckets" {
+		t.Errorf("List() = %+v", got)
+	}
+}
+
+func TestExecuteAWSOperationUsesRegistry(t *testing.T) {
+	name := "test_registered_operation"
+	err := RegisterDescribedOperation(name, "Echo the bucket", func(c *Client, ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+		return c.execAWSCLI(ctx, []string{"s3api", "head-bucket", "--bucket", getStringParam(input, "bucket_name", "")}, profile)
+	})
+	if err != nil && !strings.Contains(err.Error(), "already registered") { // -count > 1
+		t.Fatal(err)
+	}
+	f := newFakeCLI()
+	f.fixtures["s3api head-bucket --bucket logs"] = "ok"
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), name, map[string]interface{}{"bucket_name": "logs"}, &AIProfile{})
+	if err != nil || out != "ok" {
+		t.Fatalf("executeAWSOperation() = %q, %v", out, err)
+	}
+
+	prompt := GetLLMAnalysisPrompt("anything")
+	if !strings.Contains(prompt, "CUSTOM OPERATIONS") || !strings.Contains(prompt, "- test_registered_operation: Echo the bucket") {
+		t.Error("expected the registered operation offered in the analysis prompt")
+	}
+	if strings.Contains(prompt, "- audit_s3_bucket\n") {
+		t.Error("built-in operations should not be listed again as custom operations")
+	}
+	found := false
+	for _, info := range ListOperations() {
+		if info.Name == "audit_lambda_config" && info.Builtin {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected migrated built-ins in ListOperations")
+	}
+}
+
+func TestRegisterCustomOperations(t *testing.T) {
+	r := NewOperationRegistry()
+	errs := registerCustomOperations(r, []CustomOperationSpec{
+		{Name: "function_config", Description: "One function", Command: []string{"lambda", "get-function-configuration", "--function-name", "{function_name}"}},
+		{Name: "delete_bucket", Command: []string{"s3api", "delete-bucket", "--bucket", "x"}},
+		{Name: "read_secret", Command: []string{"secretsmanager", "get-secret-value", "--secret-id", "db"}},
+		{Name: "no_action", Command: []string{"ec2"}},
+		{Name: "positional", Command: []string{"s3api", "head-object", "--bucket", "b", "--key", "k", "/tmp/out"}},
+		{Name: "history", Command: []string{"ssm", "get-parameter-history", "--name", "db", "--with-decryption"}},
+	})
+	if len(errs) != 5 {
+		t.Fatalf("expected 5 refused specs, got %v", errs)
+	}
+
+	fn, ok := r.Lookup("function_config")
+	if !ok {
+		t.Fatal("expected the read-only spec registered")
+	}
+	f := newFakeCLI()
+	f.fixtures["lambda get-function-configuration --function-name checkout"] = "{}"
+	c := newFakeClient(f)
+	if out, err := fn(c, context.Background(), map[string]interface{}{"function_name": "checkout"}, &AIProfile{}); err != nil || out != "{}" {
+		t.Errorf("custom operation = %q, %v", out, err)
+	}
+	if _, err := fn(c, context.Background(), map[string]interface{}{}, &AIProfile{}); err == nil || !strings.Contains(err.Error(), "function_name parameter required") {
+		t.Errorf("expected a missing parameter error, got %v", err)
+	}
+}
@@ -0,0 +1,393 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Static us-east-1 prices (USD) for what each kind of orphaned resource
+// keeps billing; they match the deploy cost estimator's table.
+const (
+	orphanHoursPerMonth  = 730.0
+	eipIdleHour          = 0.005
+	natGatewayIdleHour   = 0.045
+	loadBalancerIdleHour = 0.0225
+	snapshotGBMonth      = 0.05
+)
+
+// ebsGBMonth is the storage price per volume type.
+var ebsGBMonth = map[string]float64{
+	"gp2": 0.10, "gp3": 0.08, "io1": 0.125, "io2": 0.125, "st1": 0.045, "sc1": 0.015, "standard": 0.05,
+}
+
+const (
+	// orphanSnapshotAge is how old a snapshot must be to count as
+	// forgotten; override with snapshot_age_days.
+	orphanSnapshotAge = 90 * 24 * time.Hour
+	// orphanNATWindow is how long a NAT gateway must have sent no traffic
+	// to count as idle.
+	orphanNATWindow          = 7 * 24 * time.Hour
+	orphanMaxLoadBalancers   = 20
+	orphanMaxResourcesListed = 15
+)
+
+// orphanedResource is one resource that bills without doing work.
+type orphanedResource struct {
+	ID      string
+	Detail  string
+	Monthly float64 // estimated monthly savings from deleting it
+}
+
+// orphanCheck finds one kind of waste.
+type orphanCheck struct {
+	Kind    string
+	Service string // for categorizeAWSError
+	Run     func(ctx context.Context, c *Client, input map[string]interface{}, profile *AIProfile) ([]orphanedResource, error)
+}
+
+var orphanChecks = []orphanCheck{
+	{Kind: "Unattached EBS volumes", Service: "EC2", Run: findUnattachedVolumes},
+	{Kind: "Unassociated Elastic IPs", Service: "EC2", Run: findUnassociatedAddresses},
+	{Kind: "Idle NAT gateways", Service: "EC2", Run: findIdleNATGateways},
+	{Kind: "Old EBS snapshots", Service: "EC2", Run: findOldSnapshots},
+	{Kind: "Load balancers with no targets", Service: "ELBv2", Run: findEmptyLoadBalancers},
+	{Kind: "Stopped instances with EBS", Service: "EC2", Run: findStoppedInstanceVolumes},
+}
+
+// findOrphanedResources is the find_orphaned_resources operation: a read-only
+// sweep for resources that keep billing while doing nothing, each with an
+// estimated monthly saving.
+func (c *Client) findOrphanedResources(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	var out strings.Builder
+	out.WriteString("🧹 Orphaned resources\n")
+	out.WriteString("============================\n")
+
+	type section struct {
+		kind      string
+		resources []orphanedResource
+		total     float64
+	}
+	var sections []section
+	var failures []string
+	grandTotal := 0.0
+	for _, check := range orphanChecks {
+		resources, err := check.Run(ctx, c, input, profile)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", check.Kind, categorizeAWSError(err, check.Service)))
+			continue
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		s := section{kind: check.Kind, resources: resources}
+		for _, r := range resources {
+			s.total += r.Monthly
+		}
+		sort.SliceStable(s.resources, func(i, j int) bool { return s.resources[i].Monthly > s.resources[j].Monthly })
+		sections = append(sections, s)
+		grandTotal += s.total
+	}
+	sort.SliceStable(sections, func(i, j int) bool { return sections[i].total > sections[j].total })
+
+	if len(sections) == 0 {
+		out.WriteString("✅ No unattached volumes, idle addresses or gateways, old snapshots, empty load balancers or stopped instances found\n")
+	} else {
+		out.WriteString(fmt.Sprintf("💰 Estimated savings: ~$%.2f/month (us-east-1 on-demand prices)\n", grandTotal))
+	}
+	for _, s := range sections {
+		out.WriteString(fmt.Sprintf("\n%s (%d, ~$%.2f/month):\n", s.kind, len(s.resources), s.total))
+		for i, r := range s.resources {
+			if i == orphanMaxResourcesListed {
+				out.WriteString(fmt.Sprintf("  … %d more\n", len(s.resources)-i))
+				break
+			}
+			out.WriteString(fmt.Sprintf("  • %s: %s (~$%.2f/month)\n", r.ID, r.Detail, r.Monthly))
+		}
+	}
+	if len(failures) > 0 {
+		out.WriteString("\nNot checked:\n")
+		for _, f := range failures {
+			out.WriteString(fmt.Sprintf("  %s\n", f))
+		}
+	}
+	return out.String(), nil
+}
+
+type ec2Volume struct {
+	VolumeID    string    `json:"VolumeId"`
+	Size        float64   `json:"Size"`
+	VolumeType  string    `json:"VolumeType"`
+	CreateTime  time.Time `json:"CreateTime"`
+	Attachments []struct {
+		InstanceID string `json:"InstanceId"`
+	} `json:"Attachments"`
+}
+
+func (v ec2Volume) monthly() float64 {
+	price, ok := ebsGBMonth[v.VolumeType]
+	if !ok {
+		price = ebsGBMonth["gp3"]
+	}
+	return v.Size * price
+}
+
+func (c *Client) describeVolumes(ctx context.Context, filter string, profile *AIProfile) ([]ec2Volume, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-volumes", "--filters", filter, "--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Volumes []ec2Volume `json:"Volumes"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse volumes: %w", err)
+	}
+	return resp.Volumes, nil
+}
+
+func findUnattachedVolumes(ctx context.Context, c *Client, _ map[string]interface{}, profile *AIProfile) ([]orphanedResource, error) {
+	volumes, err := c.describeVolumes(ctx, "Name=status,Values=available", profile)
+	if err != nil {
+		return nil, err
+	}
+	var found []orphanedResource
+	for _, v := range volumes {
+		detail := fmt.Sprintf("%.0f GB %s, unattached", v.Size, v.VolumeType)
+		if !v.CreateTime.IsZero() {
+			detail += ", created " + v.CreateTime.UTC().Format("2006-01-02")
+		}
+		found = append(found, orphanedResource{ID: v.VolumeID, Detail: detail, Monthly: v.monthly()})
+	}
+	return found, nil
+}
+
+func findUnassociatedAddresses(ctx context.Context, c *Client, _ map[string]interface{}, profile *AIProfile) ([]orphanedResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-addresses", "--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Addresses []struct {
+			AllocationID  string `json:"AllocationId"`
+			PublicIP      string `json:"PublicIp"`
+			AssociationID string `json:"AssociationId"`
+		} `json:"Addresses"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse addresses: %w", err)
+	}
+	var found []orphanedResource
+	for _, a := range resp.Addresses {
+		if a.AssociationID != "" {
+			continue
+		}
+		found = append(found, orphanedResource{ID: a.AllocationID, Detail: a.PublicIP + " not associated", Monthly: eipIdleHour * orphanHoursPerMonth})
+	}
+	return found, nil
+}
+
+func findIdleNATGateways(ctx context.Context, c *Client, _ map[string]interface{}, profile *AIProfile) ([]orphanedResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-nat-gateways", "--filter", "Name=state,Values=available", "--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		NatGateways []struct {
+			NatGatewayID string `json:"NatGatewayId"`
+			VpcID        string `json:"VpcId"`
+		} `json:"NatGateways"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse NAT gateways: %w", err)
+	}
+	var found []orphanedResource
+	end := time.Now().UTC()
+	for _, gw := range resp.NatGateways {
+		req := metricStatisticsRequest{
+			Namespace:  "AWS/NATGateway",
+			MetricName: "BytesOutToDestination",
+			Dimensions: []metricDimension{{Name: "NatGatewayId", Value: gw.NatGatewayID}},
+			Period:     86400,
+			Stat:       "Sum",
+			Window:     orphanNATWindow,
+		}
+		metricRaw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile)
+		if err != nil {
+			continue
+		}
+		points, err := decodeMetricDatapoints(metricRaw, req)
+		if err != nil {
+			continue
+		}
+		total := 0.0
+		for _, p := range points {
+			total += p.Value
+		}
+		if total == 0 {
+			found = append(found, orphanedResource{ID: gw.NatGatewayID, Detail: fmt.Sprintf("in %s, no traffic in the last %s", gw.VpcID, formatLookback(orphanNATWindow)), Monthly: natGatewayIdleHour * orphanHoursPerMonth})
+		}
+	}
+	return found, nil
+}
+
+func findOldSnapshots(ctx context.Context, c *Client, input map[string]interface{}, profile *AIProfile) ([]orphanedResource, error) {
+	maxAge := orphanSnapshotAge
+	if days, ok := intParam(input, "snapshot_age_days"); ok && days > 0 {
+		maxAge = time.Duration(days) * 24 * time.Hour
+	}
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-snapshots", "--owner-ids", "self", "--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Snapshots []struct {
+			SnapshotID  string    `json:"SnapshotId"`
+			VolumeID    string    `json:"VolumeId"`
+			VolumeSize  float64   `json:"VolumeSize"`
+			StartTime   time.Time `json:"StartTime"`
+			Description string    `json:"Description"`
+		} `json:"Snapshots"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshots: %w", err)
+	}
+	cutoff := time.Now().Add(-maxAge)
+	var found []orphanedResource
+	for _, s := range resp.Snapshots {
+		if s.StartTime.IsZero() || s.StartTime.After(cutoff) {
+			continue
+		}
+		// AMI snapshots are removed by deregistering the image, not here.
+		if strings.Contains(s.Description, "CreateImage") || strings.Contains(s.Description, "Copied for DestinationAmi") {
+			continue
+		}
+		detail := fmt.Sprintf("%.0f GB from %s, taken %s", s.VolumeSize, s.VolumeID, s.StartTime.UTC().Format("2006-01-02"))
+		found = append(found, orphanedResource{ID: s.SnapshotID, Detail: detail + "; saving is an upper bound since snapshots are incremental", Monthly: s.VolumeSize * snapshotGBMonth})
+	}
+	return found, nil
+}
+
+func findEmptyLoadBalancers(ctx context.Context, c *Client, _ map[string]interface{}, profile *AIProfile) ([]orphanedResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"elbv2", "describe-load-balancers", "--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		LoadBalancers []struct {
+			LoadBalancerArn  string `json:"LoadBalancerArn"`
+			LoadBalancerName string `json:"LoadBalancerName"`
+			Type             string `json:"Type"`
+		} `json:"LoadBalancers"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse load balancers: %w", err)
+	}
+	var found []orphanedResource
+	for i, lb := range resp.LoadBalancers {
+		if i == orphanMaxLoadBalancers {
+			break
+		}
+		targets, err := c.loadBalancerTargetCount(ctx, lb.LoadBalancerArn, profile)
+		if err != nil || targets > 0 {
+			continue
+		}
+		found = append(found, orphanedResource{ID: lb.LoadBalancerName, Detail: lb.Type + " with no registered targets", Monthly: loadBalancerIdleHour * orphanHoursPerMonth})
+	}
+	return found, nil
+}
+
+// loadBalancerTargetCount counts registered targets across the load
+// balancer's target groups.
+func (c *Client) loadBalancerTargetCount(ctx context.Context, lbArn string, profile *AIProfile) (int, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"elbv2", "describe-target-groups", "--load-balancer-arn", lbArn, "--output", "json"}, profile)
+	if err != nil {
+		return 0, err
+	}
+	var groups struct {
+		TargetGroups []struct {
+			TargetGroupArn string `json:"TargetGroupArn"`
+		} `json:"TargetGroups"`
+	}
+	if err := json.Unmarshal([]byte(raw), &groups); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, tg := range groups.TargetGroups {
+		healthRaw, err := c.execAWSCLI(ctx, []string{"elbv2", "describe-target-health", "--target-group-arn", tg.TargetGroupArn, "--output", "json"}, profile)
+		if err != nil {
+			return 0, err
+		}
+		var health struct {
+			TargetHealthDescriptions []json.RawMessage `json:"TargetHealthDescriptions"`
+		}
+		if err := json.Unmarshal([]byte(healthRaw), &health); err != nil {
+			return 0, err
+		}
+		count += len(health.TargetHealthDescriptions)
+	}
+	return count, nil
+}
+
+// stoppedSincePattern pulls the date out of a StateTransitionReason such as
+// "User initiated (2024-01-15 10:00:00 GMT)".
+var stoppedSincePattern = regexp.MustCompile(`\((\d{4}-\d{2}-\d{2})`)
+
+func findStoppedInstanceVolumes(ctx context.Context, c *Client, _ map[string]interface{}, profile *AIProfile) ([]orphanedResource, error) {
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-instances", "--filters", "Name=instance-state-name,Values=stopped", "--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Reservations []struct {
+			Instances []struct {
+				InstanceID            string `json:"InstanceId"`
+				InstanceType          string `json:"InstanceType"`
+				StateTransitionReason string `json:"StateTransitionReason"`
+			} `json:"Instances"`
+		} `json:"Reservations"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse instances: %w", err)
+	}
+	var ids []string
+	details := make(map[string]string)
+	for _, r := range resp.Reservations {
+		for _, inst := range r.Instances {
+			ids = append(ids, inst.InstanceID)
+			detail := inst.InstanceType + " stopped"
+			if m := stoppedSincePattern.FindStringSubmatch(inst.StateTransitionReason); m != nil {
+				detail += " since " + m[1]
+			}
+			details[inst.InstanceID] = detail
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	volumes, err := c.describeVolumes(ctx, "Name=attachment.instance-id,Values="+strings.Join(ids, ","), profile)
+	if err != nil {
+		return nil, err
+	}
+	gb := make(map[string]float64)
+	monthly := make(map[string]float64)
+	for _, v := range volumes {
+		for _, a := range v.Attachments {
+			gb[a.InstanceID] += v.Size
+			monthly[a.InstanceID] += v.monthly()
+		}
+	}
+	var found []orphanedResource
+	for _, id := range ids {
+		if gb[id] == 0 {
+			continue
+		}
+		found = append(found, orphanedResource{ID: id, Detail: fmt.Sprintf("%s, %.0f GB EBS still billed", details[id], gb[id]), Monthly: monthly[id]})
+	}
+	return found, nil
+}
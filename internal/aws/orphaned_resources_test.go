@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFindOrphanedResources(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["ec2 describe-volumes --filters Name=status,Values=available"] = `{"Volumes": [
+		{"VolumeId": "vol-big", "Size": 500, "VolumeType": "gp2", "CreateTime": "2023-02-01T00:00:00Z"},
+		{"VolumeId": "vol-small", "Size": 8, "VolumeType": "gp3"}
+	]}`
+	f.fixtures["ec2 describe-addresses"] = `{"Addresses": [
+		{"AllocationId": "eipalloc-used", "PublicIp": "3.3.3.3", "AssociationId": "eipassoc-1"},
+		{"AllocationId": "eipalloc-free", "PublicIp": "4.4.4.4"}
+	]}`
+	f.fixtures["ec2 describe-nat-gateways"] = `{"NatGateways": [{"NatGatewayId": "nat-idle", "VpcId": "vpc-1"}, {"NatGatewayId": "nat-busy", "VpcId": "vpc-1"}]}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/NATGateway"] = `{"Datapoints": []}`
+	f.fixtures["ec2 describe-snapshots"] = `{"Snapshots": [
+		{"SnapshotId": "snap-old", "VolumeId": "vol-gone", "VolumeSize": 100, "StartTime": "2022-01-01T00:00:00Z", "Description": "nightly"},
+		{"SnapshotId": "snap-ami", "VolumeSize": 100, "StartTime": "2022-01-01T00:00:00Z", "Description": "Created by CreateImage(i-1) for ami-1"}
+	]}`
+	f.failures["elbv2 describe-load-balancers"] = errors.New("AccessDenied: not allowed")
+	f.fixtures["ec2 describe-instances"] = `{"Reservations": [{"Instances": [{"InstanceId": "i-stopped", "InstanceType": "m5.large", "StateTransitionReason": "User initiated (2024-01-15 10:00:00 GMT)"}]}]}`
+	f.fixtures["ec2 describe-volumes --filters Name=attachment.instance-id,Values=i-stopped"] = `{"Volumes": [{"VolumeId": "vol-root", "Size": 50, "VolumeType": "gp3", "Attachments": [{"InstanceId": "i-stopped"}]}]}`
+	c := newFakeClient(f)
+	c.execFunc = func(ctx context.Context, args []string, profile *AIProfile) (string, error) {
+		if strings.Contains(strings.Join(args, " "), "Value=nat-busy") {
+			return `{"Datapoints": [{"Timestamp": "2024-05-01T00:00:00Z", "Sum": 1048576}]}`, nil
+		}
+		return f.exec(ctx, args, profile)
+	}
+
+	out, err := c.executeAWSOperation(context.Background(), "find_orphaned_resources", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Estimated savings: ~$96.14/month",
+		"Unattached EBS volumes (2, ~$50.64/month):\n  • vol-big: 500 GB gp2, unattached, created 2023-02-01 (~$50.00/month)",
+		"eipalloc-free: 4.4.4.4 not associated (~$3.65/month)",
+		"nat-idle: in vpc-1, no traffic in the last 7d (~$32.85/month)",
+		"snap-old: 100 GB from vol-gone, taken 2022-01-01",
+		"i-stopped: m5.large stopped since 2024-01-15, 50 GB EBS still billed (~$4.00/month)",
+		"Load balancers with no targets: ❌ ELBv2 service access denied",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"eipalloc-used", "nat-busy", "snap-ami"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output should not list %s:\n%s", unwanted, out)
+		}
+	}
+}
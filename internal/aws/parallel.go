@@ -3,21 +3,33 @@ package aws
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/bgdnvk/clanker/internal/logging"
 	"github.com/spf13/viper"
 )
 
 // executeOperationsWithProfile executes operations with a given profile
 func (c *Client) executeOperationsWithProfile(ctx context.Context, operations []LLMOperation, profile *AIProfile) (string, error) {
+	return formatOperationResults(c.runOperations(ctx, operations, profile, nil)), nil
+}
+
+// RunOperations executes operations concurrently with profile and returns
+// each result in the original order, for callers that judge results one by
+// one instead of reading the formatted report.
+func (c *Client) RunOperations(ctx context.Context, operations []LLMOperation, profile *AIProfile) []LLMOperationResult {
+	return c.runOperations(ctx, operations, profile, nil)
+}
+
+// runOperations executes operations concurrently and returns their results in
+// the original order. onResult, when set, is called from the collecting
+// goroutine as each operation finishes.
+func (c *Client) runOperations(ctx context.Context, operations []LLMOperation, profile *AIProfile, onResult func(LLMOperationResult)) []LLMOperationResult {
 	// Check if local rate limiting is enabled (default: true)
 	localMode := viper.GetBool("local_mode")
 	delayMs := viper.GetInt("local_delay_ms")
-	verbose := viper.GetBool("debug")
-
 	// Default to local mode if not explicitly set
 	if !viper.IsSet("local_mode") {
 		localMode = true
@@ -28,24 +40,24 @@ func (c *Client) executeOperationsWithProfile(ctx context.Context, operations []
 		delayMs = 100 // 100ms delay between calls by default
 	}
 
-	if verbose {
-		log.Printf("🔧 Parallel execution: %d operations, local_mode=%v, delay=%dms", len(operations), localMode, delayMs)
-	}
+	logging.Verbosef("🔧 Parallel execution: %d operations, local_mode=%v, delay=%dms, max_concurrent_cli=%d", len(operations), localMode, delayMs, awsCLIMaxConcurrent())
 
 	// Create channels for results
 	resultChan := make(chan LLMOperationResult, len(operations))
 	var wg sync.WaitGroup
 
 	// Execute all operations concurrently (or with rate limiting in local mode)
+	// Subprocess concurrency is capped separately by aws.max_concurrent_cli.
 	for i, op := range operations {
 		wg.Add(1)
 
 		// Add delay for local mode to prevent system overload
-		if localMode && i > 0 {
-			if verbose {
-				log.Printf("⏱️  Local mode delay: %dms before operation %d/%d: %s", delayMs, i+1, len(operations), op.Operation)
+		if localMode && i > 0 && ctx.Err() == nil {
+			logging.Debugf("⏱️  Local mode delay: %dms before operation %d/%d: %s", delayMs, i+1, len(operations), op.Operation)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(delayMs) * time.Millisecond):
 			}
-			time.Sleep(time.Duration(delayMs) * time.Millisecond)
 		}
 
 		go func(index int, operation string, params map[string]interface{}) {
@@ -63,20 +75,16 @@ func (c *Client) executeOperationsWithProfile(ctx context.Context, operations []
 			default:
 			}
 
-			if verbose {
-				log.Printf("Starting operation %d: %s", index+1, operation)
-			}
+			logging.Verbosef("Starting operation %d: %s", index+1, operation)
 
 			start := time.Now()
 			result, err := c.executeAWSOperation(ctx, operation, params, profile)
 			duration := time.Since(start)
 
-			if verbose {
-				if err != nil {
-					log.Printf("❌ Operation %d failed (%v): %s - %v", index+1, duration, operation, err)
-				} else {
-					log.Printf("✅ Operation %d completed (%v): %s", index+1, duration, operation)
-				}
+			if err != nil {
+				logging.Verbosef("❌ Operation %d failed (%v): %s - %v", index+1, duration, operation, err)
+			} else {
+				logging.Verbosef("✅ Operation %d completed (%v): %s", index+1, duration, operation)
 			}
 
 			resultChan <- LLMOperationResult{
@@ -98,9 +106,15 @@ func (c *Client) executeOperationsWithProfile(ctx context.Context, operations []
 	results := make([]LLMOperationResult, len(operations))
 	for result := range resultChan {
 		results[result.Index] = result
+		if onResult != nil {
+			onResult(result)
+		}
 	}
+	return results
+}
 
-	// Build results string in original order
+// formatOperationResults renders operation results in order for the LLM.
+func formatOperationResults(results []LLMOperationResult) string {
 	var awsResults strings.Builder
 	for _, result := range results {
 		if result.Error != nil {
@@ -110,7 +124,7 @@ func (c *Client) executeOperationsWithProfile(ctx context.Context, operations []
 		}
 	}
 
-	return awsResults.String(), nil
+	return awsResults.String()
 }
 
 // ExecuteOperationsConcurrently executes multiple AWS operations concurrently for LLM processing
@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 func TestExecuteOperationsConcurrently_EmptyOperations(t *testing.T) {
@@ -55,3 +56,32 @@ func TestExecuteOperationsWithProfile_CancelledContext(t *testing.T) {
 		t.Error("expected non-empty result string with error info")
 	}
 }
+
+func TestCLISemaphore_CapsAndRespectsCancellation(t *testing.T) {
+	sem := newCLISemaphore(2)
+	ctx := context.Background()
+	if err := sem.acquire(ctx); err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	if err := sem.acquire(ctx); err != nil {
+		t.Fatalf("second acquire: %v", err)
+	}
+
+	full, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := sem.acquire(full); err == nil {
+		t.Fatal("expected acquire to fail while all slots are taken")
+	}
+
+	sem.release()
+	if err := sem.acquire(ctx); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+
+	cancelled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+	sem.release()
+	if err := sem.acquire(cancelled); err == nil {
+		t.Fatal("expected a cancelled context to never take a slot")
+	}
+}
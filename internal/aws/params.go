@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"strconv"
+	"strings"
+)
+
+// getStringParam reads a string parameter, trimmed. JSON numbers are
+// formatted (models sometimes send IDs unquoted); a missing, empty or
+// otherwise-typed value yields def.
+func getStringParam(input map[string]interface{}, key, def string) string {
+	switch v := input[key].(type) {
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			return s
+		}
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	}
+	return def
+}
+
+// stringListParam reads a list parameter from a JSON array, or from a
+// string split on whitespace outside single or double quotes.
+func stringListParam(input map[string]interface{}, key string) []string {
+	switch v := input[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item := item.(type) {
+			case string:
+				out = append(out, item)
+			case float64:
+				out = append(out, strconv.FormatFloat(item, 'f', -1, 64))
+			case bool:
+				out = append(out, strconv.FormatBool(item))
+			}
+		}
+		return out
+	case string:
+		return splitQuotedFields(v)
+	}
+	return nil
+}
+
+func splitQuotedFields(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	var quote rune
+	inField := false
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inField = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inField {
+				fields = append(fields, cur.String())
+				cur.Reset()
+				inField = false
+			}
+		default:
+			cur.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+// boolParam reads a boolean parameter, accepting JSON booleans and "true".
+func boolParam(input map[string]interface{}, key string) bool {
+	switch v := input[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return err == nil && b
+	default:
+		return false
+	}
+}
+
+// intParam reads an integer parameter from JSON numbers or numeric strings.
+func intParam(input map[string]interface{}, key string) (int, bool) {
+	switch v := input[key].(type) {
+	case int:
+		return v, true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
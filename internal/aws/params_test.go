@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGetStringParam(t *testing.T) {
+	input := map[string]interface{}{
+		"name":    "  web  ",
+		"blank":   "   ",
+		"account": float64(123456789012),
+		"count":   3,
+		"list":    []interface{}{"a"},
+	}
+	tests := []struct {
+		key, def, want string
+	}{
+		{"name", "", "web"},
+		{"blank", "default", "default"},
+		{"missing", "default", "default"},
+		{"account", "", "123456789012"},
+		{"count", "", "3"},
+		{"list", "fallback", "fallback"},
+	}
+	for _, tt := range tests {
+		if got := getStringParam(input, tt.key, tt.def); got != tt.want {
+			t.Errorf("getStringParam(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestListBoolAndIntParams(t *testing.T) {
+	input := map[string]interface{}{
+		"args":    `--filter "Name=tag:env" 'a b'`,
+		"ids":     []interface{}{"i-1", float64(2), true},
+		"dry_run": " true ",
+		"force":   true,
+		"count":   float64(4),
+		"half":    float64(1.5),
+		"limit":   "25",
+	}
+	if got := stringListParam(input, "args"); strings.Join(got, "|") != "--filter|Name=tag:env|a b" {
+		t.Errorf("stringListParam(args) = %q", got)
+	}
+	if got := stringListParam(input, "ids"); strings.Join(got, "|") != "i-1|2|true" {
+		t.Errorf("stringListParam(ids) = %q", got)
+	}
+	if !boolParam(input, "dry_run") || !boolParam(input, "force") || boolParam(input, "missing") {
+		t.Error("boolParam should accept JSON booleans and \"true\" only")
+	}
+	if n, ok := intParam(input, "count"); !ok || n != 4 {
+		t.Errorf("intParam(count) = %d, %t", n, ok)
+	}
+	if n, ok := intParam(input, "limit"); !ok || n != 25 {
+		t.Errorf("intParam(limit) = %d, %t", n, ok)
+	}
+	if _, ok := intParam(input, "half"); ok {
+		t.Error("intParam should reject fractional numbers")
+	}
+}
+
+func TestMalformedRequiredParamsReturnErrors(t *testing.T) {
+	c := newFakeClient(newFakeCLI())
+	profile := &AIProfile{}
+	ops := map[string]string{
+		"describe_instance":        "instance_id",
+		"analyze_ecs_service_logs": "service_name",
+		"get_ecs_task_logs":        "task_arn",
+		"describe_lambda_function": "function_name",
+		"describe_dynamodb_table":  "table_name",
+		"describe_sqs_queue":       "queue_url",
+	}
+	for op, key := range ops {
+		for _, value := range []interface{}{nil, "", map[string]interface{}{"x": 1}, []interface{}{"a"}} {
+			_, err := c.executeAWSOperation(context.Background(), op, map[string]interface{}{key: value}, profile)
+			if err == nil || !strings.Contains(err.Error(), key+" parameter required") {
+				t.Errorf("%s with %s=%#v: err = %v, want %s parameter required", op, key, value, err, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// AWS partitions. Each has its own ARN prefix, endpoints and service catalog.
+const (
+	PartitionAWS      = "aws"
+	PartitionGovCloud = "aws-us-gov"
+	PartitionChina    = "aws-cn"
+)
+
+// partitionUnavailableServices lists well-known services (by CLI or check
+// name) that do not exist outside the commercial partition. It is not
+// exhaustive; aws.partition_unavailable_services.<partition> adds more.
+var partitionUnavailableServices = map[string][]string{
+	PartitionGovCloud: {"lightsail", "apprunner", "amplify", "cloudfront", "globalaccelerator", "route53domains", "gamelift", "braket", "ivs", "chime"},
+	PartitionChina:    {"lightsail", "apprunner", "route53domains", "bedrock", "qbusiness", "braket", "ivs", "chime"},
+}
+
+// PartitionForRegion returns the partition a region belongs to.
+func PartitionForRegion(region string) string {
+	region = strings.ToLower(strings.TrimSpace(region))
+	switch {
+	case strings.HasPrefix(region, "us-gov-"):
+		return PartitionGovCloud
+	case strings.HasPrefix(region, "cn-"):
+		return PartitionChina
+	default:
+		return PartitionAWS
+	}
+}
+
+// EffectivePartition returns the profile's explicit partition, or the one
+// its region belongs to.
+func (p *AIProfile) EffectivePartition() string {
+	if p == nil {
+		return PartitionAWS
+	}
+	if partition := strings.ToLower(strings.TrimSpace(p.Partition)); partition != "" {
+		return partition
+	}
+	return PartitionForRegion(p.Region)
+}
+
+// ARN builds an ARN in the given partition; region and account may be empty
+// for global resources.
+func ARN(partition, service, region, accountID, resource string) string {
+	if partition == "" {
+		partition = PartitionAWS
+	}
+	return fmt.Sprintf("arn:%s:%s:%s:%s:%s", partition, service, region, accountID, resource)
+}
+
+// ManagedPolicyARN returns the ARN of an AWS managed policy such as
+// "service-role/AWSLambdaBasicExecutionRole" in the given partition.
+func ManagedPolicyARN(partition, policyPath string) string {
+	return ARN(partition, "iam", "", "aws", "policy/"+strings.TrimPrefix(policyPath, "/"))
+}
+
+// ManagedPolicyPath returns the policy path of an AWS managed policy ARN in
+// any partition, e.g. "AdministratorAccess" for
+// arn:aws-us-gov:iam::aws:policy/AdministratorAccess.
+func ManagedPolicyPath(arn string) (string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(arn), ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || !isPartition(parts[1]) || parts[2] != "iam" || parts[4] != "aws" {
+		return "", false
+	}
+	path, ok := strings.CutPrefix(parts[5], "policy/")
+	return path, ok && path != ""
+}
+
+func isPartition(partition string) bool {
+	switch partition {
+	case PartitionAWS, PartitionGovCloud, PartitionChina:
+		return true
+	}
+	return strings.HasPrefix(partition, "aws-")
+}
+
+// ServiceAvailableInPartition reports whether service (a CLI service name or
+// check name such as "lightsail") exists in partition.
+func ServiceAvailableInPartition(service, partition string) bool {
+	service = strings.ToLower(strings.TrimSpace(service))
+	unavailable := append([]string(nil), partitionUnavailableServices[partition]...)
+	unavailable = append(unavailable, viper.GetStringSlice("aws.partition_unavailable_services."+partition)...)
+	for _, name := range unavailable {
+		if strings.EqualFold(strings.TrimSpace(name), service) {
+			return false
+		}
+	}
+	return true
+}
+
+// partitionUnavailableError reports a call to a service that does not exist
+// in the profile's partition.
+type partitionUnavailableError struct {
+	Service   string
+	Partition string
+	Region    string
+}
+
+func (e *partitionUnavailableError) Error() string {
+	return fmt.Sprintf("%s is not available in the %s partition (region %s)", e.Service, e.Partition, e.Region)
+}
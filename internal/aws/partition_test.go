@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestPartitionForRegion(t *testing.T) {
+	cases := map[string]string{
+		"us-east-1":     PartitionAWS,
+		"us-gov-west-1": PartitionGovCloud,
+		"cn-north-1":    PartitionChina,
+		"":              PartitionAWS,
+	}
+	for region, want := range cases {
+		if got := PartitionForRegion(region); got != want {
+			t.Errorf("PartitionForRegion(%q) = %q, want %q", region, got, want)
+		}
+	}
+	if got := (&AIProfile{Region: "us-east-1", Partition: "aws-cn"}).EffectivePartition(); got != PartitionChina {
+		t.Errorf("explicit partition should win, got %q", got)
+	}
+}
+
+func TestManagedPolicyARNRoundTrip(t *testing.T) {
+	arn := ManagedPolicyARN(PartitionGovCloud, "service-role/AWSLambdaBasicExecutionRole")
+	if arn != "arn:aws-us-gov:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole" {
+		t.Fatalf("unexpected ARN %q", arn)
+	}
+	if path, ok := ManagedPolicyPath(arn); !ok || path != "service-role/AWSLambdaBasicExecutionRole" {
+		t.Errorf("ManagedPolicyPath = %q, %v", path, ok)
+	}
+	if _, ok := ManagedPolicyPath("arn:aws:iam::123456789012:policy/custom"); ok {
+		t.Error("customer managed policies are not AWS managed")
+	}
+}
+
+func TestServiceAvailableInPartition(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	if ServiceAvailableInPartition("lightsail", PartitionGovCloud) {
+		t.Error("lightsail should be unavailable in GovCloud")
+	}
+	if !ServiceAvailableInPartition("lightsail", PartitionAWS) {
+		t.Error("lightsail should be available in the commercial partition")
+	}
+	viper.Set("aws.partition_unavailable_services.aws-cn", []string{"kendra"})
+	if ServiceAvailableInPartition("kendra", PartitionChina) {
+		t.Error("configured services should be treated as unavailable")
+	}
+}
+
+func TestUnavailableServiceInGovCloud(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	f := newFakeCLI()
+	f.fixtures["lightsail"] = `{"instances": []}`
+	c := newFakeClient(f)
+	profile := &AIProfile{Region: "us-gov-west-1"}
+
+	_, err := c.execAWSCLI(context.Background(), []string{"lightsail", "get-instances"}, profile)
+	if err == nil {
+		t.Fatal("expected a partition error")
+	}
+	if msg := categorizeAWSError(err, "Lightsail"); !strings.Contains(msg, "not offered in the aws-us-gov partition") {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	out, err := c.executeAWSOperation(context.Background(), "check_lightsail_service", map[string]interface{}{}, profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "lightsail is not offered in the aws-us-gov partition (region us-gov-west-1)") {
+		t.Errorf("unexpected output %q", out)
+	}
+}
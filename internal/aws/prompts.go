@@ -13,11 +13,14 @@ Available AWS READ-ONLY operations (all are safe and never modify/delete anythin
 INFRASTRUCTURE DISCOVERY (New Enhanced Operations):
 - discover_all_active_services: Automatically discover all active AWS services by running service checks in parallel
 - get_infrastructure_overview: Get a comprehensive overview of the entire infrastructure across all services
+- get_account_summary: One-page account dashboard: resource counts per service, month-to-date and estimated monthly cost, alarms in ALARM, public S3 buckets, world-open security groups and public RDS instances, and resources changed in the last week
+- compare_environments: Diff two environments (e.g. staging and prod): Lambda functions, ECS services, EC2 instances, RDS instances and security groups present in only one, and differing settings such as memory, instance class, engine version or ingress rules, grouped by service (params: environments as two names from infra.aws.environments or AWS CLI profiles; or query to take the configured environment names it mentions)
 - check_all_services_parallel: Run all service availability checks in parallel to map the infrastructure
 
 TERRAFORM INTEGRATION:
 - get_terraform_outputs: Get terraform outputs from the configured workspace
 - get_terraform_state_summary: Get a summary of terraform state resources
+- get_terraform_plan_diff: Run terraform plan and list resources to create, update, replace and destroy
 
 SERVICE EXISTENCE CHECKS (Quick checks to see if services exist and their basic counts):
 - check_sqs_service: Check if SQS service is available and count queues
@@ -40,11 +43,13 @@ SERVICE EXISTENCE CHECKS (Quick checks to see if services exist and their basic
 COMPUTE:
 - list_ec2_instances: List EC2 instances with state, type, and details
 - describe_instance: Get detailed info about a specific EC2 instance
+- analyze_ec2_health: Diagnose an EC2 instance that is down or unreachable: failed system/instance/EBS status checks, stops and terminations caused by AWS (Spot interruption, insufficient capacity) with the state reason, and pending scheduled maintenance or retirement events (params: instance_id for one instance; or query to check the instances whose ID or Name tag it mentions; checks every instance otherwise)
 - list_ecs_clusters: List ECS clusters and their running services/tasks
 - describe_ecs_service: Get details about a specific ECS service
 - list_batch_jobs: List AWS Batch jobs and their status
 - list_auto_scaling_groups: List Auto Scaling Groups with instance counts and capacity
 - describe_auto_scaling_group: Get detailed ASG configuration and instances
+- analyze_asg_activity: Why Auto Scaling groups are not reaching desired capacity: in-service vs desired instances, failed launches over the last 24 hours (or the investigation window) grouped by reason such as insufficient capacity or an invalid launch template, and target groups marking instances unhealthy in a launch/terminate loop. Prefer it over describe_auto_scaling_group when scaling is not working (params: optional asg_name; or query to match group names)
 - list_launch_templates: List EC2 Launch Templates and their versions
 - describe_launch_template: Get detailed Launch Template configuration
 
@@ -52,16 +57,21 @@ SERVERLESS:
 - list_lambda_functions: List Lambda functions with runtime and last modified
 - describe_lambda_function: Get detailed config for a specific Lambda function
 - list_lambda_layers: List Lambda layers available
+- analyze_lambda_performance: Parse a function's REPORT log lines into p50/p95/p99 duration, cold-start rate and init duration, and memory usage with a suggested memory size (params: function_name; window defaults to the last 24h)
+- audit_lambda_config: Audit Lambda configuration against observed use: timeout vs max duration, memory vs peak usage, missing dead-letter queue or on-failure destination, reserved and provisioned concurrency, and deprecated or end-of-life runtimes, each with a recommendation (params: function_name; or query to audit the functions it names; audits the first 10 functions otherwise)
 
 CONTAINER SERVICES:
 - list_ecr_repositories: List ECR repositories with URIs and creation dates
 - describe_ecr_repository: Get images and details for a specific ECR repository
+- analyze_ecr_image_scan: Summarize ECR scan findings by severity with the top CVEs and packages (parameters: repository_name plus image_tag or image_digest, or image as a full URI; without them checks the ECR images of running ECS services and EKS pods)
 - list_eks_clusters: List EKS Kubernetes clusters with status and details
 - describe_eks_cluster: Get detailed EKS cluster configuration
+- describe_eks_workloads: Inspect pods in an EKS cluster with kubectl and report crash reasons and restart counts (params: cluster_name)
 
 STORAGE:
 - list_s3_buckets: List S3 buckets with creation dates and regions
 - describe_s3_bucket: Get details about a specific S3 bucket (size, objects, etc.)
+- audit_s3_bucket: Audit S3 public exposure and data protection: Block Public Access (bucket and account), bucket policy statements granting "*" access, ACL grants to AllUsers/AuthenticatedUsers, default encryption and versioning, listing publicly readable or writable buckets first (params: bucket_name; audits every bucket otherwise)
 - list_ebs_volumes: List EBS volumes and their attachments, size, type
 - describe_ebs_volume: Get detailed info about a specific EBS volume
 - list_efs_filesystems: List EFS file systems with performance modes
@@ -70,35 +80,47 @@ STORAGE:
 DATABASE:
 - list_rds_instances: List RDS database instances with status and config
 - describe_rds_instance: Get detailed info about a specific RDS instance
+- analyze_rds_performance: Diagnose a slow database or "too many connections": last hour of CPU, connections vs max_connections, freeable memory and read/write latency, plus top Performance Insights waits when enabled (params: optional instance_id, or query to analyze the instances it mentions)
 - list_rds_clusters: List RDS Aurora clusters with engine and status
 - list_dynamodb_tables: List DynamoDB tables
 - describe_dynamodb_table: Get detailed DynamoDB table schema and settings
+- analyze_dynamodb_throttling: Explain DynamoDB throttling: read/write throttle events and peak consumed vs provisioned (or on-demand maximum) capacity for a table and each GSI, naming the throttled index and likely hot partitions (params: table_name, or query to analyze the tables it mentions)
 
 NETWORKING:
 - list_vpcs: List VPCs and their CIDR blocks
 - list_subnets: List subnets across VPCs
 - list_security_groups: List security groups and their rules
 - describe_load_balancers: List and describe load balancers (ALB/NLB/CLB)
+- analyze_alb_errors: Correlate an ALB's 5xx over the last hour (or the investigation window) with target health and name unhealthy targets with their reason codes (params: load_balancer as ARN or name; optional when there is only one ALB)
 - list_route_tables: List route tables and their routes
+- analyze_connectivity: Explain why traffic is blocked: walks source security group egress, source subnet NACL, route table and NAT gateway, target NACL and security group ingress, and the NACL return path, and reports the first blocking hop (params: source as instance ID, ENI ID or Name tag; target as "internet" (default), an RDS instance identifier or endpoint, or a security group ID; optional port, defaulting to the database port or 443; or query to take the source and target IDs it mentions)
 
 MESSAGE QUEUING & EVENTS:
 - list_sqs_queues: List SQS queues with URLs and attributes
 - describe_sqs_queue: Get detailed SQS queue configuration and metrics
 - list_sns_topics: List SNS topics and their ARNs
 - describe_sns_topic: Get SNS topic configuration and subscriptions
+- analyze_queue_health: Find stuck or backed-up queues: SQS queues whose dead-letter queue holds messages or whose oldest message is older than a threshold, and SNS topics with failed deliveries (params: queue_url or queue_name for one queue, topic_arn for one topic, age_threshold_minutes default 15, query to check the queues it names)
 - list_eventbridge_rules: List EventBridge rules with schedules and targets
 - list_eventbridge_buses: List custom EventBridge event buses
 
 MONITORING & LOGS:
-- get_recent_logs: Get recent CloudWatch logs and errors
+- get_recent_logs: Get recent CloudWatch logs and errors (params: log_group_name, optional filter_pattern, limit, hours_back)
 - list_cloudwatch_alarms: List CloudWatch alarms and their status
+- analyze_active_alarms: The alarms currently in ALARM with when they tripped, how often they flapped, and the metric's current value against the threshold; INSUFFICIENT_DATA alarms listed separately as possibly misconfigured. Prefer it over list_cloudwatch_alarms for "what's alarming" (params: optional alarm_name_prefix)
 - describe_cloudwatch_metrics: Get CloudWatch metrics for resources
+- get_xray_traces: Slow, erroring and faulting X-Ray traces over the last hour (or the investigation window, capped at X-Ray's 6 hour limit), the segments of the slowest ones, and which downstream calls (DynamoDB, RDS, HTTP services) took most of the request time. Use it for slow requests and latency when X-Ray is enabled (params: optional service, min_latency_seconds (default 1), max_traces)
+- get_metric_statistics: Fetch recent datapoints and a min/max/avg summary for one metric (params: namespace, metric_name, dimensions, period, stat such as Average, Maximum, Sum or p99)
 - list_log_groups: List CloudWatch log groups
+- get_service_quotas: List a service's quotas with peak usage over the last hour as a percentage of each quota, closest to the limit first (params: service_code such as lambda, ec2, vpc, dynamodb; optional quota_name substring filter)
+Log and metric operations also accept start_time and end_time (RFC3339, or a lookback such as 2h or 3d) to look at a past incident; otherwise they follow the investigation window.
 
 SECURITY & IAM:
 - list_iam_roles: List IAM roles (names only, no sensitive data)
+- analyze_iam_role: Rank a role's risky grants from its attached and inline policies: "*" actions or resources, wildcard iam:PassRole, admin-equivalent managed policies (params: role_name, or query to analyze the roles it mentions)
 - list_iam_groups: List IAM groups (names only, no sensitive data)
 - list_iam_users: List IAM users (names only, no sensitive data)
+- get_security_findings: Active GuardDuty and Security Hub findings, deduplicated and summarized by severity with the top finding types and most affected resources; possible compromises such as crypto-mining or exposed credentials are listed first. Prefer it over the check_guardduty_service and check_securityhub_service availability checks for security posture questions
 - describe_security_groups: Get security group rules and associations
 - list_kms_keys: List KMS encryption keys
 - describe_kms_key: Get KMS key details and policies
@@ -111,10 +133,12 @@ DEVOPS & CI/CD:
 - list_codepipelines: List CodePipeline pipelines with status
 - describe_codepipeline: Get detailed pipeline configuration and stages
 - list_codecommit_repositories: List CodeCommit Git repositories
+- detect_stack_drift: Run CloudFormation drift detection and list MODIFIED and DELETED resources with expected vs actual property values, for manual changes made outside the template (params: stack_name; or query to check the stacks it names; checks every active stack otherwise)
 
 ANALYTICS & BIG DATA:
 - list_kinesis_streams: List Kinesis data streams
 - describe_kinesis_stream: Get Kinesis stream shards and throughput
+- analyze_kinesis_lag: Find streaming consumers falling behind: Kinesis iterator age (high or growing), read/write throttling that points at under-sharding, and Firehose delivery failures or backlog to the destination (params: stream_name for one Kinesis stream, delivery_stream_name for one Firehose stream, iterator_age_threshold_seconds default 60, query to check the streams it names)
 - list_glue_jobs: List AWS Glue ETL jobs and schedules
 - list_glue_databases: List Glue Data Catalog databases
 - list_emr_clusters: List EMR big data clusters
@@ -131,9 +155,12 @@ CACHING:
 APPLICATION INTEGRATION:
 - list_step_functions: List Step Functions state machines
 - describe_step_function: Get Step Function workflow definition
+- analyze_stepfunctions_failures: Failed executions per state machine over the last 24 hours (or the investigation window), the failing state with its error and cause for the newest ones, and the state that fails most often. Prefer it over describe_step_function when a workflow is failing (params: state_machine_name or state_machine_arn, optional max_executions; or query to match state machine names)
 
 COST & BILLING:
-- get_cost_and_usage: Get cost information and usage metrics
+- get_cost_and_usage: Get cost information and usage metrics (params: group_by "SERVICE" for month-to-date spend per service)
+- get_cost_by_tag: Month-to-date spend per value of a cost allocation tag, highest first (params: tag_key such as Environment or Team)
+- find_orphaned_resources: Read-only sweep for waste with estimated monthly savings per resource: unattached EBS volumes, unassociated Elastic IPs, NAT gateways with no traffic in 7 days, old snapshots, load balancers with no targets, and stopped instances still paying for EBS (params: snapshot_age_days default 90)
 - list_budgets: List AWS Budgets and spending alerts
 
 AI/ML SERVICES:
@@ -151,11 +178,13 @@ AI/ML SERVICES:
 
 OTHER SERVICES:
 - list_api_gateways: List API Gateway REST and HTTP APIs
+- analyze_apigw_errors: Sum 4xx, 5xx and requests per REST or HTTP API over the last 3 hours (or the investigation window), name the noisiest failing route and say whether latency is in the integration or the gateway (params: api_name or api_id, optional stage; or query to match API names)
 - list_cloudfront_distributions: List CloudFront distributions
 - list_route53_zones: List Route53 hosted zones
 - list_secrets: List AWS Secrets Manager secrets (names only)
 - list_ssm_parameters: List Systems Manager parameters (names only)
-
+- raw_aws_query: Run any read-only AWS CLI call no operation above covers; use it only as a last resort (params: service such as ec2 or elbv2, command starting with list-, describe-, get-, head-, lookup-, search- or filter-, args as an array of CLI flags, e.g. ["--filters", "Name=vpc-id,Values=vpc-123"]; mutating commands and secret reads are refused)
+%s
 Respond with ONLY a JSON object in this format:
 {
   "operations": [
@@ -168,5 +197,5 @@ Respond with ONLY a JSON object in this format:
   "analysis": "brief explanation of what the user wants to know"
 }
 
-If no AWS operations are needed, return: {"operations": [], "analysis": "explanation"}`, question)
+If no AWS operations are needed, return: {"operations": [], "analysis": "explanation"}`, question, customOperationsPromptSection())
 }
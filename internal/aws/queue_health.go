@@ -0,0 +1,463 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// queueHealthWindow is how far back SNS delivery failures are counted
+	// unless the investigation window says otherwise.
+	queueHealthWindow = time.Hour
+	// queueAgeThreshold flags queues whose oldest message has waited longer
+	// than this; override with age_threshold_minutes.
+	queueAgeThreshold = 15 * time.Minute
+	queueAgeLookback  = 15 * time.Minute
+	queueMetricPeriod = 300
+	queueHealthMax    = 25
+	topicHealthMax    = 20
+	// topicSubscriptionsMax caps the subscriptions inspected per failing
+	// topic.
+	topicSubscriptionsMax = 10
+)
+
+// sqsQueueHealth is the backlog picture of one queue.
+type sqsQueueHealth struct {
+	Name            string
+	URL             string
+	ARN             string
+	Visible         int
+	InFlight        int
+	Delayed         int
+	DLQArn          string
+	MaxReceiveCount int
+	// OldestAge is ApproximateAgeOfOldestMessage; zero when the queue is
+	// empty or the metric has no recent datapoint.
+	OldestAge time.Duration
+	// Sources are the queues that redrive into this one when it is a DLQ.
+	Sources []string
+}
+
+func (q sqsQueueHealth) isDLQ() bool {
+	return len(q.Sources) > 0
+}
+
+// topicDelivery is the SNS delivery picture of one topic over the window.
+type topicDelivery struct {
+	Name      string
+	ARN       string
+	Failed    float64
+	Delivered float64
+	// Subscriptions describes each subscription as "protocol endpoint",
+	// noting the ones without a redrive policy.
+	Subscriptions []string
+}
+
+// analyzeQueueHealth is the analyze_queue_health operation: it flags SQS
+// queues whose dead-letter queue holds messages or whose oldest message is
+// older than the threshold, and SNS topics with failed deliveries.
+func (c *Client) analyzeQueueHealth(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	threshold := queueAgeThreshold
+	if minutes, ok := intParam(input, "age_threshold_minutes"); ok && minutes > 0 {
+		threshold = time.Duration(minutes) * time.Minute
+	}
+	topicArn := getStringParam(input, "topic_arn", "")
+	queueURL := getStringParam(input, "queue_url", "")
+	queueName := getStringParam(input, "queue_name", "")
+
+	var out strings.Builder
+	out.WriteString("📬 Queue health\n")
+	out.WriteString("============================\n")
+
+	if topicArn == "" {
+		urls, msg := c.queueHealthURLs(ctx, queueURL, queueName, getStringParam(input, "query", ""), profile)
+		if msg != "" {
+			out.WriteString(msg + "\n")
+		} else {
+			queues := c.sqsQueueHealth(ctx, urls, profile)
+			out.WriteString(formatQueueHealth(queues, threshold))
+		}
+	}
+
+	if queueURL == "" && queueName == "" {
+		start, end := operationWindow(ctx, input, queueHealthWindow)
+		if topicArn == "" {
+			out.WriteString("\n")
+		}
+		out.WriteString(c.topicDeliveryHealth(ctx, topicArn, start, end, profile))
+	}
+	return out.String(), nil
+}
+
+// queueHealthURLs resolves the queues to check: the given URL or name, the
+// queues a query names, or every queue up to queueHealthMax. msg is set when
+// there is nothing to check.
+func (c *Client) queueHealthURLs(ctx context.Context, queueURL, queueName, query string, profile *AIProfile) (urls []string, msg string) {
+	if queueURL != "" {
+		return []string{queueURL}, ""
+	}
+	if queueName != "" {
+		url, err := c.sqsQueueURL(ctx, queueName, "", profile)
+		if err != nil {
+			return nil, categorizeAWSError(err, "SQS")
+		}
+		return []string{url}, ""
+	}
+
+	raw, err := c.execAWSCLI(ctx, []string{"sqs", "list-queues", "--output", "json"}, profile)
+	if err != nil {
+		return nil, categorizeAWSError(err, "SQS")
+	}
+	var resp struct {
+		QueueUrls []string `json:"QueueUrls"`
+	}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return nil, fmt.Sprintf("Failed to parse SQS queues: %v", err)
+		}
+	}
+	if len(resp.QueueUrls) == 0 {
+		return nil, "No SQS queues found."
+	}
+
+	names := make([]string, len(resp.QueueUrls))
+	byName := make(map[string]string, len(resp.QueueUrls))
+	for i, url := range resp.QueueUrls {
+		names[i] = sqsQueueNameFromURL(url)
+		byName[names[i]] = url
+	}
+	if matched := rolesReferencedInQuery(names, query); len(matched) > 0 {
+		for _, name := range matched {
+			urls = append(urls, byName[name])
+		}
+		return urls, ""
+	}
+	return limitStrings(resp.QueueUrls, queueHealthMax), ""
+}
+
+// sqsQueueHealth fetches attributes for each queue, follows redrive policies
+// to their dead-letter queues, and reads the oldest message age of queues
+// that hold messages.
+func (c *Client) sqsQueueHealth(ctx context.Context, urls []string, profile *AIProfile) []sqsQueueHealth {
+	var queues []sqsQueueHealth
+	byARN := make(map[string]int)
+	for _, url := range urls {
+		q, err := c.sqsQueueAttributes(ctx, url, profile)
+		if err != nil {
+			continue
+		}
+		byARN[q.ARN] = len(queues)
+		queues = append(queues, q)
+	}
+
+	for i := 0; i < len(queues); i++ {
+		q := queues[i]
+		if q.DLQArn == "" {
+			continue
+		}
+		j, ok := byARN[q.DLQArn]
+		if !ok {
+			name, account := arnResourceName(q.DLQArn), arnAccount(q.DLQArn)
+			url, err := c.sqsQueueURL(ctx, name, account, profile)
+			if err != nil {
+				continue
+			}
+			dlq, err := c.sqsQueueAttributes(ctx, url, profile)
+			if err != nil {
+				continue
+			}
+			j = len(queues)
+			byARN[q.DLQArn] = j
+			queues = append(queues, dlq)
+		}
+		queues[j].Sources = append(queues[j].Sources, q.Name)
+	}
+
+	for i := range queues {
+		if queues[i].Visible+queues[i].InFlight > 0 {
+			queues[i].OldestAge = c.sqsOldestMessageAge(ctx, queues[i].Name, profile)
+		}
+	}
+	return queues
+}
+
+func (c *Client) sqsQueueURL(ctx context.Context, name, account string, profile *AIProfile) (string, error) {
+	args := []string{"sqs", "get-queue-url", "--queue-name", name}
+	if account != "" {
+		args = append(args, "--queue-owner-aws-account-id", account)
+	}
+	raw, err := c.execAWSCLI(ctx, append(args, "--output", "json"), profile)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		QueueURL string `json:"QueueUrl"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return "", fmt.Errorf("failed to parse queue URL for %s: %w", name, err)
+	}
+	return resp.QueueURL, nil
+}
+
+func (c *Client) sqsQueueAttributes(ctx context.Context, url string, profile *AIProfile) (sqsQueueHealth, error) {
+	q := sqsQueueHealth{Name: sqsQueueNameFromURL(url), URL: url}
+	raw, err := c.execAWSCLI(ctx, []string{"sqs", "get-queue-attributes", "--queue-url", url, "--attribute-names", "All", "--output", "json"}, profile)
+	if err != nil {
+		return q, err
+	}
+	var resp struct {
+		Attributes map[string]string `json:"Attributes"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return q, fmt.Errorf("failed to parse attributes of %s: %w", q.Name, err)
+	}
+	attrs := resp.Attributes
+	q.ARN = attrs["QueueArn"]
+	q.Visible, _ = strconv.Atoi(attrs["ApproximateNumberOfMessages"])
+	q.InFlight, _ = strconv.Atoi(attrs["ApproximateNumberOfMessagesNotVisible"])
+	q.Delayed, _ = strconv.Atoi(attrs["ApproximateNumberOfMessagesDelayed"])
+	if policy := attrs["RedrivePolicy"]; policy != "" {
+		var redrive struct {
+			DeadLetterTargetArn string          `json:"deadLetterTargetArn"`
+			MaxReceiveCount     json.RawMessage `json:"maxReceiveCount"`
+		}
+		if json.Unmarshal([]byte(policy), &redrive) == nil {
+			q.DLQArn = redrive.DeadLetterTargetArn
+			q.MaxReceiveCount, _ = strconv.Atoi(strings.Trim(string(redrive.MaxReceiveCount), `"`))
+		}
+	}
+	return q, nil
+}
+
+// sqsOldestMessageAge returns the latest ApproximateAgeOfOldestMessage. The
+// metric is only published by CloudWatch, not by get-queue-attributes.
+func (c *Client) sqsOldestMessageAge(ctx context.Context, name string, profile *AIProfile) time.Duration {
+	req := metricStatisticsRequest{
+		Namespace:  "AWS/SQS",
+		MetricName: "ApproximateAgeOfOldestMessage",
+		Dimensions: []metricDimension{{Name: "QueueName", Value: name}},
+		Period:     queueMetricPeriod,
+		Stat:       "Maximum",
+		Window:     queueAgeLookback,
+	}
+	raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, time.Now().UTC()), profile)
+	if err != nil {
+		return 0
+	}
+	points, _ := decodeMetricDatapoints(raw, req)
+	if len(points) == 0 {
+		return 0
+	}
+	latest := points[0]
+	for _, p := range points[1:] {
+		if p.Timestamp.After(latest.Timestamp) {
+			latest = p
+		}
+	}
+	return time.Duration(latest.Value) * time.Second
+}
+
+// queueFindings flags dead-letter queues holding messages and queues whose
+// oldest message has waited longer than threshold.
+func queueFindings(queues []sqsQueueHealth, threshold time.Duration) []string {
+	var findings []string
+	for _, q := range queues {
+		if q.isDLQ() && q.Visible+q.InFlight > 0 {
+			findings = append(findings, fmt.Sprintf("%s: %d messages in the dead-letter queue of %s; consumers failed to process them, inspect and redrive once fixed", q.Name, q.Visible+q.InFlight, strings.Join(q.Sources, ", ")))
+		}
+		if !q.isDLQ() && q.OldestAge > threshold {
+			findings = append(findings, fmt.Sprintf("%s: oldest message is %s old (threshold %s) with %d visible and %d in flight; consumers are stuck, failing or too slow", q.Name, formatQueueAge(q.OldestAge), formatLookback(threshold), q.Visible, q.InFlight))
+		}
+	}
+	return findings
+}
+
+func formatQueueHealth(queues []sqsQueueHealth, threshold time.Duration) string {
+	var out strings.Builder
+	if len(queues) == 0 {
+		out.WriteString("No SQS queue attributes could be read.\n")
+		return out.String()
+	}
+	findings := queueFindings(queues, threshold)
+	if len(findings) == 0 {
+		out.WriteString(fmt.Sprintf("✅ No dead-letter backlog and no message older than %s across %d queues\n", formatLookback(threshold), len(queues)))
+	}
+	for _, f := range findings {
+		out.WriteString(fmt.Sprintf("⚠️  %s\n", f))
+	}
+
+	out.WriteString("\nSQS queues:\n")
+	for _, q := range queues {
+		line := fmt.Sprintf("  • %s: %d visible, %d in flight", q.Name, q.Visible, q.InFlight)
+		if q.Delayed > 0 {
+			line += fmt.Sprintf(", %d delayed", q.Delayed)
+		}
+		if q.OldestAge > 0 {
+			line += ", oldest " + formatQueueAge(q.OldestAge)
+		}
+		switch {
+		case q.isDLQ():
+			line += "; DLQ for " + strings.Join(q.Sources, ", ")
+		case q.DLQArn != "":
+			line += fmt.Sprintf("; DLQ %s (maxReceiveCount %d)", arnResourceName(q.DLQArn), q.MaxReceiveCount)
+		default:
+			line += "; no DLQ, failing messages retry until retention expires"
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
+
+// topicDeliveryHealth sums NumberOfNotificationsFailed for the topic, or
+// every topic up to topicHealthMax, and lists the subscriptions of topics
+// with failures.
+func (c *Client) topicDeliveryHealth(ctx context.Context, topicArn string, start, end time.Time, profile *AIProfile) string {
+	arns := []string{topicArn}
+	if topicArn == "" {
+		raw, err := c.execAWSCLI(ctx, []string{"sns", "list-topics", "--output", "json"}, profile)
+		if err != nil {
+			return categorizeAWSError(err, "SNS") + "\n"
+		}
+		var resp struct {
+			Topics []struct {
+				TopicArn string `json:"TopicArn"`
+			} `json:"Topics"`
+		}
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return fmt.Sprintf("Failed to parse SNS topics: %v\n", err)
+		}
+		arns = nil
+		for _, t := range resp.Topics {
+			arns = append(arns, t.TopicArn)
+		}
+		if len(arns) == 0 {
+			return "No SNS topics found.\n"
+		}
+		arns = limitStrings(arns, topicHealthMax)
+	}
+
+	var failing []topicDelivery
+	for _, arn := range arns {
+		t := topicDelivery{Name: arnResourceName(arn), ARN: arn}
+		t.Failed = c.topicMetricSum(ctx, t.Name, "NumberOfNotificationsFailed", start, end, profile)
+		if t.Failed == 0 {
+			continue
+		}
+		t.Delivered = c.topicMetricSum(ctx, t.Name, "NumberOfNotificationsDelivered", start, end, profile)
+		t.Subscriptions = c.topicSubscriptions(ctx, arn, profile)
+		failing = append(failing, t)
+	}
+	return formatTopicDelivery(failing, len(arns), windowPhrase(start, end))
+}
+
+func (c *Client) topicMetricSum(ctx context.Context, topic, metric string, start, end time.Time, profile *AIProfile) float64 {
+	window := end.Sub(start)
+	req := metricStatisticsRequest{
+		Namespace:  "AWS/SNS",
+		MetricName: metric,
+		Dimensions: []metricDimension{{Name: "TopicName", Value: topic}},
+		Period:     metricPeriodForWindow(queueMetricPeriod, window),
+		Stat:       "Sum",
+		Window:     window,
+	}
+	raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile)
+	if err != nil {
+		return 0
+	}
+	points, _ := decodeMetricDatapoints(raw, req)
+	total := 0.0
+	for _, p := range points {
+		total += p.Value
+	}
+	return total
+}
+
+// topicSubscriptions describes a topic's subscriptions, flagging the ones
+// with no redrive policy: their failed deliveries are dropped after retries.
+func (c *Client) topicSubscriptions(ctx context.Context, topicArn string, profile *AIProfile) []string {
+	raw, err := c.execAWSCLI(ctx, []string{"sns", "list-subscriptions-by-topic", "--topic-arn", topicArn, "--output", "json"}, profile)
+	if err != nil {
+		return nil
+	}
+	var resp struct {
+		Subscriptions []struct {
+			SubscriptionArn string `json:"SubscriptionArn"`
+			Protocol        string `json:"Protocol"`
+			Endpoint        string `json:"Endpoint"`
+		} `json:"Subscriptions"`
+	}
+	if json.Unmarshal([]byte(raw), &resp) != nil {
+		return nil
+	}
+	var subs []string
+	for i, s := range resp.Subscriptions {
+		if i == topicSubscriptionsMax {
+			subs = append(subs, fmt.Sprintf("… %d more", len(resp.Subscriptions)-i))
+			break
+		}
+		desc := s.Protocol + " " + s.Endpoint
+		if !strings.HasPrefix(s.SubscriptionArn, "arn:") {
+			subs = append(subs, desc+" (pending confirmation)")
+			continue
+		}
+		attrsRaw, err := c.execAWSCLI(ctx, []string{"sns", "get-subscription-attributes", "--subscription-arn", s.SubscriptionArn, "--output", "json"}, profile)
+		if err == nil {
+			var attrs struct {
+				Attributes map[string]string `json:"Attributes"`
+			}
+			if json.Unmarshal([]byte(attrsRaw), &attrs) == nil && attrs.Attributes["RedrivePolicy"] == "" {
+				desc += " (no DLQ)"
+			}
+		}
+		subs = append(subs, desc)
+	}
+	return subs
+}
+
+func formatTopicDelivery(failing []topicDelivery, checked int, window string) string {
+	var out strings.Builder
+	if len(failing) == 0 {
+		out.WriteString(fmt.Sprintf("✅ No failed SNS deliveries across %d topics %s\n", checked, window))
+		return out.String()
+	}
+	sort.Slice(failing, func(i, j int) bool { return failing[i].Failed > failing[j].Failed })
+	out.WriteString(fmt.Sprintf("SNS delivery failures %s:\n", window))
+	for _, t := range failing {
+		out.WriteString(fmt.Sprintf("⚠️  %s: %.0f failed of %.0f attempted deliveries\n", t.Name, t.Failed, t.Failed+t.Delivered))
+		for _, sub := range t.Subscriptions {
+			out.WriteString(fmt.Sprintf("    - %s\n", sub))
+		}
+	}
+	return out.String()
+}
+
+func sqsQueueNameFromURL(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
+
+// arnResourceName returns the last ARN segment, the name of an SQS queue or
+// SNS topic.
+func arnResourceName(arn string) string {
+	return arn[strings.LastIndex(arn, ":")+1:]
+}
+
+func arnAccount(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
+
+// formatQueueAge renders an age to the minute, or in seconds under a minute.
+func formatQueueAge(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeQueueHealthFlagsDLQAndOldMessages(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["sqs list-queues"] = `{"QueueUrls": [
+		"https://sqs.us-east-1.amazonaws.com/123456789012/orders",
+		"https://sqs.us-east-1.amazonaws.com/123456789012/emails"
+	]}`
+	f.fixtures["sqs get-queue-attributes --queue-url https://sqs.us-east-1.amazonaws.com/123456789012/orders "] = `{"Attributes": {
+		"QueueArn": "arn:aws:sqs:us-east-1:123456789012:orders",
+		"ApproximateNumberOfMessages": "1200",
+		"ApproximateNumberOfMessagesNotVisible": "10",
+		"RedrivePolicy": "{\"deadLetterTargetArn\":\"arn:aws:sqs:us-east-1:123456789012:orders-dlq\",\"maxReceiveCount\":5}"
+	}}`
+	f.fixtures["sqs get-queue-attributes --queue-url https://sqs.us-east-1.amazonaws.com/123456789012/emails "] = `{"Attributes": {
+		"QueueArn": "arn:aws:sqs:us-east-1:123456789012:emails",
+		"ApproximateNumberOfMessages": "0",
+		"ApproximateNumberOfMessagesNotVisible": "0"
+	}}`
+	f.fixtures["sqs get-queue-url --queue-name orders-dlq --queue-owner-aws-account-id 123456789012"] = `{"QueueUrl": "https://sqs.us-east-1.amazonaws.com/123456789012/orders-dlq"}`
+	f.fixtures["sqs get-queue-attributes --queue-url https://sqs.us-east-1.amazonaws.com/123456789012/orders-dlq "] = `{"Attributes": {
+		"QueueArn": "arn:aws:sqs:us-east-1:123456789012:orders-dlq",
+		"ApproximateNumberOfMessages": "42",
+		"ApproximateNumberOfMessagesNotVisible": "0"
+	}}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/SQS"] = `{"Datapoints": [
+		{"Timestamp": "2024-05-01T12:00:00Z", "Maximum": 600},
+		{"Timestamp": "2024-05-01T12:05:00Z", "Maximum": 7800}
+	]}`
+	f.fixtures["sns list-topics"] = `{"Topics": [{"TopicArn": "arn:aws:sns:us-east-1:123456789012:order-events"}]}`
+	f.fixtures["sns list-subscriptions-by-topic"] = `{"Subscriptions": [{"SubscriptionArn": "arn:aws:sns:us-east-1:123456789012:order-events:abc", "Protocol": "https", "Endpoint": "https://hooks.example.com/orders"}]}`
+	f.fixtures["sns get-subscription-attributes"] = `{"Attributes": {"Protocol": "https"}}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/SNS --metric-name NumberOfNotificationsFailed"] = `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Sum": 7}]}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/SNS --metric-name NumberOfNotificationsDelivered"] = `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Sum": 93}]}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "analyze_queue_health", map[string]interface{}{"query": "why are messages stuck"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"orders-dlq: 42 messages in the dead-letter queue of orders",
+		"orders: oldest message is 2h10m old (threshold 15m) with 1200 visible and 10 in flight",
+		"orders: 1200 visible, 10 in flight, oldest 2h10m; DLQ orders-dlq (maxReceiveCount 5)",
+		"emails: 0 visible, 0 in flight; no DLQ",
+		"order-events: 7 failed of 100 attempted deliveries",
+		"https https://hooks.example.com/orders (no DLQ)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "emails: oldest") {
+		t.Errorf("empty queue should not be flagged:\n%s", out)
+	}
+}
+
+func TestQueueFindingsHealthy(t *testing.T) {
+	queues := []sqsQueueHealth{
+		{Name: "jobs", Visible: 3, OldestAge: 2 * time.Minute, DLQArn: "arn:aws:sqs:us-east-1:1:jobs-dlq"},
+		{Name: "jobs-dlq", Sources: []string{"jobs"}},
+	}
+	if findings := queueFindings(queues, queueAgeThreshold); len(findings) != 0 {
+		t.Fatalf("expected no findings, got %v", findings)
+	}
+	if got := formatQueueHealth(queues, queueAgeThreshold); !strings.Contains(got, "✅ No dead-letter backlog and no message older than 15m across 2 queues") {
+		t.Errorf("unexpected output:\n%s", got)
+	}
+}
@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	rawQueryServicePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	rawQueryCommandPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+)
+
+// rawAWSQuery is the raw_aws_query operation: it runs "aws <service>
+// <command> <args>" for the read-only APIs no dedicated operation covers.
+// The command must start with a read-only verb; anything else is refused.
+func (c *Client) rawAWSQuery(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	service := strings.ToLower(getStringParam(input, "service", ""))
+	command := strings.ToLower(getStringParam(input, "command", ""))
+	if service == "" {
+		return "", fmt.Errorf("service parameter required")
+	}
+	if command == "" {
+		return "", fmt.Errorf("command parameter required")
+	}
+	args := stringListParam(input, "args")
+	if err := validateRawAWSQuery(service, command, args); err != nil {
+		return "", err
+	}
+
+	cli := append([]string{service, command}, args...)
+	if !rawQueryHasFlag(args, "--output") {
+		cli = append(cli, "--output", "json")
+	}
+	out, err := c.execAWSCLI(ctx, cli, profile)
+	if err != nil {
+		return categorizeAWSError(err, service), nil
+	}
+	return out, nil
+}
+
+func validateRawAWSQuery(service, command string, args []string) error {
+	if !rawQueryServicePattern.MatchString(service) {
+		return fmt.Errorf("invalid service %q: use the CLI service name, e.g. ec2 or elbv2", service)
+	}
+	if !rawQueryCommandPattern.MatchString(command) {
+		return fmt.Errorf("invalid command %q: use a single CLI action, e.g. describe-vpcs, and pass flags in args", command)
+	}
+	if err := checkReadOnlyAction(service, command); err != nil {
+		return fmt.Errorf("raw_aws_query refused: %w; only list, describe, get, head, lookup, search and filter actions are allowed", err)
+	}
+	if err := checkReadOnlyArgs(args); err != nil {
+		return fmt.Errorf("raw_aws_query refused: %w", err)
+	}
+	return nil
+}
+
+func rawQueryHasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
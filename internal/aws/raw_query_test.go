@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRawAWSQuery(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["ec2 describe-vpc-endpoints --filters Name=vpc-id,Values=vpc-1 --output json"] = `{"VpcEndpoints": []}`
+	f.fixtures["elbv2 describe-ssl-policies --names ELBSecurityPolicy-2016-08 --output text"] = "policy"
+	c := newFakeClient(f)
+
+	for _, input := range []map[string]interface{}{
+		{"service": "ec2", "command": "describe-vpc-endpoints", "args": []interface{}{"--filters", "Name=vpc-id,Values=vpc-1"}},
+		{"service": "EC2", "command": "describe-vpc-endpoints", "args": "--filters 'Name=vpc-id,Values=vpc-1'"},
+	} {
+		out, err := c.executeAWSOperation(context.Background(), "raw_aws_query", input, &AIProfile{})
+		if err != nil || out != `{"VpcEndpoints": []}` {
+			t.Errorf("raw_aws_query(%v) = %q, %v", input, out, err)
+		}
+	}
+	out, err := c.executeAWSOperation(context.Background(), "raw_aws_query", map[string]interface{}{
+		"service": "elbv2", "command": "describe-ssl-policies", "args": []interface{}{"--names", "ELBSecurityPolicy-2016-08", "--output", "text"},
+	}, &AIProfile{})
+	if err != nil || out != "policy" {
+		t.Errorf("expected a caller-chosen --output kept, got %q, %v", out, err)
+	}
+}
+
+func TestRawAWSQueryRefusesUnsafeCalls(t *testing.T) {
+	c := newFakeClient(newFakeCLI())
+	for _, tt := range []struct {
+		input map[string]interface{}
+		want  string
+	}{
+		{map[string]interface{}{"service": "ec2", "command": "terminate-instances", "args": []interface{}{"--instance-ids", "i-1"}}, "not a read-only action"},
+		{map[string]interface{}{"service": "s3api", "command": "put-bucket-policy"}, "not a read-only action"},
+		{map[string]interface{}{"service": "secretsmanager", "command": "get-secret-value", "args": "--secret-id db"}, "reads secrets"},
+		{map[string]interface{}{"service": "s3api", "command": "get-object", "args": "--bucket b --key k out.txt"}, "writes local files"},
+		{map[string]interface{}{"service": "ec2", "command": "describe-instances --dry-run"}, "invalid command"},
+		{map[string]interface{}{"service": "ec2", "command": "describe-instances", "args": []interface{}{"--filters", "file:///etc/passwd"}}, "local files"},
+		{map[string]interface{}{"service": "ec2", "command": "describe-instances", "args": []interface{}{"--profile=prod"}}, "--profile is not allowed"},
+		{map[string]interface{}{"command": "describe-instances"}, "service parameter required"},
+		{map[string]interface{}{"service": "s3api", "command": "get-object-torrent", "args": "--bucket b --key k /tmp/x"}, "writes local files"},
+		{map[string]interface{}{"service": "apigateway", "command": "get-export", "args": "--rest-api-id a --stage-name prod --export-type oas30 /tmp/x"}, "writes local files"},
+		{map[string]interface{}{"service": "apigateway", "command": "get-sdk", "args": "--rest-api-id a --stage-name prod --sdk-type java /tmp/x"}, "writes local files"},
+		{map[string]interface{}{"service": "glacier", "command": "get-job-output", "args": "--vault-name v --job-id j /tmp/x"}, "writes local files"},
+		{map[string]interface{}{"service": "ebs", "command": "get-snapshot-block", "args": "--snapshot-id s --block-index 0 --block-token t /tmp/x"}, "writes local files"},
+		{map[string]interface{}{"service": "lightsail", "command": "get-relational-database-master-user-password", "args": "--relational-database-name db"}, "reads secrets"},
+		{map[string]interface{}{"service": "lightsail", "command": "get-instance-access-details", "args": "--instance-name web"}, "reads secrets"},
+		{map[string]interface{}{"service": "redshift", "command": "get-cluster-credentials", "args": "--db-user admin --cluster-identifier c"}, "reads secrets"},
+		{map[string]interface{}{"service": "sso", "command": "get-role-credentials", "args": "--role-name r --account-id 1 --access-token t"}, "reads secrets"},
+		{map[string]interface{}{"service": "ssm", "command": "get-parameter-history", "args": "--name db-password --with-decryption"}, "--with-decryption is not allowed"},
+		{map[string]interface{}{"service": "ec2", "command": "describe-instances", "args": "--outfile /tmp/x"}, "--outfile is not allowed"},
+		{map[string]interface{}{"service": "ec2", "command": "describe-instances", "args": "out.json"}, "positional argument"},
+		{map[string]interface{}{"service": "ec2", "command": "describe-instances", "args": "--instance-ids i-1 /tmp/x"}, "positional argument"},
+	} {
+		_, err := c.executeAWSOperation(context.Background(), "raw_aws_query", tt.input, &AIProfile{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("raw_aws_query(%v) err = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestCheckReadOnlyArgsAllowsFlagValues(t *testing.T) {
+	for _, args := range [][]string{
+		{"--instance-ids", "i-1", "i-2", "--max-items", "5"},
+		{"--resource-arns", "arn:aws:ecs:us-east-1:1:service/a/b", "arn:aws:ecs:us-east-1:1:service/a/c"},
+		{"--bucket", "b", "--key", "logs/app.log", "--dry-run"},
+		{"--query=Reservations[].Instances[].InstanceId", "--no-paginate"},
+	} {
+		if err := checkReadOnlyArgs(args); err != nil {
+			t.Errorf("checkReadOnlyArgs(%v) = %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// rdsPerformanceWindow is how far back analyze_rds_performance looks.
+	rdsPerformanceWindow = time.Hour
+	rdsPerformancePeriod = 300
+	// rdsPerformanceMaxInstances bounds the fan-out when no instance is named.
+	rdsPerformanceMaxInstances = 3
+	// rdsConnectionWarnPercent flags connections close to max_connections.
+	rdsConnectionWarnPercent = 80.0
+	// rdsFreeMemoryWarnPercent flags freeable memory below this share of the
+	// instance class memory.
+	rdsFreeMemoryWarnPercent = 10.0
+	// rdsFreeMemoryFloorBytes is the memory pressure threshold when the
+	// instance class memory is unknown.
+	rdsFreeMemoryFloorBytes = 256 * 1024 * 1024
+	rdsCPUWarnPercent       = 80.0
+	// rdsLatencyWarnSeconds flags average read or write latency above 20ms.
+	rdsLatencyWarnSeconds = 0.02
+	rdsTopWaits           = 5
+)
+
+// rdsMemoryFormula matches {DBInstanceClassMemory/N} in parameter values
+// such as "LEAST({DBInstanceClassMemory/9531392},5000)".
+var rdsMemoryFormula = regexp.MustCompile(`\{DBInstanceClassMemory/(\d+)\}`)
+
+type rdsInstance struct {
+	ID                 string `json:"DBInstanceIdentifier"`
+	Class              string `json:"DBInstanceClass"`
+	Engine             string `json:"Engine"`
+	EngineVersion      string `json:"EngineVersion"`
+	Status             string `json:"DBInstanceStatus"`
+	ResourceID         string `json:"DbiResourceId"`
+	PerformanceInsight bool   `json:"PerformanceInsightsEnabled"`
+	ParameterGroups    []struct {
+		Name string `json:"DBParameterGroupName"`
+	} `json:"DBParameterGroups"`
+}
+
+// rdsMetricSummary is one CloudWatch metric over the window.
+type rdsMetricSummary struct {
+	Name    string
+	Stat    string
+	Min     float64
+	Max     float64
+	Avg     float64
+	HasData bool
+}
+
+// rdsWait is one Performance Insights wait event and its average load in
+// active sessions.
+type rdsWait struct {
+	Name string
+	Type string
+	Load float64
+}
+
+// rdsPerformanceReport collects everything analyze_rds_performance learned
+// about one instance.
+type rdsPerformanceReport struct {
+	Instance       rdsInstance
+	Metrics        map[string]rdsMetricSummary
+	MaxConnections int
+	MemoryBytes    float64
+	Waits          []rdsWait
+	WaitsNote      string
+	Start, End     time.Time
+}
+
+// rdsPerformanceMetrics are fetched for every instance, with the statistic
+// that best exposes trouble for each.
+var rdsPerformanceMetrics = []struct {
+	Name string
+	Stat string
+}{
+	{"CPUUtilization", "Average"},
+	{"DatabaseConnections", "Maximum"},
+	{"FreeableMemory", "Minimum"},
+	{"ReadLatency", "Average"},
+	{"WriteLatency", "Average"},
+}
+
+// analyzeRDSPerformance is the analyze_rds_performance operation: it pulls
+// the investigation window (the last hour by default) of CPU, connection, memory and latency metrics for an RDS
+// instance, compares connections with max_connections and, when Performance
+// Insights is enabled, lists the top wait events.
+func (c *Client) analyzeRDSPerformance(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	id := getStringParam(input, "instance_id", getStringParam(input, "db_instance_identifier", ""))
+	args := []string{"rds", "describe-db-instances", "--output", "json"}
+	if id != "" {
+		args = []string{"rds", "describe-db-instances", "--db-instance-identifier", id, "--output", "json"}
+	}
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return categorizeAWSError(err, "RDS"), nil
+	}
+	var resp struct {
+		DBInstances []rdsInstance `json:"DBInstances"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return "", fmt.Errorf("failed to parse RDS instances: %w", err)
+	}
+	instances := selectRDSInstances(resp.DBInstances, getStringParam(input, "query", ""))
+	if len(instances) == 0 {
+		return "No RDS instances found.", nil
+	}
+
+	var out strings.Builder
+	start, end := operationWindow(ctx, input, rdsPerformanceWindow)
+	for i, inst := range instances {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(formatRDSPerformance(c.rdsPerformanceReport(ctx, inst, start, end, profile)))
+	}
+	if len(resp.DBInstances) > len(instances) && id == "" {
+		out.WriteString(fmt.Sprintf("\n%d more instance(s) not analyzed; pass instance_id to pick one.\n", len(resp.DBInstances)-len(instances)))
+	}
+	return out.String(), nil
+}
+
+// selectRDSInstances keeps the instances named in query, or the first few
+// when the query names none.
+func selectRDSInstances(instances []rdsInstance, query string) []rdsInstance {
+	query = strings.ToLower(query)
+	var named []rdsInstance
+	for _, inst := range instances {
+		if query != "" && inst.ID != "" && strings.Contains(query, strings.ToLower(inst.ID)) {
+			named = append(named, inst)
+		}
+	}
+	if len(named) > 0 {
+		return named
+	}
+	if len(instances) > rdsPerformanceMaxInstances {
+		return instances[:rdsPerformanceMaxInstances]
+	}
+	return instances
+}
+
+func (c *Client) rdsPerformanceReport(ctx context.Context, inst rdsInstance, start, end time.Time, profile *AIProfile) rdsPerformanceReport {
+	report := rdsPerformanceReport{Instance: inst, Metrics: make(map[string]rdsMetricSummary), Start: start, End: end}
+	window := end.Sub(start)
+	for _, m := range rdsPerformanceMetrics {
+		req := metricStatisticsRequest{
+			Namespace:  "AWS/RDS",
+			MetricName: m.Name,
+			Dimensions: []metricDimension{{Name: "DBInstanceIdentifier", Value: inst.ID}},
+			Period:     metricPeriodForWindow(rdsPerformancePeriod, window),
+			Stat:       m.Stat,
+			Window:     window,
+		}
+		summary := rdsMetricSummary{Name: m.Name, Stat: m.Stat}
+		if raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile); err == nil {
+			if points, err := decodeMetricDatapoints(raw, req); err == nil && len(points) > 0 {
+				summary.Min, summary.Max, summary.Avg = summarizeMetricDatapoints(points)
+				summary.HasData = true
+			}
+		}
+		report.Metrics[m.Name] = summary
+	}
+
+	report.MemoryBytes = c.rdsClassMemory(ctx, inst.Class, profile)
+	if len(inst.ParameterGroups) > 0 {
+		report.MaxConnections = c.rdsMaxConnections(ctx, inst.ParameterGroups[0].Name, report.MemoryBytes, profile)
+	}
+
+	if !inst.PerformanceInsight || inst.ResourceID == "" {
+		report.WaitsNote = "Performance Insights is not enabled; enable it to see top wait events."
+		return report
+	}
+	waits, err := c.rdsTopWaits(ctx, inst.ResourceID, start, end, profile)
+	if err != nil {
+		report.WaitsNote = "Performance Insights data unavailable: " + categorizeAWSError(err, "Performance Insights")
+		return report
+	}
+	report.Waits = waits
+	return report
+}
+
+// rdsClassMemory returns the memory of an instance class such as db.r5.large
+// in bytes, looked up from the matching EC2 instance type, or 0 if unknown.
+func (c *Client) rdsClassMemory(ctx context.Context, class string, profile *AIProfile) float64 {
+	instanceType, ok := strings.CutPrefix(class, "db.")
+	if !ok || instanceType == "" {
+		return 0
+	}
+	raw, err := c.execAWSCLI(ctx, []string{"ec2", "describe-instance-types", "--instance-types", instanceType, "--query", "InstanceTypes[0].MemoryInfo.SizeInMiB", "--output", "text"}, profile)
+	if err != nil {
+		return 0
+	}
+	mib, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return 0
+	}
+	return mib * 1024 * 1024
+}
+
+// rdsMaxConnections reads max_connections from the parameter group, or 0 if
+// it cannot be determined.
+func (c *Client) rdsMaxConnections(ctx context.Context, group string, memoryBytes float64, profile *AIProfile) int {
+	raw, err := c.execAWSCLI(ctx, []string{"rds", "describe-db-parameters", "--db-parameter-group-name", group,
+		"--query", "Parameters[?ParameterName=='max_connections'].ParameterValue", "--output", "text"}, profile)
+	if err != nil {
+		return 0
+	}
+	return evalMaxConnections(raw, memoryBytes)
+}
+
+// evalMaxConnections evaluates a max_connections parameter value: a plain
+// number, {DBInstanceClassMemory/N}, or LEAST/GREATEST of those.
+func evalMaxConnections(value string, memoryBytes float64) int {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "None" {
+		return 0
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+
+	lower := strings.ToLower(value)
+	fn := ""
+	for _, name := range []string{"least", "greatest"} {
+		if strings.HasPrefix(lower, name+"(") && strings.HasSuffix(lower, ")") {
+			fn = name
+			value = value[len(name)+1 : len(value)-1]
+		}
+	}
+
+	var results []float64
+	for _, term := range strings.Split(value, ",") {
+		term = strings.TrimSpace(term)
+		if m := rdsMemoryFormula.FindStringSubmatch(term); m != nil {
+			divisor, _ := strconv.ParseFloat(m[1], 64)
+			if memoryBytes <= 0 || divisor <= 0 {
+				continue
+			}
+			results = append(results, math.Floor(memoryBytes/divisor))
+			continue
+		}
+		if n, err := strconv.ParseFloat(term, 64); err == nil {
+			results = append(results, n)
+		}
+	}
+	if len(results) == 0 || (fn == "" && len(results) != 1) {
+		return 0
+	}
+	result := results[0]
+	for _, r := range results[1:] {
+		if fn == "greatest" {
+			result = math.Max(result, r)
+		} else {
+			result = math.Min(result, r)
+		}
+	}
+	return int(result)
+}
+
+// rdsTopWaits returns the wait events contributing the most database load
+// over the window, from Performance Insights.
+func (c *Client) rdsTopWaits(ctx context.Context, resourceID string, start, end time.Time, profile *AIProfile) ([]rdsWait, error) {
+	query := fmt.Sprintf(`[{"Metric":"db.load.avg","GroupBy":{"Group":"db.wait_event","Limit":%d}}]`, rdsTopWaits)
+	raw, err := c.execAWSCLI(ctx, []string{"pi", "get-resource-metrics",
+		"--service-type", "RDS",
+		"--identifier", resourceID,
+		"--metric-queries", query,
+		"--start-time", start.Format(time.RFC3339),
+		"--end-time", end.Format(time.RFC3339),
+		"--period-in-seconds", strconv.Itoa(rdsPIPeriod(end.Sub(start))),
+		"--output", "json"}, profile)
+	if err != nil {
+		return nil, err
+	}
+	return parseRDSWaits(raw)
+}
+
+// rdsPIPeriod picks a Performance Insights period (1m, 5m, 1h or 1d) that
+// keeps a window to a few hundred datapoints.
+func rdsPIPeriod(window time.Duration) int {
+	for _, period := range []int{60, 300, 3600} {
+		if window/(time.Duration(period)*time.Second) <= 300 {
+			return period
+		}
+	}
+	return 86400
+}
+
+func parseRDSWaits(raw string) ([]rdsWait, error) {
+	var resp struct {
+		MetricList []struct {
+			Key struct {
+				Dimensions map[string]string `json:"Dimensions"`
+			} `json:"Key"`
+			DataPoints []struct {
+				Value *float64 `json:"Value"`
+			} `json:"DataPoints"`
+		} `json:"MetricList"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse Performance Insights metrics: %w", err)
+	}
+
+	var waits []rdsWait
+	for _, m := range resp.MetricList {
+		name := m.Key.Dimensions["db.wait_event.name"]
+		if name == "" {
+			// The ungrouped total load has no dimensions.
+			continue
+		}
+		sum, n := 0.0, 0
+		for _, dp := range m.DataPoints {
+			if dp.Value != nil {
+				sum += *dp.Value
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		waits = append(waits, rdsWait{Name: name, Type: m.Key.Dimensions["db.wait_event.type"], Load: sum / float64(n)})
+	}
+	sort.SliceStable(waits, func(i, j int) bool { return waits[i].Load > waits[j].Load })
+	return waits, nil
+}
+
+// rdsFindings flags connection exhaustion, memory pressure, CPU saturation
+// and high storage latency.
+func rdsFindings(r rdsPerformanceReport) []string {
+	var findings []string
+	if conns := r.Metrics["DatabaseConnections"]; conns.HasData && r.MaxConnections > 0 {
+		pct := conns.Max / float64(r.MaxConnections) * 100
+		if pct >= rdsConnectionWarnPercent {
+			findings = append(findings, fmt.Sprintf("Connection exhaustion: peak %.0f connections of max_connections %d (%.0f%%)", conns.Max, r.MaxConnections, pct))
+		}
+	}
+	if mem := r.Metrics["FreeableMemory"]; mem.HasData {
+		switch {
+		case r.MemoryBytes > 0 && mem.Min/r.MemoryBytes*100 < rdsFreeMemoryWarnPercent:
+			findings = append(findings, fmt.Sprintf("Memory pressure: freeable memory fell to %s (%.1f%% of %s)", formatBytesMiB(mem.Min), mem.Min/r.MemoryBytes*100, formatBytesMiB(r.MemoryBytes)))
+		case r.MemoryBytes <= 0 && mem.Min < rdsFreeMemoryFloorBytes:
+			findings = append(findings, fmt.Sprintf("Memory pressure: freeable memory fell to %s", formatBytesMiB(mem.Min)))
+		}
+	}
+	if cpu := r.Metrics["CPUUtilization"]; cpu.HasData && cpu.Max >= rdsCPUWarnPercent {
+		findings = append(findings, fmt.Sprintf("CPU saturation: average CPU peaked at %.1f%%", cpu.Max))
+	}
+	for _, kind := range []string{"Read", "Write"} {
+		if lat := r.Metrics[kind+"Latency"]; lat.HasData && lat.Avg >= rdsLatencyWarnSeconds {
+			findings = append(findings, fmt.Sprintf("High %s latency: %.1f ms average, %.1f ms peak", strings.ToLower(kind), lat.Avg*1000, lat.Max*1000))
+		}
+	}
+	return findings
+}
+
+func formatRDSPerformance(r rdsPerformanceReport) string {
+	inst := r.Instance
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("🗄️  RDS performance: %s (%s %s, %s, %s)\n", inst.ID, inst.Engine, inst.EngineVersion, inst.Class, inst.Status))
+	out.WriteString("============================\n")
+
+	findings := rdsFindings(r)
+	if len(findings) == 0 {
+		out.WriteString(fmt.Sprintf("✅ No connection, memory, CPU or latency pressure %s\n", windowPhrase(r.Start, r.End)))
+	}
+	for _, f := range findings {
+		out.WriteString(fmt.Sprintf("⚠️  %s\n", f))
+	}
+
+	out.WriteString(fmt.Sprintf("\nMetrics (%s):\n", describeWindow(r.Start, r.End)))
+	for _, m := range rdsPerformanceMetrics {
+		s := r.Metrics[m.Name]
+		if !s.HasData {
+			out.WriteString(fmt.Sprintf("  • %s: no datapoints\n", m.Name))
+			continue
+		}
+		switch m.Name {
+		case "CPUUtilization":
+			out.WriteString(fmt.Sprintf("  • CPUUtilization: avg %.1f%%, peak %.1f%%\n", s.Avg, s.Max))
+		case "DatabaseConnections":
+			limit := "max_connections unknown"
+			if r.MaxConnections > 0 {
+				limit = fmt.Sprintf("max_connections %d", r.MaxConnections)
+			}
+			out.WriteString(fmt.Sprintf("  • DatabaseConnections: peak %.0f, low %.0f (%s)\n", s.Max, s.Min, limit))
+		case "FreeableMemory":
+			out.WriteString(fmt.Sprintf("  • FreeableMemory: low %s, high %s\n", formatBytesMiB(s.Min), formatBytesMiB(s.Max)))
+		default:
+			out.WriteString(fmt.Sprintf("  • %s: avg %.1f ms, peak %.1f ms\n", m.Name, s.Avg*1000, s.Max*1000))
+		}
+	}
+
+	if r.WaitsNote != "" {
+		out.WriteString(fmt.Sprintf("\nℹ️  %s\n", r.WaitsNote))
+		return out.String()
+	}
+	out.WriteString("\nTop wait events (Performance Insights, avg active sessions):\n")
+	if len(r.Waits) == 0 {
+		out.WriteString("  No database load recorded in the window.\n")
+	}
+	for _, w := range r.Waits {
+		out.WriteString(fmt.Sprintf("  • %s (%s): %.2f\n", w.Name, w.Type, w.Load))
+	}
+	return out.String()
+}
+
+func formatBytesMiB(b float64) string {
+	return fmt.Sprintf("%.0f MiB", b/1024/1024)
+}
@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEvalMaxConnections(t *testing.T) {
+	const mem = 8 * 1024 * 1024 * 1024
+	cases := map[string]int{
+		"500":                              500,
+		"{DBInstanceClassMemory/12582880}": 682,
+		"LEAST({DBInstanceClassMemory/9531392},5000)":    901,
+		"GREATEST({DBInstanceClassMemory/9531392},1000)": 1000,
+		"None": 0,
+	}
+	for value, want := range cases {
+		if got := evalMaxConnections(value, mem); got != want {
+			t.Errorf("evalMaxConnections(%q) = %d, want %d", value, got, want)
+		}
+	}
+	if got := evalMaxConnections("{DBInstanceClassMemory/12582880}", 0); got != 0 {
+		t.Errorf("formula without known memory should be 0, got %d", got)
+	}
+}
+
+func TestAnalyzeRDSPerformanceFlagsExhaustionAndMemory(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["rds describe-db-instances --db-instance-identifier orders-db"] = `{"DBInstances": [{
+		"DBInstanceIdentifier": "orders-db", "DBInstanceClass": "db.t3.medium", "Engine": "postgres",
+		"EngineVersion": "15.4", "DBInstanceStatus": "available", "DbiResourceId": "db-ABC",
+		"PerformanceInsightsEnabled": false,
+		"DBParameterGroups": [{"DBParameterGroupName": "default.postgres15"}]
+	}]}`
+	f.fixtures["ec2 describe-instance-types --instance-types t3.medium"] = "4096\n"
+	f.fixtures["rds describe-db-parameters --db-parameter-group-name default.postgres15"] = "100\n"
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/RDS --metric-name CPUUtilization"] = `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Average": 35}]}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/RDS --metric-name DatabaseConnections"] = `{"Datapoints": [
+		{"Timestamp": "2024-05-01T12:00:00Z", "Maximum": 60},
+		{"Timestamp": "2024-05-01T12:05:00Z", "Maximum": 97}
+	]}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/RDS --metric-name FreeableMemory"] = `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Minimum": 209715200}]}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/RDS --metric-name ReadLatency"] = `{"Datapoints": [{"Timestamp": "2024-05-01T12:00:00Z", "Average": 0.002}]}`
+	f.fixtures["cloudwatch get-metric-statistics --namespace AWS/RDS --metric-name WriteLatency"] = `{"Datapoints": []}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_rds_performance", map[string]interface{}{"instance_id": "orders-db"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Connection exhaustion: peak 97 connections of max_connections 100 (97%)",
+		"Memory pressure: freeable memory fell to 200 MiB (4.9% of 4096 MiB)",
+		"WriteLatency: no datapoints",
+		"Performance Insights is not enabled",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, "pi ") {
+			t.Errorf("Performance Insights should not be queried when disabled: %s", call)
+		}
+	}
+}
+
+func TestParseRDSWaitsSkipsTotalAndSortsByLoad(t *testing.T) {
+	raw := `{"MetricList": [
+		{"Key": {"Metric": "db.load.avg"}, "DataPoints": [{"Value": 3.0}]},
+		{"Key": {"Metric": "db.load.avg", "Dimensions": {"db.wait_event.name": "CPU", "db.wait_event.type": "CPU"}}, "DataPoints": [{"Value": 0.5}, {"Value": 0.7}]},
+		{"Key": {"Metric": "db.load.avg", "Dimensions": {"db.wait_event.name": "Lock:transactionid", "db.wait_event.type": "Lock"}}, "DataPoints": [{"Value": 2.0}, {"Value": 1.0}]}
+	]}`
+	waits, err := parseRDSWaits(raw)
+	if err != nil {
+		t.Fatalf("parseRDSWaits: %v", err)
+	}
+	if len(waits) != 2 || waits[0].Name != "Lock:transactionid" || waits[0].Load != 1.5 {
+		t.Errorf("unexpected waits: %+v", waits)
+	}
+}
@@ -0,0 +1,426 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// s3AuditMaxBuckets caps how many buckets one account-wide audit checks;
+// each bucket costs six API calls.
+const s3AuditMaxBuckets = 100
+
+// s3PublicGroups maps the ACL grantee groups that make a bucket public to
+// how the audit names them.
+var s3PublicGroups = map[string]string{
+	"http://acs.amazonaws.com/groups/global/AllUsers":           "AllUsers",
+	"http://acs.amazonaws.com/groups/global/AuthenticatedUsers": "AuthenticatedUsers (any AWS account)",
+}
+
+// s3PublicAccessBlock is a PublicAccessBlockConfiguration, at bucket or
+// account level.
+type s3PublicAccessBlock struct {
+	BlockPublicAcls       bool `json:"BlockPublicAcls"`
+	IgnorePublicAcls      bool `json:"IgnorePublicAcls"`
+	BlockPublicPolicy     bool `json:"BlockPublicPolicy"`
+	RestrictPublicBuckets bool `json:"RestrictPublicBuckets"`
+}
+
+func (b *s3PublicAccessBlock) allOn() bool {
+	return b != nil && b.BlockPublicAcls && b.IgnorePublicAcls && b.BlockPublicPolicy && b.RestrictPublicBuckets
+}
+
+// off lists the settings that are not enabled.
+func (b *s3PublicAccessBlock) off() []string {
+	if b == nil {
+		return []string{"BlockPublicAcls", "IgnorePublicAcls", "BlockPublicPolicy", "RestrictPublicBuckets"}
+	}
+	var off []string
+	for _, s := range []struct {
+		name string
+		on   bool
+	}{
+		{"BlockPublicAcls", b.BlockPublicAcls},
+		{"IgnorePublicAcls", b.IgnorePublicAcls},
+		{"BlockPublicPolicy", b.BlockPublicPolicy},
+		{"RestrictPublicBuckets", b.RestrictPublicBuckets},
+	} {
+		if !s.on {
+			off = append(off, s.name)
+		}
+	}
+	return off
+}
+
+// s3BucketAudit is what audit_s3_bucket found for one bucket.
+type s3BucketAudit struct {
+	Name         string
+	Block        *s3PublicAccessBlock // nil when the bucket has none
+	PublicRead   []string             // how anyone can read, one entry per grant or statement
+	PublicWrite  []string             // how anyone can write
+	Encryption   string               // default encryption algorithm, "" when not configured
+	Versioning   string               // Enabled, Suspended, or "" when never enabled
+	Findings     []string
+	Unchecked    []string // checks that failed, e.g. for AccessDenied
+	policyPublic bool     // get-bucket-policy-status reported IsPublic
+}
+
+func (a *s3BucketAudit) public() bool {
+	return len(a.PublicRead) > 0 || len(a.PublicWrite) > 0
+}
+
+// auditS3Buckets is the audit_s3_bucket operation. For one bucket
+// (bucket_name) or every bucket in the account it checks Block Public
+// Access, wildcard-principal policy statements, ACL grants to AllUsers and
+// AuthenticatedUsers, default encryption and versioning, and lists publicly
+// readable or writable buckets first.
+func (c *Client) auditS3Buckets(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	names, skipped, err := c.s3AuditTargets(ctx, input, profile)
+	if err != nil {
+		return categorizeAWSError(err, "S3"), nil
+	}
+
+	var out strings.Builder
+	out.WriteString("🪣 S3 bucket security audit\n")
+	out.WriteString("============================\n")
+	if len(names) == 0 {
+		out.WriteString("No S3 buckets found\n")
+		return out.String(), nil
+	}
+
+	account, accountErr := c.s3AccountPublicAccessBlock(ctx, profile)
+	audits := make([]*s3BucketAudit, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			audit := c.auditS3Bucket(ctx, name, profile)
+			s3AuditExposure(audit, account)
+			audits[i] = audit
+		}(i, name)
+	}
+	wg.Wait()
+
+	switch {
+	case accountErr != nil:
+		out.WriteString("Account-level Block Public Access: could not be read (" + accountErr.Error() + ")\n")
+	case account.allOn():
+		out.WriteString("Account-level Block Public Access: all four settings on; bucket ACLs and policies cannot make objects public\n")
+	default:
+		out.WriteString("Account-level Block Public Access: off for " + strings.Join(account.off(), ", ") + "\n")
+	}
+	formatS3Audit(&out, audits)
+	if skipped > 0 {
+		out.WriteString(fmt.Sprintf("\n… %d more buckets not audited; pass bucket_name to audit one\n", skipped))
+	}
+	return out.String(), nil
+}
+
+// s3AuditTargets returns the buckets to audit and how many were left out by
+// the s3AuditMaxBuckets cap.
+func (c *Client) s3AuditTargets(ctx context.Context, input map[string]interface{}, profile *AIProfile) ([]string, int, error) {
+	if name := strings.TrimSpace(getStringParam(input, "bucket_name", "")); name != "" {
+		return []string{name}, 0, nil
+	}
+	raw, err := c.execAWSCLI(ctx, []string{"s3api", "list-buckets", "--query", "Buckets[].Name", "--output", "json"}, profile)
+	if err != nil {
+		return nil, 0, err
+	}
+	var names []string
+	if trimmed := strings.TrimSpace(raw); trimmed != "" && trimmed != "null" {
+		if err := json.Unmarshal([]byte(raw), &names); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse bucket list: %w", err)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > s3AuditMaxBuckets {
+		return names[:s3AuditMaxBuckets], len(names) - s3AuditMaxBuckets, nil
+	}
+	return names, 0, nil
+}
+
+// s3AccountPublicAccessBlock reads the account-wide Block Public Access
+// settings. It returns nil with no error when the account has none.
+func (c *Client) s3AccountPublicAccessBlock(ctx context.Context, profile *AIProfile) (*s3PublicAccessBlock, error) {
+	account, err := c.execAWSCLI(ctx, []string{"sts", "get-caller-identity", "--query", "Account", "--output", "text"}, profile)
+	if err != nil {
+		return nil, fmt.Errorf("account id lookup failed")
+	}
+	raw, err := c.execAWSCLI(ctx, []string{"s3control", "get-public-access-block", "--account-id", strings.TrimSpace(account), "--output", "json"}, profile)
+	if err != nil {
+		if s3NotConfigured(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("s3control get-public-access-block failed")
+	}
+	var resp struct {
+		PublicAccessBlockConfiguration s3PublicAccessBlock `json:"PublicAccessBlockConfiguration"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("unexpected get-public-access-block output")
+	}
+	return &resp.PublicAccessBlockConfiguration, nil
+}
+
+// auditS3Bucket reads one bucket's access and data-protection settings.
+// Missing configuration is recorded as such; any other failure lands in
+// Unchecked.
+func (c *Client) auditS3Bucket(ctx context.Context, bucket string, profile *AIProfile) *s3BucketAudit {
+	audit := &s3BucketAudit{Name: bucket}
+	get := func(check string, args []string, v interface{}) bool {
+		raw, err := c.execAWSCLI(ctx, append(append([]string{"s3api"}, args...), "--bucket", bucket, "--output", "json"), profile)
+		if err != nil {
+			if !s3NotConfigured(err) {
+				audit.Unchecked = append(audit.Unchecked, check)
+			}
+			return false
+		}
+		if strings.TrimSpace(raw) == "" {
+			return true
+		}
+		if err := json.Unmarshal([]byte(raw), v); err != nil {
+			audit.Unchecked = append(audit.Unchecked, check)
+			return false
+		}
+		return true
+	}
+
+	var block struct {
+		PublicAccessBlockConfiguration s3PublicAccessBlock `json:"PublicAccessBlockConfiguration"`
+	}
+	if get("Block Public Access", []string{"get-public-access-block"}, &block) {
+		audit.Block = &block.PublicAccessBlockConfiguration
+	}
+
+	var policy struct {
+		Policy string `json:"Policy"`
+	}
+	if get("bucket policy", []string{"get-bucket-policy"}, &policy) {
+		read, write := s3PolicyPublicGrants(policy.Policy)
+		audit.PublicRead = append(audit.PublicRead, read...)
+		audit.PublicWrite = append(audit.PublicWrite, write...)
+	}
+	var status struct {
+		PolicyStatus struct {
+			IsPublic bool `json:"IsPublic"`
+		} `json:"PolicyStatus"`
+	}
+	if get("bucket policy status", []string{"get-bucket-policy-status"}, &status) {
+		audit.policyPublic = status.PolicyStatus.IsPublic
+	}
+
+	var acl struct {
+		Grants []struct {
+			Grantee struct {
+				URI string `json:"URI"`
+			} `json:"Grantee"`
+			Permission string `json:"Permission"`
+		} `json:"Grants"`
+	}
+	if get("ACL", []string{"get-bucket-acl"}, &acl) {
+		for _, g := range acl.Grants {
+			group, ok := s3PublicGroups[g.Grantee.URI]
+			if !ok {
+				continue
+			}
+			grant := fmt.Sprintf("ACL grants %s to %s", g.Permission, group)
+			switch g.Permission {
+			case "READ", "READ_ACP":
+				audit.PublicRead = append(audit.PublicRead, grant)
+			case "WRITE", "WRITE_ACP":
+				audit.PublicWrite = append(audit.PublicWrite, grant)
+			case "FULL_CONTROL":
+				audit.PublicRead = append(audit.PublicRead, grant)
+				audit.PublicWrite = append(audit.PublicWrite, grant)
+			}
+		}
+	}
+
+	var enc struct {
+		ServerSideEncryptionConfiguration struct {
+			Rules []struct {
+				Default struct {
+					SSEAlgorithm string `json:"SSEAlgorithm"`
+				} `json:"ApplyServerSideEncryptionByDefault"`
+			} `json:"Rules"`
+		} `json:"ServerSideEncryptionConfiguration"`
+	}
+	if get("default encryption", []string{"get-bucket-encryption"}, &enc) {
+		for _, rule := range enc.ServerSideEncryptionConfiguration.Rules {
+			if rule.Default.SSEAlgorithm != "" {
+				audit.Encryption = rule.Default.SSEAlgorithm
+				break
+			}
+		}
+	}
+
+	var versioning struct {
+		Status string `json:"Status"`
+	}
+	if get("versioning", []string{"get-bucket-versioning"}, &versioning) {
+		audit.Versioning = versioning.Status
+	}
+	return audit
+}
+
+// s3NotConfigured reports whether err means the setting was never
+// configured rather than that the lookup failed.
+func s3NotConfigured(err error) bool {
+	msg := err.Error()
+	for _, code := range []string{"NoSuchPublicAccessBlockConfiguration", "NoSuchBucketPolicy", "ServerSideEncryptionConfigurationNotFoundError"} {
+		if strings.Contains(msg, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// s3PolicyPublicGrants describes the Allow statements in a bucket policy
+// that give a wildcard principal read or write access without a Condition.
+// Statements with a Condition (source VPC, IP range, org ID) are left to
+// get-bucket-policy-status, which evaluates them.
+func s3PolicyPublicGrants(policy string) (read, write []string) {
+	var doc iamPolicyDocument
+	if json.Unmarshal([]byte(policy), &doc) != nil {
+		return nil, nil
+	}
+	for i, st := range doc.Statements {
+		if st.Effect != "Allow" || len(st.Condition) > 0 || !s3WildcardPrincipal(st.Principal) {
+			continue
+		}
+		name := st.Sid
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		var readActions, writeActions []string
+		for _, action := range st.Action {
+			a := strings.ToLower(action)
+			switch {
+			case a == "*" || a == "s3:*":
+				readActions = append(readActions, action)
+				writeActions = append(writeActions, action)
+			case strings.HasPrefix(a, "s3:get") || strings.HasPrefix(a, "s3:list"):
+				readActions = append(readActions, action)
+			case strings.HasPrefix(a, "s3:put") || strings.HasPrefix(a, "s3:delete") || strings.HasPrefix(a, "s3:abortmultipartupload"):
+				writeActions = append(writeActions, action)
+			}
+		}
+		if len(readActions) > 0 {
+			read = append(read, fmt.Sprintf("policy statement %s allows %s to everyone", name, strings.Join(readActions, ", ")))
+		}
+		if len(writeActions) > 0 {
+			write = append(write, fmt.Sprintf("policy statement %s allows %s to everyone", name, strings.Join(writeActions, ", ")))
+		}
+	}
+	return read, write
+}
+
+// s3WildcardPrincipal reports whether a policy Principal is "*" or
+// {"AWS": "*"}.
+func s3WildcardPrincipal(raw json.RawMessage) bool {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s == "*"
+	}
+	var m map[string]iamStringList
+	if json.Unmarshal(raw, &m) != nil {
+		return false
+	}
+	return containsString(m["AWS"], "*")
+}
+
+// s3AuditExposure drops public grants that Block Public Access neutralizes
+// and records the remaining findings.
+func s3AuditExposure(audit *s3BucketAudit, account *s3PublicAccessBlock) {
+	ignoreACLs := (audit.Block != nil && audit.Block.IgnorePublicAcls) || (account != nil && account.IgnorePublicAcls)
+	restrictPolicy := (audit.Block != nil && audit.Block.RestrictPublicBuckets) || (account != nil && account.RestrictPublicBuckets)
+	keep := func(grants []string) []string {
+		var kept []string
+		for _, g := range grants {
+			if strings.HasPrefix(g, "ACL ") && ignoreACLs || strings.HasPrefix(g, "policy ") && restrictPolicy {
+				continue
+			}
+			kept = append(kept, g)
+		}
+		return kept
+	}
+	audit.PublicRead = keep(audit.PublicRead)
+	audit.PublicWrite = keep(audit.PublicWrite)
+	if audit.policyPublic && !restrictPolicy && len(audit.PublicRead) == 0 && len(audit.PublicWrite) == 0 {
+		audit.PublicRead = append(audit.PublicRead, "bucket policy is public according to get-bucket-policy-status")
+	}
+
+	if !account.allOn() && !audit.Block.allOn() {
+		audit.Findings = append(audit.Findings, "⚠️  Block Public Access is not fully on (off: "+strings.Join(audit.Block.off(), ", ")+"); a future policy or ACL change can make it public")
+	}
+	if audit.Encryption == "" && !containsString(audit.Unchecked, "default encryption") {
+		audit.Findings = append(audit.Findings, "⚠️  No default encryption configured")
+	}
+	if audit.Versioning != "Enabled" && !containsString(audit.Unchecked, "versioning") {
+		state := "never enabled"
+		if audit.Versioning == "Suspended" {
+			state = "suspended"
+		}
+		audit.Findings = append(audit.Findings, "💡 Versioning is "+state+"; overwritten or deleted objects cannot be recovered")
+	}
+}
+
+// formatS3Audit lists publicly exposed buckets first, then buckets with
+// other findings, then the clean ones.
+func formatS3Audit(out *strings.Builder, audits []*s3BucketAudit) {
+	var exposed, flagged []*s3BucketAudit
+	var clean []string
+	for _, audit := range audits {
+		switch {
+		case audit.public():
+			exposed = append(exposed, audit)
+		case len(audit.Findings) > 0 || len(audit.Unchecked) > 0:
+			flagged = append(flagged, audit)
+		default:
+			clean = append(clean, audit.Name)
+		}
+	}
+
+	writeBucket := func(audit *s3BucketAudit) {
+		for _, g := range audit.PublicWrite {
+			out.WriteString("  🚨 " + g + "\n")
+		}
+		for _, g := range audit.PublicRead {
+			out.WriteString("  🚨 " + g + "\n")
+		}
+		for _, f := range audit.Findings {
+			out.WriteString("  " + f + "\n")
+		}
+		if len(audit.Unchecked) > 0 {
+			out.WriteString("  ❓ Could not check: " + strings.Join(audit.Unchecked, ", ") + "\n")
+		}
+	}
+
+	if len(exposed) > 0 {
+		out.WriteString(fmt.Sprintf("\n🚨 PUBLICLY EXPOSED (%d):\n", len(exposed)))
+		for _, audit := range exposed {
+			var access []string
+			if len(audit.PublicRead) > 0 {
+				access = append(access, "readable")
+			}
+			if len(audit.PublicWrite) > 0 {
+				access = append(access, "WRITABLE")
+			}
+			out.WriteString(fmt.Sprintf("\n%s: %s by anyone\n", audit.Name, strings.Join(access, " and ")))
+			writeBucket(audit)
+		}
+	} else {
+		out.WriteString("\n✅ No publicly readable or writable buckets\n")
+	}
+	for _, audit := range flagged {
+		out.WriteString("\n" + audit.Name + "\n")
+		writeBucket(audit)
+	}
+	if len(clean) > 0 {
+		out.WriteString(fmt.Sprintf("\n✅ No findings: %s\n", strings.Join(clean, ", ")))
+	}
+}
@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestS3PolicyPublicGrants(t *testing.T) {
+	policy := `{"Version":"2012-10-17","Statement":[
+		{"Sid":"PublicRead","Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::site/*"},
+		{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:PutObject","s3:ListBucket"],"Resource":"*"},
+		{"Sid":"VpcOnly","Effect":"Allow","Principal":"*","Action":"s3:*","Condition":{"StringEquals":{"aws:SourceVpce":"vpce-1"}}},
+		{"Sid":"Role","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::123456789012:role/app"},"Action":"s3:*"},
+		{"Sid":"DenyAll","Effect":"Deny","Principal":"*","Action":"s3:DeleteObject"}]}`
+	read, write := s3PolicyPublicGrants(policy)
+	if len(read) != 2 || !strings.Contains(read[0], "PublicRead allows s3:GetObject") || !strings.Contains(read[1], "#2 allows s3:ListBucket") {
+		t.Errorf("read grants = %v", read)
+	}
+	if len(write) != 1 || !strings.Contains(write[0], "#2 allows s3:PutObject") {
+		t.Errorf("write grants = %v", write)
+	}
+}
+
+func TestAuditS3BucketOperation(t *testing.T) {
+	notFound := func(code string) error {
+		return errors.New("An error occurred (" + code + ") when calling the operation")
+	}
+	f := newFakeCLI()
+	f.fixtures["s3api list-buckets"] = `["public-site","private-data","locked"]`
+	f.fixtures["sts get-caller-identity"] = "123456789012\n"
+	f.failures["s3control get-public-access-block"] = notFound("NoSuchPublicAccessBlockConfiguration")
+
+	f.failures["s3api get-public-access-block --bucket public-site"] = notFound("NoSuchPublicAccessBlockConfiguration")
+	f.fixtures["s3api get-bucket-policy --bucket public-site"] = `{"Policy":"{\"Statement\":[{\"Sid\":\"PublicRead\",\"Effect\":\"Allow\",\"Principal\":\"*\",\"Action\":\"s3:GetObject\"}]}"}`
+	f.fixtures["s3api get-bucket-policy-status --bucket public-site"] = `{"PolicyStatus":{"IsPublic":true}}`
+	f.fixtures["s3api get-bucket-acl --bucket public-site"] = `{"Grants":[{"Grantee":{"Type":"Group","URI":"http://acs.amazonaws.com/groups/global/AllUsers"},"Permission":"WRITE"}]}`
+	f.fixtures["s3api get-bucket-encryption --bucket public-site"] = `{"ServerSideEncryptionConfiguration":{"Rules":[{"ApplyServerSideEncryptionByDefault":{"SSEAlgorithm":"AES256"}}]}}`
+	f.fixtures["s3api get-bucket-versioning --bucket public-site"] = ``
+
+	allOn := `{"PublicAccessBlockConfiguration":{"BlockPublicAcls":true,"IgnorePublicAcls":true,"BlockPublicPolicy":true,"RestrictPublicBuckets":true}}`
+	f.fixtures["s3api get-public-access-block --bucket private-data"] = allOn
+	f.failures["s3api get-bucket-policy --bucket private-data"] = notFound("NoSuchBucketPolicy")
+	f.failures["s3api get-bucket-policy-status --bucket private-data"] = notFound("NoSuchBucketPolicy")
+	f.fixtures["s3api get-bucket-acl --bucket private-data"] = `{"Grants":[]}`
+	f.failures["s3api get-bucket-encryption --bucket private-data"] = notFound("AccessDenied")
+	f.fixtures["s3api get-bucket-versioning --bucket private-data"] = `{"Status":"Enabled"}`
+
+	// A public ACL grant that IgnorePublicAcls neutralizes.
+	f.fixtures["s3api get-public-access-block --bucket locked"] = allOn
+	f.failures["s3api get-bucket-policy --bucket locked"] = notFound("NoSuchBucketPolicy")
+	f.failures["s3api get-bucket-policy-status --bucket locked"] = notFound("NoSuchBucketPolicy")
+	f.fixtures["s3api get-bucket-acl --bucket locked"] = `{"Grants":[{"Grantee":{"URI":"http://acs.amazonaws.com/groups/global/AllUsers"},"Permission":"READ"}]}`
+	f.fixtures["s3api get-bucket-encryption --bucket locked"] = `{"ServerSideEncryptionConfiguration":{"Rules":[{"ApplyServerSideEncryptionByDefault":{"SSEAlgorithm":"aws:kms"}}]}}`
+	f.fixtures["s3api get-bucket-versioning --bucket locked"] = `{"Status":"Enabled"}`
+
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "audit_s3_bucket", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Account-level Block Public Access: off for BlockPublicAcls, IgnorePublicAcls, BlockPublicPolicy, RestrictPublicBuckets",
+		"PUBLICLY EXPOSED (1)",
+		"public-site: readable and WRITABLE by anyone",
+		"ACL grants WRITE to AllUsers",
+		"policy statement PublicRead allows s3:GetObject to everyone",
+		"Versioning is never enabled",
+		"❓ Could not check: default encryption",
+		"✅ No findings: locked",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "public-site") > strings.Index(out, "private-data") {
+		t.Errorf("expected exposed buckets listed first:\n%s", out)
+	}
+	if strings.Contains(out, "No default encryption") {
+		t.Errorf("an unreadable encryption setting should not be reported as missing:\n%s", out)
+	}
+}
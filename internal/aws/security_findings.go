@@ -0,0 +1,404 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// securityFindingsLimit bounds the findings read from each source.
+	securityFindingsLimit = 100
+	// guardDutyGetBatch is the most IDs guardduty get-findings takes per call.
+	guardDutyGetBatch    = 50
+	securityTopTypes     = 8
+	securityTopResources = 5
+	securityTypeExamples = 3
+)
+
+// securitySeverities orders finding severities from most to least urgent.
+var securitySeverities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}
+
+// urgentFindingPatterns mark findings that mean an active compromise rather
+// than a misconfiguration; they are listed before everything else. Patterns
+// match the lowercased type and title, first match wins.
+var urgentFindingPatterns = []struct{ pattern, label string }{
+	{"cryptocurrency", "crypto-mining"},
+	{"bitcoin", "crypto-mining"},
+	{"credentialexfiltration", "exfiltrated credentials"},
+	{"credentials_exposed", "exposed credentials"},
+	{"credentials exposed", "exposed credentials"},
+	{"key exposed", "exposed credentials"},
+	{"exfiltration", "data exfiltration"},
+	{"backdoor", "backdoor"},
+	{"trojan", "malware"},
+	{"malware", "malware"},
+	{"credentialaccess", "credential access"},
+}
+
+// securityFinding is a GuardDuty or Security Hub finding in one shape.
+type securityFinding struct {
+	ID       string
+	Source   string
+	Severity string
+	Type     string
+	Resource string
+	Count    int
+	Urgent   string
+}
+
+// getSecurityFindings is the get_security_findings operation: it reads
+// active GuardDuty and Security Hub findings, drops Security Hub's copies of
+// GuardDuty findings and duplicate control results, and summarizes them by
+// severity, finding type and affected resource with compromises first.
+func (c *Client) getSecurityFindings(ctx context.Context, _ map[string]interface{}, profile *AIProfile) (string, error) {
+	var out strings.Builder
+	out.WriteString("🛡️  SECURITY FINDINGS\n")
+	out.WriteString("============================\n")
+
+	guardDuty, gdStatus := c.guardDutyFindings(ctx, profile)
+	out.WriteString("GuardDuty: " + gdStatus + "\n")
+	hub, hubStatus := c.securityHubFindings(ctx, guardDuty, profile)
+	out.WriteString("Security Hub: " + hubStatus + "\n")
+
+	findings := append(guardDuty, hub...)
+	if len(findings) == 0 {
+		out.WriteString("\n✅ No active findings.\n")
+		return out.String(), nil
+	}
+	out.WriteString(formatSecurityFindings(findings))
+	return out.String(), nil
+}
+
+// guardDutyFindings returns the unarchived findings of every detector,
+// most severe first, and a status line for the source.
+func (c *Client) guardDutyFindings(ctx context.Context, profile *AIProfile) ([]securityFinding, string) {
+	raw, err := c.execAWSCLI(ctx, []string{"guardduty", "list-detectors", "--output", "json"}, profile)
+	if err != nil {
+		return nil, categorizeAWSError(err, "GuardDuty")
+	}
+	var detectors struct {
+		DetectorIDs []string `json:"DetectorIds"`
+	}
+	if err := json.Unmarshal([]byte(raw), &detectors); err != nil {
+		return nil, fmt.Sprintf("failed to parse detectors: %v", err)
+	}
+	if len(detectors.DetectorIDs) == 0 {
+		return nil, "not enabled in this region (no detectors)"
+	}
+
+	var findings []securityFinding
+	for _, detector := range detectors.DetectorIDs {
+		raw, err := c.execAWSCLI(ctx, []string{"guardduty", "list-findings", "--detector-id", detector,
+			"--finding-criteria", `{"Criterion":{"service.archived":{"Eq":["false"]}}}`,
+			"--sort-criteria", `{"AttributeName":"severity","OrderBy":"DESC"}`,
+			"--max-items", fmt.Sprint(securityFindingsLimit), "--output", "json"}, profile)
+		if err != nil {
+			return findings, categorizeAWSError(err, "GuardDuty")
+		}
+		var list struct {
+			FindingIDs []string `json:"FindingIds"`
+		}
+		if err := json.Unmarshal([]byte(raw), &list); err != nil {
+			return findings, fmt.Sprintf("failed to parse findings: %v", err)
+		}
+		for i := 0; i < len(list.FindingIDs); i += guardDutyGetBatch {
+			args := []string{"guardduty", "get-findings", "--detector-id", detector, "--finding-ids"}
+			args = append(args, list.FindingIDs[i:min(i+guardDutyGetBatch, len(list.FindingIDs))]...)
+			raw, err := c.execAWSCLI(ctx, append(args, "--output", "json"), profile)
+			if err != nil {
+				return findings, categorizeAWSError(err, "GuardDuty")
+			}
+			batch, err := parseGuardDutyFindings(raw)
+			if err != nil {
+				return findings, fmt.Sprintf("failed to parse findings: %v", err)
+			}
+			findings = append(findings, batch...)
+		}
+	}
+	return findings, fmt.Sprintf("%d active findings (%d detector(s))", len(findings), len(detectors.DetectorIDs))
+}
+
+func parseGuardDutyFindings(raw string) ([]securityFinding, error) {
+	var resp struct {
+		Findings []struct {
+			ID       string  `json:"Id"`
+			Type     string  `json:"Type"`
+			Severity float64 `json:"Severity"`
+			Resource struct {
+				ResourceType    string `json:"ResourceType"`
+				InstanceDetails struct {
+					InstanceID string `json:"InstanceId"`
+				} `json:"InstanceDetails"`
+				AccessKeyDetails struct {
+					AccessKeyID string `json:"AccessKeyId"`
+					UserName    string `json:"UserName"`
+				} `json:"AccessKeyDetails"`
+				S3BucketDetails []struct {
+					Name string `json:"Name"`
+				} `json:"S3BucketDetails"`
+				EksClusterDetails struct {
+					Name string `json:"Name"`
+				} `json:"EksClusterDetails"`
+			} `json:"Resource"`
+			Service struct {
+				Count int `json:"Count"`
+			} `json:"Service"`
+		} `json:"Findings"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, err
+	}
+	findings := make([]securityFinding, 0, len(resp.Findings))
+	for _, f := range resp.Findings {
+		r := f.Resource
+		resource := r.ResourceType
+		switch {
+		case r.InstanceDetails.InstanceID != "":
+			resource = r.InstanceDetails.InstanceID
+		case r.AccessKeyDetails.AccessKeyID != "":
+			resource = strings.TrimSpace(r.AccessKeyDetails.UserName + " " + r.AccessKeyDetails.AccessKeyID)
+		case len(r.S3BucketDetails) > 0:
+			resource = r.S3BucketDetails[0].Name
+		case r.EksClusterDetails.Name != "":
+			resource = r.EksClusterDetails.Name
+		}
+		findings = append(findings, securityFinding{
+			ID:       f.ID,
+			Source:   "GuardDuty",
+			Severity: guardDutySeverity(f.Severity),
+			Type:     f.Type,
+			Resource: resource,
+			Count:    max(f.Service.Count, 1),
+			Urgent:   urgentFindingLabel(f.Type),
+		})
+	}
+	return findings, nil
+}
+
+// guardDutySeverity maps GuardDuty's numeric severity to its label.
+func guardDutySeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return "CRITICAL"
+	case score >= 7:
+		return "HIGH"
+	case score >= 4:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+// securityHubFindings returns the most severe active, unresolved Security Hub
+// findings that are not copies of guardDuty findings or repeats of the same
+// check on the same resource, and a status line for the source.
+func (c *Client) securityHubFindings(ctx context.Context, guardDuty []securityFinding, profile *AIProfile) ([]securityFinding, string) {
+	raw, err := c.execAWSCLI(ctx, []string{"securityhub", "get-findings",
+		"--filters", `{"RecordState":[{"Value":"ACTIVE","Comparison":"EQUALS"}],"WorkflowStatus":[{"Value":"NEW","Comparison":"EQUALS"},{"Value":"NOTIFIED","Comparison":"EQUALS"}]}`,
+		"--sort-criteria", `[{"Field":"SeverityNormalized","SortOrder":"desc"}]`,
+		"--max-items", fmt.Sprint(securityFindingsLimit), "--output", "json"}, profile)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not subscribed") {
+			return nil, "not enabled in this region"
+		}
+		return nil, categorizeAWSError(err, "Security Hub")
+	}
+	var resp struct {
+		Findings []struct {
+			ID          string `json:"Id"`
+			Title       string `json:"Title"`
+			ProductName string `json:"ProductName"`
+			Severity    struct {
+				Label string `json:"Label"`
+			} `json:"Severity"`
+			Compliance struct {
+				Status string `json:"Status"`
+			} `json:"Compliance"`
+			Resources []struct {
+				ID string `json:"Id"`
+			} `json:"Resources"`
+		} `json:"Findings"`
+		NextToken string `json:"NextToken"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Sprintf("failed to parse findings: %v", err)
+	}
+
+	seenGuardDuty := make(map[string]bool, len(guardDuty))
+	for _, f := range guardDuty {
+		seenGuardDuty[f.ID] = true
+	}
+	seen := make(map[string]bool)
+	var findings []securityFinding
+	duplicates := 0
+	for _, f := range resp.Findings {
+		severity := strings.ToUpper(f.Severity.Label)
+		if f.Compliance.Status == "PASSED" || severity == "INFORMATIONAL" {
+			continue
+		}
+		if f.ProductName == "GuardDuty" && seenGuardDuty[f.ID[strings.LastIndex(f.ID, "/")+1:]] {
+			duplicates++
+			continue
+		}
+		resource := ""
+		if len(f.Resources) > 0 {
+			resource = shortResourceID(f.Resources[0].ID)
+		}
+		key := f.Title + "\x00" + resource
+		if seen[key] {
+			duplicates++
+			continue
+		}
+		seen[key] = true
+		findings = append(findings, securityFinding{
+			ID:       f.ID,
+			Source:   valueOr(f.ProductName, "Security Hub"),
+			Severity: severity,
+			Type:     f.Title,
+			Resource: resource,
+			Count:    1,
+			Urgent:   urgentFindingLabel(f.Title),
+		})
+	}
+	status := fmt.Sprintf("%d active findings", len(findings))
+	if duplicates > 0 {
+		status += fmt.Sprintf(" (%d duplicates dropped)", duplicates)
+	}
+	if resp.NextToken != "" {
+		status += fmt.Sprintf("; truncated to the %d most severe, more exist", securityFindingsLimit)
+	}
+	return findings, status
+}
+
+// shortResourceID trims an ARN to its resource part.
+func shortResourceID(id string) string {
+	if strings.HasPrefix(id, "arn:") {
+		if i := strings.LastIndex(id, ":"); i >= 0 && i < len(id)-1 {
+			return id[i+1:]
+		}
+	}
+	return id
+}
+
+func urgentFindingLabel(text string) string {
+	lower := strings.ToLower(text)
+	for _, p := range urgentFindingPatterns {
+		if strings.Contains(lower, p.pattern) {
+			return p.label
+		}
+	}
+	return ""
+}
+
+func severityRank(severity string) int {
+	for i, s := range securitySeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(securitySeverities)
+}
+
+func formatSecurityFindings(findings []securityFinding) string {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank(findings[i].Severity) < severityRank(findings[j].Severity)
+	})
+
+	var out strings.Builder
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	var parts []string
+	for _, severity := range securitySeverities {
+		if n := counts[severity]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", severity, n))
+		}
+	}
+	out.WriteString("\nBy severity: " + strings.Join(parts, ", ") + "\n")
+
+	var urgent []securityFinding
+	for _, f := range findings {
+		if f.Urgent != "" {
+			urgent = append(urgent, f)
+		}
+	}
+	if len(urgent) > 0 {
+		out.WriteString("\n🚨 Possible compromise:\n")
+		for _, f := range urgent {
+			line := fmt.Sprintf("  - [%s] %s: %s on %s (%s", f.Severity, f.Urgent, f.Type, valueOr(f.Resource, "(unknown resource)"), f.Source)
+			if f.Count > 1 {
+				line += fmt.Sprintf(", seen %d times", f.Count)
+			}
+			out.WriteString(line + ")\n")
+		}
+	}
+
+	type group struct {
+		severity  string
+		findings  int
+		resources []string
+	}
+	types := make(map[string]*group)
+	var typeOrder []string
+	resources := make(map[string]*group)
+	var resourceOrder []string
+	for _, f := range findings {
+		g := types[f.Type]
+		if g == nil {
+			g = &group{severity: f.Severity}
+			types[f.Type] = g
+			typeOrder = append(typeOrder, f.Type)
+		}
+		g.findings++
+		if f.Resource != "" && !containsString(g.resources, f.Resource) {
+			g.resources = append(g.resources, f.Resource)
+		}
+		if f.Resource == "" {
+			continue
+		}
+		r := resources[f.Resource]
+		if r == nil {
+			r = &group{severity: f.Severity}
+			resources[f.Resource] = r
+			resourceOrder = append(resourceOrder, f.Resource)
+		}
+		r.findings++
+	}
+	// Findings are sorted by severity, so a group's first severity is its
+	// worst and a stable sort on count keeps severity as the tie-breaker.
+	sort.SliceStable(typeOrder, func(i, j int) bool {
+		a, b := types[typeOrder[i]], types[typeOrder[j]]
+		if ra, rb := severityRank(a.severity), severityRank(b.severity); ra != rb {
+			return ra < rb
+		}
+		return a.findings > b.findings
+	})
+	out.WriteString("\nTop finding types:\n")
+	for _, t := range limitStrings(typeOrder, securityTopTypes) {
+		g := types[t]
+		line := fmt.Sprintf("  - [%s] %s: %d finding(s)", g.severity, t, g.findings)
+		if len(g.resources) > 0 {
+			line += " on " + strings.Join(limitStrings(g.resources, securityTypeExamples), ", ")
+			if len(g.resources) > securityTypeExamples {
+				line += fmt.Sprintf(" and %d more", len(g.resources)-securityTypeExamples)
+			}
+		}
+		out.WriteString(line + "\n")
+	}
+
+	sort.SliceStable(resourceOrder, func(i, j int) bool {
+		return resources[resourceOrder[i]].findings > resources[resourceOrder[j]].findings
+	})
+	if len(resourceOrder) > 0 {
+		out.WriteString("\nMost affected resources:\n")
+		for _, name := range limitStrings(resourceOrder, securityTopResources) {
+			r := resources[name]
+			out.WriteString(fmt.Sprintf("  - %s: %d finding(s), worst %s\n", name, r.findings, r.severity))
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGetSecurityFindings(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["guardduty list-detectors"] = `{"DetectorIds": ["det1"]}`
+	f.fixtures["guardduty list-findings"] = `{"FindingIds": ["gd-1", "gd-2"]}`
+	f.fixtures["guardduty get-findings"] = `{"Findings": [
+		{"Id": "gd-1", "Type": "CryptoCurrency:EC2/BitcoinTool.B!DNS", "Severity": 8,
+		 "Resource": {"ResourceType": "Instance", "InstanceDetails": {"InstanceId": "i-0abc"}}, "Service": {"Count": 14}},
+		{"Id": "gd-2", "Type": "Recon:EC2/PortProbeUnprotectedPort", "Severity": 2,
+		 "Resource": {"ResourceType": "Instance", "InstanceDetails": {"InstanceId": "i-0abc"}}, "Service": {"Count": 3}}]}`
+	f.fixtures["securityhub get-findings"] = `{"Findings": [
+		{"Id": "arn:aws:guardduty:us-east-1:123456789012:detector/det1/finding/gd-1", "ProductName": "GuardDuty", "Title": "EC2 instance is querying a domain associated with Bitcoin", "Severity": {"Label": "HIGH"}, "Resources": [{"Id": "arn:aws:ec2:us-east-1:123456789012:instance/i-0abc"}]},
+		{"Id": "fsbp/S3.8/1", "ProductName": "Security Hub", "Title": "S3 general purpose buckets should block public access", "Severity": {"Label": "CRITICAL"}, "Compliance": {"Status": "FAILED"}, "Resources": [{"Id": "arn:aws:s3:::prod-uploads"}]},
+		{"Id": "cis/2.1.5/1", "ProductName": "Security Hub", "Title": "S3 general purpose buckets should block public access", "Severity": {"Label": "CRITICAL"}, "Compliance": {"Status": "FAILED"}, "Resources": [{"Id": "arn:aws:s3:::prod-uploads"}]},
+		{"Id": "fsbp/IAM.6/1", "ProductName": "Security Hub", "Title": "Hardware MFA should be enabled for the root user", "Severity": {"Label": "CRITICAL"}, "Compliance": {"Status": "PASSED"}, "Resources": [{"Id": "AWS::::Account:123456789012"}]}],
+		"NextToken": "eyJOZXh0VG9rZW4iOiBudWxsfQ=="}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "get_security_findings", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"GuardDuty: 2 active findings (1 detector(s))",
+		"Security Hub: 1 active findings (2 duplicates dropped); truncated to the 100 most severe, more exist",
+		"By severity: CRITICAL 1, HIGH 1, LOW 1",
+		"🚨 Possible compromise:\n  - [HIGH] crypto-mining: CryptoCurrency:EC2/BitcoinTool.B!DNS on i-0abc (GuardDuty, seen 14 times)",
+		"Top finding types:\n  - [CRITICAL] S3 general purpose buckets should block public access: 1 finding(s) on prod-uploads\n  - [HIGH] CryptoCurrency:EC2/BitcoinTool.B!DNS",
+		"Most affected resources:\n  - i-0abc: 2 finding(s), worst HIGH",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Hardware MFA") {
+		t.Errorf("passed controls should be skipped:\n%s", out)
+	}
+	if !strings.Contains(strings.Join(f.calls, "\n"), "SeverityNormalized") {
+		t.Errorf("expected Security Hub findings sorted by severity: %v", f.calls)
+	}
+
+	f = newFakeCLI()
+	f.fixtures["guardduty list-detectors"] = `{"DetectorIds": []}`
+	f.failures["securityhub get-findings"] = errors.New("InvalidAccessException: Account 123456789012 is not subscribed to AWS Security Hub")
+	out, _ = newFakeClient(f).executeAWSOperation(context.Background(), "get_security_findings", map[string]interface{}{}, &AIProfile{})
+	for _, want := range []string{"GuardDuty: not enabled in this region (no detectors)", "Security Hub: not enabled in this region", "✅ No active findings."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
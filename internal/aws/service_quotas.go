@@ -0,0 +1,213 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// serviceQuotaUsageWindow is how far back get_service_quotas looks for
+	// the peak usage of each quota.
+	serviceQuotaUsageWindow = time.Hour
+	serviceQuotaUsagePeriod = 300
+	// serviceQuotaMaxUsageLookups bounds the CloudWatch fan-out per service.
+	serviceQuotaMaxUsageLookups = 25
+	// serviceQuotaWarnPercent flags quotas that are close to being hit.
+	serviceQuotaWarnPercent = 80.0
+)
+
+// serviceQuotaAliases maps common service names to Service Quotas service codes.
+var serviceQuotaAliases = map[string]string{
+	"api_gateway": "apigateway",
+	"api-gateway": "apigateway",
+	"apigw":       "apigateway",
+	"ddb":         "dynamodb",
+	"elb":         "elasticloadbalancing",
+	"alb":         "elasticloadbalancing",
+	"cloudwatch":  "monitoring",
+}
+
+type serviceQuotaUsageMetric struct {
+	Namespace      string            `json:"MetricNamespace"`
+	Name           string            `json:"MetricName"`
+	Dimensions     map[string]string `json:"MetricDimensions"`
+	Recommendation string            `json:"MetricStatisticRecommendation"`
+}
+
+type serviceQuota struct {
+	ServiceCode string                   `json:"ServiceCode"`
+	QuotaCode   string                   `json:"QuotaCode"`
+	QuotaName   string                   `json:"QuotaName"`
+	Value       float64                  `json:"Value"`
+	Unit        string                   `json:"Unit"`
+	Adjustable  bool                     `json:"Adjustable"`
+	UsageMetric *serviceQuotaUsageMetric `json:"UsageMetric"`
+}
+
+// serviceQuotaUsage is a quota with its peak usage over the window, when
+// CloudWatch publishes a usage metric for it.
+type serviceQuotaUsage struct {
+	Quota       serviceQuota
+	Usage       float64
+	HasUsage    bool
+	Utilization float64
+}
+
+// normalizeServiceQuotaCode turns a service name such as "Lambda" or
+// "api_gateway" into a Service Quotas service code.
+func normalizeServiceQuotaCode(service string) string {
+	code := strings.ToLower(strings.TrimSpace(service))
+	if alias, ok := serviceQuotaAliases[code]; ok {
+		return alias
+	}
+	return code
+}
+
+// getServiceQuotas is the get_service_quotas operation: it lists a service's
+// quotas and, where CloudWatch publishes a usage metric, reports the peak
+// usage over the last hour as a percentage of the quota.
+func (c *Client) getServiceQuotas(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	code := normalizeServiceQuotaCode(getStringParam(input, "service_code", getStringParam(input, "service", "")))
+	if code == "" {
+		return "", fmt.Errorf("service_code parameter required (e.g. lambda, ec2, vpc)")
+	}
+	filter := strings.ToLower(getStringParam(input, "quota_name", ""))
+
+	quotas, err := c.listServiceQuotas(ctx, []string{"service-quotas", "list-service-quotas", "--service-code", code, "--output", "json"}, profile)
+	if err != nil {
+		return categorizeAWSError(err, "Service Quotas"), nil
+	}
+	// Accounts that never requested an increase only have default quotas.
+	if len(quotas) == 0 {
+		quotas, err = c.listServiceQuotas(ctx, []string{"service-quotas", "list-aws-default-service-quotas", "--service-code", code, "--output", "json"}, profile)
+		if err != nil {
+			return categorizeAWSError(err, "Service Quotas"), nil
+		}
+	}
+
+	var usages []serviceQuotaUsage
+	lookups := 0
+	end := time.Now().UTC()
+	for _, q := range quotas {
+		if filter != "" && !strings.Contains(strings.ToLower(q.QuotaName), filter) {
+			continue
+		}
+		u := serviceQuotaUsage{Quota: q}
+		if q.UsageMetric != nil && q.UsageMetric.Namespace != "" && lookups < serviceQuotaMaxUsageLookups {
+			lookups++
+			req := serviceQuotaUsageRequest(*q.UsageMetric)
+			if raw, err := c.execAWSCLI(ctx, metricStatisticsArgs(req, end), profile); err == nil {
+				if points, err := decodeMetricDatapoints(raw, req); err == nil && len(points) > 0 {
+					_, peak, _ := summarizeMetricDatapoints(points)
+					u.Usage, u.HasUsage = peak, true
+					if q.Value > 0 {
+						u.Utilization = peak / q.Value * 100
+					}
+				}
+			}
+		}
+		usages = append(usages, u)
+	}
+	return formatServiceQuotas(code, usages), nil
+}
+
+func (c *Client) listServiceQuotas(ctx context.Context, args []string, profile *AIProfile) ([]serviceQuota, error) {
+	raw, err := c.execAWSCLI(ctx, args, profile)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Quotas []serviceQuota `json:"Quotas"`
+	}
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse service quotas: %w", err)
+	}
+	return resp.Quotas, nil
+}
+
+// serviceQuotaUsageRequest builds the metric request for a quota's usage
+// metric using the statistic Service Quotas recommends.
+func serviceQuotaUsageRequest(m serviceQuotaUsageMetric) metricStatisticsRequest {
+	stat := "Maximum"
+	if standard, ok := standardMetricStatistics[strings.ToLower(m.Recommendation)]; ok {
+		stat = standard
+	}
+	dims := make([]metricDimension, 0, len(m.Dimensions))
+	for name, value := range m.Dimensions {
+		dims = append(dims, metricDimension{Name: name, Value: value})
+	}
+	sort.Slice(dims, func(i, j int) bool { return dims[i].Name < dims[j].Name })
+	return metricStatisticsRequest{
+		Namespace:  m.Namespace,
+		MetricName: m.Name,
+		Dimensions: dims,
+		Period:     serviceQuotaUsagePeriod,
+		Stat:       stat,
+		Window:     serviceQuotaUsageWindow,
+	}
+}
+
+// formatServiceQuotas lists quotas with known usage first, highest
+// utilization first, followed by the remaining quota values.
+func formatServiceQuotas(code string, usages []serviceQuotaUsage) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("📏 Service quotas for %s\n", code))
+	out.WriteString("============================\n")
+	if len(usages) == 0 {
+		out.WriteString("No quotas found for this service code.\n")
+		return out.String()
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		if usages[i].HasUsage != usages[j].HasUsage {
+			return usages[i].HasUsage
+		}
+		if usages[i].HasUsage {
+			return usages[i].Utilization > usages[j].Utilization
+		}
+		return usages[i].Quota.QuotaName < usages[j].Quota.QuotaName
+	})
+
+	nearLimit := 0
+	for _, u := range usages {
+		if u.HasUsage && u.Utilization >= serviceQuotaWarnPercent {
+			nearLimit++
+		}
+	}
+	if nearLimit > 0 {
+		out.WriteString(fmt.Sprintf("⚠️  %d quota(s) at or above %.0f%% utilization\n", nearLimit, serviceQuotaWarnPercent))
+	}
+
+	for _, u := range usages {
+		q := u.Quota
+		adjustable := ""
+		if q.Adjustable {
+			adjustable = ", adjustable"
+		}
+		if !u.HasUsage {
+			out.WriteString(fmt.Sprintf("  • %s (%s): limit %s%s\n", q.QuotaName, q.QuotaCode, formatQuotaValue(q.Value), adjustable))
+			continue
+		}
+		marker := "✅"
+		if u.Utilization >= serviceQuotaWarnPercent {
+			marker = "⚠️ "
+		}
+		out.WriteString(fmt.Sprintf("  %s %s (%s): %s of %s used, %.1f%% (peak over last %s%s)\n",
+			marker, q.QuotaName, q.QuotaCode, formatQuotaValue(u.Usage), formatQuotaValue(q.Value), u.Utilization, serviceQuotaUsageWindow, adjustable))
+	}
+	return out.String()
+}
+
+func formatQuotaValue(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
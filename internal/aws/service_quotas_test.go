@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeServiceQuotaCode(t *testing.T) {
+	for in, want := range map[string]string{
+		" Lambda ":    "lambda",
+		"api_gateway": "apigateway",
+		"vpc":         "vpc",
+		"":            "",
+	} {
+		if got := normalizeServiceQuotaCode(in); got != want {
+			t.Errorf("normalizeServiceQuotaCode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestServiceQuotaUsageRequest(t *testing.T) {
+	req := serviceQuotaUsageRequest(serviceQuotaUsageMetric{
+		Namespace:      "AWS/Usage",
+		Name:           "ResourceCount",
+		Dimensions:     map[string]string{"Type": "Resource", "Service": "Lambda", "Resource": "ConcurrentExecutions", "Class": "None"},
+		Recommendation: "Maximum",
+	})
+	if req.Stat != "Maximum" || req.Extended {
+		t.Errorf("stat = %q (extended %v), want Maximum", req.Stat, req.Extended)
+	}
+	if len(req.Dimensions) != 4 || req.Dimensions[0].Name != "Class" {
+		t.Errorf("dimensions not sorted by name: %+v", req.Dimensions)
+	}
+}
+
+func TestFormatServiceQuotas(t *testing.T) {
+	out := formatServiceQuotas("lambda", []serviceQuotaUsage{
+		{Quota: serviceQuota{QuotaName: "Function and layer storage", QuotaCode: "L-2ACBD22F", Value: 75}},
+		{Quota: serviceQuota{QuotaName: "Concurrent executions", QuotaCode: "L-B99A9384", Value: 1000, Adjustable: true}, Usage: 920, HasUsage: true, Utilization: 92},
+		{Quota: serviceQuota{QuotaName: "Elastic network interfaces", QuotaCode: "L-9FEE3D26", Value: 250}, Usage: 25, HasUsage: true, Utilization: 10},
+	})
+
+	if !strings.Contains(out, "1 quota(s) at or above 80% utilization") {
+		t.Errorf("expected near-limit summary:\n%s", out)
+	}
+	concurrent := strings.Index(out, "Concurrent executions")
+	eni := strings.Index(out, "Elastic network interfaces")
+	storage := strings.Index(out, "Function and layer storage")
+	if concurrent < 0 || eni < concurrent || storage < eni {
+		t.Errorf("expected quotas ordered by utilization, then unmeasured:\n%s", out)
+	}
+	if !strings.Contains(out, "920 of 1000 used, 92.0%") {
+		t.Errorf("expected utilization line:\n%s", out)
+	}
+}
@@ -0,0 +1,261 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Drift detection runs asynchronously in CloudFormation; these bound how long
+// detect_stack_drift waits for it (about a minute by default).
+var (
+	driftPollInterval = 3 * time.Second
+	driftMaxPolls     = 20
+)
+
+const (
+	driftMaxStacks     = 20
+	driftMaxValueChars = 80
+)
+
+// stackDriftCheck tracks one stack's drift detection run.
+type stackDriftCheck struct {
+	Stack       string
+	DetectionID string
+	Status      string // DETECTION_IN_PROGRESS, DETECTION_COMPLETE or DETECTION_FAILED
+	Reason      string
+	DriftStatus string // DRIFTED, IN_SYNC, UNKNOWN or NOT_CHECKED
+	Drifted     int
+	Err         string
+	Resources   []stackResourceDrift
+}
+
+type stackResourceDrift struct {
+	LogicalResourceID   string `json:"LogicalResourceId"`
+	PhysicalResourceID  string `json:"PhysicalResourceId"`
+	ResourceType        string `json:"ResourceType"`
+	DriftStatus         string `json:"StackResourceDriftStatus"`
+	PropertyDifferences []struct {
+		PropertyPath   string `json:"PropertyPath"`
+		ExpectedValue  string `json:"ExpectedValue"`
+		ActualValue    string `json:"ActualValue"`
+		DifferenceType string `json:"DifferenceType"`
+	} `json:"PropertyDifferences"`
+}
+
+// detectStackDrift is the detect_stack_drift operation: it starts drift
+// detection on one stack (stack_name), the stacks named in query, or every
+// active stack, polls until detection finishes or the poll budget runs out,
+// and reports MODIFIED and DELETED resources with their property differences.
+func (c *Client) detectStackDrift(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	stacks, skipped, err := c.driftStacks(ctx, input, profile)
+	if err != nil {
+		return categorizeAWSError(err, "CloudFormation"), nil
+	}
+
+	var out strings.Builder
+	out.WriteString("🧭 CloudFormation stack drift\n")
+	out.WriteString("============================\n")
+	if len(stacks) == 0 {
+		out.WriteString("No active CloudFormation stacks found\n")
+		return out.String(), nil
+	}
+
+	checks := make([]*stackDriftCheck, 0, len(stacks))
+	for _, stack := range stacks {
+		check := &stackDriftCheck{Stack: stack}
+		checks = append(checks, check)
+		raw, err := c.execAWSCLI(ctx, []string{"cloudformation", "detect-stack-drift", "--stack-name", stack, "--output", "json"}, profile)
+		if err != nil {
+			check.Err = categorizeAWSError(err, "CloudFormation")
+			continue
+		}
+		var started struct {
+			StackDriftDetectionID string `json:"StackDriftDetectionId"`
+		}
+		if err := json.Unmarshal([]byte(raw), &started); err != nil || started.StackDriftDetectionID == "" {
+			check.Err = "could not start drift detection"
+			continue
+		}
+		check.DetectionID = started.StackDriftDetectionID
+		check.Status = "DETECTION_IN_PROGRESS"
+	}
+
+	if err := c.pollStackDrift(ctx, checks, profile); err != nil {
+		return "", err
+	}
+
+	for _, check := range checks {
+		if check.Status != "DETECTION_COMPLETE" || check.DriftStatus != "DRIFTED" {
+			continue
+		}
+		raw, err := c.execAWSCLI(ctx, []string{"cloudformation", "describe-stack-resource-drifts", "--stack-name", check.Stack,
+			"--stack-resource-drift-status-filters", "MODIFIED", "DELETED", "--output", "json"}, profile)
+		if err != nil {
+			check.Err = categorizeAWSError(err, "CloudFormation")
+			continue
+		}
+		var resp struct {
+			StackResourceDrifts []stackResourceDrift `json:"StackResourceDrifts"`
+		}
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			check.Err = "could not parse resource drifts"
+			continue
+		}
+		check.Resources = resp.StackResourceDrifts
+		sort.SliceStable(check.Resources, func(i, j int) bool {
+			return check.Resources[i].LogicalResourceID < check.Resources[j].LogicalResourceID
+		})
+	}
+
+	formatStackDrift(&out, checks)
+	if skipped > 0 {
+		out.WriteString(fmt.Sprintf("\n… %d more stacks not checked; pass stack_name to check one\n", skipped))
+	}
+	return out.String(), nil
+}
+
+// driftStacks picks the stacks to check and how many were left out by the
+// driftMaxStacks cap.
+func (c *Client) driftStacks(ctx context.Context, input map[string]interface{}, profile *AIProfile) ([]string, int, error) {
+	if name := strings.TrimSpace(getStringParam(input, "stack_name", "")); name != "" {
+		return []string{name}, 0, nil
+	}
+
+	raw, err := c.execAWSCLI(ctx, []string{"cloudformation", "list-stacks", "--stack-status-filter",
+		"CREATE_COMPLETE", "UPDATE_COMPLETE", "UPDATE_ROLLBACK_COMPLETE", "IMPORT_COMPLETE", "IMPORT_ROLLBACK_COMPLETE",
+		"--output", "json", "--query", "StackSummaries[].StackName"}, profile)
+	if err != nil {
+		return nil, 0, err
+	}
+	var all []string
+	if err := json.Unmarshal([]byte(raw), &all); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse stack list: %w", err)
+	}
+	sort.Strings(all)
+
+	if query := strings.ToLower(getStringParam(input, "query", "")); query != "" {
+		var named []string
+		for _, stack := range all {
+			if strings.Contains(query, strings.ToLower(stack)) {
+				named = append(named, stack)
+			}
+		}
+		if len(named) > 0 {
+			all = named
+		}
+	}
+	if len(all) > driftMaxStacks {
+		return all[:driftMaxStacks], len(all) - driftMaxStacks, nil
+	}
+	return all, 0, nil
+}
+
+// pollStackDrift waits for every started detection to finish, checking at
+// most driftMaxPolls times. Detections still running afterwards keep the
+// DETECTION_IN_PROGRESS status.
+func (c *Client) pollStackDrift(ctx context.Context, checks []*stackDriftCheck, profile *AIProfile) error {
+	for poll := 0; poll < driftMaxPolls; poll++ {
+		pending := 0
+		for _, check := range checks {
+			if check.Status != "DETECTION_IN_PROGRESS" {
+				continue
+			}
+			raw, err := c.execAWSCLI(ctx, []string{"cloudformation", "describe-stack-drift-detection-status",
+				"--stack-drift-detection-id", check.DetectionID, "--output", "json"}, profile)
+			if err != nil {
+				check.Status = ""
+				check.Err = categorizeAWSError(err, "CloudFormation")
+				continue
+			}
+			var status struct {
+				DetectionStatus           string `json:"DetectionStatus"`
+				DetectionStatusReason     string `json:"DetectionStatusReason"`
+				StackDriftStatus          string `json:"StackDriftStatus"`
+				DriftedStackResourceCount int    `json:"DriftedStackResourceCount"`
+			}
+			if err := json.Unmarshal([]byte(raw), &status); err != nil {
+				check.Status = ""
+				check.Err = "could not parse drift detection status"
+				continue
+			}
+			check.Status = status.DetectionStatus
+			check.Reason = status.DetectionStatusReason
+			check.DriftStatus = status.StackDriftStatus
+			check.Drifted = status.DriftedStackResourceCount
+			if check.Status == "DETECTION_IN_PROGRESS" {
+				pending++
+			}
+		}
+		if pending == 0 || poll == driftMaxPolls-1 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(driftPollInterval):
+		}
+	}
+	return nil
+}
+
+// formatStackDrift lists drifted stacks first, then stacks that could not be
+// checked, then the in-sync count.
+func formatStackDrift(out *strings.Builder, checks []*stackDriftCheck) {
+	var inSync []string
+	var problems []string
+	drifted := 0
+	for _, check := range checks {
+		switch {
+		case check.Err != "":
+			problems = append(problems, fmt.Sprintf("❓ %s: %s", check.Stack, check.Err))
+		case check.Status == "DETECTION_IN_PROGRESS":
+			problems = append(problems, fmt.Sprintf("⏳ %s: detection still running after %s; run again shortly", check.Stack, driftPollInterval*time.Duration(driftMaxPolls)))
+		case check.Status == "DETECTION_FAILED":
+			problems = append(problems, fmt.Sprintf("❓ %s: detection failed: %s", check.Stack, check.Reason))
+		case check.DriftStatus == "DRIFTED":
+			drifted++
+			out.WriteString(fmt.Sprintf("\n⚠️ %s: DRIFTED (%d resources)\n", check.Stack, check.Drifted))
+			for _, r := range check.Resources {
+				id := r.LogicalResourceID
+				if r.PhysicalResourceID != "" && r.PhysicalResourceID != id {
+					id += " (" + r.PhysicalResourceID + ")"
+				}
+				out.WriteString(fmt.Sprintf("  • %s %s: %s\n", r.ResourceType, id, r.DriftStatus))
+				for _, d := range r.PropertyDifferences {
+					out.WriteString(fmt.Sprintf("      %s %s: expected %s, actual %s\n", d.DifferenceType, d.PropertyPath,
+						truncateDriftValue(d.ExpectedValue), truncateDriftValue(d.ActualValue)))
+				}
+			}
+		default:
+			inSync = append(inSync, check.Stack)
+		}
+	}
+
+	if drifted == 0 && len(problems) == 0 {
+		out.WriteString(fmt.Sprintf("✅ All %d stacks match their templates\n", len(inSync)))
+		return
+	}
+	if len(problems) > 0 {
+		out.WriteString("\n")
+		for _, p := range problems {
+			out.WriteString(p + "\n")
+		}
+	}
+	if len(inSync) > 0 {
+		out.WriteString(fmt.Sprintf("\n✅ In sync: %s\n", strings.Join(inSync, ", ")))
+	}
+}
+
+func truncateDriftValue(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	if len(v) > driftMaxValueChars {
+		return v[:driftMaxValueChars] + "…"
+	}
+	return v
+}
@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func stackDriftFixtures() *fakeCLI {
+	f := newFakeCLI()
+	f.fixtures["cloudformation list-stacks"] = `["web", "orders-db", "network"]`
+	f.fixtures["cloudformation detect-stack-drift --stack-name web"] = `{"StackDriftDetectionId": "det-web"}`
+	f.fixtures["cloudformation detect-stack-drift --stack-name orders-db"] = `{"StackDriftDetectionId": "det-db"}`
+	f.fixtures["cloudformation detect-stack-drift --stack-name network"] = `{"StackDriftDetectionId": "det-net"}`
+	f.fixtures["cloudformation describe-stack-drift-detection-status --stack-drift-detection-id det-web"] = `{"DetectionStatus": "DETECTION_COMPLETE", "StackDriftStatus": "DRIFTED", "DriftedStackResourceCount": 1}`
+	f.fixtures["cloudformation describe-stack-drift-detection-status --stack-drift-detection-id det-db"] = `{"DetectionStatus": "DETECTION_COMPLETE", "StackDriftStatus": "IN_SYNC"}`
+	f.fixtures["cloudformation describe-stack-drift-detection-status --stack-drift-detection-id det-net"] = `{"DetectionStatus": "DETECTION_FAILED", "DetectionStatusReason": "Nested stack drift is not supported"}`
+	f.fixtures["cloudformation describe-stack-resource-drifts --stack-name web"] = `{"StackResourceDrifts": [{
+		"LogicalResourceId": "WebSG", "PhysicalResourceId": "sg-0123", "ResourceType": "AWS::EC2::SecurityGroup",
+		"StackResourceDriftStatus": "MODIFIED",
+		"PropertyDifferences": [{"PropertyPath": "/SecurityGroupIngress/0/CidrIp", "ExpectedValue": "10.0.0.0/8", "ActualValue": "0.0.0.0/0", "DifferenceType": "NOT_EQUAL"}]
+	}]}`
+	return f
+}
+
+func TestDetectStackDriftAllStacks(t *testing.T) {
+	out, err := newFakeClient(stackDriftFixtures()).executeAWSOperation(context.Background(), "detect_stack_drift", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"⚠️ web: DRIFTED (1 resources)",
+		"  • AWS::EC2::SecurityGroup WebSG (sg-0123): MODIFIED",
+		"      NOT_EQUAL /SecurityGroupIngress/0/CidrIp: expected 10.0.0.0/8, actual 0.0.0.0/0",
+		"❓ network: detection failed: Nested stack drift is not supported",
+		"✅ In sync: orders-db",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDetectStackDriftStacksNamedInQuery(t *testing.T) {
+	f := stackDriftFixtures()
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "detect_stack_drift", map[string]interface{}{"query": "is orders-db out of sync?"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "✅ All 1 stacks match their templates") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	for _, call := range f.calls {
+		if strings.Contains(call, "detect-stack-drift --stack-name web") {
+			t.Errorf("checked a stack the query did not name: %s", call)
+		}
+	}
+}
+
+func TestDetectStackDriftBoundedPolling(t *testing.T) {
+	interval, polls := driftPollInterval, driftMaxPolls
+	driftPollInterval, driftMaxPolls = time.Millisecond, 3
+	defer func() { driftPollInterval, driftMaxPolls = interval, polls }()
+
+	f := stackDriftFixtures()
+	f.fixtures["cloudformation describe-stack-drift-detection-status --stack-drift-detection-id det-web"] = `{"DetectionStatus": "DETECTION_IN_PROGRESS"}`
+	out, err := newFakeClient(f).executeAWSOperation(context.Background(), "detect_stack_drift", map[string]interface{}{"stack_name": "web"}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "⏳ web: detection still running") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	statusCalls := 0
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, "cloudformation describe-stack-drift-detection-status") {
+			statusCalls++
+		}
+	}
+	if statusCalls != 3 {
+		t.Errorf("expected 3 status polls, got %d", statusCalls)
+	}
+}
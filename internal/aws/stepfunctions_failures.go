@@ -0,0 +1,327 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// sfnFailureWindow is how far back analyze_stepfunctions_failures looks
+	// for failed executions unless the investigation window says otherwise.
+	sfnFailureWindow = 24 * time.Hour
+	// sfnMaxStateMachines bounds the machines checked when none is named.
+	sfnMaxStateMachines = 10
+	// sfnHistoriesPerMachine is how many of the newest failed executions
+	// get their history read; override with max_executions.
+	sfnHistoriesPerMachine = 5
+	sfnMaxCauseChars       = 240
+)
+
+// sfnExecution is an execution from list-executions.
+type sfnExecution struct {
+	ARN   string    `json:"executionArn"`
+	Name  string    `json:"name"`
+	Start time.Time `json:"startDate"`
+	Stop  time.Time `json:"stopDate"`
+}
+
+// sfnFailure is where and why one execution failed, read from its history.
+type sfnFailure struct {
+	Execution sfnExecution
+	State     string
+	Error     string
+	Cause     string
+	Err       string
+}
+
+// sfnMachineFailures is the failure picture of one state machine.
+type sfnMachineFailures struct {
+	Name     string
+	Failed   int
+	Failures []sfnFailure
+	Err      string
+}
+
+// analyzeStepFunctionsFailures is the analyze_stepfunctions_failures
+// operation: it lists failed executions per state machine, reads the
+// history of the newest ones to find the failing state with its error and
+// cause, and ranks the states that fail most often.
+func (c *Client) analyzeStepFunctionsFailures(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	perMachine := sfnHistoriesPerMachine
+	if n, ok := intParam(input, "max_executions"); ok && n > 0 {
+		perMachine = n
+	}
+	start, end := operationWindow(ctx, input, sfnFailureWindow)
+
+	var out strings.Builder
+	out.WriteString("🪜 STEP FUNCTIONS FAILURES\n")
+	out.WriteString("============================\n")
+
+	machines, msg := c.sfnStateMachines(ctx, getStringParam(input, "state_machine_arn", ""), getStringParam(input, "state_machine_name", ""), getStringParam(input, "query", ""), profile)
+	if msg != "" {
+		out.WriteString(msg + "\n")
+		return out.String(), nil
+	}
+
+	var results []sfnMachineFailures
+	for _, m := range machines {
+		results = append(results, c.sfnMachineFailures(ctx, m.name, m.arn, start, end, perMachine, profile))
+	}
+	out.WriteString(formatSFNFailures(results, windowPhrase(start, end)))
+	return out.String(), nil
+}
+
+type sfnStateMachine struct {
+	name, arn string
+}
+
+// sfnStateMachines resolves the machines to check: the named one, the ones
+// a query names, or every machine up to sfnMaxStateMachines.
+func (c *Client) sfnStateMachines(ctx context.Context, arn, name, query string, profile *AIProfile) ([]sfnStateMachine, string) {
+	if arn != "" {
+		return []sfnStateMachine{{name: arn[strings.LastIndex(arn, ":")+1:], arn: arn}}, ""
+	}
+	raw, err := c.execAWSCLI(ctx, []string{"stepfunctions", "list-state-machines", "--output", "json"}, profile)
+	if err != nil {
+		return nil, categorizeAWSError(err, "Step Functions")
+	}
+	var resp struct {
+		StateMachines []struct {
+			Name string `json:"name"`
+			ARN  string `json:"stateMachineArn"`
+		} `json:"stateMachines"`
+	}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return nil, fmt.Sprintf("Failed to parse state machines: %v", err)
+		}
+	}
+	if len(resp.StateMachines) == 0 {
+		return nil, "No Step Functions state machines found."
+	}
+
+	arns := make(map[string]string, len(resp.StateMachines))
+	names := make([]string, 0, len(resp.StateMachines))
+	for _, m := range resp.StateMachines {
+		arns[m.Name] = m.ARN
+		names = append(names, m.Name)
+	}
+	if name != "" {
+		if arns[name] == "" {
+			return nil, fmt.Sprintf("No state machine named %s.", name)
+		}
+		names = []string{name}
+	} else if matched := rolesReferencedInQuery(names, query); len(matched) > 0 {
+		names = matched
+	} else {
+		names = limitStrings(names, sfnMaxStateMachines)
+	}
+	machines := make([]sfnStateMachine, 0, len(names))
+	for _, n := range names {
+		machines = append(machines, sfnStateMachine{name: n, arn: arns[n]})
+	}
+	return machines, ""
+}
+
+func (c *Client) sfnMachineFailures(ctx context.Context, name, arn string, start, end time.Time, perMachine int, profile *AIProfile) sfnMachineFailures {
+	m := sfnMachineFailures{Name: name}
+	raw, err := c.execAWSCLI(ctx, []string{"stepfunctions", "list-executions", "--state-machine-arn", arn, "--status-filter", "FAILED", "--max-results", "100", "--output", "json"}, profile)
+	if err != nil {
+		m.Err = categorizeAWSError(err, "Step Functions")
+		return m
+	}
+	var resp struct {
+		Executions []sfnExecution `json:"executions"`
+	}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			m.Err = fmt.Sprintf("Failed to parse executions: %v", err)
+			return m
+		}
+	}
+
+	var failed []sfnExecution
+	for _, e := range resp.Executions {
+		if e.Stop.Before(start) || e.Stop.After(end) {
+			continue
+		}
+		failed = append(failed, e)
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Stop.After(failed[j].Stop) })
+	m.Failed = len(failed)
+	for i, e := range failed {
+		if i == perMachine {
+			break
+		}
+		m.Failures = append(m.Failures, c.sfnExecutionFailure(ctx, e, profile))
+	}
+	return m
+}
+
+// sfnExecutionFailure reads an execution's history newest first. The newest
+// failure event other than ExecutionFailed carries the most specific error,
+// and the state entered just before it is the state that failed.
+func (c *Client) sfnExecutionFailure(ctx context.Context, e sfnExecution, profile *AIProfile) sfnFailure {
+	f := sfnFailure{Execution: e}
+	raw, err := c.execAWSCLI(ctx, []string{"stepfunctions", "get-execution-history", "--execution-arn", e.ARN, "--reverse-order", "--max-results", "100", "--output", "json"}, profile)
+	if err != nil {
+		f.Err = categorizeAWSError(err, "Step Functions")
+		return f
+	}
+	var resp struct {
+		Events []map[string]json.RawMessage `json:"events"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		f.Err = fmt.Sprintf("Failed to parse execution history: %v", err)
+		return f
+	}
+
+	var execError, execCause string
+	failedAt := -1
+	for i, event := range resp.Events {
+		var eventType string
+		_ = json.Unmarshal(event["type"], &eventType)
+		if eventType == "ExecutionFailed" {
+			d := sfnEventDetails(event)
+			execError, execCause = d.Error, d.Cause
+		} else if strings.HasSuffix(eventType, "Failed") || strings.HasSuffix(eventType, "TimedOut") {
+			d := sfnEventDetails(event)
+			failedAt = i
+			f.Error, f.Cause = d.Error, d.Cause
+			break
+		}
+	}
+	// Without a failure event of its own (a Fail state), the execution
+	// failed in the last state it entered.
+	for _, event := range resp.Events[failedAt+1:] {
+		var eventType string
+		_ = json.Unmarshal(event["type"], &eventType)
+		if strings.HasSuffix(eventType, "StateEntered") {
+			f.State = sfnEventDetails(event).Name
+			break
+		}
+	}
+	if f.Error == "" {
+		f.Error, f.Cause = execError, execCause
+	}
+	f.Cause = sfnCauseSummary(f.Cause)
+	return f
+}
+
+type sfnDetails struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+	Cause string `json:"cause"`
+}
+
+// sfnEventDetails decodes whichever *EventDetails field a history event
+// carries; each event type names its own.
+func sfnEventDetails(event map[string]json.RawMessage) sfnDetails {
+	var d sfnDetails
+	for key, raw := range event {
+		if strings.HasSuffix(key, "EventDetails") {
+			_ = json.Unmarshal(raw, &d)
+			break
+		}
+	}
+	return d
+}
+
+// sfnCauseSummary shortens a cause to one line, preferring the
+// errorMessage of the Lambda error payloads most task failures carry.
+func sfnCauseSummary(cause string) string {
+	var lambdaErr struct {
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if json.Unmarshal([]byte(cause), &lambdaErr) == nil && lambdaErr.ErrorMessage != "" {
+		cause = lambdaErr.ErrorMessage
+	}
+	cause = strings.Join(strings.Fields(cause), " ")
+	if len(cause) > sfnMaxCauseChars {
+		cause = cause[:sfnMaxCauseChars] + "…"
+	}
+	return cause
+}
+
+func formatSFNFailures(results []sfnMachineFailures, window string) string {
+	var out strings.Builder
+	var healthy []string
+	for _, m := range results {
+		switch {
+		case m.Err != "":
+			out.WriteString(fmt.Sprintf("\n❌ %s: %s\n", m.Name, m.Err))
+			continue
+		case m.Failed == 0:
+			healthy = append(healthy, m.Name)
+			continue
+		}
+		out.WriteString(fmt.Sprintf("\n🚨 %s (%d failed %s)\n", m.Name, m.Failed, window))
+
+		counts := make(map[string]int)
+		errorsByState := make(map[string]map[string]bool)
+		var states []string
+		for _, f := range m.Failures {
+			if f.Err != "" {
+				continue
+			}
+			state := valueOr(f.State, "(unknown state)")
+			if counts[state] == 0 {
+				states = append(states, state)
+				errorsByState[state] = make(map[string]bool)
+			}
+			counts[state]++
+			if f.Error != "" {
+				errorsByState[state][f.Error] = true
+			}
+		}
+		sort.SliceStable(states, func(i, j int) bool { return counts[states[i]] > counts[states[j]] })
+		if len(states) > 0 {
+			inspected := 0
+			for _, n := range counts {
+				inspected += n
+			}
+			out.WriteString(fmt.Sprintf("   Failing states (newest %d inspected):\n", inspected))
+			for _, state := range states {
+				var errs []string
+				for e := range errorsByState[state] {
+					errs = append(errs, e)
+				}
+				sort.Strings(errs)
+				line := fmt.Sprintf("     - %s: %d", state, counts[state])
+				if len(errs) > 0 {
+					line += " (" + strings.Join(errs, ", ") + ")"
+				}
+				out.WriteString(line + "\n")
+			}
+			if len(states) > 1 || counts[states[0]] > 1 {
+				out.WriteString(fmt.Sprintf("   Fails most often in: %s\n", states[0]))
+			}
+		}
+
+		out.WriteString("   Recent failures:\n")
+		for _, f := range m.Failures {
+			line := fmt.Sprintf("     - %s (%s)", f.Execution.Name, f.Execution.Stop.UTC().Format("2006-01-02 15:04 MST"))
+			switch {
+			case f.Err != "":
+				line += ": history unavailable: " + f.Err
+			default:
+				line += ": " + valueOr(f.State, "(unknown state)")
+				if f.Error != "" {
+					line += " failed with " + f.Error
+				}
+				if f.Cause != "" {
+					line += ": " + f.Cause
+				}
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+	if len(healthy) > 0 {
+		out.WriteString(fmt.Sprintf("\n✅ No failed executions %s: %s\n", window, strings.Join(healthy, ", ")))
+	}
+	return out.String()
+}
@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeStepFunctionsFailures(t *testing.T) {
+	now := time.Now().UTC()
+	at := func(ago time.Duration) string { return now.Add(-ago).Format(time.RFC3339) }
+	f := newFakeCLI()
+	f.fixtures["stepfunctions list-state-machines"] = `{"stateMachines": [
+		{"name": "order-pipeline", "stateMachineArn": "arn:aws:states:us-east-1:123456789012:stateMachine:order-pipeline"},
+		{"name": "nightly-report", "stateMachineArn": "arn:aws:states:us-east-1:123456789012:stateMachine:nightly-report"}]}`
+	f.fixtures["stepfunctions list-executions --state-machine-arn arn:aws:states:us-east-1:123456789012:stateMachine:order-pipeline"] = `{"executions": [
+		{"executionArn": "arn:exec:1", "name": "order-1", "stopDate": "` + at(time.Hour) + `"},
+		{"executionArn": "arn:exec:2", "name": "order-2", "stopDate": "` + at(2*time.Hour) + `"},
+		{"executionArn": "arn:exec:3", "name": "order-3", "stopDate": "` + at(3*time.Hour) + `"},
+		{"executionArn": "arn:exec:old", "name": "order-old", "stopDate": "` + at(72*time.Hour) + `"}]}`
+	f.fixtures["stepfunctions list-executions --state-machine-arn arn:aws:states:us-east-1:123456789012:stateMachine:nightly-report"] = `{"executions": []}`
+	chargeFailed := `{"events": [
+		{"id": 6, "type": "ExecutionFailed", "executionFailedEventDetails": {"error": "PaymentDeclined", "cause": "card declined"}},
+		{"id": 5, "type": "LambdaFunctionFailed", "lambdaFunctionFailedEventDetails": {"error": "PaymentDeclined", "cause": "{\"errorMessage\": \"card declined for order 42\", \"errorType\": \"PaymentDeclined\"}"}},
+		{"id": 4, "type": "LambdaFunctionStarted"},
+		{"id": 3, "type": "TaskStateEntered", "stateEnteredEventDetails": {"name": "ChargeCard"}},
+		{"id": 2, "type": "TaskStateExited", "stateExitedEventDetails": {"name": "ReserveStock"}},
+		{"id": 1, "type": "TaskStateEntered", "stateEnteredEventDetails": {"name": "ReserveStock"}}]}`
+	f.fixtures["stepfunctions get-execution-history --execution-arn arn:exec:1"] = chargeFailed
+	f.fixtures["stepfunctions get-execution-history --execution-arn arn:exec:2"] = chargeFailed
+	f.fixtures["stepfunctions get-execution-history --execution-arn arn:exec:3"] = `{"events": [
+		{"id": 3, "type": "ExecutionFailed", "executionFailedEventDetails": {"error": "OutOfStock", "cause": "no stock"}},
+		{"id": 2, "type": "FailStateEntered", "stateEnteredEventDetails": {"name": "RejectOrder"}},
+		{"id": 1, "type": "ChoiceStateEntered", "stateEnteredEventDetails": {"name": "InStock?"}}]}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "analyze_stepfunctions_failures", map[string]interface{}{}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"🚨 order-pipeline (3 failed in the last 1d)",
+		"Failing states (newest 3 inspected):",
+		"- ChargeCard: 2 (PaymentDeclined)",
+		"- RejectOrder: 1 (OutOfStock)",
+		"Fails most often in: ChargeCard",
+		": ChargeCard failed with PaymentDeclined: card declined for order 42",
+		": RejectOrder failed with OutOfStock: no stock",
+		"✅ No failed executions in the last 1d: nightly-report",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "order-old") {
+		t.Errorf("executions outside the window should be skipped:\n%s", out)
+	}
+}
+
+func TestAnalyzeStepFunctionsFailuresQueryMatch(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["stepfunctions list-state-machines"] = `{"stateMachines": [
+		{"name": "order-pipeline", "stateMachineArn": "arn:order"},
+		{"name": "nightly-report", "stateMachineArn": "arn:nightly"}]}`
+	f.fixtures["stepfunctions list-executions"] = `{"executions": []}`
+	c := newFakeClient(f)
+
+	if _, err := c.executeAWSOperation(context.Background(), "analyze_stepfunctions_failures", map[string]interface{}{"query": "why is nightly-report failing"}, &AIProfile{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, call := range f.calls {
+		if strings.Contains(call, "arn:order") {
+			t.Errorf("only the machine named in the query should be checked, got %s", call)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// cloudWatchMaxDatapoints is the most datapoints get-metric-statistics
+// returns for one call.
+const cloudWatchMaxDatapoints = 1440
+
+// TimeRange is the investigation window shared by log and metric operations.
+// Start and End are absolute bounds; a zero End means "now". Without a Start,
+// the window reaches Lookback before the end, or the operation's own default
+// lookback when Lookback is zero too.
+type TimeRange struct {
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Lookback time.Duration `json:"lookback"`
+}
+
+// IsZero reports whether the range sets nothing.
+func (r TimeRange) IsZero() bool {
+	return r.Start.IsZero() && r.End.IsZero() && r.Lookback == 0
+}
+
+// Bounds returns the concrete window, ending now when End is unset and
+// starting Lookback (or fallback) before the end when Start is unset.
+func (r TimeRange) Bounds(now time.Time, fallback time.Duration) (start, end time.Time) {
+	end = r.End
+	if end.IsZero() {
+		end = now
+	}
+	start = r.Start
+	if start.IsZero() {
+		if r.Lookback > 0 {
+			fallback = r.Lookback
+		}
+		start = end.Add(-fallback)
+	}
+	return start.UTC(), end.UTC()
+}
+
+// String describes the range, e.g. "last 2h" or "2024-05-01T12:00:00Z to
+// 2024-05-01T14:00:00Z".
+func (r TimeRange) String() string {
+	switch {
+	case r.IsZero():
+		return "default window"
+	case r.Start.IsZero() && r.Lookback == 0:
+		return "until " + r.End.UTC().Format(time.RFC3339)
+	case r.Start.IsZero() && r.End.IsZero():
+		return "last " + formatLookback(r.Lookback)
+	}
+	start, end := r.Bounds(time.Now(), 0)
+	return formatWindow(start, end, false)
+}
+
+// ParseTimeRange parses --since / --until values. Each accepts a lookback
+// such as "30m", "2h", "3d" or "1w", an RFC3339 timestamp, or a date
+// (2006-01-02, UTC). An empty until means now.
+func ParseTimeRange(since, until string, now time.Time) (TimeRange, error) {
+	var r TimeRange
+	var err error
+	if strings.TrimSpace(until) == "" {
+		// A lookback with no end follows the clock, so a long investigation
+		// still covers the last N hours when each operation runs.
+		if d, err := parseLookback(strings.TrimSpace(since)); err == nil {
+			return TimeRange{Lookback: d}, nil
+		}
+	}
+	if r.Start, err = parseTimeBound(since, now); err != nil {
+		return TimeRange{}, fmt.Errorf("invalid --since %q: %w", since, err)
+	}
+	if r.End, err = parseTimeBound(until, now); err != nil {
+		return TimeRange{}, fmt.Errorf("invalid --until %q: %w", until, err)
+	}
+	if !r.End.IsZero() && r.End.After(now) {
+		return TimeRange{}, fmt.Errorf("--until %q is in the future", until)
+	}
+	end := r.End
+	if end.IsZero() {
+		end = now
+	}
+	if !r.Start.IsZero() && !r.Start.Before(end) {
+		return TimeRange{}, fmt.Errorf("--since must be before --until")
+	}
+	return r, nil
+}
+
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.UTC(), nil
+	}
+	d, err := parseLookback(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a duration like 2h or 3d, an RFC3339 time, or a date")
+	}
+	return now.Add(-d).UTC(), nil
+}
+
+// parseLookback parses Go durations plus whole days ("3d") and weeks ("1w").
+func parseLookback(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, fmt.Errorf("empty lookback")
+	}
+	var d time.Duration
+	switch unit := value[len(value)-1]; unit {
+	case 'd', 'w':
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * 24 * time.Hour
+		if unit == 'w' {
+			d *= 7
+		}
+	default:
+		var err error
+		if d, err = time.ParseDuration(value); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("lookback must be positive")
+	}
+	return d, nil
+}
+
+// timeFrameLookbacks are the default windows for the semantic analyzer's
+// time frames when no --since is given.
+var timeFrameLookbacks = map[string]time.Duration{
+	"real_time":  time.Hour,
+	"recent":     24 * time.Hour,
+	"historical": 7 * 24 * time.Hour,
+}
+
+// DefaultTimeRange returns the window implied by a query's time frame, or a
+// zero range for unknown frames so operations keep their own defaults.
+func DefaultTimeRange(timeFrame string) TimeRange {
+	lookback, ok := timeFrameLookbacks[timeFrame]
+	if !ok {
+		return TimeRange{}
+	}
+	return TimeRange{Lookback: lookback}
+}
+
+// ConfiguredTimeRange reads agent.since / agent.until, set by the --since and
+// --until flags. Invalid values are rejected when the flags are parsed, so
+// they are ignored here.
+func ConfiguredTimeRange() TimeRange {
+	since, until := viper.GetString("agent.since"), viper.GetString("agent.until")
+	if since == "" && until == "" {
+		return TimeRange{}
+	}
+	r, err := ParseTimeRange(since, until, time.Now())
+	if err != nil {
+		return TimeRange{}
+	}
+	return r
+}
+
+type (
+	timeRangeKey        struct{}
+	defaultTimeRangeKey struct{}
+)
+
+// WithTimeRange attaches the user's investigation window (--since/--until) to
+// ctx; log and metric operations run with that ctx use it unless their
+// parameters override it.
+func WithTimeRange(ctx context.Context, r TimeRange) context.Context {
+	if r.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, timeRangeKey{}, r)
+}
+
+// WithDefaultTimeRange attaches a fallback window, such as the one implied by
+// the query's time frame. Unlike WithTimeRange it yields to an operation's
+// hours_back.
+func WithDefaultTimeRange(ctx context.Context, r TimeRange) context.Context {
+	if r.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, defaultTimeRangeKey{}, r)
+}
+
+// TimeRangeFromContext returns the window attached by WithTimeRange.
+func TimeRangeFromContext(ctx context.Context) (TimeRange, bool) {
+	r, ok := ctx.Value(timeRangeKey{}).(TimeRange)
+	return r, ok
+}
+
+// operationWindow resolves the window of a log or metric operation:
+// start_time / end_time parameters first, then the investigation window on
+// ctx, then hours_back, then the default window on ctx, then defaultWindow
+// ending now.
+func operationWindow(ctx context.Context, input map[string]interface{}, defaultWindow time.Duration) (start, end time.Time) {
+	now := time.Now().UTC()
+	var r TimeRange
+	if ctxRange, ok := TimeRangeFromContext(ctx); ok {
+		r = ctxRange
+	} else if hours, ok := intParam(input, "hours_back"); ok && hours > 0 {
+		defaultWindow = time.Duration(hours) * time.Hour
+	} else if fallback, ok := ctx.Value(defaultTimeRangeKey{}).(TimeRange); ok {
+		r = fallback
+	}
+	if t, err := parseTimeBound(getStringParam(input, "start_time", ""), now); err == nil && !t.IsZero() {
+		r.Start = t
+	}
+	if t, err := parseTimeBound(getStringParam(input, "end_time", ""), now); err == nil && !t.IsZero() {
+		r.End = t
+	}
+	start, end = r.Bounds(now, defaultWindow)
+	if !start.Before(end) {
+		start = end.Add(-defaultWindow)
+	}
+	return start, end
+}
+
+// logTimeArgs returns the filter-log-events window arguments.
+func logTimeArgs(start, end time.Time) []string {
+	return []string{"--start-time", strconv.FormatInt(start.UnixMilli(), 10), "--end-time", strconv.FormatInt(end.UnixMilli(), 10)}
+}
+
+// metricPeriodForWindow raises period (seconds) to a multiple of 60 large
+// enough that window fits in one get-metric-statistics call.
+func metricPeriodForWindow(period int, window time.Duration) int {
+	minimum := int(math.Ceil(window.Seconds()/cloudWatchMaxDatapoints/60)) * 60
+	if period < minimum {
+		return minimum
+	}
+	return period
+}
+
+// describeWindow labels a window for output headers: "last 6h" when it ends
+// about now, otherwise its RFC3339 bounds.
+func describeWindow(start, end time.Time) string {
+	return formatWindow(start, end, time.Since(end) < time.Minute)
+}
+
+// windowPhrase is describeWindow for use in a sentence: "in the last 6h" or
+// "from <start> to <end>".
+func windowPhrase(start, end time.Time) string {
+	if time.Since(end) < time.Minute {
+		return "in the " + describeWindow(start, end)
+	}
+	return "from " + describeWindow(start, end)
+}
+
+func formatWindow(start, end time.Time, endsNow bool) string {
+	if endsNow {
+		return "last " + formatLookback(end.Sub(start))
+	}
+	return start.UTC().Format(time.RFC3339) + " to " + end.UTC().Format(time.RFC3339)
+}
+
+// formatLookback renders a duration in the largest whole unit: 3d, 6h, 45m.
+func formatLookback(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
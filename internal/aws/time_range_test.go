@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	r, err := ParseTimeRange("3d", "", now)
+	if err != nil || r.Lookback != 72*time.Hour || !r.Start.IsZero() {
+		t.Fatalf("relative since: %+v, %v", r, err)
+	}
+	if got := r.String(); got != "last 3d" {
+		t.Errorf("String() = %q", got)
+	}
+
+	r, err = ParseTimeRange("2024-05-01T14:00:00Z", "2h", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Start.Equal(time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)) || !r.End.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("absolute range: %+v", r)
+	}
+
+	for _, bad := range [][2]string{{"yesterday", ""}, {"1h", "2h"}, {"", "2030-01-01T00:00:00Z"}, {"-2h", ""}} {
+		if _, err := ParseTimeRange(bad[0], bad[1], now); err == nil {
+			t.Errorf("expected an error for since=%q until=%q", bad[0], bad[1])
+		}
+	}
+}
+
+func TestDefaultTimeRangeFollowsTimeFrame(t *testing.T) {
+	if r := DefaultTimeRange("historical"); r.Lookback != 7*24*time.Hour {
+		t.Errorf("historical: %+v", r)
+	}
+	if r := DefaultTimeRange("real_time"); r.Lookback != time.Hour {
+		t.Errorf("real_time: %+v", r)
+	}
+	if r := DefaultTimeRange("unknown"); !r.IsZero() {
+		t.Errorf("unknown frames should keep operation defaults: %+v", r)
+	}
+}
+
+func TestOperationWindowPrecedence(t *testing.T) {
+	input := map[string]interface{}{"hours_back": 6}
+	start, end := operationWindow(context.Background(), input, time.Hour)
+	if end.Sub(start) != 6*time.Hour {
+		t.Errorf("hours_back should apply without a context window, got %s", end.Sub(start))
+	}
+
+	recent := WithDefaultTimeRange(context.Background(), DefaultTimeRange("recent"))
+	if start, end := operationWindow(recent, input, time.Hour); end.Sub(start) != 6*time.Hour {
+		t.Errorf("hours_back should beat the query's default window, got %s", end.Sub(start))
+	}
+	if start, end := operationWindow(recent, map[string]interface{}{}, time.Hour); end.Sub(start) != 24*time.Hour {
+		t.Errorf("the default window should beat the operation default, got %s", end.Sub(start))
+	}
+
+	incident := TimeRange{Start: time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC), End: time.Date(2024, 5, 1, 16, 0, 0, 0, time.UTC)}
+	ctx := WithTimeRange(context.Background(), incident)
+	start, end = operationWindow(ctx, input, time.Hour)
+	if !start.Equal(incident.Start) || !end.Equal(incident.End) {
+		t.Errorf("context window should beat hours_back, got %s to %s", start, end)
+	}
+
+	input["start_time"] = "2024-05-01T15:00:00Z"
+	start, _ = operationWindow(ctx, input, time.Hour)
+	if !start.Equal(time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("start_time parameter should win, got %s", start)
+	}
+}
+
+func TestMetricPeriodForWindow(t *testing.T) {
+	if got := metricPeriodForWindow(300, 3*time.Hour); got != 300 {
+		t.Errorf("short window should keep the period, got %d", got)
+	}
+	if got := metricPeriodForWindow(60, 7*24*time.Hour); got != 420 {
+		t.Errorf("a week needs at least 420s periods, got %d", got)
+	}
+}
+
+func TestLogOperationsUseContextWindow(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["logs filter-log-events"] = "[]"
+	f.fixtures["lambda get-function-configuration"] = "{}"
+	c := newFakeClient(f)
+	incident := TimeRange{Start: time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC), End: time.Date(2024, 5, 1, 16, 0, 0, 0, time.UTC)}
+	ctx := WithTimeRange(context.Background(), incident)
+
+	out, err := c.executeAWSOperation(ctx, "analyze_lambda_errors", map[string]interface{}{"function_name": "checkout"}, &AIProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--start-time " + strconv.FormatInt(incident.Start.UnixMilli(), 10) + " --end-time " + strconv.FormatInt(incident.End.UnixMilli(), 10)
+	if !strings.Contains(f.calls[0], want) {
+		t.Errorf("expected %q in %q", want, f.calls[0])
+	}
+	if !strings.Contains(out, "ERROR LOGS (2024-05-01T14:00:00Z to 2024-05-01T16:00:00Z)") {
+		t.Errorf("expected the window in the heading:\n%s", out)
+	}
+}
@@ -0,0 +1,363 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// xrayTraceWindow is how far back get_xray_traces looks unless the
+	// investigation window says otherwise.
+	xrayTraceWindow = time.Hour
+	// xrayMaxWindow is the longest range X-Ray accepts in one trace query;
+	// longer windows keep their end and lose their start.
+	xrayMaxWindow = 6 * time.Hour
+	// xrayDefaultLatency is the response time, in seconds, above which a
+	// trace counts as slow; override with min_latency_seconds.
+	xrayDefaultLatency = 1.0
+	// xrayTracesInspected is how many of the slowest traces get their
+	// segments read; override with max_traces.
+	xrayTracesInspected = 5
+	// xrayBatchSize is the most trace IDs batch-get-traces takes per call.
+	xrayBatchSize   = 5
+	xrayMaxSummary  = 500
+	xrayCallsShown  = 3
+	xrayMaxMsgChars = 160
+)
+
+// xrayTraceSummary is a trace from get-trace-summaries.
+type xrayTraceSummary struct {
+	ID           string  `json:"Id"`
+	ResponseTime float64 `json:"ResponseTime"`
+	HasFault     bool    `json:"HasFault"`
+	HasError     bool    `json:"HasError"`
+	HasThrottle  bool    `json:"HasThrottle"`
+	HTTP         struct {
+		URL    string `json:"HttpURL"`
+		Method string `json:"HttpMethod"`
+		Status int    `json:"HttpStatus"`
+	} `json:"Http"`
+}
+
+// xraySegment is a segment document from batch-get-traces; subsegments
+// share its shape.
+type xraySegment struct {
+	Name        string          `json:"name"`
+	StartTime   float64         `json:"start_time"`
+	EndTime     float64         `json:"end_time"`
+	Namespace   string          `json:"namespace"`
+	Fault       bool            `json:"fault"`
+	Error       bool            `json:"error"`
+	Cause       json.RawMessage `json:"cause"`
+	Subsegments []xraySegment   `json:"subsegments"`
+	AWS         struct {
+		Operation string `json:"operation"`
+	} `json:"aws"`
+}
+
+// xrayCall is one downstream call a service made within a trace.
+type xrayCall struct {
+	Caller  string
+	Target  string
+	Seconds float64
+}
+
+// xrayTrace is what one inspected trace spent its time on.
+type xrayTrace struct {
+	Summary   xrayTraceSummary
+	Calls     []xrayCall
+	Exception string
+}
+
+// getXRayTraces is the get_xray_traces operation: it finds slow, erroring
+// and faulting traces in the window, reads the segments of the slowest ones
+// and reports which downstream calls their time went to.
+func (c *Client) getXRayTraces(ctx context.Context, input map[string]interface{}, profile *AIProfile) (string, error) {
+	threshold := xrayDefaultLatency
+	if v, err := strconv.ParseFloat(getStringParam(input, "min_latency_seconds", ""), 64); err == nil && v > 0 {
+		threshold = v
+	}
+	inspect := xrayTracesInspected
+	if n, ok := intParam(input, "max_traces"); ok && n > 0 {
+		inspect = n
+	}
+	start, end := operationWindow(ctx, input, xrayTraceWindow)
+
+	var out strings.Builder
+	out.WriteString("🔭 X-RAY TRACES\n")
+	out.WriteString("============================\n")
+	if end.Sub(start) > xrayMaxWindow {
+		out.WriteString(fmt.Sprintf("X-Ray queries cover at most %s; narrowed %s to its last %s.\n", formatLookback(xrayMaxWindow), describeWindow(start, end), formatLookback(xrayMaxWindow)))
+		start = end.Add(-xrayMaxWindow)
+	}
+	window := windowPhrase(start, end)
+
+	filter := fmt.Sprintf("responsetime > %s OR error OR fault", strconv.FormatFloat(threshold, 'f', -1, 64))
+	if service := getStringParam(input, "service", ""); service != "" {
+		filter = fmt.Sprintf("service(%q) AND (%s)", service, filter)
+	}
+	out.WriteString("Filter: " + filter + "\n")
+
+	raw, err := c.execAWSCLI(ctx, []string{"xray", "get-trace-summaries",
+		"--start-time", start.UTC().Format(time.RFC3339),
+		"--end-time", end.UTC().Format(time.RFC3339),
+		"--filter-expression", filter,
+		"--max-items", strconv.Itoa(xrayMaxSummary),
+		"--output", "json"}, profile)
+	if err != nil {
+		out.WriteString(categorizeAWSError(err, "X-Ray") + "\n")
+		return out.String(), nil
+	}
+	var resp struct {
+		TraceSummaries []xrayTraceSummary `json:"TraceSummaries"`
+		NextToken      string             `json:"NextToken"`
+	}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			out.WriteString(fmt.Sprintf("Failed to parse trace summaries: %v\n", err))
+			return out.String(), nil
+		}
+	}
+	summaries := resp.TraceSummaries
+	if len(summaries) == 0 {
+		out.WriteString(fmt.Sprintf("\n✅ No traces slower than %ss or with errors %s. If requests were slow, X-Ray may not be enabled for these services.\n", strconv.FormatFloat(threshold, 'f', -1, 64), window))
+		return out.String(), nil
+	}
+
+	out.WriteString(xrayMatchLine(summaries, resp.NextToken != "", window))
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].ResponseTime > summaries[j].ResponseTime })
+	slowest := summaries
+	if len(slowest) > inspect {
+		slowest = slowest[:inspect]
+	}
+	traces, msg := c.xrayTraces(ctx, slowest, profile)
+	if msg != "" {
+		out.WriteString("\n" + msg + "\n")
+		return out.String(), nil
+	}
+	out.WriteString(formatXRayTraces(traces))
+	return out.String(), nil
+}
+
+func xrayMatchLine(summaries []xrayTraceSummary, truncated bool, window string) string {
+	var faults, errors, throttles int
+	for _, s := range summaries {
+		switch {
+		case s.HasFault:
+			faults++
+		case s.HasError:
+			errors++
+		}
+		if s.HasThrottle {
+			throttles++
+		}
+	}
+	count := strconv.Itoa(len(summaries))
+	if truncated {
+		count += "+"
+	}
+	line := fmt.Sprintf("Matched %s traces %s", count, window)
+	var parts []string
+	if faults > 0 {
+		parts = append(parts, fmt.Sprintf("%d with faults", faults))
+	}
+	if errors > 0 {
+		parts = append(parts, fmt.Sprintf("%d with errors", errors))
+	}
+	if throttles > 0 {
+		parts = append(parts, fmt.Sprintf("%d throttled", throttles))
+	}
+	if len(parts) > 0 {
+		line += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return line + "\n"
+}
+
+// xrayTraces reads the segments of each summary, xrayBatchSize at a time,
+// and keeps the summaries' order.
+func (c *Client) xrayTraces(ctx context.Context, summaries []xrayTraceSummary, profile *AIProfile) ([]xrayTrace, string) {
+	segments := make(map[string][]xraySegment, len(summaries))
+	for i := 0; i < len(summaries); i += xrayBatchSize {
+		args := []string{"xray", "batch-get-traces", "--trace-ids"}
+		for _, s := range summaries[i:min(i+xrayBatchSize, len(summaries))] {
+			args = append(args, s.ID)
+		}
+		raw, err := c.execAWSCLI(ctx, append(args, "--output", "json"), profile)
+		if err != nil {
+			return nil, categorizeAWSError(err, "X-Ray")
+		}
+		var resp struct {
+			Traces []struct {
+				ID       string `json:"Id"`
+				Segments []struct {
+					Document string `json:"Document"`
+				} `json:"Segments"`
+			} `json:"Traces"`
+		}
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return nil, fmt.Sprintf("Failed to parse traces: %v", err)
+		}
+		for _, t := range resp.Traces {
+			for _, s := range t.Segments {
+				var doc xraySegment
+				if json.Unmarshal([]byte(s.Document), &doc) == nil {
+					segments[t.ID] = append(segments[t.ID], doc)
+				}
+			}
+		}
+	}
+
+	traces := make([]xrayTrace, 0, len(summaries))
+	for _, s := range summaries {
+		t := xrayTrace{Summary: s}
+		for _, seg := range segments[s.ID] {
+			xrayDownstreamCalls(seg.Name, seg.Subsegments, &t.Calls)
+			if t.Exception == "" {
+				t.Exception = xrayException(seg)
+			}
+		}
+		sort.SliceStable(t.Calls, func(i, j int) bool { return t.Calls[i].Seconds > t.Calls[j].Seconds })
+		traces = append(traces, t)
+	}
+	return traces, ""
+}
+
+// xrayDownstreamCalls collects the calls a service made to AWS services and
+// remote endpoints. Local subsegments are walked into; a call's own
+// subsegments (retries, SDK internals) are part of its time.
+func xrayDownstreamCalls(caller string, subsegments []xraySegment, calls *[]xrayCall) {
+	for _, sub := range subsegments {
+		if sub.Namespace != "aws" && sub.Namespace != "remote" {
+			xrayDownstreamCalls(caller, sub.Subsegments, calls)
+			continue
+		}
+		target := sub.Name
+		if sub.AWS.Operation != "" {
+			target += " " + sub.AWS.Operation
+		}
+		*calls = append(*calls, xrayCall{Caller: caller, Target: target, Seconds: sub.EndTime - sub.StartTime})
+	}
+}
+
+// xrayException returns the first exception message recorded on a failing
+// segment or subsegment.
+func xrayException(seg xraySegment) string {
+	if seg.Fault || seg.Error {
+		var cause struct {
+			Exceptions []struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"exceptions"`
+		}
+		if json.Unmarshal(seg.Cause, &cause) == nil {
+			for _, e := range cause.Exceptions {
+				msg := strings.Join(strings.Fields(valueOr(e.Message, e.Type)), " ")
+				if msg == "" {
+					continue
+				}
+				if len(msg) > xrayMaxMsgChars {
+					msg = msg[:xrayMaxMsgChars] + "…"
+				}
+				return msg
+			}
+		}
+	}
+	for _, sub := range seg.Subsegments {
+		if msg := xrayException(sub); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+func (c xrayCall) label() string {
+	return c.Caller + " → " + c.Target
+}
+
+func formatXRayTraces(traces []xrayTrace) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("\nSlowest traces (%d inspected):\n", len(traces)))
+
+	type aggregate struct {
+		seconds, requestSeconds float64
+		traces                  int
+	}
+	totals := make(map[string]*aggregate)
+	var labels []string
+	for _, t := range traces {
+		s := t.Summary
+		line := "  - " + s.ID
+		if s.HTTP.Method != "" || s.HTTP.URL != "" {
+			line += " " + strings.TrimSpace(s.HTTP.Method+" "+s.HTTP.URL)
+		}
+		if s.HTTP.Status != 0 {
+			line += fmt.Sprintf(" %d", s.HTTP.Status)
+		}
+		line += fmt.Sprintf(" %.2fs", s.ResponseTime)
+		switch {
+		case s.HasFault:
+			line += " [fault]"
+		case s.HasError:
+			line += " [error]"
+		}
+		out.WriteString(line + "\n")
+
+		for i, call := range t.Calls {
+			if i == xrayCallsShown {
+				break
+			}
+			out.WriteString(fmt.Sprintf("      %s: %.2fs%s\n", call.label(), call.Seconds, xrayShare(call.Seconds, s.ResponseTime)))
+		}
+		if len(t.Calls) == 0 {
+			out.WriteString("      no downstream calls recorded; the time was spent in the service itself\n")
+		}
+		if t.Exception != "" {
+			out.WriteString("      exception: " + t.Exception + "\n")
+		}
+
+		seen := make(map[string]bool)
+		for _, call := range t.Calls {
+			label := call.label()
+			agg := totals[label]
+			if agg == nil {
+				agg = &aggregate{}
+				totals[label] = agg
+				labels = append(labels, label)
+			}
+			agg.seconds += call.Seconds
+			if !seen[label] {
+				seen[label] = true
+				agg.traces++
+				agg.requestSeconds += s.ResponseTime
+			}
+		}
+	}
+
+	if len(labels) == 0 {
+		return out.String()
+	}
+	sort.SliceStable(labels, func(i, j int) bool { return totals[labels[i]].seconds > totals[labels[j]].seconds })
+	out.WriteString("\nTime dominated by:\n")
+	for i, label := range labels {
+		if i == xrayCallsShown {
+			break
+		}
+		agg := totals[label]
+		out.WriteString(fmt.Sprintf("  - %s: %.2fs across %d of %d traces%s\n", label, agg.seconds, agg.traces, len(traces), xrayShare(agg.seconds, agg.requestSeconds)))
+	}
+	return out.String()
+}
+
+// xrayShare renders part as a share of a request's response time, or
+// nothing when the response time is unknown.
+func xrayShare(part, whole float64) string {
+	if whole <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%.0f%% of request time)", 100*part/whole)
+}
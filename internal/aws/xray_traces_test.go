@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGetXRayTraces(t *testing.T) {
+	f := newFakeCLI()
+	f.fixtures["xray get-trace-summaries"] = `{"TraceSummaries": [
+		{"Id": "1-aaa", "ResponseTime": 1.2, "HasError": true, "Http": {"HttpURL": "https://api.example.com/cart", "HttpMethod": "GET", "HttpStatus": 404}},
+		{"Id": "1-bbb", "ResponseTime": 2.3, "HasFault": true, "Http": {"HttpURL": "https://api.example.com/orders", "HttpMethod": "POST", "HttpStatus": 500}}]}`
+	f.fixtures["xray batch-get-traces"] = `{"Traces": [
+		{"Id": "1-bbb", "Segments": [{"Document": "{\"name\":\"orders-api\",\"start_time\":100,\"end_time\":102.3,\"fault\":true,\"subsegments\":[{\"name\":\"handler\",\"start_time\":100,\"end_time\":102.3,\"fault\":true,\"cause\":{\"exceptions\":[{\"message\":\"context deadline exceeded\"}]},\"subsegments\":[{\"name\":\"DynamoDB\",\"namespace\":\"aws\",\"aws\":{\"operation\":\"Query\"},\"start_time\":100.1,\"end_time\":102.2,\"subsegments\":[{\"name\":\"attempt\",\"namespace\":\"remote\",\"start_time\":100.1,\"end_time\":102.2}]}]}]}"}]},
+		{"Id": "1-aaa", "Segments": [{"Document": "{\"name\":\"orders-api\",\"start_time\":200,\"end_time\":201.2,\"subsegments\":[{\"name\":\"DynamoDB\",\"namespace\":\"aws\",\"aws\":{\"operation\":\"Query\"},\"start_time\":200,\"end_time\":200.9},{\"name\":\"payments.internal\",\"namespace\":\"remote\",\"start_time\":200.9,\"end_time\":201.1}]}"}]}]}`
+	c := newFakeClient(f)
+
+	out, err := c.executeAWSOperation(context.Background(), "get_xray_traces", map[string]interface{}{"service": "orders-api", "hours_back": 24}, &AIProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"X-Ray queries cover at most 6h; narrowed last 1d to its last 6h.",
+		`Filter: service("orders-api") AND (responsetime > 1 OR error OR fault)`,
+		"Matched 2 traces in the last 6h (1 with faults, 1 with errors)",
+		"  - 1-bbb POST https://api.example.com/orders 500 2.30s [fault]\n      orders-api → DynamoDB Query: 2.10s (91% of request time)\n      exception: context deadline exceeded",
+		"  - 1-aaa GET https://api.example.com/cart 404 1.20s [error]\n      orders-api → DynamoDB Query: 0.90s (75% of request time)\n      orders-api → payments.internal: 0.20s (17% of request time)",
+		"Time dominated by:\n  - orders-api → DynamoDB Query: 3.00s across 2 of 2 traces (86% of request time)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "attempt") {
+		t.Errorf("a call's own subsegments should not count as separate calls:\n%s", out)
+	}
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, "xray batch-get-traces") && !strings.Contains(call, "--trace-ids 1-bbb 1-aaa") {
+			t.Errorf("expected the slowest trace first, got %q", call)
+		}
+	}
+
+	f = newFakeCLI()
+	f.fixtures["xray get-trace-summaries"] = `{"TraceSummaries": []}`
+	out, _ = newFakeClient(f).executeAWSOperation(context.Background(), "get_xray_traces", map[string]interface{}{}, &AIProfile{})
+	if !strings.Contains(out, "✅ No traces slower than 1s or with errors in the last 1h") {
+		t.Errorf("expected the no-traces message, got:\n%s", out)
+	}
+}
@@ -3,22 +3,29 @@ package deploy
 import (
 	"bufio"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/bgdnvk/clanker/internal/logging"
 )
 
 // RepoProfile is the result of analyzing a git repo
 type RepoProfile struct {
 	RepoURL          string            `json:"repoUrl"`
 	ClonePath        string            `json:"clonePath"`
-	Language         string            `json:"language"`       // go, python, node, rust, java, etc
-	Framework        string            `json:"framework"`      // express, flask, fastapi, gin, fiber, nextjs, etc
-	PackageManager   string            `json:"packageManager"` // npm, pnpm, yarn, bun, pip, cargo, go
+	FromCache        bool              `json:"fromCache,omitempty"` // ClonePath is a clone cache entry; do not delete it
+	Language         string            `json:"language"`            // go, python, node, rust, java, etc
+	Framework        string            `json:"framework"`           // express, flask, fastapi, gin, fiber, nextjs, etc
+	PackageManager   string            `json:"packageManager"`      // npm, pnpm, yarn, bun, pip, cargo, go
 	IsMonorepo       bool              `json:"isMonorepo"`
+	SubPath          string            `json:"subPath,omitempty"` // workspace being deployed, relative to ClonePath
 	HasDocker        bool              `json:"hasDocker"`
 	HasCompose       bool              `json:"hasCompose"`                 // docker-compose.yml
 	DeployHints      []string          `json:"deployHints"`                // fly.toml, render.yaml, etc
@@ -37,37 +44,118 @@ type RepoProfile struct {
 	HasDB            bool              `json:"hasDb"`
 	DBType           string            `json:"dbType"` // postgres, mysql, redis, mongo, etc
 	Summary          string            `json:"summary"`
-	KeyFiles         map[string]string `json:"keyFiles"` // filename → content (capped)
-	FileTree         string            `json:"fileTree"` // top-level directory listing
+	KeyFiles         map[string]string `json:"keyFiles"`             // filename → content (capped)
+	FileTree         string            `json:"fileTree"`             // top-level directory listing
+	RepoConfig       *RepoConfig       `json:"repoConfig,omitempty"` // deploy section of .clanker.yaml, if the repo ships one
+}
+
+// CloneOptions controls how CloneAndAnalyzeWithOptions fetches the repo.
+type CloneOptions struct {
+	Depth  int    // history depth; 0 means 1 since static analysis only needs the tip
+	Token  string // access token for private GitHub/GitLab repos over HTTPS
+	Branch string // branch or tag to clone; empty uses the remote default
+
+	// NoCache clones into a fresh temp dir instead of reusing the clone cache.
+	NoCache bool
+	// CacheDir holds cached clones (deploy.clone_cache_dir); empty uses
+	// DefaultCloneCacheDir.
+	CacheDir string
+	// MaxCacheAge evicts cached clones not used for this long; 0 uses 7 days.
+	MaxCacheAge time.Duration
+	// MaxCacheBytes evicts least recently used clones past this size; 0 uses 2 GiB.
+	MaxCacheBytes int64
 }
 
 // CloneAndAnalyze clones a repo and returns a profile
 func CloneAndAnalyze(ctx context.Context, repoURL string) (*RepoProfile, error) {
+	return CloneAndAnalyzeWithOptions(ctx, repoURL, CloneOptions{})
+}
+
+// CloneAndAnalyzeWithOptions clones a repo with the given depth, branch and
+// credentials and returns a profile. Unless opts.NoCache is set the clone
+// comes from the clone cache, updated in place when the repo was cloned
+// before; callers must not delete ClonePath when FromCache is set.
+func CloneAndAnalyzeWithOptions(ctx context.Context, repoURL string, opts CloneOptions) (*RepoProfile, error) {
+	if !opts.NoCache {
+		dir, err := cachedClone(ctx, repoURL, opts)
+		if err != nil {
+			return nil, err
+		}
+		profile, err := analyzeClone(repoURL, dir)
+		if err != nil {
+			return nil, err
+		}
+		profile.FromCache = true
+		return profile, nil
+	}
+
 	tmpDir, err := os.MkdirTemp("", "clanker-deploy-*")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp dir: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", repoURL, tmpDir)
+	cmd := exec.CommandContext(ctx, "git", cloneArgs(repoURL, tmpDir, opts)...)
+	cmd.Env = append(os.Environ(), cloneAuthEnv(repoURL, opts.Token)...)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		os.RemoveAll(tmpDir)
 		return nil, fmt.Errorf("git clone failed: %w\n%s", err, string(out))
 	}
 
-	profile, err := Analyze(tmpDir)
+	profile, err := analyzeClone(repoURL, tmpDir)
 	if err != nil {
 		os.RemoveAll(tmpDir)
 		return nil, err
 	}
+	return profile, nil
+}
+
+func analyzeClone(repoURL, dir string) (*RepoProfile, error) {
+	profile, err := Analyze(dir)
+	if err != nil {
+		return nil, err
+	}
 
 	profile.RepoURL = repoURL
-	profile.ClonePath = tmpDir
-	profile.KeyFiles = readKeyFiles(tmpDir)
-	profile.FileTree = buildFileTree(tmpDir, "", 0)
+	profile.ClonePath = dir
+	profile.KeyFiles = readKeyFiles(dir)
+	profile.FileTree = buildFileTree(dir, "", 0)
 	profile.Summary = buildSummary(profile)
 	return profile, nil
 }
 
+func cloneArgs(repoURL, dir string, opts CloneOptions) []string {
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = 1
+	}
+	args := []string{"clone", "--depth", strconv.Itoa(depth)}
+	if branch := strings.TrimSpace(opts.Branch); branch != "" {
+		args = append(args, "--branch", branch, "--single-branch")
+	}
+	return append(args, repoURL, dir)
+}
+
+// cloneAuthEnv passes the token as an HTTP Authorization header through git's
+// GIT_CONFIG_* environment, so it never lands in the clone URL, the cloned
+// repo's .git/config, or the process arguments.
+func cloneAuthEnv(repoURL, token string) []string {
+	token = strings.TrimSpace(token)
+	if token == "" || !strings.HasPrefix(strings.ToLower(repoURL), "https://") {
+		return nil
+	}
+	user := "x-access-token"
+	if strings.Contains(strings.ToLower(repoURL), "gitlab") {
+		user = "oauth2"
+	}
+	creds := base64.StdEncoding.EncodeToString([]byte(user + ":" + token))
+	return []string{
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + creds,
+	}
+}
+
 // Analyze inspects a local directory
 func Analyze(dir string) (*RepoProfile, error) {
 	p := &RepoProfile{}
@@ -91,6 +179,13 @@ func Analyze(dir string) (*RepoProfile, error) {
 	detectDatabase(dir, p)
 	detectCommands(dir, p)
 
+	// A broken repo config should not block a deploy that works without it.
+	cfg, err := loadRepoConfig(dir)
+	if err != nil {
+		logging.Infof("[deploy] warning: ignoring repo config: %v", err)
+	}
+	p.RepoConfig = cfg
+
 	return p, nil
 }
 
@@ -193,35 +288,6 @@ func detectMigrations(dir string, p *RepoProfile) {
 	}
 }
 
-func detectNativeDeps(dir string, p *RepoProfile) {
-	if p == nil {
-		return
-	}
-	if !fileExists(dir, "package.json") {
-		return
-	}
-	// Very coarse scan; goal is to warn planner about OS deps.
-	nativeMarkers := []string{
-		"node-gyp",
-		"sharp",
-		"playwright",
-		"puppeteer",
-		"canvas",
-		"better-sqlite3",
-		"sqlite3",
-		"bcrypt",
-		"grpc",
-		"@grpc/grpc-js",
-		"ffi-napi",
-		"ref-napi",
-	}
-	for _, m := range nativeMarkers {
-		if contentContains(dir, "package.json", m) {
-			p.NativeDeps = append(p.NativeDeps, m)
-		}
-	}
-}
-
 func detectStaticSiteAndBuildOutput(dir string, p *RepoProfile) {
 	if p == nil {
 		return
@@ -735,6 +801,9 @@ func buildSummary(p *RepoProfile) string {
 	if len(p.DeployHints) > 0 {
 		parts = append(parts, "deploy hints: "+strings.Join(p.DeployHints, ", "))
 	}
+	if p.RepoConfig != nil {
+		parts = append(parts, "deploy config: "+p.RepoConfig.File)
+	}
 	return strings.Join(parts, " • ")
 }
 
@@ -754,7 +823,9 @@ func readKeyFiles(dir string) map[string]string {
 		"go.mod",
 		"requirements.txt", "pyproject.toml",
 		"Cargo.toml",
-		"pom.xml",
+		"pom.xml", "build.gradle", "build.gradle.kts",
+		"Gemfile",
+		".nvmrc", ".node-version", ".python-version", ".ruby-version", "runtime.txt", ".tool-versions",
 		"Makefile",
 		"fly.toml",
 		"render.yaml",
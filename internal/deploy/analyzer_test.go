@@ -0,0 +1,43 @@
+package deploy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCloneArgs(t *testing.T) {
+	got := cloneArgs("https://github.com/o/r", "/tmp/x", CloneOptions{})
+	want := []string{"clone", "--depth", "1", "https://github.com/o/r", "/tmp/x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("default cloneArgs = %v, want %v", got, want)
+	}
+
+	got = cloneArgs("https://github.com/o/r", "/tmp/x", CloneOptions{Depth: 10, Branch: "release"})
+	want = []string{"clone", "--depth", "10", "--branch", "release", "--single-branch", "https://github.com/o/r", "/tmp/x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("cloneArgs = %v, want %v", got, want)
+	}
+}
+
+func TestCloneAuthEnv(t *testing.T) {
+	if env := cloneAuthEnv("https://github.com/o/r", ""); env != nil {
+		t.Fatalf("expected no auth env without a token, got %v", env)
+	}
+	if env := cloneAuthEnv("git@github.com:o/r.git", "secret"); env != nil {
+		t.Fatalf("expected no auth env for ssh URLs, got %v", env)
+	}
+
+	env := cloneAuthEnv("https://github.com/o/r", "secret")
+	joined := strings.Join(env, "\n")
+	if !strings.Contains(joined, "GIT_CONFIG_KEY_0=http.extraHeader") {
+		t.Fatalf("expected http.extraHeader config, got %v", env)
+	}
+	if strings.Contains(joined, "secret") {
+		t.Fatal("token must be encoded, not passed verbatim")
+	}
+	// base64("oauth2:secret")
+	if env := cloneAuthEnv("https://gitlab.com/o/r", "secret"); !strings.Contains(strings.Join(env, "\n"), "b2F1dGgyOnNlY3JldA==") {
+		t.Fatalf("expected oauth2 basic credentials for GitLab, got %v", env)
+	}
+}
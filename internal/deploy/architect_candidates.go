@@ -0,0 +1,176 @@
+package deploy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxArchitectCandidates caps how many architectures the architect proposes
+// when asked for candidates.
+const maxArchitectCandidates = 3
+
+// ArchitectChooser picks one of the architect's candidates before the maker
+// pipeline runs. It returns the index of the pick; recommended is the
+// architect's own choice.
+type ArchitectChooser func(candidates []ArchitectDecision, recommended int) (int, error)
+
+// architectCandidateCount is how many candidates opts asks for: 0 for a
+// single decision, otherwise 2-3.
+func architectCandidateCount(opts *DeployOptions) int {
+	if opts == nil || opts.ArchitectCandidates < 2 {
+		return 0
+	}
+	return min(opts.ArchitectCandidates, maxArchitectCandidates)
+}
+
+// architectCandidatesPrompt asks the architect for n complete alternatives
+// instead of one decision.
+func architectCandidatesPrompt(n int) string {
+	return fmt.Sprintf(`
+
+## Candidate Architectures
+Instead of a single decision, return %d candidate architectures that are genuinely different tradeoffs
+(for example cheapest vs. simplest to operate vs. most headroom), not small variations of one method.
+Each candidate is a complete decision in the response format above: its own method, buildSteps,
+cpuMemory, estMonthly and costBreakdown. Add "tradeoffs": a list of what this candidate gives up or
+gains compared with the others. Mark the one you would pick with "recommended" (its index).
+
+Respond with this JSON object instead:
+{
+  "recommended": 0,
+  "candidates": [
+    {"provider": "aws", "method": "...", "reasoning": "...", "buildSteps": ["..."], "estMonthly": "...", "tradeoffs": ["..."]}
+  ]
+}`, n)
+}
+
+// ParseArchitectCandidates parses a candidates response. Each candidate is
+// parsed like a single architect decision and the recommended one is marked;
+// an out-of-range recommendation falls back to the first candidate. Fewer
+// than two usable candidates is an error so the caller can fall back to a
+// single decision.
+func ParseArchitectCandidates(raw string) ([]ArchitectDecision, int, error) {
+	var resp struct {
+		Recommended int              `json:"recommended"`
+		Candidates  []map[string]any `json:"candidates"`
+	}
+	if _, err := decodeLLMJSON(raw, &resp, "candidates"); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse architect candidates: %w", err)
+	}
+
+	var candidates []ArchitectDecision
+	recommended := 0
+	for i, obj := range resp.Candidates {
+		encoded, err := json.Marshal(obj)
+		if err != nil {
+			continue
+		}
+		d, err := ParseArchitectDecision(string(encoded))
+		if err != nil {
+			continue // unparseable or no method
+		}
+		if i == resp.Recommended {
+			recommended = len(candidates)
+		}
+		candidates = append(candidates, *d)
+	}
+	if len(candidates) < 2 {
+		return nil, 0, fmt.Errorf("architect returned %d usable candidates, need at least 2", len(candidates))
+	}
+	if len(candidates) > maxArchitectCandidates {
+		candidates = candidates[:maxArchitectCandidates]
+		if recommended >= maxArchitectCandidates {
+			recommended = 0
+		}
+	}
+	candidates[recommended].Recommended = true
+	return candidates, recommended, nil
+}
+
+// PromptForArchitecture lists the candidates on stderr and reads the user's
+// pick from stdin; Enter keeps the recommended one.
+func PromptForArchitecture(candidates []ArchitectDecision, recommended int) (int, error) {
+	return promptForArchitecture(os.Stdin, os.Stderr, candidates, recommended)
+}
+
+func promptForArchitecture(in io.Reader, out io.Writer, candidates []ArchitectDecision, recommended int) (int, error) {
+	fmt.Fprintf(out, "\n[deploy] The architect proposed %d architectures:\n", len(candidates))
+	for i, c := range candidates {
+		label := ""
+		if i == recommended {
+			label = " (recommended)"
+		}
+		fmt.Fprintf(out, "\n  %d. %s/%s%s", i+1, c.Provider, c.Method, label)
+		if c.EstMonthly != "" {
+			fmt.Fprintf(out, " — %s/month", c.EstMonthly)
+		}
+		fmt.Fprintln(out)
+		if c.Reasoning != "" {
+			fmt.Fprintf(out, "     %s\n", c.Reasoning)
+		}
+		for _, t := range c.Tradeoffs {
+			fmt.Fprintf(out, "     - %s\n", t)
+		}
+		if len(c.BuildSteps) > 0 {
+			fmt.Fprintf(out, "     build: %s\n", strings.Join(c.BuildSteps, " → "))
+		}
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "\n  Choose 1-%d [%d]: ", len(candidates), recommended+1)
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if err != nil && err != io.EOF {
+				return 0, err
+			}
+			return recommended, nil
+		}
+		if n, convErr := strconv.Atoi(line); convErr == nil && n >= 1 && n <= len(candidates) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(out, "  [!] Enter a number between 1 and %d\n", len(candidates))
+		if err != nil {
+			return 0, fmt.Errorf("no architecture chosen: %w", err)
+		}
+	}
+}
+
+// chooseArchitectCandidate parses a candidates response into
+// result.Candidates and returns the chosen one: the user's pick when
+// opts.ChooseArchitecture is set, the recommended one otherwise. It returns
+// nil when the response holds fewer than two candidates so the caller parses
+// it as a single decision; only the chooser's error is returned.
+func chooseArchitectCandidate(result *IntelligenceResult, raw string, opts *DeployOptions, logf func(string, ...any)) (*ArchitectDecision, error) {
+	candidates, recommended, err := ParseArchitectCandidates(raw)
+	if err != nil {
+		logf("[intelligence] %v, using a single architecture decision", err)
+		return nil, nil
+	}
+	result.Candidates = candidates
+	for i, c := range candidates {
+		logf("[intelligence] candidate %d: %s/%s %s", i+1, c.Provider, c.Method, c.EstMonthly)
+	}
+
+	pick := recommended
+	if opts.ChooseArchitecture != nil {
+		pick, err = opts.ChooseArchitecture(candidates, recommended)
+		if err != nil {
+			return nil, fmt.Errorf("choosing an architecture: %w", err)
+		}
+		if pick < 0 || pick >= len(candidates) {
+			pick = recommended
+		}
+	}
+	if pick != recommended {
+		logf("[intelligence] using candidate %d (%s) instead of the recommended %s", pick+1, candidates[pick].Method, candidates[recommended].Method)
+	}
+	chosen := candidates[pick]
+	return &chosen, nil
+}
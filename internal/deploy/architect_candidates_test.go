@@ -0,0 +1,115 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const candidatesResponse = `{
+  "recommended": 1,
+  "candidates": [
+    {"method": "lambda-apigw", "reasoning": "cheapest at low traffic", "estMonthly": "$2-5", "buildSteps": ["npm ci", "zip"], "tradeoffs": ["cold starts"]},
+    {"method": "ecs-fargate", "reasoning": "runs the Dockerfile as is", "estMonthly": "$25-35", "needsAlb": "yes", "tradeoffs": "pays for an ALB"},
+    {"reasoning": "no method, dropped"}
+  ]
+}`
+
+func TestParseArchitectCandidates(t *testing.T) {
+	candidates, recommended, err := ParseArchitectCandidates(candidatesResponse)
+	if err != nil {
+		t.Fatalf("ParseArchitectCandidates: %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("got %d candidates, want 2 (the one without a method is dropped): %+v", len(candidates), candidates)
+	}
+	if recommended != 1 || !candidates[1].Recommended || candidates[0].Recommended {
+		t.Errorf("recommended = %d, marks %v/%v; want the fargate candidate", recommended, candidates[0].Recommended, candidates[1].Recommended)
+	}
+	if candidates[0].Provider != "aws" || !candidates[1].NeedsALB {
+		t.Errorf("candidates not parsed like a single decision: %+v", candidates)
+	}
+	if len(candidates[1].Tradeoffs) != 1 || candidates[1].Tradeoffs[0] != "pays for an ALB" {
+		t.Errorf("tradeoffs = %v", candidates[1].Tradeoffs)
+	}
+
+	if _, _, err := ParseArchitectCandidates(`{"method": "ecs-fargate"}`); err == nil {
+		t.Error("expected an error for a single decision")
+	}
+}
+
+func TestChooseArchitectCandidate(t *testing.T) {
+	logf := func(string, ...any) {}
+
+	result := &IntelligenceResult{}
+	var offered int
+	opts := &DeployOptions{ArchitectCandidates: 3, ChooseArchitecture: func(c []ArchitectDecision, recommended int) (int, error) {
+		offered = len(c)
+		return 0, nil
+	}}
+	arch, err := chooseArchitectCandidate(result, candidatesResponse, opts, logf)
+	if err != nil {
+		t.Fatalf("chooseArchitectCandidate: %v", err)
+	}
+	if offered != 2 || arch == nil || arch.Method != "lambda-apigw" {
+		t.Errorf("got %+v after offering %d, want the user's lambda-apigw pick", arch, offered)
+	}
+	if len(result.Candidates) != 2 || !result.Candidates[1].Recommended {
+		t.Errorf("result should carry every candidate with the recommended one marked: %+v", result.Candidates)
+	}
+
+	arch, err = chooseArchitectCandidate(&IntelligenceResult{}, candidatesResponse, &DeployOptions{ArchitectCandidates: 2}, logf)
+	if err != nil || arch == nil || arch.Method != "ecs-fargate" {
+		t.Errorf("without a chooser got %+v (%v), want the recommended ecs-fargate", arch, err)
+	}
+
+	arch, err = chooseArchitectCandidate(&IntelligenceResult{}, `{"method": "ec2"}`, opts, logf)
+	if err != nil || arch != nil {
+		t.Errorf("a single decision should fall through, got %+v (%v)", arch, err)
+	}
+}
+
+func TestPromptForArchitecture(t *testing.T) {
+	candidates, recommended, err := ParseArchitectCandidates(candidatesResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	pick, err := promptForArchitecture(strings.NewReader("7\n1\n"), &out, candidates, recommended)
+	if err != nil || pick != 0 {
+		t.Fatalf("pick = %d (%v), want 0 after an out-of-range answer", pick, err)
+	}
+	for _, want := range []string{"2. aws/ecs-fargate (recommended) — $25-35/month", "- cold starts", "Enter a number between 1 and 2"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("prompt missing %q:\n%s", want, out.String())
+		}
+	}
+
+	pick, err = promptForArchitecture(strings.NewReader("\n"), &out, candidates, recommended)
+	if err != nil || pick != recommended {
+		t.Errorf("Enter should keep the recommended candidate, got %d (%v)", pick, err)
+	}
+}
+
+func TestApplyArchitectureOverridesKeepsUserChoice(t *testing.T) {
+	profile := &RepoProfile{}
+	result := &IntelligenceResult{Preflight: &PreflightReport{IsStaticSite: true}}
+	var logged []string
+	logf := func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+
+	arch := &ArchitectDecision{Provider: "aws", Method: "ecs-fargate"}
+	applyArchitectureOverrides("aws", &DeployOptions{}, profile, nil, result, StatefulnessReport{}, arch, false, logf)
+	if arch.Method != "s3-cloudfront" {
+		t.Errorf("a recommended decision should get the static-site override, got %s", arch.Method)
+	}
+
+	logged = nil
+	arch = &ArchitectDecision{Provider: "aws", Method: "ecs-fargate", UseAPIGateway: true}
+	applyArchitectureOverrides("aws", &DeployOptions{}, profile, nil, result, StatefulnessReport{}, arch, true, logf)
+	if arch.Method != "ecs-fargate" || !arch.UseAPIGateway {
+		t.Errorf("the user's pick must not be rewritten, got %+v", arch)
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], "clanker would use s3-cloudfront") {
+		t.Errorf("expected the conflict to be logged, got %v", logged)
+	}
+}
@@ -3,8 +3,11 @@ package deploy
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -17,35 +20,56 @@ type CFInfraSnapshot struct {
 	KVNamespaces  []string `json:"kvNamespaces,omitempty"`  // existing KV namespaces
 	D1Databases   []string `json:"d1Databases,omitempty"`   // existing D1 databases
 	R2Buckets     []string `json:"r2Buckets,omitempty"`     // existing R2 buckets
+	Unavailable   string   `json:"unavailable,omitempty"`   // why the scan was skipped (no wrangler, not authenticated)
 }
 
-// ScanCFInfra queries the Cloudflare account for existing resources via wrangler
-func ScanCFInfra(ctx context.Context, logf func(string, ...any)) *CFInfraSnapshot {
-	snap := &CFInfraSnapshot{}
+// cfAccountIDRe matches a Cloudflare account ID in wrangler whoami output.
+var cfAccountIDRe = regexp.MustCompile(`\b[0-9a-f]{32}\b`)
 
-	// whoami — get account info
-	if out := wranglerCLI(ctx, "whoami"); out != "" {
-		snap.AccountID = strings.TrimSpace(out)
+var (
+	errWranglerMissing         = errors.New("wrangler is not available (install Node.js and run npm i -g wrangler)")
+	errWranglerUnauthenticated = errors.New("wrangler is not authenticated (set CLOUDFLARE_API_TOKEN or run wrangler login)")
+)
+
+// ScanCFInfra queries the Cloudflare account for existing resources via wrangler,
+// authenticating with apiToken (CLOUDFLARE_API_TOKEN) and accountID when set.
+// Fails gracefully — a missing wrangler or unauthenticated account yields an
+// empty snapshot with Unavailable explaining why.
+func ScanCFInfra(ctx context.Context, apiToken, accountID string, logf func(string, ...any)) *CFInfraSnapshot {
+	snap := &CFInfraSnapshot{AccountID: strings.TrimSpace(accountID)}
+	run := func(args ...string) (string, error) {
+		return wranglerCLI(ctx, apiToken, snap.AccountID, args...)
+	}
+
+	// whoami — confirms wrangler is runnable and authenticated
+	out, err := run("whoami")
+	if err != nil {
+		snap.Unavailable = err.Error()
+		logf("[cf-scan] skipping Cloudflare scan: %s", snap.Unavailable)
+		return snap
+	}
+	if snap.AccountID == "" {
+		snap.AccountID = cfAccountIDRe.FindString(out)
 	}
 
 	// list pages projects
-	if out := wranglerCLI(ctx, "pages", "project", "list"); out != "" {
+	if out, err := run("pages", "project", "list"); err == nil {
 		snap.PagesProjects = parseCFListOutput(out)
 	}
 
-	// list KV namespaces
-	if out := wranglerCLI(ctx, "kv", "namespace", "list"); out != "" {
+	// list KV namespaces (JSON by default)
+	if out, err := run("kv", "namespace", "list"); err == nil {
 		snap.KVNamespaces = parseJSONNames(out, "title")
 	}
 
 	// list D1 databases
-	if out := wranglerCLI(ctx, "d1", "list"); out != "" {
+	if out, err := run("d1", "list", "--json"); err == nil {
 		snap.D1Databases = parseJSONNames(out, "name")
 	}
 
-	// list R2 buckets
-	if out := wranglerCLI(ctx, "r2", "bucket", "list"); out != "" {
-		snap.R2Buckets = parseJSONNames(out, "name")
+	// list R2 buckets (plain "name: ..." blocks)
+	if out, err := run("r2", "bucket", "list"); err == nil {
+		snap.R2Buckets = parseR2BucketList(out)
 	}
 
 	logf("[cf-scan] found: %d pages projects, %d KV namespaces, %d D1 databases, %d R2 buckets",
@@ -74,21 +98,80 @@ func (s *CFInfraSnapshot) FormatCFForPrompt() string {
 	if len(s.R2Buckets) > 0 {
 		b.WriteString("Existing R2 Buckets: " + strings.Join(s.R2Buckets, ", ") + "\n")
 	}
+	if len(s.KVNamespaces)+len(s.D1Databases)+len(s.R2Buckets) > 0 {
+		b.WriteString("→ When a KV namespace, D1 database or R2 bucket above matches a resource the app needs by name, reuse it (look up its ID and bind it) instead of creating a duplicate\n")
+	}
 
 	return b.String()
 }
 
-// wranglerCLI runs a wrangler command, returns stdout or empty on error
-func wranglerCLI(ctx context.Context, args ...string) string {
+// wranglerCLI runs a wrangler command with the given credentials and returns
+// its output. A missing wrangler or an unauthenticated session is reported as
+// errWranglerMissing / errWranglerUnauthenticated.
+func wranglerCLI(ctx context.Context, apiToken, accountID string, args ...string) (string, error) {
+	if _, err := exec.LookPath("npx"); err != nil {
+		return "", errWranglerMissing
+	}
+
 	tctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(tctx, "npx", append([]string{"wrangler"}, args...)...)
+	cmd := exec.CommandContext(tctx, "npx", append([]string{"--no-install", "wrangler"}, args...)...)
+	cmd.Env = os.Environ()
+	if apiToken != "" {
+		cmd.Env = append(cmd.Env, "CLOUDFLARE_API_TOKEN="+apiToken)
+	}
+	if accountID != "" {
+		cmd.Env = append(cmd.Env, "CLOUDFLARE_ACCOUNT_ID="+accountID)
+	}
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return ""
+		return "", classifyWranglerError(string(out), err)
+	}
+	if isWranglerUnauthenticated(string(out)) {
+		return "", errWranglerUnauthenticated
+	}
+	return string(out), nil
+}
+
+func classifyWranglerError(out string, err error) error {
+	lower := strings.ToLower(out)
+	switch {
+	case strings.Contains(lower, "could not determine executable"),
+		strings.Contains(lower, "command not found"),
+		strings.Contains(lower, "npm err! missing"),
+		strings.Contains(lower, "canceled due to missing packages"):
+		return errWranglerMissing
+	case isWranglerUnauthenticated(out):
+		return errWranglerUnauthenticated
+	}
+	return fmt.Errorf("wrangler failed: %w", err)
+}
+
+func isWranglerUnauthenticated(out string) bool {
+	lower := strings.ToLower(out)
+	return strings.Contains(lower, "not authenticated") ||
+		strings.Contains(lower, "not logged in") ||
+		strings.Contains(lower, "authentication error") ||
+		strings.Contains(lower, "invalid api token")
+}
+
+// parseR2BucketList parses wrangler r2 bucket list output, which is either a
+// JSON array or blocks of "name: <bucket>" / "creation_date: ..." lines.
+func parseR2BucketList(raw string) []string {
+	if names := parseJSONNames(raw, "name"); len(names) > 0 {
+		return names
 	}
-	return string(out)
+	var names []string
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if name, ok := strings.CutPrefix(line, "name:"); ok {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
 }
 
 // parseCFListOutput parses wrangler list output (table format) into names
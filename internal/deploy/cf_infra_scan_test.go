@@ -0,0 +1,42 @@
+package deploy
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseR2BucketList(t *testing.T) {
+	text := "Listing buckets...\nname:           assets\ncreation_date:  2024-01-02T03:04:05.000Z\n\nname:           uploads\ncreation_date:  2024-02-03T04:05:06.000Z\n"
+	if got := parseR2BucketList(text); !reflect.DeepEqual(got, []string{"assets", "uploads"}) {
+		t.Errorf("text output: got %v", got)
+	}
+	if got := parseR2BucketList(`[{"name":"assets","creation_date":"2024-01-02"}]`); !reflect.DeepEqual(got, []string{"assets"}) {
+		t.Errorf("json output: got %v", got)
+	}
+}
+
+func TestClassifyWranglerError(t *testing.T) {
+	exit := errors.New("exit status 1")
+	if err := classifyWranglerError("npm ERR! canceled due to missing packages and no YES option", exit); err != errWranglerMissing {
+		t.Errorf("missing package: got %v", err)
+	}
+	if err := classifyWranglerError("You are not authenticated. Please run `wrangler login`.", exit); err != errWranglerUnauthenticated {
+		t.Errorf("unauthenticated: got %v", err)
+	}
+	if err := classifyWranglerError("something else", exit); err == errWranglerMissing || err == errWranglerUnauthenticated {
+		t.Errorf("unexpected classification: %v", err)
+	}
+}
+
+func TestFormatCFForPromptAsksForReuse(t *testing.T) {
+	snap := &CFInfraSnapshot{D1Databases: []string{"app-db"}, KVNamespaces: []string{"SESSIONS"}}
+	out := snap.FormatCFForPrompt()
+	if !strings.Contains(out, "Existing D1 Databases: app-db") || !strings.Contains(out, "reuse it") {
+		t.Errorf("expected resources and reuse guidance:\n%s", out)
+	}
+	if got := (&CFInfraSnapshot{PagesProjects: []string{"site"}}).FormatCFForPrompt(); strings.Contains(got, "reuse it") {
+		t.Errorf("no reuse guidance expected without KV/D1/R2:\n%s", got)
+	}
+}
@@ -0,0 +1,195 @@
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bgdnvk/clanker/internal/logging"
+)
+
+const (
+	// defaultCloneCacheMaxAge evicts clones not used for a week.
+	defaultCloneCacheMaxAge = 7 * 24 * time.Hour
+	// defaultCloneCacheMaxBytes caps the cache at 2 GiB.
+	defaultCloneCacheMaxBytes int64 = 2 << 30
+)
+
+// DefaultCloneCacheDir is where clones are cached when deploy.clone_cache_dir
+// is unset: <user cache dir>/clanker/repos.
+func DefaultCloneCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "clanker", "repos")
+}
+
+// cloneCacheKey names a repo's cache entry by its URL and ref, so iterating on
+// the same repo and branch reuses one directory.
+func cloneCacheKey(repoURL, ref string) string {
+	url := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(repoURL), "/"), ".git")
+	sum := sha256.Sum256([]byte(url + "\x00" + strings.TrimSpace(ref)))
+	return hex.EncodeToString(sum[:])[:24]
+}
+
+// cachedClone returns an up-to-date clone of repoURL from the cache under
+// opts.CacheDir, fetching and hard-resetting an existing entry or cloning a
+// new one. An entry that fails to update is discarded and cloned again.
+func cachedClone(ctx context.Context, repoURL string, opts CloneOptions) (string, error) {
+	root := opts.CacheDir
+	if strings.TrimSpace(root) == "" {
+		root = DefaultCloneCacheDir()
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create clone cache dir: %w", err)
+	}
+	dir := filepath.Join(root, cloneCacheKey(repoURL, opts.Branch))
+	env := append(os.Environ(), cloneAuthEnv(repoURL, opts.Token)...)
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		err := updateCachedClone(ctx, dir, opts, env)
+		if err == nil {
+			touchCacheEntry(dir)
+			evictCloneCache(root, opts.MaxCacheAge, opts.MaxCacheBytes, dir)
+			return dir, nil
+		}
+		logging.Infof("[deploy] cached clone could not be updated (%v), cloning again", err)
+	}
+	os.RemoveAll(dir)
+
+	// Clone beside the entry and rename it into place, so an interrupted
+	// clone never looks like a usable cache entry.
+	staging, err := os.MkdirTemp(root, ".clone-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, "git", cloneArgs(repoURL, staging, opts)...)
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("git clone failed: %w\n%s", err, string(out))
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("failed to move clone into cache: %w", err)
+	}
+	evictCloneCache(root, opts.MaxCacheAge, opts.MaxCacheBytes, dir)
+	return dir, nil
+}
+
+// updateCachedClone brings a cached clone to the tip of its ref and removes
+// anything a previous run left in the working tree.
+func updateCachedClone(ctx context.Context, dir string, opts CloneOptions, env []string) error {
+	for _, args := range updateArgs(opts) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+func updateArgs(opts CloneOptions) [][]string {
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = 1
+	}
+	ref := strings.TrimSpace(opts.Branch)
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return [][]string{
+		{"fetch", "--depth", strconv.Itoa(depth), "--force", "origin", ref},
+		{"reset", "--hard", "FETCH_HEAD"},
+		{"clean", "-ffdx"},
+	}
+}
+
+// touchCacheEntry marks an entry as used; eviction goes by the entry's mtime.
+func touchCacheEntry(dir string) {
+	now := time.Now()
+	_ = os.Chtimes(dir, now, now)
+}
+
+// evictCloneCache removes entries under root not used within maxAge, then
+// the least recently used entries until the cache fits in maxBytes. keep is
+// never evicted. Zero limits use the defaults; negative ones disable that
+// check.
+func evictCloneCache(root string, maxAge time.Duration, maxBytes int64, keep string) {
+	if maxAge == 0 {
+		maxAge = defaultCloneCacheMaxAge
+	}
+	if maxBytes == 0 {
+		maxBytes = defaultCloneCacheMaxBytes
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	type cacheEntry struct {
+		path  string
+		used  time.Time
+		bytes int64
+	}
+	var live []cacheEntry
+	var total int64
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(root, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if path != keep && maxAge > 0 && time.Since(info.ModTime()) > maxAge {
+			os.RemoveAll(path)
+			continue
+		}
+		size := dirSize(path)
+		total += size
+		if path != keep {
+			live = append(live, cacheEntry{path: path, used: info.ModTime(), bytes: size})
+		}
+	}
+	if maxBytes < 0 || total <= maxBytes {
+		return
+	}
+
+	sort.Slice(live, func(i, j int) bool { return live[i].used.Before(live[j].used) })
+	for _, e := range live {
+		if total <= maxBytes {
+			break
+		}
+		if os.RemoveAll(e.path) == nil {
+			total -= e.bytes
+		}
+	}
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
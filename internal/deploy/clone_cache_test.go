@@ -0,0 +1,114 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestCachedCloneUpdatesInPlace(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	src := t.TempDir()
+	gitRun(t, src, "init", "-q")
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n"), 0o644)
+	gitRun(t, src, "add", ".")
+	gitRun(t, src, "commit", "-qm", "first")
+
+	opts := CloneOptions{CacheDir: t.TempDir()}
+	url := "file://" + src
+	dir, err := cachedClone(context.Background(), url, opts)
+	if err != nil {
+		t.Fatalf("first clone: %v", err)
+	}
+	// Leftovers from a previous run must not survive the update.
+	os.WriteFile(filepath.Join(dir, "stale.txt"), []byte("x"), 0o644)
+
+	os.WriteFile(filepath.Join(src, "Dockerfile"), []byte("FROM scratch\n"), 0o644)
+	gitRun(t, src, "add", ".")
+	gitRun(t, src, "commit", "-qm", "second")
+
+	again, err := cachedClone(context.Background(), url, opts)
+	if err != nil {
+		t.Fatalf("cached clone: %v", err)
+	}
+	if again != dir {
+		t.Fatalf("expected the same cache entry, got %s and %s", dir, again)
+	}
+	if !fileExists(again, "Dockerfile") {
+		t.Error("cached clone was not updated to the latest commit")
+	}
+	if fileExists(again, "stale.txt") {
+		t.Error("untracked files were not cleaned")
+	}
+
+	if cloneCacheKey(url, "") == cloneCacheKey(url, "release") {
+		t.Error("different refs must use different cache entries")
+	}
+	if cloneCacheKey("https://github.com/o/r.git", "") != cloneCacheKey("https://github.com/o/r", "") {
+		t.Error("a trailing .git should not change the cache key")
+	}
+}
+
+func TestCloneAndAnalyzeNoCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	src := t.TempDir()
+	gitRun(t, src, "init", "-q")
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n"), 0o644)
+	gitRun(t, src, "add", ".")
+	gitRun(t, src, "commit", "-qm", "first")
+
+	cacheDir := t.TempDir()
+	profile, err := CloneAndAnalyzeWithOptions(context.Background(), "file://"+src, CloneOptions{NoCache: true, CacheDir: cacheDir})
+	if err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	defer os.RemoveAll(profile.ClonePath)
+	if profile.FromCache || strings.HasPrefix(profile.ClonePath, cacheDir) {
+		t.Errorf("expected a fresh clone outside the cache, got %s (FromCache=%t)", profile.ClonePath, profile.FromCache)
+	}
+	if entries, _ := os.ReadDir(cacheDir); len(entries) != 0 {
+		t.Errorf("expected the clone cache to stay empty, got %d entries", len(entries))
+	}
+}
+
+func TestEvictCloneCache(t *testing.T) {
+	root := t.TempDir()
+	entry := func(name string, age time.Duration, bytes int) string {
+		dir := filepath.Join(root, name)
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, "blob"), make([]byte, bytes), 0o644)
+		used := time.Now().Add(-age)
+		os.Chtimes(dir, used, used)
+		return dir
+	}
+	expired := entry("expired", 10*24*time.Hour, 10)
+	oldest := entry("oldest", 3*time.Hour, 600)
+	newer := entry("newer", time.Hour, 600)
+	keep := entry("keep", 5*time.Hour, 600)
+
+	evictCloneCache(root, 0, 1500, keep)
+
+	for dir, want := range map[string]bool{expired: false, oldest: false, newer: true, keep: true} {
+		if _, err := os.Stat(dir); (err == nil) != want {
+			t.Errorf("%s kept=%t, want %t", filepath.Base(dir), err == nil, want)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package deploy
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bgdnvk/clanker/internal/maker"
+)
+
+// Static us-east-1 on-demand prices (USD) for the managed pieces a deploy
+// method adds on top of compute. Instance and RDS classes come from the
+// maker price tables so a plan estimate and an architect estimate agree.
+const (
+	fargateVCPUHour      = 0.04048
+	fargateGBHour        = 0.004445
+	appRunnerVCPUHour    = 0.064
+	appRunnerGBHour      = 0.007
+	albHour              = 0.0225
+	albLCUHour           = 0.008
+	natGatewayHour       = 0.045
+	eksControlPlaneHour  = 0.10
+	ebsGBMonth           = 0.08  // gp3
+	rdsStorageGBMonth    = 0.115 // gp2
+	s3GBMonth            = 0.023
+	cloudFrontEgressGB   = 0.085
+	lambdaPerMillion     = 0.20
+	lambdaGBSecond       = 0.0000166667
+	apiGatewayPerMillion = 1.00 // HTTP API
+	doAppPlatformBasic   = 5.0
+	cfWorkersPaid        = 5.0
+)
+
+// elastiCacheHourly covers the cache node types the architect suggests.
+var elastiCacheHourly = map[string]float64{
+	"cache.t3.micro": 0.017, "cache.t3.small": 0.034, "cache.t3.medium": 0.068,
+	"cache.t4g.micro": 0.016, "cache.t4g.small": 0.032,
+}
+
+// CostParams sizes a deploy for EstimateMonthlyCost; the method implies the
+// provider. Zero values take the defaults noted on each field, which are
+// also listed in the estimate's assumptions.
+type CostParams struct {
+	VCPU         float64 // per task for fargate / app-runner; default 0.25
+	MemoryGB     float64 // per task for fargate / app-runner; default 0.5
+	Count        int     // tasks, instances or nodes; default 1 (2 nodes for eks)
+	InstanceType string  // ec2 type, droplet size, GCE machine type or Hetzner server type
+	ALB          bool
+	NATGateway   bool
+	DBClass      string  // RDS class (db.*) or ElastiCache node type (cache.*)
+	DBStorageGB  float64 // default 20
+	DiskGB       float64 // EBS per instance for ec2/eks nodes; default 20
+	StorageGB    float64 // S3 storage for s3-cloudfront; default 1
+	EgressGB     float64 // monthly CloudFront egress for s3-cloudfront; default 10
+	// Requests, DurationMs and LambdaMemoryMB size lambda and API Gateway;
+	// defaults 1M requests/month of 200ms at 512 MB.
+	Requests       float64
+	DurationMs     float64
+	LambdaMemoryMB float64
+}
+
+// CostLineItem is one priced component of a CostEstimate.
+type CostLineItem struct {
+	Name       string  `json:"name"`
+	MonthlyUSD float64 `json:"monthlyUsd"`
+}
+
+// CostEstimate is a deterministic monthly cost from static prices.
+type CostEstimate struct {
+	Method      string         `json:"method"`
+	MonthlyUSD  float64        `json:"monthlyUsd"`
+	Items       []CostLineItem `json:"items"`
+	Assumptions []string       `json:"assumptions,omitempty"`
+}
+
+// Breakdown renders the items as "name: ~$X/mo" lines, the format of
+// ArchitectDecision.CostBreakdown.
+func (e CostEstimate) Breakdown() []string {
+	lines := make([]string, 0, len(e.Items))
+	for _, item := range e.Items {
+		lines = append(lines, fmt.Sprintf("%s: ~$%.2f/mo", item.Name, item.MonthlyUSD))
+	}
+	return lines
+}
+
+func (e *CostEstimate) add(name string, monthly float64) {
+	e.Items = append(e.Items, CostLineItem{Name: name, MonthlyUSD: monthly})
+	e.MonthlyUSD += monthly
+}
+
+// EstimateMonthlyCost prices a deploy method from static on-demand tables.
+// It is coarse (no free tier, no data transfer beyond CloudFront egress)
+// but reproducible, unlike an LLM's guess. Methods or instance types
+// without a price return an error.
+func EstimateMonthlyCost(method string, params CostParams) (CostEstimate, error) {
+	method = strings.ToLower(strings.TrimSpace(method))
+	est := CostEstimate{Method: method}
+	count := params.Count
+	if count <= 0 {
+		count = 1
+	}
+	assume := func(format string, args ...any) {
+		est.Assumptions = append(est.Assumptions, fmt.Sprintf(format, args...))
+	}
+	instance := func(provider, defaultType, label string) error {
+		instanceType := strings.TrimSpace(params.InstanceType)
+		if instanceType == "" {
+			instanceType = defaultType
+			assume("%s size %s", label, instanceType)
+		}
+		hourly, ok := maker.InstanceHourlyPrice(provider, instanceType)
+		if !ok {
+			return fmt.Errorf("no price for %s %q", label, instanceType)
+		}
+		est.add(fmt.Sprintf("%d× %s %s", count, label, instanceType), hourly*maker.HoursPerMonth*float64(count))
+		return nil
+	}
+
+	switch method {
+	case "ecs-fargate", "fargate":
+		vcpu, mem := taskSize(params, assume)
+		est.add(fmt.Sprintf("Fargate %d× %.2g vCPU / %.2g GB", count, vcpu, mem), (vcpu*fargateVCPUHour+mem*fargateGBHour)*maker.HoursPerMonth*float64(count))
+	case "app-runner":
+		vcpu, mem := taskSize(params, assume)
+		est.add(fmt.Sprintf("App Runner %d× %.2g vCPU / %.2g GB (always active)", count, vcpu, mem), (vcpu*appRunnerVCPUHour+mem*appRunnerGBHour)*maker.HoursPerMonth*float64(count))
+	case "ec2":
+		if err := instance("aws", "t3.small", "EC2"); err != nil {
+			return est, err
+		}
+		disk := diskGB(params, assume) * float64(count)
+		est.add(fmt.Sprintf("EBS gp3 %.0f GB", disk), disk*ebsGBMonth)
+	case "eks":
+		if params.Count <= 0 {
+			count = 2
+			assume("2 worker nodes")
+		}
+		est.add("EKS control plane", eksControlPlaneHour*maker.HoursPerMonth)
+		if err := instance("aws", "t3.medium", "EKS node"); err != nil {
+			return est, err
+		}
+		disk := diskGB(params, assume) * float64(count)
+		est.add(fmt.Sprintf("EBS gp3 %.0f GB", disk), disk*ebsGBMonth)
+	case "lambda", "lambda-apigw":
+		requests, duration, memMB := params.Requests, params.DurationMs, params.LambdaMemoryMB
+		if requests <= 0 {
+			requests = 1_000_000
+			assume("1M requests/month")
+		}
+		if duration <= 0 {
+			duration = 200
+			assume("200ms average duration")
+		}
+		if memMB <= 0 {
+			memMB = 512
+			assume("512 MB memory")
+		}
+		est.add("Lambda requests", requests/1_000_000*lambdaPerMillion)
+		est.add("Lambda compute", requests*duration/1000*memMB/1024*lambdaGBSecond)
+		if method == "lambda-apigw" {
+			est.add("API Gateway HTTP API", requests/1_000_000*apiGatewayPerMillion)
+		}
+	case "s3-cloudfront":
+		storage, egress := params.StorageGB, params.EgressGB
+		if storage <= 0 {
+			storage = 1
+			assume("1 GB stored")
+		}
+		if egress <= 0 {
+			egress = 10
+			assume("10 GB/month egress")
+		}
+		est.add(fmt.Sprintf("S3 %.0f GB", storage), storage*s3GBMonth)
+		est.add(fmt.Sprintf("CloudFront %.0f GB egress", egress), egress*cloudFrontEgressGB)
+	case "do-droplet":
+		if err := instance("digitalocean", "s-1vcpu-2gb", "Droplet"); err != nil {
+			return est, err
+		}
+	case "do-app-platform", "app-platform":
+		est.add(fmt.Sprintf("App Platform %d× basic container", count), doAppPlatformBasic*float64(count))
+	case "gcp-compute-engine":
+		if err := instance("gcp", "e2-small", "Compute Engine"); err != nil {
+			return est, err
+		}
+	case "hetzner-server":
+		if err := instance("hetzner", "cx21", "Hetzner server"); err != nil {
+			return est, err
+		}
+	case "cf-pages":
+		est.add("Cloudflare Pages", 0)
+	case "cf-workers":
+		est.add("Cloudflare Workers paid plan", cfWorkersPaid)
+	default:
+		return est, fmt.Errorf("no price table for method %q", method)
+	}
+
+	if params.ALB {
+		est.add("ALB (1 LCU)", (albHour+albLCUHour)*maker.HoursPerMonth)
+	}
+	if params.NATGateway {
+		est.add("NAT gateway", natGatewayHour*maker.HoursPerMonth)
+	}
+	if class := strings.ToLower(strings.TrimSpace(params.DBClass)); class != "" {
+		if hourly, ok := elastiCacheHourly[class]; ok {
+			est.add("ElastiCache "+class, hourly*maker.HoursPerMonth)
+		} else if hourly, ok := maker.InstanceHourlyPrice("aws", class); ok {
+			storage := params.DBStorageGB
+			if storage <= 0 {
+				storage = 20
+				assume("20 GB database storage")
+			}
+			est.add("RDS "+class, hourly*maker.HoursPerMonth)
+			est.add(fmt.Sprintf("RDS storage %.0f GB", storage), storage*rdsStorageGBMonth)
+		} else {
+			return est, fmt.Errorf("no price for database class %q", class)
+		}
+	}
+	est.MonthlyUSD = math.Round(est.MonthlyUSD*100) / 100
+	return est, nil
+}
+
+func taskSize(params CostParams, assume func(string, ...any)) (vcpu, memGB float64) {
+	vcpu, memGB = params.VCPU, params.MemoryGB
+	if vcpu <= 0 {
+		vcpu = 0.25
+		assume("0.25 vCPU per task")
+	}
+	if memGB <= 0 {
+		memGB = 0.5
+		assume("0.5 GB memory per task")
+	}
+	return vcpu, memGB
+}
+
+func diskGB(params CostParams, assume func(string, ...any)) float64 {
+	if params.DiskGB > 0 {
+		return params.DiskGB
+	}
+	assume("20 GB disk per instance")
+	return 20
+}
+
+// CostParamsFromArchitect sizes an estimate from the architect's decision:
+// cpuMemory as "256/512" (CPU units / MB) for containers or an instance type
+// for VMs, the ALB flag, and a small instance for the managed DB.
+func CostParamsFromArchitect(arch *ArchitectDecision, opts *DeployOptions) CostParams {
+	params := CostParams{ALB: arch.NeedsALB}
+	sizing := strings.TrimSpace(arch.CpuMemory)
+	if parts := strings.SplitN(sizing, "/", 2); len(parts) == 2 {
+		cpu, cpuErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		mem, memErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if cpuErr == nil && memErr == nil {
+			params.VCPU, params.MemoryGB = cpu/1024, mem/1024
+		}
+	} else if instanceTypePattern.MatchString(sizing) {
+		params.InstanceType = sizing
+	}
+	if params.InstanceType == "" && opts != nil && arch.Method == "ec2" {
+		params.InstanceType = strings.TrimSpace(opts.InstanceType)
+	}
+	if arch.NeedsDB {
+		switch service := strings.ToLower(arch.DBService); {
+		case strings.Contains(service, "redis"), strings.Contains(service, "elasticache"), strings.Contains(service, "valkey"):
+			params.DBClass = "cache.t3.micro"
+		case strings.HasPrefix(service, "rds"), strings.Contains(service, "postgres"), strings.Contains(service, "mysql"):
+			params.DBClass = "db.t3.micro"
+		}
+	}
+	return params
+}
+
+// instanceTypePattern matches a bare instance, droplet, machine or server
+// type such as t3.small, s-1vcpu-2gb, e2-small or cx21.
+var instanceTypePattern = regexp.MustCompile(`^[a-z0-9]+([.-][a-z0-9]+)+$|^c[a-z]*x\d+$`)
+
+var estMonthlyNumber = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// estMonthlyRange parses the architect's estMonthly ("$15-25", "~$12/mo",
+// "$0 (free tier)") into a range. ok is false when it has no number.
+func estMonthlyRange(s string) (low, high float64, ok bool) {
+	nums := estMonthlyNumber.FindAllString(strings.ReplaceAll(s, ",", ""), 2)
+	if len(nums) == 0 {
+		return 0, 0, false
+	}
+	low, _ = strconv.ParseFloat(nums[0], 64)
+	high = low
+	if len(nums) == 2 {
+		high, _ = strconv.ParseFloat(nums[1], 64)
+		if high < low {
+			low, high = high, low
+		}
+	}
+	return low, high, true
+}
+
+// costDivergenceFactor: an LLM estimate more than this factor away from the
+// deterministic one is replaced.
+const costDivergenceFactor = 2.0
+
+// CrossCheckArchitectCost compares the architect's EstMonthly with
+// EstimateMonthlyCost and keeps the deterministic number when the two
+// diverge by more than costDivergenceFactor or the architect gave none.
+// It returns the estimate (nil for unpriced methods) and whether arch was
+// changed.
+func CrossCheckArchitectCost(arch *ArchitectDecision, opts *DeployOptions) (*CostEstimate, bool) {
+	if arch == nil {
+		return nil, false
+	}
+	est, err := EstimateMonthlyCost(arch.Method, CostParamsFromArchitect(arch, opts))
+	if err != nil {
+		return nil, false
+	}
+	low, high, ok := estMonthlyRange(arch.EstMonthly)
+	if ok {
+		lowBound, highBound := low/costDivergenceFactor, high*costDivergenceFactor
+		// A dollar either way is noise for near-free methods.
+		if est.MonthlyUSD >= lowBound-1 && est.MonthlyUSD <= highBound+1 {
+			return &est, false
+		}
+	}
+	guess := ""
+	if strings.TrimSpace(arch.EstMonthly) != "" {
+		guess = fmt.Sprintf(" (architect guessed %s)", strings.TrimSpace(arch.EstMonthly))
+	}
+	arch.EstMonthly = fmt.Sprintf("~$%s%s", formatMonthlyUSD(est.MonthlyUSD), guess)
+	arch.CostBreakdown = est.Breakdown()
+	return &est, true
+}
+
+// formatMonthlyUSD keeps cents only for amounts under $10.
+func formatMonthlyUSD(v float64) string {
+	if v < 10 {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 0, 64)
+}
@@ -0,0 +1,69 @@
+package deploy
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEstimateMonthlyCost(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		params CostParams
+		want   float64
+	}{
+		// 0.25 vCPU × 0.04048 + 0.5 GB × 0.004445, 730h
+		{"fargate defaults", "ecs-fargate", CostParams{}, 9.01},
+		// 2 tasks + ALB with one LCU
+		{"fargate with alb", "ecs-fargate", CostParams{VCPU: 0.5, MemoryGB: 1, Count: 2, ALB: true}, 58.31},
+		// t3.small + 20 GB gp3 + db.t3.micro + 20 GB storage
+		{"ec2 with rds", "ec2", CostParams{InstanceType: "t3.small", DBClass: "db.t3.micro"}, 31.49},
+		// control plane + 2 × t3.medium + 40 GB gp3
+		{"eks defaults", "eks", CostParams{}, 136.94},
+		{"s3 cloudfront", "s3-cloudfront", CostParams{StorageGB: 10, EgressGB: 100}, 8.73},
+		{"droplet", "do-droplet", CostParams{InstanceType: "s-1vcpu-1gb"}, 6.57},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			est, err := EstimateMonthlyCost(tt.method, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(est.MonthlyUSD-tt.want) > 0.01 {
+				t.Errorf("MonthlyUSD = %.2f, want %.2f (%v)", est.MonthlyUSD, tt.want, est.Breakdown())
+			}
+		})
+	}
+
+	if _, err := EstimateMonthlyCost("ec2", CostParams{InstanceType: "x9.mega"}); err == nil {
+		t.Error("expected an error for an unpriced instance type")
+	}
+	if _, err := EstimateMonthlyCost("azure-vm", CostParams{}); err == nil {
+		t.Error("expected an error for an unpriced method")
+	}
+}
+
+func TestCrossCheckArchitectCost(t *testing.T) {
+	arch := &ArchitectDecision{Method: "ecs-fargate", CpuMemory: "512/1024", NeedsALB: true, EstMonthly: "$3-5"}
+	est, replaced := CrossCheckArchitectCost(arch, nil)
+	if est == nil || !replaced {
+		t.Fatalf("expected a wildly low guess to be replaced, got %v %v", est, replaced)
+	}
+	if arch.EstMonthly != "~$40 (architect guessed $3-5)" {
+		t.Errorf("EstMonthly = %q", arch.EstMonthly)
+	}
+	if len(arch.CostBreakdown) != 2 || !strings.HasPrefix(arch.CostBreakdown[1], "ALB (1 LCU): ~$22.27/mo") {
+		t.Errorf("CostBreakdown = %v", arch.CostBreakdown)
+	}
+
+	close := &ArchitectDecision{Method: "ec2", CpuMemory: "t3.small", EstMonthly: "$15-25"}
+	if _, replaced := CrossCheckArchitectCost(close, nil); replaced || close.EstMonthly != "$15-25" {
+		t.Errorf("estimate within range should be kept, got %q", close.EstMonthly)
+	}
+
+	missing := &ArchitectDecision{Method: "lambda"}
+	if _, replaced := CrossCheckArchitectCost(missing, nil); !replaced || missing.EstMonthly != "~$1.87" {
+		t.Errorf("missing estimate should be filled, got %q", missing.EstMonthly)
+	}
+}
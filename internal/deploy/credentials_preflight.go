@@ -0,0 +1,131 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// credentialPreflightTimeout bounds each credential check; a hung CLI
+// should not stall the deploy longer than the check is worth.
+const credentialPreflightTimeout = 20 * time.Second
+
+// credentialLookPath and credentialCommand run the provider CLIs; tests
+// replace them.
+var (
+	credentialLookPath = exec.LookPath
+	credentialCommand  = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, name, args...)
+		if len(env) > 0 {
+			cmd.Env = append(os.Environ(), env...)
+		}
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+)
+
+// PreflightCredentials checks that the provider's CLI is installed and
+// logged in before any phase depends on it, and returns an error that says
+// what to run when it is not. Token-based providers (digitalocean, hetzner)
+// are not checked here.
+func PreflightCredentials(provider, awsProfile, awsRegion string) error {
+	return preflightCredentials(context.Background(), provider, awsProfile, awsRegion, nil)
+}
+
+func preflightCredentials(ctx context.Context, provider, awsProfile, awsRegion string, opts *DeployOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, credentialPreflightTimeout)
+	defer cancel()
+
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "aws", "":
+		return preflightAWSCredentials(ctx, awsProfile, awsRegion)
+	case "gcp":
+		if _, err := credentialLookPath("gcloud"); err != nil {
+			return errors.New("gcloud CLI not found on PATH; install the Google Cloud SDK (https://cloud.google.com/sdk/docs/install)")
+		}
+		out, err := credentialCommand(ctx, nil, "gcloud", "auth", "list", "--filter=status:ACTIVE", "--format=value(account)")
+		if err != nil {
+			return fmt.Errorf("gcloud auth list failed (%s); run gcloud auth login", credentialErrorLine(out, err))
+		}
+		if strings.TrimSpace(out) == "" {
+			return errors.New("no active gcloud account; run gcloud auth login")
+		}
+	case "azure":
+		if _, err := credentialLookPath("az"); err != nil {
+			return errors.New("az CLI not found on PATH; install the Azure CLI (https://learn.microsoft.com/cli/azure/install-azure-cli)")
+		}
+		if out, err := credentialCommand(ctx, nil, "az", "account", "show", "--output", "json"); err != nil {
+			return fmt.Errorf("az account show failed (%s); run az login", credentialErrorLine(out, err))
+		}
+	case "cloudflare":
+		var env []string
+		if opts != nil && opts.CFToken != "" {
+			env = append(env, "CLOUDFLARE_API_TOKEN="+opts.CFToken)
+		}
+		if opts != nil && opts.CFAccountID != "" {
+			env = append(env, "CLOUDFLARE_ACCOUNT_ID="+opts.CFAccountID)
+		}
+		if _, err := credentialLookPath("npx"); err != nil {
+			return errWranglerMissing
+		}
+		out, err := credentialCommand(ctx, env, "npx", "--no-install", "wrangler", "whoami")
+		if err != nil {
+			return classifyWranglerError(out, err)
+		}
+		if isWranglerUnauthenticated(out) {
+			return errWranglerUnauthenticated
+		}
+	}
+	return nil
+}
+
+func preflightAWSCredentials(ctx context.Context, awsProfile, awsRegion string) error {
+	if _, err := credentialLookPath("aws"); err != nil {
+		return errors.New("aws CLI not found on PATH; install AWS CLI v2 (https://docs.aws.amazon.com/cli/latest/userguide/getting-started-install.html)")
+	}
+	args := []string{"sts", "get-caller-identity", "--output", "json"}
+	if awsProfile != "" {
+		args = append(args, "--profile", awsProfile)
+	}
+	if awsRegion != "" {
+		args = append(args, "--region", awsRegion)
+	}
+	out, err := credentialCommand(ctx, nil, "aws", args...)
+	if err == nil {
+		return nil
+	}
+
+	profileFlag, profileName := "", "the default profile"
+	if awsProfile != "" {
+		profileFlag, profileName = " --profile "+awsProfile, "profile "+awsProfile
+	}
+	lower := strings.ToLower(out)
+	switch {
+	case strings.Contains(lower, "could not be found") && strings.Contains(lower, "profile"):
+		return fmt.Errorf("AWS %s is not configured; run aws configure%s or aws configure sso%s", profileName, profileFlag, profileFlag)
+	case strings.Contains(lower, "sso") && (strings.Contains(lower, "expired") || strings.Contains(lower, "token")):
+		return fmt.Errorf("AWS SSO session for %s has expired; run aws sso login%s", profileName, profileFlag)
+	case strings.Contains(lower, "unable to locate credentials"):
+		return fmt.Errorf("no AWS credentials found for %s; run aws configure%s or aws sso login%s", profileName, profileFlag, profileFlag)
+	case strings.Contains(lower, "expiredtoken") || strings.Contains(lower, "expired"):
+		return fmt.Errorf("AWS credentials for %s have expired; refresh them (aws sso login%s, or new keys in ~/.aws/credentials)", profileName, profileFlag)
+	case strings.Contains(lower, "invalidclienttokenid") || strings.Contains(lower, "signaturedoesnotmatch"):
+		return fmt.Errorf("AWS credentials for %s were rejected; check the access key in ~/.aws/credentials or run aws configure%s", profileName, profileFlag)
+	}
+	return fmt.Errorf("aws sts get-caller-identity failed for %s (%s); check the profile with aws configure list%s", profileName, credentialErrorLine(out, err), profileFlag)
+}
+
+// credentialErrorLine is the first non-empty line of a command's output, or
+// the error when it printed nothing.
+func credentialErrorLine(out string, err error) string {
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return err.Error()
+}
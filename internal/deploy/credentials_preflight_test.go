@@ -0,0 +1,69 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func stubCredentialCLI(t *testing.T, installed map[string]bool, run func(name string, args []string) (string, error)) *[]string {
+	t.Helper()
+	origLook, origCmd := credentialLookPath, credentialCommand
+	t.Cleanup(func() { credentialLookPath, credentialCommand = origLook, origCmd })
+	var calls []string
+	credentialLookPath = func(name string) (string, error) {
+		if installed[name] {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+	credentialCommand = func(_ context.Context, _ []string, name string, args ...string) (string, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return run(name, args)
+	}
+	return &calls
+}
+
+func TestPreflightCredentialsAWS(t *testing.T) {
+	calls := stubCredentialCLI(t, map[string]bool{"aws": true}, func(string, []string) (string, error) {
+		return "Error when retrieving token from sso: Token has expired and refresh failed\n", errors.New("exit status 255")
+	})
+	err := PreflightCredentials("aws", "dev", "eu-west-1")
+	if err == nil || !strings.Contains(err.Error(), "run aws sso login --profile dev") {
+		t.Fatalf("expected an sso login hint, got %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0] != "aws sts get-caller-identity --output json --profile dev --region eu-west-1" {
+		t.Errorf("unexpected calls: %v", *calls)
+	}
+
+	stubCredentialCLI(t, map[string]bool{"aws": true}, func(string, []string) (string, error) {
+		return `{"Account": "123456789012"}`, nil
+	})
+	if err := PreflightCredentials("aws", "", ""); err != nil {
+		t.Errorf("valid credentials should pass, got %v", err)
+	}
+
+	stubCredentialCLI(t, nil, nil)
+	if err := PreflightCredentials("", "", ""); err == nil || !strings.Contains(err.Error(), "aws CLI not found") {
+		t.Errorf("expected a missing CLI error, got %v", err)
+	}
+}
+
+func TestPreflightCredentialsGCPAndAzure(t *testing.T) {
+	stubCredentialCLI(t, map[string]bool{"gcloud": true, "az": true}, func(name string, _ []string) (string, error) {
+		if name == "az" {
+			return "ERROR: Please run 'az login' to setup account.\n", errors.New("exit status 1")
+		}
+		return "", nil
+	})
+	if err := PreflightCredentials("gcp", "", ""); err == nil || !strings.Contains(err.Error(), "run gcloud auth login") {
+		t.Errorf("expected a gcloud auth login hint, got %v", err)
+	}
+	if err := PreflightCredentials("azure", "", ""); err == nil || !strings.Contains(err.Error(), "run az login") {
+		t.Errorf("expected an az login hint, got %v", err)
+	}
+	if err := PreflightCredentials("hetzner", "", ""); err != nil {
+		t.Errorf("token-based providers are not checked, got %v", err)
+	}
+}
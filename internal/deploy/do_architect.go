@@ -0,0 +1,167 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// doDefaultMethod picks the DigitalOcean method used when the architect has no
+// strong opinion: App Platform for stateless web apps, a Droplet for anything
+// stateful or always-on without an HTTP surface.
+func doDefaultMethod(p *RepoProfile, deep *DeepAnalysis) string {
+	if p == nil {
+		return "do-droplet"
+	}
+	if doLooksStateful(p) {
+		return "do-droplet"
+	}
+	if p.IsStaticSite {
+		return "do-app-platform"
+	}
+	servesHTTP := len(p.Ports) > 0
+	if deep != nil && deep.ExposesHTTP {
+		servesHTTP = true
+	}
+	if servesHTTP {
+		return "do-app-platform"
+	}
+	return "do-droplet"
+}
+
+// doLooksStateful reports whether the app keeps state on local disk, which
+// App Platform cannot persist across deploys.
+func doLooksStateful(p *RepoProfile) bool {
+	switch strings.ToLower(strings.TrimSpace(p.DBType)) {
+	case "sqlite", "d1":
+		return true
+	}
+	for _, name := range []string{"docker-compose.yml", "docker-compose.yaml"} {
+		if compose, ok := p.KeyFiles[name]; ok && strings.Contains(compose, "volumes:") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDOMethod maps the method spellings the architect tends to return
+// onto the canonical do-* method names.
+func normalizeDOMethod(method string) string {
+	switch strings.ToLower(strings.TrimSpace(method)) {
+	case "do-app-platform", "app-platform", "app platform", "apps", "do-apps", "do-app":
+		return "do-app-platform"
+	case "do-droplet", "droplet", "droplet-compose", "do-droplet-compose":
+		return "do-droplet"
+	case "do-k8s", "doks", "do-kubernetes", "do-doks", "kubernetes", "k8s":
+		return "do-k8s"
+	default:
+		return method
+	}
+}
+
+// doArchitectPrompt is the DigitalOcean section of the architect prompt.
+func doArchitectPrompt(p *RepoProfile, deep *DeepAnalysis) string {
+	defaultMethod := doDefaultMethod(p, deep)
+	var b strings.Builder
+	b.WriteString(`
+## DigitalOcean Options to Consider
+1. **do-app-platform** — App Platform managed containers. Best for stateless HTTP apps; HTTPS, autoscaling and zero-downtime deploys built in. No persistent local disk. (~$5-12/mo per basic container, +$15/mo for a dev/managed database)
+2. **do-droplet** — Droplet VM + Docker Compose. Best for stateful or always-on services (workers, bots, websockets with local state, SQLite). (~$6-24/mo)
+3. **do-k8s** — DigitalOcean Managed Kubernetes (DOKS). Only for multi-service apps that already ship Kubernetes manifests or when explicitly requested. (~$24+/mo for a 2-node pool, +$12/mo load balancer)
+
+## Defaults
+- Stateless web app → do-app-platform
+- Stateful or always-on process → do-droplet
+`)
+	b.WriteString(fmt.Sprintf("- Heuristic recommendation for this repo: %s\n", defaultMethod))
+	b.WriteString(`
+## DigitalOcean Services
+- App Platform (doctl apps create --spec app.yaml) for managed containers
+- Droplet for always-on runtime
+- Block storage volume for stateful data (optional)
+- Container Registry (DOCR) for Docker images
+- Managed Databases (doctl databases create) when a database is needed
+- Cloud Firewall for port restrictions
+- Reserved IP for stable public endpoint
+
+## Build Steps by Method (doctl)
+- do-app-platform: doctl registry create → docker build/push to DOCR → write app spec (service, http_port, envs) → doctl apps create --spec → doctl apps get to confirm ACTIVE
+- do-droplet: doctl registry create → docker build/push to DOCR → doctl compute firewall create → doctl compute droplet create --user-data-file (install Docker, pull image, compose up) → verify health
+- do-k8s: doctl kubernetes cluster create → doctl registry kubernetes-manifest | kubectl apply → kubectl apply app manifests → verify rollout
+
+## Deployment CLI
+All commands must use doctl CLI only.
+
+## Cost Estimation
+Estimate the MONTHLY cost in USD and include a per-service breakdown.
+
+## Response Format (JSON only, no markdown fences)
+{
+	"provider": "digitalocean",
+	"method": "do-app-platform",
+	"reasoning": "Stateless HTTP app; App Platform gives HTTPS and deploys without managing a VM.",
+	"alternatives": [
+		{"method": "do-droplet", "why_not": "Requires managing a VM; only needed for stateful workloads"},
+		{"method": "do-k8s", "why_not": "Unnecessary complexity for this workload"}
+	],
+	"buildSteps": [
+		"Create Container Registry and push Docker image",
+		"Write App Platform spec with the service, port and env vars",
+		"Create the app with doctl apps create --spec",
+		"Verify the app reaches ACTIVE and the health endpoint responds"
+	],
+	"runCmd": "docker compose up -d",
+	"notes": ["App Platform has no persistent disk; use a managed database for state"],
+	"cpuMemory": "basic-xxs",
+	"needsAlb": false,
+	"useApiGateway": false,
+	"needsDb": false,
+	"dbService": "",
+	"estMonthly": "$5-12",
+	"costBreakdown": ["App Platform basic container", "Container Registry basic"]
+}`)
+	return b.String()
+}
+
+func doAppPlatformPrompt(p *RepoProfile, opts *DeployOptions) string {
+	deployID := ""
+	if opts != nil {
+		deployID = opts.DeployID
+	}
+	resourcePrefix := repoResourcePrefix(p.RepoURL, deployID)
+	port := 8080
+	if len(p.Ports) > 0 {
+		port = p.Ports[0]
+	}
+
+	var b strings.Builder
+	b.WriteString("Deploy using DigitalOcean App Platform (managed containers):\n")
+	b.WriteString(fmt.Sprintf("Naming: use prefix %s for the app and registry repository\n", resourcePrefix))
+	b.WriteString("1. Create a DigitalOcean Container Registry (doctl registry create) if one does not exist\n")
+	b.WriteString("2. Build the Docker image and push it to DOCR\n")
+	b.WriteString(fmt.Sprintf("3. Write an App Platform spec with one service using the DOCR image, http_port %d and instance size basic-xxs\n", port))
+	b.WriteString("4. Put required env vars in the spec; mark secrets with type SECRET\n")
+	b.WriteString("5. Create the app: doctl apps create --spec <spec file>\n")
+	b.WriteString("6. Wait for the deployment to reach ACTIVE (doctl apps get) and capture the live URL\n")
+	b.WriteString("7. Verify the health endpoint responds over HTTPS\n")
+	b.WriteString("- App Platform has no persistent disk; any state must live in a managed database or Spaces\n")
+	return b.String()
+}
+
+func doK8sPrompt(p *RepoProfile, opts *DeployOptions) string {
+	deployID := ""
+	if opts != nil {
+		deployID = opts.DeployID
+	}
+	resourcePrefix := repoResourcePrefix(p.RepoURL, deployID)
+
+	var b strings.Builder
+	b.WriteString("Deploy using DigitalOcean Managed Kubernetes (DOKS):\n")
+	b.WriteString(fmt.Sprintf("Naming: use prefix %s for the cluster, registry repository and Kubernetes resources\n", resourcePrefix))
+	b.WriteString("1. Create a DOKS cluster with a small node pool (doctl kubernetes cluster create --node-pool \"size=s-2vcpu-4gb;count=2\")\n")
+	b.WriteString("2. Create a DigitalOcean Container Registry and integrate it with the cluster (doctl kubernetes cluster registry add)\n")
+	b.WriteString("3. Build the Docker image and push it to DOCR\n")
+	b.WriteString("4. Apply a Deployment and a Service of type LoadBalancer for the app\n")
+	b.WriteString("5. Store secrets as Kubernetes Secrets, not in manifests\n")
+	b.WriteString("6. Wait for the rollout and load balancer IP, then verify the health endpoint\n")
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package deploy
+
+import "testing"
+
+func TestDoDefaultMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *RepoProfile
+		deep    *DeepAnalysis
+		want    string
+	}{
+		{"stateless web app", &RepoProfile{Ports: []int{3000}}, nil, "do-app-platform"},
+		{"static site", &RepoProfile{IsStaticSite: true}, nil, "do-app-platform"},
+		{"http from deep analysis", &RepoProfile{}, &DeepAnalysis{ExposesHTTP: true}, "do-app-platform"},
+		{"sqlite is stateful", &RepoProfile{Ports: []int{8080}, DBType: "sqlite"}, nil, "do-droplet"},
+		{"compose volumes", &RepoProfile{Ports: []int{8080}, KeyFiles: map[string]string{"docker-compose.yml": "services:\n  app:\n    volumes:\n      - data:/data\n"}}, nil, "do-droplet"},
+		{"always-on worker", &RepoProfile{}, &DeepAnalysis{}, "do-droplet"},
+	}
+	for _, tt := range tests {
+		if got := doDefaultMethod(tt.profile, tt.deep); got != tt.want {
+			t.Errorf("%s: doDefaultMethod = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStrategyFromArchitect_DigitalOcean(t *testing.T) {
+	tests := []struct {
+		provider, method, wantMethod string
+	}{
+		{"digitalocean", "app-platform", "do-app-platform"},
+		{"DigitalOcean", "droplet", "do-droplet"},
+		{"do", "doks", "do-k8s"},
+		{"digitalocean", "do-droplet", "do-droplet"},
+	}
+	for _, tt := range tests {
+		strat := StrategyFromArchitect(&ArchitectDecision{Provider: tt.provider, Method: tt.method})
+		if strat.Provider != "digitalocean" || strat.Method != tt.wantMethod {
+			t.Errorf("StrategyFromArchitect(%q, %q) = %s/%s, want digitalocean/%s", tt.provider, tt.method, strat.Provider, strat.Method, tt.wantMethod)
+		}
+	}
+}
+
+func TestParseArchitectDecision_NormalizesDigitalOcean(t *testing.T) {
+	d, err := ParseArchitectDecision(`{"provider":"do","method":"app-platform"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Provider != "digitalocean" || d.Method != "do-app-platform" {
+		t.Errorf("got %s/%s, want digitalocean/do-app-platform", d.Provider, d.Method)
+	}
+}
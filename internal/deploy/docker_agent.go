@@ -20,12 +20,23 @@ type DockerAnalysis struct {
 	HasHealthcheck      bool     `json:"hasHealthcheck"`
 	HealthcheckHint     string   `json:"healthcheckHint,omitempty"`
 	VolumeMounts        []string `json:"volumeMounts,omitempty"`
-	EnvFiles            []string `json:"envFiles,omitempty"`
-	ReferencedEnvVars   []string `json:"referencedEnvVars,omitempty"`
-	HardRequiredEnvVars []string `json:"hardRequiredEnvVars,omitempty"`
-	BuildCommand        string   `json:"buildCommand,omitempty"`
-	RunCommand          string   `json:"runCommand,omitempty"`
-	Warnings            []string `json:"warnings,omitempty"`
+	// Services are the compose services with their own image and mounts.
+	Services            []ComposeService `json:"services,omitempty"`
+	EnvFiles            []string         `json:"envFiles,omitempty"`
+	ReferencedEnvVars   []string         `json:"referencedEnvVars,omitempty"`
+	HardRequiredEnvVars []string         `json:"hardRequiredEnvVars,omitempty"`
+	BuildCommand        string           `json:"buildCommand,omitempty"`
+	RunCommand          string           `json:"runCommand,omitempty"`
+	NeedsWebSockets     bool             `json:"needsWebSockets"`
+	WebSocketHints      []string         `json:"webSocketHints,omitempty"`
+	Warnings            []string         `json:"warnings,omitempty"`
+}
+
+// ComposeService is one entry under a compose file's services key.
+type ComposeService struct {
+	Name         string   `json:"name"`
+	Image        string   `json:"image,omitempty"`
+	VolumeMounts []string `json:"volumeMounts,omitempty"`
 }
 
 func AnalyzeDockerAgent(profile *RepoProfile) *DockerAnalysis {
@@ -55,6 +66,14 @@ func AnalyzeDockerAgent(profile *RepoProfile) *DockerAnalysis {
 		parseCompose(composeText, analysis)
 	}
 
+	for _, port := range append(append([]int{}, analysis.ExposedPorts...), analysis.PublishedPorts...) {
+		if webSocketPorts[port] {
+			analysis.WebSocketHints = append(analysis.WebSocketHints, fmt.Sprintf("port %d is a common WebSocket port", port))
+		}
+	}
+	analysis.WebSocketHints = uniqueStrings(analysis.WebSocketHints)
+	analysis.NeedsWebSockets = len(analysis.WebSocketHints) > 0
+
 	if analysis.PrimaryPort == 0 {
 		if len(analysis.PublishedPorts) > 0 {
 			analysis.PrimaryPort = analysis.PublishedPorts[0]
@@ -139,6 +158,9 @@ func (d *DockerAnalysis) FormatForPrompt() string {
 	if d.RunCommand != "" {
 		b.WriteString("- Recommended run command: " + d.RunCommand + "\n")
 	}
+	if d.NeedsWebSockets {
+		b.WriteString("- Long-lived connections: WebSockets detected (" + strings.Join(d.WebSocketHints, "; ") + "); use an ALB or a VM, not API Gateway HTTP APIs\n")
+	}
 	if d.HasHealthcheck {
 		b.WriteString("- Healthcheck: present")
 		if strings.TrimSpace(d.HealthcheckHint) != "" {
@@ -189,6 +211,11 @@ func parseDockerfile(content string, analysis *DockerAnalysis) {
 			analysis.HasHealthcheck = true
 			analysis.HealthcheckHint = trimmed
 		}
+		if dockerfileRunLineRe.MatchString(trimmed) {
+			if marker := webSocketMarker(trimmed); marker != "" {
+				analysis.WebSocketHints = append(analysis.WebSocketHints, "Dockerfile "+strings.ToUpper(strings.Fields(trimmed)[0])+" mentions "+marker)
+			}
+		}
 	}
 	if fromCount > 1 {
 		analysis.BuildUsesMultiStage = true
@@ -210,14 +237,29 @@ func parseCompose(content string, analysis *DockerAnalysis) {
 	envFileRe := regexp.MustCompile(`^\s*env_file\s*:\s*(.+)$`)
 	envRefRe := regexp.MustCompile(`\$\{\s*([A-Za-z_][A-Za-z0-9_]*)`) // ${VAR} or ${VAR:-...}
 	volumeHostRequiredVarRe := regexp.MustCompile(`^\$\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}$`)
+	topLevelKeyRe := regexp.MustCompile(`^([a-zA-Z0-9_-]+):`)
+	imageLineRe := regexp.MustCompile(`^\s+image\s*:\s*['"]?([^\s'"#]+)`)
 
+	// section is the top-level key being read; service indexes
+	// analysis.Services while inside one entry of services.
+	section, service := "", -1
 	for _, line := range strings.Split(content, "\n") {
 		trimmed := strings.TrimSpace(line)
 		if strings.HasPrefix(trimmed, "#") || trimmed == "" {
 			continue
 		}
+		if m := topLevelKeyRe.FindStringSubmatch(line); len(m) == 2 {
+			section, service = m[1], -1
+		}
 		if m := serviceLineRe.FindStringSubmatch(line); len(m) == 2 {
 			analysis.ComposeServices = append(analysis.ComposeServices, strings.TrimSpace(m[1]))
+			if section == "services" {
+				analysis.Services = append(analysis.Services, ComposeService{Name: strings.TrimSpace(m[1])})
+				service = len(analysis.Services) - 1
+			}
+		}
+		if m := imageLineRe.FindStringSubmatch(line); len(m) == 2 && service >= 0 {
+			analysis.Services[service].Image = m[1]
 		}
 		// Prefer published HOST ports for deployment/health checks.
 		if m := varHostPortDefaultRe.FindStringSubmatch(line); len(m) == 4 {
@@ -240,6 +282,9 @@ func parseCompose(content string, analysis *DockerAnalysis) {
 			}
 			mount := strings.TrimSpace(m[1])
 			analysis.VolumeMounts = append(analysis.VolumeMounts, mount)
+			if service >= 0 {
+				analysis.Services[service].VolumeMounts = append(analysis.Services[service].VolumeMounts, mount)
+			}
 			parts := strings.SplitN(mount, ":", 2)
 			if len(parts) == 2 {
 				host := strings.TrimSpace(parts[0])
@@ -263,7 +308,46 @@ func parseCompose(content string, analysis *DockerAnalysis) {
 				analysis.HealthcheckHint = "compose healthcheck"
 			}
 		}
+		if m := composeCommandRe.FindStringSubmatch(trimmed); len(m) == 2 {
+			if marker := webSocketMarker(trimmed); marker != "" {
+				analysis.WebSocketHints = append(analysis.WebSocketHints, "compose "+strings.ToLower(m[1])+" mentions "+marker)
+			}
+		}
+	}
+}
+
+// webSocketPorts are ports conventionally used by WebSocket servers and
+// gateways (OpenClaw gateway, python websockets, soketi/laravel-websockets,
+// RabbitMQ Web-STOMP). 9001 is left out: it is as often MinIO's console or
+// PHP-FPM as MQTT over WebSockets.
+var webSocketPorts = map[int]bool{
+	18789: true,
+	8765:  true,
+	6001:  true,
+	15674: true,
+}
+
+// webSocketMarkers are substrings of run commands that imply the service
+// holds long-lived WebSocket connections. Plain "gateway" is not one: API
+// gateways and payment gateways are ordinary HTTP.
+var webSocketMarkers = []string{"websocket", "socket.io", "ws://", "wss://", "--ws", "soketi"}
+
+// Only the command a container runs says how it serves traffic; image names
+// and healthcheck probes would match markers they merely mention.
+var (
+	dockerfileRunLineRe = regexp.MustCompile(`(?i)^(CMD|ENTRYPOINT)\b`)
+	composeCommandRe    = regexp.MustCompile(`(?i)^-?\s*(command|entrypoint)\s*:`)
+)
+
+// webSocketMarker returns the first WebSocket marker found in line, or "".
+func webSocketMarker(line string) string {
+	lower := strings.ToLower(line)
+	for _, marker := range webSocketMarkers {
+		if strings.Contains(lower, marker) {
+			return marker
+		}
 	}
+	return ""
 }
 
 func choosePrimaryService(services []string) string {
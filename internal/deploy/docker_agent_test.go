@@ -0,0 +1,77 @@
+package deploy
+
+import "testing"
+
+func TestAnalyzeDockerAgent_NeedsWebSockets(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *RepoProfile
+		want    bool
+	}{
+		{
+			name: "openclaw gateway port",
+			profile: &RepoProfile{HasCompose: true, KeyFiles: map[string]string{
+				"docker-compose.yml": "services:\n  openclaw-gateway:\n    image: openclaw:local\n    command: [\"node\", \"dist/index.js\", \"gateway\"]\n    ports:\n      - \"18789:18789\"\n",
+			}},
+			want: true,
+		},
+		{
+			name: "compose websocket command",
+			profile: &RepoProfile{HasCompose: true, KeyFiles: map[string]string{
+				"docker-compose.yml": "services:\n  realtime:\n    build: .\n    command: node socket.io-server.js\n",
+			}},
+			want: true,
+		},
+		{
+			name: "api gateway command",
+			profile: &RepoProfile{HasCompose: true, KeyFiles: map[string]string{
+				"docker-compose.yml": "services:\n  api-gateway:\n    image: kong/kong-gateway:3.6\n    command: kong start --gateway\n",
+			}},
+			want: false,
+		},
+		{
+			name: "markers outside the command",
+			profile: &RepoProfile{HasCompose: true, KeyFiles: map[string]string{
+				"docker-compose.yml": "services:\n  web:\n    image: acme/websocket-docs:1\n    healthcheck:\n      test: [\"CMD\", \"curl\", \"-f\", \"ws://localhost:3000\"]\n  minio:\n    image: minio/minio\n    ports:\n      - \"9001:9001\"\n",
+			}},
+			want: false,
+		},
+		{
+			name: "dockerfile websocket cmd",
+			profile: &RepoProfile{HasDocker: true, KeyFiles: map[string]string{
+				"Dockerfile": "FROM python:3.12\nEXPOSE 8000\nCMD [\"uvicorn\", \"app:app\", \"--ws\", \"websockets\"]\n",
+			}},
+			want: true,
+		},
+		{
+			name: "common websocket port",
+			profile: &RepoProfile{HasDocker: true, KeyFiles: map[string]string{
+				"Dockerfile": "FROM node:20\nEXPOSE 6001\nCMD [\"node\", \"server.js\"]\n",
+			}},
+			want: true,
+		},
+		{
+			name: "plain http api",
+			profile: &RepoProfile{HasDocker: true, KeyFiles: map[string]string{
+				"Dockerfile": "FROM node:20\nEXPOSE 3000\nHEALTHCHECK CMD curl -f http://localhost:3000/health\nCMD [\"node\", \"server.js\"]\n",
+			}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		got := AnalyzeDockerAgent(tt.profile)
+		if got.NeedsWebSockets != tt.want {
+			t.Errorf("%s: NeedsWebSockets = %t (hints %v), want %t", tt.name, got.NeedsWebSockets, got.WebSocketHints, tt.want)
+		}
+	}
+}
+
+func TestShouldUseAPIGateway_WebSockets(t *testing.T) {
+	p := &RepoProfile{Framework: "fastapi", KeyFiles: map[string]string{}}
+	if !shouldUseAPIGateway(p, &DeepAnalysis{}, &DockerAnalysis{}) {
+		t.Fatal("expected API Gateway for a pure API without websockets")
+	}
+	if shouldUseAPIGateway(p, &DeepAnalysis{}, &DockerAnalysis{NeedsWebSockets: true}) {
+		t.Fatal("expected ALB when the docker analysis detected websockets")
+	}
+}
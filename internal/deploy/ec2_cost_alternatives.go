@@ -0,0 +1,163 @@
+package deploy
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/bgdnvk/clanker/internal/maker"
+)
+
+// spotDiscount is the typical Spot saving over on-demand for the small
+// general-purpose families. The real price varies by AZ and over time.
+const spotDiscount = 0.6
+
+// gravitonFamilies maps x86 instance families to their Graviton (arm64)
+// equivalent of the same size.
+var gravitonFamilies = map[string]string{
+	"t3": "t4g",
+	"m5": "m6g",
+	"c5": "c6g",
+	"r5": "r6g",
+}
+
+// arm64IncompatibleDeps are native deps without working linux/arm64 builds.
+var arm64IncompatibleDeps = map[string]string{
+	"puppeteer": "puppeteer downloads a Chrome build that does not exist for linux/arm64",
+	"grpc":      "the legacy grpc package has no arm64 prebuilds; @grpc/grpc-js does not have this problem",
+}
+
+// ApplyEC2CostAlternatives adds the Graviton and Spot shapes of an EC2 pick
+// to arch.Alternatives with their cost delta. Graviton is offered when
+// nothing pins the image to amd64; Spot only for apps with no local state,
+// since a reclaimed instance loses its disk. It reports whether the
+// alternatives changed.
+func ApplyEC2CostAlternatives(arch *ArchitectDecision, p *RepoProfile, docker *DockerAnalysis, stateful StatefulnessReport) bool {
+	if arch == nil || arch.Method != "ec2" {
+		return false
+	}
+	instanceType := strings.ToLower(strings.TrimSpace(arch.CpuMemory))
+	if instanceType == "" {
+		instanceType = "t3.small"
+	}
+	base, ok := maker.InstanceHourlyPrice("aws", instanceType)
+	if !ok {
+		return false
+	}
+
+	// The architect may suggest these shapes too; drop the ones the gates
+	// below rule out.
+	armOK := len(arm64Blockers(p, docker)) == 0
+	changed := false
+	kept := arch.Alternatives[:0]
+	for _, alt := range arch.Alternatives {
+		if (alt.Spot && stateful.Stateful) || (!armOK && isGravitonType(alt.InstanceType)) {
+			changed = true
+			continue
+		}
+		kept = append(kept, alt)
+	}
+	arch.Alternatives = kept
+
+	cheapest := instanceType
+	var added []ArchitectAlternative
+	if armOK {
+		if graviton := gravitonEquivalent(instanceType); graviton != "" {
+			if hourly, ok := maker.InstanceHourlyPrice("aws", graviton); ok && hourly < base {
+				cheapest = graviton
+				added = append(added, ArchitectAlternative{
+					Method:       "ec2",
+					InstanceType: graviton,
+					WhyNot:       "Graviton (arm64): the image must be built for linux/arm64 (docker buildx --platform linux/arm64) and the instance needs an arm64 AMI",
+					EstMonthly:   formatInstanceMonthly(hourly),
+					CostDelta:    formatCostDelta(hourly, base, instanceType),
+				})
+			}
+		}
+	}
+	if !stateful.Stateful {
+		hourly, _ := maker.InstanceHourlyPrice("aws", cheapest)
+		hourly *= 1 - spotDiscount
+		added = append(added, ArchitectAlternative{
+			Method:       "ec2",
+			InstanceType: cheapest,
+			Spot:         true,
+			WhyNot:       "Spot: AWS can reclaim the instance with two minutes' notice; fine for a stateless app behind an Auto Scaling group that replaces it, and the price varies",
+			EstMonthly:   "~" + formatInstanceMonthly(hourly),
+			CostDelta:    "~" + formatCostDelta(hourly, base, instanceType),
+		})
+	}
+
+	for _, alt := range added {
+		if !hasAlternative(arch.Alternatives, alt) {
+			arch.Alternatives = append(arch.Alternatives, alt)
+			changed = true
+		}
+	}
+	return changed
+}
+
+func isGravitonType(instanceType string) bool {
+	family, _, _ := strings.Cut(strings.ToLower(instanceType), ".")
+	for _, graviton := range gravitonFamilies {
+		if family == graviton {
+			return true
+		}
+	}
+	return false
+}
+
+// arm64Blockers lists why the app may not run on arm64: a Dockerfile stage
+// pinned to amd64 or a native dep without arm64 builds.
+func arm64Blockers(p *RepoProfile, docker *DockerAnalysis) []string {
+	var blockers []string
+	if docker != nil {
+		for _, pin := range docker.PlatformPins {
+			if pin = strings.ToLower(pin); strings.Contains(pin, "amd64") || strings.Contains(pin, "x86_64") {
+				blockers = append(blockers, "Dockerfile pins --platform="+pin)
+			}
+		}
+	}
+	if p != nil {
+		for _, dep := range p.NativeDeps {
+			if reason, ok := arm64IncompatibleDeps[dep]; ok {
+				blockers = append(blockers, reason)
+			}
+		}
+	}
+	return blockers
+}
+
+// gravitonEquivalent returns the arm64 type of the same size, e.g.
+// t3.small -> t4g.small, or "" when there is none.
+func gravitonEquivalent(instanceType string) string {
+	family, size, ok := strings.Cut(instanceType, ".")
+	if !ok {
+		return ""
+	}
+	if graviton, ok := gravitonFamilies[family]; ok {
+		return graviton + "." + size
+	}
+	return ""
+}
+
+func hasAlternative(alts []ArchitectAlternative, alt ArchitectAlternative) bool {
+	for _, existing := range alts {
+		if existing.Method == alt.Method && strings.EqualFold(existing.InstanceType, alt.InstanceType) && existing.Spot == alt.Spot {
+			return true
+		}
+	}
+	return false
+}
+
+func formatInstanceMonthly(hourly float64) string {
+	return "$" + formatMonthlyUSD(hourly*maker.HoursPerMonth) + "/mo"
+}
+
+// formatCostDelta renders the monthly instance cost difference from the
+// on-demand base type.
+func formatCostDelta(hourly, base float64, baseType string) string {
+	delta := (base - hourly) * maker.HoursPerMonth
+	percent := math.Round((hourly - base) / base * 100)
+	return fmt.Sprintf("-$%s/mo (%.0f%%) vs %s on-demand", formatMonthlyUSD(delta), percent, baseType)
+}
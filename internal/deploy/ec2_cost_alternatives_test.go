@@ -0,0 +1,55 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyEC2CostAlternatives(t *testing.T) {
+	arch := &ArchitectDecision{Method: "ec2", CpuMemory: "t3.small"}
+	if !ApplyEC2CostAlternatives(arch, &RepoProfile{Language: "node"}, &DockerAnalysis{}, StatefulnessReport{}) {
+		t.Fatal("expected alternatives for a stateless t3.small")
+	}
+	if len(arch.Alternatives) != 2 {
+		t.Fatalf("expected Graviton and Spot alternatives, got %+v", arch.Alternatives)
+	}
+	graviton, spot := arch.Alternatives[0], arch.Alternatives[1]
+	if graviton.InstanceType != "t4g.small" || graviton.Spot || graviton.CostDelta != "-$2.92/mo (-19%) vs t3.small on-demand" {
+		t.Errorf("unexpected Graviton alternative: %+v", graviton)
+	}
+	if spot.InstanceType != "t4g.small" || !spot.Spot || !strings.Contains(spot.CostDelta, "(-68%) vs t3.small on-demand") {
+		t.Errorf("unexpected Spot alternative: %+v", spot)
+	}
+	if ApplyEC2CostAlternatives(arch, &RepoProfile{}, &DockerAnalysis{}, StatefulnessReport{}) {
+		t.Error("a second pass should not add duplicates")
+	}
+}
+
+func TestApplyEC2CostAlternativesGates(t *testing.T) {
+	// An amd64 pin rules out Graviton, local state rules out Spot, and the
+	// architect's own suggestions are held to the same gates.
+	arch := &ArchitectDecision{Method: "ec2", CpuMemory: "m5.large", Alternatives: []ArchitectAlternative{
+		{Method: "ecs-fargate", WhyNot: "needs a volume"},
+		{Method: "ec2", InstanceType: "m6g.large"},
+		{Method: "ec2", InstanceType: "m5.large", Spot: true},
+	}}
+	changed := ApplyEC2CostAlternatives(arch,
+		&RepoProfile{NativeDeps: []string{"sharp"}},
+		&DockerAnalysis{PlatformPins: []string{"linux/amd64"}},
+		StatefulnessReport{Stateful: true, LocalDBFiles: []string{"data.sqlite"}})
+	if !changed || len(arch.Alternatives) != 1 || arch.Alternatives[0].Method != "ecs-fargate" {
+		t.Errorf("expected only the fargate alternative kept, got %+v", arch.Alternatives)
+	}
+
+	if got := arm64Blockers(&RepoProfile{NativeDeps: []string{"puppeteer", "bcrypt"}}, nil); len(got) != 1 || !strings.Contains(got[0], "puppeteer") {
+		t.Errorf("arm64Blockers() = %v", got)
+	}
+	for _, arch := range []*ArchitectDecision{
+		{Method: "ecs-fargate", CpuMemory: "256/512"},
+		{Method: "ec2", CpuMemory: "x9.huge"},
+	} {
+		if ApplyEC2CostAlternatives(arch, &RepoProfile{}, nil, StatefulnessReport{}) {
+			t.Errorf("expected no alternatives for %+v", arch)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bgdnvk/clanker/internal/maker"
+)
+
+// UnresolvedPlaceholder is an env var the plan sets to a placeholder such as
+// <YOUR_API_KEY> or CHANGEME instead of a real value.
+type UnresolvedPlaceholder struct {
+	EnvVar      string `json:"envVar"`
+	Placeholder string `json:"placeholder"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+	// UseSecretsManager marks credentials, which belong in Secrets Manager
+	// and are read at runtime rather than set in the plan.
+	UseSecretsManager bool `json:"useSecretsManager"`
+}
+
+// UnmarshalJSON also accepts a bare string, the shape older validations
+// used.
+func (u *UnresolvedPlaceholder) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*u = UnresolvedPlaceholder{Placeholder: s}
+		return nil
+	}
+	type plain UnresolvedPlaceholder
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+func (u UnresolvedPlaceholder) String() string {
+	s := u.EnvVar + "=" + u.Placeholder
+	if u.Description != "" {
+		s += " (" + u.Description + ")"
+	}
+	if u.UseSecretsManager {
+		s += "; store it in Secrets Manager and read it at runtime"
+	}
+	return s
+}
+
+var (
+	// envPlaceholderRe matches values written for a human to replace.
+	envPlaceholderRe = regexp.MustCompile(`(?i)^(?:<[^>]*(?:your|insert|replace|change|here|placeholder|todo)[^>]*>|change[-_]?me|replace[-_]?me|your[-_].+|todo|tbd|x{3,}|placeholder|\.\.\.)$`)
+	// envTokenRe matches a <TOKEN> plan binding.
+	envTokenRe = regexp.MustCompile(`^<([A-Z][A-Z0-9_]*)>$`)
+)
+
+// findEnvPlaceholders returns the env vars the plan leaves as placeholders,
+// sorted by name. A <TOKEN> value counts only when it names an app env var
+// (the var itself or one the analysis found) that the user did not provide
+// and no command produces; other tokens are ordinary plan bindings.
+func findEnvPlaceholders(plan *maker.Plan, deep *DeepAnalysis, docker *DockerAnalysis, runtimeEnvKeys []string) []UnresolvedPlaceholder {
+	if plan == nil {
+		return nil
+	}
+	resolved := make(map[string]bool)
+	for _, kv := range runtimeEnvKeys {
+		key, _, _ := strings.Cut(kv, "=")
+		resolved[strings.ToUpper(strings.TrimSpace(key))] = true
+	}
+	for _, cmd := range plan.Commands {
+		for key := range cmd.Produces {
+			resolved[strings.ToUpper(strings.TrimSpace(key))] = true
+		}
+	}
+	var required, optional []EnvVarSpec
+	if deep != nil {
+		required, optional = deep.RequiredEnvVars, deep.OptionalEnvVars
+	}
+	hardRequired := make(map[string]bool)
+	if docker != nil {
+		for _, name := range docker.HardRequiredEnvVars {
+			hardRequired[name] = true
+		}
+	}
+
+	found := make(map[string]UnresolvedPlaceholder)
+	check := func(name, value string) {
+		value = strings.TrimSpace(value)
+		if _, dup := found[name]; dup || value == "" {
+			return
+		}
+		spec := findEnvVarSpec(required, name)
+		isRequired := (spec != nil && spec.Required) || hardRequired[name]
+		if spec == nil {
+			spec = findEnvVarSpec(optional, name)
+		}
+		if !envPlaceholderRe.MatchString(value) {
+			m := envTokenRe.FindStringSubmatch(value)
+			if m == nil || resolved[m[1]] || isProviderCredentialToken(m[1]) {
+				return
+			}
+			if m[1] != name && findEnvVarSpec(required, m[1]) == nil && findEnvVarSpec(optional, m[1]) == nil {
+				return
+			}
+		}
+		u := UnresolvedPlaceholder{
+			EnvVar:            name,
+			Placeholder:       value,
+			Required:          isRequired,
+			UseSecretsManager: isCredentialEnvName(name),
+		}
+		if spec != nil {
+			u.Description = spec.Description
+		}
+		found[name] = u
+	}
+	// Scan the decoded args: the plan JSON escapes < and > as \u003c.
+	var texts []string
+	for _, cmd := range plan.Commands {
+		texts = append(texts, cmd.Args...)
+		if script := extractEC2UserDataScript(cmd.Args); script != "" {
+			texts = append(texts, script)
+		}
+	}
+	for _, text := range texts {
+		for _, m := range envAssignRe.FindAllStringSubmatch(text, -1) {
+			check(m[1], m[2])
+		}
+		for _, m := range envNameValueRe.FindAllStringSubmatch(text, -1) {
+			check(m[1], m[2])
+		}
+	}
+
+	out := make([]UnresolvedPlaceholder, 0, len(found))
+	for _, u := range found {
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].EnvVar < out[j].EnvVar })
+	return out
+}
+
+// isCredentialEnvName reports whether an env var name holds a credential.
+func isCredentialEnvName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range []string{"TOKEN", "KEY", "SECRET", "PASSWORD", "CREDENTIAL", "PRIVATE", "DSN"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEnvPlaceholderChecks records placeholders on out. Required vars left
+// as placeholders fail validation, since the app would crash on start;
+// the rest are warnings.
+func applyEnvPlaceholderChecks(out *deterministicValidation, placeholders []UnresolvedPlaceholder) {
+	out.Placeholders = placeholders
+	for _, u := range placeholders {
+		where := fmt.Sprintf("set %s to the real value from the user's env config", u.EnvVar)
+		if u.UseSecretsManager {
+			where = fmt.Sprintf("create a Secrets Manager secret for %s and have the app read it at runtime (ECS secrets valueFrom, Lambda/user-data fetch at startup)", u.EnvVar)
+		}
+		if !u.Required {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("env var %s is set to placeholder %s; %s or drop it", u.EnvVar, u.Placeholder, where))
+			continue
+		}
+		issue := fmt.Sprintf("[HARD] required env var %s is left as placeholder %s", u.EnvVar, u.Placeholder)
+		if u.Description != "" {
+			issue += " (" + u.Description + ")"
+		}
+		out.Issues = append(out.Issues, issue+"; the app will fail on startup without it")
+		out.Fixes = append(out.Fixes, strings.ToUpper(where[:1])+where[1:])
+	}
+}
@@ -0,0 +1,81 @@
+package deploy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bgdnvk/clanker/internal/maker"
+)
+
+func TestDeterministicValidatePlanFlagsEnvPlaceholders(t *testing.T) {
+	plan := &maker.Plan{
+		Version:  1,
+		Provider: "aws",
+		Commands: []maker.Command{
+			{Args: []string{"lambda", "create-function", "--function-name", "api",
+				"--environment", "Variables={STRIPE_API_KEY=<YOUR_STRIPE_KEY>,LOG_LEVEL=CHANGEME,DATABASE_URL=<DATABASE_URL>,SENTRY_DSN=<SENTRY_DSN>,PORT=3000,TABLE=<TABLE_NAME>}"}},
+		},
+	}
+	raw, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deep := &DeepAnalysis{
+		RequiredEnvVars: []EnvVarSpec{
+			{Name: "STRIPE_API_KEY", Description: "Stripe secret key", Required: true},
+			{Name: "DATABASE_URL", Description: "Postgres connection string", Required: true},
+		},
+		OptionalEnvVars: []EnvVarSpec{{Name: "LOG_LEVEL", Description: "log verbosity"}},
+	}
+
+	v := DeterministicValidatePlan(string(raw), nil, deep, nil, []string{"DATABASE_URL"})
+	if v.IsValid {
+		t.Fatal("expected a required placeholder to fail validation")
+	}
+	got := make(map[string]UnresolvedPlaceholder)
+	for _, u := range v.UnresolvedPlaceholders {
+		got[u.EnvVar] = u
+	}
+	if len(got) != 3 {
+		t.Fatalf("UnresolvedPlaceholders = %+v, want STRIPE_API_KEY, LOG_LEVEL and SENTRY_DSN", v.UnresolvedPlaceholders)
+	}
+	if u := got["STRIPE_API_KEY"]; !u.Required || !u.UseSecretsManager || u.Description != "Stripe secret key" || u.Placeholder != "<YOUR_STRIPE_KEY>" {
+		t.Errorf("STRIPE_API_KEY = %+v", u)
+	}
+	if u := got["LOG_LEVEL"]; u.Required || u.UseSecretsManager || u.Description != "log verbosity" {
+		t.Errorf("LOG_LEVEL = %+v", u)
+	}
+	if u := got["SENTRY_DSN"]; u.Required || !u.UseSecretsManager {
+		t.Errorf("SENTRY_DSN = %+v", u)
+	}
+
+	if !containsSubstring(v.Issues, "required env var STRIPE_API_KEY is left as placeholder <YOUR_STRIPE_KEY> (Stripe secret key)") {
+		t.Errorf("issues = %v", v.Issues)
+	}
+	if containsSubstring(v.Issues, "LOG_LEVEL") || !containsSubstring(v.Warnings, "env var LOG_LEVEL is set to placeholder CHANGEME") {
+		t.Errorf("expected LOG_LEVEL as a warning only: issues=%v warnings=%v", v.Issues, v.Warnings)
+	}
+	if !containsSubstring(v.Fixes, "Create a Secrets Manager secret for STRIPE_API_KEY") {
+		t.Errorf("fixes = %v", v.Fixes)
+	}
+}
+
+func TestUnresolvedPlaceholderUnmarshalString(t *testing.T) {
+	var v PlanValidation
+	if err := json.Unmarshal([]byte(`{"isValid": false, "unresolvedPlaceholders": ["<API_KEY>", {"envVar": "DB_URL", "placeholder": "CHANGEME", "required": true}]}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.UnresolvedPlaceholders) != 2 || v.UnresolvedPlaceholders[0].Placeholder != "<API_KEY>" || !v.UnresolvedPlaceholders[1].Required {
+		t.Errorf("UnresolvedPlaceholders = %+v", v.UnresolvedPlaceholders)
+	}
+}
+
+func containsSubstring(values []string, want string) bool {
+	for _, v := range values {
+		if strings.Contains(v, want) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // FileRequest is what the LLM asks for during exploration
 type FileRequest struct {
 	Files    []string `json:"files"`    // files it wants to read
+	ListDir  []string `json:"list_dir"` // directories whose immediate children it wants to see
 	Reason   string   `json:"reason"`   // why it needs them
 	Done     bool     `json:"done"`     // true = has enough context
 	Analysis string   `json:"analysis"` // partial analysis so far (when done=true)
@@ -19,22 +21,30 @@ type FileRequest struct {
 
 // ExplorationResult is the output of the agentic exploration
 type ExplorationResult struct {
-	FilesRead map[string]string // all files read during exploration
-	Rounds    int               // how many exploration rounds
-	Analysis  string            // LLM's analysis after reading everything
+	FilesRead  map[string]string // all files read during exploration
+	DirsListed map[string]string // directory → listing of its immediate children
+	Rounds     int               // how many exploration rounds
+	Analysis   string            // LLM's analysis after reading everything
 }
 
 const (
-	maxExplorationRounds = 3    // max LLM→read→LLM loops
+	maxExplorationRounds = 3    // default max LLM→read→LLM loops (intelligence.max_explore_rounds)
 	maxFileSize          = 6144 // cap per file
 	maxTotalFiles        = 20   // don't read the entire repo
+	maxTotalDirs         = 20   // directory listings per exploration
+	maxDirEntries        = 200  // children shown per listing
 )
 
 // ExploreRepo runs agentic file exploration:
-// LLM sees the tree → requests files → we read them → LLM requests more → done
-func ExploreRepo(ctx context.Context, profile *RepoProfile, ask AskFunc, clean CleanFunc, logf func(string, ...any)) (*ExplorationResult, error) {
+// LLM sees the tree → requests files or directory listings → we read them →
+// LLM requests more → done. maxRounds caps the loop; 0 uses the default.
+func ExploreRepo(ctx context.Context, profile *RepoProfile, maxRounds int, ask AskFunc, clean CleanFunc, logf func(string, ...any)) (*ExplorationResult, error) {
 	result := &ExplorationResult{
-		FilesRead: make(map[string]string),
+		FilesRead:  make(map[string]string),
+		DirsListed: make(map[string]string),
+	}
+	if maxRounds <= 0 {
+		maxRounds = maxExplorationRounds
 	}
 
 	// seed with key files already read by the static analyzer
@@ -43,10 +53,10 @@ func ExploreRepo(ctx context.Context, profile *RepoProfile, ask AskFunc, clean C
 	}
 
 	// exploration loop
-	for round := 0; round < maxExplorationRounds; round++ {
+	for round := 0; round < maxRounds; round++ {
 		result.Rounds = round + 1
 
-		prompt := buildExplorationPrompt(profile, result.FilesRead, round)
+		prompt := buildExplorationPrompt(profile, result, round)
 		resp, err := ask(ctx, prompt)
 		if err != nil {
 			return result, fmt.Errorf("exploration round %d failed: %w", round, err)
@@ -83,10 +93,25 @@ func ExploreRepo(ctx context.Context, profile *RepoProfile, ask AskFunc, clean C
 			}
 		}
 
-		logf("[explore] round %d: requested %d files, read %d new (%s)", round, len(req.Files), newFiles, req.Reason)
+		// list requested directories
+		newDirs := 0
+		for _, d := range req.ListDir {
+			if len(result.DirsListed) >= maxTotalDirs {
+				break
+			}
+			if _, already := result.DirsListed[d]; already {
+				continue
+			}
+			if listing, ok := listRepoDir(profile.ClonePath, d); ok {
+				result.DirsListed[d] = listing
+				newDirs++
+			}
+		}
 
-		// no new files = nothing left to explore
-		if newFiles == 0 {
+		logf("[explore] round %d: requested %d files, read %d new; listed %d of %d dirs (%s)", round, len(req.Files), newFiles, newDirs, len(req.ListDir), req.Reason)
+
+		// nothing new = nothing left to explore
+		if newFiles == 0 && newDirs == 0 {
 			break
 		}
 	}
@@ -94,7 +119,8 @@ func ExploreRepo(ctx context.Context, profile *RepoProfile, ask AskFunc, clean C
 	return result, nil
 }
 
-func buildExplorationPrompt(p *RepoProfile, filesRead map[string]string, round int) string {
+func buildExplorationPrompt(p *RepoProfile, explored *ExplorationResult, round int) string {
+	filesRead := explored.FilesRead
 	var b strings.Builder
 
 	b.WriteString("You are analyzing a repository to understand how to build and deploy it.\n\n")
@@ -104,6 +130,20 @@ func buildExplorationPrompt(p *RepoProfile, filesRead map[string]string, round i
 	b.WriteString(p.FileTree)
 	b.WriteString("```\n\n")
 
+	// directories listed on request; the tree above stops at depth 3
+	if len(explored.DirsListed) > 0 {
+		b.WriteString("## Directories Listed\n")
+		dirs := make([]string, 0, len(explored.DirsListed))
+		for dir := range explored.DirsListed {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			b.WriteString(fmt.Sprintf("\n### %s\n```\n%s```\n", dir, explored.DirsListed[dir]))
+		}
+		b.WriteString("\n")
+	}
+
 	// files already read
 	if len(filesRead) > 0 {
 		b.WriteString("## Files Already Read\n")
@@ -118,6 +158,9 @@ func buildExplorationPrompt(p *RepoProfile, filesRead map[string]string, round i
 	if p.IsMonorepo {
 		b.WriteString("- Monorepo: yes\n")
 	}
+	if p.SubPath != "" {
+		b.WriteString(fmt.Sprintf("- Deploying workspace: %s (request files inside it; root files only for build context)\n", p.SubPath))
+	}
 	if p.HasDocker {
 		b.WriteString("- Has Dockerfile\n")
 	}
@@ -134,11 +177,15 @@ Look at the file tree and the files already read. Decide what OTHER files you ne
 3. What services/components it has
 4. What environment variables and external dependencies it needs
 
-Request the most important files you haven't seen yet.
+Request the most important files you haven't seen yet. If you don't know
+where something lives (e.g. whether the entrypoint is under src/, app/ or
+cmd/), list directories with "list_dir" to see their immediate children and
+request files from them next round. Paths are relative to the repo root.
 
 ## Response Format (JSON only, no markdown fences)
 {
   "files": ["src/gateway/index.ts", "apps/api/Dockerfile", "scripts/build.sh"],
+  "list_dir": ["apps/worker"],
   "reason": "Need to understand the gateway entry point and API build process",
   "done": false,
   "analysis": ""
@@ -147,6 +194,7 @@ Request the most important files you haven't seen yet.
 If you already have enough context from the files shown, set "done": true and provide your analysis:
 {
   "files": [],
+  "list_dir": [],
   "reason": "",
   "done": true,
   "analysis": "This is a pnpm monorepo with a WebSocket gateway on port 18789..."
@@ -154,12 +202,13 @@ If you already have enough context from the files shown, set "done": true and pr
 	} else {
 		b.WriteString(fmt.Sprintf(`
 ## Round %d — Request More Files or Finish
-Based on the files you've read so far, do you need to read more files?
+Based on the files you've read so far, do you need to read more files or list more directories?
 If yes, request them. If no, set done=true and provide your full analysis of how to build and deploy this app.
 
 ## Response Format (JSON only, no markdown fences)
 {
   "files": ["some/other/file.ts"],
+  "list_dir": [],
   "reason": "Need to check the worker process entry point",
   "done": false,
   "analysis": ""
@@ -183,15 +232,69 @@ func parseFileRequest(raw string) (*FileRequest, error) {
 	return &req, nil
 }
 
+// resolveRepoPath joins relPath onto the cloned repo, rejecting absolute
+// paths, ".." escapes and symlinks that resolve outside the repo.
+func resolveRepoPath(clonePath, relPath string) (string, bool) {
+	cleanPath := filepath.Clean(strings.TrimSpace(relPath))
+	if filepath.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	root, err := filepath.EvalSymlinks(clonePath)
+	if err != nil {
+		return "", false
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(root, cleanPath))
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return resolved, true
+}
+
+// listRepoDir lists the immediate children of a directory in the cloned
+// repo, directories suffixed with "/", capped at maxDirEntries.
+func listRepoDir(clonePath, relPath string) (string, bool) {
+	fp, ok := resolveRepoPath(clonePath, relPath)
+	if !ok {
+		return "", false
+	}
+	entries, err := os.ReadDir(fp)
+	if err != nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	shown := 0
+	for _, e := range entries {
+		if e.Name() == ".git" {
+			continue
+		}
+		if shown == maxDirEntries {
+			b.WriteString(fmt.Sprintf("... (%d more)\n", len(entries)-shown))
+			break
+		}
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+		b.WriteString(name + "\n")
+		shown++
+	}
+	if shown == 0 {
+		b.WriteString("(empty)\n")
+	}
+	return b.String(), true
+}
+
 // readRepoFile reads a file from the cloned repo, capped at maxFileSize
 func readRepoFile(clonePath, relPath string) string {
-	// sanitize — prevent path traversal
-	cleanPath := filepath.Clean(relPath)
-	if strings.HasPrefix(cleanPath, "..") || strings.HasPrefix(cleanPath, "/") {
+	fp, ok := resolveRepoPath(clonePath, relPath)
+	if !ok {
 		return ""
 	}
-
-	fp := filepath.Join(clonePath, cleanPath)
 	data, err := os.ReadFile(fp)
 	if err != nil {
 		return ""
@@ -0,0 +1,93 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExploreRepo_ListDirThenReadFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "cmd", "server"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmd", "server", "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := []string{
+		`{"list_dir": ["cmd", "../"], "reason": "find the entrypoint"}`,
+		`{"files": ["cmd/server/main.go"], "reason": "read the entrypoint"}`,
+		`{"done": true, "analysis": "Go server in cmd/server"}`,
+	}
+	var prompts []string
+	ask := func(_ context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		resp := responses[0]
+		responses = responses[1:]
+		return resp, nil
+	}
+	clean := func(s string) string { return s }
+
+	result, err := ExploreRepo(context.Background(), &RepoProfile{ClonePath: dir}, 0, ask, clean, t.Logf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.DirsListed["cmd"]; got != "server/\n" {
+		t.Errorf("cmd listing = %q, want server/", got)
+	}
+	if _, ok := result.DirsListed["../"]; ok {
+		t.Error("listing outside the repo should be refused")
+	}
+	if result.FilesRead["cmd/server/main.go"] != "package main\n" {
+		t.Errorf("expected the entrypoint to be read, got %v", result.FilesRead)
+	}
+	if result.Analysis != "Go server in cmd/server" || result.Rounds != 3 {
+		t.Errorf("unexpected result: rounds=%d analysis=%q", result.Rounds, result.Analysis)
+	}
+	if len(prompts) < 2 || !strings.Contains(prompts[1], "### cmd\n```\nserver/\n```") {
+		t.Errorf("second round prompt should include the listing")
+	}
+}
+
+func TestExploreRepo_MaxRounds(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	calls := 0
+	ask := func(context.Context, string) (string, error) {
+		calls++
+		if calls == 1 {
+			return `{"list_dir": ["a"]}`, nil
+		}
+		return `{"list_dir": ["b"]}`, nil
+	}
+	result, err := ExploreRepo(context.Background(), &RepoProfile{ClonePath: dir}, 1, ask, func(s string) string { return s }, t.Logf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || result.Rounds != 1 {
+		t.Errorf("expected exploration to stop after 1 round, got %d calls", calls)
+	}
+}
+
+func TestResolveRepoPath_RejectsEscapes(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	for _, rel := range []string{"..", "../etc/passwd", "/etc/passwd", "link", "link/x"} {
+		if _, ok := resolveRepoPath(dir, rel); ok {
+			t.Errorf("resolveRepoPath(%q) should be refused", rel)
+		}
+	}
+	if _, ok := resolveRepoPath(dir, "."); !ok {
+		t.Error("the repo root should be listable")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"sort"
 	"strings"
 )
 
@@ -22,17 +23,22 @@ type InfraSnapshot struct {
 	ALBs                       []string `json:"albs,omitempty"`                       // existing ALBs
 	RDSInstances               []string `json:"rdsInstances,omitempty"`               // existing RDS instances
 	SecurityGroups             []SGInfo `json:"securityGroups,omitempty"`             // existing SGs in default VPC
+	SubnetAZCount              int      `json:"subnetAzCount"`                        // AZs with at least one default VPC subnet
 	LatestAMI                  string   `json:"latestAmi,omitempty"`                  // latest Amazon Linux 2023 AMI ID
 	Summary                    string   `json:"summary"`
 }
 
 // VPCInfo is the default VPC + subnets info
 type VPCInfo struct {
-	VPCID     string   `json:"vpcId"`
-	Subnets   []string `json:"subnets"` // subnet IDs
-	IsDefault bool     `json:"isDefault"`
+	VPCID             string   `json:"vpcId"`
+	Subnets           []string `json:"subnets"`                     // subnet IDs
+	AvailabilityZones []string `json:"availabilityZones,omitempty"` // distinct AZs of those subnets
+	IsDefault         bool     `json:"isDefault"`
 }
 
+// minLoadBalancerAZs is how many AZs an ALB (and an EKS cluster) needs subnets in.
+const minLoadBalancerAZs = 2
+
 // SGInfo is a security group summary
 type SGInfo struct {
 	ID   string `json:"id"`
@@ -57,10 +63,11 @@ func ScanInfra(ctx context.Context, profile, region string, logf func(string, ..
 		snap.VPC = &VPCInfo{VPCID: vpcID, IsDefault: true}
 
 		// subnets in default VPC
-		if subOut := awsCLI(ctx, profile, region, "ec2", "describe-subnets", "--filters", fmt.Sprintf("Name=vpc-id,Values=%s", vpcID), "--query", "Subnets[].SubnetId", "--output", "json"); subOut != "" {
-			var subnets []string
-			if err := json.Unmarshal([]byte(subOut), &subnets); err == nil {
+		if subOut := awsCLI(ctx, profile, region, "ec2", "describe-subnets", "--filters", fmt.Sprintf("Name=vpc-id,Values=%s", vpcID), "--query", "Subnets[].[SubnetId,AvailabilityZone]", "--output", "json"); subOut != "" {
+			if subnets, azs, ok := parseSubnetAZs(subOut); ok {
 				snap.VPC.Subnets = subnets
+				snap.VPC.AvailabilityZones = azs
+				snap.SubnetAZCount = len(azs)
 			}
 		}
 
@@ -154,6 +161,74 @@ func ScanInfra(ctx context.Context, profile, region string, logf func(string, ..
 	return snap
 }
 
+// parseSubnetAZs decodes [[subnetId, az], ...] into subnet IDs and the
+// distinct AZs they sit in, sorted.
+func parseSubnetAZs(raw string) ([]string, []string, bool) {
+	var rows [][]string
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		return nil, nil, false
+	}
+	var subnets, azs []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if len(row) != 2 {
+			continue
+		}
+		subnets = append(subnets, row[0])
+		if az := strings.TrimSpace(row[1]); az != "" && !seen[az] {
+			seen[az] = true
+			azs = append(azs, az)
+		}
+	}
+	sort.Strings(azs)
+	return subnets, azs, true
+}
+
+// methodNeedsMultiAZ reports whether the chosen AWS method puts a load
+// balancer (or EKS control plane) in the VPC, which needs two AZs.
+func methodNeedsMultiAZ(arch *ArchitectDecision) bool {
+	if arch == nil {
+		return false
+	}
+	if p := strings.ToLower(strings.TrimSpace(arch.Provider)); p != "" && p != "aws" {
+		return false
+	}
+	switch arch.Method {
+	case "ec2", "eks":
+		// The EC2 deployment always fronts the instance with an ALB.
+		return true
+	case "ecs-fargate", "":
+		return arch.NeedsALB
+	default:
+		return false
+	}
+}
+
+// ValidateAvailabilityZones fails when an ALB-fronted method is chosen but
+// the default VPC has subnets in fewer than two AZs, which would otherwise
+// only surface when create-load-balancer fails at apply time. It passes when
+// a new VPC will be created or the scan could not see the account.
+func ValidateAvailabilityZones(arch *ArchitectDecision, snap *InfraSnapshot, opts *DeployOptions) error {
+	if !methodNeedsMultiAZ(arch) || snap == nil || snap.AccountID == "" {
+		return nil
+	}
+	if opts != nil && opts.NewVPC {
+		return nil
+	}
+	if snap.VPC == nil {
+		return fmt.Errorf("%s needs a VPC with subnets in at least %d availability zones, but %s has no default VPC; re-run with --new-vpc to create one", arch.Method, minLoadBalancerAZs, snap.Region)
+	}
+	if len(snap.VPC.Subnets) > 0 && snap.SubnetAZCount < minLoadBalancerAZs {
+		zones := "none"
+		if len(snap.VPC.AvailabilityZones) > 0 {
+			zones = strings.Join(snap.VPC.AvailabilityZones, ", ")
+		}
+		return fmt.Errorf("%s needs subnets in at least %d availability zones for its load balancer, but default VPC %s in %s only has subnets in %d (%s); re-run with --new-vpc, or add a subnet in another AZ to the default VPC",
+			arch.Method, minLoadBalancerAZs, snap.VPC.VPCID, snap.Region, snap.SubnetAZCount, zones)
+	}
+	return nil
+}
+
 func awsCLI(ctx context.Context, profile, region string, args ...string) string {
 	fullArgs := append([]string{"--profile", profile, "--region", region}, args...)
 	cmd := exec.CommandContext(ctx, "aws", fullArgs...)
@@ -171,7 +246,7 @@ func buildInfraSummary(s *InfraSnapshot) string {
 		parts = append(parts, fmt.Sprintf("account: %s", s.AccountID))
 	}
 	if s.VPC != nil {
-		parts = append(parts, fmt.Sprintf("VPC: %s (%d subnets)", s.VPC.VPCID, len(s.VPC.Subnets)))
+		parts = append(parts, fmt.Sprintf("VPC: %s (%d subnets in %d AZs)", s.VPC.VPCID, len(s.VPC.Subnets), s.SubnetAZCount))
 	} else {
 		parts = append(parts, "no default VPC found")
 	}
@@ -224,6 +299,10 @@ func (s *InfraSnapshot) FormatForPrompt() string {
 		if len(s.VPC.Subnets) > 0 {
 			b.WriteString(fmt.Sprintf("- Subnets: %s\n", strings.Join(s.VPC.Subnets, ", ")))
 			b.WriteString("  → REUSE these subnets, do NOT create new ones\n")
+			if len(s.VPC.AvailabilityZones) > 0 {
+				b.WriteString(fmt.Sprintf("- Subnet AZs: %s\n", strings.Join(s.VPC.AvailabilityZones, ", ")))
+				b.WriteString("  → An ALB needs two subnets in DIFFERENT AZs; pick one subnet per AZ\n")
+			}
 		}
 	}
 
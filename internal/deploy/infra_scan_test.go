@@ -0,0 +1,67 @@
+package deploy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSubnetAZs(t *testing.T) {
+	raw := `[["subnet-a","us-east-1b"],["subnet-b","us-east-1a"],["subnet-c","us-east-1b"]]`
+	subnets, azs, ok := parseSubnetAZs(raw)
+	if !ok {
+		t.Fatal("expected parse to succeed")
+	}
+	if !reflect.DeepEqual(subnets, []string{"subnet-a", "subnet-b", "subnet-c"}) {
+		t.Errorf("subnets = %v", subnets)
+	}
+	if !reflect.DeepEqual(azs, []string{"us-east-1a", "us-east-1b"}) {
+		t.Errorf("azs = %v", azs)
+	}
+	if _, _, ok := parseSubnetAZs("not json"); ok {
+		t.Error("expected parse failure for invalid output")
+	}
+}
+
+func TestValidateAvailabilityZones(t *testing.T) {
+	singleAZ := &InfraSnapshot{
+		AccountID:     "123456789012",
+		Region:        "us-east-1",
+		VPC:           &VPCInfo{VPCID: "vpc-1", Subnets: []string{"subnet-a"}, AvailabilityZones: []string{"us-east-1a"}, IsDefault: true},
+		SubnetAZCount: 1,
+	}
+	twoAZ := &InfraSnapshot{
+		AccountID:     "123456789012",
+		Region:        "us-east-1",
+		VPC:           &VPCInfo{VPCID: "vpc-1", Subnets: []string{"subnet-a", "subnet-b"}, AvailabilityZones: []string{"us-east-1a", "us-east-1b"}},
+		SubnetAZCount: 2,
+	}
+	fargateALB := &ArchitectDecision{Provider: "aws", Method: "ecs-fargate", NeedsALB: true}
+
+	err := ValidateAvailabilityZones(fargateALB, singleAZ, &DeployOptions{})
+	if err == nil || !strings.Contains(err.Error(), "--new-vpc") || !strings.Contains(err.Error(), "us-east-1a") {
+		t.Errorf("expected single-AZ failure suggesting --new-vpc, got %v", err)
+	}
+	if err := ValidateAvailabilityZones(&ArchitectDecision{Provider: "aws", Method: "ec2"}, singleAZ, nil); err == nil {
+		t.Error("ec2 always uses an ALB and should fail on a single AZ")
+	}
+
+	passing := []struct {
+		name string
+		arch *ArchitectDecision
+		snap *InfraSnapshot
+		opts *DeployOptions
+	}{
+		{"two AZs", fargateALB, twoAZ, nil},
+		{"new VPC", fargateALB, singleAZ, &DeployOptions{NewVPC: true}},
+		{"no ALB", &ArchitectDecision{Provider: "aws", Method: "ecs-fargate"}, singleAZ, nil},
+		{"app runner", &ArchitectDecision{Provider: "aws", Method: "app-runner"}, singleAZ, nil},
+		{"scan failed", fargateALB, &InfraSnapshot{Region: "us-east-1"}, nil},
+		{"no scan", fargateALB, nil, nil},
+	}
+	for _, tt := range passing {
+		if err := ValidateAvailabilityZones(tt.arch, tt.snap, tt.opts); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
@@ -5,10 +5,17 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
+
+	awsclient "github.com/bgdnvk/clanker/internal/aws"
+	"github.com/bgdnvk/clanker/internal/logging"
+	"github.com/bgdnvk/clanker/internal/notify"
 )
 
 func repoResourcePrefix(repoURL string, deployID string) string {
@@ -130,12 +137,18 @@ type IntelligenceResult struct {
 	DeepAnalysis     *DeepAnalysis         `json:"deepAnalysis"`
 	Docker           *DockerAnalysis       `json:"docker,omitempty"`
 	Preflight        *PreflightReport      `json:"preflight,omitempty"`
+	Statefulness     *StatefulnessReport   `json:"statefulness,omitempty"`
+	CostEstimate     *CostEstimate         `json:"costEstimate,omitempty"`
 	InfraSnap        *InfraSnapshot        `json:"infraSnapshot,omitempty"`
 	CFInfraSnap      *CFInfraSnapshot      `json:"cfInfraSnapshot,omitempty"`
 	DOInfraSnap      *DOInfraSnapshot      `json:"doInfraSnapshot,omitempty"`
 	HetznerInfraSnap *HetznerInfraSnapshot `json:"hetznerInfraSnapshot,omitempty"`
 	Architecture     *ArchitectDecision    `json:"architecture"`
+	Candidates       []ArchitectDecision   `json:"candidates,omitempty"` // every architecture proposed when DeployOptions.ArchitectCandidates > 1
 	Validation       *PlanValidation       `json:"validation,omitempty"`
+	Health           *DeploymentHealth     `json:"health,omitempty"` // set by VerifyDeployment after deploy
+	RollbackPlan     *RollbackPlan         `json:"rollbackPlan,omitempty"`
+	ResourcePrefix   string                `json:"resourcePrefix,omitempty"` // name prefix of the created resources
 	// final enriched prompt for maker pipeline
 	EnrichedPrompt string `json:"enrichedPrompt"`
 }
@@ -148,13 +161,27 @@ type DeployOptions struct {
 	DeployID     string // run-specific id for unique resource naming
 	DOToken      string // DigitalOcean API token for infra scan
 	HetznerToken string // Hetzner Cloud API token for infra scan
+	CFToken      string // Cloudflare API token for infra scan
+	CFAccountID  string // Cloudflare account ID for infra scan
+	Partition    string // AWS partition (aws, aws-us-gov, aws-cn); derived from the region when empty
 	SREOnly      bool   // deploy only the Clanker SRE observer, not the app
+	SubPath      string // monorepo workspace to deploy, relative to the repo root (e.g. packages/api)
+
+	FileBudgetTokens int // cap on file contents per phase prompt (intelligence.file_budget_tokens); 0 uses the default
+	MaxExploreRounds int // cap on phase 0 exploration rounds (intelligence.max_explore_rounds); 0 uses the default
+
+	AnalysisAsk AskFunc // LLM call for the deep analysis phase (the profile's analysis_model); nil uses ask
+
+	ArchitectCandidates int              // architectures to propose (2-3) instead of a single decision; 0 or 1 proposes one
+	ChooseArchitecture  ArchitectChooser // picks among the candidates (deploy --interactive); nil keeps the recommended one
+
+	Notifier notify.Notifier // told when RunIntelligence finishes or fails (notifications.sink); nil sends nothing
 }
 
 // shouldUseAPIGateway determines whether to use API Gateway or ALB based on app characteristics.
 // API Gateway is better for: pure REST APIs, low traffic, pay-per-request pricing
 // ALB is better for: web apps with frontend, WebSockets, high traffic, static content
-func shouldUseAPIGateway(p *RepoProfile, deep *DeepAnalysis) bool {
+func shouldUseAPIGateway(p *RepoProfile, deep *DeepAnalysis, docker *DockerAnalysis) bool {
 	// Web apps with frontend should use ALB
 	frontendFrameworks := map[string]bool{
 		"react": true, "nextjs": true, "nuxt": true, "vue": true,
@@ -170,9 +197,13 @@ func shouldUseAPIGateway(p *RepoProfile, deep *DeepAnalysis) bool {
 		return false
 	}
 
-	// Check deep analysis for WebSocket mentions
+	// API Gateway cannot hold long-lived connections behind a container.
+	if docker != nil && docker.NeedsWebSockets {
+		return false
+	}
+
+	// Check deep analysis for WebSocket services
 	if deep != nil {
-		descLower := strings.ToLower(deep.AppDescription)
 		for _, service := range deep.Services {
 			serviceLower := strings.ToLower(service)
 			if strings.Contains(serviceLower, "websocket") ||
@@ -182,11 +213,6 @@ func shouldUseAPIGateway(p *RepoProfile, deep *DeepAnalysis) bool {
 				return false // ALB for WebSocket apps
 			}
 		}
-		if strings.Contains(descLower, "websocket") ||
-			strings.Contains(descLower, "real-time") ||
-			strings.Contains(descLower, "realtime") {
-			return false
-		}
 	}
 
 	// Pure API frameworks should use API Gateway
@@ -221,6 +247,110 @@ type EnvVarSpec struct {
 	Example     string `json:"example"`
 }
 
+var (
+	// envAssignRe matches NAME=value env assignments in CLI args, user-data and
+	// Lambda Variables={...} maps, with the value optionally (escaped-)quoted.
+	envAssignRe = regexp.MustCompile(`\b([A-Z][A-Z0-9_]+)=(?:\\?["'])?([^\s"'\\,;&|}\]]+)`)
+	// envNameValueRe matches ECS-style {"name": "X", "value": "Y"} pairs, which
+	// appear escaped inside the plan's string args.
+	envNameValueRe = regexp.MustCompile(`\\?"name\\?"\s*:\s*\\?"([A-Za-z_][A-Za-z0-9_]*)\\?"\s*,\s*\\?"value\\?"\s*:\s*\\?"([^"\\]+)`)
+
+	awsKeyIDRe   = regexp.MustCompile(`^(AKIA|ASIA)[0-9A-Z]{16}$`)
+	apiKeyRe     = regexp.MustCompile(`^sk-[A-Za-z0-9_-]{16,}$`)
+	jwtRe        = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}$`)
+	base64BlobRe = regexp.MustCompile(`^[A-Za-z0-9+/_-]{40,}={0,2}$`)
+)
+
+// secretPlaceholderMarkers appear in documentation examples (EnvVarSpec.Example,
+// .env.example files) rather than real credentials.
+var secretPlaceholderMarkers = []string{"xxx", "your", "example", "placeholder", "changeme", "change-me", "dummy", "redacted", "replace", "todo", "secret-here", "<", ">", "${", "{{"}
+
+// detectInlineSecrets scans the env var values set in a plan for literals
+// that look like real credentials and returns one finding per variable. The
+// values themselves are never echoed back.
+func detectInlineSecrets(planJSON string) []string {
+	if strings.TrimSpace(planJSON) == "" {
+		return nil
+	}
+
+	var findings []string
+	seen := make(map[string]bool)
+	check := func(name, value string) {
+		kind := classifySecretValue(value)
+		if kind == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		findings = append(findings, fmt.Sprintf("%s looks like %s", name, kind))
+	}
+	for _, m := range envAssignRe.FindAllStringSubmatch(planJSON, -1) {
+		check(m[1], m[2])
+	}
+	for _, m := range envNameValueRe.FindAllStringSubmatch(planJSON, -1) {
+		check(m[1], m[2])
+	}
+	return findings
+}
+
+// classifySecretValue names the kind of credential value looks like, or
+// returns "" when it looks like an ordinary value or a placeholder.
+func classifySecretValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || isSecretPlaceholder(value) {
+		return ""
+	}
+	switch {
+	case awsKeyIDRe.MatchString(value):
+		return "an AWS access key ID"
+	case apiKeyRe.MatchString(value):
+		return "an API key (sk- prefix)"
+	case jwtRe.MatchString(value):
+		return "a JWT"
+	case base64BlobRe.MatchString(value) && hasMixedCharClasses(value):
+		return "a long base64-encoded secret"
+	}
+	return ""
+}
+
+func isSecretPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	if strings.HasPrefix(lower, "$") {
+		return true
+	}
+	for _, marker := range secretPlaceholderMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	// Real keys are high-entropy; "sk-aaaaaaaaaaaaaaaa" or "AKIA0000..." are not.
+	body := value
+	if i := strings.IndexAny(body, "-"); i >= 0 && i < 5 {
+		body = body[i+1:]
+	}
+	distinct := make(map[rune]bool)
+	for _, r := range body {
+		distinct[r] = true
+	}
+	return len(distinct) <= 4
+}
+
+// hasMixedCharClasses reports whether s mixes upper case, lower case and
+// digits, which separates encoded secrets from long words, paths and hex digests.
+func hasMixedCharClasses(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
 // DeepAnalysis is the LLM's understanding of what the app actually does
 type DeepAnalysis struct {
 	AppDescription string   `json:"appDescription"` // what does this app do
@@ -237,6 +367,10 @@ type DeepAnalysis struct {
 	BuildCommand  string `json:"buildCommand"`  // build step if needed: "npm run build"
 	NodeVersion   string `json:"nodeVersion"`   // required Node version
 
+	// Language version the repo pins (package.json engines, go.mod,
+	// pyproject.toml, ...), checked against the chosen runtime
+	RuntimeRequirement *RuntimeRequirement `json:"runtimeRequirement,omitempty"`
+
 	// Config requirements (extracted from README and .env files)
 	RequiredEnvVars []EnvVarSpec `json:"requiredEnvVars"` // MUST have values to run
 	OptionalEnvVars []EnvVarSpec `json:"optionalEnvVars"` // nice to have, has defaults
@@ -248,15 +382,25 @@ type DeepAnalysis struct {
 	// Deployment method
 	PreferDocker  bool   `json:"preferDocker"`  // true if Dockerfile exists and is recommended
 	GlobalInstall string `json:"globalInstall"` // e.g., "npm install -g appname"
+
+	// Database migrations that must run before the app serves traffic
+	MigrationPlan *MigrationPlan `json:"migrationPlan,omitempty"`
+
+	// Fields coerced, dropped or missing while parsing the response
+	ParseWarnings []string `json:"parseWarnings,omitempty"`
 }
 
 // PlanValidation is the LLM's review of its own generated plan
 type PlanValidation struct {
-	IsValid                bool     `json:"isValid"`
-	Issues                 []string `json:"issues"`                 // problems found
-	Fixes                  []string `json:"fixes"`                  // suggested fixes
-	Warnings               []string `json:"warnings"`               // non-blocking warnings
-	UnresolvedPlaceholders []string `json:"unresolvedPlaceholders"` // placeholders that need resolution
+	IsValid                bool                    `json:"isValid"`
+	Issues                 []string                `json:"issues"`                 // problems found
+	Fixes                  []string                `json:"fixes"`                  // suggested fixes
+	Warnings               []string                `json:"warnings"`               // non-blocking warnings
+	UnresolvedPlaceholders []UnresolvedPlaceholder `json:"unresolvedPlaceholders"` // env vars left as placeholders
+
+	// IssueDetails carries every issue and warning with its severity; Issues
+	// and Warnings are derived from it for callers that predate severities.
+	IssueDetails []ValidationIssue `json:"issueDetails,omitempty"`
 }
 
 // RunIntelligence executes the multi-phase recursive reasoning pipeline.
@@ -266,21 +410,73 @@ type PlanValidation struct {
 // Phase 2: Architecture Decision + Cost Estimation (LLM picks best option)
 // Both phases feed into the final enriched prompt for the maker plan generator.
 func RunIntelligence(ctx context.Context, profile *RepoProfile, ask AskFunc, clean CleanFunc, debug bool, targetProvider, awsProfile, awsRegion string, opts *DeployOptions, logf func(string, ...any)) (*IntelligenceResult, error) {
+	result, err := runIntelligence(ctx, profile, ask, clean, debug, targetProvider, awsProfile, awsRegion, opts, logf)
+	if opts != nil {
+		notify.Send(ctx, opts.Notifier, intelligenceNotification(profile, result, err))
+	}
+	return result, err
+}
+
+// intelligenceNotification summarizes a pipeline run in one line: the repo,
+// the chosen architecture and its estimated monthly cost.
+func intelligenceNotification(profile *RepoProfile, result *IntelligenceResult, err error) notify.Notification {
+	repo := "repo"
+	if profile != nil && profile.RepoURL != "" {
+		repo = profile.RepoURL
+	}
+	if err != nil {
+		return notify.Notification{Title: "Deploy analysis failed", Summary: fmt.Sprintf("%s: %v", repo, err), Failed: true}
+	}
+	summary := repo
+	if result != nil && result.Architecture != nil {
+		arch := result.Architecture
+		summary += fmt.Sprintf(" → %s %s", arch.Provider, arch.Method)
+		if arch.EstMonthly != "" {
+			summary += fmt.Sprintf(", est. %s monthly", arch.EstMonthly)
+		}
+	}
+	return notify.Notification{Title: "Deploy analysis finished", Summary: summary}
+}
+
+func runIntelligence(ctx context.Context, profile *RepoProfile, ask AskFunc, clean CleanFunc, debug bool, targetProvider, awsProfile, awsRegion string, opts *DeployOptions, logf func(string, ...any)) (*IntelligenceResult, error) {
 	// default options if nil
 	if opts == nil {
-		opts = &DeployOptions{Target: "fargate", InstanceType: "t3.small"}
+		opts = &DeployOptions{}
 	}
-	if opts.Target == "" {
-		opts.Target = "fargate"
+	if strings.TrimSpace(opts.Partition) == "" {
+		opts.Partition = awsclient.PartitionForRegion(awsRegion)
 	}
 	if opts.SREOnly {
 		return buildSREOnlyIntelligence(profile, targetProvider, opts, logf), nil
 	}
-	result := &IntelligenceResult{}
+	// Fail fast with a fix-it message instead of an empty infra scan and a
+	// confusing error from a later phase.
+	if err := preflightCredentials(ctx, targetProvider, awsProfile, awsRegion, opts); err != nil {
+		return nil, fmt.Errorf("credential preflight failed: %w", err)
+	}
+	if strings.TrimSpace(opts.SubPath) != "" {
+		if err := ScopeProfileToSubPath(profile, opts.SubPath); err != nil {
+			return nil, err
+		}
+		logf("[intelligence] scoped to workspace %s: %s", profile.SubPath, profile.Summary)
+	}
+	// The repo's own deploy config fills whatever the CLI left unset; the
+	// hardcoded defaults only apply when neither said anything.
+	if cfg := profile.RepoConfig; cfg != nil {
+		applyRepoConfigOptions(cfg, opts)
+		logf("[intelligence] using deploy config from %s (target: %s)", cfg.File, opts.Target)
+	}
+	if opts.Target == "" {
+		opts.Target = "fargate"
+	}
+	if opts.InstanceType == "" {
+		opts.InstanceType = "t3.small"
+	}
+	result := &IntelligenceResult{ResourcePrefix: repoResourcePrefix(profile.RepoURL, opts.DeployID)}
 
 	// Phase 0: Agentic file exploration — LLM asks for files it needs
 	logf("[intelligence] phase 0: exploring repository...")
-	exploration, err := ExploreRepo(ctx, profile, ask, clean, logf)
+	exploration, err := ExploreRepo(ctx, profile, opts.MaxExploreRounds, ask, clean, logf)
 	if err != nil {
 		logf("[intelligence] warning: exploration failed (%v), using static files only", err)
 		exploration = &ExplorationResult{FilesRead: profile.KeyFiles}
@@ -308,23 +504,41 @@ func RunIntelligence(ctx context.Context, profile *RepoProfile, ask AskFunc, cle
 	go func() {
 		defer wg.Done()
 		logf("[intelligence] phase 1: deep understanding (%d files)...", len(profile.KeyFiles))
-		deepPrompt := buildDeepAnalysisPrompt(profile)
-		deepResp, callErr := ask(ctx, deepPrompt)
+		deepPrompt := buildDeepAnalysisPrompt(profile, NewProfileBudget(opts.FileBudgetTokens))
+		deepAsk := ask
+		if opts.AnalysisAsk != nil {
+			deepAsk = opts.AnalysisAsk
+		}
+		deepResp, callErr := deepAsk(ctx, deepPrompt)
 		if callErr != nil {
 			deepErr = fmt.Errorf("phase 1 (deep analysis) failed: %w", callErr)
 			return
 		}
 
 		parsed, parseErr := parseDeepAnalysis(clean(deepResp))
+		if parseErr != nil {
+			logf("[intelligence] deep analysis response unusable (%v), retrying with a strict schema reminder", parseErr)
+			parsed, parseErr = retryStrictJSON(ctx, deepAsk, clean, deepPrompt, parsed, parseErr, parseDeepAnalysis)
+		}
+		fallbackDescription := profile.Summary
+		if exploration.Analysis != "" {
+			fallbackDescription = exploration.Analysis
+		}
+		var missing *missingFieldsError
+		if errors.As(parseErr, &missing) {
+			logf("[intelligence] warning: deep analysis still %v, filling from static analysis", parseErr)
+			parsed.AppDescription = fallbackDescription
+			parseErr = nil
+		}
 		if parseErr != nil {
 			logf("[intelligence] warning: deep analysis parse failed (%v), continuing with static analysis", parseErr)
 			parsed = &DeepAnalysis{
-				AppDescription: profile.Summary,
+				AppDescription: fallbackDescription,
 				Complexity:     "unknown",
 			}
-			if exploration.Analysis != "" {
-				parsed.AppDescription = exploration.Analysis
-			}
+		}
+		if len(parsed.ParseWarnings) > 0 {
+			logf("[intelligence] deep analysis parse warnings: %s", strings.Join(parsed.ParseWarnings, "; "))
 		}
 		deep = parsed
 	}()
@@ -344,7 +558,11 @@ func RunIntelligence(ctx context.Context, profile *RepoProfile, ask AskFunc, cle
 		switch strings.ToLower(strings.TrimSpace(targetProvider)) {
 		case "cloudflare":
 			logf("[intelligence] phase 1.5: scanning Cloudflare infrastructure...")
-			cfInfraSnap = ScanCFInfra(ctx, logf)
+			var cfToken, cfAccountID string
+			if opts != nil {
+				cfToken, cfAccountID = opts.CFToken, opts.CFAccountID
+			}
+			cfInfraSnap = ScanCFInfra(ctx, cfToken, cfAccountID, logf)
 		case "digitalocean":
 			if opts != nil && opts.DOToken != "" {
 				logf("[intelligence] phase 1.5: scanning DigitalOcean infrastructure...")
@@ -371,8 +589,17 @@ func RunIntelligence(ctx context.Context, profile *RepoProfile, ask AskFunc, cle
 	if deepErr != nil {
 		return nil, deepErr
 	}
+	applyRepoConfigAnalysis(profile.RepoConfig, deep)
+	ensureMigrationPlan(profile, deep)
+	ensureRuntimeRequirement(profile, deep)
+	if deep.RuntimeRequirement != nil {
+		logf("[intelligence] runtime requirement: %s", deep.RuntimeRequirement)
+	}
 	result.DeepAnalysis = deep
 	result.Preflight = BuildPreflightReport(profile, result.Docker, deep)
+	if deep.MigrationPlan != nil {
+		logf("[intelligence] migrations detected: %s (%s)", deep.MigrationPlan.Command, deep.MigrationPlan.Mode)
+	}
 
 	// CRITICAL: Update profile.Ports with detected listening port from deep analysis
 	// This ensures the port is used correctly in EC2/ECS prompts for target groups
@@ -381,7 +608,7 @@ func RunIntelligence(ctx context.Context, profile *RepoProfile, ask AskFunc, cle
 		profile.Ports = []int{deep.ListeningPort}
 	}
 
-	if debug {
+	if debug || logging.Enabled(logging.LevelVerbose) {
 		logf("[intelligence] deep analysis: %s (complexity: %s)", deep.AppDescription, deep.Complexity)
 	}
 
@@ -393,41 +620,63 @@ func RunIntelligence(ctx context.Context, profile *RepoProfile, ask AskFunc, cle
 	// Phase 2: Architecture Decision + Cost Estimation
 	logf("[intelligence] phase 2: architecture + cost estimation (target: %s)...", opts.Target)
 	archPrompt := buildSmartArchitectPrompt(profile, deep, targetProvider, opts)
-	archResp, err := ask(ctx, archPrompt)
+	if cfCtx := cfInfraSnap.FormatCFForPrompt(); cfCtx != "" {
+		archPrompt += "\n## Existing Cloudflare Resources\n" + cfCtx
+	}
+	archPrompt += runtimeRequirementPrompt(deep.RuntimeRequirement)
+	// With candidates requested the first call asks for them; a retry after
+	// an unusable response asks for a single decision.
+	askPrompt := archPrompt
+	candidateCount := architectCandidateCount(opts)
+	if candidateCount > 0 {
+		askPrompt += architectCandidatesPrompt(candidateCount)
+	}
+	archResp, err := ask(ctx, askPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("phase 2 (architecture) failed: %w", err)
 	}
 
-	arch, err := ParseArchitectDecision(clean(archResp))
-	if err != nil {
-		logf("[intelligence] warning: architect parse failed (%v), using heuristic", err)
-		strat := DefaultStrategy(profile)
-		arch = &ArchitectDecision{
-			Provider:  strat.Provider,
-			Method:    strat.Method,
-			Reasoning: "fallback heuristic",
+	var arch *ArchitectDecision
+	if candidateCount > 0 {
+		arch, err = chooseArchitectCandidate(result, clean(archResp), opts, logf)
+		if err != nil {
+			return nil, err
 		}
 	}
-
-	// Deterministic override: OpenClaw is stateful + long-lived websocket gateway.
-	// When deploying to AWS and the target is default/unspecified, prefer EC2.
-	ApplyOpenClawArchitectureDefaults(targetProvider, opts, profile, deep, arch)
-	// Deterministic override: WordPress one-click deploy uses EC2 + ALB + Docker Hub images.
-	ApplyWordPressArchitectureDefaults(targetProvider, opts, profile, deep, arch)
-	result.Architecture = arch
-
-	// Deterministic override: static sites should prefer static hosting unless user explicitly requested EC2/EKS.
-	if strings.EqualFold(strings.TrimSpace(targetProvider), "aws") || strings.TrimSpace(targetProvider) == "" {
-		if result.Preflight != nil && result.Preflight.IsStaticSite {
-			if opts == nil || strings.TrimSpace(opts.Target) == "" || strings.TrimSpace(opts.Target) == "fargate" {
-				if arch.Method != "s3-cloudfront" {
-					arch.Method = "s3-cloudfront"
-					arch.Provider = "aws"
-					arch.Reasoning = "Static site detected; S3+CloudFront is simpler and cheaper than running servers"
-				}
+	userChosen := arch != nil && opts.ChooseArchitecture != nil
+	if arch == nil {
+		arch, err = ParseArchitectDecision(clean(archResp))
+		if err != nil {
+			logf("[intelligence] architect response unusable (%v), retrying with a strict schema reminder", err)
+			arch, err = retryStrictJSON(ctx, ask, clean, archPrompt, arch, err, ParseArchitectDecision)
+		}
+		var missingArch *missingFieldsError
+		if errors.As(err, &missingArch) {
+			logf("[intelligence] warning: architect response still %v, defaulting to %s", err, arch.Method)
+			err = nil
+		}
+		if err != nil {
+			logf("[intelligence] warning: architect parse failed (%v), using heuristic", err)
+			strat := DefaultStrategy(profile)
+			if strings.EqualFold(strings.TrimSpace(targetProvider), "digitalocean") {
+				strat.Provider = "digitalocean"
+				strat.Method = doDefaultMethod(profile, deep)
+			}
+			arch = &ArchitectDecision{
+				Provider:  strat.Provider,
+				Method:    strat.Method,
+				Reasoning: "fallback heuristic",
 			}
 		}
 	}
+	if len(arch.ParseWarnings) > 0 {
+		logf("[intelligence] architect parse warnings: %s", strings.Join(arch.ParseWarnings, "; "))
+	}
+
+	stateful := detectStatefulness(profile, deep, result.Docker)
+	result.Statefulness = &stateful
+	applyArchitectureOverrides(targetProvider, opts, profile, deep, result, stateful, arch, userChosen, logf)
+	result.Architecture = arch
 
 	logf("[intelligence] architecture: %s — %s", arch.Method, arch.Reasoning)
 	if arch.EstMonthly != "" {
@@ -445,12 +694,45 @@ func RunIntelligence(ctx context.Context, profile *RepoProfile, ask AskFunc, cle
 		}
 	}
 
-	// Auto-detect API Gateway vs ALB based on app type
-	arch.UseAPIGateway = shouldUseAPIGateway(profile, deep)
+	ApplyLambdaAPIGatewayDefaults(arch)
+
+	// Cheaper shapes of an EC2 host, offered but not applied.
+	if ApplyEC2CostAlternatives(arch, profile, result.Docker, stateful) {
+		for _, alt := range arch.Alternatives {
+			if alt.CostDelta == "" {
+				continue
+			}
+			shape := alt.InstanceType
+			if alt.Spot {
+				shape += " Spot"
+			}
+			logf("[intelligence] cheaper option: %s at %s (%s)", shape, alt.EstMonthly, alt.CostDelta)
+		}
+	}
+
+	// Deterministic cross-check: the architect's estMonthly is a model guess;
+	// prefer the static price table when the two disagree wildly.
+	if est, replaced := CrossCheckArchitectCost(arch, opts); est != nil {
+		result.CostEstimate = est
+		if replaced {
+			logf("[intelligence] cost estimate from price table: %s/month", arch.EstMonthly)
+		}
+	}
+
+	// An ALB needs two AZs; catch a single-AZ default VPC now, not at apply time.
+	if err := ValidateAvailabilityZones(arch, infraSnap, opts); err != nil {
+		return nil, err
+	}
+	// A runtime older (or newer) than the repo pins builds or crashes only
+	// after deploying.
+	if err := ValidateRuntimeVersion(arch, deep, profile); err != nil {
+		return nil, err
+	}
 
 	// build the final enriched prompt with all intelligence + infra context
 	strat := StrategyFromArchitect(arch)
 	result.EnrichedPrompt = buildIntelligentPrompt(profile, deep, result.Docker, arch, strat, infraSnap, cfInfraSnap, doInfraSnap, hetznerInfraSnap, opts)
+	result.RollbackPlan = BuildRollbackPlan(profile, deep, arch, opts)
 
 	return result, nil
 }
@@ -572,7 +854,8 @@ func ValidatePlan(ctx context.Context, planJSON string, profile *RepoProfile, de
 	// missing onboarding scripts for known repos, and secret inlining.
 	det := runDeterministicPlanValidation(planJSON, profile, deep, docker, profile.EnvVars)
 	if len(det.Issues) > 0 {
-		v := &PlanValidation{IsValid: false, Issues: det.Issues, Fixes: det.Fixes, Warnings: det.Warnings}
+		v := &PlanValidation{IsValid: false, Issues: det.Issues, Fixes: det.Fixes, Warnings: det.Warnings, UnresolvedPlaceholders: det.Placeholders}
+		v.IssueDetails = deterministicIssueDetails(det.Issues, det.Warnings)
 		return v, buildFixPrompt(v), nil
 	}
 
@@ -597,6 +880,7 @@ func ValidatePlan(ctx context.Context, planJSON string, profile *RepoProfile, de
 		return v, buildFixPrompt(v), nil
 	}
 	v = normalizeValidation(v)
+	v.UnresolvedPlaceholders = append(v.UnresolvedPlaceholders, det.Placeholders...)
 
 	if !v.IsValid && len(v.Fixes) > 0 {
 		// build a fix prompt that the caller can feed back into plan generation
@@ -631,7 +915,7 @@ func normalizeValidation(v *PlanValidation) *PlanValidation {
 		if strings.Contains(l, "cloudfront does not") && strings.Contains(l, "websocket") {
 			return false
 		}
-		if strings.Contains(l, "iam policy arn is malformed") && strings.Contains(l, "arn:aws:iam::aws:policy/") {
+		if strings.Contains(l, "iam policy arn is malformed") && mentionsManagedPolicyARN(l) {
 			return false
 		}
 		return true
@@ -650,10 +934,18 @@ func normalizeValidation(v *PlanValidation) *PlanValidation {
 		}
 	}
 
+	details := make([]ValidationIssue, 0, len(v.IssueDetails))
+	for _, item := range v.IssueDetails {
+		if keepIssue(item.Message) {
+			details = append(details, item)
+		}
+	}
+
 	v.Issues = uniqueStrings(issues)
 	v.Warnings = uniqueStrings(warnings)
-	if len(v.Issues) == 0 {
-		v.IsValid = true
+	v.IssueDetails = details
+	applyIssueSeverities(v)
+	if v.IsValid {
 		v.Fixes = nil
 	}
 	return v
@@ -661,10 +953,11 @@ func normalizeValidation(v *PlanValidation) *PlanValidation {
 
 // --- Phase 1: Deep Understanding ---
 
-func buildDeepAnalysisPrompt(p *RepoProfile) string {
+func buildDeepAnalysisPrompt(p *RepoProfile, budget ProfileBudget) string {
 	var b strings.Builder
 
 	b.WriteString("You are an expert software engineer. Analyze this repository and explain what it does and how to run it.\n\n")
+	b.WriteString(subPathPromptSection(p))
 
 	// file tree
 	if p.FileTree != "" {
@@ -673,14 +966,8 @@ func buildDeepAnalysisPrompt(p *RepoProfile) string {
 		b.WriteString("```\n\n")
 	}
 
-	// actual file contents
-	if len(p.KeyFiles) > 0 {
-		b.WriteString("## Key Files\n")
-		for name, content := range p.KeyFiles {
-			b.WriteString(fmt.Sprintf("\n### %s\n```\n%s\n```\n", name, content))
-		}
-		b.WriteString("\n")
-	}
+	// actual file contents, capped to the context budget
+	b.WriteString(budget.Apply(p.KeyFiles).FormatForPrompt())
 
 	// static analysis results for context
 	profileJSON, _ := json.MarshalIndent(struct {
@@ -777,6 +1064,13 @@ Analyze the repo deeply and respond with JSON. READ THE README AND PACKAGE.JSON
 17. globalInstall: Can it be installed globally?
     - e.g., "npm install -g packagename"
 
+18. migrationPlan: Does the app need database migrations before it can serve requests?
+    - Check migrationHints, prisma/, alembic.ini, flyway.conf, drizzle config, package.json scripts
+    - tool: prisma, alembic, flyway, drizzle, goose, knex, django, rails, or custom
+    - command: the exact deploy-time command (e.g. "npx prisma migrate deploy", "alembic upgrade head")
+    - mode: "on_boot" if the Dockerfile/entrypoint/start script already runs it, otherwise "job"
+    - Omit (null) if the app has no migrations
+
 ## Response Format (JSON only, no markdown fences)
 {
   "appDescription": "...",
@@ -799,21 +1093,25 @@ Analyze the repo deeply and respond with JSON. READ THE README AND PACKAGE.JSON
   "healthEndpoint": "/health",
   "exposesHTTP": true,
   "preferDocker": false,
-  "globalInstall": ""
+  "globalInstall": "",
+  "migrationPlan": {"tool": "prisma", "command": "npx prisma migrate deploy", "mode": "job", "notes": "needs DATABASE_URL"}
 }`)
 
 	return b.String()
 }
 
+// parseDeepAnalysis decodes the phase 1 response, coercing mistyped fields
+// into ParseWarnings. When appDescription is missing the analysis is
+// returned with a *missingFieldsError.
 func parseDeepAnalysis(raw string) (*DeepAnalysis, error) {
-	raw = strings.TrimSpace(raw)
-	raw = strings.TrimPrefix(raw, "```json")
-	raw = strings.TrimPrefix(raw, "```")
-	raw = strings.TrimSuffix(raw, "```")
-	raw = strings.TrimSpace(raw)
-
 	var d DeepAnalysis
-	if err := json.Unmarshal([]byte(raw), &d); err != nil {
+	warnings, err := decodeLLMJSON(raw, &d, "appDescription")
+	d.ParseWarnings = warnings
+	var missing *missingFieldsError
+	if errors.As(err, &missing) {
+		return &d, missing
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse deep analysis: %w", err)
 	}
 	return &d, nil
@@ -821,6 +1119,53 @@ func parseDeepAnalysis(raw string) (*DeepAnalysis, error) {
 
 // --- Phase 2: Smart Architecture ---
 
+// applyArchitectureOverrides applies the deterministic placement rules to
+// the architect's decision. A candidate the user picked with --interactive
+// is final: what the rules would have changed is logged instead of applied.
+func applyArchitectureOverrides(targetProvider string, opts *DeployOptions, profile *RepoProfile, deep *DeepAnalysis, result *IntelligenceResult, stateful StatefulnessReport, arch *ArchitectDecision, userChosen bool, logf func(string, ...any)) {
+	if !userChosen {
+		overrideArchitecture(targetProvider, opts, profile, deep, result, stateful, arch, logf)
+		return
+	}
+	proposed := *arch
+	proposed.Notes = slices.Clone(arch.Notes)
+	overrideArchitecture(targetProvider, opts, profile, deep, result, stateful, &proposed, func(string, ...any) {})
+	if proposed.Method != arch.Method || proposed.UseAPIGateway != arch.UseAPIGateway {
+		logf("[intelligence] warning: keeping your choice of %s (api gateway: %t); clanker would use %s (api gateway: %t): %s",
+			arch.Method, arch.UseAPIGateway, proposed.Method, proposed.UseAPIGateway, proposed.Reasoning)
+	}
+}
+
+// overrideArchitecture rewrites the decision for what the code shows. A
+// registered recipe for a known app (OpenClaw, WordPress, or one added with
+// RegisterDeployRecipe) places it first; otherwise apps with local state
+// (SQLite files, compose volumes, in-memory sessions) must not land on a
+// target that loses it on redeploy. Static sites prefer S3+CloudFront unless
+// the user asked for a target, and API Gateway vs ALB follows the app type.
+func overrideArchitecture(targetProvider string, opts *DeployOptions, profile *RepoProfile, deep *DeepAnalysis, result *IntelligenceResult, stateful StatefulnessReport, arch *ArchitectDecision, logf func(string, ...any)) {
+	if recipe := matchingDeployRecipe(profile, deep); recipe != nil {
+		if recipe.Apply(targetProvider, opts, profile, deep, arch) {
+			logf("[intelligence] %s recipe: using %s", recipe.Name(), arch.Method)
+		}
+	} else if ApplyStatefulArchitectureDefaults(targetProvider, opts, stateful, arch) {
+		logf("[intelligence] stateful app (%s): using %s", strings.Join(stateful.Signals(), ", "), arch.Method)
+	}
+
+	if strings.EqualFold(strings.TrimSpace(targetProvider), "aws") || strings.TrimSpace(targetProvider) == "" {
+		if result.Preflight != nil && result.Preflight.IsStaticSite {
+			if opts == nil || strings.TrimSpace(opts.Target) == "" || strings.TrimSpace(opts.Target) == "fargate" {
+				if arch.Method != "s3-cloudfront" {
+					arch.Method = "s3-cloudfront"
+					arch.Provider = "aws"
+					arch.Reasoning = "Static site detected; S3+CloudFront is simpler and cheaper than running servers"
+				}
+			}
+		}
+	}
+
+	arch.UseAPIGateway = shouldUseAPIGateway(profile, deep, result.Docker)
+}
+
 func buildSmartArchitectPrompt(p *RepoProfile, deep *DeepAnalysis, targetProvider string, opts *DeployOptions) string {
 	var b strings.Builder
 
@@ -853,6 +1198,9 @@ func buildSmartArchitectPrompt(p *RepoProfile, deep *DeepAnalysis, targetProvide
 	if p.IsMonorepo {
 		b.WriteString(fmt.Sprintf("\n- Monorepo (%s workspaces)", p.PackageManager))
 	}
+	if p.SubPath != "" {
+		b.WriteString(fmt.Sprintf("\n- Deploying only the workspace at %s", p.SubPath))
+	}
 	if len(p.Ports) > 0 {
 		portStrs := make([]string, len(p.Ports))
 		for i, port := range p.Ports {
@@ -860,6 +1208,7 @@ func buildSmartArchitectPrompt(p *RepoProfile, deep *DeepAnalysis, targetProvide
 		}
 		b.WriteString(fmt.Sprintf("\n- Ports: %s", strings.Join(portStrs, ", ")))
 	}
+	b.WriteString(formatRepoConfigForPrompt(p.RepoConfig))
 
 	// key file contents for informed decisions
 	if dockerfile, ok := p.KeyFiles["Dockerfile"]; ok {
@@ -1029,50 +1378,7 @@ Estimate the MONTHLY cost in USD.
 			b.WriteString(OpenClawArchitectPromptDigitalOcean())
 			break
 		}
-		b.WriteString(`
-## DigitalOcean Options to Consider
-1. **do-droplet** — Droplet VM + Docker Compose (best for stateful or always-on services) (~$6-24/mo)
-2. **do-app-platform** — managed containers (good for stateless HTTP apps, no persistent disk)
-3. **do-k8s** — Kubernetes (overkill unless explicitly requested)
-
-## DigitalOcean Services
-- Droplet for always-on runtime
-- Block storage volume for stateful data (optional)
-- Container Registry (DOCR) for Docker images
-- Cloud Firewall for port restrictions
-- Reserved IP for stable public endpoint
-
-## Deployment CLI
-All commands must use doctl CLI only.
-
-## Cost Estimation
-Estimate the MONTHLY cost in USD.
-
-## Response Format (JSON only, no markdown fences)
-{
-	"provider": "digitalocean",
-	"method": "do-droplet",
-	"reasoning": "A Droplet with Docker Compose is the simplest and cheapest option for this app.",
-	"alternatives": [
-		{"method": "do-app-platform", "why_not": "No persistent local disk; less suitable for stateful apps"},
-		{"method": "do-k8s", "why_not": "Unnecessary complexity for this workload"}
-	],
-	"buildSteps": [
-		"Create Container Registry and push Docker image",
-		"Create Cloud Firewall for required ports",
-		"Create Droplet with user-data (install Docker, pull image, compose up)",
-		"Verify service health"
-	],
-	"runCmd": "docker compose up -d",
-	"notes": ["Expose only required ports via Cloud Firewall", "Persist any required state on disk"],
-	"cpuMemory": "s-1vcpu-2gb",
-	"needsAlb": false,
-	"useApiGateway": false,
-	"needsDb": false,
-	"dbService": "",
-	"estMonthly": "$12-18",
-	"costBreakdown": ["Droplet", "Container Registry basic", "Reserved IP"]
-}`)
+		b.WriteString(doArchitectPrompt(p, deep))
 	case "hetzner":
 		b.WriteString(`
 ## Hetzner Cloud Options to Consider
@@ -1136,6 +1442,15 @@ Estimate the MONTHLY cost in USD.
 5. **Lambda** — event-driven/cron, not for long-running servers (~$0-5/mo)
 6. **S3 + CloudFront** — static sites only, nearly free (~$1-3/mo)
 7. **Lightsail** — cheapest for simple apps (~$3.50-10/mo)
+8. **Lambda + API Gateway (lambda-apigw)** — small stateless HTTP APIs with low/bursty traffic (~$0-5/mo)
+`)
+		b.WriteString(lambdaArchitectOption(p, deep))
+		b.WriteString(`
+## EC2 Cost Options
+If you pick ec2, also list cheaper shapes of it under "alternatives" with "instanceType", "spot" and "costDelta":
+- Graviton (t4g, m6g, c6g, r6g) is ~20% cheaper than the x86 equivalent. Only offer it when the image can run on linux/arm64 (no amd64 --platform pin, no x86-only native deps).
+- Spot is typically ~60% cheaper but can be reclaimed with two minutes' notice. Only offer it for stateless, fault-tolerant apps (no local database files, volumes or in-memory sessions).
+Keep "cpuMemory" the on-demand pick; alternatives are suggestions.
 
 ## Load Balancer / API Gateway Decision
 Choose based on the application type:
@@ -1153,7 +1468,8 @@ Break it down by service (compute, storage, networking, database).
   "reasoning": "User requested EC2 deployment. This is a Dockerized Node.js app that will run well on a t3.small instance with docker compose.",
   "alternatives": [
     {"method": "ecs-fargate", "why_not": "User prefers EC2 for direct control"},
-    {"method": "app-runner", "why_not": "User explicitly requested EC2"}
+    {"method": "app-runner", "why_not": "User explicitly requested EC2"},
+    {"method": "ec2", "instanceType": "t4g.small", "why_not": "Graviton: needs a linux/arm64 image", "estMonthly": "$12.26/mo", "costDelta": "-$2.92/mo (-19%) vs t3.small on-demand"}
   ],
   "buildSteps": [
     "Create EC2 instance with Docker pre-installed",
@@ -1240,8 +1556,14 @@ func buildValidationPrompt(planJSON string, p *RepoProfile, deep *DeepAnalysis,
   "isValid": false,
   "issues": ["Security group doesn't allow port 18789", "Missing ECR login before push"],
   "fixes": ["Add inbound rule for port 18789", "Add ecr get-login-password command before docker push"],
-  "warnings": ["No health check configured — service may restart unnecessarily"]
-}`)
+  "warnings": ["No health check configured — service may restart unnecessarily"],
+  "issueDetails": [
+    {"message": "Security group doesn't allow port 18789", "severity": "error", "fix": "Add inbound rule for port 18789"},
+    {"message": "Missing ECR login before push", "severity": "error", "fix": "Add ecr get-login-password command before docker push"},
+    {"message": "No health check configured — service may restart unnecessarily", "severity": "warning"}
+  ]
+}
+Severity: "critical" = secrets inlined or data loss, "error" = deploy will fail, "warning" = works but suboptimal, "info" = cosmetic (naming, tags). Only critical/error block the deploy.`)
 
 	return b.String()
 }
@@ -1269,6 +1591,10 @@ func parseValidation(raw string) (*PlanValidation, error) {
 			for _, it := range items {
 				if s, ok := it["issue"].(string); ok && strings.TrimSpace(s) != "" {
 					out.Issues = append(out.Issues, strings.TrimSpace(s))
+					if sev, ok := it["severity"].(string); ok {
+						fix, _ := it["fix"].(string)
+						out.IssueDetails = append(out.IssueDetails, ValidationIssue{Message: strings.TrimSpace(s), Severity: ValidationSeverity(sev), Fix: strings.TrimSpace(fix)})
+					}
 				}
 				if s, ok := it["fix"].(string); ok && strings.TrimSpace(s) != "" {
 					out.Fixes = append(out.Fixes, strings.TrimSpace(s))
@@ -1293,8 +1619,18 @@ func parseValidation(raw string) (*PlanValidation, error) {
 func buildFixPrompt(v *PlanValidation) string {
 	var b strings.Builder
 	b.WriteString("\n\n## CRITICAL: Fix these issues from the previous plan\n")
-	for _, issue := range v.Issues {
-		b.WriteString(fmt.Sprintf("- ISSUE: %s\n", issue))
+	if len(v.IssueDetails) > 0 {
+		// IssueDetails is ordered critical first; fix those before anything else.
+		for _, issue := range v.IssueDetails {
+			if !issue.Blocking() {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("- ISSUE [%s]: %s\n", strings.ToUpper(string(issue.Severity)), issue.Message))
+		}
+	} else {
+		for _, issue := range v.Issues {
+			b.WriteString(fmt.Sprintf("- ISSUE: %s\n", issue))
+		}
 	}
 	for _, fix := range v.Fixes {
 		b.WriteString(fmt.Sprintf("- FIX: %s\n", fix))
@@ -1426,6 +1762,9 @@ func buildIntelligentPrompt(p *RepoProfile, deep *DeepAnalysis, docker *DockerAn
 	if p.IsMonorepo {
 		b.WriteString("- Monorepo: yes\n")
 	}
+	if p.SubPath != "" {
+		b.WriteString(fmt.Sprintf("- Workspace: deploy only %s (build from the repo root with a workspace filter if it depends on shared packages)\n", p.SubPath))
+	}
 	if p.HasDocker {
 		b.WriteString("- Dockerfile: YES — use it for the build, do NOT create a new one\n")
 	}
@@ -1458,6 +1797,7 @@ func buildIntelligentPrompt(p *RepoProfile, deep *DeepAnalysis, docker *DockerAn
 			b.WriteString(fmt.Sprintf("- Prefer Docker runtime command: %s\n", docker.RunCommand))
 		}
 	}
+	b.WriteString(nativeDepsPromptSection(p))
 	AppendOpenClawDeploymentRequirements(&b, p, deep, strat.Provider)
 	AppendWordPressDeploymentRequirements(&b, p, deep)
 	if pf := BuildPreflightReport(p, docker, deep); pf != nil {
@@ -1471,6 +1811,7 @@ func buildIntelligentPrompt(p *RepoProfile, deep *DeepAnalysis, docker *DockerAn
 			b.WriteString("- If this is an SPA, configure routing so deep links work (e.g. CloudFront custom error response 404->200 /index.html, or platform-specific redirect rules).\n")
 		}
 	}
+	b.WriteString(formatMigrationPlanForPrompt(deep.MigrationPlan))
 	if len(p.BootstrapScripts) > 0 {
 		b.WriteString("\n## Bootstrap Scripts (If deploying on a VM)\n")
 		b.WriteString("- This repo includes bootstrap/onboarding scripts. If the workload depends on them, run them BEFORE starting services.\n")
@@ -1500,6 +1841,8 @@ func buildIntelligentPrompt(p *RepoProfile, deep *DeepAnalysis, docker *DockerAn
 		b.WriteString(s3CloudfrontIntelligentPrompt(p))
 	case "lightsail":
 		b.WriteString(lightsailPrompt(p, arch, opts))
+	case "lambda-apigw":
+		b.WriteString(lambdaAPIGatewayPrompt(p, arch, deep, opts))
 	case "cf-pages":
 		b.WriteString(cfPagesPrompt(p, deep, opts))
 	case "cf-workers":
@@ -1512,6 +1855,10 @@ func buildIntelligentPrompt(p *RepoProfile, deep *DeepAnalysis, docker *DockerAn
 		b.WriteString(azureVMPrompt(p, deep, opts))
 	case "do-droplet":
 		b.WriteString(doDropletPrompt(p, deep, opts))
+	case "do-app-platform":
+		b.WriteString(doAppPlatformPrompt(p, opts))
+	case "do-k8s":
+		b.WriteString(doK8sPrompt(p, opts))
 	default:
 		switch strings.ToLower(strings.TrimSpace(strat.Provider)) {
 		case "cloudflare":
@@ -1562,6 +1909,7 @@ func buildIntelligentPrompt(p *RepoProfile, deep *DeepAnalysis, docker *DockerAn
 		b.WriteString("- All commands use npx wrangler CLI\n")
 		b.WriteString("- Auth via CLOUDFLARE_API_TOKEN env var (already set)\n")
 		b.WriteString(fmt.Sprintf("- Name projects/resources with prefix %s\n", resourcePrefix))
+		b.WriteString("- Reuse existing KV namespaces, D1 databases and R2 buckets listed above when their names match; never create a duplicate\n")
 		b.WriteString("- Prefer free tier where possible\n")
 		b.WriteString("- The plan must be fully executable with npx wrangler commands only\n")
 		b.WriteString("- Commands must be in the correct dependency order\n")
@@ -1578,6 +1926,11 @@ func buildIntelligentPrompt(p *RepoProfile, deep *DeepAnalysis, docker *DockerAn
 		b.WriteString("- Persist state/workspace on managed disk\n")
 		b.WriteString("- Commands must be in dependency order\n")
 		b.WriteString(fmt.Sprintf("- Name resources with prefix %s\n", resourcePrefix))
+	case "digitalocean":
+		b.WriteString("- The plan must be fully executable with doctl CLI only\n")
+		b.WriteString("- Prefer the smallest Droplet or App Platform instance size that fits\n")
+		b.WriteString("- Commands must be in dependency order\n")
+		b.WriteString(fmt.Sprintf("- Name resources with prefix %s\n", resourcePrefix))
 	default:
 		b.WriteString("- Use the default VPC and its existing subnets when possible\n")
 		b.WriteString(fmt.Sprintf("- Name resources with prefix %s\n", resourcePrefix))
@@ -1586,6 +1939,10 @@ func buildIntelligentPrompt(p *RepoProfile, deep *DeepAnalysis, docker *DockerAn
 		b.WriteString("- The plan must be fully executable with AWS CLI only\n")
 		b.WriteString("- Commands must be in the correct dependency order\n")
 		b.WriteString("- Every resource that's referenced must be created first\n")
+		if partition := deployPartition(opts); partition != awsclient.PartitionAWS {
+			b.WriteString(fmt.Sprintf("- This account is in the %s partition: every ARN must start with arn:%s: (e.g. %s), and services not offered there (such as App Runner or Lightsail) must not be used\n",
+				partition, partition, awsclient.ManagedPolicyARN(partition, "service-role/AWSLambdaBasicExecutionRole")))
+		}
 	}
 
 	return b.String()
@@ -1600,8 +1957,14 @@ func smartECSPrompt(p *RepoProfile, arch *ArchitectDecision, deep *DeepAnalysis,
 		deployID = opts.DeployID
 	}
 	resourcePrefix := repoResourcePrefix(p.RepoURL, deployID)
+	names := newAWSResourceNames(resourcePrefix)
 	b.WriteString("Deploy using ECS Fargate (serverless containers):\n")
-	b.WriteString(fmt.Sprintf("Naming: use prefix %s for ECR repo, cluster, service, security group, and ALB (if used)\n", resourcePrefix))
+	b.WriteString(fmt.Sprintf("Naming (use exactly, the rollback plan depends on them): ECR repo %s, cluster %s, service %s, task family %s, execution role %s, log group %s, service security group %s",
+		names.ECRRepo, names.Cluster, names.Service, names.TaskFamily, names.TaskExecRole, names.ECSLogGroup, names.ECSSG))
+	if arch.NeedsALB {
+		b.WriteString(fmt.Sprintf(", ALB %s, ALB security group %s, target group %s", names.ALB, names.ALBSG, names.TargetGroup))
+	}
+	b.WriteString("\n")
 	b.WriteString("1. Create an ECR repository\n")
 
 	if p.HasDocker {
@@ -1730,8 +2093,9 @@ func appRunnerPrompt(p *RepoProfile, arch *ArchitectDecision, opts *DeployOption
 		deployID = opts.DeployID
 	}
 	resourcePrefix := repoResourcePrefix(p.RepoURL, deployID)
+	names := newAWSResourceNames(resourcePrefix)
 	b.WriteString("Deploy using AWS App Runner (simplest container hosting):\n")
-	b.WriteString(fmt.Sprintf("Naming: use prefix %s for ECR repo + App Runner service\n", resourcePrefix))
+	b.WriteString(fmt.Sprintf("Naming: ECR repo %s, App Runner service %s\n", names.ECRRepo, names.AppRunnerService))
 
 	if p.HasDocker {
 		b.WriteString("1. Create ECR repository, build and push Docker image\n")
@@ -1767,7 +2131,7 @@ func lightsailPrompt(p *RepoProfile, arch *ArchitectDecision, opts *DeployOption
 	}
 	resourcePrefix := repoResourcePrefix(p.RepoURL, deployID)
 	b.WriteString("Deploy using AWS Lightsail (cheapest option, $3.50/mo):\n")
-	b.WriteString(fmt.Sprintf("Naming: use prefix %s for the Lightsail service/container\n", resourcePrefix))
+	b.WriteString(fmt.Sprintf("Naming: Lightsail container service %s\n", newAWSResourceNames(resourcePrefix).LightsailService))
 	b.WriteString("1. Create a Lightsail container service (nano plan)\n")
 
 	if p.HasDocker {
@@ -1947,23 +2311,25 @@ func ec2Prompt(p *RepoProfile, arch *ArchitectDecision, deep *DeepAnalysis, opts
 	projectTag := resourcePrefix
 
 	// Tag values have generous limits; some AWS resource names do not.
-	vpcTagName := awsName(resourcePrefix, "-vpc", 128)
-	subnet1aTagName := awsName(resourcePrefix, "-public-1a", 128)
-	subnet1bTagName := awsName(resourcePrefix, "-public-1b", 128)
-	igwTagName := awsName(resourcePrefix, "-igw", 128)
-	rtTagName := awsName(resourcePrefix, "-public-rt", 128)
+	// The rollback plan derives the same names.
+	names := newAWSResourceNames(resourcePrefix)
+	vpcTagName := names.VPC
+	subnet1aTagName := names.Subnet1a
+	subnet1bTagName := names.Subnet1b
+	igwTagName := names.IGW
+	rtTagName := names.RouteTable
 
-	albSGName := awsName(resourcePrefix, "-alb-sg", 255)
-	ec2SGName := awsName(resourcePrefix, "-ec2-sg", 255)
+	albSGName := names.ALBSG
+	ec2SGName := names.EC2SG
 
-	roleName := awsName(resourcePrefix, "-ec2-role", 64)
-	profileName := awsName(resourcePrefix, "-ec2-profile", 128)
+	roleName := names.EC2Role
+	profileName := names.EC2Profile
 
-	ecrRepoName := awsName(resourcePrefix, "", 256)
+	ecrRepoName := names.ECRRepo
 	localImageName := awsName(resourcePrefix, "", 128)
-	instanceName := awsName(resourcePrefix, "", 128)
-	albName := awsName(resourcePrefix, "-alb", 32)
-	tgName := awsName(resourcePrefix, "-tg", 32)
+	instanceName := names.Instance
+	albName := names.ALB
+	tgName := names.TargetGroup
 
 	instanceType := "t3.small"
 	if opts != nil && opts.InstanceType != "" {
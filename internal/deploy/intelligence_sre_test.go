@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 	"testing"
+
+	"github.com/bgdnvk/clanker/internal/notify"
 )
 
 func TestRunIntelligenceSREOnlySkipsLLMAndInfraScan(t *testing.T) {
@@ -52,3 +54,35 @@ func TestRunIntelligenceSREOnlySkipsLLMAndInfraScan(t *testing.T) {
 		t.Fatalf("expected conservative SRE interval in prompt: %s", result.EnrichedPrompt)
 	}
 }
+
+type recordingNotifier struct{ sent []notify.Notification }
+
+func (r *recordingNotifier) Notify(_ context.Context, n notify.Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func TestRunIntelligenceNotifies(t *testing.T) {
+	n := &recordingNotifier{}
+	profile := &RepoProfile{RepoURL: "https://github.com/o/r", KeyFiles: map[string]string{}}
+	noLLM := func(context.Context, string) (string, error) { return "", nil }
+	clean := func(s string) string { return s }
+
+	if _, err := RunIntelligence(context.Background(), profile, noLLM, clean, false, "aws", "p", "us-east-1",
+		&DeployOptions{SREOnly: true, Notifier: n}, func(string, ...any) {}); err != nil {
+		t.Fatalf("RunIntelligence: %v", err)
+	}
+	if len(n.sent) != 1 || n.sent[0].Failed || !strings.HasPrefix(n.sent[0].Summary, "https://github.com/o/r → aws ") {
+		t.Fatalf("sent = %+v", n.sent)
+	}
+
+	// A monorepo path that does not exist fails before any LLM call.
+	_, err := RunIntelligence(context.Background(), profile, noLLM, clean, false, "digitalocean", "", "",
+		&DeployOptions{SubPath: "missing", Notifier: n}, func(string, ...any) {})
+	if err == nil {
+		t.Fatal("expected an error for a missing sub-path")
+	}
+	if len(n.sent) != 2 || !n.sent[1].Failed {
+		t.Errorf("failure was not notified: %+v", n.sent)
+	}
+}
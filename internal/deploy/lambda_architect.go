@@ -0,0 +1,116 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lambdaColdStartNote is added to a lambda-apigw decision whose notes do not
+// already mention cold starts.
+const lambdaColdStartNote = "Cold starts: the first request after idle can take 100ms-several seconds (longer for JVM or container-image functions); use provisioned concurrency if latency matters"
+
+// lambdaAPIGatewaySuitable reports whether the repo looks like a small pure
+// API with no persistent state, the shape Lambda + an HTTP API serves well.
+func lambdaAPIGatewaySuitable(p *RepoProfile, deep *DeepAnalysis) bool {
+	if p == nil || p.IsStaticSite || p.HasDB || doLooksStateful(p) {
+		return false
+	}
+	if !shouldUseAPIGateway(p, deep, nil) {
+		return false
+	}
+	if deep != nil {
+		if strings.EqualFold(strings.TrimSpace(deep.Complexity), "complex") {
+			return false
+		}
+		if len(deep.Services) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeAWSMethod maps the serverless spellings the architect tends to
+// return onto lambda-apigw. Other AWS methods pass through unchanged.
+func normalizeAWSMethod(method string) string {
+	switch strings.ToLower(strings.TrimSpace(method)) {
+	case "lambda-apigw", "lambda-api-gateway", "lambda+apigw", "lambda+api-gateway",
+		"lambda-http-api", "apigw-lambda", "serverless-api":
+		return "lambda-apigw"
+	default:
+		return method
+	}
+}
+
+// ApplyLambdaAPIGatewayDefaults fills in what a lambda-apigw decision always
+// implies: API Gateway in front, no ALB, and a cold-start caveat in notes.
+func ApplyLambdaAPIGatewayDefaults(arch *ArchitectDecision) {
+	if arch == nil || arch.Method != "lambda-apigw" {
+		return
+	}
+	arch.UseAPIGateway = true
+	arch.NeedsALB = false
+	for _, note := range arch.Notes {
+		if strings.Contains(strings.ToLower(note), "cold start") {
+			return
+		}
+	}
+	arch.Notes = append(arch.Notes, lambdaColdStartNote)
+}
+
+// lambdaArchitectOption is the lambda-apigw entry of the AWS architect prompt.
+func lambdaArchitectOption(p *RepoProfile, deep *DeepAnalysis) string {
+	var b strings.Builder
+	b.WriteString(`
+## Serverless API (method "lambda-apigw")
+Lambda function behind an API Gateway HTTP API, pay-per-request (~$0-5/mo at low traffic).
+- Prefer it for small pure-API apps with no persistent state (no local disk, no database it owns) and low or bursty traffic
+- Avoid it for WebSockets, long-running requests (>29s API Gateway limit), background workers, or steady high traffic
+- Build steps: package the handler (zip, or container image with the Lambda Web Adapter for web frameworks) → create an execution role → create the function → create the HTTP API with the function as its integration → grant API Gateway invoke permission
+- Always add a cold-start caveat to "notes"
+`)
+	if lambdaAPIGatewaySuitable(p, deep) {
+		b.WriteString("- Heuristic: this repo looks like a small stateless API; lambda-apigw is a strong candidate\n")
+	}
+	return b.String()
+}
+
+func lambdaAPIGatewayPrompt(p *RepoProfile, arch *ArchitectDecision, deep *DeepAnalysis, opts *DeployOptions) string {
+	var b strings.Builder
+	deployID := ""
+	if opts != nil {
+		deployID = opts.DeployID
+	}
+	resourcePrefix := repoResourcePrefix(p.RepoURL, deployID)
+	port := 8080
+	if len(p.Ports) > 0 {
+		port = p.Ports[0]
+	}
+
+	b.WriteString("Deploy using AWS Lambda + API Gateway HTTP API (serverless, pay-per-request):\n")
+	names := newAWSResourceNames(resourcePrefix)
+	b.WriteString(fmt.Sprintf("Naming: function %s, execution role %s, ECR repo %s, HTTP API %s\n", names.Function, names.LambdaRole, names.ECRRepo, names.HTTPAPI))
+	if p.HasDocker {
+		b.WriteString("1. Package the handler as a container image: create an ECR repository, add the AWS Lambda Web Adapter to the image ")
+		b.WriteString(fmt.Sprintf("(COPY --from=public.ecr.aws/awsguru/aws-lambda-adapter /lambda-adapter /opt/extensions/lambda-adapter, AWS_LWA_PORT=%d), build and push\n", port))
+	} else {
+		b.WriteString("1. Package the handler as a zip: install production dependencies, bundle the code, and use the Lambda Web Adapter layer ")
+		b.WriteString(fmt.Sprintf("(AWS_LWA_PORT=%d) if the app is a regular web server rather than a native Lambda handler\n", port))
+	}
+	b.WriteString("2. Create an IAM execution role trusted by lambda.amazonaws.com with AWSLambdaBasicExecutionRole attached\n")
+
+	memory := "512"
+	if arch != nil && strings.TrimSpace(arch.CpuMemory) != "" {
+		// Lambda only takes memory; keep the memory half of "cpu/memory".
+		parts := strings.Split(arch.CpuMemory, "/")
+		memory = strings.TrimSpace(parts[len(parts)-1])
+	}
+	b.WriteString(fmt.Sprintf("3. Create the function (aws lambda create-function) with memory %s MB, timeout 29s, and the app's env vars; wait for it to become Active\n", memory))
+	b.WriteString("4. Create the HTTP API (aws apigatewayv2 create-api --protocol-type HTTP --target <function-arn>) so a $default route and stage proxy every request to the function\n")
+	b.WriteString("5. Grant API Gateway permission to invoke the function (aws lambda add-permission --principal apigateway.amazonaws.com --source-arn for the API)\n")
+	b.WriteString("6. Verify: curl the API endpoint")
+	if deep != nil && deep.HealthEndpoint != "" {
+		b.WriteString(" + " + deep.HealthEndpoint)
+	}
+	b.WriteString(" and expect a 2xx; the first call may be slow because of a cold start\n")
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLambdaAPIGatewaySuitable(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *RepoProfile
+		deep    *DeepAnalysis
+		want    bool
+	}{
+		{"small fastapi service", &RepoProfile{Framework: "fastapi", Ports: []int{8000}}, &DeepAnalysis{Complexity: "simple"}, true},
+		{"owns a database", &RepoProfile{Framework: "express", HasDB: true}, nil, false},
+		{"sqlite is stateful", &RepoProfile{Framework: "flask", DBType: "sqlite"}, nil, false},
+		{"frontend app", &RepoProfile{Framework: "nextjs"}, nil, false},
+		{"complex app", &RepoProfile{Framework: "gin"}, &DeepAnalysis{Complexity: "complex"}, false},
+		{"websocket service", &RepoProfile{Framework: "express"}, &DeepAnalysis{Services: []string{"websocket server"}}, false},
+	}
+	for _, tt := range tests {
+		if got := lambdaAPIGatewaySuitable(tt.profile, tt.deep); got != tt.want {
+			t.Errorf("%s: lambdaAPIGatewaySuitable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStrategyFromArchitect_LambdaAPIGateway(t *testing.T) {
+	for _, method := range []string{"lambda-apigw", "lambda-api-gateway", "Lambda+APIGW", "serverless-api"} {
+		strat := StrategyFromArchitect(&ArchitectDecision{Provider: "aws", Method: method})
+		if strat.Method != "lambda-apigw" {
+			t.Errorf("StrategyFromArchitect(aws, %q).Method = %q, want lambda-apigw", method, strat.Method)
+		}
+	}
+	if strat := StrategyFromArchitect(&ArchitectDecision{Provider: "aws", Method: "lambda"}); strat.Method != "lambda" {
+		t.Errorf("plain lambda should pass through, got %q", strat.Method)
+	}
+}
+
+func TestApplyLambdaAPIGatewayDefaults(t *testing.T) {
+	arch := &ArchitectDecision{Method: "lambda-apigw", NeedsALB: true}
+	ApplyLambdaAPIGatewayDefaults(arch)
+	if !arch.UseAPIGateway || arch.NeedsALB {
+		t.Errorf("expected API Gateway without ALB, got useApiGateway=%v needsAlb=%v", arch.UseAPIGateway, arch.NeedsALB)
+	}
+	if len(arch.Notes) != 1 || !strings.Contains(arch.Notes[0], "Cold starts") {
+		t.Errorf("expected a cold-start note, got %v", arch.Notes)
+	}
+
+	ApplyLambdaAPIGatewayDefaults(arch)
+	if len(arch.Notes) != 1 {
+		t.Errorf("cold-start note should not be duplicated, got %v", arch.Notes)
+	}
+}
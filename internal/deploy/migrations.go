@@ -0,0 +1,124 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MigrationPlan describes how the app's database schema gets created/upgraded.
+type MigrationPlan struct {
+	Tool    string `json:"tool"`            // prisma, alembic, flyway, drizzle, goose, knex, django, rails, custom
+	Command string `json:"command"`         // e.g. "npx prisma migrate deploy", "alembic upgrade head"
+	Mode    string `json:"mode"`            // "job" (one-time before start) or "on_boot" (entrypoint runs it)
+	Notes   string `json:"notes,omitempty"` // anything the deployer must know (needs DATABASE_URL, etc)
+}
+
+const (
+	MigrationModeJob    = "job"
+	MigrationModeOnBoot = "on_boot"
+)
+
+// migrationToolDefaults maps a migration hint to a tool and its deploy command.
+var migrationToolDefaults = []struct {
+	hint    string
+	tool    string
+	command string
+}{
+	{"prisma", "prisma", "npx prisma migrate deploy"},
+	{"alembic", "alembic", "alembic upgrade head"},
+	{"flyway", "flyway", "flyway migrate"},
+	{"drizzle", "drizzle", "npx drizzle-kit migrate"},
+	{"goose", "goose", "goose up"},
+}
+
+// ensureMigrationPlan fills deep.MigrationPlan from static migration hints when
+// the deep analysis did not produce one, and normalizes the mode.
+func ensureMigrationPlan(p *RepoProfile, deep *DeepAnalysis) {
+	if deep == nil {
+		return
+	}
+	if deep.MigrationPlan != nil && strings.TrimSpace(deep.MigrationPlan.Command) != "" {
+		deep.MigrationPlan.Mode = normalizeMigrationMode(deep.MigrationPlan.Mode)
+		return
+	}
+	deep.MigrationPlan = inferMigrationPlan(p)
+}
+
+// inferMigrationPlan derives a best-effort MigrationPlan from MigrationHints.
+func inferMigrationPlan(p *RepoProfile) *MigrationPlan {
+	if p == nil || len(p.MigrationHints) == 0 {
+		return nil
+	}
+	joined := strings.ToLower(strings.Join(p.MigrationHints, " "))
+	for _, d := range migrationToolDefaults {
+		if strings.Contains(joined, d.hint) {
+			return &MigrationPlan{Tool: d.tool, Command: d.command, Mode: MigrationModeJob}
+		}
+	}
+	command := "npm run migrate"
+	switch strings.ToLower(p.Language) {
+	case "python":
+		command = "python manage.py migrate"
+	case "go":
+		command = "migrate -path migrations -database $DATABASE_URL up"
+	}
+	return &MigrationPlan{
+		Tool:    "custom",
+		Command: command,
+		Mode:    MigrationModeJob,
+		Notes:   "Migration files detected (" + strings.Join(p.MigrationHints, ", ") + "); confirm the exact command from the README",
+	}
+}
+
+func normalizeMigrationMode(mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "on_boot", "on-boot", "onboot", "boot", "entrypoint":
+		return MigrationModeOnBoot
+	default:
+		return MigrationModeJob
+	}
+}
+
+// formatMigrationPlanForPrompt renders the explicit migration step for the
+// plan-generation prompt.
+func formatMigrationPlanForPrompt(m *MigrationPlan) string {
+	if m == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n## Database Migrations (REQUIRED)\n")
+	b.WriteString(fmt.Sprintf("- Tool: %s\n", valueOrDefault(m.Tool, "custom")))
+	b.WriteString(fmt.Sprintf("- Command: %s\n", m.Command))
+	if m.Mode == MigrationModeOnBoot {
+		b.WriteString("- The app runs migrations on boot; make sure the database is reachable and DATABASE_URL (or equivalent) is set before the container starts.\n")
+	} else {
+		b.WriteString("- Run migrations before first request: execute the command once after the database exists and before the app starts serving traffic (one-time job, ECS run-task, or a user-data step before the app starts).\n")
+	}
+	if m.Notes != "" {
+		b.WriteString(fmt.Sprintf("- Notes: %s\n", m.Notes))
+	}
+	return b.String()
+}
+
+// planHasMigrationStep reports whether any plan text runs the migration.
+func planHasMigrationStep(planJSON string, m *MigrationPlan) bool {
+	lower := strings.ToLower(planJSON)
+	if m != nil {
+		if cmd := strings.ToLower(strings.TrimSpace(m.Command)); cmd != "" && strings.Contains(lower, cmd) {
+			return true
+		}
+	}
+	for _, marker := range []string{"migrate", "alembic upgrade", "flyway", "goose up", "db:migrate", "db-migrate"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueOrDefault(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
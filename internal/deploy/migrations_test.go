@@ -0,0 +1,69 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnsureMigrationPlan_InfersFromHints(t *testing.T) {
+	p := &RepoProfile{Language: "node", MigrationHints: []string{"prisma/migrations", "package.json:scripts/deps(migrate)"}}
+	deep := &DeepAnalysis{}
+	ensureMigrationPlan(p, deep)
+	if deep.MigrationPlan == nil || deep.MigrationPlan.Command != "npx prisma migrate deploy" || deep.MigrationPlan.Mode != MigrationModeJob {
+		t.Fatalf("unexpected migration plan: %+v", deep.MigrationPlan)
+	}
+
+	deep = &DeepAnalysis{MigrationPlan: &MigrationPlan{Tool: "alembic", Command: "alembic upgrade head", Mode: "entrypoint"}}
+	ensureMigrationPlan(p, deep)
+	if deep.MigrationPlan.Command != "alembic upgrade head" || deep.MigrationPlan.Mode != MigrationModeOnBoot {
+		t.Errorf("expected LLM plan to be kept with normalized mode, got %+v", deep.MigrationPlan)
+	}
+
+	deep = &DeepAnalysis{}
+	ensureMigrationPlan(&RepoProfile{}, deep)
+	if deep.MigrationPlan != nil {
+		t.Errorf("expected no migration plan without hints, got %+v", deep.MigrationPlan)
+	}
+}
+
+func TestRunDeterministicPlanValidation_RequiresMigrationStep(t *testing.T) {
+	p := &RepoProfile{MigrationHints: []string{"alembic.ini"}}
+	deep := &DeepAnalysis{MigrationPlan: &MigrationPlan{Tool: "alembic", Command: "alembic upgrade head", Mode: MigrationModeJob}}
+
+	without := `{"provider":"aws","commands":[{"args":["ecs","create-service","--service-name","api"]}]}`
+	out := runDeterministicPlanValidation(without, p, deep, nil, nil)
+	if !containsIssue(out.Issues, "migrations detected") {
+		t.Fatalf("expected missing migration issue, got %v", out.Issues)
+	}
+
+	with := `{"provider":"aws","commands":[{"args":["ecs","run-task","--overrides","{\"containerOverrides\":[{\"command\":[\"alembic\",\"upgrade\",\"head\"]}]}"]},{"args":["ecs","create-service","--service-name","api","--note","alembic upgrade head"]}]}`
+	out = runDeterministicPlanValidation(with, p, deep, nil, nil)
+	if containsIssue(out.Issues, "migrations detected") {
+		t.Errorf("did not expect migration issue, got %v", out.Issues)
+	}
+
+	deep.MigrationPlan.Mode = MigrationModeOnBoot
+	out = runDeterministicPlanValidation(without, p, deep, nil, nil)
+	if containsIssue(out.Issues, "migrations detected") {
+		t.Errorf("on_boot migrations should not require a plan step, got %v", out.Issues)
+	}
+}
+
+func containsIssue(issues []string, substr string) bool {
+	for _, issue := range issues {
+		if strings.Contains(issue, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFormatMigrationPlanForPrompt(t *testing.T) {
+	got := formatMigrationPlanForPrompt(&MigrationPlan{Tool: "prisma", Command: "npx prisma migrate deploy", Mode: MigrationModeJob})
+	if !strings.Contains(got, "Run migrations before first request") || !strings.Contains(got, "npx prisma migrate deploy") {
+		t.Errorf("unexpected prompt section:\n%s", got)
+	}
+	if formatMigrationPlanForPrompt(nil) != "" {
+		t.Error("expected empty section for nil plan")
+	}
+}
@@ -0,0 +1,312 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// nativeDepMarker maps a dependency mention in a manifest to the native dep
+// it implies. A marker is ignored when the manifest also mentions unless
+// (e.g. psycopg2-binary ships its own libpq).
+type nativeDepMarker struct {
+	file   string
+	marker string
+	dep    string
+	unless string
+}
+
+var nativeDepMarkers = []nativeDepMarker{
+	// Node
+	{file: "package.json", marker: "node-gyp", dep: "node-gyp"},
+	{file: "package.json", marker: "sharp", dep: "sharp"},
+	{file: "package.json", marker: "playwright", dep: "playwright"},
+	{file: "package.json", marker: "puppeteer", dep: "puppeteer"},
+	{file: "package.json", marker: "canvas", dep: "canvas"},
+	{file: "package.json", marker: "better-sqlite3", dep: "better-sqlite3"},
+	{file: "package.json", marker: "sqlite3", dep: "sqlite3"},
+	{file: "package.json", marker: "bcrypt", dep: "bcrypt"},
+	{file: "package.json", marker: `"grpc"`, dep: "grpc"}, // the legacy package, not @grpc/*
+	{file: "package.json", marker: "@grpc/grpc-js", dep: "@grpc/grpc-js"},
+	{file: "package.json", marker: "ffi-napi", dep: "ffi-napi"},
+	{file: "package.json", marker: "ref-napi", dep: "ref-napi"},
+	{file: "package.json", marker: "pg-native", dep: "libpq"},
+	{file: "package.json", marker: "fluent-ffmpeg", dep: "ffmpeg"},
+	{file: "package.json", marker: `"gm"`, dep: "imagemagick"},
+
+	// Python
+	{file: "requirements.txt", marker: "psycopg2", dep: "libpq", unless: "psycopg2-binary"},
+	{file: "pyproject.toml", marker: "psycopg2", dep: "libpq", unless: "psycopg2-binary"},
+	{file: "requirements.txt", marker: "mysqlclient", dep: "mysqlclient"},
+	{file: "pyproject.toml", marker: "mysqlclient", dep: "mysqlclient"},
+	{file: "requirements.txt", marker: "pillow", dep: "pillow"},
+	{file: "pyproject.toml", marker: "pillow", dep: "pillow"},
+	{file: "requirements.txt", marker: "lxml", dep: "lxml"},
+	{file: "pyproject.toml", marker: "lxml", dep: "lxml"},
+	{file: "requirements.txt", marker: "cairo", dep: "cairo"},
+	{file: "pyproject.toml", marker: "cairo", dep: "cairo"},
+	{file: "requirements.txt", marker: "weasyprint", dep: "weasyprint"},
+	{file: "pyproject.toml", marker: "weasyprint", dep: "weasyprint"},
+	{file: "requirements.txt", marker: "opencv-python", dep: "opencv"},
+	{file: "pyproject.toml", marker: "opencv-python", dep: "opencv"},
+	{file: "requirements.txt", marker: "ffmpeg-python", dep: "ffmpeg"},
+	{file: "requirements.txt", marker: "moviepy", dep: "ffmpeg"},
+	{file: "requirements.txt", marker: "pydub", dep: "ffmpeg"},
+	{file: "pyproject.toml", marker: "ffmpeg-python", dep: "ffmpeg"},
+	{file: "pyproject.toml", marker: "moviepy", dep: "ffmpeg"},
+	{file: "pyproject.toml", marker: "pydub", dep: "ffmpeg"},
+
+	// Ruby
+	{file: "Gemfile", marker: `gem "pg"`, dep: "libpq"},
+	{file: "Gemfile", marker: `gem 'pg'`, dep: "libpq"},
+	{file: "Gemfile", marker: "mysql2", dep: "mysqlclient"},
+	{file: "Gemfile", marker: "nokogiri", dep: "nokogiri"},
+	{file: "Gemfile", marker: "rmagick", dep: "imagemagick"},
+}
+
+// nativeDepPackages is what one native dep needs from the OS. Apt lists are
+// for Debian-based images (-slim included), apk lists for Alpine.
+type nativeDepPackages struct {
+	Apt []string
+	Apk []string
+	// FullImage: too many shared libraries for -slim/-alpine to be practical.
+	FullImage bool
+	// NoAlpine: prebuilt binaries or wheels only exist for glibc.
+	NoAlpine bool
+	Note     string
+}
+
+var nativeBuildTools = nativeDepPackages{
+	Apt: []string{"python3", "make", "g++"},
+	Apk: []string{"python3", "make", "g++"},
+}
+
+// nativeDepCatalog covers the native deps that most often build locally but
+// break in a generated container.
+var nativeDepCatalog = map[string]nativeDepPackages{
+	"node-gyp": nativeBuildTools,
+	"bcrypt":   nativeBuildTools,
+	"grpc":     nativeBuildTools,
+	"better-sqlite3": {
+		Apt: []string{"python3", "make", "g++", "libsqlite3-dev"},
+		Apk: []string{"python3", "make", "g++", "sqlite-dev"},
+	},
+	"sqlite3": {
+		Apt: []string{"python3", "make", "g++", "libsqlite3-dev"},
+		Apk: []string{"python3", "make", "g++", "sqlite-dev"},
+	},
+	"ffi-napi": {
+		Apt: []string{"python3", "make", "g++", "libffi-dev"},
+		Apk: []string{"python3", "make", "g++", "libffi-dev"},
+	},
+	"ref-napi": {
+		Apt: []string{"python3", "make", "g++", "libffi-dev"},
+		Apk: []string{"python3", "make", "g++", "libffi-dev"},
+	},
+	"sharp": {
+		Apk:      []string{"vips-dev"},
+		NoAlpine: true,
+		Note:     "sharp ships prebuilt libvips for glibc; on Alpine it must compile against vips-dev",
+	},
+	"canvas": {
+		Apt:       []string{"build-essential", "libcairo2-dev", "libpango1.0-dev", "libjpeg-dev", "libgif-dev", "librsvg2-dev"},
+		Apk:       []string{"build-base", "cairo-dev", "pango-dev", "jpeg-dev", "giflib-dev", "librsvg-dev"},
+		FullImage: true,
+	},
+	"playwright": {
+		Apt:       []string{"libnss3", "libatk1.0-0", "libatk-bridge2.0-0", "libcups2", "libdrm2", "libxkbcommon0", "libxcomposite1", "libxdamage1", "libxrandr2", "libgbm1", "libasound2", "libpango-1.0-0", "libcairo2"},
+		FullImage: true,
+		NoAlpine:  true,
+		Note:      "run `npx playwright install --with-deps chromium` in the image, or start FROM mcr.microsoft.com/playwright",
+	},
+	"puppeteer": {
+		Apt:       []string{"chromium", "fonts-liberation", "libnss3", "libgbm1", "libasound2"},
+		Apk:       []string{"chromium", "nss", "freetype", "harfbuzz", "ttf-freefont"},
+		FullImage: true,
+		Note:      "set PUPPETEER_EXECUTABLE_PATH to the installed chromium and PUPPETEER_SKIP_DOWNLOAD=true",
+	},
+	"libpq": {
+		Apt:  []string{"libpq-dev", "gcc"},
+		Apk:  []string{"postgresql-dev", "gcc", "musl-dev"},
+		Note: "keep libpq5 (apt) or libpq (apk) in the runtime stage of a multi-stage build",
+	},
+	"mysqlclient": {
+		Apt: []string{"default-libmysqlclient-dev", "pkg-config", "build-essential"},
+		Apk: []string{"mariadb-dev", "pkgconf", "build-base"},
+	},
+	"pillow": {
+		Apt:      []string{"libjpeg62-turbo-dev", "zlib1g-dev", "libfreetype6-dev"},
+		Apk:      []string{"jpeg-dev", "zlib-dev", "freetype-dev", "build-base"},
+		NoAlpine: true,
+		Note:     "Pillow wheels cover glibc; on Alpine it compiles from source",
+	},
+	"lxml": {
+		Apt: []string{"libxml2-dev", "libxslt1-dev"},
+		Apk: []string{"libxml2-dev", "libxslt-dev", "build-base"},
+	},
+	"cairo": {
+		Apt: []string{"libcairo2-dev", "pkg-config"},
+		Apk: []string{"cairo-dev", "pkgconf", "build-base"},
+	},
+	"weasyprint": {
+		Apt:       []string{"libpango-1.0-0", "libpangoft2-1.0-0", "libharfbuzz0b", "fonts-dejavu-core"},
+		Apk:       []string{"pango", "font-dejavu"},
+		FullImage: true,
+	},
+	"opencv": {
+		Apt:      []string{"libgl1", "libglib2.0-0"},
+		NoAlpine: true,
+		Note:     "use opencv-python-headless on servers to avoid the GUI libraries",
+	},
+	"ffmpeg": {
+		Apt: []string{"ffmpeg"},
+		Apk: []string{"ffmpeg"},
+	},
+	"imagemagick": {
+		Apt: []string{"imagemagick", "libmagickwand-dev"},
+		Apk: []string{"imagemagick", "imagemagick-dev"},
+	},
+	"nokogiri": {
+		Apk:      []string{"build-base", "libxml2-dev", "libxslt-dev"},
+		NoAlpine: true,
+		Note:     "nokogiri ships precompiled gems for glibc; on Alpine it compiles against libxml2",
+	},
+}
+
+// nativeDepInstall is the combined OS package plan for a set of native deps.
+type nativeDepInstall struct {
+	Apt       []string
+	Apk       []string
+	FullImage []string // deps that need a non-slim, non-Alpine base image
+	NoAlpine  []string // deps that need a glibc base image
+	Notes     []string
+}
+
+// detectNativeDeps scans the Node, Python and Ruby manifests for
+// dependencies that need OS packages. Coarse substring matching is enough:
+// the goal is to warn the planner, not to resolve versions.
+func detectNativeDeps(dir string, p *RepoProfile) {
+	if p == nil {
+		return
+	}
+	contents := make(map[string]string)
+	seen := make(map[string]bool, len(p.NativeDeps))
+	for _, dep := range p.NativeDeps {
+		seen[dep] = true
+	}
+	for _, m := range nativeDepMarkers {
+		content, ok := contents[m.file]
+		if !ok {
+			data, err := os.ReadFile(filepath.Join(dir, m.file))
+			if err == nil {
+				content = strings.ToLower(string(data))
+			}
+			contents[m.file] = content
+		}
+		if content == "" || seen[m.dep] || !strings.Contains(content, m.marker) {
+			continue
+		}
+		// Skip when every mention of the marker is the unless variant.
+		if m.unless != "" && strings.Count(content, m.marker) == strings.Count(content, m.unless) {
+			continue
+		}
+		seen[m.dep] = true
+		p.NativeDeps = append(p.NativeDeps, m.dep)
+	}
+}
+
+// nativeDepsToPackages translates detected native deps into apt and apk
+// package lists, deduplicated in detection order. Deps without a catalog
+// entry (e.g. pure-JS @grpc/grpc-js) contribute nothing.
+func nativeDepsToPackages(deps []string) nativeDepInstall {
+	var out nativeDepInstall
+	seenApt := make(map[string]bool)
+	seenApk := make(map[string]bool)
+	for _, dep := range deps {
+		pkgs, ok := nativeDepCatalog[dep]
+		if !ok {
+			continue
+		}
+		for _, pkg := range pkgs.Apt {
+			if !seenApt[pkg] {
+				seenApt[pkg] = true
+				out.Apt = append(out.Apt, pkg)
+			}
+		}
+		for _, pkg := range pkgs.Apk {
+			if !seenApk[pkg] {
+				seenApk[pkg] = true
+				out.Apk = append(out.Apk, pkg)
+			}
+		}
+		if pkgs.FullImage {
+			out.FullImage = append(out.FullImage, dep)
+		}
+		if pkgs.NoAlpine {
+			out.NoAlpine = append(out.NoAlpine, dep)
+		}
+		if pkgs.Note != "" {
+			out.Notes = append(out.Notes, pkgs.Note)
+		}
+	}
+	return out
+}
+
+// adjustBaseImageForNativeDeps moves a -slim or -alpine base image to a
+// fuller variant when a detected dep needs one: the full Debian tag for
+// FullImage deps, the Debian tag instead of -alpine for glibc-only deps.
+// Tags are adjusted part by part, so distro suffixes survive:
+// 3.12-slim-bookworm becomes 3.12-bookworm and 22-alpine3.20 becomes 22.
+func adjustBaseImageForNativeDeps(image string, deps []string) string {
+	install := nativeDepsToPackages(deps)
+	dropSlim := len(install.FullImage) > 0
+	dropAlpine := dropSlim || len(install.NoAlpine) > 0
+	i := strings.LastIndex(image, ":")
+	if !dropAlpine || i < 0 || strings.ContainsAny(image[i:], "/@") {
+		return image
+	}
+	parts := strings.Split(image[i+1:], "-")
+	kept := parts[:1]
+	for _, part := range parts[1:] {
+		if (dropSlim && part == "slim") || (dropAlpine && strings.HasPrefix(part, "alpine")) {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	return image[:i+1] + strings.Join(kept, "-")
+}
+
+// nativeDepsPromptSection turns the profile's native deps into explicit
+// install steps for the generated Dockerfile or VM bootstrap.
+func nativeDepsPromptSection(p *RepoProfile) string {
+	if p == nil || len(p.NativeDeps) == 0 {
+		return ""
+	}
+	install := nativeDepsToPackages(p.NativeDeps)
+	if len(install.Apt) == 0 && len(install.Apk) == 0 && len(install.Notes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n## System Packages (native deps: " + strings.Join(p.NativeDeps, ", ") + ")\n")
+	b.WriteString("These dependencies need OS packages; a container without them builds locally but fails in the image. Install them explicitly:\n")
+	if len(install.Apt) > 0 {
+		b.WriteString(fmt.Sprintf("- Debian/Ubuntu images: RUN apt-get update && apt-get install -y --no-install-recommends %s && rm -rf /var/lib/apt/lists/*\n", strings.Join(install.Apt, " ")))
+	}
+	if len(install.Apk) > 0 && len(install.NoAlpine) == 0 {
+		b.WriteString(fmt.Sprintf("- Alpine images: RUN apk add --no-cache %s\n", strings.Join(install.Apk, " ")))
+	}
+	if len(install.FullImage) > 0 {
+		b.WriteString(fmt.Sprintf("- Base image: use the full Debian variant (not -slim or -alpine) because of %s\n", strings.Join(install.FullImage, ", ")))
+	} else if len(install.NoAlpine) > 0 {
+		b.WriteString(fmt.Sprintf("- Base image: do not use -alpine; %s needs glibc (a -slim Debian image is fine)\n", strings.Join(install.NoAlpine, ", ")))
+	}
+	if p.HasDocker {
+		b.WriteString("- The repo has a Dockerfile: if it lacks these packages, add them rather than rewriting it\n")
+	}
+	for _, note := range install.Notes {
+		b.WriteString("- " + note + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectNativeDepsAcrossManifests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json":     `{"dependencies":{"pg-native":"^3.0.0","canvas":"^2.11.0"}}`,
+		"requirements.txt": "psycopg2==2.9.9\nPillow==10.0.0\nffmpeg-python==0.2.0\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &RepoProfile{}
+	detectNativeDeps(dir, p)
+	want := []string{"canvas", "libpq", "pillow", "ffmpeg"}
+	if !reflect.DeepEqual(p.NativeDeps, want) {
+		t.Errorf("NativeDeps = %v, want %v", p.NativeDeps, want)
+	}
+}
+
+func TestDetectNativeDepsSkipsPsycopgBinary(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("psycopg2-binary==2.9.9\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &RepoProfile{}
+	detectNativeDeps(dir, p)
+	if len(p.NativeDeps) != 0 {
+		t.Errorf("psycopg2-binary bundles libpq, got NativeDeps %v", p.NativeDeps)
+	}
+}
+
+func TestDetectNativeDepsLegacyGRPCOnly(t *testing.T) {
+	for content, want := range map[string][]string{
+		`{"dependencies":{"@grpc/grpc-js":"^1.10.0","@grpc/proto-loader":"^0.7.0"}}`: {"@grpc/grpc-js"},
+		`{"dependencies":{"grpc":"^1.24.11"}}`:                                       {"grpc"},
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		p := &RepoProfile{}
+		detectNativeDeps(dir, p)
+		if !reflect.DeepEqual(p.NativeDeps, want) {
+			t.Errorf("NativeDeps for %s = %v, want %v", content, p.NativeDeps, want)
+		}
+	}
+}
+
+func TestAdjustBaseImageForDistroTags(t *testing.T) {
+	tests := []struct {
+		image string
+		deps  []string
+		want  string
+	}{
+		{"python:3.12-slim-bookworm", []string{"weasyprint"}, "python:3.12-bookworm"},
+		{"node:22-bookworm-slim", []string{"canvas"}, "node:22-bookworm"},
+		{"node:22-alpine3.20", []string{"sharp"}, "node:22"},
+		{"python:3.12-slim-bookworm", []string{"pillow"}, "python:3.12-slim-bookworm"},
+		{"localhost:5000/node", []string{"canvas"}, "localhost:5000/node"},
+	}
+	for _, tt := range tests {
+		if got := adjustBaseImageForNativeDeps(tt.image, tt.deps); got != tt.want {
+			t.Errorf("adjustBaseImageForNativeDeps(%q, %v) = %q, want %q", tt.image, tt.deps, got, tt.want)
+		}
+	}
+}
+
+func TestNativeDepsToPackages(t *testing.T) {
+	install := nativeDepsToPackages([]string{"bcrypt", "better-sqlite3", "@grpc/grpc-js", "libpq"})
+	wantApt := []string{"python3", "make", "g++", "libsqlite3-dev", "libpq-dev", "gcc"}
+	if !reflect.DeepEqual(install.Apt, wantApt) {
+		t.Errorf("Apt = %v, want %v", install.Apt, wantApt)
+	}
+	if len(install.FullImage) != 0 || len(install.NoAlpine) != 0 {
+		t.Errorf("expected no base image constraints, got %+v", install)
+	}
+}
+
+func TestDockerBaseImageMovesOffSlimForNativeDeps(t *testing.T) {
+	tests := []struct {
+		lang string
+		deps []string
+		want string
+	}{
+		{"node", nil, "node:22-slim"},
+		{"node", []string{"libpq"}, "node:22-slim"},
+		{"node", []string{"canvas"}, "node:22"},
+		{"python", []string{"weasyprint"}, "python:3.12"},
+		{"go", []string{"sharp"}, "golang:1.22"},
+		{"go", []string{"ffmpeg"}, "golang:1.22-alpine"},
+	}
+	for _, tt := range tests {
+		got := dockerBaseImage(&RepoProfile{Language: tt.lang, NativeDeps: tt.deps})
+		if got != tt.want {
+			t.Errorf("dockerBaseImage(%s, %v) = %q, want %q", tt.lang, tt.deps, got, tt.want)
+		}
+	}
+}
+
+func TestNativeDepsPromptSection(t *testing.T) {
+	out := nativeDepsPromptSection(&RepoProfile{NativeDeps: []string{"libpq", "pillow"}})
+	for _, want := range []string{
+		"apt-get install -y --no-install-recommends libpq-dev gcc libjpeg62-turbo-dev",
+		"do not use -alpine",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "apk add") {
+		t.Errorf("Alpine install step should be omitted for glibc-only deps:\n%s", out)
+	}
+	if nativeDepsPromptSection(&RepoProfile{NativeDeps: []string{"@grpc/grpc-js"}}) != "" {
+		t.Error("deps without OS packages should not produce a section")
+	}
+}
@@ -248,42 +248,13 @@ func OpenClawAzureVMPrompt(p *RepoProfile, deep *DeepAnalysis, opts *DeployOptio
 	return b.String()
 }
 
+// ApplyOpenClawArchitectureDefaults keeps OpenClaw on a VM: EC2 on AWS
+// unless another target was requested, and always a Droplet on DigitalOcean.
 func ApplyOpenClawArchitectureDefaults(targetProvider string, opts *DeployOptions, p *RepoProfile, deep *DeepAnalysis, arch *ArchitectDecision) bool {
-	if arch == nil {
-		return false
-	}
-	provider := strings.ToLower(strings.TrimSpace(targetProvider))
 	if !IsOpenClawRepo(p, deep) {
 		return false
 	}
-
-	// DigitalOcean: force do-droplet for OpenClaw runtime; App Platform is only the HTTPS front door.
-	if provider == "digitalocean" {
-		arch.Provider = "digitalocean"
-		arch.Method = "do-droplet"
-		arch.Reasoning = "OpenClaw stays stateful on a Droplet while App Platform supplies managed HTTPS without requiring a user domain"
-		return true
-	}
-
-	// Only override AWS when the user didn't explicitly request a different target.
-	if provider != "" && provider != "aws" {
-		return false
-	}
-
-	if opts == nil {
-		arch.Provider = "aws"
-		arch.Method = "ec2"
-		arch.Reasoning = "OpenClaw is a stateful, long-running gateway; EC2 is the safest default on AWS for persistent local state + websocket workloads"
-		return true
-	}
-	target := strings.TrimSpace(opts.Target)
-	if target == "" || target == "fargate" {
-		arch.Provider = "aws"
-		arch.Method = "ec2"
-		arch.Reasoning = "OpenClaw is a stateful, long-running gateway; EC2 is the safest default on AWS for persistent local state + websocket workloads"
-		return true
-	}
-	return false
+	return ApplyStatefulArchitectureDefaults(targetProvider, opts, StatefulnessReport{Stateful: true, App: "openclaw"}, arch)
 }
 
 func AppendOpenClawDeploymentRequirements(b *strings.Builder, p *RepoProfile, deep *DeepAnalysis, provider string) bool {
@@ -85,7 +85,7 @@ func classifyIssue(s string) string {
 	if strings.Contains(l, "cloudfront") && strings.Contains(l, "does not") && strings.Contains(l, "websocket") {
 		return "noise"
 	}
-	if strings.Contains(l, "iam policy arn is malformed") && strings.Contains(l, "arn:aws:iam::aws:policy/") {
+	if strings.Contains(l, "iam policy arn is malformed") && mentionsManagedPolicyARN(l) {
 		return "noise"
 	}
 	// Hard patterns: check before context patterns so that issues like
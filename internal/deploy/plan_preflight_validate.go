@@ -11,9 +11,10 @@ import (
 )
 
 type deterministicValidation struct {
-	Issues   []string
-	Fixes    []string
-	Warnings []string
+	Issues       []string
+	Fixes        []string
+	Warnings     []string
+	Placeholders []UnresolvedPlaceholder
 }
 
 // ValidatePlanDeterministicFinal re-runs deterministic validation on the final
@@ -37,10 +38,11 @@ func ValidatePlanDeterministicFinal(plan *maker.Plan, p *RepoProfile, deep *Deep
 	}
 	det := runDeterministicPlanValidation(string(planJSON), p, deep, docker, runtimeEnvKeys)
 	v := &PlanValidation{
-		IsValid:  len(det.Issues) == 0,
-		Issues:   det.Issues,
-		Fixes:    det.Fixes,
-		Warnings: det.Warnings,
+		IsValid:                len(det.Issues) == 0,
+		Issues:                 det.Issues,
+		Fixes:                  det.Fixes,
+		Warnings:               det.Warnings,
+		UnresolvedPlaceholders: det.Placeholders,
 	}
 	return normalizeValidation(v)
 }
@@ -65,7 +67,10 @@ func doFlagValueLocal(args []string, flagName string) string {
 func runDeterministicPlanValidation(planJSON string, p *RepoProfile, deep *DeepAnalysis, docker *DockerAnalysis, runtimeEnvKeys []string) deterministicValidation {
 	var out deterministicValidation
 
-	if containsSecretLikeText(planJSON) {
+	if findings := detectInlineSecrets(planJSON); len(findings) > 0 {
+		out.Issues = append(out.Issues, "[HARD] plan inlines secret values in env vars: "+strings.Join(findings, "; "))
+		out.Fixes = append(out.Fixes, "Move these values to Secrets Manager or SSM Parameter Store (SecureString) and reference them at runtime (ECS secrets valueFrom, Lambda/user-data fetch at startup) instead of inlining them in the plan")
+	} else if containsSecretLikeText(planJSON) {
 		out.Issues = append(out.Issues, "[HARD] plan appears to inline secrets (token/API key/private key) in args")
 		out.Fixes = append(out.Fixes, "Replace any secret literals with placeholders; store secrets in a secret manager and inject at runtime")
 	}
@@ -78,6 +83,8 @@ func runDeterministicPlanValidation(planJSON string, p *RepoProfile, deep *DeepA
 	isOpenClaw := IsOpenClawRepo(p, deep)
 	preflight := BuildPreflightReport(p, docker, deep)
 
+	applyEnvPlaceholderChecks(&out, findEnvPlaceholders(&plan, deep, docker, runtimeEnvKeys))
+
 	// Generic sanity check: a deploy plan should actually launch *something*.
 	// This is intentionally conservative and only triggers when no obvious launch op is present.
 	// Detect provider from plan JSON for provider-gated checks.
@@ -300,6 +307,24 @@ func runDeterministicPlanValidation(planJSON string, p *RepoProfile, deep *DeepA
 		}
 	} // end isAWS EC2 user-data lint
 
+	// Migrations: an app with detected migrations comes up with an empty schema
+	// unless some step runs them. Apps that migrate on boot handle it themselves.
+	var migrationPlan *MigrationPlan
+	if deep != nil {
+		migrationPlan = deep.MigrationPlan
+	}
+	if migrationPlan == nil && preflight != nil && len(preflight.MigrationHints) > 0 {
+		migrationPlan = inferMigrationPlan(p)
+	}
+	if migrationPlan != nil && migrationPlan.Mode != MigrationModeOnBoot && !planHasMigrationStep(planJSON, migrationPlan) {
+		out.Issues = append(out.Issues, "[HARD] database migrations detected ("+migrationPlan.Command+") but the plan never runs them")
+		out.Fixes = append(out.Fixes, "Add a step that runs `"+migrationPlan.Command+"` after the database is created and before the app serves traffic")
+	}
+
+	portChecks := validatePortConsistency(planJSON, deep)
+	out.Issues = append(out.Issues, portChecks.Issues...)
+	out.Fixes = append(out.Fixes, portChecks.Fixes...)
+
 	// Cross-reference: verify user-data ECR image references match plan-created repos.
 	// Generic — catches any project where the LLM invents a different repo name.
 	crossRefIssues := crossCheckUserDataVsPlan(&plan)
@@ -318,9 +343,9 @@ func runDeterministicPlanValidation(planJSON string, p *RepoProfile, deep *DeepA
 func DeterministicValidatePlan(planJSON string, profile *RepoProfile, deep *DeepAnalysis, docker *DockerAnalysis, runtimeEnvKeys []string) *PlanValidation {
 	det := runDeterministicPlanValidation(planJSON, profile, deep, docker, runtimeEnvKeys)
 	if len(det.Issues) > 0 {
-		return &PlanValidation{IsValid: false, Issues: det.Issues, Fixes: det.Fixes, Warnings: det.Warnings}
+		return &PlanValidation{IsValid: false, Issues: det.Issues, Fixes: det.Fixes, Warnings: det.Warnings, UnresolvedPlaceholders: det.Placeholders}
 	}
-	return &PlanValidation{IsValid: true, Issues: nil, Fixes: nil, Warnings: det.Warnings}
+	return &PlanValidation{IsValid: true, Issues: nil, Fixes: nil, Warnings: det.Warnings, UnresolvedPlaceholders: det.Placeholders}
 }
 
 func CheckBulkRepairInvariants(plan *maker.Plan, profile *RepoProfile, deep *DeepAnalysis, runtimeEnvKeys []string) *PlanValidation {
@@ -2,8 +2,10 @@ package deploy
 
 import (
 	"encoding/json"
+	"regexp"
 	"strings"
 
+	awsclient "github.com/bgdnvk/clanker/internal/aws"
 	"github.com/bgdnvk/clanker/internal/maker"
 )
 
@@ -11,8 +13,9 @@ func SanitizePlan(plan *maker.Plan) *maker.Plan {
 	if plan == nil {
 		return nil
 	}
+	partition := planPartition(plan)
 	for i := range plan.Commands {
-		plan.Commands[i].Args = sanitizeCommandArgs(plan.Commands[i].Args)
+		plan.Commands[i].Args = sanitizeCommandArgs(plan.Commands[i].Args, partition)
 		plan.Commands[i].Reason = strings.TrimSpace(plan.Commands[i].Reason)
 	}
 	plan.Question = strings.TrimSpace(plan.Question)
@@ -80,7 +83,23 @@ func clonePlan(plan *maker.Plan) *maker.Plan {
 	return &out
 }
 
-func sanitizeCommandArgs(args []string) []string {
+// planPartition returns the AWS partition of the first --region a plan
+// command targets, defaulting to the commercial partition.
+func planPartition(plan *maker.Plan) string {
+	for _, cmd := range plan.Commands {
+		for i, arg := range cmd.Args {
+			if arg == "--region" && i+1 < len(cmd.Args) {
+				return awsclient.PartitionForRegion(cmd.Args[i+1])
+			}
+			if region, ok := strings.CutPrefix(arg, "--region="); ok {
+				return awsclient.PartitionForRegion(region)
+			}
+		}
+	}
+	return awsclient.PartitionAWS
+}
+
+func sanitizeCommandArgs(args []string, partition string) []string {
 	if len(args) == 0 {
 		return nil
 	}
@@ -113,11 +132,11 @@ func sanitizeCommandArgs(args []string) []string {
 	if service == "iam" && (op == "attach-role-policy" || op == "detach-role-policy" || op == "delete-policy") {
 		for i := 0; i < len(out); i++ {
 			if strings.TrimSpace(out[i]) == "--policy-arn" && i+1 < len(out) {
-				out[i+1] = sanitizeManagedPolicyARN(out[i+1])
+				out[i+1] = sanitizeManagedPolicyARN(out[i+1], partition)
 			}
 			if strings.HasPrefix(strings.TrimSpace(out[i]), "--policy-arn=") {
 				v := strings.TrimPrefix(strings.TrimSpace(out[i]), "--policy-arn=")
-				out[i] = "--policy-arn=" + sanitizeManagedPolicyARN(v)
+				out[i] = "--policy-arn=" + sanitizeManagedPolicyARN(v, partition)
 			}
 		}
 	}
@@ -177,7 +196,21 @@ func stripProgramPrefix(args []string) []string {
 	}
 }
 
-func sanitizeManagedPolicyARN(value string) string {
+// managedPolicyARNTypo matches AWS managed policy ARNs with a wrong number of
+// colons or slashes before "policy/", in any partition.
+var managedPolicyARNTypo = regexp.MustCompile(`^arn:(aws|aws-us-gov|aws-cn):iam:{1,3}aws:policy/+`)
+
+var managedPolicyARNMention = regexp.MustCompile(`arn:aws(-us-gov|-cn)?:iam::aws:policy/`)
+
+// mentionsManagedPolicyARN reports whether text contains a well-formed AWS
+// managed policy ARN in any partition.
+func mentionsManagedPolicyARN(text string) bool {
+	return managedPolicyARNMention.MatchString(text)
+}
+
+// sanitizeManagedPolicyARN repairs malformed managed policy ARNs and expands
+// bare policy names to an ARN in partition.
+func sanitizeManagedPolicyARN(value, partition string) string {
 	v := strings.TrimSpace(value)
 	v = strings.Trim(v, "\"'`")
 	v = strings.Trim(v, " ")
@@ -185,22 +218,17 @@ func sanitizeManagedPolicyARN(value string) string {
 		return v
 	}
 
-	v = strings.ReplaceAll(v, "arn:aws:iam:aws:policy/", "arn:aws:iam::aws:policy/")
-	v = strings.ReplaceAll(v, "arn:aws:iam:::aws:policy/", "arn:aws:iam::aws:policy/")
-	v = strings.ReplaceAll(v, "arn:aws:iam::aws:policy//", "arn:aws:iam::aws:policy/")
-	v = strings.ReplaceAll(v, "arn:aws-us-gov:iam:aws:policy/", "arn:aws-us-gov:iam::aws:policy/")
-	v = strings.ReplaceAll(v, "arn:aws-cn:iam:aws:policy/", "arn:aws-cn:iam::aws:policy/")
-
-	if strings.HasPrefix(v, "arn:aws:iam::aws:policy/") || strings.HasPrefix(v, "arn:aws-us-gov:iam::aws:policy/") || strings.HasPrefix(v, "arn:aws-cn:iam::aws:policy/") {
+	v = managedPolicyARNTypo.ReplaceAllString(v, "arn:$1:iam::aws:policy/")
+	if _, ok := awsclient.ManagedPolicyPath(v); ok {
 		return v
 	}
 
-	if strings.HasPrefix(v, "aws:policy/") {
-		return "arn:aws:iam::" + v
+	if path, ok := strings.CutPrefix(v, "aws:policy/"); ok {
+		return awsclient.ManagedPolicyARN(partition, path)
 	}
 
 	if !strings.HasPrefix(v, "arn:") {
-		return "arn:aws:iam::aws:policy/" + strings.TrimPrefix(v, "/")
+		return awsclient.ManagedPolicyARN(partition, v)
 	}
 
 	return v
@@ -2,33 +2,51 @@ package deploy
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 )
 
 // DeployStrategy controls how we deploy
 type DeployStrategy struct {
-	Provider  string // aws, cloudflare
-	Method    string // ecs-fargate, ec2, lambda, s3-cloudfront, cf-pages, cf-workers, cf-containers
+	Provider  string // aws, cloudflare, digitalocean
+	Method    string // ecs-fargate, ec2, lambda, lambda-apigw, s3-cloudfront, cf-pages, cf-workers, cf-containers, do-droplet, do-app-platform, do-k8s
 	Region    string
 	Reasoning string // LLM's reasoning for the choice
 }
 
 // ArchitectDecision is the structured JSON response from the architect LLM call
 type ArchitectDecision struct {
-	Provider      string   `json:"provider"`                // aws, cloudflare, gcp, azure, digitalocean
-	Method        string   `json:"method"`                  // ecs-fargate, ec2, eks, lambda, s3-cloudfront, cf-pages, cf-workers, cf-containers, do-droplet, do-app-platform
-	Reasoning     string   `json:"reasoning"`               // why this architecture
-	BuildSteps    []string `json:"buildSteps"`              // how to build it
-	RunCmd        string   `json:"runCmd"`                  // simplest way to start it locally
-	Notes         []string `json:"notes"`                   // gotchas, warnings
-	CpuMemory     string   `json:"cpuMemory"`               // e.g. "256/512", "512/1024", or instance type for EC2
-	NeedsALB      bool     `json:"needsAlb"`                // whether to put an ALB in front
-	UseAPIGateway bool     `json:"useApiGateway"`           // whether to use API Gateway instead of ALB
-	NeedsDB       bool     `json:"needsDb"`                 // whether to provision a managed DB
-	DBService     string   `json:"dbService"`               // rds-postgres, elasticache-redis, etc
-	EstMonthly    string   `json:"estMonthly"`              // estimated monthly cost e.g. "$15-25"
-	CostBreakdown []string `json:"costBreakdown,omitempty"` // per-service cost breakdown
+	Provider      string                 `json:"provider"`                // aws, cloudflare, gcp, azure, digitalocean
+	Method        string                 `json:"method"`                  // ecs-fargate, ec2, eks, lambda, lambda-apigw, s3-cloudfront, cf-pages, cf-workers, cf-containers, do-droplet, do-app-platform, do-k8s
+	Reasoning     string                 `json:"reasoning"`               // why this architecture
+	BuildSteps    []string               `json:"buildSteps"`              // how to build it
+	RunCmd        string                 `json:"runCmd"`                  // simplest way to start it locally
+	Notes         []string               `json:"notes"`                   // gotchas, warnings
+	CpuMemory     string                 `json:"cpuMemory"`               // e.g. "256/512", "512/1024", or instance type for EC2
+	Runtime       string                 `json:"runtime,omitempty"`       // Lambda runtime id or container base image, e.g. "nodejs20.x", "python:3.12-slim"
+	NeedsALB      bool                   `json:"needsAlb"`                // whether to put an ALB in front
+	UseAPIGateway bool                   `json:"useApiGateway"`           // whether to use API Gateway instead of ALB
+	NeedsDB       bool                   `json:"needsDb"`                 // whether to provision a managed DB
+	DBService     string                 `json:"dbService"`               // rds-postgres, elasticache-redis, etc
+	EstMonthly    string                 `json:"estMonthly"`              // estimated monthly cost e.g. "$15-25"
+	CostBreakdown []string               `json:"costBreakdown,omitempty"` // per-service cost breakdown
+	Alternatives  []ArchitectAlternative `json:"alternatives,omitempty"`  // methods passed over and cheaper shapes of the pick
+	ParseWarnings []string               `json:"parseWarnings,omitempty"` // fields coerced, dropped or missing while parsing the response
+	Tradeoffs     []string               `json:"tradeoffs,omitempty"`     // what this candidate gains or gives up vs. the others
+	Recommended   bool                   `json:"recommended,omitempty"`   // the architect's pick among several candidates
+}
+
+// ArchitectAlternative is a deployment shape the architect did not pick:
+// another method, or a cheaper variant of its EC2 pick (Graviton, Spot)
+// with the cost difference.
+type ArchitectAlternative struct {
+	Method       string `json:"method"`
+	WhyNot       string `json:"why_not,omitempty"`
+	InstanceType string `json:"instanceType,omitempty"`
+	Spot         bool   `json:"spot,omitempty"`
+	EstMonthly   string `json:"estMonthly,omitempty"`
+	CostDelta    string `json:"costDelta,omitempty"` // e.g. "-$3.07/mo (-20%) vs t3.small on-demand"
 }
 
 // ArchitectPrompt builds the prompt for the architect LLM call
@@ -49,7 +67,7 @@ func ArchitectPrompt(p *RepoProfile) string {
 %s
 
 ## Your Task
-1. Pick the best AWS deployment method (ecs-fargate, ec2, lambda, s3-cloudfront, app-runner, lightsail)
+1. Pick the best AWS deployment method (ecs-fargate, ec2, lambda, lambda-apigw, s3-cloudfront, app-runner, lightsail)
 2. Explain WHY in 1-2 sentences
 3. List the build steps needed (clone, install deps, build, containerize, etc.)
 4. Give the simplest command to run it locally for testing
@@ -80,17 +98,15 @@ func ArchitectPrompt(p *RepoProfile) string {
 }`, string(profileJSON), openClawRules)
 }
 
-// ParseArchitectDecision parses the LLM response into an ArchitectDecision
+// ParseArchitectDecision parses the LLM response into an ArchitectDecision.
+// Mistyped fields are coerced and recorded in ParseWarnings; when method is
+// missing the defaulted decision is returned with a *missingFieldsError.
 func ParseArchitectDecision(raw string) (*ArchitectDecision, error) {
-	// strip markdown fences if present
-	raw = strings.TrimSpace(raw)
-	raw = strings.TrimPrefix(raw, "```json")
-	raw = strings.TrimPrefix(raw, "```")
-	raw = strings.TrimSuffix(raw, "```")
-	raw = strings.TrimSpace(raw)
-
 	var d ArchitectDecision
-	if err := json.Unmarshal([]byte(raw), &d); err != nil {
+	warnings, err := decodeLLMJSON(raw, &d, "method")
+	d.ParseWarnings = warnings
+	var missing *missingFieldsError
+	if err != nil && !errors.As(err, &missing) {
 		return nil, fmt.Errorf("failed to parse architect response: %w", err)
 	}
 
@@ -108,15 +124,34 @@ func ParseArchitectDecision(raw string) (*ArchitectDecision, error) {
 			d.Provider = "aws"
 		}
 	}
+	switch strings.ToLower(strings.TrimSpace(d.Provider)) {
+	case "digitalocean", "digital ocean", "digital-ocean", "do":
+		d.Provider = "digitalocean"
+		d.Method = normalizeDOMethod(d.Method)
+	case "aws":
+		d.Method = normalizeAWSMethod(d.Method)
+	}
 
+	if missing != nil {
+		return &d, missing
+	}
 	return &d, nil
 }
 
 // StrategyFromArchitect converts an ArchitectDecision into a DeployStrategy
 func StrategyFromArchitect(d *ArchitectDecision) DeployStrategy {
+	provider := d.Provider
+	method := d.Method
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "digitalocean", "digital ocean", "digital-ocean", "do":
+		provider = "digitalocean"
+		method = normalizeDOMethod(method)
+	case "aws", "":
+		method = normalizeAWSMethod(method)
+	}
 	return DeployStrategy{
-		Provider:  d.Provider,
-		Method:    d.Method,
+		Provider:  provider,
+		Method:    method,
 		Region:    "us-east-1", // region resolved separately
 		Reasoning: d.Reasoning,
 	}
@@ -357,22 +392,25 @@ func ecsPrompt(p *RepoProfile) string {
 	return b.String()
 }
 
-// dockerBaseImage picks a reasonable base image for generating a Dockerfile
+// dockerBaseImage picks a reasonable base image for generating a Dockerfile,
+// moving off -slim/-alpine when a native dep needs a fuller image.
 func dockerBaseImage(p *RepoProfile) string {
+	var image string
 	switch p.Language {
 	case "node":
-		return "node:22-slim"
+		image = "node:22-slim"
 	case "python":
-		return "python:3.12-slim"
+		image = "python:3.12-slim"
 	case "go":
-		return "golang:1.22-alpine"
+		image = "golang:1.22-alpine"
 	case "rust":
-		return "rust:1.78-slim"
+		image = "rust:1.78-slim"
 	case "java":
-		return "eclipse-temurin:21-jre"
+		image = "eclipse-temurin:21-jre"
 	default:
-		return "ubuntu:24.04"
+		image = "ubuntu:24.04"
 	}
+	return adjustBaseImageForNativeDeps(image, p.NativeDeps)
 }
 
 func s3CloudfrontPrompt(p *RepoProfile) string {
@@ -0,0 +1,251 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bgdnvk/clanker/internal/maker"
+)
+
+var (
+	// dockerPublishRe matches docker run -p/--publish host:container mappings,
+	// with an optional bind address.
+	dockerPublishRe = regexp.MustCompile(`(?:^|\s)(?:-p|--publish)[ =]+(?:[\d.]+:)?(\d+):(\d+)`)
+	// lbContainerPortRe matches containerPort in ecs create-service
+	// --load-balancers, written as JSON or shorthand.
+	lbContainerPortRe = regexp.MustCompile(`"?containerPort"?\s*[=:]\s*(\d+)`)
+)
+
+// backingServicePorts are database and cache ports; a source-group rule on
+// one opens a backing service to the app, not the app to its load balancer.
+var backingServicePorts = map[int]bool{1433: true, 1521: true, 3306: true, 5432: true, 6379: true, 9200: true, 11211: true, 27017: true}
+
+// planPorts is every place a plan names the port app traffic uses.
+type planPorts struct {
+	// ContainerPorts are the ports containers are told the app listens on:
+	// task definition portMappings and docker run -p container sides.
+	ContainerPorts []int
+	// HostPorts maps a published container port to its host port.
+	HostPorts map[int]int
+	// LBContainerPorts are ecs create-service --load-balancers containerPorts.
+	LBContainerPorts []int
+	TargetGroupPorts []int
+	HealthCheckPorts []int
+	// SourceGroupPorts are ingress ports opened to another security group,
+	// which is how the ALB reaches the app, minus backingServicePorts.
+	SourceGroupPorts []int
+}
+
+// collectPlanPorts reads the port references out of a plan's commands.
+func collectPlanPorts(plan *maker.Plan) planPorts {
+	ports := planPorts{HostPorts: map[int]int{}}
+	for _, cmd := range plan.Commands {
+		args := cmd.Args
+		if len(args) < 2 {
+			continue
+		}
+		service := strings.ToLower(strings.TrimSpace(args[0]))
+		op := strings.ToLower(strings.TrimSpace(args[1]))
+		switch {
+		case service == "ec2" && op == "run-instances":
+			for _, m := range dockerPublishRe.FindAllStringSubmatch(extractEC2UserDataScript(args), -1) {
+				host, _ := strconv.Atoi(m[1])
+				container, _ := strconv.Atoi(m[2])
+				ports.ContainerPorts = append(ports.ContainerPorts, container)
+				ports.HostPorts[container] = host
+			}
+		case service == "ecs" && op == "register-task-definition":
+			var defs []struct {
+				PortMappings []struct {
+					ContainerPort int `json:"containerPort"`
+				} `json:"portMappings"`
+			}
+			if json.Unmarshal([]byte(parseFlag(args, "--container-definitions")), &defs) == nil {
+				for _, d := range defs {
+					for _, pm := range d.PortMappings {
+						if pm.ContainerPort > 0 {
+							ports.ContainerPorts = append(ports.ContainerPorts, pm.ContainerPort)
+						}
+					}
+				}
+			}
+		case service == "ecs" && op == "create-service":
+			for _, m := range lbContainerPortRe.FindAllStringSubmatch(parseFlag(args, "--load-balancers"), -1) {
+				if port, err := strconv.Atoi(m[1]); err == nil {
+					ports.LBContainerPorts = append(ports.LBContainerPorts, port)
+				}
+			}
+		case service == "elbv2" && (op == "create-target-group" || op == "modify-target-group"):
+			if strings.EqualFold(strings.TrimSpace(parseFlag(args, "--target-type")), "lambda") {
+				continue
+			}
+			if port := parseFlagInt(args, "--port"); port > 0 && op == "create-target-group" {
+				ports.TargetGroupPorts = append(ports.TargetGroupPorts, port)
+			}
+			if port := parseFlagInt(args, "--health-check-port"); port > 0 {
+				ports.HealthCheckPorts = append(ports.HealthCheckPorts, port)
+			}
+		case service == "ec2" && op == "authorize-security-group-ingress":
+			if strings.TrimSpace(parseFlag(args, "--source-group")) != "" {
+				if port := parseFlagInt(args, "--port"); port > 0 && !backingServicePorts[port] {
+					ports.SourceGroupPorts = append(ports.SourceGroupPorts, port)
+				}
+			}
+			var perms []struct {
+				FromPort int               `json:"FromPort"`
+				Groups   []json.RawMessage `json:"UserIdGroupPairs"`
+			}
+			if json.Unmarshal([]byte(parseFlag(args, "--ip-permissions")), &perms) == nil {
+				for _, p := range perms {
+					if len(p.Groups) > 0 && p.FromPort > 0 && !backingServicePorts[p.FromPort] {
+						ports.SourceGroupPorts = append(ports.SourceGroupPorts, p.FromPort)
+					}
+				}
+			}
+		}
+	}
+	return ports
+}
+
+// trafficPort is the port the load balancer and security groups must use:
+// the port the app listens on, or the host port user-data publishes it on.
+func (p planPorts) trafficPort(listening int) int {
+	if host, ok := p.HostPorts[listening]; ok && host > 0 {
+		return host
+	}
+	return listening
+}
+
+// validatePortConsistency checks that the plan's container ports, target
+// group port, health check port and ALB-to-app ingress all agree with the
+// port deep analysis found the app listening on. A plan that runs the app on
+// 8080 behind a target group health-checking 80 deploys cleanly and never
+// turns healthy, so a disagreement is a hard failure.
+func validatePortConsistency(planJSON string, deep *DeepAnalysis) deterministicValidation {
+	var out deterministicValidation
+	if deep == nil || deep.ListeningPort <= 0 {
+		return out
+	}
+	var plan maker.Plan
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return out
+	}
+	listening := deep.ListeningPort
+	ports := collectPlanPorts(&plan)
+	traffic := ports.trafficPort(listening)
+
+	var mismatches []string
+	if len(ports.ContainerPorts) > 0 && !slices.Contains(ports.ContainerPorts, listening) {
+		mismatches = append(mismatches, "container port "+joinInts(ports.ContainerPorts))
+	}
+	if bad := intsOtherThan(ports.LBContainerPorts, listening); len(bad) > 0 {
+		mismatches = append(mismatches, "ecs service load balancer containerPort "+joinInts(bad))
+	}
+	if bad := intsOtherThan(ports.TargetGroupPorts, traffic); len(bad) > 0 {
+		mismatches = append(mismatches, "target group port "+joinInts(bad))
+	}
+	if bad := intsOtherThan(ports.HealthCheckPorts, traffic); len(bad) > 0 {
+		mismatches = append(mismatches, "health check port "+joinInts(bad))
+	}
+	// Other source-group rules may connect services that are not behind the
+	// load balancer, so only flag them when none uses the app's port.
+	if len(ports.TargetGroupPorts) > 0 && len(ports.SourceGroupPorts) > 0 && !slices.Contains(ports.SourceGroupPorts, traffic) {
+		mismatches = append(mismatches, "load balancer security group ingress "+joinInts(ports.SourceGroupPorts))
+	}
+	if len(mismatches) == 0 {
+		return out
+	}
+
+	listens := fmt.Sprintf("app listens on %d", listening)
+	if traffic != listening {
+		listens += fmt.Sprintf(" (published on host port %d)", traffic)
+	}
+	out.Issues = append(out.Issues, fmt.Sprintf("[HARD] port mismatch: %s but the plan uses %s", listens, strings.Join(mismatches, ", ")))
+	out.Fixes = append(out.Fixes, fmt.Sprintf("Align every port with the app: container port %d, target group --port %d, --health-check-port traffic-port, and security group ingress from the load balancer on %d", listening, traffic, traffic))
+	return out
+}
+
+// alignPlanPorts rewrites target group and health check ports that disagree
+// with the port deep analysis found, and returns how many it changed.
+// Container definitions and security groups are left to the repair agent:
+// a source-group rule may just as well open a database to the app.
+func alignPlanPorts(plan *maker.Plan, deep *DeepAnalysis) int {
+	if plan == nil || deep == nil || deep.ListeningPort <= 0 {
+		return 0
+	}
+	ports := collectPlanPorts(plan)
+	traffic := ports.trafficPort(deep.ListeningPort)
+	if len(ports.ContainerPorts) > 0 && !slices.Contains(ports.ContainerPorts, deep.ListeningPort) {
+		// The containers disagree too; guessing which side is right is the
+		// repair agent's job.
+		return 0
+	}
+
+	fixed := 0
+	for i := range plan.Commands {
+		args := plan.Commands[i].Args
+		if len(args) < 2 {
+			continue
+		}
+		service := strings.ToLower(strings.TrimSpace(args[0]))
+		op := strings.ToLower(strings.TrimSpace(args[1]))
+		if service != "elbv2" || (op != "create-target-group" && op != "modify-target-group") {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(parseFlag(args, "--target-type")), "lambda") {
+			continue
+		}
+		if op == "create-target-group" && setPortFlag(args, "--port", traffic) {
+			fixed++
+		}
+		if setPortFlag(args, "--health-check-port", traffic) {
+			fixed++
+		}
+	}
+	return fixed
+}
+
+// setPortFlag sets a numeric port flag to port when it holds a different
+// number, and reports whether it changed anything.
+func setPortFlag(args []string, name string, port int) bool {
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		switch {
+		case arg == name && i+1 < len(args):
+			if n := parseFlagInt(args[i:i+2], name); n > 0 && n != port {
+				args[i+1] = strconv.Itoa(port)
+				return true
+			}
+			return false
+		case strings.HasPrefix(arg, name+"="):
+			if n := parseFlagInt([]string{arg}, name); n > 0 && n != port {
+				args[i] = name + "=" + strconv.Itoa(port)
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
+
+func intsOtherThan(values []int, want int) []int {
+	var out []int
+	for _, v := range uniqueInts(values) {
+		if v != want {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range uniqueInts(values) {
+		parts = append(parts, strconv.Itoa(v))
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,96 @@
+package deploy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bgdnvk/clanker/internal/maker"
+)
+
+func portPlan(commands ...[]string) *maker.Plan {
+	plan := &maker.Plan{Version: 1, Provider: "aws"}
+	for _, args := range commands {
+		plan.Commands = append(plan.Commands, maker.Command{Args: args})
+	}
+	return plan
+}
+
+func portPlanJSON(t *testing.T, plan *maker.Plan) string {
+	t.Helper()
+	raw, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatalf("marshal plan: %v", err)
+	}
+	return string(raw)
+}
+
+func TestValidatePortConsistency(t *testing.T) {
+	deep := &DeepAnalysis{ListeningPort: 8080}
+	mismatched := portPlan(
+		[]string{"ec2", "run-instances", "--user-data", "#!/bin/bash\ndocker run -d -p 8080:8080 app"},
+		[]string{"elbv2", "create-target-group", "--name", "web", "--protocol", "HTTP", "--port", "80", "--health-check-port", "80"},
+		[]string{"ec2", "authorize-security-group-ingress", "--group-id", "<APP_SG_ID>", "--protocol", "tcp", "--port", "80", "--source-group", "<ALB_SG_ID>"},
+		[]string{"ec2", "authorize-security-group-ingress", "--group-id", "<ALB_SG_ID>", "--protocol", "tcp", "--port", "443", "--cidr", "0.0.0.0/0"},
+	)
+	out := validatePortConsistency(portPlanJSON(t, mismatched), deep)
+	want := "[HARD] port mismatch: app listens on 8080 but the plan uses target group port 80, health check port 80, load balancer security group ingress 80"
+	if len(out.Issues) != 1 || out.Issues[0] != want {
+		t.Fatalf("issues = %v, want %q", out.Issues, want)
+	}
+	if len(out.Fixes) != 1 || !strings.Contains(out.Fixes[0], "target group --port 8080") {
+		t.Errorf("expected a fix aligning the target group, got %v", out.Fixes)
+	}
+
+	published := portPlan(
+		[]string{"ec2", "run-instances", "--user-data", "#!/bin/bash\ndocker run -d -p 80:8080 app"},
+		[]string{"elbv2", "create-target-group", "--name", "web", "--port", "80", "--health-check-port", "traffic-port"},
+		[]string{"ec2", "authorize-security-group-ingress", "--group-id", "<APP_SG_ID>", "--port", "80", "--source-group", "<ALB_SG_ID>"},
+		[]string{"ec2", "authorize-security-group-ingress", "--group-id", "<DB_SG_ID>", "--port", "5432", "--source-group", "<APP_SG_ID>"},
+	)
+	if out := validatePortConsistency(portPlanJSON(t, published), deep); len(out.Issues) > 0 {
+		t.Errorf("publishing 8080 on host port 80 is consistent, got %v", out.Issues)
+	}
+
+	ecs := portPlan(
+		[]string{"ecs", "register-task-definition", "--container-definitions", `[{"name":"app","portMappings":[{"containerPort":3000}]}]`},
+		[]string{"ecs", "create-service", "--load-balancers", `[{"targetGroupArn":"<TG_ARN>","containerName":"app","containerPort": 3000}]`},
+	)
+	out = validatePortConsistency(portPlanJSON(t, ecs), deep)
+	if len(out.Issues) != 1 || !strings.Contains(out.Issues[0], "container port 3000, ecs service load balancer containerPort 3000") {
+		t.Errorf("expected container port mismatches, got %v", out.Issues)
+	}
+
+	if out := validatePortConsistency(portPlanJSON(t, mismatched), nil); len(out.Issues) > 0 {
+		t.Errorf("without a detected port there is nothing to compare, got %v", out.Issues)
+	}
+}
+
+func TestAlignPlanPorts(t *testing.T) {
+	deep := &DeepAnalysis{ListeningPort: 8080}
+	plan := portPlan(
+		[]string{"elbv2", "create-target-group", "--name", "web", "--port=80", "--health-check-port", "80"},
+		[]string{"elbv2", "create-target-group", "--name", "fn", "--target-type", "lambda"},
+		[]string{"ec2", "authorize-security-group-ingress", "--group-id", "<DB_SG_ID>", "--port", "5432", "--source-group", "<APP_SG_ID>"},
+	)
+	if n := alignPlanPorts(plan, deep); n != 2 {
+		t.Fatalf("aligned %d ports, want 2", n)
+	}
+	if got := strings.Join(plan.Commands[0].Args, " "); got != "elbv2 create-target-group --name web --port=8080 --health-check-port 8080" {
+		t.Errorf("target group not aligned: %s", got)
+	}
+	if plan.Commands[2].Args[5] != "5432" {
+		t.Errorf("security group rules must be left alone, got %v", plan.Commands[2].Args)
+	}
+	if out := validatePortConsistency(portPlanJSON(t, plan), deep); len(out.Issues) > 0 {
+		t.Errorf("aligned plan should validate, got %v", out.Issues)
+	}
+
+	containers := portPlan(
+		[]string{"ecs", "register-task-definition", "--container-definitions", `[{"portMappings":[{"containerPort":3000}]}]`},
+		[]string{"elbv2", "create-target-group", "--port", "3000"},
+	)
+	if n := alignPlanPorts(containers, deep); n != 0 {
+		t.Errorf("should not guess when the containers disagree too, aligned %d", n)
+	}
+}
@@ -0,0 +1,240 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	awsclient "github.com/bgdnvk/clanker/internal/aws"
+)
+
+// postDeployLogErrorPattern is the CloudWatch Logs filter for error lines in
+// the app's log group.
+const postDeployLogErrorPattern = "?ERROR ?Error ?error ?Exception ?panic ?FATAL"
+
+// PostDeployCheckResult is one investigation operation run against the
+// deployed resources.
+type PostDeployCheckResult struct {
+	Operation string   `json:"operation"`
+	Target    string   `json:"target"`
+	Healthy   bool     `json:"healthy"`
+	Findings  []string `json:"findings,omitempty"`
+	Output    string   `json:"output,omitempty"`
+}
+
+// PostDeployReport is what the investigation operations found about a
+// deployment after it was applied.
+type PostDeployReport struct {
+	Prefix  string                  `json:"prefix"`
+	Method  string                  `json:"method"`
+	Healthy bool                    `json:"healthy"`
+	Checks  []PostDeployCheckResult `json:"checks"`
+}
+
+// Findings returns every check's findings, prefixed with the operation.
+func (r *PostDeployReport) Findings() []string {
+	if r == nil {
+		return nil
+	}
+	var out []string
+	for _, c := range r.Checks {
+		for _, f := range c.Findings {
+			out = append(out, c.Operation+": "+f)
+		}
+	}
+	return out
+}
+
+// PostDeployCheck runs the investigation agent's operations against the
+// resources a deployment created (found by its resource prefix): ECS service
+// events, ALB target health, EC2 status checks and recent error logs. It
+// reports whether the app actually came up, so "deployed but broken" is
+// caught without a second command. Error lines in the logs are findings but
+// do not on their own make the deployment unhealthy.
+func PostDeployCheck(ctx context.Context, result *IntelligenceResult, profile *awsclient.AIProfile) (*PostDeployReport, error) {
+	if profile == nil {
+		profile = &awsclient.AIProfile{}
+	}
+	client, err := awsclient.NewClientWithProfile(ctx, profile.AWSProfile)
+	if err != nil {
+		return nil, fmt.Errorf("post-deploy check: %w", err)
+	}
+	return postDeployCheck(ctx, client, result, profile)
+}
+
+func postDeployCheck(ctx context.Context, client *awsclient.Client, result *IntelligenceResult, profile *awsclient.AIProfile) (*PostDeployReport, error) {
+	if result == nil || result.Architecture == nil {
+		return nil, fmt.Errorf("post-deploy check: no architecture decision")
+	}
+	prefix := result.ResourcePrefix
+	if prefix == "" && result.RollbackPlan != nil {
+		prefix = result.RollbackPlan.Prefix
+	}
+	if prefix == "" {
+		return nil, fmt.Errorf("post-deploy check: the deployment has no resource prefix")
+	}
+	arch := result.Architecture
+	if provider := strings.ToLower(strings.TrimSpace(arch.Provider)); provider != "" && provider != "aws" {
+		return nil, fmt.Errorf("post-deploy check: provider %s is not AWS", arch.Provider)
+	}
+
+	ops, targets := postDeployOperations(arch, newAWSResourceNames(prefix))
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("post-deploy check: no checks for method %s", arch.Method)
+	}
+	report := &PostDeployReport{Prefix: prefix, Method: arch.Method, Healthy: true}
+	for _, r := range client.RunOperations(ctx, ops, profile) {
+		check := judgePostDeployResult(r)
+		check.Target = targets[r.Index]
+		if !check.Healthy {
+			report.Healthy = false
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// postDeployOperations picks the operations for the deploy method and the
+// resource each one looks at.
+func postDeployOperations(arch *ArchitectDecision, names awsResourceNames) ([]awsclient.LLMOperation, []string) {
+	var ops []awsclient.LLMOperation
+	var targets []string
+	add := func(target, operation, reason string, params map[string]interface{}) {
+		ops = append(ops, awsclient.LLMOperation{Operation: operation, Reason: reason, Parameters: params})
+		targets = append(targets, target)
+	}
+	recentErrors := func(logGroup string) {
+		add(logGroup, "get_recent_logs", "error lines since the deployment started", map[string]interface{}{
+			"log_group_name": logGroup,
+			"filter_pattern": postDeployLogErrorPattern,
+			"start_time":     "30m",
+			"limit":          50,
+		})
+	}
+	alb := func() {
+		if !arch.UseAPIGateway {
+			add(names.ALB, "analyze_alb_errors", "target health behind the load balancer", map[string]interface{}{
+				"load_balancer_name": names.ALB,
+				"start_time":         "30m",
+			})
+		}
+	}
+
+	switch arch.Method {
+	case "ecs-fargate", "":
+		add(names.Service, "analyze_ecs_service_events", "tasks placed and staying up", map[string]interface{}{
+			"cluster_name": names.Cluster,
+			"service_name": names.Service,
+		})
+		alb()
+		recentErrors(names.ECSLogGroup)
+	case "ec2":
+		add(names.Instance, "analyze_ec2_health", "instance status checks", map[string]interface{}{
+			"query": names.Instance,
+		})
+		alb()
+	case "lambda", "lambda-apigw":
+		recentErrors("/aws/lambda/" + names.Function)
+	}
+	return ops, targets
+}
+
+var (
+	ecsRunningRe     = regexp.MustCompile(`running (\d+)/(\d+)`)
+	albTargetsRe     = regexp.MustCompile(`(\d+)/(\d+) targets healthy`)
+	ecsFailureLineRe = regexp.MustCompile(`(?m)^\s*- \[[^\]]+\] (.+)$`)
+)
+
+// judgePostDeployResult reads one operation's report. An operation that could
+// not run is a finding, not a failure of the app.
+func judgePostDeployResult(r awsclient.LLMOperationResult) PostDeployCheckResult {
+	check := PostDeployCheckResult{Operation: r.Operation, Healthy: true, Output: r.Result}
+	if r.Error != nil {
+		check.Findings = append(check.Findings, "could not run: "+r.Error.Error())
+		return check
+	}
+	out := r.Result
+	if strings.HasPrefix(strings.TrimSpace(out), "❌") {
+		check.Findings = append(check.Findings, "could not run: "+firstLine(out))
+		return check
+	}
+
+	fail := func(finding string) {
+		check.Healthy = false
+		check.Findings = append(check.Findings, finding)
+	}
+	switch r.Operation {
+	case "analyze_ecs_service_events":
+		if m := ecsRunningRe.FindStringSubmatch(out); m != nil {
+			running, _ := strconv.Atoi(m[1])
+			desired, _ := strconv.Atoi(m[2])
+			if running < desired {
+				fail(fmt.Sprintf("%d of %d tasks running", running, desired))
+			}
+		} else if strings.Contains(out, "❌") {
+			check.Findings = append(check.Findings, "could not run: "+firstLine(out[strings.Index(out, "❌"):]))
+			return check
+		} else {
+			fail("service not found")
+		}
+		if strings.Contains(out, "🚨") {
+			for _, m := range ecsFailureLineRe.FindAllStringSubmatch(out, 3) {
+				fail(m[1])
+			}
+		}
+	case "analyze_alb_errors":
+		if strings.Contains(out, "no registered targets") {
+			fail("no targets registered with the load balancer")
+		}
+		for _, m := range albTargetsRe.FindAllStringSubmatch(out, -1) {
+			if m[1] != m[2] {
+				fail(fmt.Sprintf("%s of %s targets healthy", m[1], m[2]))
+			}
+		}
+		if strings.Contains(out, "🚨") {
+			fail("ELB 5xx line up with unhealthy targets")
+		}
+	case "analyze_ec2_health":
+		// Failed status checks and stopped instances are 🔴; scheduled
+		// events and transient states are worth reporting but not fatal.
+		for _, line := range strings.Split(out, "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "🔴"):
+				fail(strings.TrimSpace(strings.TrimPrefix(line, "🔴")))
+			case strings.HasPrefix(line, "ℹ️"), strings.HasPrefix(line, "📅"):
+				check.Findings = append(check.Findings, strings.TrimSpace(line))
+			}
+		}
+		if strings.Contains(out, "No EC2 instances found") {
+			fail("instance not found")
+		}
+	case "get_recent_logs":
+		if n := countLogEvents(out); n > 0 {
+			check.Findings = append(check.Findings, fmt.Sprintf("%d error lines in the last 30 minutes", n))
+		}
+	}
+	return check
+}
+
+// countLogEvents counts the events in a get_recent_logs report: a header
+// followed by the filter-log-events JSON array.
+func countLogEvents(out string) int {
+	start := strings.Index(out, "[")
+	if start < 0 {
+		return 0
+	}
+	var events []json.RawMessage
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out[start:])), &events); err != nil {
+		return 0
+	}
+	return len(events)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,109 @@
+package deploy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	awsclient "github.com/bgdnvk/clanker/internal/aws"
+)
+
+func postDeployArgValue(args []string, flag string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func TestPostDeployCheckFlagsECSServiceThatNeverStarted(t *testing.T) {
+	result := &IntelligenceResult{
+		ResourcePrefix: "myapp-abc123",
+		Architecture:   &ArchitectDecision{Provider: "aws", Method: "ecs-fargate", UseAPIGateway: true},
+	}
+	var describedService, logGroup string
+	client := &awsclient.Client{}
+	client.SetExecFunc(func(_ context.Context, args []string, _ *awsclient.AIProfile) (string, error) {
+		switch {
+		case len(args) > 1 && args[1] == "describe-services":
+			describedService = postDeployArgValue(args, "--services") + "@" + postDeployArgValue(args, "--cluster")
+			return `[{"serviceName":"myapp-abc123-svc","status":"ACTIVE","desiredCount":1,"runningCount":0,"pendingCount":0,
+				"events":[{"createdAt":"2026-10-16T10:00:00Z","message":"(service myapp-abc123-svc) is unable to place a task because no container instance met all of its requirements."}]}]`, nil
+		case len(args) > 1 && args[1] == "filter-log-events":
+			logGroup = postDeployArgValue(args, "--log-group-name")
+			return `[{"Timestamp":1,"Message":"Error: connect ECONNREFUSED 127.0.0.1:5432"},{"Timestamp":2,"Message":"Error: connect ECONNREFUSED 127.0.0.1:5432"}]`, nil
+		}
+		return "{}", nil
+	})
+
+	report, err := postDeployCheck(context.Background(), client, result, &awsclient.AIProfile{})
+	if err != nil {
+		t.Fatalf("postDeployCheck: %v", err)
+	}
+	if describedService != "myapp-abc123-svc@myapp-abc123-cluster" {
+		t.Errorf("described %q, want the service and cluster named by the prefix", describedService)
+	}
+	if logGroup != "/ecs/myapp-abc123" {
+		t.Errorf("read log group %q, want /ecs/myapp-abc123", logGroup)
+	}
+	if report.Healthy {
+		t.Fatalf("report is healthy, want unhealthy: %+v", report)
+	}
+	findings := strings.Join(report.Findings(), "\n")
+	for _, want := range []string{"0 of 1 tasks running", "unable to place a task", "2 error lines"} {
+		if !strings.Contains(findings, want) {
+			t.Errorf("findings missing %q:\n%s", want, findings)
+		}
+	}
+}
+
+func TestPostDeployCheckNeedsPrefix(t *testing.T) {
+	result := &IntelligenceResult{Architecture: &ArchitectDecision{Method: "ec2"}}
+	if _, err := postDeployCheck(context.Background(), &awsclient.Client{}, result, nil); err == nil {
+		t.Fatal("expected an error without a resource prefix")
+	}
+}
+
+func TestJudgePostDeployResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  awsclient.LLMOperationResult
+		healthy bool
+		finding string
+	}{
+		{
+			name: "alb with an unhealthy target",
+			result: awsclient.LLMOperationResult{Operation: "analyze_alb_errors", Result: "⚖️  ALB ERROR ANALYSIS: app-alb\n" +
+				"Target group app-tg:\n  - i-1:3000 unhealthy (Target.ResponseCodeMismatch)\n  0/1 targets healthy\n"},
+			finding: "0 of 1 targets healthy",
+		},
+		{
+			name:    "alb healthy",
+			result:  awsclient.LLMOperationResult{Operation: "analyze_alb_errors", Result: "✅ No ELB 5xx and no unhealthy targets in the last 30m\n\nTarget group app-tg:\n  1/1 targets healthy\n"},
+			healthy: true,
+		},
+		{
+			name:    "ec2 instance failing its status check",
+			result:  awsclient.LLMOperationResult{Operation: "analyze_ec2_health", Result: "⚠️  app (i-1):\n    🔴 instance status check failed: the OS is not answering\n"},
+			finding: "instance status check failed",
+		},
+		{
+			name:    "operation that could not run",
+			result:  awsclient.LLMOperationResult{Operation: "get_recent_logs", Result: "❌ Failed to get logs for /ecs/app: ResourceNotFoundException"},
+			healthy: true,
+			finding: "could not run",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := judgePostDeployResult(tt.result)
+			if got.Healthy != tt.healthy {
+				t.Errorf("healthy = %v, want %v (findings %v)", got.Healthy, tt.healthy, got.Findings)
+			}
+			if tt.finding != "" && !strings.Contains(strings.Join(got.Findings, "\n"), tt.finding) {
+				t.Errorf("findings %v missing %q", got.Findings, tt.finding)
+			}
+		})
+	}
+}
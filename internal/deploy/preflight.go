@@ -121,5 +121,10 @@ func containsSecretLikeText(s string) bool {
 	if strings.TrimSpace(s) == "" {
 		return false
 	}
-	return secretLikeRe.FindStringIndex(s) != nil
+	for _, match := range secretLikeRe.FindAllString(s, -1) {
+		if !isSecretPlaceholder(match) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,136 @@
+package deploy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultFileBudgetTokens caps the file contents embedded in a phase prompt.
+	DefaultFileBudgetTokens = 60000
+	// minTruncatedFileTokens is the smallest remainder worth spending on a
+	// truncated file; below it the file is elided instead.
+	minTruncatedFileTokens = 256
+)
+
+// alwaysFullFiles are embedded in full regardless of the budget: every phase
+// needs them to reason about build and runtime.
+var alwaysFullFiles = map[string]bool{
+	"Dockerfile":          true,
+	"dockerfile":          true,
+	"docker-compose.yml":  true,
+	"docker-compose.yaml": true,
+	"compose.yml":         true,
+	"compose.yaml":        true,
+	"package.json":        true,
+}
+
+// ProfileBudget limits how much of RepoProfile.KeyFiles is embedded in an LLM
+// prompt so large repos and monorepos stay inside the model context window.
+type ProfileBudget struct {
+	MaxTokens int
+}
+
+// BudgetedFile is a key file as it will appear in the prompt.
+type BudgetedFile struct {
+	Name      string
+	Content   string
+	Truncated bool
+}
+
+// BudgetedFiles is the deterministic result of applying a ProfileBudget.
+type BudgetedFiles struct {
+	Files  []BudgetedFile
+	Elided []string
+}
+
+// NewProfileBudget returns a budget of maxTokens, or the default when unset.
+func NewProfileBudget(maxTokens int) ProfileBudget {
+	if maxTokens <= 0 {
+		maxTokens = DefaultFileBudgetTokens
+	}
+	return ProfileBudget{MaxTokens: maxTokens}
+}
+
+// estimateTokens approximates tokens as one per four bytes.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// Apply selects file contents within the budget. Always-full files come first,
+// then the remaining files smallest first (ties by name) so the most files fit.
+// The first file that no longer fits is truncated; the rest are elided.
+func (b ProfileBudget) Apply(keyFiles map[string]string) BudgetedFiles {
+	var pinned, rest []string
+	for name := range keyFiles {
+		if alwaysFullFiles[name] {
+			pinned = append(pinned, name)
+		} else {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(pinned)
+	sort.Slice(rest, func(i, j int) bool {
+		si, sj := len(keyFiles[rest[i]]), len(keyFiles[rest[j]])
+		if si != sj {
+			return si < sj
+		}
+		return rest[i] < rest[j]
+	})
+
+	var out BudgetedFiles
+	remaining := b.MaxTokens
+	for _, name := range pinned {
+		out.Files = append(out.Files, BudgetedFile{Name: name, Content: keyFiles[name]})
+		remaining -= estimateTokens(keyFiles[name])
+	}
+	for _, name := range rest {
+		content := keyFiles[name]
+		cost := estimateTokens(content)
+		switch {
+		case cost <= remaining:
+			out.Files = append(out.Files, BudgetedFile{Name: name, Content: content})
+			remaining -= cost
+		case remaining >= minTruncatedFileTokens:
+			// Back up to a rune start so a multi-byte character is not split.
+			n := remaining * 4
+			for n > 0 && !utf8.RuneStart(content[n]) {
+				n--
+			}
+			cut := content[:n]
+			if idx := strings.LastIndex(cut, "\n"); idx > 0 {
+				cut = cut[:idx]
+			}
+			out.Files = append(out.Files, BudgetedFile{Name: name, Content: cut, Truncated: true})
+			remaining = 0
+		default:
+			out.Elided = append(out.Elided, name)
+		}
+	}
+	sort.Strings(out.Elided)
+	return out
+}
+
+// FormatForPrompt renders the budgeted files as a "Key Files" prompt section,
+// noting truncated and elided files so the model knows the context is partial.
+func (f BudgetedFiles) FormatForPrompt() string {
+	if len(f.Files) == 0 && len(f.Elided) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Key Files\n")
+	for _, file := range f.Files {
+		content := file.Content
+		if file.Truncated {
+			content += "\n... (truncated to fit the context budget)"
+		}
+		b.WriteString(fmt.Sprintf("\n### %s\n```\n%s\n```\n", file.Name, content))
+	}
+	if len(f.Elided) > 0 {
+		b.WriteString(fmt.Sprintf("\nNOTE: context is partial. %d files were omitted to fit the context budget: %s\n", len(f.Elided), strings.Join(f.Elided, ", ")))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestProfileBudgetApply(t *testing.T) {
+	big := strings.Repeat("x", 4000) + "\n"
+	files := map[string]string{
+		"Dockerfile":   strings.Repeat("RUN echo\n", 500), // pinned even though it exceeds the budget alone
+		"package.json": `{"name":"app"}`,
+		"README.md":    "hello",
+		"src/a.ts":     big + big,
+		"src/b.ts":     big + big + big,
+		"src/c.ts":     big + big + big + big,
+	}
+
+	budget := ProfileBudget{MaxTokens: 1125 + 2*1001 + 1000}
+	got := budget.Apply(files)
+
+	var names []string
+	for _, f := range got.Files {
+		names = append(names, f.Name)
+	}
+	want := "Dockerfile,package.json,README.md,src/a.ts,src/b.ts"
+	if strings.Join(names, ",") != want {
+		t.Fatalf("files = %v, want %s", names, want)
+	}
+	if got.Files[0].Truncated || got.Files[3].Truncated || !got.Files[4].Truncated {
+		t.Fatalf("expected only src/b.ts to be truncated, got %+v", got.Files)
+	}
+	if len(got.Elided) != 1 || got.Elided[0] != "src/c.ts" {
+		t.Fatalf("elided = %v, want [src/c.ts]", got.Elided)
+	}
+
+	again := budget.Apply(files)
+	if again.FormatForPrompt() != got.FormatForPrompt() {
+		t.Fatal("expected budget application to be deterministic")
+	}
+	if !strings.Contains(got.FormatForPrompt(), "context is partial") {
+		t.Fatal("expected prompt to note elided files")
+	}
+}
+
+func TestNewProfileBudgetDefault(t *testing.T) {
+	if NewProfileBudget(0).MaxTokens != DefaultFileBudgetTokens {
+		t.Fatal("expected default budget when unset")
+	}
+}
+
+func TestProfileBudgetTruncatesOnRuneBoundary(t *testing.T) {
+	// After the leading "x" every two-byte "é" starts on an odd offset, so the
+	// 1024-byte cut lands mid-rune.
+	files := map[string]string{"notes.md": "x" + strings.Repeat("é", 1500)}
+	got := ProfileBudget{MaxTokens: minTruncatedFileTokens}.Apply(files)
+	if len(got.Files) != 1 || !got.Files[0].Truncated {
+		t.Fatalf("expected notes.md to be truncated, got %+v", got)
+	}
+	if !utf8.ValidString(got.Files[0].Content) {
+		t.Fatal("truncated content split a UTF-8 character")
+	}
+}
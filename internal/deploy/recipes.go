@@ -0,0 +1,114 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DeployRecipe is a fast path for a known app: when it matches a repo, it
+// sets the architecture directly instead of leaving placement to the
+// generic stateful overrides. The built-in recipes are the app rule packs'
+// ApplyArchitectureDefaults hooks; RegisterDeployRecipe adds more.
+type DeployRecipe interface {
+	// Name identifies the recipe in logs; it must be unique.
+	Name() string
+	// Matches reports whether the repo is the app this recipe handles.
+	Matches(p *RepoProfile, deep *DeepAnalysis) bool
+	// Apply adjusts arch for the app and reports whether it changed it.
+	Apply(targetProvider string, opts *DeployOptions, p *RepoProfile, deep *DeepAnalysis, arch *ArchitectDecision) bool
+}
+
+// rulePackRecipe is the recipe of an app rule pack.
+type rulePackRecipe struct {
+	pack RulePack
+}
+
+func (r rulePackRecipe) Name() string { return r.pack.Name }
+
+func (r rulePackRecipe) Matches(p *RepoProfile, deep *DeepAnalysis) bool {
+	return r.pack.Matches == nil || r.pack.Matches(RulePackContext{Profile: p, Deep: deep})
+}
+
+func (r rulePackRecipe) Apply(targetProvider string, opts *DeployOptions, p *RepoProfile, deep *DeepAnalysis, arch *ArchitectDecision) bool {
+	ctx := RulePackContext{TargetProvider: targetProvider, Options: opts, Profile: p, Deep: deep}
+	return r.pack.ApplyArchitectureDefaults(ctx, arch)
+}
+
+var (
+	recipesMu         sync.Mutex
+	registeredRecipes []DeployRecipe
+)
+
+// RegisterDeployRecipe adds a recipe as an app rule pack after the ones
+// already registered. The first matching recipe wins, so the built-in
+// OpenClaw and WordPress packs take precedence over recipes added later.
+func RegisterDeployRecipe(r DeployRecipe) error {
+	if r == nil {
+		return fmt.Errorf("deploy recipe is nil")
+	}
+	name := strings.TrimSpace(r.Name())
+	if name == "" {
+		return fmt.Errorf("deploy recipe name is required")
+	}
+	for _, pack := range builtinRulePacks() {
+		if pack.Name == name {
+			return fmt.Errorf("deploy recipe %q is already registered", name)
+		}
+	}
+	recipesMu.Lock()
+	defer recipesMu.Unlock()
+	for _, existing := range registeredRecipes {
+		if existing.Name() == name {
+			return fmt.Errorf("deploy recipe %q is already registered", name)
+		}
+	}
+	registeredRecipes = append(registeredRecipes, r)
+	return nil
+}
+
+// registeredRecipePacks returns the registered recipes as app rule packs.
+func registeredRecipePacks() []RulePack {
+	recipesMu.Lock()
+	defer recipesMu.Unlock()
+	packs := make([]RulePack, 0, len(registeredRecipes))
+	for _, r := range registeredRecipes {
+		packs = append(packs, RulePack{
+			Name:  r.Name(),
+			Scope: rulePackScopeApp,
+			Matches: func(ctx RulePackContext) bool {
+				return ctx.Profile != nil && r.Matches(ctx.Profile, ctx.Deep)
+			},
+			ApplyArchitectureDefaults: func(ctx RulePackContext, arch *ArchitectDecision) bool {
+				return r.Apply(ctx.TargetProvider, ctx.Options, ctx.Profile, ctx.Deep, arch)
+			},
+		})
+	}
+	return packs
+}
+
+// DeployRecipes returns the recipes in the order they are tried: the app
+// rule packs with architecture defaults, built-in ones first.
+func DeployRecipes() []DeployRecipe {
+	var recipes []DeployRecipe
+	for _, pack := range deployRulePacks() {
+		if pack.Scope == rulePackScopeApp && pack.ApplyArchitectureDefaults != nil {
+			recipes = append(recipes, rulePackRecipe{pack: pack})
+		}
+	}
+	return recipes
+}
+
+// matchingDeployRecipe returns the first recipe that matches the repo, or
+// nil.
+func matchingDeployRecipe(p *RepoProfile, deep *DeepAnalysis) DeployRecipe {
+	if p == nil {
+		return nil
+	}
+	for _, r := range DeployRecipes() {
+		if r.Matches(p, deep) {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+)
+
+type ghostRecipe struct{}
+
+func (ghostRecipe) Name() string { return "ghost" }
+
+func (ghostRecipe) Matches(p *RepoProfile, _ *DeepAnalysis) bool {
+	return strings.Contains(p.RepoURL, "TryGhost/Ghost")
+}
+
+func (ghostRecipe) Apply(_ string, _ *DeployOptions, _ *RepoProfile, _ *DeepAnalysis, arch *ArchitectDecision) bool {
+	arch.Method = "ec2"
+	arch.Reasoning = "Ghost keeps content on local disk"
+	return true
+}
+
+// namedRecipe is ghostRecipe under another name.
+type namedRecipe struct {
+	ghostRecipe
+	name string
+}
+
+func (r namedRecipe) Name() string { return r.name }
+
+func TestDeployRecipes(t *testing.T) {
+	t.Cleanup(func() { registeredRecipes = nil })
+
+	if err := RegisterDeployRecipe(ghostRecipe{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterDeployRecipe(ghostRecipe{}); err == nil {
+		t.Error("expected an error registering the same recipe twice")
+	}
+	if err := RegisterDeployRecipe(namedRecipe{name: "wordpress"}); err == nil {
+		t.Error("expected an error shadowing a built-in rule pack")
+	}
+	if err := RegisterDeployRecipe(nil); err == nil {
+		t.Error("expected an error for a nil recipe")
+	}
+
+	ghost := &RepoProfile{RepoURL: "https://github.com/TryGhost/Ghost"}
+	r := matchingDeployRecipe(ghost, nil)
+	if r == nil || r.Name() != "ghost" {
+		t.Fatalf("expected the ghost recipe, got %v", r)
+	}
+	arch := &ArchitectDecision{Method: "ecs-fargate"}
+	if !r.Apply("aws", nil, ghost, nil, arch) || arch.Method != "ec2" {
+		t.Errorf("ghost recipe should move to ec2, got %s", arch.Method)
+	}
+
+	wordpress := &RepoProfile{RepoURL: "https://github.com/docker-library/wordpress"}
+	if r := matchingDeployRecipe(wordpress, nil); r == nil || r.Name() != "wordpress" {
+		t.Errorf("expected the built-in wordpress recipe, got %v", r)
+	}
+	if r := matchingDeployRecipe(&RepoProfile{RepoURL: "https://github.com/acme/api"}, nil); r != nil {
+		t.Errorf("expected no recipe for an unknown app, got %s", r.Name())
+	}
+}
@@ -0,0 +1,206 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// repoConfigFiles are the names a repo can declare its deploy intent under,
+// in lookup order.
+var repoConfigFiles = []string{".clanker.yaml", ".clanker.yml", "clanker.yaml", "clanker.yml"}
+
+var repoConfigTargets = map[string]bool{"fargate": true, "ec2": true, "eks": true}
+
+// RepoConfig is the deploy section of a .clanker.yaml shipped in the repo
+// being deployed:
+//
+//	deploy:
+//	  target: ec2
+//	  instance_type: t3.medium
+//	  port: 8080
+//	  health_endpoint: /healthz
+//	  start_command: node server.js
+//	  env:
+//	    NODE_ENV: production
+//	  required_env: [DATABASE_URL]
+//
+// It is the repo author's statement of how the app runs, so it takes
+// precedence over what analysis infers; CLI flags still override it.
+type RepoConfig struct {
+	File           string            `yaml:"-"` // where it was read from, relative to the analyzed dir
+	Target         string            `yaml:"target"`
+	InstanceType   string            `yaml:"instance_type"`
+	Port           int               `yaml:"port"`
+	HealthEndpoint string            `yaml:"health_endpoint"`
+	StartCommand   string            `yaml:"start_command"`
+	BuildCommand   string            `yaml:"build_command"`
+	Env            map[string]string `yaml:"env"`          // variables with known values
+	RequiredEnv    []string          `yaml:"required_env"` // variables the deployer must supply
+}
+
+// loadRepoConfig reads the deploy section of the first repo config file in
+// dir. It returns nil when there is none or it has no deploy section.
+func loadRepoConfig(dir string) (*RepoConfig, error) {
+	for _, name := range repoConfigFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		var doc struct {
+			Deploy *RepoConfig `yaml:"deploy"`
+		}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if doc.Deploy == nil {
+			return nil, nil
+		}
+		cfg := doc.Deploy
+		cfg.File = name
+		cfg.Target = strings.ToLower(strings.TrimSpace(cfg.Target))
+		if cfg.Target != "" && !repoConfigTargets[cfg.Target] {
+			return nil, fmt.Errorf("%s: unsupported deploy.target %q (available: fargate, ec2, eks)", name, cfg.Target)
+		}
+		if cfg.Port < 0 || cfg.Port > 65535 {
+			return nil, fmt.Errorf("%s: deploy.port %d is out of range", name, cfg.Port)
+		}
+		if ep := strings.TrimSpace(cfg.HealthEndpoint); ep != "" && !strings.HasPrefix(ep, "/") {
+			cfg.HealthEndpoint = "/" + ep
+		}
+		return cfg, nil
+	}
+	return nil, nil
+}
+
+// applyRepoConfigOptions fills the target and instance type the CLI left
+// unset from the repo config.
+func applyRepoConfigOptions(cfg *RepoConfig, opts *DeployOptions) {
+	if cfg == nil || opts == nil {
+		return
+	}
+	if opts.Target == "" {
+		opts.Target = cfg.Target
+	}
+	if opts.InstanceType == "" {
+		opts.InstanceType = cfg.InstanceType
+	}
+}
+
+// applyRepoConfigAnalysis overrides what deep analysis inferred with what
+// the repo config declares.
+func applyRepoConfigAnalysis(cfg *RepoConfig, deep *DeepAnalysis) {
+	if cfg == nil || deep == nil {
+		return
+	}
+	if cfg.Port > 0 {
+		deep.ListeningPort = cfg.Port
+		deep.ExposesHTTP = true
+	}
+	if cfg.HealthEndpoint != "" {
+		deep.HealthEndpoint = cfg.HealthEndpoint
+	}
+	if cfg.StartCommand != "" {
+		deep.StartCommand = cfg.StartCommand
+	}
+	if cfg.BuildCommand != "" {
+		deep.BuildCommand = cfg.BuildCommand
+	}
+
+	for _, name := range cfg.RequiredEnv {
+		spec := removeEnvVarSpec(&deep.OptionalEnvVars, name)
+		if existing := findEnvVarSpec(deep.RequiredEnvVars, name); existing != nil {
+			continue
+		}
+		if spec == nil {
+			spec = &EnvVarSpec{Name: name, Description: "required by " + cfg.File}
+		}
+		spec.Required = true
+		deep.RequiredEnvVars = append(deep.RequiredEnvVars, *spec)
+	}
+
+	names := make([]string, 0, len(cfg.Env))
+	for name := range cfg.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// A declared value satisfies the variable, so it is no longer
+		// something the deployer has to supply.
+		spec := removeEnvVarSpec(&deep.RequiredEnvVars, name)
+		if existing := findEnvVarSpec(deep.OptionalEnvVars, name); existing != nil {
+			existing.Default = cfg.Env[name]
+			continue
+		}
+		if spec == nil {
+			spec = &EnvVarSpec{Name: name, Description: "set in " + cfg.File}
+		}
+		spec.Required = false
+		spec.Default = cfg.Env[name]
+		deep.OptionalEnvVars = append(deep.OptionalEnvVars, *spec)
+	}
+}
+
+func findEnvVarSpec(specs []EnvVarSpec, name string) *EnvVarSpec {
+	for i := range specs {
+		if specs[i].Name == name {
+			return &specs[i]
+		}
+	}
+	return nil
+}
+
+// removeEnvVarSpec deletes name from specs and returns the removed entry.
+func removeEnvVarSpec(specs *[]EnvVarSpec, name string) *EnvVarSpec {
+	for i, spec := range *specs {
+		if spec.Name == name {
+			*specs = append((*specs)[:i], (*specs)[i+1:]...)
+			return &spec
+		}
+	}
+	return nil
+}
+
+// formatRepoConfigForPrompt renders the repo config as ground truth for the
+// architect prompt.
+func formatRepoConfigForPrompt(cfg *RepoConfig) string {
+	if cfg == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n\n## Repo deploy config (%s, written by the repo authors; treat as ground truth)", cfg.File))
+	if cfg.Target != "" {
+		b.WriteString("\n- Target: " + cfg.Target)
+	}
+	if cfg.InstanceType != "" {
+		b.WriteString("\n- Instance type: " + cfg.InstanceType)
+	}
+	if cfg.Port > 0 {
+		b.WriteString(fmt.Sprintf("\n- Port: %d", cfg.Port))
+	}
+	if cfg.HealthEndpoint != "" {
+		b.WriteString("\n- Health endpoint: " + cfg.HealthEndpoint)
+	}
+	if cfg.StartCommand != "" {
+		b.WriteString("\n- Start command: " + cfg.StartCommand)
+	}
+	if cfg.BuildCommand != "" {
+		b.WriteString("\n- Build command: " + cfg.BuildCommand)
+	}
+	if len(cfg.Env) > 0 {
+		names := make([]string, 0, len(cfg.Env))
+		for name := range cfg.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteString("\n- Env set by the repo: " + strings.Join(names, ", "))
+	}
+	if len(cfg.RequiredEnv) > 0 {
+		b.WriteString("\n- Env the deployer must supply: " + strings.Join(cfg.RequiredEnv, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRepoConfig(t *testing.T) {
+	dir := t.TempDir()
+	if cfg, err := loadRepoConfig(dir); cfg != nil || err != nil {
+		t.Fatalf("expected nothing without a config file, got %+v, %v", cfg, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".clanker.yaml"), []byte(`
+deploy:
+  target: EC2
+  instance_type: t3.medium
+  port: 8080
+  health_endpoint: healthz
+  env:
+    NODE_ENV: production
+  required_env: [DATABASE_URL]
+`), 0o644)
+	cfg, err := loadRepoConfig(dir)
+	if err != nil || cfg == nil {
+		t.Fatalf("loadRepoConfig() = %+v, %v", cfg, err)
+	}
+	if cfg.File != ".clanker.yaml" || cfg.Target != "ec2" || cfg.InstanceType != "t3.medium" || cfg.Port != 8080 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.HealthEndpoint != "/healthz" {
+		t.Errorf("HealthEndpoint = %q, want /healthz", cfg.HealthEndpoint)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".clanker.yaml"), []byte("deploy:\n  target: lambda\n"), 0o644)
+	if _, err := loadRepoConfig(dir); err == nil || !strings.Contains(err.Error(), "lambda") {
+		t.Errorf("expected an unsupported target error, got %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, ".clanker.yaml"), []byte("deploy: [oops"), 0o644)
+	if _, err := loadRepoConfig(dir); err == nil {
+		t.Error("expected a malformed config to fail to load")
+	}
+	if profile, err := Analyze(dir); err != nil || profile.RepoConfig != nil {
+		t.Errorf("expected Analyze to continue without a malformed config, got %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, ".clanker.yaml"), []byte("openai:\n  key: x\n"), 0o644)
+	if cfg, err := loadRepoConfig(dir); cfg != nil || err != nil {
+		t.Errorf("expected a config without a deploy section to be ignored, got %+v, %v", cfg, err)
+	}
+}
+
+func TestApplyRepoConfigOptions(t *testing.T) {
+	cfg := &RepoConfig{Target: "ec2", InstanceType: "t3.large"}
+
+	opts := &DeployOptions{}
+	applyRepoConfigOptions(cfg, opts)
+	if opts.Target != "ec2" || opts.InstanceType != "t3.large" {
+		t.Errorf("expected the repo config to fill unset options, got %+v", opts)
+	}
+
+	opts = &DeployOptions{Target: "fargate"}
+	applyRepoConfigOptions(cfg, opts)
+	if opts.Target != "fargate" || opts.InstanceType != "t3.large" {
+		t.Errorf("expected CLI flags to win over the repo config, got %+v", opts)
+	}
+}
+
+func TestApplyRepoConfigAnalysis(t *testing.T) {
+	cfg := &RepoConfig{
+		File:           ".clanker.yaml",
+		Port:           8080,
+		HealthEndpoint: "/healthz",
+		StartCommand:   "node server.js",
+		Env:            map[string]string{"NODE_ENV": "production", "API_KEY": "public"},
+		RequiredEnv:    []string{"DATABASE_URL", "REDIS_URL"},
+	}
+	deep := &DeepAnalysis{
+		ListeningPort:   3000,
+		HealthEndpoint:  "/",
+		RequiredEnvVars: []EnvVarSpec{{Name: "API_KEY", Required: true}, {Name: "DATABASE_URL", Required: true}},
+		OptionalEnvVars: []EnvVarSpec{{Name: "REDIS_URL", Description: "cache"}},
+	}
+	applyRepoConfigAnalysis(cfg, deep)
+
+	if deep.ListeningPort != 8080 || deep.HealthEndpoint != "/healthz" || deep.StartCommand != "node server.js" {
+		t.Errorf("expected declared values to override analysis, got port=%d health=%q start=%q",
+			deep.ListeningPort, deep.HealthEndpoint, deep.StartCommand)
+	}
+	var required []string
+	for _, v := range deep.RequiredEnvVars {
+		required = append(required, v.Name)
+	}
+	if strings.Join(required, ",") != "DATABASE_URL,REDIS_URL" {
+		t.Errorf("required env = %v, want DATABASE_URL,REDIS_URL", required)
+	}
+	if v := findEnvVarSpec(deep.RequiredEnvVars, "REDIS_URL"); v == nil || v.Description != "cache" || !v.Required {
+		t.Errorf("expected REDIS_URL promoted with its description kept, got %+v", v)
+	}
+	if v := findEnvVarSpec(deep.OptionalEnvVars, "API_KEY"); v == nil || v.Default != "public" || v.Required {
+		t.Errorf("expected API_KEY satisfied by its declared value, got %+v", v)
+	}
+	if v := findEnvVarSpec(deep.OptionalEnvVars, "NODE_ENV"); v == nil || v.Default != "production" {
+		t.Errorf("expected NODE_ENV added with its value, got %+v", v)
+	}
+}
+
+func TestScopeProfileToSubPathInheritsRepoConfig(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "packages", "api"), 0o755)
+	os.WriteFile(filepath.Join(root, "clanker.yml"), []byte("deploy:\n  target: eks\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "packages", "api", "package.json"), []byte(`{"name":"api"}`), 0o644)
+
+	profile, err := analyzeClone("https://github.com/acme/mono", root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ScopeProfileToSubPath(profile, "packages/api"); err != nil {
+		t.Fatal(err)
+	}
+	if profile.RepoConfig == nil || profile.RepoConfig.Target != "eks" {
+		t.Errorf("expected the workspace to inherit the root deploy config, got %+v", profile.RepoConfig)
+	}
+	if !strings.Contains(formatRepoConfigForPrompt(profile.RepoConfig), "Target: eks") {
+		t.Error("expected the prompt section to carry the declared target")
+	}
+}
@@ -0,0 +1,615 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	awsclient "github.com/bgdnvk/clanker/internal/aws"
+	"github.com/bgdnvk/clanker/internal/maker"
+)
+
+// awsResourceNames are the names a deploy gives the AWS resources it creates.
+// The deployment prompts and the rollback plan both use them, so teardown
+// targets exactly what was created.
+type awsResourceNames struct {
+	Prefix string
+
+	VPC        string
+	Subnet1a   string
+	Subnet1b   string
+	IGW        string
+	RouteTable string
+
+	ALBSG string
+	EC2SG string
+	ECSSG string
+
+	EC2Role    string
+	EC2Profile string
+	Instance   string
+
+	ECRRepo     string
+	ALB         string
+	TargetGroup string
+
+	Cluster      string
+	Service      string
+	TaskFamily   string
+	TaskExecRole string
+	ECSLogGroup  string
+
+	Function   string
+	LambdaRole string
+	HTTPAPI    string
+
+	AppRunnerService string
+	LightsailService string
+}
+
+func newAWSResourceNames(prefix string) awsResourceNames {
+	return awsResourceNames{
+		Prefix: prefix,
+
+		VPC:        awsName(prefix, "-vpc", 128),
+		Subnet1a:   awsName(prefix, "-public-1a", 128),
+		Subnet1b:   awsName(prefix, "-public-1b", 128),
+		IGW:        awsName(prefix, "-igw", 128),
+		RouteTable: awsName(prefix, "-public-rt", 128),
+
+		ALBSG: awsName(prefix, "-alb-sg", 255),
+		EC2SG: awsName(prefix, "-ec2-sg", 255),
+		ECSSG: awsName(prefix, "-ecs-sg", 255),
+
+		EC2Role:    awsName(prefix, "-ec2-role", 64),
+		EC2Profile: awsName(prefix, "-ec2-profile", 128),
+		Instance:   awsName(prefix, "", 128),
+
+		ECRRepo:     awsName(prefix, "", 256),
+		ALB:         awsName(prefix, "-alb", 32),
+		TargetGroup: awsName(prefix, "-tg", 32),
+
+		Cluster:      awsName(prefix, "-cluster", 255),
+		Service:      awsName(prefix, "-svc", 255),
+		TaskFamily:   awsName(prefix, "", 255),
+		TaskExecRole: awsName(prefix, "-ecs-exec-role", 64),
+		ECSLogGroup:  "/ecs/" + awsName(prefix, "", 255),
+
+		Function:   awsName(prefix, "", 64),
+		LambdaRole: awsName(prefix, "-lambda-role", 64),
+		HTTPAPI:    awsName(prefix, "-api", 128),
+
+		AppRunnerService: awsName(prefix, "", 40),
+		LightsailService: awsName(prefix, "", 63),
+	}
+}
+
+// RollbackStep is one command of a rollback plan. Lookup steps bind resource
+// IDs through Produces (maker plan bindings) for the teardown steps after them.
+type RollbackStep struct {
+	Resource string            `json:"resource"`
+	Name     string            `json:"name"`
+	Args     []string          `json:"args"`
+	Reason   string            `json:"reason,omitempty"`
+	Produces map[string]string `json:"produces,omitempty"`
+}
+
+// RollbackPlan tears down what a deployment creates: lookups first, then the
+// resources in reverse creation order. It is never executed by the
+// intelligence pipeline; MakerPlan turns it into a plan the maker can run.
+type RollbackPlan struct {
+	Provider string         `json:"provider"`
+	Method   string         `json:"method"`
+	Prefix   string         `json:"prefix"`
+	Steps    []RollbackStep `json:"steps"`
+	Notes    []string       `json:"notes,omitempty"`
+}
+
+// rollbackResource is one created resource with the commands that find and
+// remove it.
+type rollbackResource struct {
+	lookups  []RollbackStep
+	teardown []RollbackStep
+}
+
+// BuildRollbackPlan derives the teardown for the resources the chosen deploy
+// method creates, named with the same prefix the deployment prompt uses.
+func BuildRollbackPlan(p *RepoProfile, deep *DeepAnalysis, arch *ArchitectDecision, opts *DeployOptions) *RollbackPlan {
+	if p == nil || arch == nil {
+		return nil
+	}
+	deployID := ""
+	if opts != nil {
+		deployID = opts.DeployID
+	}
+	names := newAWSResourceNames(repoResourcePrefix(p.RepoURL, deployID))
+	plan := &RollbackPlan{
+		Provider: strings.ToLower(strings.TrimSpace(arch.Provider)),
+		Method:   arch.Method,
+		Prefix:   names.Prefix,
+	}
+	if plan.Provider == "" {
+		plan.Provider = "aws"
+	}
+
+	var created []rollbackResource
+	switch {
+	case plan.Provider != "aws":
+		plan.Notes = append(plan.Notes, fmt.Sprintf("No generated rollback for provider %s; delete the resources named with prefix %s", plan.Provider, names.Prefix))
+		return plan
+	case arch.Method == "ec2":
+		created = ec2RollbackResources(names, opts, !IsWordPressRepo(p, deep))
+		if IsOpenClawRepo(p, deep) {
+			plan.Notes = append(plan.Notes, "The CloudFront distribution in front of the ALB is not removed; disable and delete it manually")
+		}
+	case arch.Method == "lambda-apigw":
+		created = lambdaRollbackResources(names, p.HasDocker, deployPartition(opts))
+	case arch.Method == "app-runner":
+		created = appRunnerRollbackResources(names, p.HasDocker)
+	case arch.Method == "lightsail":
+		created = []rollbackResource{{teardown: []RollbackStep{{
+			Resource: "lightsail-container-service", Name: names.LightsailService,
+			Args:   []string{"lightsail", "delete-container-service", "--service-name", names.LightsailService},
+			Reason: "Delete the Lightsail container service and its deployments",
+		}}}}
+	case arch.Method == "ecs-fargate" || arch.Method == "":
+		created = ecsRollbackResources(names, arch, deployPartition(opts))
+	default:
+		plan.Notes = append(plan.Notes, fmt.Sprintf("No generated rollback for method %s; find its resources with: aws resourcegroupstaggingapi get-resources --tag-filters Key=Project,Values=%s", arch.Method, names.Prefix))
+		return plan
+	}
+
+	for _, r := range created {
+		plan.Steps = append(plan.Steps, r.lookups...)
+	}
+	for i := len(created) - 1; i >= 0; i-- {
+		plan.Steps = append(plan.Steps, created[i].teardown...)
+	}
+	if arch.NeedsDB || p.HasDB {
+		plan.Notes = append(plan.Notes, "Databases are not removed by the rollback; snapshot and delete them manually once the data is safe")
+	}
+	return plan
+}
+
+// deployPartition returns the AWS partition the deployment targets.
+func deployPartition(opts *DeployOptions) string {
+	if opts != nil && strings.TrimSpace(opts.Partition) != "" {
+		return strings.TrimSpace(opts.Partition)
+	}
+	return awsclient.PartitionAWS
+}
+
+// rollbackCreators maps a rollback step's Resource to the forward-plan
+// commands that create it, with the flag naming the resource ("" when the
+// name lives in tags).
+var rollbackCreators = map[string][]struct{ service, op, nameFlag string }{
+	"target-group":                {{"elbv2", "create-target-group", "--name"}},
+	"alb":                         {{"elbv2", "create-load-balancer", "--name"}},
+	"security-group":              {{"ec2", "create-security-group", "--group-name"}},
+	"ecr-repository":              {{"ecr", "create-repository", "--repository-name"}},
+	"iam-role":                    {{"iam", "create-role", "--role-name"}},
+	"instance-profile":            {{"iam", "create-instance-profile", "--instance-profile-name"}},
+	"ec2-instance":                {{"ec2", "run-instances", ""}},
+	"vpc":                         {{"ec2", "create-vpc", ""}},
+	"subnet":                      {{"ec2", "create-subnet", ""}},
+	"internet-gateway":            {{"ec2", "create-internet-gateway", ""}},
+	"route-table":                 {{"ec2", "create-route-table", ""}},
+	"ecs-cluster":                 {{"ecs", "create-cluster", "--cluster-name"}},
+	"ecs-service":                 {{"ecs", "create-service", "--service-name"}},
+	"log-group":                   {{"logs", "create-log-group", "--log-group-name"}, {"lambda", "create-function", ""}},
+	"lambda-function":             {{"lambda", "create-function", "--function-name"}},
+	"http-api":                    {{"apigatewayv2", "create-api", "--name"}, {"apigatewayv2", "quick-create", "--name"}},
+	"app-runner-service":          {{"apprunner", "create-service", "--service-name"}},
+	"lightsail-container-service": {{"lightsail", "create-container-service", "--service-name"}},
+}
+
+// ForPlan narrows the rollback to the resources the forward plan creates, so
+// a --destroy run only tears down what the deployment made. A resource is
+// kept when the plan has a command creating it under the same name, or any
+// command creating its kind when the plan names it in tags or through a
+// placeholder. Created resources with no rollback step are listed in Notes.
+func (r *RollbackPlan) ForPlan(plan *maker.Plan) *RollbackPlan {
+	if r == nil || plan == nil || r.Provider != "aws" {
+		return r
+	}
+	type created struct{ service, op, name string }
+	var creates []created
+	for _, cmd := range plan.Commands {
+		if len(cmd.Args) < 2 {
+			continue
+		}
+		c := created{service: strings.ToLower(strings.TrimSpace(cmd.Args[0])), op: strings.ToLower(strings.TrimSpace(cmd.Args[1]))}
+		known := false
+		for _, creators := range rollbackCreators {
+			for _, cr := range creators {
+				if cr.service != c.service || cr.op != c.op {
+					continue
+				}
+				known = true
+				if cr.nameFlag != "" {
+					c.name = strings.TrimSpace(parseFlag(cmd.Args, cr.nameFlag))
+				}
+			}
+		}
+		if known {
+			creates = append(creates, c)
+		}
+	}
+
+	used := make([]bool, len(creates))
+	keep := func(step RollbackStep) bool {
+		found := false
+		for _, cr := range rollbackCreators[step.Resource] {
+			for i, c := range creates {
+				if c.service != cr.service || c.op != cr.op {
+					continue
+				}
+				if cr.nameFlag == "" || c.name == "" || strings.Contains(c.name, "<") || c.name == step.Name {
+					used[i] = true
+					found = true
+				}
+			}
+		}
+		return found
+	}
+
+	out := &RollbackPlan{Provider: r.Provider, Method: r.Method, Prefix: r.Prefix, Notes: append([]string(nil), r.Notes...)}
+	for _, step := range r.Steps {
+		if keep(step) {
+			out.Steps = append(out.Steps, step)
+		}
+	}
+	for i, c := range creates {
+		if used[i] {
+			continue
+		}
+		what := c.service + " " + c.op
+		if c.name != "" {
+			what += " " + c.name
+		}
+		out.Notes = append(out.Notes, "Not covered by the rollback, delete it manually: "+what)
+	}
+	return out
+}
+
+// MakerPlan converts the rollback into a maker plan for a --destroy run.
+func (r *RollbackPlan) MakerPlan() *maker.Plan {
+	if r == nil {
+		return nil
+	}
+	plan := &maker.Plan{
+		Version:  maker.CurrentPlanVersion,
+		Provider: r.Provider,
+		Question: fmt.Sprintf("Roll back deployment %s (%s)", r.Prefix, r.Method),
+		Summary:  fmt.Sprintf("Tear down the %s resources created for %s, in reverse creation order", r.Method, r.Prefix),
+		Notes:    append([]string(nil), r.Notes...),
+	}
+	for _, step := range r.Steps {
+		plan.Commands = append(plan.Commands, maker.Command{
+			Args:     append([]string(nil), step.Args...),
+			Reason:   step.Reason,
+			Produces: step.Produces,
+		})
+	}
+	return plan
+}
+
+func albRollbackResources(names awsResourceNames, instanceTarget bool) []rollbackResource {
+	tg := rollbackResource{
+		lookups: []RollbackStep{{
+			Resource: "target-group", Name: names.TargetGroup,
+			Args:     []string{"elbv2", "describe-target-groups", "--names", names.TargetGroup, "--output", "json"},
+			Reason:   "Look up the target group ARN",
+			Produces: map[string]string{"TG_ARN": "$.TargetGroups[0].TargetGroupArn"},
+		}},
+	}
+	if instanceTarget {
+		tg.teardown = append(tg.teardown, RollbackStep{
+			Resource: "target-group", Name: names.TargetGroup,
+			Args:   []string{"elbv2", "deregister-targets", "--target-group-arn", "<TG_ARN>", "--targets", "Id=<INSTANCE_ID>"},
+			Reason: "Deregister the instance from the target group",
+		})
+	}
+	tg.teardown = append(tg.teardown, RollbackStep{
+		Resource: "target-group", Name: names.TargetGroup,
+		Args:   []string{"elbv2", "delete-target-group", "--target-group-arn", "<TG_ARN>"},
+		Reason: "Delete the target group",
+	})
+
+	alb := rollbackResource{
+		lookups: []RollbackStep{{
+			Resource: "alb", Name: names.ALB,
+			Args:     []string{"elbv2", "describe-load-balancers", "--names", names.ALB, "--output", "json"},
+			Reason:   "Look up the load balancer ARN",
+			Produces: map[string]string{"ALB_ARN": "$.LoadBalancers[0].LoadBalancerArn"},
+		}},
+		teardown: []RollbackStep{
+			{
+				Resource: "alb", Name: names.ALB,
+				Args:   []string{"elbv2", "delete-load-balancer", "--load-balancer-arn", "<ALB_ARN>"},
+				Reason: "Delete the load balancer and its listeners",
+			},
+			{
+				Resource: "alb", Name: names.ALB,
+				Args:   []string{"elbv2", "wait", "load-balancers-deleted", "--load-balancer-arns", "<ALB_ARN>"},
+				Reason: "Wait for the load balancer to release the target group and security group",
+			},
+		},
+	}
+	return []rollbackResource{tg, alb}
+}
+
+func securityGroupRollback(name, binding string) rollbackResource {
+	return rollbackResource{
+		lookups: []RollbackStep{{
+			Resource: "security-group", Name: name,
+			Args:     []string{"ec2", "describe-security-groups", "--filters", "Name=group-name,Values=" + name, "--output", "json"},
+			Reason:   "Look up the security group ID",
+			Produces: map[string]string{binding: "$.SecurityGroups[0].GroupId"},
+		}},
+		teardown: []RollbackStep{{
+			Resource: "security-group", Name: name,
+			Args:   []string{"ec2", "delete-security-group", "--group-id", "<" + binding + ">"},
+			Reason: "Delete the security group",
+		}},
+	}
+}
+
+func ecrRollback(names awsResourceNames) rollbackResource {
+	return rollbackResource{teardown: []RollbackStep{{
+		Resource: "ecr-repository", Name: names.ECRRepo,
+		Args:   []string{"ecr", "delete-repository", "--repository-name", names.ECRRepo, "--force"},
+		Reason: "Delete the ECR repository and its images",
+	}}}
+}
+
+// iamRoleRollback detaches the AWS managed policies at policyPaths (e.g.
+// "service-role/AWSLambdaBasicExecutionRole") in partition, then deletes role.
+func iamRoleRollback(role, partition string, policyPaths ...string) rollbackResource {
+	var r rollbackResource
+	for _, path := range policyPaths {
+		arn := awsclient.ManagedPolicyARN(partition, path)
+		r.teardown = append(r.teardown, RollbackStep{
+			Resource: "iam-role", Name: role,
+			Args:   []string{"iam", "detach-role-policy", "--role-name", role, "--policy-arn", arn},
+			Reason: "Detach the managed policy so the role can be deleted",
+		})
+	}
+	r.teardown = append(r.teardown, RollbackStep{
+		Resource: "iam-role", Name: role,
+		Args:   []string{"iam", "delete-role", "--role-name", role},
+		Reason: "Delete the IAM role",
+	})
+	return r
+}
+
+func ec2RollbackResources(names awsResourceNames, opts *DeployOptions, usesECR bool) []rollbackResource {
+	var created []rollbackResource
+	if opts != nil && opts.NewVPC {
+		created = append(created, vpcRollbackResources(names)...)
+	}
+
+	role := iamRoleRollback(names.EC2Role, deployPartition(opts),
+		"AmazonSSMManagedInstanceCore",
+		"CloudWatchAgentServerPolicy",
+		"AmazonEC2ContainerRegistryReadOnly")
+	profile := rollbackResource{teardown: []RollbackStep{
+		{
+			Resource: "instance-profile", Name: names.EC2Profile,
+			Args:   []string{"iam", "remove-role-from-instance-profile", "--instance-profile-name", names.EC2Profile, "--role-name", names.EC2Role},
+			Reason: "Detach the role from the instance profile",
+		},
+		{
+			Resource: "instance-profile", Name: names.EC2Profile,
+			Args:   []string{"iam", "delete-instance-profile", "--instance-profile-name", names.EC2Profile},
+			Reason: "Delete the instance profile",
+		},
+	}}
+	created = append(created, role, profile)
+	if usesECR {
+		created = append(created, ecrRollback(names))
+	}
+	// The instance group allows traffic from the ALB group, so it is created
+	// after it and deleted before it.
+	created = append(created,
+		securityGroupRollback(names.ALBSG, "ALB_SG_ID"),
+		securityGroupRollback(names.EC2SG, "EC2_SG_ID"))
+
+	created = append(created, rollbackResource{
+		lookups: []RollbackStep{{
+			Resource: "ec2-instance", Name: names.Instance,
+			Args: []string{"ec2", "describe-instances",
+				"--filters", "Name=tag:Name,Values=" + names.Instance, "Name=instance-state-name,Values=pending,running,stopping,stopped",
+				"--output", "json"},
+			Reason:   "Look up the instance ID",
+			Produces: map[string]string{"INSTANCE_ID": "$.Reservations[0].Instances[0].InstanceId"},
+		}},
+		teardown: []RollbackStep{
+			{
+				Resource: "ec2-instance", Name: names.Instance,
+				Args:   []string{"ec2", "terminate-instances", "--instance-ids", "<INSTANCE_ID>"},
+				Reason: "Terminate the instance",
+			},
+			{
+				Resource: "ec2-instance", Name: names.Instance,
+				Args:   []string{"ec2", "wait", "instance-terminated", "--instance-ids", "<INSTANCE_ID>"},
+				Reason: "Wait for termination so its security group can be deleted",
+			},
+		},
+	})
+	// The EC2 deployment always fronts the instance with an ALB.
+	return append(created, albRollbackResources(names, true)...)
+}
+
+func vpcRollbackResources(names awsResourceNames) []rollbackResource {
+	vpc := rollbackResource{
+		lookups: []RollbackStep{{
+			Resource: "vpc", Name: names.VPC,
+			Args:     []string{"ec2", "describe-vpcs", "--filters", "Name=tag:Name,Values=" + names.VPC, "--output", "json"},
+			Reason:   "Look up the VPC ID",
+			Produces: map[string]string{"VPC_ID": "$.Vpcs[0].VpcId"},
+		}},
+		teardown: []RollbackStep{{
+			Resource: "vpc", Name: names.VPC,
+			Args:   []string{"ec2", "delete-vpc", "--vpc-id", "<VPC_ID>"},
+			Reason: "Delete the VPC",
+		}},
+	}
+	subnet := func(name, binding string) rollbackResource {
+		return rollbackResource{
+			lookups: []RollbackStep{{
+				Resource: "subnet", Name: name,
+				Args:     []string{"ec2", "describe-subnets", "--filters", "Name=tag:Name,Values=" + name, "--output", "json"},
+				Reason:   "Look up the subnet ID",
+				Produces: map[string]string{binding: "$.Subnets[0].SubnetId"},
+			}},
+			teardown: []RollbackStep{{
+				Resource: "subnet", Name: name,
+				Args:   []string{"ec2", "delete-subnet", "--subnet-id", "<" + binding + ">"},
+				Reason: "Delete the subnet",
+			}},
+		}
+	}
+	igw := rollbackResource{
+		lookups: []RollbackStep{{
+			Resource: "internet-gateway", Name: names.IGW,
+			Args:     []string{"ec2", "describe-internet-gateways", "--filters", "Name=tag:Name,Values=" + names.IGW, "--output", "json"},
+			Reason:   "Look up the internet gateway ID",
+			Produces: map[string]string{"IGW_ID": "$.InternetGateways[0].InternetGatewayId"},
+		}},
+		teardown: []RollbackStep{
+			{
+				Resource: "internet-gateway", Name: names.IGW,
+				Args:   []string{"ec2", "detach-internet-gateway", "--internet-gateway-id", "<IGW_ID>", "--vpc-id", "<VPC_ID>"},
+				Reason: "Detach the internet gateway from the VPC",
+			},
+			{
+				Resource: "internet-gateway", Name: names.IGW,
+				Args:   []string{"ec2", "delete-internet-gateway", "--internet-gateway-id", "<IGW_ID>"},
+				Reason: "Delete the internet gateway",
+			},
+		},
+	}
+	rt := rollbackResource{
+		lookups: []RollbackStep{{
+			Resource: "route-table", Name: names.RouteTable,
+			Args:   []string{"ec2", "describe-route-tables", "--filters", "Name=tag:Name,Values=" + names.RouteTable, "--output", "json"},
+			Reason: "Look up the route table and its subnet associations",
+			Produces: map[string]string{
+				"RT_ID":      "$.RouteTables[0].RouteTableId",
+				"RT_ASSOC_A": "$.RouteTables[0].Associations[0].RouteTableAssociationId",
+				"RT_ASSOC_B": "$.RouteTables[0].Associations[1].RouteTableAssociationId",
+			},
+		}},
+		teardown: []RollbackStep{
+			{
+				Resource: "route-table", Name: names.RouteTable,
+				Args:   []string{"ec2", "disassociate-route-table", "--association-id", "<RT_ASSOC_A>"},
+				Reason: "Disassociate the route table from the first subnet",
+			},
+			{
+				Resource: "route-table", Name: names.RouteTable,
+				Args:   []string{"ec2", "disassociate-route-table", "--association-id", "<RT_ASSOC_B>"},
+				Reason: "Disassociate the route table from the second subnet",
+			},
+			{
+				Resource: "route-table", Name: names.RouteTable,
+				Args:   []string{"ec2", "delete-route-table", "--route-table-id", "<RT_ID>"},
+				Reason: "Delete the route table",
+			},
+		},
+	}
+	return []rollbackResource{vpc, subnet(names.Subnet1a, "SUBNET_1A_ID"), subnet(names.Subnet1b, "SUBNET_1B_ID"), igw, rt}
+}
+
+func ecsRollbackResources(names awsResourceNames, arch *ArchitectDecision, partition string) []rollbackResource {
+	cluster := rollbackResource{teardown: []RollbackStep{{
+		Resource: "ecs-cluster", Name: names.Cluster,
+		Args:   []string{"ecs", "delete-cluster", "--cluster", names.Cluster},
+		Reason: "Delete the ECS cluster",
+	}}}
+	role := iamRoleRollback(names.TaskExecRole, partition, "service-role/AmazonECSTaskExecutionRolePolicy")
+	logs := rollbackResource{teardown: []RollbackStep{{
+		Resource: "log-group", Name: names.ECSLogGroup,
+		Args:   []string{"logs", "delete-log-group", "--log-group-name", names.ECSLogGroup},
+		Reason: "Delete the task log group",
+	}}}
+	service := rollbackResource{teardown: []RollbackStep{
+		{
+			Resource: "ecs-service", Name: names.Service,
+			Args:   []string{"ecs", "delete-service", "--cluster", names.Cluster, "--service", names.Service, "--force"},
+			Reason: "Delete the ECS service and stop its tasks",
+		},
+		{
+			Resource: "ecs-service", Name: names.Service,
+			Args:   []string{"ecs", "wait", "services-inactive", "--cluster", names.Cluster, "--services", names.Service},
+			Reason: "Wait for tasks to stop so their network interfaces release the security group",
+		},
+	}}
+
+	created := []rollbackResource{ecrRollback(names), cluster, role, logs}
+	if arch.NeedsALB {
+		created = append(created, securityGroupRollback(names.ALBSG, "ALB_SG_ID"))
+	}
+	created = append(created, securityGroupRollback(names.ECSSG, "ECS_SG_ID"), service)
+	if arch.NeedsALB {
+		created = append(created, albRollbackResources(names, false)...)
+	}
+	return created
+}
+
+func lambdaRollbackResources(names awsResourceNames, hasDocker bool, partition string) []rollbackResource {
+	var created []rollbackResource
+	if hasDocker {
+		created = append(created, ecrRollback(names))
+	}
+	created = append(created,
+		iamRoleRollback(names.LambdaRole, partition, "service-role/AWSLambdaBasicExecutionRole"),
+		rollbackResource{teardown: []RollbackStep{
+			{
+				Resource: "lambda-function", Name: names.Function,
+				Args:   []string{"lambda", "delete-function", "--function-name", names.Function},
+				Reason: "Delete the Lambda function",
+			},
+			{
+				Resource: "log-group", Name: "/aws/lambda/" + names.Function,
+				Args:   []string{"logs", "delete-log-group", "--log-group-name", "/aws/lambda/" + names.Function},
+				Reason: "Delete the function's log group",
+			},
+		}},
+		rollbackResource{
+			lookups: []RollbackStep{{
+				Resource: "http-api", Name: names.HTTPAPI,
+				Args:     []string{"apigatewayv2", "get-apis", "--query", fmt.Sprintf("Items[?Name=='%s'] | [0]", names.HTTPAPI), "--output", "json"},
+				Reason:   "Look up the HTTP API ID",
+				Produces: map[string]string{"API_ID": "$.ApiId"},
+			}},
+			teardown: []RollbackStep{{
+				Resource: "http-api", Name: names.HTTPAPI,
+				Args:   []string{"apigatewayv2", "delete-api", "--api-id", "<API_ID>"},
+				Reason: "Delete the HTTP API and its routes, integration and stage",
+			}},
+		},
+	)
+	return created
+}
+
+func appRunnerRollbackResources(names awsResourceNames, hasDocker bool) []rollbackResource {
+	var created []rollbackResource
+	if hasDocker {
+		created = append(created, ecrRollback(names))
+	}
+	created = append(created, rollbackResource{
+		lookups: []RollbackStep{{
+			Resource: "app-runner-service", Name: names.AppRunnerService,
+			Args:     []string{"apprunner", "list-services", "--query", fmt.Sprintf("ServiceSummaryList[?ServiceName=='%s'] | [0]", names.AppRunnerService), "--output", "json"},
+			Reason:   "Look up the App Runner service ARN",
+			Produces: map[string]string{"APPRUNNER_SERVICE_ARN": "$.ServiceArn"},
+		}},
+		teardown: []RollbackStep{{
+			Resource: "app-runner-service", Name: names.AppRunnerService,
+			Args:   []string{"apprunner", "delete-service", "--service-arn", "<APPRUNNER_SERVICE_ARN>"},
+			Reason: "Delete the App Runner service",
+		}},
+	})
+	return created
+}
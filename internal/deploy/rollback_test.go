@@ -0,0 +1,156 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+)
+
+// stepIndex returns the position of the first step whose args start with
+// the given command, or -1.
+func stepIndex(plan *RollbackPlan, args ...string) int {
+	for i, step := range plan.Steps {
+		if len(step.Args) >= len(args) && strings.Join(step.Args[:len(args)], " ") == strings.Join(args, " ") {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestBuildRollbackPlanEC2ReversesCreationOrder(t *testing.T) {
+	p := &RepoProfile{RepoURL: "https://github.com/acme/shop", Language: "node"}
+	arch := &ArchitectDecision{Provider: "aws", Method: "ec2"}
+	opts := &DeployOptions{DeployID: "run-1"}
+
+	plan := BuildRollbackPlan(p, nil, arch, opts)
+	if plan == nil || len(plan.Steps) == 0 {
+		t.Fatal("expected rollback steps")
+	}
+
+	order := [][]string{
+		{"elbv2", "describe-load-balancers"},
+		{"elbv2", "delete-load-balancer"},
+		{"elbv2", "deregister-targets"},
+		{"elbv2", "delete-target-group"},
+		{"ec2", "terminate-instances"},
+		{"ec2", "delete-security-group", "--group-id", "<EC2_SG_ID>"},
+		{"ec2", "delete-security-group", "--group-id", "<ALB_SG_ID>"},
+		{"ecr", "delete-repository"},
+		{"iam", "delete-instance-profile"},
+		{"iam", "delete-role"},
+	}
+	last := -1
+	for _, args := range order {
+		i := stepIndex(plan, args...)
+		if i < 0 {
+			t.Fatalf("missing step %v", args)
+		}
+		if i <= last {
+			t.Errorf("step %v at %d, expected after %d", args, i, last)
+		}
+		last = i
+	}
+
+	// Names must match what the deployment prompt told the planner to create.
+	names := newAWSResourceNames(repoResourcePrefix(p.RepoURL, opts.DeployID))
+	prompt := ec2Prompt(p, arch, &DeepAnalysis{}, opts)
+	for _, name := range []string{names.ALB, names.TargetGroup, names.EC2SG, names.EC2Role} {
+		if !strings.Contains(prompt, name) {
+			t.Errorf("ec2 prompt does not use rollback name %q", name)
+		}
+	}
+	if i := stepIndex(plan, "elbv2", "describe-load-balancers", "--names", names.ALB); i < 0 {
+		t.Errorf("ALB lookup should target %q", names.ALB)
+	}
+}
+
+func TestBuildRollbackPlanFargateWithoutALB(t *testing.T) {
+	p := &RepoProfile{RepoURL: "https://github.com/acme/api"}
+	plan := BuildRollbackPlan(p, nil, &ArchitectDecision{Provider: "aws", Method: "ecs-fargate"}, nil)
+
+	if stepIndex(plan, "elbv2") >= 0 {
+		t.Error("no load balancer steps expected without NeedsALB")
+	}
+	svc := stepIndex(plan, "ecs", "delete-service")
+	cluster := stepIndex(plan, "ecs", "delete-cluster")
+	ecr := stepIndex(plan, "ecr", "delete-repository")
+	if svc < 0 || cluster < 0 || ecr < 0 || !(svc < cluster && cluster < ecr) {
+		t.Errorf("expected service < cluster < ECR, got %d, %d, %d", svc, cluster, ecr)
+	}
+
+	mp := plan.MakerPlan()
+	if len(mp.Commands) != len(plan.Steps) || mp.Provider != "aws" {
+		t.Errorf("maker plan mismatch: %d commands for %d steps", len(mp.Commands), len(plan.Steps))
+	}
+}
+
+func TestBuildRollbackPlanUnsupportedProvider(t *testing.T) {
+	plan := BuildRollbackPlan(&RepoProfile{RepoURL: "https://github.com/acme/site"}, nil, &ArchitectDecision{Provider: "cloudflare", Method: "cf-pages"}, nil)
+	if len(plan.Steps) != 0 || len(plan.Notes) == 0 {
+		t.Errorf("expected a note and no steps, got %+v", plan)
+	}
+}
+
+func TestBuildRollbackPlanGovCloudPolicyARNs(t *testing.T) {
+	p := &RepoProfile{RepoURL: "https://github.com/acme/api"}
+	opts := &DeployOptions{Partition: "aws-us-gov"}
+	plan := BuildRollbackPlan(p, nil, &ArchitectDecision{Provider: "aws", Method: "lambda-apigw"}, opts)
+
+	want := "arn:aws-us-gov:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+	found := false
+	for _, step := range plan.Steps {
+		for _, arg := range step.Args {
+			if strings.HasPrefix(arg, "arn:aws:") {
+				t.Errorf("commercial ARN in GovCloud rollback: %v", step.Args)
+			}
+			found = found || arg == want
+		}
+	}
+	if !found {
+		t.Errorf("expected %s in rollback steps", want)
+	}
+}
+
+func TestRollbackPlanForPlanKeepsCreatedResources(t *testing.T) {
+	p := &RepoProfile{RepoURL: "https://github.com/acme/shop"}
+	opts := &DeployOptions{DeployID: "run-1"}
+	names := newAWSResourceNames(repoResourcePrefix(p.RepoURL, opts.DeployID))
+	rollback := BuildRollbackPlan(p, nil, &ArchitectDecision{Provider: "aws", Method: "ec2"}, opts)
+
+	forward := portPlan(
+		[]string{"ec2", "create-security-group", "--group-name", names.ALBSG, "--description", "alb"},
+		[]string{"ec2", "create-security-group", "--group-name", "shop-web-sg", "--description", "web"},
+		[]string{"iam", "create-role", "--role-name", names.EC2Role},
+		[]string{"ec2", "run-instances", "--image-id", "<AMI_ID>"},
+		[]string{"elbv2", "create-target-group", "--name", "<TG_NAME>"},
+		[]string{"elbv2", "create-load-balancer", "--name", names.ALB},
+		[]string{"elbv2", "create-listener", "--load-balancer-arn", "<ALB_ARN>"},
+	)
+	got := rollback.ForPlan(forward)
+
+	for _, args := range [][]string{
+		{"elbv2", "delete-load-balancer"},
+		{"elbv2", "delete-target-group"},
+		{"ec2", "terminate-instances"},
+		{"ec2", "delete-security-group", "--group-id", "<ALB_SG_ID>"},
+		{"iam", "delete-role"},
+	} {
+		if stepIndex(got, args...) < 0 {
+			t.Errorf("missing step %v for a created resource", args)
+		}
+	}
+	for _, args := range [][]string{
+		{"ecr", "delete-repository"},
+		{"iam", "delete-instance-profile"},
+		{"ec2", "delete-security-group", "--group-id", "<EC2_SG_ID>"},
+	} {
+		if stepIndex(got, args...) >= 0 {
+			t.Errorf("step %v tears down something the plan never created", args)
+		}
+	}
+	if !strings.Contains(strings.Join(got.Notes, "\n"), "ec2 create-security-group shop-web-sg") {
+		t.Errorf("expected a note for the uncovered security group, got %v", got.Notes)
+	}
+	if len(rollback.Steps) <= len(got.Steps) {
+		t.Error("ForPlan must not modify the template rollback")
+	}
+}
@@ -66,7 +66,13 @@ func validationFromPlanChecks(checks awsPlanChecks) deterministicValidation {
 	}
 }
 
+// deployRulePacks returns the built-in rule packs followed by the app packs
+// of recipes added with RegisterDeployRecipe.
 func deployRulePacks() []RulePack {
+	return append(builtinRulePacks(), registeredRecipePacks()...)
+}
+
+func builtinRulePacks() []RulePack {
 	return []RulePack{
 		{
 			Name:  "aws",
@@ -74,6 +80,12 @@ func deployRulePacks() []RulePack {
 			Matches: func(ctx RulePackContext) bool {
 				return ctx.effectivePlanProvider() == "aws"
 			},
+			ApplyPlanAutofix: func(plan *maker.Plan, ctx RulePackContext, logf func(string, ...any)) *maker.Plan {
+				if n := alignPlanPorts(plan, ctx.Deep); n > 0 && logf != nil {
+					logf("[deploy] aws autofix: aligned %d target group and health check port(s) with app port %d", n, ctx.Deep.ListeningPort)
+				}
+				return plan
+			},
 			ValidatePlan: func(plan *maker.Plan, ctx RulePackContext) deterministicValidation {
 				if plan == nil {
 					return deterministicValidation{}
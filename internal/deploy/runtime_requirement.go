@@ -0,0 +1,413 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RuntimeRequirement is the language version the app declares it needs,
+// e.g. node ">=20" from package.json engines or go "1.22" from go.mod.
+type RuntimeRequirement struct {
+	Language   string `json:"language"`   // node, python, go, ruby, java
+	Constraint string `json:"constraint"` // ">=3.11", "^20", "1.22", "~> 3.2"
+	Source     string `json:"source"`     // file the constraint came from
+}
+
+func (r *RuntimeRequirement) String() string {
+	return fmt.Sprintf("%s %s (%s)", r.Language, r.Constraint, r.Source)
+}
+
+var (
+	goModVersionRe        = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+(?:\.\d+)?)\s*$`)
+	goToolchainRe         = regexp.MustCompile(`(?m)^toolchain\s+go(\d+\.\d+(?:\.\d+)?)\s*$`)
+	pyprojectRequiresRe   = regexp.MustCompile(`(?m)^\s*requires-python\s*=\s*["']([^"']+)["']`)
+	poetryPythonRe        = regexp.MustCompile(`(?m)^\s*python\s*=\s*["']([^"']+)["']`)
+	runtimeTxtPythonRe    = regexp.MustCompile(`^python-(\d+\.\d+(?:\.\d+)?)`)
+	gemfileRubyRe         = regexp.MustCompile(`(?m)^\s*ruby\s+["']([^"']+)["']`)
+	pomJavaVersionRe      = regexp.MustCompile(`<(?:java\.version|maven\.compiler\.release|maven\.compiler\.target|maven\.compiler\.source)>\s*(?:1\.)?(\d+)\s*<`)
+	gradleJavaVersionRe   = regexp.MustCompile(`(?:JavaLanguageVersion\.of\(\s*(\d+)\s*\)|JavaVersion\.VERSION_(?:1_)?(\d+))`)
+	toolVersionsLineRe    = regexp.MustCompile(`^(nodejs|python|golang|ruby|java)\s+(\S+)`)
+	versionNumberRe       = regexp.MustCompile(`\d+(?:\.\d+)*`)
+	constraintClauseRe    = regexp.MustCompile(`^(>=|<=|==|!=|~>|~=|>|<|=|\^|~)?\s*v?(\d+(?:\.(?:\d+|x|\*))*)`)
+	lambdaRuntimeRe       = regexp.MustCompile(`^(nodejs|python|java|ruby)(\d+(?:\.\d+)?)(?:\.x)?(?:\.al2)?$`)
+	javaImageTagVersionRe = regexp.MustCompile(`(?:temurin|jdk|jre|corretto|openjdk|java)-?(\d+)`)
+)
+
+// toolVersionsLanguages maps asdf .tool-versions plugin names to languages.
+var toolVersionsLanguages = map[string]string{"nodejs": "node", "python": "python", "golang": "go", "ruby": "ruby", "java": "java"}
+
+// runtimeLanguage is the language whose version matters for the repo: the
+// analyzer's language, or ruby when the repo ships a Gemfile.
+func runtimeLanguage(p *RepoProfile) string {
+	switch p.Language {
+	case "node", "python", "go", "java":
+		return p.Language
+	}
+	if p.KeyFiles["Gemfile"] != "" || p.KeyFiles[".ruby-version"] != "" {
+		return "ruby"
+	}
+	return ""
+}
+
+// detectRuntimeRequirement reads the version the repo declares for its
+// language from the files that pin it, most specific first.
+func detectRuntimeRequirement(p *RepoProfile) *RuntimeRequirement {
+	if p == nil {
+		return nil
+	}
+	language := runtimeLanguage(p)
+	if language == "" {
+		return nil
+	}
+	req := func(constraint, source string) *RuntimeRequirement {
+		constraint = strings.TrimSpace(constraint)
+		if constraint == "" {
+			return nil
+		}
+		return &RuntimeRequirement{Language: language, Constraint: constraint, Source: source}
+	}
+	firstLine := func(name string) string {
+		line, _, _ := strings.Cut(strings.TrimSpace(p.KeyFiles[name]), "\n")
+		return strings.TrimSpace(line)
+	}
+
+	var found *RuntimeRequirement
+	switch language {
+	case "node":
+		var pkg struct {
+			Engines map[string]string `json:"engines"`
+		}
+		if json.Unmarshal([]byte(p.KeyFiles["package.json"]), &pkg) == nil && pkg.Engines["node"] != "" {
+			found = req(pkg.Engines["node"], "package.json engines.node")
+		} else if v := firstLine(".nvmrc"); v != "" && !strings.HasPrefix(v, "lts") {
+			found = req(strings.TrimPrefix(v, "v"), ".nvmrc")
+		} else if v := firstLine(".node-version"); v != "" {
+			found = req(strings.TrimPrefix(v, "v"), ".node-version")
+		}
+	case "python":
+		if m := pyprojectRequiresRe.FindStringSubmatch(p.KeyFiles["pyproject.toml"]); m != nil {
+			found = req(m[1], "pyproject.toml requires-python")
+		} else if m := poetryPythonRe.FindStringSubmatch(p.KeyFiles["pyproject.toml"]); m != nil {
+			found = req(m[1], "pyproject.toml [tool.poetry.dependencies]")
+		} else if v := firstLine(".python-version"); v != "" {
+			found = req(v, ".python-version")
+		} else if m := runtimeTxtPythonRe.FindStringSubmatch(firstLine("runtime.txt")); m != nil {
+			found = req(m[1], "runtime.txt")
+		}
+	case "go":
+		if m := goToolchainRe.FindStringSubmatch(p.KeyFiles["go.mod"]); m != nil {
+			found = req(">="+m[1], "go.mod toolchain")
+		} else if m := goModVersionRe.FindStringSubmatch(p.KeyFiles["go.mod"]); m != nil {
+			// The go directive is a minimum since Go 1.21.
+			found = req(">="+m[1], "go.mod go directive")
+		}
+	case "ruby":
+		if m := gemfileRubyRe.FindStringSubmatch(p.KeyFiles["Gemfile"]); m != nil {
+			found = req(m[1], "Gemfile ruby")
+		} else if v := firstLine(".ruby-version"); v != "" {
+			found = req(strings.TrimPrefix(v, "ruby-"), ".ruby-version")
+		}
+	case "java":
+		if m := pomJavaVersionRe.FindStringSubmatch(p.KeyFiles["pom.xml"]); m != nil {
+			found = req(">="+m[1], "pom.xml")
+		} else {
+			for _, name := range []string{"build.gradle", "build.gradle.kts"} {
+				if m := gradleJavaVersionRe.FindStringSubmatch(p.KeyFiles[name]); m != nil {
+					found = req(">="+m[1]+m[2], name)
+					break
+				}
+			}
+		}
+	}
+	if found != nil {
+		return found
+	}
+	for _, line := range strings.Split(p.KeyFiles[".tool-versions"], "\n") {
+		if m := toolVersionsLineRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil && toolVersionsLanguages[m[1]] == language {
+			if v := versionNumberRe.FindString(m[2]); v != "" {
+				return req(v, ".tool-versions")
+			}
+		}
+	}
+	return nil
+}
+
+// ensureRuntimeRequirement fills deep.RuntimeRequirement from the repo's
+// version files, falling back to the deep analysis's nodeVersion for Node
+// apps, and keeps NodeVersion in step with it.
+func ensureRuntimeRequirement(p *RepoProfile, deep *DeepAnalysis) {
+	if deep == nil {
+		return
+	}
+	if r := detectRuntimeRequirement(p); r != nil {
+		deep.RuntimeRequirement = r
+	} else if p != nil && p.Language == "node" && strings.TrimSpace(deep.NodeVersion) != "" {
+		deep.RuntimeRequirement = &RuntimeRequirement{Language: "node", Constraint: strings.TrimSpace(deep.NodeVersion), Source: "deep analysis"}
+	}
+	if r := deep.RuntimeRequirement; r != nil && r.Language == "node" && deep.NodeVersion == "" {
+		deep.NodeVersion = r.Constraint
+	}
+}
+
+// runtimeRequirementPrompt tells the architect which runtime versions it may
+// choose and asks it to name the one it picked.
+func runtimeRequirementPrompt(r *RuntimeRequirement) string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("\n## Runtime Version\nThe app requires %s %s (from %s). Any Lambda runtime or base image you choose MUST satisfy it. "+
+		"Set \"runtime\" in your JSON to the Lambda runtime id (e.g. nodejs20.x, python3.12) or the container base image (e.g. node:20-slim) the deployment will use.\n",
+		r.Language, r.Constraint, r.Source)
+}
+
+// runtimeVersion reads the language and version out of a Lambda runtime id
+// (nodejs20.x, python3.12, java21) or a container image reference
+// (node:20-alpine, python:3.12-slim, golang:1.22, eclipse-temurin:21-jre).
+// ok is false when the reference names no version, e.g. a latest tag.
+func runtimeVersion(ref string) (language, version string, ok bool) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if m := lambdaRuntimeRe.FindStringSubmatch(ref); m != nil {
+		language = m[1]
+		if language == "nodejs" {
+			language = "node"
+		}
+		return language, m[2], true
+	}
+
+	name, tag, _ := strings.Cut(ref[strings.LastIndex(ref, "/")+1:], ":")
+	tag, _, _ = strings.Cut(tag, "@")
+	switch name {
+	case "node", "nodejs": // nodejs is the Lambda base image (public.ecr.aws/lambda/nodejs:20)
+		language = "node"
+	case "python":
+		language = "python"
+	case "golang":
+		language = "go"
+	case "ruby":
+		language = "ruby"
+	case "java", "openjdk", "eclipse-temurin", "amazoncorretto", "ibm-semeru-runtimes", "sapmachine":
+		if v := versionNumberRe.FindString(tag); v != "" && strings.HasPrefix(tag, v) {
+			major, _, _ := strings.Cut(v, ".")
+			return "java", major, true
+		}
+		return "java", "", false
+	case "maven", "gradle":
+		if m := javaImageTagVersionRe.FindStringSubmatch(tag); m != nil {
+			return "java", m[1], true
+		}
+		return "java", "", false
+	default:
+		return "", "", false
+	}
+	if v := versionNumberRe.FindString(tag); v != "" && strings.HasPrefix(tag, v) {
+		return language, v, true
+	}
+	return language, "", false
+}
+
+// satisfiesConstraint reports whether a runtime version meets a version
+// constraint. It understands comparison operators, npm ^ and ~, Ruby ~>,
+// Python ~= and ==X.*, x wildcards, comma or space separated ranges and ||
+// alternatives. A bare version matches on the parts it gives ("20" matches
+// 20.x). A runtime that only names a major or minor ("20", "3.12") stands for
+// its newest release. known is false when the constraint cannot be parsed.
+func satisfiesConstraint(version, constraint string) (ok, known bool) {
+	have := parseVersionParts(version)
+	if len(have) == 0 {
+		return false, false
+	}
+	for _, alternative := range strings.Split(constraint, "||") {
+		clauses := strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' })
+		// Rejoin an operator split from its version (">= 18").
+		var joined []string
+		for i := 0; i < len(clauses); i++ {
+			c := clauses[i]
+			if strings.Trim(c, "<>=!~^") == "" && i+1 < len(clauses) {
+				c += clauses[i+1]
+				i++
+			}
+			joined = append(joined, c)
+		}
+		if len(joined) == 0 {
+			continue
+		}
+		all := true
+		for _, clause := range joined {
+			match, parsed := satisfiesClause(have, clause)
+			if !parsed {
+				return false, false
+			}
+			if !match {
+				all = false
+			}
+		}
+		if all {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func satisfiesClause(have []int, clause string) (ok, known bool) {
+	m := constraintClauseRe.FindStringSubmatch(strings.TrimSpace(clause))
+	if m == nil {
+		return false, false
+	}
+	op := m[1]
+	raw := strings.Split(m[2], ".")
+	var want []int
+	for _, part := range raw {
+		if part == "x" || part == "*" {
+			break
+		}
+		n, _ := strconv.Atoi(part)
+		want = append(want, n)
+	}
+	wildcard := len(want) < len(raw)
+	cmp := compareVersionParts(have, want)
+
+	switch op {
+	case ">=":
+		return cmp >= 0, true
+	case ">":
+		return cmp > 0 && !prefixMatches(have, want), true
+	case "<=":
+		return cmp <= 0 || prefixMatches(have, want), true
+	case "<":
+		return cmp < 0 && !prefixMatches(have, want), true
+	case "!=":
+		return !prefixMatches(have, want), true
+	case "^":
+		// ^1.2.3 allows anything below the next major (next minor for 0.x).
+		upper := []int{want[0] + 1}
+		if want[0] == 0 && len(want) > 1 {
+			upper = []int{0, want[1] + 1}
+		}
+		return cmp >= 0 && compareVersionParts(have, upper) < 0 && !prefixMatches(have, upper), true
+	case "~", "~>", "~=":
+		// npm ~1.2 pins the minor; Ruby ~> and Python ~= bump the
+		// second-to-last part they were given.
+		var upper []int
+		switch {
+		case op == "~" && len(want) > 1:
+			upper = []int{want[0], want[1] + 1}
+		case op == "~":
+			upper = []int{want[0] + 1}
+		case len(want) == 1:
+			upper = []int{want[0] + 1}
+		default:
+			upper = append(append([]int(nil), want[:len(want)-2]...), want[len(want)-2]+1)
+		}
+		return cmp >= 0 && compareVersionParts(have, upper) < 0 && !prefixMatches(have, upper), true
+	default: // "", "=", "=="
+		if wildcard || op == "" || len(want) < 3 {
+			return prefixMatches(have, want) || prefixMatches(want, have), true
+		}
+		return cmp == 0, true
+	}
+}
+
+// compareVersionParts compares two versions part by part; a missing part on
+// the runtime side counts as the newest release of that line.
+func compareVersionParts(have, want []int) int {
+	for i, w := range want {
+		if i >= len(have) {
+			return 1
+		}
+		if have[i] != w {
+			if have[i] > w {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+// prefixMatches reports whether a starts with every part of prefix.
+func prefixMatches(a, prefix []int) bool {
+	if len(prefix) > len(a) {
+		return false
+	}
+	for i, p := range prefix {
+		if a[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+func parseVersionParts(v string) []int {
+	var parts []int
+	for _, s := range strings.Split(versionNumberRe.FindString(v), ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return parts
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// ValidateRuntimeVersion fails when the architect's runtime or a base image
+// in the repo's Dockerfile runs a version of the app's language that its
+// RuntimeRequirement rules out, which would otherwise only show as a crash
+// or build failure after deploying. References that name no version or
+// another language, and constraints it cannot parse, pass.
+func ValidateRuntimeVersion(arch *ArchitectDecision, deep *DeepAnalysis, p *RepoProfile) error {
+	if deep == nil || deep.RuntimeRequirement == nil {
+		return nil
+	}
+	r := deep.RuntimeRequirement
+	type candidate struct{ ref, where string }
+	var refs []candidate
+	if arch != nil && strings.TrimSpace(arch.Runtime) != "" {
+		refs = append(refs, candidate{arch.Runtime, "the architect's " + arch.Method + " runtime"})
+	}
+	if p != nil {
+		for _, image := range dockerfileBaseImages(p.KeyFiles["Dockerfile"]) {
+			refs = append(refs, candidate{image, "the Dockerfile's base image"})
+		}
+	}
+	for _, c := range refs {
+		language, version, ok := runtimeVersion(c.ref)
+		if !ok || language != r.Language {
+			continue
+		}
+		if match, known := satisfiesConstraint(version, r.Constraint); known && !match {
+			return fmt.Errorf("%s %s runs %s %s, but the app requires %s %s (from %s); use a runtime that satisfies %s",
+				c.where, c.ref, r.Language, version, r.Language, r.Constraint, r.Source, r.Constraint)
+		}
+	}
+	return nil
+}
+
+// dockerfileBaseImages returns the images of the Dockerfile's FROM lines,
+// skipping references to earlier build stages.
+func dockerfileBaseImages(content string) []string {
+	stages := map[string]bool{}
+	var images []string
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(strings.TrimSpace(line))
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		fields = fields[1:]
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if !stages[strings.ToLower(fields[0])] {
+			images = append(images, fields[0])
+		}
+		if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+			stages[strings.ToLower(fields[2])] = true
+		}
+	}
+	return images
+}
@@ -0,0 +1,174 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectRuntimeRequirement(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  *RepoProfile
+		want     string
+		wantNone bool
+	}{
+		{
+			name:    "node engines over nvmrc",
+			profile: &RepoProfile{Language: "node", KeyFiles: map[string]string{"package.json": `{"engines": {"node": ">=20.11"}}`, ".nvmrc": "18\n"}},
+			want:    "node >=20.11 (package.json engines.node)",
+		},
+		{
+			name:    "node nvmrc",
+			profile: &RepoProfile{Language: "node", KeyFiles: map[string]string{"package.json": `{"name": "app"}`, ".nvmrc": "v20.11.1\n"}},
+			want:    "node 20.11.1 (.nvmrc)",
+		},
+		{
+			name:    "python requires-python",
+			profile: &RepoProfile{Language: "python", KeyFiles: map[string]string{"pyproject.toml": "[project]\nname = \"api\"\nrequires-python = \">=3.11,<3.13\"\n"}},
+			want:    "python >=3.11,<3.13 (pyproject.toml requires-python)",
+		},
+		{
+			name:    "go directive",
+			profile: &RepoProfile{Language: "go", KeyFiles: map[string]string{"go.mod": "module example.com/api\n\ngo 1.22.1\n"}},
+			want:    "go >=1.22.1 (go.mod go directive)",
+		},
+		{
+			name:    "ruby from Gemfile",
+			profile: &RepoProfile{Language: "unknown", KeyFiles: map[string]string{"Gemfile": "source 'https://rubygems.org'\nruby '~> 3.2'\n"}},
+			want:    "ruby ~> 3.2 (Gemfile ruby)",
+		},
+		{
+			name:    "java from pom",
+			profile: &RepoProfile{Language: "java", KeyFiles: map[string]string{"pom.xml": "<properties><java.version>17</java.version></properties>"}},
+			want:    "java >=17 (pom.xml)",
+		},
+		{
+			name:    "tool-versions fallback",
+			profile: &RepoProfile{Language: "python", KeyFiles: map[string]string{".tool-versions": "nodejs 20.1.0\npython 3.12.2\n"}},
+			want:    "python 3.12.2 (.tool-versions)",
+		},
+		{
+			name:     "nothing pinned",
+			profile:  &RepoProfile{Language: "rust", KeyFiles: map[string]string{"Cargo.toml": "[package]"}},
+			wantNone: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectRuntimeRequirement(tt.profile)
+			if tt.wantNone {
+				if got != nil {
+					t.Fatalf("got %s, want none", got)
+				}
+				return
+			}
+			if got == nil || got.String() != tt.want {
+				t.Fatalf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSatisfiesConstraint(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		want                bool
+	}{
+		{"20", ">=18.17.0", true},
+		{"18", ">=18.17.0", true},
+		{"16", ">=18", false},
+		{"20", "^18", false},
+		{"18", "^18.2.0", true},
+		{"18", "18.x", true},
+		{"20", "20.11.1", true},
+		{"18", "20.11.1", false},
+		{"20", ">= 18 < 21", true},
+		{"22", ">= 18 < 21", false},
+		{"16", "^14 || ^16", true},
+		{"3.12", ">=3.11,<3.13", true},
+		{"3.13", ">=3.11,<3.13", false},
+		{"3.9", "^3.10", false},
+		{"3.12", "~=3.10", true},
+		{"3.11", "==3.11.*", true},
+		{"3.3", "~> 3.2", true},
+		{"4.0", "~> 3.2", false},
+		{"3.2", "~> 3.2.1", true},
+		{"1.21", ">=1.22.1", false},
+		{"1.22", ">=1.22.1", true},
+		{"21", ">=17", true},
+		{"11", ">=17", false},
+	}
+	for _, tt := range tests {
+		got, known := satisfiesConstraint(tt.version, tt.constraint)
+		if !known || got != tt.want {
+			t.Errorf("satisfiesConstraint(%q, %q) = %v (known %v), want %v", tt.version, tt.constraint, got, known, tt.want)
+		}
+	}
+	if _, known := satisfiesConstraint("20", "lts/iron"); known {
+		t.Error("an unparseable constraint should be unknown")
+	}
+}
+
+func TestRuntimeVersion(t *testing.T) {
+	tests := map[string]string{
+		"nodejs20.x":                        "node 20",
+		"python3.12":                        "python 3.12",
+		"java21":                            "java 21",
+		"node:20-alpine":                    "node 20",
+		"python:3.12-slim@sha256:abc":       "python 3.12",
+		"golang:1.22-bookworm":              "go 1.22",
+		"eclipse-temurin:21-jre":            "java 21",
+		"maven:3.9-eclipse-temurin-17":      "java 17",
+		"public.ecr.aws/lambda/nodejs:18":   "node 18",
+		"registry.local:5000/ruby:3.3-slim": "ruby 3.3",
+		"node:lts":                          "",
+		"gcr.io/distroless/static-debian12": "",
+		"nginx:1.25":                        "",
+	}
+	for ref, want := range tests {
+		language, version, ok := runtimeVersion(ref)
+		got := ""
+		if ok {
+			got = language + " " + version
+		}
+		if got != want {
+			t.Errorf("runtimeVersion(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestValidateRuntimeVersion(t *testing.T) {
+	deep := &DeepAnalysis{RuntimeRequirement: &RuntimeRequirement{Language: "node", Constraint: ">=20", Source: "package.json engines.node"}}
+
+	err := ValidateRuntimeVersion(&ArchitectDecision{Method: "lambda-apigw", Runtime: "nodejs18.x"}, deep, &RepoProfile{})
+	if err == nil || !strings.Contains(err.Error(), "lambda-apigw runtime nodejs18.x runs node 18, but the app requires node >=20 (from package.json engines.node)") {
+		t.Errorf("expected a lambda runtime mismatch, got %v", err)
+	}
+
+	dockerfile := "FROM node:18-alpine AS build\nRUN npm ci\nFROM build AS test\nFROM gcr.io/distroless/nodejs20\n"
+	err = ValidateRuntimeVersion(&ArchitectDecision{Method: "ecs-fargate"}, deep, &RepoProfile{KeyFiles: map[string]string{"Dockerfile": dockerfile}})
+	if err == nil || !strings.Contains(err.Error(), "Dockerfile's base image node:18-alpine") {
+		t.Errorf("expected the build stage's base image to fail, got %v", err)
+	}
+
+	if err := ValidateRuntimeVersion(&ArchitectDecision{Method: "ecs-fargate", Runtime: "node:22-slim"}, deep, &RepoProfile{KeyFiles: map[string]string{"Dockerfile": "FROM node:lts\n"}}); err != nil {
+		t.Errorf("a satisfying runtime and an unversioned image should pass, got %v", err)
+	}
+	if err := ValidateRuntimeVersion(&ArchitectDecision{Runtime: "python3.9"}, deep, nil); err != nil {
+		t.Errorf("a runtime for another language should be ignored, got %v", err)
+	}
+}
+
+func TestEnsureRuntimeRequirementSyncsNodeVersion(t *testing.T) {
+	deep := &DeepAnalysis{}
+	ensureRuntimeRequirement(&RepoProfile{Language: "node", KeyFiles: map[string]string{".node-version": "20.11.0"}}, deep)
+	if deep.NodeVersion != "20.11.0" || deep.RuntimeRequirement == nil {
+		t.Errorf("NodeVersion = %q, requirement %v", deep.NodeVersion, deep.RuntimeRequirement)
+	}
+
+	deep = &DeepAnalysis{NodeVersion: ">=18"}
+	ensureRuntimeRequirement(&RepoProfile{Language: "node", KeyFiles: map[string]string{}}, deep)
+	if deep.RuntimeRequirement == nil || deep.RuntimeRequirement.Source != "deep analysis" {
+		t.Errorf("expected the deep analysis nodeVersion as the requirement, got %v", deep.RuntimeRequirement)
+	}
+}
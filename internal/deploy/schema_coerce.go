@@ -0,0 +1,258 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// strictJSONReminder is appended to a prompt whose response could not be
+// parsed or left out required fields.
+const strictJSONReminder = "\n\nIMPORTANT: your previous response could not be used. Respond with ONE JSON object only: no prose, no markdown fences. Use exactly the field names and value types from the response format above, and include every field (use \"\", 0, false or [] when a value is unknown)."
+
+// missingFieldsError reports required fields an LLM response left out or
+// left empty. The parse functions return it alongside the decoded value.
+type missingFieldsError struct {
+	Fields []string
+}
+
+func (e *missingFieldsError) Error() string {
+	return "missing required fields: " + strings.Join(e.Fields, ", ")
+}
+
+// decodeLLMJSON unmarshals an LLM's JSON object into target, a pointer to a
+// struct. Before decoding it walks the object against target's json tags and
+// coerces the mismatches models commonly produce ("3000" for an int, "yes"
+// for a bool, a bare string for a list); values that cannot be coerced are
+// dropped so the rest of the object still decodes. It returns one warning per
+// coerced, dropped or missing field. When a required field is absent or empty
+// target is still populated and the error is a *missingFieldsError.
+func decodeLLMJSON(raw string, target any, required ...string) ([]string, error) {
+	// strip markdown fences if present
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(raw, "```json")
+	raw = strings.TrimPrefix(raw, "```")
+	raw = strings.TrimSuffix(raw, "```")
+	raw = strings.TrimSpace(raw)
+
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(raw), &obj); err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("expected a JSON object, got null")
+	}
+
+	var warnings []string
+	coerced := coerceToSchema(obj, reflect.TypeOf(target), "", &warnings)
+	normalized, err := json.Marshal(coerced)
+	if err != nil {
+		return warnings, err
+	}
+	if err := json.Unmarshal(normalized, target); err != nil {
+		return warnings, err
+	}
+
+	var missing []string
+	for _, name := range required {
+		if v, ok := obj[name]; !ok || v == nil || v == "" {
+			missing = append(missing, name)
+			warnings = append(warnings, name+": missing")
+		}
+	}
+	if len(missing) > 0 {
+		return warnings, &missingFieldsError{Fields: missing}
+	}
+	return warnings, nil
+}
+
+// coerceToSchema returns v converted to fit t, appending a warning for each
+// conversion. A nil return drops the value.
+func coerceToSchema(v any, t reflect.Type, path string, warnings *[]string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if v == nil {
+		return nil
+	}
+	warnf := func(format string, args ...any) {
+		*warnings = append(*warnings, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			warnf("dropped %s, expected an object", jsonKind(v))
+			return nil
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			val, present := obj[name]
+			if !present {
+				continue
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			if c := coerceToSchema(val, f.Type, fieldPath, warnings); c != nil {
+				obj[name] = c
+			} else {
+				delete(obj, name)
+			}
+		}
+		return obj
+
+	case reflect.Slice:
+		switch val := v.(type) {
+		case []any:
+			out := make([]any, 0, len(val))
+			for i, item := range val {
+				if c := coerceToSchema(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), warnings); c != nil {
+					out = append(out, c)
+				}
+			}
+			return out
+		case string:
+			if strings.TrimSpace(val) == "" {
+				warnf("coerced empty string to an empty list")
+				return []any{}
+			}
+			if t.Elem().Kind() == reflect.String {
+				warnf("coerced string to a one-element list")
+				return []any{val}
+			}
+		case map[string]any:
+			if t.Elem().Kind() == reflect.Struct || t.Elem().Kind() == reflect.Pointer {
+				warnf("coerced object to a one-element list")
+				return []any{coerceToSchema(val, t.Elem(), path+"[0]", warnings)}
+			}
+		}
+		warnf("dropped %s, expected a list", jsonKind(v))
+		return nil
+
+	case reflect.String:
+		switch val := v.(type) {
+		case string:
+			return val
+		case float64:
+			s := strconv.FormatFloat(val, 'f', -1, 64)
+			warnf("coerced number %s to string", s)
+			return s
+		case bool:
+			warnf("coerced bool %t to string", val)
+			return strconv.FormatBool(val)
+		case []any:
+			parts := make([]string, 0, len(val))
+			for _, item := range val {
+				if s, ok := item.(string); ok {
+					parts = append(parts, s)
+				}
+			}
+			if len(parts) == len(val) {
+				warnf("coerced list to string")
+				return strings.Join(parts, ", ")
+			}
+		}
+		warnf("dropped %s, expected a string", jsonKind(v))
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch val := v.(type) {
+		case float64:
+			if val != float64(int64(val)) {
+				warnf("coerced number %v to int %d", val, int64(val))
+				return int64(val)
+			}
+			return val
+		case string:
+			s := strings.TrimSpace(val)
+			if s == "" {
+				warnf("coerced empty string to 0")
+				return 0
+			}
+			if n, err := strconv.Atoi(s); err == nil {
+				warnf("coerced string %q to int", val)
+				return n
+			}
+		}
+		warnf("dropped %s, expected an int", jsonKind(v))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		if s, ok := v.(string); ok {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				warnf("coerced string %q to number", s)
+				return n
+			}
+		}
+		if _, ok := v.(float64); ok {
+			return v
+		}
+		warnf("dropped %s, expected a number", jsonKind(v))
+		return nil
+
+	case reflect.Bool:
+		switch val := v.(type) {
+		case bool:
+			return val
+		case string:
+			switch strings.ToLower(strings.TrimSpace(val)) {
+			case "true", "yes", "y", "1":
+				warnf("coerced string %q to bool", val)
+				return true
+			case "false", "no", "n", "0", "":
+				warnf("coerced string %q to bool", val)
+				return false
+			}
+		case float64:
+			warnf("coerced number %v to bool", val)
+			return val != 0
+		}
+		warnf("dropped %s, expected a bool", jsonKind(v))
+		return nil
+	}
+	return v
+}
+
+// jsonKind names a decoded JSON value's type for warnings.
+func jsonKind(v any) string {
+	switch val := v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", val)
+	case float64:
+		return "number " + strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "object"
+	}
+	return "null"
+}
+
+// retryStrictJSON asks once more with strictJSONReminder appended after the
+// first response failed to parse or missed required fields, and returns
+// whichever attempt is more usable: a clean parse, then a partial one
+// (missing fields only), then the first attempt's result.
+func retryStrictJSON[T any](ctx context.Context, ask AskFunc, clean CleanFunc, prompt string, first *T, firstErr error, parse func(string) (*T, error)) (*T, error) {
+	resp, err := ask(ctx, prompt+strictJSONReminder)
+	if err != nil {
+		return first, firstErr
+	}
+	second, secondErr := parse(clean(resp))
+	if secondErr == nil || (second != nil && first == nil) {
+		return second, secondErr
+	}
+	return first, firstErr
+}
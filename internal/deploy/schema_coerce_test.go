@@ -0,0 +1,126 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDeepAnalysis_CoercesMistypedFields(t *testing.T) {
+	raw := "```json\n" + `{
+  "appDescription": "chat bot",
+  "listeningPort": "3000",
+  "exposesHTTP": "yes",
+  "preferDocker": 1,
+  "services": "api",
+  "nodeVersion": 20,
+  "requiredEnvVars": [{"name": "TOKEN", "required": "true"}],
+  "migrationPlan": "run prisma"
+}` + "\n```"
+	d, err := parseDeepAnalysis(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.ListeningPort != 3000 || !d.ExposesHTTP || !d.PreferDocker {
+		t.Errorf("got port=%d exposesHTTP=%t preferDocker=%t", d.ListeningPort, d.ExposesHTTP, d.PreferDocker)
+	}
+	if len(d.Services) != 1 || d.Services[0] != "api" || d.NodeVersion != "20" {
+		t.Errorf("got services=%v nodeVersion=%q", d.Services, d.NodeVersion)
+	}
+	if len(d.RequiredEnvVars) != 1 || !d.RequiredEnvVars[0].Required {
+		t.Errorf("got requiredEnvVars=%+v", d.RequiredEnvVars)
+	}
+	if d.MigrationPlan != nil {
+		t.Errorf("string migrationPlan should be dropped, got %+v", d.MigrationPlan)
+	}
+
+	warnings := strings.Join(d.ParseWarnings, "\n")
+	for _, want := range []string{
+		`listeningPort: coerced string "3000" to int`,
+		`exposesHTTP: coerced string "yes" to bool`,
+		`preferDocker: coerced number 1 to bool`,
+		`services: coerced string to a one-element list`,
+		`nodeVersion: coerced number 20 to string`,
+		`requiredEnvVars[0].required: coerced string "true" to bool`,
+		`migrationPlan: dropped string "run prisma", expected an object`,
+	} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("missing warning %q in:\n%s", want, warnings)
+		}
+	}
+}
+
+func TestParseDeepAnalysis_MissingRequiredField(t *testing.T) {
+	d, err := parseDeepAnalysis(`{"appDescription": "", "listeningPort": 8080}`)
+	var missing *missingFieldsError
+	if !errors.As(err, &missing) || len(missing.Fields) != 1 || missing.Fields[0] != "appDescription" {
+		t.Fatalf("err = %v, want missing appDescription", err)
+	}
+	if d == nil || d.ListeningPort != 8080 {
+		t.Fatalf("partial analysis not returned: %+v", d)
+	}
+	if len(d.ParseWarnings) != 1 || d.ParseWarnings[0] != "appDescription: missing" {
+		t.Errorf("ParseWarnings = %v", d.ParseWarnings)
+	}
+
+	if _, err := parseDeepAnalysis("not json"); err == nil || errors.As(err, &missing) {
+		t.Errorf("invalid JSON: err = %v, want a parse error", err)
+	}
+}
+
+func TestParseArchitectDecision_CoercesFlags(t *testing.T) {
+	d, err := ParseArchitectDecision(`{"method":"ecs-fargate","needsAlb":"true","needsDb":"no","notes":"use port 80"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.NeedsALB || d.NeedsDB || len(d.Notes) != 1 {
+		t.Errorf("got needsAlb=%t needsDb=%t notes=%v", d.NeedsALB, d.NeedsDB, d.Notes)
+	}
+	if len(d.ParseWarnings) != 3 {
+		t.Errorf("ParseWarnings = %v, want 3", d.ParseWarnings)
+	}
+
+	d, err = ParseArchitectDecision(`{"reasoning":"small api"}`)
+	var missing *missingFieldsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("err = %v, want missing method", err)
+	}
+	if d.Method != "ecs-fargate" || d.Provider != "aws" {
+		t.Errorf("defaults not applied: %s/%s", d.Provider, d.Method)
+	}
+}
+
+func TestRetryStrictJSON(t *testing.T) {
+	var prompts []string
+	reply := func(responses ...string) AskFunc {
+		prompts = nil
+		return func(_ context.Context, prompt string) (string, error) {
+			prompts = append(prompts, prompt)
+			return responses[len(prompts)-1], nil
+		}
+	}
+	clean := func(s string) string { return s }
+
+	first, firstErr := parseDeepAnalysis(`{"listeningPort": 3000}`)
+	got, err := retryStrictJSON(context.Background(), reply(`{"appDescription":"api"}`), clean, "PROMPT", first, firstErr, parseDeepAnalysis)
+	if err != nil || got.AppDescription != "api" {
+		t.Fatalf("got %+v, %v; want the retried analysis", got, err)
+	}
+	if len(prompts) != 1 || !strings.HasPrefix(prompts[0], "PROMPT") || !strings.HasSuffix(prompts[0], strictJSONReminder) {
+		t.Errorf("retry prompt = %q", prompts)
+	}
+
+	// A retry that is not even JSON keeps the first, partial analysis.
+	got, err = retryStrictJSON(context.Background(), reply("sorry"), clean, "PROMPT", first, firstErr, parseDeepAnalysis)
+	if got != first || err != firstErr {
+		t.Errorf("got %+v, %v; want the first attempt", got, err)
+	}
+
+	// A partial retry beats an unparseable first attempt.
+	got, err = retryStrictJSON(context.Background(), reply(`{"listeningPort": 80}`), clean, "PROMPT", nil, errors.New("bad json"), parseDeepAnalysis)
+	var missing *missingFieldsError
+	if got == nil || got.ListeningPort != 80 || !errors.As(err, &missing) {
+		t.Errorf("got %+v, %v; want the partial retry", got, err)
+	}
+}
@@ -0,0 +1,336 @@
+package deploy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// StatefulnessReport is the deterministic answer to whether an app keeps
+// state on local disk or in process memory, which stateless targets
+// (Fargate, Lambda, App Runner, Cloud Run, App Platform) lose on redeploy.
+type StatefulnessReport struct {
+	Stateful bool `json:"stateful"`
+	// App names a known stateful app (openclaw, wordpress) when one matched.
+	App           string   `json:"app,omitempty"`
+	LocalDBFiles  []string `json:"localDbFiles,omitempty"`  // sqlite files and embedded DB drivers
+	Volumes       []string `json:"volumes,omitempty"`       // compose named volumes and data bind mounts
+	SessionStores []string `json:"sessionStores,omitempty"` // in-memory or on-disk session stores
+}
+
+// Signals lists every reason the app was judged stateful.
+func (r StatefulnessReport) Signals() []string {
+	var signals []string
+	if r.App != "" {
+		signals = append(signals, "known stateful app: "+r.App)
+	}
+	for _, s := range r.LocalDBFiles {
+		signals = append(signals, "local database: "+s)
+	}
+	for _, s := range r.Volumes {
+		signals = append(signals, "compose volume: "+s)
+	}
+	for _, s := range r.SessionStores {
+		signals = append(signals, "session store: "+s)
+	}
+	return signals
+}
+
+// statefulPlacement is where a stateful app runs on one provider.
+type statefulPlacement struct {
+	Method    string
+	Reasoning string
+	NeedsALB  bool
+	// OverrideTarget also replaces an explicit --target other than fargate.
+	OverrideTarget bool
+}
+
+// statefulAppPlacements holds each known stateful app's placement by
+// provider, keyed by the name of the app rule pack that recognizes it.
+var statefulAppPlacements = map[string]map[string]statefulPlacement{
+	"openclaw": {
+		"aws": {
+			Method:    "ec2",
+			Reasoning: "OpenClaw is a stateful, long-running gateway; EC2 is the safest default on AWS for persistent local state + websocket workloads",
+		},
+		"digitalocean": {
+			Method:         "do-droplet",
+			Reasoning:      "OpenClaw stays stateful on a Droplet while App Platform supplies managed HTTPS without requiring a user domain",
+			OverrideTarget: true,
+		},
+	},
+	"wordpress": {
+		"aws": {
+			Method:         "ec2",
+			Reasoning:      "WordPress one-click deploy: run wordpress + mariadb (Docker Hub images) on EC2 and expose via an ALB (health check /wp-login.php); persist DB + wp-content via Docker volumes",
+			NeedsALB:       true,
+			OverrideTarget: true,
+		},
+	},
+}
+
+// statelessMethods are methods whose local disk and memory do not survive a
+// redeploy, mapped by provider to the persistent-disk method used instead.
+var statelessMethods = map[string]map[string]string{
+	"aws":          {"ecs-fargate": "ec2", "fargate": "ec2", "lambda": "ec2", "lambda-apigw": "ec2", "app-runner": "ec2", "apprunner": "ec2"},
+	"gcp":          {"cloud-run": "gcp-compute-engine"},
+	"azure":        {"azure-container-apps": "azure-vm", "container-apps": "azure-vm"},
+	"digitalocean": {"do-app-platform": "do-droplet"},
+}
+
+// embeddedDBDependencies are packages that always keep a database in a
+// local file.
+var embeddedDBDependencies = []string{"lowdb", "nedb", "tinydb"}
+
+// sqliteDependencies are sqlite drivers. Apps often use them only for tests
+// or local development, so they count only alongside a sqlite path in
+// production config.
+var sqliteDependencies = []string{"better-sqlite3", "sqlite3", "aiosqlite"}
+
+// productionConfigFiles are the key files that describe how the app runs
+// when deployed, as opposed to how it is developed or tested.
+var productionConfigFiles = []string{
+	"Dockerfile", "dockerfile",
+	"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml",
+	"fly.toml", "render.yaml", "Procfile", "railway.json", "railway.toml",
+	".env.example", ".env.sample", ".env.template", ".env.production",
+}
+
+// sqlitePathRe matches sqlite URLs and database file paths in config.
+var sqlitePathRe = regexp.MustCompile(`(?i)sqlite[0-9]*:/*[^\s"'\x60]+|[\w./~-]+\.(?:sqlite3?|db)\b`)
+
+// backingServiceImages are compose images for databases, caches and queues;
+// their volumes belong to the backing service, not the app.
+var backingServiceImages = []string{"postgres", "postgis", "mysql", "mariadb", "mongo", "redis", "valkey", "memcached", "rabbitmq", "elasticsearch", "opensearch", "clickhouse", "cassandra", "minio"}
+
+// sessionStoreMarkers are session stores that keep sessions in process memory
+// or on local disk.
+var sessionStoreMarkers = []string{"new MemoryStore", "\"memorystore\"", "session-file-store", "SESSION_TYPE = \"filesystem\"", "SESSION_TYPE = 'filesystem'", "django.contrib.sessions.backends.file"}
+
+// sharedSessionStores back express-session with something that outlives the
+// process.
+var sharedSessionStores = []string{"connect-redis", "connect-mongo", "connect-pg-simple", "express-mysql-session", "connect-dynamodb", "@quixo3/prisma-session-store"}
+
+// dataMountHints mark bind-mount container paths that hold app data rather
+// than config or source.
+var dataMountHints = []string{"data", "db", "storage", "uploads", "state", "workspace", "/var/lib/"}
+
+// detectStatefulness flags sqlite paths in production config, the app
+// service's compose volumes that hold data, and in-memory session stores.
+// Docker analysis is derived from the profile when docker is nil.
+func detectStatefulness(profile *RepoProfile, deep *DeepAnalysis, docker *DockerAnalysis) StatefulnessReport {
+	var report StatefulnessReport
+	if profile == nil {
+		return report
+	}
+	for _, pack := range matchingRulePacks(RulePackContext{Profile: profile, Deep: deep}, rulePackScopeApp) {
+		if _, ok := statefulAppPlacements[pack.Name]; ok {
+			report.App = pack.Name
+			break
+		}
+	}
+
+	// A sqlite file checked into the tree or a sqlite driver in the
+	// dependencies is often only a dev or test database; the app is stateful
+	// when the config it deploys with points at one.
+	var config strings.Builder
+	for _, name := range productionConfigFiles {
+		config.WriteString(profile.KeyFiles[name])
+		config.WriteString("\n")
+	}
+	report.LocalDBFiles = append(report.LocalDBFiles, sqlitePathRe.FindAllString(config.String(), -1)...)
+	if len(report.LocalDBFiles) > 0 {
+		for _, dep := range sqliteDependencies {
+			if hasDependency(profile, dep) {
+				report.LocalDBFiles = append(report.LocalDBFiles, dep)
+			}
+		}
+	}
+	for _, dep := range embeddedDBDependencies {
+		if hasDependency(profile, dep) {
+			report.LocalDBFiles = append(report.LocalDBFiles, dep)
+		}
+	}
+
+	if docker == nil {
+		docker = AnalyzeDockerAgent(profile)
+	}
+	for _, mount := range appServiceMounts(docker) {
+		if isDataVolume(mount) {
+			report.Volumes = append(report.Volumes, mount)
+		}
+	}
+
+	for _, marker := range sessionStoreMarkers {
+		if keyFileMentions(profile, marker) {
+			report.SessionStores = append(report.SessionStores, marker)
+		}
+	}
+	if keyFileMentions(profile, "\"express-session\"") && !keyFileMentionsAny(profile, sharedSessionStores) {
+		report.SessionStores = append(report.SessionStores, "express-session default MemoryStore")
+	}
+
+	report.LocalDBFiles = uniqueStrings(report.LocalDBFiles)
+	report.Volumes = uniqueStrings(report.Volumes)
+	report.SessionStores = uniqueStrings(report.SessionStores)
+	report.Stateful = report.App != "" || len(report.LocalDBFiles) > 0 || len(report.Volumes) > 0 || len(report.SessionStores) > 0
+	return report
+}
+
+// appServiceMounts returns the compose mounts of the service that runs the
+// app, skipping database and cache sidecars whose volumes a managed service
+// or the sidecar itself owns. Without per-service detail it falls back to
+// every mount.
+func appServiceMounts(docker *DockerAnalysis) []string {
+	if len(docker.Services) == 0 {
+		return docker.VolumeMounts
+	}
+	var names []string
+	byName := make(map[string]ComposeService, len(docker.Services))
+	for _, svc := range docker.Services {
+		if isBackingServiceImage(svc.Image) {
+			continue
+		}
+		names = append(names, svc.Name)
+		byName[svc.Name] = svc
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return byName[choosePrimaryService(names)].VolumeMounts
+}
+
+// isBackingServiceImage reports whether a compose image is a database, cache
+// or queue, ignoring registry, namespace and tag.
+func isBackingServiceImage(image string) bool {
+	image = strings.ToLower(strings.TrimSpace(image))
+	if image == "" {
+		return false
+	}
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	for _, name := range backingServiceImages {
+		if strings.HasPrefix(image, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDependency reports whether package.json or requirements.txt lists dep.
+func hasDependency(profile *RepoProfile, dep string) bool {
+	return keyFileMentions(profile, "\""+dep+"\"") || keyFileLineStarts(profile, "requirements.txt", dep)
+}
+
+// isDataVolume reports whether a compose mount keeps data: any named volume,
+// or a writable bind mount into a data-looking path.
+func isDataVolume(mount string) bool {
+	parts := strings.Split(mount, ":")
+	if len(parts) < 2 {
+		return false
+	}
+	host, container := parts[0], parts[1]
+	if host == "" {
+		return false
+	}
+	if len(parts) > 2 && strings.Contains(parts[2], "ro") {
+		return false
+	}
+	if !strings.ContainsAny(host[:1], "./~$") {
+		return true
+	}
+	container = strings.ToLower(container)
+	for _, hint := range dataMountHints {
+		if strings.Contains(container, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func keyFileMentions(profile *RepoProfile, needle string) bool {
+	for _, content := range profile.KeyFiles {
+		if strings.Contains(content, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func keyFileMentionsAny(profile *RepoProfile, needles []string) bool {
+	for _, needle := range needles {
+		if keyFileMentions(profile, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFileLineStarts reports whether a line of the named key file starts with
+// prefix, as a requirement does in requirements.txt.
+func keyFileLineStarts(profile *RepoProfile, file, prefix string) bool {
+	for _, line := range strings.Split(profile.KeyFiles[file], "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == prefix || strings.HasPrefix(line, prefix+"=") || strings.HasPrefix(line, prefix+">") || strings.HasPrefix(line, prefix+"<") || strings.HasPrefix(line, prefix+"~") {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyStatefulArchitectureDefaults steers stateful apps away from stateless
+// targets. Known apps use their own placement; other stateful apps move from
+// a stateless method to the provider's persistent-disk method unless the
+// user asked for a specific target.
+func ApplyStatefulArchitectureDefaults(targetProvider string, opts *DeployOptions, report StatefulnessReport, arch *ArchitectDecision) bool {
+	if arch == nil || !report.Stateful {
+		return false
+	}
+	provider := strings.ToLower(strings.TrimSpace(targetProvider))
+	if provider == "" {
+		provider = "aws"
+	}
+	target := ""
+	if opts != nil {
+		target = strings.TrimSpace(opts.Target)
+	}
+	defaultTarget := target == "" || target == "fargate"
+
+	if report.App != "" {
+		return applyStatefulAppPlacement(report.App, provider, defaultTarget, arch)
+	}
+
+	method := strings.ToLower(strings.TrimSpace(arch.Method))
+	if provider == "digitalocean" {
+		method = normalizeDOMethod(method)
+	}
+	replacement, ok := statelessMethods[provider][method]
+	if !ok {
+		return false
+	}
+	if !defaultTarget {
+		arch.Notes = append(arch.Notes, fmt.Sprintf("%s does not persist local state across deploys; detected %s", arch.Method, strings.Join(report.Signals(), ", ")))
+		return false
+	}
+	arch.Provider = provider
+	arch.Method = replacement
+	arch.Reasoning = fmt.Sprintf("App keeps local state (%s) that %s would lose on redeploy; %s keeps it on a persistent disk", strings.Join(report.Signals(), ", "), method, replacement)
+	return true
+}
+
+// applyStatefulAppPlacement applies a known app's placement for provider.
+func applyStatefulAppPlacement(name, provider string, defaultTarget bool, arch *ArchitectDecision) bool {
+	placement, ok := statefulAppPlacements[name][provider]
+	if !ok || (!defaultTarget && !placement.OverrideTarget) {
+		return false
+	}
+	arch.Provider = provider
+	arch.Method = placement.Method
+	arch.Reasoning = placement.Reasoning
+	if placement.NeedsALB {
+		arch.NeedsALB = true
+		arch.UseAPIGateway = false
+	}
+	return true
+}
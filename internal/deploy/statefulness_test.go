@@ -0,0 +1,94 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectStatefulness(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *RepoProfile
+		want    bool
+	}{
+		{"stateless api", &RepoProfile{Ports: []int{3000}, KeyFiles: map[string]string{"package.json": `{"dependencies": {"express": "^4"}}`}}, false},
+		{"sqlite file only in tree", &RepoProfile{DBType: "sqlite", FileTree: "src/\n  app.js\ntest/\n  fixtures.db\n"}, false},
+		{"sqlite driver without production path", &RepoProfile{DBType: "sqlite", KeyFiles: map[string]string{"package.json": `{"devDependencies": {"better-sqlite3": "^9"}}`}}, false},
+		{"sqlite url in env example", &RepoProfile{KeyFiles: map[string]string{".env.example": "DATABASE_URL=sqlite:///data/app.db\n"}}, true},
+		{"sqlite path in Dockerfile", &RepoProfile{KeyFiles: map[string]string{"Dockerfile": "FROM node:20\nENV DB_PATH=/data/app.sqlite\n"}}, true},
+		{"embedded db dependency", &RepoProfile{KeyFiles: map[string]string{"package.json": `{"dependencies": {"lowdb": "^7"}}`}}, true},
+		{"named compose volume", &RepoProfile{HasCompose: true, KeyFiles: map[string]string{"docker-compose.yml": "services:\n  app:\n    volumes:\n      - appdata:/srv\n"}}, true},
+		{"read-only config bind mount", &RepoProfile{HasCompose: true, KeyFiles: map[string]string{"docker-compose.yml": "services:\n  app:\n    volumes:\n      - ./nginx.conf:/etc/nginx/nginx.conf:ro\n"}}, false},
+		{"data bind mount", &RepoProfile{HasCompose: true, KeyFiles: map[string]string{"docker-compose.yml": "services:\n  app:\n    volumes:\n      - ./uploads:/app/uploads\n"}}, true},
+		{"database sidecar volume", &RepoProfile{HasCompose: true, KeyFiles: map[string]string{"docker-compose.yml": "services:\n  api:\n    build: .\n  db:\n    image: postgres:16\n    volumes:\n      - pgdata:/var/lib/postgresql/data\n  cache:\n    image: redis:7\n    volumes:\n      - redisdata:/data\nvolumes:\n  pgdata:\n  redisdata:\n"}}, false},
+		{"app volume beside a database", &RepoProfile{HasCompose: true, KeyFiles: map[string]string{"docker-compose.yml": "services:\n  web:\n    build: .\n    volumes:\n      - uploads:/app/uploads\n  db:\n    image: bitnami/postgresql\n    volumes:\n      - pgdata:/bitnami\nvolumes:\n  uploads:\n  pgdata:\n"}}, true},
+		{"express-session default store", &RepoProfile{KeyFiles: map[string]string{"package.json": `{"dependencies": {"express-session": "^1"}}`}}, true},
+		{"express-session with redis", &RepoProfile{KeyFiles: map[string]string{"package.json": `{"dependencies": {"express-session": "^1", "connect-redis": "^7"}}`}}, false},
+		{"flask filesystem sessions", &RepoProfile{KeyFiles: map[string]string{"config.py": `SESSION_TYPE = "filesystem"`}}, true},
+		{"known app", &RepoProfile{RepoURL: "https://github.com/openclaw/openclaw"}, true},
+	}
+	for _, tt := range tests {
+		report := detectStatefulness(tt.profile, nil, nil)
+		if report.Stateful != tt.want {
+			t.Errorf("%s: Stateful = %t, want %t (signals %v)", tt.name, report.Stateful, tt.want, report.Signals())
+		}
+	}
+}
+
+func TestApplyStatefulArchitectureDefaults(t *testing.T) {
+	sqlite := detectStatefulness(&RepoProfile{KeyFiles: map[string]string{
+		".env.example": "DATABASE_URL=sqlite:///data/app.db\n",
+		"package.json": `{"dependencies": {"sqlite3": "^5"}}`,
+	}}, nil, nil)
+	tests := []struct {
+		name       string
+		provider   string
+		opts       *DeployOptions
+		method     string
+		wantMethod string
+	}{
+		{"fargate to ec2", "aws", &DeployOptions{}, "ecs-fargate", "ec2"},
+		{"unset provider is aws", "", nil, "lambda-apigw", "ec2"},
+		{"cloud run to compute engine", "gcp", nil, "cloud-run", "gcp-compute-engine"},
+		{"app platform to droplet", "digitalocean", nil, "app-platform", "do-droplet"},
+		{"persistent method kept", "aws", nil, "eks", "eks"},
+		{"explicit target kept", "aws", &DeployOptions{Target: "eks"}, "ecs-fargate", "ecs-fargate"},
+	}
+	for _, tt := range tests {
+		arch := &ArchitectDecision{Provider: tt.provider, Method: tt.method}
+		ApplyStatefulArchitectureDefaults(tt.provider, tt.opts, sqlite, arch)
+		if arch.Method != tt.wantMethod {
+			t.Errorf("%s: method = %q, want %q", tt.name, arch.Method, tt.wantMethod)
+		}
+	}
+
+	arch := &ArchitectDecision{Method: "ecs-fargate"}
+	ApplyStatefulArchitectureDefaults("aws", &DeployOptions{Target: "eks"}, sqlite, arch)
+	if len(arch.Notes) != 1 || !strings.Contains(arch.Notes[0], "local database: sqlite:///data/app.db, local database: sqlite3") {
+		t.Errorf("expected a persistence note for an explicit stateless target, got %v", arch.Notes)
+	}
+}
+
+func TestKnownStatefulAppPlacements(t *testing.T) {
+	openclaw := &RepoProfile{RepoURL: "https://github.com/openclaw/openclaw"}
+	arch := &ArchitectDecision{Provider: "digitalocean", Method: "do-app-platform"}
+	if !ApplyOpenClawArchitectureDefaults("digitalocean", &DeployOptions{Target: "do-app-platform"}, openclaw, nil, arch) || arch.Method != "do-droplet" {
+		t.Errorf("OpenClaw on DigitalOcean should always use a Droplet, got %s", arch.Method)
+	}
+	arch = &ArchitectDecision{Method: "ecs-fargate"}
+	if ApplyOpenClawArchitectureDefaults("aws", &DeployOptions{Target: "eks"}, openclaw, nil, arch) {
+		t.Errorf("OpenClaw on AWS should respect an explicit target, got %s", arch.Method)
+	}
+
+	wordpress := &RepoProfile{RepoURL: "https://github.com/docker-library/wordpress"}
+	arch = &ArchitectDecision{Method: "lambda", UseAPIGateway: true}
+	if !ApplyWordPressArchitectureDefaults("", &DeployOptions{Target: "eks"}, wordpress, nil, arch) {
+		t.Fatal("expected WordPress override")
+	}
+	if arch.Method != "ec2" || !arch.NeedsALB || arch.UseAPIGateway {
+		t.Errorf("WordPress should get EC2 + ALB, got %+v", arch)
+	}
+	if ApplyWordPressArchitectureDefaults("gcp", nil, wordpress, nil, &ArchitectDecision{Method: "cloud-run"}) {
+		t.Error("WordPress has no GCP placement")
+	}
+}
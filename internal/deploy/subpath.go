@@ -0,0 +1,115 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// workspaceRootFiles are the repo-root files kept for context when a profile
+// is scoped to one workspace: they describe how the monorepo is wired.
+var workspaceRootFiles = []string{
+	"package.json",
+	"pnpm-workspace.yaml",
+	"turbo.json",
+	"nx.json",
+	"lerna.json",
+	"go.work",
+	"tsconfig.json",
+	"tsconfig.base.json",
+}
+
+// rootKeyFilePrefix marks repo-root files in a workspace-scoped profile's
+// KeyFiles, keeping them from shadowing the workspace's own files.
+const rootKeyFilePrefix = "root/"
+
+// normalizeSubPath cleans a workspace path relative to the repo root. It
+// returns "" for the root itself and an error for paths that escape the repo.
+func normalizeSubPath(subPath string) (string, error) {
+	subPath = strings.TrimSpace(filepath.ToSlash(subPath))
+	if subPath == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(subPath, "/") {
+		return "", fmt.Errorf("sub path %q must be relative to the repo root", subPath)
+	}
+	cleaned := path.Clean(subPath)
+	if cleaned == "." {
+		return "", nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("sub path %q escapes the repo root", subPath)
+	}
+	return cleaned, nil
+}
+
+// ScopeProfileToSubPath re-runs static analysis on one workspace of a
+// monorepo so exploration and the architect see a single service. ClonePath
+// stays at the repo root. Workspace KeyFiles are keyed by bare name, so
+// lookups such as KeyFiles["Dockerfile"] find the service's own files, and
+// root-level workspace config is kept for context under rootKeyFilePrefix.
+func ScopeProfileToSubPath(profile *RepoProfile, subPath string) error {
+	if profile == nil {
+		return nil
+	}
+	rel, err := normalizeSubPath(subPath)
+	if err != nil || rel == "" {
+		return err
+	}
+
+	dir := filepath.Join(profile.ClonePath, filepath.FromSlash(rel))
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("sub path %q is not a directory in the repo", rel)
+	}
+
+	scoped, err := Analyze(dir)
+	if err != nil {
+		return fmt.Errorf("failed to analyze %s: %w", rel, err)
+	}
+	scoped.RepoURL = profile.RepoURL
+	scoped.ClonePath = profile.ClonePath
+	scoped.SubPath = rel
+	scoped.IsMonorepo = true
+	// Workspaces usually share the root's package manager and lockfile.
+	if scoped.PackageManager == "" {
+		scoped.PackageManager = profile.PackageManager
+	}
+	if len(scoped.LockFiles) == 0 {
+		scoped.LockFiles = profile.LockFiles
+	}
+	if scoped.RepoConfig == nil {
+		scoped.RepoConfig = profile.RepoConfig
+	}
+
+	scoped.KeyFiles = make(map[string]string)
+	for name, content := range readKeyFiles(dir) {
+		scoped.KeyFiles[name] = content
+	}
+	for _, name := range workspaceRootFiles {
+		if content, ok := profile.KeyFiles[name]; ok {
+			scoped.KeyFiles[rootKeyFilePrefix+name] = content
+		}
+	}
+	scoped.FileTree = rel + "/\n" + buildFileTree(dir, "  ", 0)
+	scoped.Summary = buildSummary(scoped)
+
+	*profile = *scoped
+	return nil
+}
+
+// subPathPromptSection tells a phase prompt which workspace is being
+// deployed; it is empty for whole-repo deploys.
+func subPathPromptSection(p *RepoProfile) string {
+	if p == nil || p.SubPath == "" {
+		return ""
+	}
+	return fmt.Sprintf(`
+## Monorepo Workspace
+Only the workspace at %s is being deployed. Design the build, runtime and infrastructure for this one service.
+Root-level files (package.json, workspace config, lockfiles) are shown for context: the build may need to run from the repo root (e.g. "pnpm --filter" or "turbo run build --filter"), but do not deploy the other workspaces.
+Key files are shown relative to the workspace; repo-root files are prefixed with root/. Paths in commands are relative to the repo root.
+`, p.SubPath)
+}
@@ -0,0 +1,94 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSubPath(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{".", "", false},
+		{"./packages/api/", "packages/api", false},
+		{"apps//web", "apps/web", false},
+		{"../other", "", true},
+		{"packages/../../x", "", true},
+		{"/abs/path", "", true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeSubPath(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("normalizeSubPath(%q) = %q, %v; want %q, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestScopeProfileToSubPath(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		fp := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fp, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("package.json", `{"name":"root","private":true,"workspaces":["packages/*"]}`)
+	write("pnpm-workspace.yaml", "packages:\n  - packages/*\n")
+	write("pnpm-lock.yaml", "lockfileVersion: 9\n")
+	write("packages/api/package.json", `{"name":"api","dependencies":{"express":"^4"},"scripts":{"start":"node index.js"}}`)
+	write("packages/api/index.js", "app.listen(4000)\n")
+	write("packages/api/Dockerfile", "FROM node:20-alpine\nCMD [\"node\", \"index.js\"]\n")
+	write("Dockerfile", "FROM nginx\n")
+	write("packages/web/package.json", `{"name":"web","dependencies":{"next":"14"}}`)
+
+	profile, err := Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	profile.ClonePath = root
+	profile.KeyFiles = readKeyFiles(root)
+
+	if err := ScopeProfileToSubPath(profile, "./packages/api"); err != nil {
+		t.Fatalf("ScopeProfileToSubPath: %v", err)
+	}
+	if profile.SubPath != "packages/api" || profile.ClonePath != root || !profile.IsMonorepo {
+		t.Errorf("unexpected scoped profile: subPath=%q clonePath=%q monorepo=%v", profile.SubPath, profile.ClonePath, profile.IsMonorepo)
+	}
+	if !strings.Contains(profile.KeyFiles["package.json"], `"name":"api"`) {
+		t.Errorf("expected the workspace package.json keyed by bare name, got %v", keysOf(profile.KeyFiles))
+	}
+	if !strings.Contains(profile.KeyFiles["Dockerfile"], "node:20-alpine") {
+		t.Errorf("expected KeyFiles[Dockerfile] to be the workspace Dockerfile, got %q", profile.KeyFiles["Dockerfile"])
+	}
+	if !strings.Contains(profile.KeyFiles["root/package.json"], `"name":"root"`) {
+		t.Errorf("expected the root package.json under root/, got %v", keysOf(profile.KeyFiles))
+	}
+	if _, ok := profile.KeyFiles["root/pnpm-workspace.yaml"]; !ok {
+		t.Errorf("expected root workspace config kept for context, got %v", keysOf(profile.KeyFiles))
+	}
+	if strings.Contains(profile.FileTree, "web") {
+		t.Errorf("file tree should only cover the workspace, got:\n%s", profile.FileTree)
+	}
+	if !strings.Contains(subPathPromptSection(profile), "packages/api") {
+		t.Error("expected the prompt section to name the workspace")
+	}
+
+	if err := ScopeProfileToSubPath(profile, "packages/missing"); err == nil {
+		t.Error("expected an error for a missing workspace")
+	}
+}
+
+func keysOf(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,101 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxValidationAttempts caps validate→repair→validate rounds when
+// intelligence.max_validation_attempts is unset.
+const DefaultMaxValidationAttempts = 3
+
+// NotConvergedError reports a repair loop that gave up: the attempts ran out,
+// or a repair brought back the exact issues of an earlier attempt.
+type NotConvergedError struct {
+	Attempts       int
+	Repeated       bool // a repair brought back an earlier attempt's issues
+	RepeatsAttempt int  // that earlier attempt when Repeated; 0 is the initial validation
+	LastIssues     []string
+}
+
+func (e *NotConvergedError) Error() string {
+	msg := fmt.Sprintf("could not converge after %d attempts", e.Attempts)
+	switch {
+	case e.Repeated && e.RepeatsAttempt == 0:
+		msg += " (issues repeat the initial validation)"
+	case e.Repeated:
+		msg += fmt.Sprintf(" (issues repeat attempt %d)", e.RepeatsAttempt)
+	}
+	if len(e.LastIssues) == 0 {
+		return msg
+	}
+	return msg + ", last issues: " + strings.Join(e.LastIssues, "; ")
+}
+
+// ValidationTracker counts validation attempts in a repair loop and
+// remembers each attempt's issue fingerprint, so a model that keeps
+// oscillating between the same "fixes" stops instead of looping.
+type ValidationTracker struct {
+	maxAttempts int
+	attempts    int
+	seen        map[string]int // fingerprint → first attempt it was seen in
+}
+
+// NewValidationTracker returns a tracker allowing maxAttempts repairs;
+// 0 or less uses DefaultMaxValidationAttempts. initial is the validation
+// that started the loop and counts as attempt 0.
+func NewValidationTracker(maxAttempts int, initial *PlanValidation) *ValidationTracker {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxValidationAttempts
+	}
+	t := &ValidationTracker{maxAttempts: maxAttempts, seen: make(map[string]int)}
+	if initial != nil && len(initial.Issues) > 0 {
+		t.seen[IssueFingerprint(initial.Issues)] = 0
+	}
+	return t
+}
+
+// MaxAttempts returns the repair budget.
+func (t *ValidationTracker) MaxAttempts() int { return t.maxAttempts }
+
+// Attempts returns how many validations have been recorded.
+func (t *ValidationTracker) Attempts() int { return t.attempts }
+
+// Record adds the validation of a repaired plan. It returns a
+// *NotConvergedError when the plan is still invalid and either its issues
+// are identical to an earlier attempt's or this was the last attempt.
+func (t *ValidationTracker) Record(v *PlanValidation) error {
+	t.attempts++
+	if v == nil || v.IsValid || len(v.Issues) == 0 {
+		return nil
+	}
+	fp := IssueFingerprint(v.Issues)
+	if first, ok := t.seen[fp]; ok {
+		return &NotConvergedError{Attempts: t.attempts, Repeated: true, RepeatsAttempt: first, LastIssues: v.Issues}
+	}
+	t.seen[fp] = t.attempts
+	if t.attempts >= t.maxAttempts {
+		return &NotConvergedError{Attempts: t.attempts, LastIssues: v.Issues}
+	}
+	return nil
+}
+
+// IssueFingerprint identifies a set of issues regardless of order, case and
+// whitespace.
+func IssueFingerprint(issues []string) string {
+	normalized := make([]string, 0, len(issues))
+	seen := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		n := strings.ToLower(strings.Join(strings.Fields(issue), " "))
+		if n != "" && !seen[n] {
+			seen[n] = true
+			normalized = append(normalized, n)
+		}
+	}
+	sort.Strings(normalized)
+	sum := sha256.Sum256([]byte(strings.Join(normalized, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
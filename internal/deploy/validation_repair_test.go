@@ -0,0 +1,59 @@
+package deploy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidationTrackerStopsOnRepeatedIssues(t *testing.T) {
+	tracker := NewValidationTracker(0, &PlanValidation{Issues: []string{"missing ALB", "no health check"}})
+	if tracker.MaxAttempts() != DefaultMaxValidationAttempts {
+		t.Fatalf("MaxAttempts() = %d", tracker.MaxAttempts())
+	}
+	if err := tracker.Record(&PlanValidation{Issues: []string{"port mismatch"}}); err != nil {
+		t.Fatalf("new issues should not stop the loop: %v", err)
+	}
+	// Same set as the initial validation, reordered and reformatted.
+	err := tracker.Record(&PlanValidation{Issues: []string{"No  health check", "missing ALB"}})
+	var nc *NotConvergedError
+	if !errors.As(err, &nc) || nc.Attempts != 2 || !nc.Repeated || nc.RepeatsAttempt != 0 {
+		t.Fatalf("Record() = %v, want a repeat of the initial validation", err)
+	}
+	if !strings.Contains(err.Error(), "could not converge after 2 attempts (issues repeat the initial validation)") || !strings.Contains(err.Error(), "last issues: No  health check; missing ALB") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestValidationTrackerReportsRepeatedAttempt(t *testing.T) {
+	tracker := NewValidationTracker(5, &PlanValidation{Issues: []string{"missing ALB"}})
+	if err := tracker.Record(&PlanValidation{Issues: []string{"port mismatch"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Record(&PlanValidation{Issues: []string{"no health check"}}); err != nil {
+		t.Fatal(err)
+	}
+	err := tracker.Record(&PlanValidation{Issues: []string{"port mismatch"}})
+	var nc *NotConvergedError
+	if !errors.As(err, &nc) || !nc.Repeated || nc.RepeatsAttempt != 1 || nc.Attempts != 3 {
+		t.Fatalf("Record() = %v, want a repeat of attempt 1", err)
+	}
+	if !strings.Contains(err.Error(), "(issues repeat attempt 1)") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestValidationTrackerBudget(t *testing.T) {
+	tracker := NewValidationTracker(2, nil)
+	if err := tracker.Record(&PlanValidation{Issues: []string{"a"}}); err != nil {
+		t.Fatal(err)
+	}
+	err := tracker.Record(&PlanValidation{Issues: []string{"b"}})
+	var nc *NotConvergedError
+	if !errors.As(err, &nc) || nc.Repeated || nc.Attempts != 2 {
+		t.Fatalf("Record() = %v, want the budget exhausted", err)
+	}
+	if err := NewValidationTracker(1, nil).Record(&PlanValidation{IsValid: true}); err != nil {
+		t.Errorf("a valid plan should never fail: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package deploy
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValidationSeverity ranks how much a plan validation issue matters.
+// Only error and critical issues make a plan invalid.
+type ValidationSeverity string
+
+const (
+	SeverityInfo     ValidationSeverity = "info"
+	SeverityWarning  ValidationSeverity = "warning"
+	SeverityError    ValidationSeverity = "error"
+	SeverityCritical ValidationSeverity = "critical"
+)
+
+// ValidationIssue is a single validation finding with its severity.
+type ValidationIssue struct {
+	Message  string             `json:"message"`
+	Severity ValidationSeverity `json:"severity"`
+	Fix      string             `json:"fix,omitempty"`
+}
+
+// Blocking reports whether the issue must be fixed before deploying.
+func (i ValidationIssue) Blocking() bool {
+	return i.Severity == SeverityError || i.Severity == SeverityCritical
+}
+
+// criticalIssueMarkers identify issues that leak secrets into the plan.
+var criticalIssueMarkers = []string{
+	"secret", "password", "api key", "apikey", "access key", "private key", "credential", "plaintext", "inlined",
+}
+
+// cosmeticIssueMarkers identify issues that never justify blocking a deploy.
+var cosmeticIssueMarkers = []string{
+	"naming", "name length", "name is too long", "name too long", "tagging", "missing tags", "cosmetic", "consider ", "style",
+}
+
+func severityRank(s ValidationSeverity) int {
+	switch s {
+	case SeverityCritical:
+		return 3
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func normalizeSeverity(s ValidationSeverity) ValidationSeverity {
+	switch strings.ToLower(strings.TrimSpace(string(s))) {
+	case "critical", "fatal", "blocker":
+		return SeverityCritical
+	case "error", "high", "hard":
+		return SeverityError
+	case "warning", "warn", "medium", "low":
+		return SeverityWarning
+	case "info", "note":
+		return SeverityInfo
+	default:
+		return ""
+	}
+}
+
+// inferIssueSeverity classifies a flat issue string. Secret leaks and
+// destructive steps are critical, cosmetic remarks are demoted to warnings
+// and everything else is an error.
+func inferIssueSeverity(message string) ValidationSeverity {
+	lower := strings.ToLower(message)
+	for _, marker := range criticalIssueMarkers {
+		if strings.Contains(lower, marker) {
+			return SeverityCritical
+		}
+	}
+	for _, marker := range cosmeticIssueMarkers {
+		if strings.Contains(lower, marker) {
+			return SeverityWarning
+		}
+	}
+	return SeverityError
+}
+
+// issueDetailsFromFlat builds severity-tagged issues from the flat slices.
+func issueDetailsFromFlat(issues, warnings []string) []ValidationIssue {
+	details := make([]ValidationIssue, 0, len(issues)+len(warnings))
+	for _, issue := range issues {
+		details = append(details, ValidationIssue{Message: issue, Severity: inferIssueSeverity(issue)})
+	}
+	for _, warning := range warnings {
+		details = append(details, ValidationIssue{Message: warning, Severity: SeverityWarning})
+	}
+	return details
+}
+
+// applyIssueSeverities merges the flat Issues/Warnings into v.IssueDetails
+// (explicit severities win), orders them critical first, repopulates the flat
+// slices from them and recomputes IsValid.
+func applyIssueSeverities(v *PlanValidation) {
+	all := append(append([]ValidationIssue{}, v.IssueDetails...), issueDetailsFromFlat(v.Issues, v.Warnings)...)
+
+	seen := make(map[string]bool, len(all))
+	details := make([]ValidationIssue, 0, len(all))
+	for _, d := range all {
+		d.Message = strings.TrimSpace(d.Message)
+		if d.Message == "" || seen[d.Message] {
+			continue
+		}
+		seen[d.Message] = true
+		if sev := normalizeSeverity(d.Severity); sev != "" {
+			d.Severity = sev
+		} else {
+			d.Severity = inferIssueSeverity(d.Message)
+		}
+		details = append(details, d)
+	}
+	sort.SliceStable(details, func(i, j int) bool {
+		return severityRank(details[i].Severity) > severityRank(details[j].Severity)
+	})
+	v.IssueDetails = details
+
+	var issues, warnings []string
+	for _, d := range details {
+		if d.Blocking() {
+			issues = append(issues, d.Message)
+			if d.Fix != "" {
+				v.Fixes = append(v.Fixes, d.Fix)
+			}
+		} else {
+			warnings = append(warnings, d.Message)
+		}
+	}
+	v.Issues = issues
+	v.Warnings = warnings
+	v.Fixes = uniqueStrings(v.Fixes)
+	v.IsValid = len(issues) == 0
+}
+
+// deterministicIssueDetails tags deterministic findings without demoting any
+// of them: a deterministic issue is always at least an error.
+func deterministicIssueDetails(issues, warnings []string) []ValidationIssue {
+	details := issueDetailsFromFlat(issues, warnings)
+	for i := range details {
+		if i < len(issues) && !details[i].Blocking() {
+			details[i].Severity = SeverityError
+		}
+	}
+	sort.SliceStable(details, func(i, j int) bool {
+		return severityRank(details[i].Severity) > severityRank(details[j].Severity)
+	})
+	return details
+}
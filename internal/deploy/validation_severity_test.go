@@ -0,0 +1,53 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeValidation_Severities(t *testing.T) {
+	v := normalizeValidation(&PlanValidation{
+		Issues:   []string{"Resource naming exceeds the 32 character ALB name length", "Security group doesn't allow port 18789"},
+		Warnings: []string{"No health check configured"},
+		IssueDetails: []ValidationIssue{
+			{Message: "Database password is inlined in the user-data script", Severity: "critical", Fix: "Read it from Secrets Manager"},
+		},
+	})
+	if v.IsValid {
+		t.Fatal("expected plan with error/critical issues to be invalid")
+	}
+	if len(v.IssueDetails) != 4 || v.IssueDetails[0].Severity != SeverityCritical {
+		t.Fatalf("expected 4 details with critical first, got %+v", v.IssueDetails)
+	}
+	if len(v.Issues) != 2 || v.Issues[0] != "Database password is inlined in the user-data script" {
+		t.Fatalf("expected critical then error in flat issues, got %v", v.Issues)
+	}
+	if !containsIssue(v.Warnings, "Resource naming exceeds the 32 character ALB name length") {
+		t.Fatalf("expected naming issue to be demoted to a warning, got %v", v.Warnings)
+	}
+	if !containsIssue(v.Fixes, "Read it from Secrets Manager") {
+		t.Fatalf("expected detail fix to be carried into Fixes, got %v", v.Fixes)
+	}
+}
+
+func TestNormalizeValidation_WarningsOnlyIsValid(t *testing.T) {
+	v := normalizeValidation(&PlanValidation{
+		IssueDetails: []ValidationIssue{{Message: "Tags are missing on the ALB", Severity: "info"}},
+		Fixes:        []string{"Add tags"},
+	})
+	if !v.IsValid || len(v.Issues) != 0 || len(v.Fixes) != 0 {
+		t.Fatalf("expected info-only validation to be valid with no fixes, got %+v", v)
+	}
+}
+
+func TestBuildFixPrompt_CriticalFirst(t *testing.T) {
+	v := normalizeValidation(&PlanValidation{
+		Issues: []string{"Missing ECR login before push", "API key inlined in task definition"},
+	})
+	prompt := buildFixPrompt(v)
+	critical := strings.Index(prompt, "[CRITICAL]")
+	errIdx := strings.Index(prompt, "[ERROR]")
+	if critical < 0 || errIdx < 0 || critical > errIdx {
+		t.Fatalf("expected critical issue before error issue, got:\n%s", prompt)
+	}
+}
@@ -0,0 +1,112 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultHealthCheckTimeout bounds VerifyDeployment when ctx has no deadline.
+	DefaultHealthCheckTimeout = 6 * time.Minute
+	healthInitialBackoff      = 2 * time.Second
+	healthMaxBackoff          = 30 * time.Second
+	healthRequestTimeout      = 10 * time.Second
+)
+
+// DeploymentHealth is the outcome of polling a deployed app.
+type DeploymentHealth struct {
+	URL          string        `json:"url"`
+	Healthy      bool          `json:"healthy"`
+	StatusCode   int           `json:"statusCode,omitempty"`
+	Latency      time.Duration `json:"latency,omitempty"` // latency of the last request
+	Attempts     int           `json:"attempts"`
+	UsedFallback bool          `json:"usedFallback,omitempty"` // no health endpoint known; probed "/"
+	Error        string        `json:"error,omitempty"`
+}
+
+// VerifyDeployment polls endpoint + deep.HealthEndpoint with exponential
+// backoff until it is healthy or the ctx deadline (DefaultHealthCheckTimeout
+// when ctx has none) passes. Redirects are followed and any 2xx/3xx counts as
+// healthy. Without a health endpoint it probes "/" and accepts any non-5xx.
+func VerifyDeployment(ctx context.Context, endpoint string, deep *DeepAnalysis) *DeploymentHealth {
+	path := ""
+	if deep != nil {
+		path = deep.HealthEndpoint
+	}
+	return VerifyDeploymentPath(ctx, endpoint, path)
+}
+
+// VerifyDeploymentPath is VerifyDeployment for a known health path, for apps
+// such as OpenClaw whose health is their root page. A health path answering
+// 404 does not exist, so polling moves on to the "/" fallback.
+func VerifyDeploymentPath(ctx context.Context, endpoint, path string) *DeploymentHealth {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultHealthCheckTimeout)
+		defer cancel()
+	}
+
+	path = strings.TrimSpace(path)
+	health := &DeploymentHealth{UsedFallback: path == ""}
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	base := strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	health.URL = base + path
+
+	healthy := func(status int) bool {
+		if health.UsedFallback {
+			return status < 500
+		}
+		return status >= 200 && status < 400
+	}
+
+	client := &http.Client{Timeout: healthRequestTimeout}
+	backoff := healthInitialBackoff
+	for {
+		health.Attempts++
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, health.URL, nil)
+		if err != nil {
+			health.Error = err.Error()
+			return health
+		}
+		start := time.Now()
+		resp, err := client.Do(req)
+		health.Latency = time.Since(start)
+		if err == nil {
+			resp.Body.Close()
+			health.StatusCode = resp.StatusCode
+			if healthy(resp.StatusCode) {
+				health.Healthy = true
+				health.Error = ""
+				return health
+			}
+			health.Error = fmt.Sprintf("unhealthy status %d", resp.StatusCode)
+			if resp.StatusCode == http.StatusNotFound && !health.UsedFallback {
+				health.UsedFallback = true
+				health.URL = base + "/"
+				continue
+			}
+		} else {
+			health.StatusCode = 0
+			health.Error = err.Error()
+		}
+
+		select {
+		case <-ctx.Done():
+			health.Error = fmt.Sprintf("not healthy after %d attempts: %s", health.Attempts, health.Error)
+			return health
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > healthMaxBackoff {
+			backoff = healthMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package deploy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerifyDeployment(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	health := VerifyDeployment(context.Background(), srv.URL+"/", &DeepAnalysis{HealthEndpoint: "healthz"})
+	if !health.Healthy || health.StatusCode != http.StatusOK || health.URL != srv.URL+"/healthz" {
+		t.Fatalf("expected healthy after redirect, got %+v", health)
+	}
+
+	// No health endpoint: "/" returning 404 still proves the app is serving.
+	health = VerifyDeployment(context.Background(), srv.URL, nil)
+	if !health.Healthy || !health.UsedFallback {
+		t.Fatalf("expected fallback probe of / to be healthy, got %+v", health)
+	}
+
+	// A health endpoint the app does not serve falls back to "/".
+	health = VerifyDeployment(context.Background(), srv.URL, &DeepAnalysis{HealthEndpoint: "/health"})
+	if !health.Healthy || !health.UsedFallback || health.URL != srv.URL+"/" || health.Attempts != 2 {
+		t.Fatalf("expected a 404 health path to fall back to /, got %+v", health)
+	}
+
+	health = VerifyDeploymentPath(context.Background(), srv.URL, "/ok")
+	if !health.Healthy || health.UsedFallback || health.URL != srv.URL+"/ok" {
+		t.Fatalf("expected the explicit path to be probed, got %+v", health)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	health = VerifyDeployment(ctx, srv.URL, &DeepAnalysis{HealthEndpoint: "/broken"})
+	if health.Healthy || health.StatusCode != http.StatusServiceUnavailable || health.Error == "" {
+		t.Fatalf("expected unhealthy result, got %+v", health)
+	}
+}
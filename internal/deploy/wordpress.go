@@ -49,29 +49,13 @@ func IsWordPressRepo(p *RepoProfile, deep *DeepAnalysis) bool {
 	return false
 }
 
+// ApplyWordPressArchitectureDefaults puts WordPress on EC2 behind an ALB when
+// deploying to AWS.
 func ApplyWordPressArchitectureDefaults(targetProvider string, opts *DeployOptions, p *RepoProfile, deep *DeepAnalysis, arch *ArchitectDecision) bool {
-	_ = opts
-	if arch == nil {
-		return false
-	}
 	if !IsWordPressRepo(p, deep) {
 		return false
 	}
-
-	provider := strings.ToLower(strings.TrimSpace(targetProvider))
-	if provider == "" {
-		provider = "aws"
-	}
-	if provider != "aws" {
-		return false
-	}
-
-	arch.Provider = "aws"
-	arch.Method = "ec2"
-	arch.NeedsALB = true
-	arch.UseAPIGateway = false
-	arch.Reasoning = "WordPress one-click deploy: run wordpress + mariadb (Docker Hub images) on EC2 and expose via an ALB (health check /wp-login.php); persist DB + wp-content via Docker volumes"
-	return true
+	return ApplyStatefulArchitectureDefaults(targetProvider, opts, StatefulnessReport{Stateful: true, App: "wordpress"}, arch)
 }
 
 func AppendWordPressDeploymentRequirements(b *strings.Builder, p *RepoProfile, deep *DeepAnalysis) bool {
@@ -0,0 +1,122 @@
+// Package logging is the leveled diagnostic output shared by the CLI's
+// packages. The level comes from the log_level config (or --log-level):
+// quiet prints nothing but errors and results, normal adds high-level
+// progress, verbose adds each operation, and debug adds the commands run
+// and their raw output. --debug is kept as a shorthand for log_level debug.
+//
+// Diagnostics go to stderr so they never mix with answers or --output json.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Level is how much diagnostic output to print.
+type Level int
+
+const (
+	LevelQuiet Level = iota
+	LevelNormal
+	LevelVerbose
+	LevelDebug
+)
+
+var levelNames = []string{"quiet", "normal", "verbose", "debug"}
+
+func (l Level) String() string {
+	if l < LevelQuiet || l > LevelDebug {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel reads a level name. The empty string is normal.
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return LevelNormal, nil
+	}
+	for i, name := range levelNames {
+		if s == name {
+			return Level(i), nil
+		}
+	}
+	return LevelNormal, fmt.Errorf("unknown log level %q (available: %s)", s, strings.Join(levelNames, ", "))
+}
+
+var (
+	mu     sync.Mutex
+	writer io.Writer = os.Stderr
+)
+
+// Current returns the configured level. debug: true wins over log_level so
+// existing --debug invocations keep their full output; an unknown log_level
+// falls back to normal.
+func Current() Level {
+	if viper.GetBool("debug") {
+		return LevelDebug
+	}
+	level, err := ParseLevel(viper.GetString("log_level"))
+	if err != nil {
+		return LevelNormal
+	}
+	return level
+}
+
+// Enabled reports whether messages at level l are printed.
+func Enabled(l Level) bool {
+	return Current() >= l
+}
+
+// Infof prints high-level progress and warnings; hidden only by quiet.
+func Infof(format string, args ...any) {
+	logf(LevelNormal, format, args...)
+}
+
+// Verbosef prints the operations being run.
+func Verbosef(format string, args ...any) {
+	logf(LevelVerbose, format, args...)
+}
+
+// Debugf prints commands and raw output.
+func Debugf(format string, args ...any) {
+	logf(LevelDebug, format, args...)
+}
+
+// Printf prints regardless of the level, for output a caller has already
+// decided to show, such as an AWS client created with debug on.
+func Printf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprint(writer, msg)
+}
+
+func logf(level Level, format string, args ...any) {
+	if Enabled(level) {
+		Printf(format, args...)
+	}
+}
+
+// SetOutput redirects diagnostics and returns a function restoring the
+// previous writer. It exists for tests.
+func SetOutput(w io.Writer) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	prev := writer
+	writer = w
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		writer = prev
+	}
+}
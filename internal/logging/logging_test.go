@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]Level{"": LevelNormal, "quiet": LevelQuiet, " Verbose ": LevelVerbose, "DEBUG": LevelDebug} {
+		got, err := ParseLevel(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("trace"); err == nil || !strings.Contains(err.Error(), "quiet, normal, verbose, debug") {
+		t.Errorf("expected an error listing the levels, got %v", err)
+	}
+}
+
+func TestLevelsFilterOutput(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	var out strings.Builder
+	defer SetOutput(&out)()
+
+	emit := func() {
+		Infof("progress")
+		Verbosef("operation %d", 1)
+		Debugf("command\n")
+	}
+	for _, tt := range []struct {
+		level string
+		debug bool
+		want  string
+	}{
+		{level: "quiet", want: ""},
+		{level: "", want: "progress\n"},
+		{level: "verbose", want: "progress\noperation 1\n"},
+		{level: "debug", want: "progress\noperation 1\ncommand\n"},
+		{level: "quiet", debug: true, want: "progress\noperation 1\ncommand\n"},
+		{level: "loud", want: "progress\n"},
+	} {
+		viper.Set("log_level", tt.level)
+		viper.Set("debug", tt.debug)
+		out.Reset()
+		emit()
+		if out.String() != tt.want {
+			t.Errorf("log_level=%q debug=%v printed %q, want %q", tt.level, tt.debug, out.String(), tt.want)
+		}
+	}
+}
@@ -256,13 +256,38 @@ func planCostIntFlag(args []string, name string, defaultVal int) int {
 // most common families. Operators with real billing should override
 // at the cmd / API level rather than mutate these tables.
 
+// InstanceHourlyPrice returns the on-demand hourly USD price of a
+// compute shape from the same tables EstimatePlanCost uses: an AWS
+// instance type or RDS class (db.*), a GCE machine type, a Hetzner
+// server type or a DigitalOcean droplet size.
+func InstanceHourlyPrice(provider, family string) (float64, bool) {
+	family = strings.ToLower(strings.TrimSpace(family))
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "", "aws":
+		if strings.HasPrefix(family, "db.") {
+			return awsRDSPrice(family)
+		}
+		return awsInstancePrice(family)
+	case "gcp":
+		return gcpInstancePrice(family)
+	case "hetzner":
+		return hetznerServerPrice(family)
+	case "digitalocean":
+		return doDropletPrice(family)
+	}
+	return 0, false
+}
+
 func awsInstancePrice(t string) (float64, bool) {
 	table := map[string]float64{
 		"t3.micro": 0.0104, "t3.small": 0.0208, "t3.medium": 0.0416, "t3.large": 0.0832, "t3.xlarge": 0.1664, "t3.2xlarge": 0.3328,
-		"t4g.micro": 0.0084, "t4g.small": 0.0168, "t4g.medium": 0.0336, "t4g.large": 0.0672,
+		"t4g.micro": 0.0084, "t4g.small": 0.0168, "t4g.medium": 0.0336, "t4g.large": 0.0672, "t4g.xlarge": 0.1344, "t4g.2xlarge": 0.2688,
 		"m5.large": 0.096, "m5.xlarge": 0.192, "m5.2xlarge": 0.384, "m5.4xlarge": 0.768,
+		"m6g.large": 0.077, "m6g.xlarge": 0.154, "m6g.2xlarge": 0.308, "m6g.4xlarge": 0.616,
 		"c5.large": 0.085, "c5.xlarge": 0.17, "c5.2xlarge": 0.34,
+		"c6g.large": 0.068, "c6g.xlarge": 0.136, "c6g.2xlarge": 0.272,
 		"r5.large": 0.126, "r5.xlarge": 0.252,
+		"r6g.large": 0.1008, "r6g.xlarge": 0.2016,
 	}
 	p, ok := table[t]
 	return p, ok
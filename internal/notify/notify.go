@@ -0,0 +1,154 @@
+// Package notify sends a short message when a long-running investigation or
+// deploy finishes, so clanker can run in the background.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// sendTimeout bounds a single notification so a slow sink never holds up
+// the command that finished.
+const sendTimeout = 10 * time.Second
+
+// Notification is one completion message.
+type Notification struct {
+	Title   string // e.g. "Investigation finished"
+	Summary string // one line: services investigated, chosen architecture, cost
+	Failed  bool
+}
+
+// Text renders the notification as a single line.
+func (n Notification) Text() string {
+	icon := "✅"
+	if n.Failed {
+		icon = "❌"
+	}
+	if n.Summary == "" {
+		return icon + " " + n.Title
+	}
+	return icon + " " + n.Title + ": " + n.Summary
+}
+
+// Notifier delivers completion notifications.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Noop is the default Notifier; it drops every notification.
+type Noop struct{}
+
+func (Noop) Notify(context.Context, Notification) error { return nil }
+
+// Slack posts notifications to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+func (s Slack) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(map[string]string{"text": n.Text()})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: sendTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Desktop shows a native desktop notification: osascript on macOS and
+// notify-send on Linux.
+type Desktop struct{}
+
+func (Desktop) Notify(ctx context.Context, n Notification) error {
+	title := "clanker: " + n.Title
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.Summary), appleScriptString(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "linux":
+		urgency := "normal"
+		if n.Failed {
+			urgency = "critical"
+		}
+		cmd = exec.CommandContext(ctx, "notify-send", "--urgency", urgency, title, n.Summary)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// FromConfig builds the Notifier selected by notifications.sink: "slack"
+// (with notifications.slack_webhook_url, or SLACK_WEBHOOK_URL), "desktop",
+// or "none". Unset or unusable settings give Noop.
+func FromConfig() Notifier {
+	switch strings.ToLower(strings.TrimSpace(viper.GetString("notifications.sink"))) {
+	case "slack":
+		url := strings.TrimSpace(viper.GetString("notifications.slack_webhook_url"))
+		if url == "" {
+			url = strings.TrimSpace(os.Getenv("SLACK_WEBHOOK_URL"))
+		}
+		if url == "" {
+			fmt.Fprintln(os.Stderr, "⚠️  notifications.sink is slack but notifications.slack_webhook_url is not set; notifications are off")
+			return Noop{}
+		}
+		return Slack{WebhookURL: url}
+	case "desktop":
+		return Desktop{}
+	}
+	return Noop{}
+}
+
+// Send delivers n with a bounded timeout. Delivery failures are reported on
+// stderr rather than returned: a missed notification must not fail the
+// investigation or deploy it describes.
+func Send(ctx context.Context, notifier Notifier, n Notification) {
+	if notifier == nil {
+		return
+	}
+	if _, ok := notifier.(Noop); ok {
+		return
+	}
+	// Notify even when the work was cancelled or timed out.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+	defer cancel()
+	if err := notifier.Notify(ctx, n); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  notification failed: %v\n", err)
+	}
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSlackNotify(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	n := Notification{Title: "Deploy analysis failed", Summary: "https://github.com/o/r: boom", Failed: true}
+	if err := (Slack{WebhookURL: srv.URL}).Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got["text"] != "❌ Deploy analysis failed: https://github.com/o/r: boom" {
+		t.Errorf("text = %q", got["text"])
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	})
+	if err := (Slack{WebhookURL: srv.URL}).Notify(context.Background(), n); err == nil {
+		t.Error("expected an error for a rejected webhook")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	defer viper.Reset()
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+
+	if _, ok := FromConfig().(Noop); !ok {
+		t.Error("unset sink should be Noop")
+	}
+	viper.Set("notifications.sink", "slack")
+	if _, ok := FromConfig().(Noop); !ok {
+		t.Error("slack without a webhook URL should be Noop")
+	}
+	viper.Set("notifications.slack_webhook_url", "https://hooks.slack.com/services/T/B/X")
+	if s, ok := FromConfig().(Slack); !ok || s.WebhookURL != "https://hooks.slack.com/services/T/B/X" {
+		t.Errorf("got %#v, want Slack", FromConfig())
+	}
+	viper.Set("notifications.sink", "desktop")
+	if _, ok := FromConfig().(Desktop); !ok {
+		t.Error("desktop sink should be Desktop")
+	}
+}
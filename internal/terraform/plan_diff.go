@@ -0,0 +1,75 @@
+package terraform
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlanDiff is the parsed result of `terraform plan`: the summary counts and
+// the resource addresses grouped by planned action.
+type PlanDiff struct {
+	NoChanges bool
+	ToAdd     int
+	ToChange  int
+	ToDestroy int
+	Created   []string
+	Updated   []string
+	Replaced  []string
+	Destroyed []string
+}
+
+var (
+	planSummaryRe  = regexp.MustCompile(`Plan: (\d+) to add, (\d+) to change, (\d+) to destroy`)
+	planResourceRe = regexp.MustCompile(`^#\s+(\S+)\s+(?:will be|must be)\s+(.+)$`)
+)
+
+// GetPlanDiff runs `plan -no-color` in the workspace and parses what it would change.
+func (c *Client) GetPlanDiff(ctx context.Context) (*PlanDiff, error) {
+	args := []string{"plan", "-no-color", "-compact-warnings", "-input=false", "-detailed-exitcode"}
+	output, exitCode, err := runTerraformCommandDetailed(ctx, c.path, c.binary, 5*time.Minute, args...)
+	// -detailed-exitcode reports "changes present" as exit code 2.
+	if err != nil && exitCode != 2 {
+		return nil, err
+	}
+	return ParsePlanDiff(output), nil
+}
+
+// ParsePlanDiff extracts the summary counts and per-action resource addresses
+// from human-readable plan output.
+func ParsePlanDiff(output string) *PlanDiff {
+	diff := &PlanDiff{}
+	for _, line := range nonEmptyLines(stripANSI(output)) {
+		if strings.HasPrefix(line, "No changes.") {
+			diff.NoChanges = true
+			continue
+		}
+		if m := planSummaryRe.FindStringSubmatch(line); len(m) == 4 {
+			diff.ToAdd, _ = strconv.Atoi(m[1])
+			diff.ToChange, _ = strconv.Atoi(m[2])
+			diff.ToDestroy, _ = strconv.Atoi(m[3])
+			continue
+		}
+		m := planResourceRe.FindStringSubmatch(line)
+		if len(m) != 3 {
+			continue
+		}
+		address, action := m[1], m[2]
+		switch {
+		case strings.HasPrefix(action, "replaced"):
+			diff.Replaced = append(diff.Replaced, address)
+		case strings.HasPrefix(action, "destroyed"):
+			diff.Destroyed = append(diff.Destroyed, address)
+		case strings.HasPrefix(action, "created"):
+			diff.Created = append(diff.Created, address)
+		case strings.HasPrefix(action, "updated"):
+			diff.Updated = append(diff.Updated, address)
+		}
+	}
+	if diff.ToAdd+diff.ToChange+diff.ToDestroy > 0 {
+		diff.NoChanges = false
+	}
+	return diff
+}
@@ -0,0 +1,54 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlanDiff(t *testing.T) {
+	output := `
+Terraform will perform the following actions:
+
+  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+    }
+
+  # aws_db_instance.main must be replaced
+-/+ resource "aws_db_instance" "main" {
+    }
+
+  # aws_s3_bucket.logs will be destroyed
+  - resource "aws_s3_bucket" "logs" {
+    }
+
+  # module.vpc.aws_subnet.private["a"] will be created
+  + resource "aws_subnet" "private" {
+    }
+
+  # data.aws_ami.latest will be read during apply
+ <= data "aws_ami" "latest" {
+    }
+
+Plan: 2 to add, 1 to change, 2 to destroy.
+`
+	diff := ParsePlanDiff(output)
+	want := &PlanDiff{
+		ToAdd:     2,
+		ToChange:  1,
+		ToDestroy: 2,
+		Created:   []string{`module.vpc.aws_subnet.private["a"]`},
+		Updated:   []string{"aws_instance.web"},
+		Replaced:  []string{"aws_db_instance.main"},
+		Destroyed: []string{"aws_s3_bucket.logs"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("ParsePlanDiff = %+v, want %+v", diff, want)
+	}
+}
+
+func TestParsePlanDiffNoChanges(t *testing.T) {
+	diff := ParsePlanDiff("No changes. Your infrastructure matches the configuration.\n")
+	if !diff.NoChanges || diff.ToAdd+diff.ToChange+diff.ToDestroy != 0 {
+		t.Fatalf("expected no-changes diff, got %+v", diff)
+	}
+}